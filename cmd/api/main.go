@@ -46,6 +46,8 @@ import (
 
 	"github.com/complyark/datalens/internal/infrastructure/cache"
 	"github.com/complyark/datalens/internal/infrastructure/connector"
+	"github.com/complyark/datalens/internal/infrastructure/connector/plugin"
+	"github.com/complyark/datalens/internal/infrastructure/keymanager"
 	"github.com/complyark/datalens/internal/infrastructure/queue"
 	"github.com/complyark/datalens/internal/service/ai"
 	"github.com/complyark/datalens/internal/service/detection"
@@ -124,6 +126,10 @@ func main() {
 	// Initialize Infrastructure (always needed)
 	// =========================================================================
 
+	// Background workers stop when the server shuts down
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
 	// Database connection pool
 	dbPool, err := database.New(cfg.DB)
 	if err != nil {
@@ -133,6 +139,14 @@ func main() {
 	defer dbPool.Close()
 	log.Info("Database connected", "host", cfg.DB.Host, "port", cfg.DB.Port, "db", cfg.DB.Name)
 
+	// Credential encryption (static key or per-tenant envelope encryption)
+	credCipher, err := keymanager.NewCredentialCipher(cfg, dbPool)
+	if err != nil {
+		log.Error("Failed to initialize credential encryption", "error", err)
+		os.Exit(1)
+	}
+	log.Info("Credential encryption initialized", "master_key_provider", cfg.Crypto.MasterKeyProvider)
+
 	// NATS Connection
 	natsConn, err := eventbus.Connect(cfg.NATS.URL, slog.Default())
 	if err != nil {
@@ -173,6 +187,10 @@ func main() {
 	fieldRepo := repository.NewDataFieldRepo(dbPool)
 	piiRepo := repository.NewPIIClassificationRepo(dbPool)
 	feedbackRepo := repository.NewDetectionFeedbackRepo(dbPool)
+	detectionRuleRepo := repository.NewCustomDetectionRuleRepo(dbPool)
+	calibrationRepo := repository.NewDetectionCalibrationRepo(dbPool)
+	aiUsageRepo := repository.NewAIUsageRepo(dbPool)
+	aiPromptRepo := repository.NewAIPromptRepo(dbPool)
 	scanRunRepo := repository.NewScanRunRepo(dbPool)
 	dsrRepo := repository.NewDSRRepo(dbPool)
 	dprRepo := repository.NewDPRRequestRepo(dbPool)
@@ -219,6 +237,13 @@ func main() {
 	var purposeHandler *handler.PurposeHandler
 	var discoveryHandler *handler.DiscoveryHandler
 	var feedbackHandler *handler.FeedbackHandler
+	var detectionRuleHandler *handler.DetectionRuleHandler
+	var calibrationHandler *handler.CalibrationHandler
+	var aiUsageHandler *handler.AIUsageHandler
+	var aiHealthHandler *handler.AIHealthHandler
+	var aiPromptHandler *handler.AIPromptHandler
+	var dpiaHandler *handler.DPIAHandler
+	var redactionHandler *handler.RedactionHandler
 	var dashboardHandler *handler.DashboardHandler
 	var dsrHandler *handler.DSRHandler
 	var consentSvc *service.ConsentService
@@ -287,8 +312,8 @@ func main() {
 
 		purposeSvc := service.NewPurposeService(purposeRepo, eb, slog.Default())
 		feedbackSvc := service.NewFeedbackService(feedbackRepo, piiRepo, eb, slog.Default())
-		m365AuthSvc := service.NewM365AuthService(cfg, dsRepo, eb, slog.Default())
-		googleAuthSvc := service.NewGoogleAuthService(cfg, dsRepo, eb, slog.Default())
+		m365AuthSvc := service.NewM365AuthService(cfg, dsRepo, eb, credCipher, slog.Default())
+		googleAuthSvc := service.NewGoogleAuthService(cfg, dsRepo, eb, credCipher, slog.Default())
 
 		consentSvc = service.NewConsentService(
 			consentWidgetRepo,
@@ -314,76 +339,29 @@ func main() {
 		noticeSvc := service.NewNoticeService(consentNoticeRepo, consentWidgetRepo, eb, slog.Default())
 		translationSvc := service.NewTranslationService(translationRepo, consentNoticeRepo, eb, "", "")
 		dashboardSvc := service.NewDashboardService(dsRepo, piiRepo, scanRunRepo, slog.Default())
+		dashboardSvc.SetReidentificationRiskRepo(entityRepo)
 		analyticsSvc := analytics.NewConsentAnalyticsService(consentSessionRepo)
 
 		// --- AI Gateway Wiring ---
-		var aiProviders []ai.ProviderConfig
-
-		// OpenAI
-		if cfg.AI.OpenAI.APIKey != "" {
-			aiProviders = append(aiProviders, ai.ProviderConfig{
-				Name:              "openai",
-				Type:              ai.ProviderTypeOpenAICompatible,
-				APIKey:            cfg.AI.OpenAI.APIKey,
-				Endpoint:          "https://api.openai.com/v1",
-				DefaultModel:      cfg.AI.OpenAI.Model,
-				RequestsPerMinute: 500,
-				TokensPerMinute:   100000,
-			})
-		}
-
-		// Anthropic
-		if cfg.AI.Anthropic.APIKey != "" {
-			aiProviders = append(aiProviders, ai.ProviderConfig{
-				Name:              "anthropic",
-				Type:              ai.ProviderTypeAnthropic,
-				APIKey:            cfg.AI.Anthropic.APIKey,
-				DefaultModel:      cfg.AI.Anthropic.Model,
-				RequestsPerMinute: 500,
-				TokensPerMinute:   100000,
-			})
-		}
-
-		// Hugging Face (Generic HTTP)
-		if cfg.AI.HuggingFace.APIKey != "" {
-			aiProviders = append(aiProviders, ai.ProviderConfig{
-				Name:                "huggingface",
-				Type:                ai.ProviderTypeGenericHTTP,
-				APIKey:              cfg.AI.HuggingFace.APIKey,
-				Endpoint:            cfg.AI.HuggingFace.Endpoint + "/" + cfg.AI.HuggingFace.Model,
-				RequestBodyTemplate: `{"inputs": "{{.Prompt}}", "parameters": {"max_new_tokens": {{.MaxTokens}}, "temperature": {{.Temperature}}}}`,
-				ResponseContentPath: "0.generated_text",
-				DefaultModel:        cfg.AI.HuggingFace.Model,
-				RequestsPerMinute:   100,
-				TokensPerMinute:     10000,
-				Timeout:             30 * time.Second,
-			})
-		}
-
-		// Local LLM (Ollama)
-		aiProviders = append(aiProviders, ai.ProviderConfig{
-			Name:              "local",
-			Type:              ai.ProviderTypeOpenAICompatible,
-			Endpoint:          cfg.AI.LocalLLM.Endpoint + "/v1",
-			DefaultModel:      cfg.AI.LocalLLM.Model,
-			RequestsPerMinute: 1000,
-			TokensPerMinute:   1000000,
-		})
-
-		aiRegistry, err := ai.BuildRegistryFromConfig(aiProviders)
+		defaultGateway, err := ai.NewGatewayFromConfig(cfg.AI, slog.Default())
 		if err != nil {
 			log.Error("Failed to build AI registry", "error", err)
 			os.Exit(1)
 		}
 
-		fallbackChain := []string{cfg.AI.DefaultProvider, "huggingface", "openai", "anthropic", "local"}
-		aiSelector := ai.NewSelector(aiRegistry, fallbackChain, slog.Default())
+		// Per-tenant usage ledger and monthly budgets
+		aiUsageSvc := service.NewAIUsageService(aiUsageRepo, eb, slog.Default())
+		defaultGateway.SetUsageLedger(aiUsageSvc)
 
-		var aiGateway ai.Gateway = ai.NewDefaultGateway(aiSelector, slog.Default())
+		// Versioned prompts (tenant and platform overrides of the builtins)
+		aiPromptSvc := service.NewAIPromptService(aiPromptRepo, slog.Default())
+		defaultGateway.SetPromptSource(aiPromptSvc)
+
+		var aiGateway ai.Gateway = defaultGateway
 
 		if rdb != nil {
-			aiGateway = ai.NewCachedGateway(aiGateway, rdb, slog.Default(), cfg.AI)
-			log.Info("AI Gateway: Caching and Budgeting enabled")
+			aiGateway = ai.NewCachedGateway(aiGateway, rdb, slog.Default())
+			log.Info("AI Gateway: Caching enabled")
 		} else {
 			log.Warn("AI Gateway: Caching disabled (Redis unavailable)")
 		}
@@ -398,16 +376,43 @@ func main() {
 
 		// Detector (Strategy Composer)
 		detector := detection.NewDefaultDetector(aiGateway)
+		offlineDetector := detection.NewOfflineDetector()
+		if cfg.Detection.NERModelPath != "" {
+			nerModel, err := detection.LoadNERModel(cfg.Detection.NERModelPath)
+			if err != nil {
+				log.Error("Custom NER model not loaded, using built-in model", "path", cfg.Detection.NERModelPath, "error", err)
+			} else {
+				detector = detector.WithNERModel(nerModel)
+				offlineDetector = offlineDetector.WithNERModel(nerModel)
+				log.Info("Custom NER model loaded", "path", cfg.Detection.NERModelPath)
+			}
+		}
 
 		// Connector Registry
 		connRegistry := connector.NewConnectorRegistry(cfg, detector, parsingSvc)
+		connRegistry.SetCredentialCipher(credCipher)
+		secretMgr, err := connector.NewSecretManager(cfg.Secrets, auditSvc, slog.Default())
+		if err != nil {
+			log.Error("Secret references disabled", "error", err)
+		} else {
+			connRegistry.SetSecretManager(secretMgr)
+		}
 		connRegistry.Register(types.DataSourceMicrosoft365, func() discovery.Connector {
-			return connector.NewM365Connector(detector)
+			return connector.NewM365Connector(detector, credCipher)
 		})
+		if len(cfg.Connectors.PluginPaths) > 0 {
+			plugins := plugin.LoadAll(context.Background(), cfg.Connectors.PluginPaths, cfg.Connectors.PluginHandshakeTimeout, slog.Default())
+			if err := connRegistry.RegisterPlugins(plugins); err != nil {
+				log.Error("Some connector plugins were not registered", "error", err)
+			}
+		}
 		log.Info("Connector registry initialized", "supported_types", connRegistry.SupportedTypes())
 
 		dsSvc := service.NewDataSourceService(dsRepo, connRegistry, eb, slog.Default())
 
+		// Custom detection rules (tenant-defined strategy, loaded per scan)
+		detectionRuleSvc := service.NewDetectionRuleService(detectionRuleRepo, auditSvc, slog.Default())
+
 		// Discovery Service
 		discoverySvc := service.NewDiscoveryService(
 			dsRepo,
@@ -418,10 +423,15 @@ func main() {
 			scanRunRepo,
 			connRegistry,
 			detector,
+			detectionRuleSvc,
 			eb,
 			slog.Default(),
 		)
 
+		// Feedback-driven detection calibration (relearned daily by the scheduler)
+		calibrationSvc := service.NewCalibrationService(feedbackRepo, calibrationRepo, tenantRepo, slog.Default())
+		discoverySvc.SetCalibrationProvider(calibrationSvc)
+
 		// Governance Context Engine
 		templateLoader, err := templates.NewLoader()
 		if err != nil {
@@ -441,6 +451,7 @@ func main() {
 			auditSvc,
 			slog.Default(),
 		)
+		policySvc.SetReidentificationRiskRepo(entityRepo)
 
 		// Lineage Engine
 		lineageRepo := repository.NewPostgresLineageRepository(dbPool)
@@ -482,14 +493,38 @@ func main() {
 
 		// Start Scan Worker
 		go func() {
-			if err := scanSvc.StartWorker(context.Background()); err != nil {
+			if err := scanSvc.StartWorker(workerCtx); err != nil {
 				log.Error("Scan worker failed", "error", err)
 			}
 		}()
 
+		// ThirdParty Repository (needed by DPIA pre-fill and RoPA auto-gen)
+		thirdPartyRepo := repository.NewThirdPartyRepo(dbPool)
+
+		// DPIA Service + Handler (pre-filled from the inventory, periodic review by the scheduler)
+		dpiaRepo := repository.NewDPIARepo(dbPool)
+		dpiaSvc := service.NewDPIAService(dpiaRepo, purposeRepo, dsRepo, piiRepo, thirdPartyRepo, mappingRepo, tenantRepo, auditSvc, slog.Default())
+		dpiaSvc.SetReidentificationRiskRepo(entityRepo)
+		dpiaSvc.SetAIGateway(aiGateway, defaultGateway.Prompts())
+		dpiaHandler = handler.NewDPIAHandler(dpiaSvc)
+
+		// Dark-pattern audit of consent widgets on activation and config bumps
+		darkPatternSvc := analytics.NewDarkPatternService(aiGateway)
+		darkPatternSvc.SetPromptRegistry(defaultGateway.Prompts())
+		widgetAuditSvc := service.NewWidgetAuditService(repository.NewWidgetAuditRepo(dbPool), darkPatternSvc, eb, slog.Default())
+		consentSvc.SetWidgetAuditor(widgetAuditSvc)
+
+		// PII Redaction API (customers scrub their own prompts and logs; offline detection only)
+		redactionSvc := service.NewRedactionService(offlineDetector, repository.NewRedactionTokenRepo(dbPool), credCipher, cfg.App.SecretKey, auditSvc, slog.Default())
+		redactionSvc.SetTenantStrategies(detectionRuleSvc)
+		redactionHandler = handler.NewRedactionHandler(redactionSvc)
+
 		// Scan Scheduler
 		retentionRepo := repository.NewRetentionRepo(dbPool)
 		schedulerSvc := service.NewSchedulerService(dsRepo, tenantRepo, policySvc, scanSvc, consentExpirySvc, retentionRepo, slog.Default())
+		schedulerSvc.SetCalibrationService(calibrationSvc)
+		schedulerSvc.SetDPIAService(dpiaSvc)
+		schedulerSvc.SetRedactionService(redactionSvc)
 		if err := schedulerSvc.Start(context.Background()); err != nil {
 			log.Error("Failed to start scan scheduler", "error", err)
 		}
@@ -505,6 +540,7 @@ func main() {
 		dsrSvc := service.NewDSRService(dsrRepo, dsRepo, dsrQueue, dprRepo, eb, auditSvc, slog.Default())
 
 		dsrExecutor := service.NewDSRExecutor(dsrRepo, dsRepo, piiRepo, connRegistry, eb, slog.Default())
+		dsrExecutor.SetInventoryRepo(inventoryRepo)
 
 		// Start DSR Worker
 		go func() {
@@ -548,6 +584,11 @@ func main() {
 		purposeHandler = handler.NewPurposeHandler(purposeSvc)
 		discoveryHandler = handler.NewDiscoveryHandler(discoverySvc, scanSvc, inventoryRepo, entityRepo, fieldRepo)
 		feedbackHandler = handler.NewFeedbackHandler(feedbackSvc)
+		detectionRuleHandler = handler.NewDetectionRuleHandler(detectionRuleSvc)
+		calibrationHandler = handler.NewCalibrationHandler(calibrationSvc)
+		aiUsageHandler = handler.NewAIUsageHandler(aiUsageSvc)
+		aiHealthHandler = handler.NewAIHealthHandler(defaultGateway)
+		aiPromptHandler = handler.NewAIPromptHandler(aiPromptSvc)
 		dashboardHandler = handler.NewDashboardHandler(dashboardSvc)
 		dsrHandler = handler.NewDSRHandler(dsrSvc, dsrExecutor)
 		consentHandler = handler.NewConsentHandler(consentSvc, consentExpirySvc)
@@ -572,12 +613,18 @@ func main() {
 		retentionSvc := service.NewRetentionService(retentionRepo, slog.Default())
 		retentionHandler = handler.NewRetentionHandler(retentionSvc)
 
-		// ThirdParty Repository (needed by RoPA auto-gen)
-		thirdPartyRepo := repository.NewThirdPartyRepo(dbPool)
-
 		// RoPA Service + Handler
 		ropaRepo := repository.NewRoPARepo(dbPool)
 		ropaSvc := service.NewRoPAService(ropaRepo, purposeRepo, dsRepo, retentionRepo, thirdPartyRepo, auditSvc, slog.Default())
+		ropaSvc.SetDPIARepo(dpiaRepo)
+
+		// Notice Generator (drafts notices from the published RoPA, flags them when it changes)
+		noticeGenSvc := service.NewNoticeGeneratorService(consentNoticeRepo, ropaRepo, dpoRepo, tenantRepo, translationSvc, eb, slog.Default())
+		ropaSvc.SetPublishHook(noticeGenSvc)
+		noticeHandler.SetGenerator(noticeGenSvc)
+		if err := noticeGenSvc.Start(context.Background()); err != nil {
+			log.Error("Failed to start notice generator", "error", err)
+		}
 		ropaHandler = handler.NewRoPAHandler(ropaSvc)
 
 		// Purpose Assignment Service + Handler
@@ -590,7 +637,7 @@ func main() {
 		departmentSvc := service.NewDepartmentService(departmentRepo, auditSvc, slog.Default())
 		departmentHandler = handler.NewDepartmentHandler(departmentSvc)
 
-		// ThirdParty Service + Handler (reuses thirdPartyRepo from L573)
+		// ThirdParty Service + Handler (reuses thirdPartyRepo)
 		thirdPartySvc := service.NewThirdPartyService(thirdPartyRepo, auditSvc, slog.Default())
 		thirdPartyHandler = handler.NewThirdPartyHandler(thirdPartySvc)
 
@@ -771,7 +818,7 @@ func main() {
 		if shouldInit("cc") {
 			mountCCRoutes(r, authSvc, apiKeySvc, rateLimiter,
 				dsHandler, purposeHandler, authHandler,
-				discoveryHandler, feedbackHandler, dashboardHandler,
+				discoveryHandler, feedbackHandler, detectionRuleHandler, calibrationHandler, dashboardHandler,
 				dsrHandler, consentHandler, noticeHandler,
 				analyticsHandler, governanceHandler, breachHandler,
 				m365Handler, googleHandler, identityHandler,
@@ -780,7 +827,8 @@ func main() {
 				dataSubjectHandler, retentionHandler,
 				ropaHandler, purposeAssignmentHandler,
 				departmentHandler, thirdPartyHandler,
-				reportHandler,
+				reportHandler, aiUsageHandler, aiHealthHandler, aiPromptHandler,
+				dpiaHandler, redactionHandler,
 			)
 		}
 
@@ -823,6 +871,9 @@ func main() {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
+		// Interrupt running scans; they resume on redelivery
+		stopWorkers()
+
 		// Close event bus
 		if err := eb.Close(); err != nil {
 			log.Error("Event bus close error", "error", err)
@@ -40,6 +40,8 @@ func mountCCRoutes(
 	authHandler *handler.AuthHandler,
 	discoveryHandler *handler.DiscoveryHandler,
 	feedbackHandler *handler.FeedbackHandler,
+	detectionRuleHandler *handler.DetectionRuleHandler,
+	calibrationHandler *handler.CalibrationHandler,
 	dashboardHandler *handler.DashboardHandler,
 	dsrHandler *handler.DSRHandler,
 	consentHandler *handler.ConsentHandler,
@@ -61,6 +63,11 @@ func mountCCRoutes(
 	departmentHandler *handler.DepartmentHandler,
 	thirdPartyHandler *handler.ThirdPartyHandler,
 	reportHandler *handler.ReportHandler,
+	aiUsageHandler *handler.AIUsageHandler,
+	aiHealthHandler *handler.AIHealthHandler,
+	aiPromptHandler *handler.AIPromptHandler,
+	dpiaHandler *handler.DPIAHandler,
+	redactionHandler *handler.RedactionHandler,
 ) {
 	// Protected routes (auth + tenant isolation + rate limiting)
 	r.Group(func(r chi.Router) {
@@ -84,6 +91,12 @@ func mountCCRoutes(
 		// Detection Feedback (verify/correct/reject PII classifications)
 		r.Mount("/discovery/feedback", feedbackHandler.Routes())
 
+		// Custom Detection Rules (tenant-defined PII identifiers)
+		r.Mount("/discovery/rules", detectionRuleHandler.Routes())
+
+		// Detection Calibration (per-tenant tuning learned from feedback)
+		r.Mount("/discovery/calibration", calibrationHandler.Routes())
+
 		// Discovery (inventories, entities, fields)
 		r.Mount("/discovery", discoveryHandler.Routes())
 
@@ -148,6 +161,21 @@ func mountCCRoutes(
 
 		// Reports (Compliance Snapshot + Data Export)
 		r.Mount("/reports", reportHandler.Routes())
+
+		// DPIAs (Data Protection Impact Assessments)
+		r.Mount("/dpia", dpiaHandler.Routes())
+
+		// PII Redaction (API-key access for customers' own LLM and logging pipelines)
+		r.Mount("/redact", redactionHandler.Routes())
+
+		// AI Usage (ledger and monthly budget)
+		r.Mount("/ai", aiUsageHandler.Routes())
+
+		// AI Provider Health (circuit breakers; tenant admins only)
+		r.With(mw.RequireRole(identity.RoleAdmin)).Mount("/ai/providers", aiHealthHandler.Routes())
+
+		// AI Prompt Versions (tenant overrides of the prompt templates; tenant admins only)
+		r.With(mw.RequireRole(identity.RoleAdmin)).Mount("/ai/prompts", aiPromptHandler.Routes())
 	})
 }
 
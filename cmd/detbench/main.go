@@ -0,0 +1,165 @@
+// DataLens 2.0 — Detection Benchmark
+//
+// Runs a labeled dataset of columns through a detector configuration and
+// reports precision, recall and F1 per PII type and per strategy. The JSON
+// report is stable across runs, so reports from two commits can be diffed
+// to see whether a detection change helped or hurt.
+//
+// Usage: go run ./cmd/detbench [flags]
+//
+//	-dataset FILE       labeled columns (default: the built-in seeder corpus)
+//	-detector NAME      offline (default) or default (adds AI; reads provider config)
+//	-strategies a,b     run only these strategies of the detector
+//	-ner-model FILE     use a custom NER model
+//	-per-strategy       also score each strategy on its own (default true)
+//	-out FILE           write the JSON report here instead of stdout
+//
+// A summary is printed to stderr.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"github.com/complyark/datalens/internal/config"
+	"github.com/complyark/datalens/internal/service/ai"
+	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	datasetPath := flag.String("dataset", "", "labeled dataset (JSON; default: the built-in seeder corpus)")
+	detectorName := flag.String("detector", "offline", "detector configuration: offline or default")
+	strategies := flag.String("strategies", "", "comma-separated strategies to run (default: all)")
+	nerModel := flag.String("ner-model", "", "custom NER model file")
+	perStrategy := flag.Bool("per-strategy", true, "also score each strategy on its own")
+	out := flag.String("out", "", "write the JSON report to this file")
+	flag.Parse()
+
+	dataset, err := loadDataset(*datasetPath)
+	if err != nil {
+		return err
+	}
+
+	detector, err := buildDetector(*detectorName)
+	if err != nil {
+		return err
+	}
+	if *nerModel != "" {
+		model, err := detection.LoadNERModel(*nerModel)
+		if err != nil {
+			return err
+		}
+		detector = detector.WithNERModel(model)
+	}
+	name := *detectorName
+	if *strategies != "" {
+		detector, err = detector.Only(strings.Split(*strategies, ",")...)
+		if err != nil {
+			return err
+		}
+		name += "[" + *strategies + "]"
+	}
+
+	report, err := detection.RunBenchmark(context.Background(), detector, name, dataset, *perStrategy)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *out != "" {
+		if err := os.WriteFile(*out, data, 0o644); err != nil {
+			return err
+		}
+	} else {
+		os.Stdout.Write(data)
+	}
+
+	printSummary(report)
+	return nil
+}
+
+// buildDetector returns the named detector configuration. The default
+// detector needs AI provider settings from the environment.
+func buildDetector(name string) (*detection.ComposableDetector, error) {
+	switch name {
+	case "offline":
+		return detection.NewOfflineDetector(), nil
+	case "default":
+		_ = godotenv.Load()
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, fmt.Errorf("load config: %w", err)
+		}
+		gateway, err := ai.NewGatewayFromConfig(cfg.AI, slog.Default())
+		if err != nil {
+			return nil, err
+		}
+		return detection.NewDefaultDetector(gateway), nil
+	default:
+		return nil, errors.New("-detector must be offline or default")
+	}
+}
+
+func printSummary(r *detection.BenchmarkReport) {
+	w := os.Stderr
+	fmt.Fprintf(w, "%s on %s (%d cases)\n\n", r.Detector, r.Dataset, r.Cases)
+	printScore(w, "combined", r.Combined)
+
+	names := make([]string, 0, len(r.ByStrategy))
+	for n := range r.ByStrategy {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		printScore(w, n, r.ByStrategy[n])
+	}
+}
+
+func printScore(w *os.File, label string, s detection.BenchmarkScore) {
+	fmt.Fprintf(w, "%-18s P %.3f  R %.3f  F1 %.3f  accuracy %.3f  category %.3f\n",
+		label, s.Overall.Precision, s.Overall.Recall, s.Overall.F1, s.Accuracy, s.CategoryAccuracy)
+	if label != "combined" {
+		return
+	}
+
+	piiTypes := make([]types.PIIType, 0, len(s.ByType))
+	for t := range s.ByType {
+		piiTypes = append(piiTypes, t)
+	}
+	sort.Slice(piiTypes, func(i, j int) bool { return piiTypes[i] < piiTypes[j] })
+	for _, t := range piiTypes {
+		m := s.ByType[t]
+		fmt.Fprintf(w, "  %-16s P %.3f  R %.3f  F1 %.3f  (tp %d fp %d fn %d)\n",
+			t, m.Precision, m.Recall, m.F1, m.TruePositives, m.FalsePositives, m.FalseNegatives)
+	}
+	fmt.Fprintln(w)
+}
+
+// loadDataset reads the dataset at path, or the built-in one if path is empty.
+func loadDataset(path string) (*detection.BenchmarkDataset, error) {
+	if path == "" {
+		return detection.DefaultBenchmarkDataset()
+	}
+	return detection.LoadBenchmarkDataset(path)
+}
@@ -0,0 +1,226 @@
+// DataLens 2.0 — Credential Key Administration
+//
+// Manages envelope-encryption keys for stored credentials. All commands are
+// safe to run while the API is serving traffic: old key versions stay
+// readable, and credentials are re-encrypted row by row.
+//
+// Usage: go run ./cmd/keyctl <command> [flags]
+//
+//	rotate-master            create a new master key version, then rewrap all data keys
+//	rewrap                   rewrap all data keys under the current master key version
+//	rotate-data-key [-tenant ID]
+//	                         create a new data key version for one tenant (default: all)
+//	reencrypt [-tenant ID] [-batch N]
+//	                         re-encrypt stored credentials with each tenant's active data key
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+
+	"github.com/complyark/datalens/internal/config"
+	"github.com/complyark/datalens/internal/infrastructure/keymanager"
+	"github.com/complyark/datalens/internal/repository"
+	"github.com/complyark/datalens/pkg/crypto"
+	"github.com/complyark/datalens/pkg/database"
+	"github.com/complyark/datalens/pkg/logging"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		return errors.New("usage: keyctl [rotate-master|rewrap|rotate-data-key|reencrypt] [flags]")
+	}
+	command := os.Args[1]
+
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	tenantFlag := fs.String("tenant", "", "limit to a single tenant ID")
+	batch := fs.Int("batch", 100, "rows per batch for reencrypt")
+	_ = fs.Parse(os.Args[2:])
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if cfg.Crypto.MasterKeyProvider == "" || cfg.Crypto.MasterKeyProvider == keymanager.ProviderStatic {
+		return errors.New("CRYPTO_MASTER_KEY_PROVIDER must be \"local\" or \"vault\" to manage keys")
+	}
+
+	log := logging.New(cfg.App.Env, cfg.App.LogLevel).WithComponent("keyctl")
+
+	pool, err := database.New(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("connect db: %w", err)
+	}
+	defer pool.Close()
+
+	km, provider, err := keymanager.NewKeyManager(cfg, pool)
+	if err != nil {
+		return fmt.Errorf("init key manager: %w", err)
+	}
+
+	var tenantID *types.ID
+	if *tenantFlag != "" {
+		id, err := types.ParseID(*tenantFlag)
+		if err != nil {
+			return fmt.Errorf("invalid -tenant: %w", err)
+		}
+		tenantID = &id
+	}
+
+	ctx := context.Background()
+	log.Info("Running key command", "command", command, "master_key", provider.ID())
+
+	switch command {
+	case "rotate-master":
+		if err := provider.Rotate(ctx); err != nil {
+			return fmt.Errorf("rotate master key: %w", err)
+		}
+		log.Info("Master key rotated")
+		return rewrap(ctx, km, log.Logger)
+
+	case "rewrap":
+		return rewrap(ctx, km, log.Logger)
+
+	case "rotate-data-key":
+		tenants, err := targetTenants(ctx, pool, tenantID)
+		if err != nil {
+			return err
+		}
+		for _, t := range tenants {
+			version, err := km.RotateDataKey(ctx, t)
+			if err != nil {
+				return fmt.Errorf("rotate data key for tenant %s: %w", t, err)
+			}
+			log.Info("Data key rotated", "tenant_id", t, "version", version)
+		}
+		log.Info("Run 'keyctl reencrypt' to move existing credentials to the new data keys")
+		return nil
+
+	case "reencrypt":
+		return reencrypt(ctx, pool, km, tenantID, *batch, log.Logger)
+
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func rewrap(ctx context.Context, km *crypto.KeyManager, log *slog.Logger) error {
+	n, err := km.RewrapDataKeys(ctx)
+	if err != nil {
+		return err
+	}
+	log.Info("Data keys rewrapped", "count", n)
+	return nil
+}
+
+// targetTenants returns the requested tenant, or every tenant.
+func targetTenants(ctx context.Context, pool *pgxpool.Pool, tenantID *types.ID) ([]types.ID, error) {
+	if tenantID != nil {
+		return []types.ID{*tenantID}, nil
+	}
+	tenants, err := repository.NewTenantRepo(pool).GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tenants: %w", err)
+	}
+	ids := make([]types.ID, 0, len(tenants))
+	for _, t := range tenants {
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
+}
+
+// reencrypt walks data_sources in ID order and rewrites credentials that
+// are not yet on their tenant's active data key. Each row is updated only
+// if its credentials are unchanged since they were read, so concurrent
+// edits through the API are never overwritten. Credentials that do not
+// decrypt (e.g. plain connection strings) are left untouched.
+func reencrypt(ctx context.Context, pool *pgxpool.Pool, km *crypto.KeyManager, tenantID *types.ID, batch int, log *slog.Logger) error {
+	var (
+		after                               = types.ID{}
+		updated, current, skipped, conflict int
+	)
+
+	for {
+		rows, err := pool.Query(ctx, `
+			SELECT id, tenant_id, credentials
+			FROM data_sources
+			WHERE id > $1 AND credentials <> '' AND deleted_at IS NULL
+			  AND ($2::uuid IS NULL OR tenant_id = $2)
+			ORDER BY id
+			LIMIT $3`, after, tenantID, batch)
+		if err != nil {
+			return fmt.Errorf("query data sources: %w", err)
+		}
+
+		type row struct {
+			id, tenantID types.ID
+			credentials  string
+		}
+		var page []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.id, &r.tenantID, &r.credentials); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan data source: %w", err)
+			}
+			page = append(page, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterate data sources: %w", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		for _, r := range page {
+			after = r.id
+			out, changed, err := km.Reencrypt(ctx, r.tenantID, r.credentials)
+			if err != nil {
+				skipped++
+				log.Debug("Skipping credentials that do not decrypt", "data_source_id", r.id, "error", err)
+				continue
+			}
+			if !changed {
+				current++
+				continue
+			}
+			tag, err := pool.Exec(ctx,
+				`UPDATE data_sources SET credentials = $1, updated_at = NOW() WHERE id = $2 AND credentials = $3`,
+				out, r.id, r.credentials)
+			if err != nil {
+				return fmt.Errorf("update data source %s: %w", r.id, err)
+			}
+			if tag.RowsAffected() == 0 {
+				conflict++
+				continue
+			}
+			updated++
+		}
+	}
+
+	log.Info("Credentials re-encrypted",
+		"updated", updated,
+		"already_current", current,
+		"skipped", skipped,
+		"changed_concurrently", conflict,
+	)
+	return nil
+}
@@ -0,0 +1,89 @@
+// DataLens 2.0 — NER Model Trainer
+//
+// Trains the offline named-entity model used for free-text PII detection
+// and writes it as JSON for DETECTION_NER_MODEL_PATH.
+//
+// Usage: go run ./cmd/nertrain -out model.json [-corpus file.conll ...] [-no-seed] [-iterations N]
+//
+// Corpora are CoNLL-style: one "token<TAB>tag" per line with BIO tags
+// (B-PER, I-PER, B-ADDR, I-ADDR, B-ORG, I-ORG, O) and a blank line between
+// sentences. The built-in seed corpus is included unless -no-seed is given.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/complyark/datalens/internal/service/detection"
+)
+
+// corpusFlags collects repeated -corpus flags.
+type corpusFlags []string
+
+func (c *corpusFlags) String() string     { return strings.Join(*c, ",") }
+func (c *corpusFlags) Set(v string) error { *c = append(*c, v); return nil }
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var corpora corpusFlags
+	flag.Var(&corpora, "corpus", "labeled CoNLL corpus (repeatable)")
+	out := flag.String("out", "", "path to write the trained model")
+	noSeed := flag.Bool("no-seed", false, "train only on the given corpora")
+	iterations := flag.Int("iterations", 5, "training passes over the corpus")
+	flag.Parse()
+
+	if *out == "" {
+		return errors.New("-out is required")
+	}
+
+	var sentences []detection.NERSentence
+	if !*noSeed {
+		seed, err := detection.NERSeedSentences()
+		if err != nil {
+			return fmt.Errorf("seed corpus: %w", err)
+		}
+		sentences = append(sentences, seed...)
+	}
+	for _, path := range corpora {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		parsed, err := detection.ParseNERCorpus(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		sentences = append(sentences, parsed...)
+	}
+	if len(sentences) == 0 {
+		return errors.New("no training sentences")
+	}
+
+	model := detection.TrainNERModel(sentences, *iterations)
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := model.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Trained on %d sentences; labels %v; %d features → %s\n",
+		len(sentences), model.Labels, len(model.Weights), *out)
+	return nil
+}
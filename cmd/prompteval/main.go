@@ -0,0 +1,189 @@
+// DataLens 2.0 — Prompt Evaluation
+//
+// Replays a dataset of recorded PII detections against a candidate
+// pii_detection prompt with a mock provider, scores it against the baseline
+// (the builtin prompt, or -baseline), and optionally promotes it. The
+// candidate is promoted only when it does not regress.
+//
+// Usage: go run ./cmd/prompteval -candidate FILE [flags]
+//
+//	-candidate FILE     candidate template (text/template over PIIDetectionInput)
+//	-system TEXT        candidate system prompt (default: the builtin's)
+//	-baseline FILE      baseline template (default: the builtin prompt)
+//	-dataset FILE       recorded detections (default: the built-in sample)
+//	-out FILE           write the JSON report here instead of stdout
+//	-promote            store and activate the candidate if it does not regress
+//	-tenant ID          promote for one tenant instead of platform-wide
+//	-description TEXT   description stored with the promoted version
+//
+// Promotion reads the database settings from the environment. A summary is
+// printed to stderr; the exit status is 1 when the candidate regresses.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"github.com/complyark/datalens/internal/config"
+	"github.com/complyark/datalens/internal/repository"
+	"github.com/complyark/datalens/internal/service"
+	"github.com/complyark/datalens/internal/service/ai"
+	"github.com/complyark/datalens/pkg/database"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+const defaultDataset = "internal/service/ai/testdata/prompt_eval_pii.json"
+
+// errRegression is returned when the candidate is worse than the baseline.
+var errRegression = errors.New("candidate regresses against the baseline")
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	candidatePath := flag.String("candidate", "", "candidate template file")
+	system := flag.String("system", "", "candidate system prompt (default: the builtin's)")
+	baselinePath := flag.String("baseline", "", "baseline template file (default: the builtin prompt)")
+	datasetPath := flag.String("dataset", defaultDataset, "recorded detections (JSON)")
+	out := flag.String("out", "", "write the JSON report to this file")
+	promote := flag.Bool("promote", false, "store and activate the candidate if it does not regress")
+	tenant := flag.String("tenant", "", "promote for this tenant instead of platform-wide")
+	description := flag.String("description", "", "description stored with the promoted version")
+	flag.Parse()
+
+	if *candidatePath == "" {
+		flag.Usage()
+		return errors.New("-candidate is required")
+	}
+
+	cases, err := ai.LoadPromptEvalDataset(*datasetPath)
+	if err != nil {
+		return err
+	}
+
+	builtin, _ := ai.BuiltinPrompt(ai.PromptPIIDetection)
+	baseline := builtin
+	if *baselinePath != "" {
+		if baseline, err = loadTemplate(*baselinePath, "baseline", builtin.System); err != nil {
+			return err
+		}
+	}
+	candidateSystem := builtin.System
+	if *system != "" {
+		candidateSystem = *system
+	}
+	candidate, err := loadTemplate(*candidatePath, "candidate", candidateSystem)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	report := struct {
+		Baseline    *ai.PromptEvalReport `json:"baseline"`
+		Candidate   *ai.PromptEvalReport `json:"candidate"`
+		Regressions []string             `json:"regressions"`
+	}{
+		Baseline:  ai.EvaluatePIIPrompt(ctx, baseline, cases),
+		Candidate: ai.EvaluatePIIPrompt(ctx, candidate, cases),
+	}
+	report.Regressions = report.Candidate.Regressions(report.Baseline)
+
+	if err := writeReport(*out, report); err != nil {
+		return err
+	}
+	for _, r := range []*ai.PromptEvalReport{report.Baseline, report.Candidate} {
+		fmt.Fprintf(os.Stderr, "%-26s accuracy %.3f  (%d/%d)  rejected %d  render errors %d  ~%d prompt tokens\n",
+			r.Prompt, r.Accuracy, r.Correct, r.Cases, r.Rejected, r.RenderErrors, r.AvgPromptTokens)
+	}
+	if len(report.Regressions) > 0 {
+		for _, r := range report.Regressions {
+			fmt.Fprintf(os.Stderr, "regression: %s\n", r)
+		}
+		return errRegression
+	}
+
+	if !*promote {
+		return nil
+	}
+	return promoteCandidate(ctx, candidate, *tenant, *description)
+}
+
+// loadTemplate reads a pii_detection template body from path.
+func loadTemplate(path, version, system string) (ai.PromptTemplate, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return ai.PromptTemplate{}, fmt.Errorf("read %s: %w", version, err)
+	}
+	return ai.PromptTemplate{
+		UseCase: ai.PromptPIIDetection,
+		Version: version,
+		System:  system,
+		Body:    string(body),
+	}, nil
+}
+
+func writeReport(path string, report any) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// promoteCandidate stores the candidate as a new version and activates it,
+// platform-wide or for one tenant.
+func promoteCandidate(ctx context.Context, candidate ai.PromptTemplate, tenant, description string) error {
+	_ = godotenv.Load()
+
+	var tenantID *types.ID
+	if tenant != "" {
+		id, err := types.ParseID(tenant)
+		if err != nil {
+			return fmt.Errorf("parse -tenant: %w", err)
+		}
+		tenantID = &id
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	db, err := database.New(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("connect db: %w", err)
+	}
+	defer db.Close()
+
+	svc := service.NewAIPromptService(repository.NewAIPromptRepo(db), slog.Default())
+	version, err := svc.CreateVersion(ctx, service.CreateVersionRequest{
+		TenantID:     tenantID,
+		UseCase:      candidate.UseCase,
+		SystemPrompt: candidate.System,
+		Template:     candidate.Body,
+		Description:  description,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := svc.Activate(ctx, tenantID, version.ID); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "promoted %s@%s\n", version.UseCase, version.Label())
+	return nil
+}
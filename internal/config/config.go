@@ -12,19 +12,65 @@ import (
 
 // Config holds the complete application configuration.
 type Config struct {
-	App       AppConfig
-	DB        DatabaseConfig
-	Redis     RedisConfig
-	NATS      NATSConfig
-	AI        AIConfig
-	JWT       JWTConfig
-	Agent     AgentConfig
-	Consent   ConsentConfig
-	Portal    PortalConfig
-	Microsoft MicrosoftConfig
-	Google    GoogleConfig
-	Identity  IdentityConfig
-	CORS      CORSConfig
+	App        AppConfig
+	DB         DatabaseConfig
+	Redis      RedisConfig
+	NATS       NATSConfig
+	AI         AIConfig
+	JWT        JWTConfig
+	Agent      AgentConfig
+	Consent    ConsentConfig
+	Portal     PortalConfig
+	Microsoft  MicrosoftConfig
+	Google     GoogleConfig
+	Identity   IdentityConfig
+	CORS       CORSConfig
+	Connectors ConnectorsConfig
+	Crypto     CryptoConfig
+	Secrets    SecretsConfig
+	Detection  DetectionConfig
+}
+
+// DetectionConfig tunes the offline PII detection strategies.
+// NERModelPath points at a named-entity model saved with NERModel.Save;
+// when empty the built-in model is used.
+type DetectionConfig struct {
+	NERModelPath string
+}
+
+// SecretsConfig controls how secret references in data source credentials
+// are resolved. Each backend is only reachable within its allow-list;
+// "{tenant_id}" in the env prefix, a file directory or an allowed Vault
+// path is replaced with the data source's tenant so one tenant cannot
+// reference another's secrets. Locations without it are not used.
+type SecretsConfig struct {
+	CacheTTL          time.Duration
+	EnvPrefix         string   // Only env vars with this prefix may be referenced; must contain {tenant_id}
+	FileDirs          []string // Directories file references may read from; must contain {tenant_id}
+	VaultAddr         string
+	VaultToken        string
+	VaultNamespace    string
+	VaultAllowedPaths []string // Vault path prefixes references may read; must contain {tenant_id}
+}
+
+// CryptoConfig selects how stored credentials are encrypted.
+// MasterKeyProvider is "static" (legacy single APP_SECRET_KEY), "local"
+// (envelope encryption with a master key file) or "vault" (envelope
+// encryption with HashiCorp Vault transit).
+type CryptoConfig struct {
+	MasterKeyProvider string
+	LocalKeyFile      string
+	VaultAddr         string
+	VaultToken        string
+	VaultNamespace    string
+	VaultTransitMount string
+	VaultTransitKey   string
+}
+
+// ConnectorsConfig holds settings for out-of-process connector plugins.
+type ConnectorsConfig struct {
+	PluginPaths            []string      // Executables built with pkg/connectorplugin
+	PluginHandshakeTimeout time.Duration // Max time for a plugin to start and handshake
 }
 
 // CORSConfig holds Cross-Origin Resource Sharing settings.
@@ -114,6 +160,15 @@ type AIConfig struct {
 	Anthropic       AnthropicConfig
 	HuggingFace     HuggingFaceConfig
 	LocalLLM        LocalLLMConfig
+	Resilience      AIResilienceConfig
+
+	// UseCaseProviders maps a use case to its preferred provider,
+	// e.g. {"pii_detection": "local"}.
+	UseCaseProviders map[string]string
+	// LocalOnly registers the local LLM alone, so no data reaches a cloud LLM.
+	// It applies to the whole deployment, every tenant included; regulated
+	// tenants that need it run on a deployment of their own.
+	LocalOnly bool
 }
 
 // OpenAIConfig holds OpenAI-specific settings.
@@ -121,12 +176,20 @@ type OpenAIConfig struct {
 	APIKey    string
 	Model     string
 	MaxTokens int
+
+	// Prices in USD per 1K tokens, used for AI usage cost accounting.
+	InputCostPer1K  float64
+	OutputCostPer1K float64
 }
 
 // AnthropicConfig holds Anthropic-specific settings.
 type AnthropicConfig struct {
 	APIKey string
 	Model  string
+
+	// Prices in USD per 1K tokens, used for AI usage cost accounting.
+	InputCostPer1K  float64
+	OutputCostPer1K float64
 }
 
 // HuggingFaceConfig holds Hugging Face Inference API settings.
@@ -136,10 +199,30 @@ type HuggingFaceConfig struct {
 	Model    string
 }
 
-// LocalLLMConfig holds local LLM (Ollama) settings.
+// LocalLLMConfig holds local LLM (Ollama or llama.cpp server) settings.
 type LocalLLMConfig struct {
-	Endpoint string
-	Model    string
+	Backend   string // "ollama" or "llamacpp"
+	Endpoint  string
+	Model     string
+	FastModel string
+
+	ContextWindow  int           // Tokens per request, capped by the model's own window
+	MaxConcurrency int           // Requests generated at once; 1 suits CPU-only inference
+	MaxQueue       int           // Requests waiting for a slot before the provider reports busy
+	PullModel      bool          // Pull a missing model (Ollama)
+	Timeout        time.Duration // Per request, including time queued
+}
+
+// AIResilienceConfig holds provider circuit breaker and hedging settings.
+type AIResilienceConfig struct {
+	BreakerWindow      time.Duration // Rolling window for error and latency rates
+	BreakerMinRequests int           // Calls in the window before the breaker may trip
+	BreakerErrorRate   float64       // Failure share (errors and slow calls) that trips it
+	BreakerSlowCall    time.Duration // Calls slower than this count as failures
+	BreakerOpenFor     time.Duration // How long a tripped breaker rejects calls before probing
+
+	HedgeUseCases []string      // Use cases that hedge to the next provider
+	HedgeDelay    time.Duration // How long to wait before hedging
 }
 
 // JWTConfig holds JWT authentication settings.
@@ -204,10 +287,16 @@ func Load() (*Config, error) {
 				APIKey:    getEnv("OPENAI_API_KEY", ""),
 				Model:     getEnv("OPENAI_MODEL", "gpt-4o"),
 				MaxTokens: getEnvInt("OPENAI_MAX_TOKENS", 4096),
+
+				InputCostPer1K:  getEnvFloat("OPENAI_INPUT_COST_PER_1K", 0.0025),
+				OutputCostPer1K: getEnvFloat("OPENAI_OUTPUT_COST_PER_1K", 0.01),
 			},
 			Anthropic: AnthropicConfig{
 				APIKey: getEnv("ANTHROPIC_API_KEY", ""),
 				Model:  getEnv("ANTHROPIC_MODEL", "claude-3-5-sonnet-20241022"),
+
+				InputCostPer1K:  getEnvFloat("ANTHROPIC_INPUT_COST_PER_1K", 0.003),
+				OutputCostPer1K: getEnvFloat("ANTHROPIC_OUTPUT_COST_PER_1K", 0.015),
 			},
 			HuggingFace: HuggingFaceConfig{
 				APIKey:   getEnv("HUGGINGFACE_API_KEY", ""),
@@ -215,9 +304,28 @@ func Load() (*Config, error) {
 				Model:    getEnv("HUGGINGFACE_MODEL", "mistralai/Mistral-7B-Instruct-v0.3"),
 			},
 			LocalLLM: LocalLLMConfig{
-				Endpoint: getEnv("LOCAL_LLM_ENDPOINT", "http://localhost:11434"),
-				Model:    getEnv("LOCAL_LLM_MODEL", "llama3.2"),
+				Backend:   getEnv("LOCAL_LLM_BACKEND", "ollama"),
+				Endpoint:  getEnv("LOCAL_LLM_ENDPOINT", "http://localhost:11434"),
+				Model:     getEnv("LOCAL_LLM_MODEL", "llama3.2"),
+				FastModel: getEnv("LOCAL_LLM_FAST_MODEL", ""),
+
+				ContextWindow:  getEnvInt("LOCAL_LLM_CONTEXT_WINDOW", 8192),
+				MaxConcurrency: getEnvInt("LOCAL_LLM_MAX_CONCURRENCY", 1),
+				MaxQueue:       getEnvInt("LOCAL_LLM_MAX_QUEUE", 8),
+				PullModel:      getEnvBool("LOCAL_LLM_PULL_MODEL", true),
+				Timeout:        getEnvDuration("LOCAL_LLM_TIMEOUT", 5*time.Minute),
 			},
+			Resilience: AIResilienceConfig{
+				BreakerWindow:      getEnvDuration("AI_BREAKER_WINDOW", time.Minute),
+				BreakerMinRequests: getEnvInt("AI_BREAKER_MIN_REQUESTS", 5),
+				BreakerErrorRate:   getEnvFloat("AI_BREAKER_ERROR_RATE", 0.5),
+				BreakerSlowCall:    getEnvDuration("AI_BREAKER_SLOW_CALL", 20*time.Second),
+				BreakerOpenFor:     getEnvDuration("AI_BREAKER_OPEN_FOR", 30*time.Second),
+				HedgeUseCases:      getEnvSlice("AI_HEDGE_USE_CASES", nil),
+				HedgeDelay:         getEnvDuration("AI_HEDGE_DELAY", 2*time.Second),
+			},
+			UseCaseProviders: getEnvMap("AI_USE_CASE_PROVIDERS"),
+			LocalOnly:        getEnvBool("AI_LOCAL_ONLY", false),
 		},
 		JWT: JWTConfig{
 			Secret:             getEnv("JWT_SECRET", getEnv("APP_SECRET_KEY", "change-me-in-prod")),
@@ -265,6 +373,31 @@ func Load() (*Config, error) {
 				"http://portal.localhost:8000",
 			}),
 		},
+		Connectors: ConnectorsConfig{
+			PluginPaths:            getEnvSlice("CONNECTOR_PLUGIN_PATHS", nil),
+			PluginHandshakeTimeout: getEnvDuration("CONNECTOR_PLUGIN_HANDSHAKE_TIMEOUT", 10*time.Second),
+		},
+		Crypto: CryptoConfig{
+			MasterKeyProvider: getEnv("CRYPTO_MASTER_KEY_PROVIDER", "static"),
+			LocalKeyFile:      getEnv("CRYPTO_LOCAL_KEY_FILE", "/etc/datalens/master-keys.json"),
+			VaultAddr:         getEnv("VAULT_ADDR", ""),
+			VaultToken:        getEnv("VAULT_TOKEN", ""),
+			VaultNamespace:    getEnv("VAULT_NAMESPACE", ""),
+			VaultTransitMount: getEnv("VAULT_TRANSIT_MOUNT", "transit"),
+			VaultTransitKey:   getEnv("VAULT_TRANSIT_KEY", "datalens"),
+		},
+		Secrets: SecretsConfig{
+			CacheTTL:          getEnvDuration("SECRETS_CACHE_TTL", 5*time.Minute),
+			EnvPrefix:         getEnv("SECRETS_ENV_PREFIX", "DATALENS_SECRET_{tenant_id}_"),
+			FileDirs:          getEnvSlice("SECRETS_FILE_DIRS", []string{"/run/secrets/datalens/{tenant_id}"}),
+			VaultAddr:         getEnv("VAULT_ADDR", ""),
+			VaultToken:        getEnv("VAULT_TOKEN", ""),
+			VaultNamespace:    getEnv("VAULT_NAMESPACE", ""),
+			VaultAllowedPaths: getEnvSlice("SECRETS_VAULT_ALLOWED_PATHS", []string{"secret/data/datalens/{tenant_id}/"}),
+		},
+		Detection: DetectionConfig{
+			NERModelPath: getEnv("DETECTION_NER_MODEL_PATH", ""),
+		},
 	}
 
 	return cfg, cfg.validate()
@@ -297,6 +430,15 @@ func getEnvInt(key string, fallback int) int {
 	return fallback
 }
 
+func getEnvFloat(key string, fallback float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
 func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	if v := os.Getenv(key); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
@@ -306,6 +448,27 @@ func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	return fallback
 }
 
+func getEnvBool(key string, fallback bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+// getEnvMap parses "k1=v1,k2=v2", skipping malformed pairs.
+func getEnvMap(key string) map[string]string {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if k, v = strings.TrimSpace(k), strings.TrimSpace(v); ok && k != "" && v != "" {
+			m[k] = v
+		}
+	}
+	return m
+}
+
 func getEnvSlice(key string, fallback []string) []string {
 	if v := os.Getenv(key); v != "" {
 		// Import "strings" needed
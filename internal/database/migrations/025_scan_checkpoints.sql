@@ -0,0 +1,5 @@
+-- 025_scan_checkpoints.sql
+-- Persist per-run scan checkpoints so redelivered scan jobs can resume.
+
+ALTER TABLE scan_runs
+    ADD COLUMN IF NOT EXISTS checkpoint JSONB NOT NULL DEFAULT '{}';
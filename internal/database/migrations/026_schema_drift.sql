@@ -0,0 +1,6 @@
+-- 026_schema_drift.sql
+-- Store the last scanned schema structure so scans can detect drift.
+-- schema_version now holds the fingerprint of this snapshot.
+
+ALTER TABLE data_inventories
+    ADD COLUMN IF NOT EXISTS schema_snapshot JSONB NOT NULL DEFAULT '{}';
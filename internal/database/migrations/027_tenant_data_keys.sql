@@ -0,0 +1,18 @@
+-- 027_tenant_data_keys.sql
+-- Per-tenant data keys for envelope encryption of stored credentials.
+-- Keys are stored wrapped by the configured master key provider.
+
+CREATE TABLE IF NOT EXISTS tenant_data_keys (
+    tenant_id UUID NOT NULL,
+    version INT NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    master_key_id TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    rotated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (tenant_id, version)
+);
+
+-- At most one active key per tenant.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_data_keys_active
+    ON tenant_data_keys (tenant_id) WHERE active;
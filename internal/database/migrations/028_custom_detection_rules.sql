@@ -0,0 +1,23 @@
+-- 028_custom_detection_rules.sql
+-- Tenant-defined detection rules, loaded as an extra detection strategy
+-- at scan time.
+
+CREATE TABLE IF NOT EXISTS custom_detection_rules (
+    id           UUID PRIMARY KEY,
+    tenant_id    UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name         VARCHAR(255) NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    type_label   VARCHAR(40) NOT NULL,
+    category     VARCHAR(50) NOT NULL,
+    sensitivity  VARCHAR(20) NOT NULL,
+    pattern      TEXT NOT NULL DEFAULT '',
+    validator    JSONB NOT NULL DEFAULT '{}',
+    column_hints TEXT[] NOT NULL DEFAULT '{}',
+    enabled      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, type_label)
+);
+
+CREATE INDEX IF NOT EXISTS idx_custom_detection_rules_tenant
+    ON custom_detection_rules(tenant_id) WHERE enabled;
@@ -0,0 +1,11 @@
+-- 029_detection_calibrations.sql
+-- Per-tenant detector calibration learned from detection feedback.
+
+CREATE TABLE IF NOT EXISTS detection_calibrations (
+    tenant_id          UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    strategy_weights   JSONB NOT NULL DEFAULT '{}',
+    type_thresholds    JSONB NOT NULL DEFAULT '{}',
+    suppressed_columns JSONB NOT NULL DEFAULT '[]',
+    report             JSONB NOT NULL DEFAULT '{}',
+    calibrated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,12 @@
+-- 030_reidentification_risk.sql
+-- Re-identification risk of each entity's quasi-identifier combinations,
+-- estimated from sampled rows during scans. The score is kept in its own
+-- column so entities can be ranked for pseudonymization work.
+
+ALTER TABLE data_entities
+    ADD COLUMN IF NOT EXISTS reid_risk       JSONB,
+    ADD COLUMN IF NOT EXISTS reid_risk_score DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_data_entities_reid_risk_score
+    ON data_entities(reid_risk_score DESC)
+    WHERE reid_risk_score IS NOT NULL;
@@ -0,0 +1,7 @@
+-- 031_subject_graph.sql
+-- Per-source subject graph: identifier fields a DSR can match on, and the
+-- key links (declared or inferred from value overlap) that lead from them
+-- to dependent entities. Rebuilt on every full scan.
+
+ALTER TABLE data_inventories
+    ADD COLUMN IF NOT EXISTS subject_graph JSONB;
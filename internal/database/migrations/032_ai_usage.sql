@@ -0,0 +1,29 @@
+-- 032_ai_usage.sql
+-- Persistent AI usage ledger and monthly per-tenant AI budgets.
+
+CREATE TABLE IF NOT EXISTS ai_usage_records (
+    id            UUID PRIMARY KEY,
+    tenant_id     UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    provider      VARCHAR(100) NOT NULL,
+    model         VARCHAR(200) NOT NULL DEFAULT '',
+    use_case      VARCHAR(100) NOT NULL DEFAULT '',
+    input_tokens  INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens  INTEGER NOT NULL DEFAULT 0,
+    cost          NUMERIC(14, 6) NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_records_tenant_created
+    ON ai_usage_records (tenant_id, created_at);
+
+CREATE TABLE IF NOT EXISTS ai_budgets (
+    tenant_id           UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    monthly_token_limit BIGINT NOT NULL DEFAULT 0,
+    monthly_cost_limit  NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    soft_limit_percent  INTEGER NOT NULL DEFAULT 80,
+    hard_limit          BOOLEAN NOT NULL DEFAULT FALSE,
+    alerted_period      VARCHAR(7) NOT NULL DEFAULT '',
+    alerted_level       VARCHAR(20) NOT NULL DEFAULT '',
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,27 @@
+-- 033_ai_prompts.sql
+-- Versioned AI prompt templates, active per use case platform-wide or per
+-- tenant, and the prompt version behind each PII classification.
+
+CREATE TABLE IF NOT EXISTS ai_prompt_versions (
+    id            UUID PRIMARY KEY,
+    tenant_id     UUID REFERENCES tenants(id) ON DELETE CASCADE, -- NULL = platform-wide
+    use_case      VARCHAR(100) NOT NULL,
+    version       INTEGER NOT NULL,
+    system_prompt TEXT NOT NULL DEFAULT '',
+    template      TEXT NOT NULL,
+    description   TEXT NOT NULL DEFAULT '',
+    status        VARCHAR(20) NOT NULL DEFAULT 'DRAFT',
+    created_by    UUID,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    activated_at  TIMESTAMPTZ,
+    UNIQUE (use_case, version)
+);
+
+-- At most one active version per use case in each scope.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_prompt_versions_active_tenant
+    ON ai_prompt_versions (tenant_id, use_case) WHERE status = 'ACTIVE' AND tenant_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_prompt_versions_active_platform
+    ON ai_prompt_versions (use_case) WHERE status = 'ACTIVE' AND tenant_id IS NULL;
+
+ALTER TABLE pii_classifications
+    ADD COLUMN IF NOT EXISTS prompt_version VARCHAR(120) NOT NULL DEFAULT '';
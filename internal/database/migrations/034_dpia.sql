@@ -0,0 +1,32 @@
+-- 034_dpia.sql
+-- Data Protection Impact Assessments (DPDPA s.10(2)(c), GDPR Art. 35):
+-- draft → review → approve, with periodic review of approved assessments.
+
+CREATE TABLE IF NOT EXISTS dpias (
+    id                   UUID PRIMARY KEY,
+    tenant_id            UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    title                VARCHAR(255) NOT NULL,
+    description          TEXT NOT NULL DEFAULT '',
+    purpose_ids          JSONB NOT NULL DEFAULT '[]',   -- empty = every purpose
+    data_source_ids      JSONB NOT NULL DEFAULT '[]',   -- empty = every data source
+    status               VARCHAR(20) NOT NULL DEFAULT 'DRAFT', -- DRAFT, IN_REVIEW, APPROVED, REVIEW_DUE, ARCHIVED
+    context              JSONB NOT NULL DEFAULT '{}',   -- inventory snapshot the assessment is based on
+    narrative            TEXT NOT NULL DEFAULT '',
+    risks                JSONB NOT NULL DEFAULT '[]',
+    mitigations          JSONB NOT NULL DEFAULT '[]',
+    risk_level           VARCHAR(10) NOT NULL DEFAULT 'LOW',
+    ai_draft             JSONB,                         -- provider, model and prompt of the AI draft
+    review_interval_days INTEGER NOT NULL DEFAULT 365,
+    created_by           UUID,
+    submitted_by         UUID,
+    reviewed_by          UUID,
+    review_notes         TEXT NOT NULL DEFAULT '',
+    approved_at          TIMESTAMPTZ,
+    last_reviewed_at     TIMESTAMPTZ,
+    next_review_at       TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dpias_tenant_status ON dpias (tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_dpias_next_review ON dpias (next_review_at) WHERE status = 'APPROVED';
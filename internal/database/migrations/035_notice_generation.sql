@@ -0,0 +1,11 @@
+-- 035_notice_generation.sql
+-- Privacy notices generated from the published RoPA. The Schedule I schema
+-- fields are now persisted, along with the RoPA version a notice was
+-- generated from and whether it has fallen out of sync with the RoPA.
+
+ALTER TABLE consent_notices
+    ADD COLUMN IF NOT EXISTS schema     JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS generation JSONB;  -- NULL for hand-written notices
+
+CREATE INDEX IF NOT EXISTS idx_consent_notices_generated
+    ON consent_notices (tenant_id) WHERE generation IS NOT NULL;
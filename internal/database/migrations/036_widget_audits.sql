@@ -0,0 +1,19 @@
+-- 036_widget_audits.sql
+-- Dark-pattern audits of consent widgets (CCPA Dark Patterns Guidelines, 2023),
+-- run on every activation and config version bump.
+
+CREATE TABLE IF NOT EXISTS consent_widget_audits (
+    id                UUID PRIMARY KEY,
+    tenant_id         UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    widget_id         UUID NOT NULL REFERENCES consent_widgets(id) ON DELETE CASCADE,
+    widget_version    INTEGER NOT NULL,
+    trigger           VARCHAR(20) NOT NULL,             -- ACTIVATION, CONFIG_UPDATE
+    findings          JSONB NOT NULL DEFAULT '[]',
+    ai_prompt_version VARCHAR(100) NOT NULL DEFAULT '',
+    ai_error          TEXT NOT NULL DEFAULT '',         -- AI check failed; rule findings only
+    override          JSONB,                            -- reason, user and time of a HIGH-finding override
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_consent_widget_audits_widget ON consent_widget_audits (widget_id, created_at DESC);
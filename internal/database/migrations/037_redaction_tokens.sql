@@ -0,0 +1,15 @@
+-- 037_redaction_tokens.sql
+-- Token vault of the PII redaction API: the original values behind
+-- reversible pseudonyms, encrypted with the tenant's key.
+
+CREATE TABLE IF NOT EXISTS redaction_tokens (
+    tenant_id  UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    token      VARCHAR(100) NOT NULL,   -- e.g. [EMAIL_3f9a0c1b7d2e4a6f8b0c2d4e6f8a0b1c]
+    pii_type   VARCHAR(100) NOT NULL,
+    ciphertext TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (tenant_id, token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_redaction_tokens_expires ON redaction_tokens (expires_at);
@@ -0,0 +1,8 @@
+-- 038_field_review.sql
+-- Columns that appear between scans are flagged for classification review
+-- until someone marks them reviewed, whether or not detection found PII.
+
+ALTER TABLE data_fields
+    ADD COLUMN IF NOT EXISTS needs_review BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_data_fields_needs_review ON data_fields(entity_id) WHERE needs_review;
@@ -0,0 +1,11 @@
+-- 039_ai_prompt_version_scope.sql
+-- Prompt versions are numbered per scope: each tenant and the platform
+-- count their own versions of a use case.
+
+ALTER TABLE ai_prompt_versions
+    DROP CONSTRAINT IF EXISTS ai_prompt_versions_use_case_version_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_prompt_versions_version_tenant
+    ON ai_prompt_versions (tenant_id, use_case, version) WHERE tenant_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_prompt_versions_version_platform
+    ON ai_prompt_versions (use_case, version) WHERE tenant_id IS NULL;
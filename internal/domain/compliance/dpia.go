@@ -0,0 +1,243 @@
+package compliance
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// DPIA is a Data Protection Impact Assessment of a high-risk processing
+// activity, as required of Significant Data Fiduciaries by DPDPA s.10(2)(c)
+// and of controllers by GDPR Art. 35. Its context is pre-filled from the
+// tenant's inventory; approved DPIAs are linked into the RoPA.
+type DPIA struct {
+	types.TenantEntity
+	Title         string     `json:"title" db:"title"`
+	Description   string     `json:"description" db:"description"`         // The processing assessed
+	PurposeIDs    []types.ID `json:"purpose_ids" db:"purpose_ids"`         // Empty: every purpose
+	DataSourceIDs []types.ID `json:"data_source_ids" db:"data_source_ids"` // Empty: every data source
+	Status        DPIAStatus `json:"status" db:"status"`
+
+	Context     DPIAContext      `json:"context" db:"context"`
+	Narrative   string           `json:"narrative" db:"narrative"`
+	Risks       []DPIARisk       `json:"risks" db:"risks"`
+	Mitigations []DPIAMitigation `json:"mitigations" db:"mitigations"`
+	RiskLevel   DPIARiskLevel    `json:"risk_level" db:"risk_level"` // Highest risk
+	AIDraft     *DPIADraftInfo   `json:"ai_draft,omitempty" db:"ai_draft"`
+
+	ReviewIntervalDays int        `json:"review_interval_days" db:"review_interval_days"`
+	CreatedBy          *types.ID  `json:"created_by,omitempty" db:"created_by"`
+	SubmittedBy        *types.ID  `json:"submitted_by,omitempty" db:"submitted_by"`
+	ReviewedBy         *types.ID  `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewNotes        string     `json:"review_notes,omitempty" db:"review_notes"`
+	ApprovedAt         *time.Time `json:"approved_at,omitempty" db:"approved_at"`
+	LastReviewedAt     *time.Time `json:"last_reviewed_at,omitempty" db:"last_reviewed_at"`
+	NextReviewAt       *time.Time `json:"next_review_at,omitempty" db:"next_review_at"`
+}
+
+// DPIAStatus tracks the lifecycle of a DPIA.
+type DPIAStatus string
+
+const (
+	DPIAStatusDraft     DPIAStatus = "DRAFT"
+	DPIAStatusInReview  DPIAStatus = "IN_REVIEW"
+	DPIAStatusApproved  DPIAStatus = "APPROVED"
+	DPIAStatusReviewDue DPIAStatus = "REVIEW_DUE" // Approved, periodic review overdue
+	DPIAStatusArchived  DPIAStatus = "ARCHIVED"
+)
+
+// DefaultDPIAReviewIntervalDays is the periodic review interval of a DPIA
+// that does not set one. DPDPA s.10(2)(c) requires periodic assessments.
+const DefaultDPIAReviewIntervalDays = 365
+
+// IsApproved reports whether the DPIA is approved, including approved
+// DPIAs awaiting their periodic review.
+func (d *DPIA) IsApproved() bool {
+	return d.Status == DPIAStatusApproved || d.Status == DPIAStatusReviewDue
+}
+
+// ValidateTransition checks if a status transition is valid.
+func (d *DPIA) ValidateTransition(newStatus DPIAStatus) error {
+	valid := false
+	switch d.Status {
+	case DPIAStatusDraft:
+		valid = newStatus == DPIAStatusInReview || newStatus == DPIAStatusArchived
+	case DPIAStatusInReview:
+		valid = newStatus == DPIAStatusApproved || newStatus == DPIAStatusDraft // Approve or request changes
+	case DPIAStatusApproved, DPIAStatusReviewDue:
+		// Review periodically (confirm or reopen), or retire with the processing
+		valid = newStatus == DPIAStatusApproved || newStatus == DPIAStatusReviewDue ||
+			newStatus == DPIAStatusDraft || newStatus == DPIAStatusArchived
+	case DPIAStatusArchived:
+		valid = false // Terminal state
+	}
+
+	if !valid {
+		return errors.New("invalid status transition from " + string(d.Status) + " to " + string(newStatus))
+	}
+	return nil
+}
+
+// DPIARiskLevel grades the likelihood, impact and level of a risk.
+type DPIARiskLevel string
+
+const (
+	DPIARiskLow    DPIARiskLevel = "LOW"
+	DPIARiskMedium DPIARiskLevel = "MEDIUM"
+	DPIARiskHigh   DPIARiskLevel = "HIGH"
+)
+
+// DPIARiskLevels returns every DPIARiskLevel, lowest first.
+func DPIARiskLevels() []DPIARiskLevel {
+	return []DPIARiskLevel{DPIARiskLow, DPIARiskMedium, DPIARiskHigh}
+}
+
+func (l DPIARiskLevel) rank() int {
+	switch l {
+	case DPIARiskHigh:
+		return 3
+	case DPIARiskMedium:
+		return 2
+	case DPIARiskLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// DPIARiskLevelFor rates a risk on a 3×3 likelihood × impact matrix: HIGH
+// when one is HIGH and the other at least MEDIUM, LOW when both are LOW.
+func DPIARiskLevelFor(likelihood, impact DPIARiskLevel) DPIARiskLevel {
+	hi, lo := likelihood, impact
+	if lo.rank() > hi.rank() {
+		hi, lo = lo, hi
+	}
+	switch {
+	case hi == DPIARiskHigh && lo.rank() >= DPIARiskMedium.rank():
+		return DPIARiskHigh
+	case hi.rank() <= DPIARiskLow.rank():
+		return DPIARiskLow
+	default:
+		return DPIARiskMedium
+	}
+}
+
+// HighestDPIARisk returns the highest level among risks, LOW if none.
+func HighestDPIARisk(risks []DPIARisk) DPIARiskLevel {
+	highest := DPIARiskLow
+	for _, r := range risks {
+		if r.Level.rank() > highest.rank() {
+			highest = r.Level
+		}
+	}
+	return highest
+}
+
+// DPIARisk is one risk to data principals.
+type DPIARisk struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Likelihood  DPIARiskLevel `json:"likelihood"`
+	Impact      DPIARiskLevel `json:"impact"`
+	Level       DPIARiskLevel `json:"level"`
+}
+
+// DPIAMitigation is a measure addressing a risk.
+type DPIAMitigation struct {
+	Risk         string        `json:"risk"` // DPIARisk.Title
+	Measure      string        `json:"measure"`
+	ResidualRisk DPIARiskLevel `json:"residual_risk"`
+}
+
+// DPIADraftInfo records the AI draft a DPIA's narrative, risks and
+// mitigations were taken from.
+type DPIADraftInfo struct {
+	Provider      string    `json:"provider"`
+	Model         string    `json:"model"`
+	PromptVersion string    `json:"prompt_version"`
+	DraftedAt     time.Time `json:"drafted_at"`
+}
+
+// DPIAContext is the snapshot of the inventory a DPIA assesses, taken when
+// it is created or refreshed.
+type DPIAContext struct {
+	GeneratedAt           time.Time                  `json:"generated_at"`
+	Purposes              []DPIAPurpose              `json:"purposes"`
+	DataSources           []DPIADataSource           `json:"data_sources"`
+	DataCategories        []DPIADataCategory         `json:"data_categories"`
+	ThirdParties          []DPIAThirdParty           `json:"third_parties"`
+	CrossBorderTransfers  []DPIATransfer             `json:"cross_border_transfers"`
+	ReidentificationRisks []DPIAReidentificationRisk `json:"reidentification_risks"`
+}
+
+// DPIAPurpose is a snapshot of a Purpose for the DPIA.
+type DPIAPurpose struct {
+	ID              types.ID `json:"id"`
+	Name            string   `json:"name"`
+	LegalBasis      string   `json:"legal_basis"`
+	RequiresConsent bool     `json:"requires_consent"`
+}
+
+// DPIADataSource is a snapshot of a DataSource for the DPIA.
+type DPIADataSource struct {
+	ID   types.ID `json:"id"`
+	Name string   `json:"name"`
+	Type string   `json:"type"`
+}
+
+// DPIADataCategory counts the PII discovered in a category.
+type DPIADataCategory struct {
+	Category  string `json:"category"`
+	Fields    int    `json:"fields"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// DPIAThirdParty is a snapshot of a ThirdParty for the DPIA.
+type DPIAThirdParty struct {
+	ID        types.ID `json:"id"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Country   string   `json:"country"`
+	DPAStatus string   `json:"dpa_status"`
+}
+
+// DPIATransfer is a flow of personal data out of the tenant's country, to a
+// foreign third party or documented on a data mapping.
+type DPIATransfer struct {
+	Recipient      string `json:"recipient"`
+	Country        string `json:"country"`
+	LegalMechanism string `json:"legal_mechanism,omitempty"`
+	Safeguarded    bool   `json:"safeguarded"` // Signed DPA or approved mechanism
+}
+
+// DPIAReidentificationRisk is a dataset's assessed re-identification risk.
+type DPIAReidentificationRisk struct {
+	EntityID       types.ID `json:"entity_id"`
+	EntityName     string   `json:"entity_name"`
+	DataSourceName string   `json:"data_source_name"`
+	Score          float64  `json:"score"`
+	Level          string   `json:"level"`
+	K              int      `json:"k"`
+}
+
+// DPIAFilter narrows a DPIA listing.
+type DPIAFilter struct {
+	Status     *DPIAStatus
+	Pagination types.Pagination
+}
+
+// DPIARepository defines persistence for DPIAs.
+type DPIARepository interface {
+	Create(ctx context.Context, d *DPIA) error
+	GetByID(ctx context.Context, id types.ID) (*DPIA, error)
+	ListByTenant(ctx context.Context, tenantID types.ID, filter DPIAFilter) (*types.PaginatedResult[DPIA], error)
+	// GetApproved returns the tenant's approved DPIAs, including those
+	// awaiting periodic review.
+	GetApproved(ctx context.Context, tenantID types.ID) ([]DPIA, error)
+	// GetReviewsDue returns APPROVED DPIAs of every tenant whose next
+	// review is before the given time.
+	GetReviewsDue(ctx context.Context, before time.Time) ([]DPIA, error)
+	Update(ctx context.Context, d *DPIA) error
+}
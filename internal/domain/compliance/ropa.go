@@ -37,16 +37,18 @@ type RoPAContent struct {
 	RetentionPolicies []RoPARetention  `json:"retention_policies"`
 	ThirdParties      []RoPAThirdParty `json:"third_parties"`
 	DataCategories    []string         `json:"data_categories"`
+	DPIAs             []RoPADPIA       `json:"dpias,omitempty"`
 }
 
 // RoPAPurpose is a snapshot of a Purpose for the RoPA.
 type RoPAPurpose struct {
-	ID          types.ID `json:"id"`
-	Name        string   `json:"name"`
-	Code        string   `json:"code"`
-	LegalBasis  string   `json:"legal_basis"`
-	Description string   `json:"description"`
-	IsActive    bool     `json:"is_active"`
+	ID          types.ID   `json:"id"`
+	Name        string     `json:"name"`
+	Code        string     `json:"code"`
+	LegalBasis  string     `json:"legal_basis"`
+	Description string     `json:"description"`
+	IsActive    bool       `json:"is_active"`
+	DPIAIDs     []types.ID `json:"dpia_ids,omitempty"` // Approved DPIAs covering the purpose
 }
 
 // RoPADataSource is a snapshot of a DataSource for the RoPA.
@@ -74,6 +76,17 @@ type RoPAThirdParty struct {
 	Country string   `json:"country"`
 }
 
+// RoPADPIA is a snapshot of an approved DPIA for the RoPA.
+type RoPADPIA struct {
+	ID           types.ID      `json:"id"`
+	Title        string        `json:"title"`
+	RiskLevel    DPIARiskLevel `json:"risk_level"`
+	Status       DPIAStatus    `json:"status"`
+	ApprovedAt   *time.Time    `json:"approved_at,omitempty"`
+	NextReviewAt *time.Time    `json:"next_review_at,omitempty"`
+	PurposeIDs   []types.ID    `json:"purpose_ids"`
+}
+
 // RoPARepository defines persistence for RoPA versions.
 type RoPARepository interface {
 	Create(ctx context.Context, version *RoPAVersion) error
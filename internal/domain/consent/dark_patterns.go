@@ -0,0 +1,97 @@
+package consent
+
+import (
+	"context"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// WidgetAudit — Dark-pattern evaluation of a consent widget
+// =============================================================================
+
+// WidgetAudit is the evaluation of one config version of a consent widget
+// against the CCPA Guidelines for Prevention and Regulation of Dark
+// Patterns, 2023. It runs on every activation and config version bump;
+// HIGH findings block activation unless overridden.
+type WidgetAudit struct {
+	types.TenantEntity
+	WidgetID        types.ID             `json:"widget_id" db:"widget_id"`
+	WidgetVersion   int                  `json:"widget_version" db:"widget_version"`
+	Trigger         WidgetAuditTrigger   `json:"trigger" db:"trigger"`
+	Findings        []DarkPatternFinding `json:"findings" db:"findings"`
+	AIPromptVersion string               `json:"ai_prompt_version,omitempty" db:"ai_prompt_version"`
+	AIError         string               `json:"ai_error,omitempty" db:"ai_error"` // AI check failed; rule findings only
+	Override        *WidgetAuditOverride `json:"override,omitempty" db:"override"`
+}
+
+// WidgetAuditTrigger records what caused an audit.
+type WidgetAuditTrigger string
+
+const (
+	WidgetAuditActivation   WidgetAuditTrigger = "ACTIVATION"
+	WidgetAuditConfigUpdate WidgetAuditTrigger = "CONFIG_UPDATE"
+)
+
+// DarkPatternFinding is one dark pattern found in a widget.
+type DarkPatternFinding struct {
+	Check       string              `json:"check"`   // Rule code, or "AI"
+	Pattern     string              `json:"pattern"` // Guidelines Annexure 1 pattern, e.g. INTERFACE_INTERFERENCE
+	Source      FindingSource       `json:"source"`
+	Severity    DarkPatternSeverity `json:"severity"`
+	Message     string              `json:"message"`
+	CitedClause string              `json:"cited_clause,omitempty"`
+	Confidence  float64             `json:"confidence,omitempty"` // AI findings only
+}
+
+// FindingSource distinguishes deterministic rule findings from AI ones.
+type FindingSource string
+
+const (
+	FindingSourceRule FindingSource = "RULE"
+	FindingSourceAI   FindingSource = "AI"
+)
+
+// DarkPatternSeverity grades a finding. HIGH findings block activation.
+type DarkPatternSeverity string
+
+const (
+	DarkPatternSeverityLow    DarkPatternSeverity = "LOW"
+	DarkPatternSeverityMedium DarkPatternSeverity = "MEDIUM"
+	DarkPatternSeverityHigh   DarkPatternSeverity = "HIGH"
+)
+
+// WidgetAuditOverride records who activated a widget despite HIGH findings, and why.
+type WidgetAuditOverride struct {
+	Reason       string    `json:"reason"`
+	OverriddenBy *types.ID `json:"overridden_by,omitempty"`
+	OverriddenAt time.Time `json:"overridden_at"`
+}
+
+// HighFindings returns the findings that block activation.
+func (a *WidgetAudit) HighFindings() []DarkPatternFinding {
+	var out []DarkPatternFinding
+	for _, f := range a.Findings {
+		if f.Severity == DarkPatternSeverityHigh {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Blocks reports whether the audit blocks activation: it has HIGH
+// findings and has not been overridden.
+func (a *WidgetAudit) Blocks() bool {
+	return a.Override == nil && len(a.HighFindings()) > 0
+}
+
+// WidgetAuditRepository defines persistence for widget audits.
+type WidgetAuditRepository interface {
+	Create(ctx context.Context, a *WidgetAudit) error
+	// GetLatest returns the most recent audit of a widget.
+	GetLatest(ctx context.Context, widgetID types.ID) (*WidgetAudit, error)
+	// ListByWidget returns a widget's audits, newest first.
+	ListByWidget(ctx context.Context, widgetID types.ID) ([]WidgetAudit, error)
+	SetOverride(ctx context.Context, id types.ID, o *WidgetAuditOverride) error
+}
@@ -385,16 +385,41 @@ type ConsentNotice struct {
 	types.TenantEntity
 	SeriesID    types.ID           `json:"series_id" db:"series_id"` // Groups versions of the same notice
 	Title       string             `json:"title" db:"title"`
-	Content     string             `json:"content" db:"content"`       // Rich text (markdown/html)
-	Version     int                `json:"version" db:"version"`       // Auto-incremented on publish
-	Status      NoticeStatus       `json:"status" db:"status"`         // DRAFT, PUBLISHED, ARCHIVED
-	Purposes    []types.ID         `json:"purposes" db:"purposes"`     // Linked purpose IDs
-	WidgetIDs   []types.ID         `json:"widget_ids" db:"widget_ids"` // Bound widgets
-	Regulation  string             `json:"regulation" db:"regulation"` // e.g., "DPDPA_2023"
-	Schema      NoticeSchemaFields `json:"schema" db:"schema"`         // DPDP Rule 3(1) Schedule I fields
+	Content     string             `json:"content" db:"content"`                 // Rich text (markdown/html)
+	Version     int                `json:"version" db:"version"`                 // Auto-incremented on publish
+	Status      NoticeStatus       `json:"status" db:"status"`                   // DRAFT, PUBLISHED, ARCHIVED
+	Purposes    []types.ID         `json:"purposes" db:"purposes"`               // Linked purpose IDs
+	WidgetIDs   []types.ID         `json:"widget_ids" db:"widget_ids"`           // Bound widgets
+	Regulation  string             `json:"regulation" db:"regulation"`           // e.g., "DPDPA_2023"
+	Schema      NoticeSchemaFields `json:"schema" db:"schema"`                   // DPDP Rule 3(1) Schedule I fields
+	Generation  *NoticeGeneration  `json:"generation,omitempty" db:"generation"` // Set on notices generated from the RoPA
 	PublishedAt *time.Time         `json:"published_at,omitempty" db:"published_at"`
 }
 
+// NoticeGeneration records the RoPA version a notice was generated from and
+// whether the notice still matches the published RoPA.
+type NoticeGeneration struct {
+	RoPAVersionID  types.ID        `json:"ropa_version_id"`
+	RoPAVersion    string          `json:"ropa_version"`
+	Scope          []types.ID      `json:"scope,omitempty"` // Purposes generated for; empty: every active purpose
+	Basis          NoticeRoPABasis `json:"basis"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+	OutOfSync      bool            `json:"out_of_sync"`
+	StaleFields    []string        `json:"stale_fields,omitempty"`    // Basis fields the published RoPA changed
+	CheckedAt      *time.Time      `json:"checked_at,omitempty"`      // Last sync check against a published RoPA
+	CheckedAgainst string          `json:"checked_against,omitempty"` // RoPA version of the last sync check
+}
+
+// NoticeRoPABasis holds the notice fields derived from the RoPA. A notice
+// is out of sync when the published RoPA derives different values.
+type NoticeRoPABasis struct {
+	DataTypesCollected  []string `json:"data_types_collected"`
+	Purposes            []string `json:"purposes"`
+	SharingCategories   []string `json:"sharing_categories"`
+	CrossBorderTransfer string   `json:"cross_border_transfer"`
+	RetentionPeriod     string   `json:"retention_period"`
+}
+
 // NoticeSchemaFields defines the required fields for a privacy notice per DPDP Rule 3(1) Schedule I.
 type NoticeSchemaFields struct {
 	// 1. Data Types & Purposes
@@ -0,0 +1,72 @@
+package discovery
+
+import (
+	"context"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// DetectionCalibration — Per-tenant tuning learned from feedback
+// =============================================================================
+
+// DetectionCalibration holds the detector tuning learned from a tenant's
+// DetectionFeedback. It is recomputed by the calibration job and applied to
+// the tenant's next scan.
+type DetectionCalibration struct {
+	TenantID          types.ID                          `json:"tenant_id" db:"tenant_id"`
+	StrategyWeights   map[types.DetectionMethod]float64 `json:"strategy_weights" db:"strategy_weights"`
+	TypeThresholds    map[types.PIIType]float64         `json:"type_thresholds" db:"type_thresholds"`
+	SuppressedColumns []SuppressedColumn                `json:"suppressed_columns" db:"suppressed_columns"`
+	Report            CalibrationReport                 `json:"report" db:"report"`
+	CalibratedAt      time.Time                         `json:"calibrated_at" db:"calibrated_at"`
+}
+
+// SuppressedColumn is a normalized column name whose detections of a PII
+// type reviewers have repeatedly rejected and never verified.
+type SuppressedColumn struct {
+	Column     string        `json:"column"`
+	Type       types.PIIType `json:"type"`
+	Rejections int           `json:"rejections"`
+}
+
+// CalibrationReport shows how the calibration would have changed the
+// reviewed detections it was learned from.
+type CalibrationReport struct {
+	FeedbackCount    int                 `json:"feedback_count"`
+	PrecisionBefore  float64             `json:"precision_before"`
+	PrecisionAfter   float64             `json:"precision_after"`
+	Retained         int                 `json:"retained"`          // Reviewed detections still reported
+	RetainedVerified int                 `json:"retained_verified"` // ...of which reviewers verified
+	Methods          []MethodCalibration `json:"methods"`
+	Types            []TypeCalibration   `json:"types"`
+}
+
+// MethodCalibration summarizes feedback and the learned weight for one
+// detection method.
+type MethodCalibration struct {
+	Method    types.DetectionMethod `json:"method"`
+	Verified  int                   `json:"verified"`
+	Corrected int                   `json:"corrected"`
+	Rejected  int                   `json:"rejected"`
+	Precision float64               `json:"precision"`
+	Weight    float64               `json:"weight"`
+}
+
+// TypeCalibration summarizes feedback and the learned threshold for one
+// PII type.
+type TypeCalibration struct {
+	Type            types.PIIType `json:"type"`
+	Feedback        int           `json:"feedback"`
+	Threshold       float64       `json:"threshold"`
+	PrecisionBefore float64       `json:"precision_before"`
+	PrecisionAfter  float64       `json:"precision_after"`
+}
+
+// DetectionCalibrationRepository defines persistence for calibrations.
+// There is at most one calibration per tenant.
+type DetectionCalibrationRepository interface {
+	GetByTenant(ctx context.Context, tenantID types.ID) (*DetectionCalibration, error)
+	Upsert(ctx context.Context, c *DetectionCalibration) error
+}
@@ -2,6 +2,7 @@ package discovery
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -68,3 +69,53 @@ type ScannableConnector interface {
 	// This allows the connector to optimize traversal (e.g. streaming) and use internal detection.
 	Scan(ctx context.Context, ds *DataSource, onFinding func(PIIClassification)) error
 }
+
+// PathConnector is an optional interface for connectors that expand
+// semi-structured columns (Postgres JSONB, MongoDB subdocuments) into
+// virtual fields named by dotted path, e.g. "profile.contact.email".
+// Filters passed to any connector method may use such paths as keys.
+type PathConnector interface {
+	Connector
+	// DeletePaths removes the given nested paths from records matching the
+	// filter, leaving the rest of each record in place. Returns the number
+	// of records modified.
+	DeletePaths(ctx context.Context, entity string, paths []string, filter map[string]string) (int64, error)
+
+	// ExportPaths returns the values at the given nested paths for records
+	// matching the filter, keyed by path.
+	ExportPaths(ctx context.Context, entity string, paths []string, filter map[string]string) ([]map[string]interface{}, error)
+}
+
+// RowSampler is an optional interface for connectors that can sample whole
+// records rather than one field at a time, which analyses of value
+// combinations (such as re-identification risk) need.
+type RowSampler interface {
+	Connector
+	// SampleRows returns up to limit records with the given fields (columns
+	// or nested paths) as text. A NULL or missing value is an empty string.
+	SampleRows(ctx context.Context, entity string, fields []string, limit int) ([]map[string]string, error)
+}
+
+// KeyDiscoverer is an optional interface for connectors that can report
+// the primary and foreign keys declared in the source schema, from which
+// the subject graph is built.
+type KeyDiscoverer interface {
+	Connector
+	DiscoverKeys(ctx context.Context) (*SchemaKeys, error)
+}
+
+// FieldPathSeparator joins a column to the keys of a nested virtual field.
+const FieldPathSeparator = "."
+
+// IsNestedField reports whether a field name is a dotted path into a
+// semi-structured column rather than a column of its own.
+func IsNestedField(name string) bool {
+	return strings.Contains(name, FieldPathSeparator)
+}
+
+// SplitFieldPath splits a nested field name into its column and the keys
+// below it. For a plain column the key list is empty.
+func SplitFieldPath(name string) (column string, keys []string) {
+	parts := strings.Split(name, FieldPathSeparator)
+	return parts[0], parts[1:]
+}
@@ -0,0 +1,50 @@
+package discovery
+
+import (
+	"context"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// CustomDetectionRule — Tenant-defined PII identifiers
+// =============================================================================
+
+// RuleValidator narrows regex matches for a custom rule.
+type RuleValidator struct {
+	Checksum  string   `json:"checksum,omitempty"` // LUHN, VERHOEFF or MOD97
+	MinLength int      `json:"min_length,omitempty"`
+	MaxLength int      `json:"max_length,omitempty"`
+	Prefixes  []string `json:"prefixes,omitempty"`
+}
+
+// CustomDetectionRule lets a tenant detect identifiers that the built-in
+// strategies do not know about, such as customer IDs, policy numbers or
+// employee codes. Findings are reported with the PII type
+// "CUSTOM:<TypeLabel>".
+type CustomDetectionRule struct {
+	ID          types.ID               `json:"id" db:"id"`
+	TenantID    types.ID               `json:"tenant_id" db:"tenant_id"`
+	Name        string                 `json:"name" db:"name"`
+	Description string                 `json:"description,omitempty" db:"description"`
+	TypeLabel   string                 `json:"type_label" db:"type_label"`
+	Category    types.PIICategory      `json:"category" db:"category"`
+	Sensitivity types.SensitivityLevel `json:"sensitivity" db:"sensitivity"`
+	Pattern     string                 `json:"pattern,omitempty" db:"pattern"`
+	Validator   RuleValidator          `json:"validator" db:"validator"`
+	ColumnHints []string               `json:"column_hints" db:"column_hints"`
+	Enabled     bool                   `json:"enabled" db:"enabled"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// CustomDetectionRuleRepository defines persistence for custom rules.
+type CustomDetectionRuleRepository interface {
+	Create(ctx context.Context, rule *CustomDetectionRule) error
+	GetByID(ctx context.Context, tenantID, id types.ID) (*CustomDetectionRule, error)
+	GetByTenant(ctx context.Context, tenantID types.ID) ([]CustomDetectionRule, error)
+	GetEnabledByTenant(ctx context.Context, tenantID types.ID) ([]CustomDetectionRule, error)
+	Update(ctx context.Context, rule *CustomDetectionRule) error
+	Delete(ctx context.Context, tenantID, id types.ID) error
+}
@@ -65,7 +65,16 @@ type DataInventory struct {
 	TotalFields    int       `json:"total_fields" db:"total_fields"`
 	PIIFieldsCount int       `json:"pii_fields_count" db:"pii_fields_count"`
 	LastScannedAt  time.Time `json:"last_scanned_at" db:"last_scanned_at"`
-	SchemaVersion  string    `json:"schema_version" db:"schema_version"`
+	SchemaVersion  string    `json:"schema_version" db:"schema_version"` // Fingerprint of SchemaSnapshot
+
+	// SchemaSnapshot is the structure seen by the last scan; the next
+	// scan diffs against it to detect schema drift.
+	SchemaSnapshot SchemaSnapshot `json:"-" db:"schema_snapshot"`
+
+	// SubjectGraph maps how entities join back to a data subject; DSRs
+	// follow it to reach records keyed by internal IDs. Nil until a scan
+	// finds identifier fields.
+	SubjectGraph *SubjectGraph `json:"subject_graph,omitempty" db:"subject_graph"`
 }
 
 // =============================================================================
@@ -81,6 +90,10 @@ type DataEntity struct {
 	Type          EntityType `json:"type" db:"type"`
 	RowCount      *int64     `json:"row_count,omitempty" db:"row_count"`
 	PIIConfidence float64    `json:"pii_confidence" db:"pii_confidence"`
+
+	// ReidRisk is the latest re-identification assessment of the entity's
+	// quasi-identifiers, or nil if it has none or was never sampled.
+	ReidRisk *ReidentificationRisk `json:"reid_risk,omitempty" db:"reid_risk"`
 }
 
 // EntityType classifies the kind of data entity.
@@ -109,6 +122,7 @@ type DataField struct {
 	Nullable     bool     `json:"nullable" db:"nullable"`
 	IsPrimaryKey bool     `json:"is_primary_key" db:"is_primary_key"`
 	IsForeignKey bool     `json:"is_foreign_key" db:"is_foreign_key"`
+	NeedsReview  bool     `json:"needs_review" db:"needs_review"` // New since a previous scan; awaiting classification review
 }
 
 // =============================================================================
@@ -131,6 +145,7 @@ type PIIClassification struct {
 	VerifiedBy      *types.ID                `json:"verified_by,omitempty" db:"verified_by"`
 	VerifiedAt      *time.Time               `json:"verified_at,omitempty" db:"verified_at"`
 	Reasoning       string                   `json:"reasoning" db:"reasoning"`
+	PromptVersion   string                   `json:"prompt_version,omitempty" db:"prompt_version"` // AI prompt behind the classification, if any
 }
 
 // =============================================================================
@@ -140,15 +155,16 @@ type PIIClassification struct {
 // ScanRun tracks the execution of a scan against a data source.
 type ScanRun struct {
 	types.BaseEntity
-	DataSourceID types.ID   `json:"data_source_id" db:"data_source_id"`
-	TenantID     types.ID   `json:"tenant_id" db:"tenant_id"`
-	Type         ScanType   `json:"type" db:"type"`
-	Status       ScanStatus `json:"status" db:"status"`
-	Progress     int        `json:"progress" db:"progress"`
-	StartedAt    *time.Time `json:"started_at,omitempty" db:"started_at"`
-	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
-	Stats        ScanStats  `json:"stats" db:"stats"`
-	ErrorMessage *string    `json:"error_message,omitempty" db:"error_message"`
+	DataSourceID types.ID       `json:"data_source_id" db:"data_source_id"`
+	TenantID     types.ID       `json:"tenant_id" db:"tenant_id"`
+	Type         ScanType       `json:"type" db:"type"`
+	Status       ScanStatus     `json:"status" db:"status"`
+	Progress     int            `json:"progress" db:"progress"`
+	StartedAt    *time.Time     `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt  *time.Time     `json:"completed_at,omitempty" db:"completed_at"`
+	Stats        ScanStats      `json:"stats" db:"stats"`
+	Checkpoint   ScanCheckpoint `json:"checkpoint" db:"checkpoint"`
+	ErrorMessage *string        `json:"error_message,omitempty" db:"error_message"`
 }
 
 // ScanType classifies the kind of scan.
@@ -178,6 +194,45 @@ type ScanStats struct {
 	PIIDetected     int           `json:"pii_detected"`
 	Duration        time.Duration `json:"duration"`
 	BytesProcessed  int64         `json:"bytes_processed"`
+	SchemaChanges   int           `json:"schema_changes,omitempty"`
+
+	AIDetection *AIDetectionStats `json:"ai_detection,omitempty"`
+}
+
+// AIDetectionStats reports what table-level AI classification cost during a
+// scan and its estimated savings over one AI call per column.
+type AIDetectionStats struct {
+	Columns      int           `json:"columns"`
+	Calls        int           `json:"calls"`
+	TokensUsed   int           `json:"tokens_used"`
+	TokensSaved  int           `json:"tokens_saved"`
+	Latency      time.Duration `json:"latency"`
+	LatencySaved time.Duration `json:"latency_saved"`
+}
+
+// ScanCheckpoint records the progress of a scan run so that a redelivered
+// job can resume where the previous worker stopped instead of starting over.
+type ScanCheckpoint struct {
+	CompletedEntities []string   `json:"completed_entities"`
+	TotalEntities     int        `json:"total_entities"`
+	FieldsScanned     int        `json:"fields_scanned"`
+	PIIDetected       int        `json:"pii_detected"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+}
+
+// IsCompleted reports whether the named entity was already fully processed.
+func (c *ScanCheckpoint) IsCompleted(entity string) bool {
+	for _, name := range c.CompletedEntities {
+		if name == entity {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether the scan status is final.
+func (s ScanStatus) IsTerminal() bool {
+	return s == ScanStatusCompleted || s == ScanStatusFailed || s == ScanStatusCancelled
 }
 
 // =============================================================================
@@ -227,6 +282,16 @@ type ScanRunRepository interface {
 	GetActive(ctx context.Context, tenantID types.ID) ([]ScanRun, error)
 	GetRecent(ctx context.Context, tenantID types.ID, limit int) ([]ScanRun, error)
 	Update(ctx context.Context, run *ScanRun) error
+	// UpdateCheckpoint persists only the progress, stats and checkpoint of a
+	// RUNNING run. It reports false when the run is no longer running, so a
+	// checkpoint never overwrites a cancel made elsewhere.
+	UpdateCheckpoint(ctx context.Context, run *ScanRun) (bool, error)
+	// Finish records the outcome of a RUNNING run. It reports false when the
+	// run is no longer running, so a finishing scan never overwrites a cancel.
+	Finish(ctx context.Context, run *ScanRun) (bool, error)
+	// Cancel marks a PENDING or RUNNING run CANCELLED as of run.CompletedAt.
+	// It reports false when the run has already finished.
+	Cancel(ctx context.Context, run *ScanRun) (bool, error)
 }
 
 // DataInventoryRepository defines persistence for data inventories.
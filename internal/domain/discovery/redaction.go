@@ -0,0 +1,38 @@
+package discovery
+
+import (
+	"context"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// RedactionToken — Token vault of the PII redaction API
+// =============================================================================
+
+// RedactionToken is a pseudonym issued by the redaction API for a
+// reversible request, with the value it stands for. Tokens are
+// deterministic per tenant, so one value is stored once however often it
+// is redacted.
+type RedactionToken struct {
+	TenantID   types.ID      `json:"tenant_id" db:"tenant_id"`
+	Token      string        `json:"token" db:"token"` // e.g. [EMAIL_3f9a0c1b7d2e4a5b8c6d9e0f1a2b3c4d]
+	PIIType    types.PIIType `json:"pii_type" db:"pii_type"`
+	Ciphertext string        `json:"-" db:"ciphertext"` // Original value, encrypted with the tenant's key
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+	ExpiresAt  time.Time     `json:"expires_at" db:"expires_at"`
+}
+
+// RedactionTokenRepository defines persistence for the token vault.
+type RedactionTokenRepository interface {
+	// Save stores tokens. A token already stored keeps its value and the
+	// later of the two expiries.
+	Save(ctx context.Context, tokens []RedactionToken) error
+	// GetByTokens returns those of the given tokens the tenant holds that
+	// have not expired.
+	GetByTokens(ctx context.Context, tenantID types.ID, tokens []string) ([]RedactionToken, error)
+	// DeleteExpired removes tokens that expired before the given time and
+	// returns how many.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,297 @@
+package discovery
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// Re-identification Risk — k-anonymity over quasi-identifier combinations
+// =============================================================================
+
+// QuasiIdentifierKind classifies a field that does not identify a person on
+// its own but narrows them down in combination with others, e.g. date of
+// birth + PIN code + gender.
+type QuasiIdentifierKind string
+
+const (
+	QuasiIdentifierBirthDate     QuasiIdentifierKind = "BIRTH_DATE"
+	QuasiIdentifierAge           QuasiIdentifierKind = "AGE"
+	QuasiIdentifierGender        QuasiIdentifierKind = "GENDER"
+	QuasiIdentifierPostalCode    QuasiIdentifierKind = "POSTAL_CODE"
+	QuasiIdentifierLocation      QuasiIdentifierKind = "LOCATION"
+	QuasiIdentifierNationality   QuasiIdentifierKind = "NATIONALITY"
+	QuasiIdentifierOccupation    QuasiIdentifierKind = "OCCUPATION"
+	QuasiIdentifierMaritalStatus QuasiIdentifierKind = "MARITAL_STATUS"
+	QuasiIdentifierDemographic   QuasiIdentifierKind = "DEMOGRAPHIC"
+)
+
+// quasiIdentifierByType maps detected PII types that act as quasi-identifiers.
+var quasiIdentifierByType = map[types.PIIType]QuasiIdentifierKind{
+	types.PIITypeDOB:          QuasiIdentifierBirthDate,
+	types.PIITypeGender:       QuasiIdentifierGender,
+	types.PIITypeAddress:      QuasiIdentifierLocation,
+	types.PIITypeOrganization: QuasiIdentifierOccupation,
+}
+
+// quasiIdentifierByName matches column name tokens for quasi-identifiers
+// that are rarely classified as PII on their own.
+var quasiIdentifierByName = []struct {
+	kind   QuasiIdentifierKind
+	tokens []string
+}{
+	{QuasiIdentifierBirthDate, []string{"dob", "birthdate", "birthday", "birth"}},
+	{QuasiIdentifierAge, []string{"age", "age_band", "age_group"}},
+	{QuasiIdentifierGender, []string{"gender", "sex"}},
+	{QuasiIdentifierPostalCode, []string{"pin", "pincode", "zip", "zipcode", "postcode", "postal"}},
+	{QuasiIdentifierLocation, []string{"city", "district", "state", "town", "village", "region", "locality"}},
+	{QuasiIdentifierNationality, []string{"nationality", "citizenship", "country"}},
+	{QuasiIdentifierOccupation, []string{"occupation", "profession", "employer", "designation"}},
+	{QuasiIdentifierMaritalStatus, []string{"marital", "married"}},
+	{QuasiIdentifierDemographic, []string{"religion", "caste", "ethnicity", "race", "community", "education"}},
+}
+
+// ClassifyQuasiIdentifier returns the quasi-identifier kind of a field from
+// its detected PII type (empty if none) or, failing that, its name.
+func ClassifyQuasiIdentifier(fieldName string, piiType types.PIIType) (QuasiIdentifierKind, bool) {
+	if kind, ok := quasiIdentifierByType[piiType]; ok {
+		return kind, true
+	}
+
+	// Nested fields are matched on their leaf key.
+	_, keys := SplitFieldPath(fieldName)
+	name := fieldName
+	if len(keys) > 0 {
+		name = keys[len(keys)-1]
+	}
+	name = strings.ToLower(name)
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == ' ' })
+
+	for _, qi := range quasiIdentifierByName {
+		for _, token := range qi.tokens {
+			if name == token {
+				return qi.kind, true
+			}
+			for _, part := range parts {
+				if part == token {
+					return qi.kind, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// QuasiIdentifier is one field of an entity that takes part in the
+// re-identification analysis.
+type QuasiIdentifier struct {
+	Field string              `json:"field"`
+	Kind  QuasiIdentifierKind `json:"kind"`
+}
+
+// ReidentificationLevel buckets a risk score for prioritization.
+type ReidentificationLevel string
+
+const (
+	ReidentificationLevelLow    ReidentificationLevel = "LOW"
+	ReidentificationLevelMedium ReidentificationLevel = "MEDIUM"
+	ReidentificationLevelHigh   ReidentificationLevel = "HIGH"
+)
+
+// ReidentificationRisk is the result of analysing an entity's sampled rows.
+// Records sharing the same quasi-identifier values form an equivalence
+// class; a record in a class of size k has a 1/k chance of being singled out
+// by someone who knows those values (the prosecutor model).
+type ReidentificationRisk struct {
+	// Score is the average per-record risk over the sample, 0–100.
+	Score float64               `json:"score"`
+	Level ReidentificationLevel `json:"level"`
+
+	QuasiIdentifiers []QuasiIdentifier `json:"quasi_identifiers"`
+
+	// K is the smallest equivalence class over all quasi-identifiers; the
+	// sample is k-anonymous for this k.
+	K int `json:"k"`
+	// UniqueRatio is the share of sampled records that are alone in their
+	// equivalence class.
+	UniqueRatio float64 `json:"unique_ratio"`
+
+	// IdentifyingSets are the minimal combinations of quasi-identifiers
+	// that single out at least half of the sampled records. Pseudonymizing
+	// or generalizing one field from each set breaks it.
+	IdentifyingSets [][]string `json:"identifying_sets,omitempty"`
+
+	SampleSize int       `json:"sample_size"`
+	AssessedAt time.Time `json:"assessed_at"`
+}
+
+const (
+	// identifyingUniqueRatio is the share of unique records at which a
+	// combination counts as identifying.
+	identifyingUniqueRatio = 0.5
+
+	// maxQuasiIdentifiersForSets caps the fields whose combinations are
+	// enumerated (2^n subsets).
+	maxQuasiIdentifiersForSets = 10
+)
+
+// ReidentificationLevelFor maps a 0–100 score to a level.
+func ReidentificationLevelFor(score float64) ReidentificationLevel {
+	switch {
+	case score >= 50:
+		return ReidentificationLevelHigh
+	case score >= 20:
+		return ReidentificationLevelMedium
+	default:
+		return ReidentificationLevelLow
+	}
+}
+
+// AssessReidentification estimates re-identification risk from sampled
+// rows, keyed by field name. Values are compared case- and
+// whitespace-insensitively; a missing value is a value of its own. Returns
+// nil when there are no rows or no quasi-identifiers.
+//
+// Uniqueness in a sample overstates uniqueness in the full table, so the
+// score is a conservative (high) estimate for large tables.
+func AssessReidentification(rows []map[string]string, qis []QuasiIdentifier) *ReidentificationRisk {
+	if len(rows) == 0 || len(qis) == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(qis))
+	for i, qi := range qis {
+		fields[i] = qi.Field
+	}
+
+	classes := equivalenceClasses(rows, fields)
+	k := len(rows)
+	unique := 0
+	for _, size := range classes {
+		if size < k {
+			k = size
+		}
+		if size == 1 {
+			unique++
+		}
+	}
+
+	// Each of a class's records carries risk 1/size, so the risks sum to
+	// the number of classes and the average is classes/rows.
+	score := math.Round(float64(len(classes))/float64(len(rows))*1000) / 10
+	return &ReidentificationRisk{
+		Score:            score,
+		Level:            ReidentificationLevelFor(score),
+		QuasiIdentifiers: qis,
+		K:                k,
+		UniqueRatio:      math.Round(float64(unique)/float64(len(rows))*1000) / 1000,
+		IdentifyingSets:  identifyingSets(rows, fields),
+		SampleSize:       len(rows),
+		AssessedAt:       time.Now().UTC(),
+	}
+}
+
+// equivalenceClasses returns the number of rows per distinct combination of
+// the given fields' values.
+func equivalenceClasses(rows []map[string]string, fields []string) map[string]int {
+	classes := make(map[string]int)
+	var key strings.Builder
+	for _, row := range rows {
+		key.Reset()
+		for _, f := range fields {
+			key.WriteString(strings.ToLower(strings.TrimSpace(row[f])))
+			key.WriteByte(0)
+		}
+		classes[key.String()]++
+	}
+	return classes
+}
+
+// identifyingSets finds the minimal field combinations whose unique-record
+// share reaches identifyingUniqueRatio, smallest first.
+func identifyingSets(rows []map[string]string, fields []string) [][]string {
+	if len(fields) > maxQuasiIdentifiersForSets {
+		fields = fields[:maxQuasiIdentifiersForSets]
+	}
+
+	var masks []int
+	for mask := 1; mask < 1<<len(fields); mask++ {
+		masks = append(masks, mask)
+	}
+	// Smaller sets first, so supersets of an identifying set are skipped.
+	sort.SliceStable(masks, func(i, j int) bool { return popcount(masks[i]) < popcount(masks[j]) })
+
+	var found []int
+	var sets [][]string
+	for _, mask := range masks {
+		minimal := true
+		for _, f := range found {
+			if mask&f == f {
+				minimal = false
+				break
+			}
+		}
+		if !minimal {
+			continue
+		}
+
+		var subset []string
+		for i, f := range fields {
+			if mask&(1<<i) != 0 {
+				subset = append(subset, f)
+			}
+		}
+		unique := 0
+		for _, size := range equivalenceClasses(rows, subset) {
+			if size == 1 {
+				unique++
+			}
+		}
+		if float64(unique)/float64(len(rows)) >= identifyingUniqueRatio {
+			found = append(found, mask)
+			sets = append(sets, subset)
+		}
+	}
+	return sets
+}
+
+func popcount(n int) int {
+	c := 0
+	for ; n > 0; n &= n - 1 {
+		c++
+	}
+	return c
+}
+
+// EntityReidentificationRisk is an entity's latest assessment with enough
+// context to locate it, as listed on the dashboard and used by policies.
+type EntityReidentificationRisk struct {
+	EntityID       types.ID             `json:"entity_id"`
+	EntityName     string               `json:"entity_name"`
+	DataSourceID   types.ID             `json:"data_source_id"`
+	DataSourceName string               `json:"data_source_name"`
+	Risk           ReidentificationRisk `json:"risk"`
+}
+
+// HasQuasiIdentifier reports whether field is one of the assessed
+// quasi-identifiers.
+func (r *ReidentificationRisk) HasQuasiIdentifier(field string) bool {
+	for _, qi := range r.QuasiIdentifiers {
+		if qi.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ReidentificationRiskRepository lists stored assessments across a tenant.
+type ReidentificationRiskRepository interface {
+	// GetReidentificationRisks returns assessed entities with a score of at
+	// least minScore, highest first. A limit of 0 returns all.
+	GetReidentificationRisks(ctx context.Context, tenantID types.ID, minScore float64, limit int) ([]EntityReidentificationRisk, error)
+}
@@ -0,0 +1,86 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+func TestClassifyQuasiIdentifier(t *testing.T) {
+	tests := []struct {
+		field   string
+		piiType types.PIIType
+		want    QuasiIdentifierKind
+		ok      bool
+	}{
+		{"date_of_birth", types.PIITypeDOB, QuasiIdentifierBirthDate, true},
+		{"pin_code", "", QuasiIdentifierPostalCode, true},
+		{"ZIP", "", QuasiIdentifierPostalCode, true},
+		{"profile.address.city", "", QuasiIdentifierLocation, true},
+		{"marital_status", "", QuasiIdentifierMaritalStatus, true},
+		{"age_band", "", QuasiIdentifierAge, true},
+		{"email", types.PIITypeEmail, "", false},
+		{"pinned", "", "", false},
+		{"order_total", "", "", false},
+	}
+	for _, tt := range tests {
+		kind, ok := ClassifyQuasiIdentifier(tt.field, tt.piiType)
+		assert.Equal(t, tt.ok, ok, tt.field)
+		assert.Equal(t, tt.want, kind, tt.field)
+	}
+}
+
+func TestAssessReidentification(t *testing.T) {
+	qis := []QuasiIdentifier{
+		{Field: "dob", Kind: QuasiIdentifierBirthDate},
+		{Field: "pin", Kind: QuasiIdentifierPostalCode},
+		{Field: "gender", Kind: QuasiIdentifierGender},
+	}
+	rows := []map[string]string{
+		{"dob": "1990-01-01", "pin": "560001", "gender": "F"},
+		{"dob": "1990-01-01", "pin": "560001", "gender": "f "},
+		{"dob": "1985-06-12", "pin": "560001", "gender": "M"},
+		{"dob": "1972-03-30", "pin": "110092", "gender": "M"},
+		{"dob": "1972-03-30", "pin": "110092"},
+	}
+
+	risk := AssessReidentification(rows, qis)
+	require.NotNil(t, risk)
+
+	// Classes: {1,2} (case-insensitive), {3}, {4}, {5} (missing gender).
+	assert.Equal(t, 1, risk.K)
+	assert.Equal(t, 0.6, risk.UniqueRatio)
+	assert.Equal(t, 80.0, risk.Score)
+	assert.Equal(t, ReidentificationLevelHigh, risk.Level)
+	assert.Equal(t, 5, risk.SampleSize)
+
+	// No single field singles out half the records, and dob+pin does
+	// not either; each pair with gender does.
+	assert.Equal(t, [][]string{{"dob", "gender"}, {"pin", "gender"}}, risk.IdentifyingSets)
+
+	assert.True(t, risk.HasQuasiIdentifier("pin"))
+	assert.False(t, risk.HasQuasiIdentifier("email"))
+}
+
+func TestAssessReidentification_KAnonymous(t *testing.T) {
+	qis := []QuasiIdentifier{{Field: "city", Kind: QuasiIdentifierLocation}}
+	var rows []map[string]string
+	for i := 0; i < 10; i++ {
+		rows = append(rows, map[string]string{"city": []string{"Pune", "Delhi"}[i%2]})
+	}
+
+	risk := AssessReidentification(rows, qis)
+	require.NotNil(t, risk)
+	assert.Equal(t, 5, risk.K)
+	assert.Equal(t, 20.0, risk.Score)
+	assert.Equal(t, ReidentificationLevelMedium, risk.Level)
+	assert.Empty(t, risk.IdentifyingSets)
+}
+
+func TestAssessReidentification_Empty(t *testing.T) {
+	assert.Nil(t, AssessReidentification(nil, []QuasiIdentifier{{Field: "dob"}}))
+	assert.Nil(t, AssessReidentification([]map[string]string{{"dob": "x"}}, nil))
+}
@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// SchemaSnapshot — Structural view of an inventory used for drift detection
+// =============================================================================
+
+// SchemaSnapshot maps entity name → field name → data type.
+type SchemaSnapshot map[string]map[string]string
+
+// Fingerprint returns a stable hash of the snapshot's structure. It is
+// stored in DataInventory.SchemaVersion so unchanged schemas can be
+// recognised without a full diff.
+func (s SchemaSnapshot) Fingerprint() string {
+	h := sha256.New()
+	for _, entity := range sortedKeys(s) {
+		h.Write([]byte(entity))
+		h.Write([]byte{0})
+		fields := s[entity]
+		for _, field := range sortedKeys(fields) {
+			h.Write([]byte(field))
+			h.Write([]byte{1})
+			h.Write([]byte(fields[field]))
+			h.Write([]byte{2})
+		}
+		h.Write([]byte{3})
+	}
+	// 32 hex chars fits the schema_version column.
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// HasField reports whether the snapshot contains the given entity field.
+func (s SchemaSnapshot) HasField(entity, field string) bool {
+	fields, ok := s[entity]
+	if !ok {
+		return false
+	}
+	_, ok = fields[field]
+	return ok
+}
+
+// =============================================================================
+// SchemaDrift — Differences between two scans of the same data source
+// =============================================================================
+
+// SchemaDrift describes structural changes detected between two scans.
+type SchemaDrift struct {
+	DataSourceID    types.ID      `json:"data_source_id"`
+	DataSourceName  string        `json:"data_source_name"`
+	PreviousVersion string        `json:"previous_version"`
+	CurrentVersion  string        `json:"current_version"`
+	AddedEntities   []string      `json:"added_entities,omitempty"`
+	RemovedEntities []string      `json:"removed_entities,omitempty"`
+	AddedFields     []FieldChange `json:"added_fields,omitempty"`
+	RemovedFields   []FieldChange `json:"removed_fields,omitempty"`
+	RetypedFields   []FieldChange `json:"retyped_fields,omitempty"`
+	DetectedAt      time.Time     `json:"detected_at"`
+}
+
+// FieldChange describes a single added, removed, or retyped field.
+// PIIType is set on added fields that were classified as PII during the
+// scan that discovered them.
+type FieldChange struct {
+	Entity  string        `json:"entity"`
+	Field   string        `json:"field"`
+	OldType string        `json:"old_type,omitempty"`
+	NewType string        `json:"new_type,omitempty"`
+	PIIType types.PIIType `json:"pii_type,omitempty"`
+}
+
+// HasChanges reports whether any structural change was found.
+func (d *SchemaDrift) HasChanges() bool {
+	return d.ChangeCount() > 0
+}
+
+// ChangeCount returns the total number of individual changes.
+func (d *SchemaDrift) ChangeCount() int {
+	return len(d.AddedEntities) + len(d.RemovedEntities) +
+		len(d.AddedFields) + len(d.RemovedFields) + len(d.RetypedFields)
+}
+
+// DiffSchemas compares two snapshots. Fields of newly added entities are
+// reported in AddedFields so they can be flagged for classification;
+// fields of removed entities are only reported via RemovedEntities.
+func DiffSchemas(previous, current SchemaSnapshot) SchemaDrift {
+	drift := SchemaDrift{
+		PreviousVersion: previous.Fingerprint(),
+		CurrentVersion:  current.Fingerprint(),
+		DetectedAt:      time.Now().UTC(),
+	}
+
+	for _, entity := range sortedKeys(current) {
+		curFields := current[entity]
+		prevFields, existed := previous[entity]
+		if !existed {
+			drift.AddedEntities = append(drift.AddedEntities, entity)
+		}
+
+		for _, field := range sortedKeys(curFields) {
+			newType := curFields[field]
+			oldType, ok := prevFields[field]
+			switch {
+			case !ok:
+				drift.AddedFields = append(drift.AddedFields, FieldChange{Entity: entity, Field: field, NewType: newType})
+			case oldType != newType:
+				drift.RetypedFields = append(drift.RetypedFields, FieldChange{Entity: entity, Field: field, OldType: oldType, NewType: newType})
+			}
+		}
+
+		for _, field := range sortedKeys(prevFields) {
+			if _, ok := curFields[field]; !ok {
+				drift.RemovedFields = append(drift.RemovedFields, FieldChange{Entity: entity, Field: field, OldType: prevFields[field]})
+			}
+		}
+	}
+
+	for _, entity := range sortedKeys(previous) {
+		if _, ok := current[entity]; !ok {
+			drift.RemovedEntities = append(drift.RemovedEntities, entity)
+		}
+	}
+
+	return drift
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
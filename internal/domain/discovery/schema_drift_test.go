@@ -0,0 +1,57 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaSnapshot_Fingerprint_StableAcrossMapOrder(t *testing.T) {
+	a := SchemaSnapshot{
+		"users":  {"id": "uuid", "email": "varchar"},
+		"orders": {"id": "uuid", "total": "numeric"},
+	}
+	b := SchemaSnapshot{
+		"orders": {"total": "numeric", "id": "uuid"},
+		"users":  {"email": "varchar", "id": "uuid"},
+	}
+
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+	assert.Len(t, a.Fingerprint(), 32)
+
+	b["users"]["email"] = "text"
+	assert.NotEqual(t, a.Fingerprint(), b.Fingerprint())
+}
+
+func TestDiffSchemas(t *testing.T) {
+	previous := SchemaSnapshot{
+		"users":    {"id": "uuid", "email": "varchar", "legacy_flag": "bool"},
+		"sessions": {"id": "uuid"},
+	}
+	current := SchemaSnapshot{
+		"users":    {"id": "uuid", "email": "text", "phone": "varchar"},
+		"payments": {"id": "uuid", "card_number": "varchar"},
+	}
+
+	drift := DiffSchemas(previous, current)
+
+	assert.True(t, drift.HasChanges())
+	assert.Equal(t, []string{"payments"}, drift.AddedEntities)
+	assert.Equal(t, []string{"sessions"}, drift.RemovedEntities)
+	assert.Equal(t, []FieldChange{
+		{Entity: "payments", Field: "card_number", NewType: "varchar"},
+		{Entity: "payments", Field: "id", NewType: "uuid"},
+		{Entity: "users", Field: "phone", NewType: "varchar"},
+	}, drift.AddedFields)
+	assert.Equal(t, []FieldChange{{Entity: "users", Field: "legacy_flag", OldType: "bool"}}, drift.RemovedFields)
+	assert.Equal(t, []FieldChange{{Entity: "users", Field: "email", OldType: "varchar", NewType: "text"}}, drift.RetypedFields)
+	assert.Equal(t, 7, drift.ChangeCount())
+	assert.Equal(t, previous.Fingerprint(), drift.PreviousVersion)
+	assert.Equal(t, current.Fingerprint(), drift.CurrentVersion)
+}
+
+func TestDiffSchemas_NoChanges(t *testing.T) {
+	snap := SchemaSnapshot{"users": {"id": "uuid"}}
+	drift := DiffSchemas(snap, SchemaSnapshot{"users": {"id": "uuid"}})
+	assert.False(t, drift.HasChanges())
+}
@@ -0,0 +1,340 @@
+package discovery
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// Subject Graph — Which entities hold a person's data and how they join
+// =============================================================================
+
+// SubjectIdentifierTypes are the PII types that identify a data subject on
+// their own and so can start a DSR lookup.
+var SubjectIdentifierTypes = map[types.PIIType]bool{
+	types.PIITypeEmail:      true,
+	types.PIITypePhone:      true,
+	types.PIITypeAadhaar:    true,
+	types.PIITypePAN:        true,
+	types.PIITypePassport:   true,
+	types.PIITypeSSN:        true,
+	types.PIITypeNationalID: true,
+}
+
+// SubjectLinkSource records how a link between two entities was found.
+type SubjectLinkSource string
+
+const (
+	// SubjectLinkForeignKey is a foreign key declared in the source schema.
+	SubjectLinkForeignKey SubjectLinkSource = "FOREIGN_KEY"
+	// SubjectLinkValueOverlap is inferred from sampled values of one column
+	// appearing in a key column of another entity.
+	SubjectLinkValueOverlap SubjectLinkSource = "VALUE_OVERLAP"
+)
+
+// SubjectRoot is an identifier field a DSR can match a subject on directly.
+type SubjectRoot struct {
+	Entity  string        `json:"entity"`
+	Field   string        `json:"field"`
+	PIIType types.PIIType `json:"pii_type"`
+}
+
+// SubjectLink says that ChildEntity records whose ChildField equals the
+// ParentField of a subject's ParentEntity record belong to the same subject,
+// e.g. orders.user_id → users.id.
+type SubjectLink struct {
+	ParentEntity string            `json:"parent_entity"`
+	ParentField  string            `json:"parent_field"`
+	ChildEntity  string            `json:"child_entity"`
+	ChildField   string            `json:"child_field"`
+	Source       SubjectLinkSource `json:"source"`
+	Confidence   float64           `json:"confidence"`
+}
+
+// String renders the link as "parent.field → child.field".
+func (l SubjectLink) String() string {
+	return l.ParentEntity + "." + l.ParentField + " → " + l.ChildEntity + "." + l.ChildField
+}
+
+// SubjectGraph maps how a data source's entities reach a data subject:
+// roots are matched on the subject's identifiers, and links lead from there
+// to dependent entities that hold the subject's data without an identifier
+// of their own. Only links reachable from a root are kept.
+type SubjectGraph struct {
+	Roots   []SubjectRoot `json:"roots"`
+	Links   []SubjectLink `json:"links"`
+	BuiltAt time.Time     `json:"built_at"`
+}
+
+// BuildSubjectGraph assembles a graph from identifier roots and candidate
+// links. Duplicate links keep the most trusted one (declared keys first,
+// then confidence); links within one entity and links not reachable from a
+// root entity are dropped. Returns nil when there are no roots.
+func BuildSubjectGraph(roots []SubjectRoot, links []SubjectLink) *SubjectGraph {
+	if len(roots) == 0 {
+		return nil
+	}
+
+	type linkKey struct{ pe, pf, ce, cf string }
+	best := make(map[linkKey]SubjectLink)
+	for _, l := range links {
+		if l.ParentEntity == l.ChildEntity {
+			continue
+		}
+		k := linkKey{l.ParentEntity, l.ParentField, l.ChildEntity, l.ChildField}
+		if cur, ok := best[k]; !ok || linkRank(l) > linkRank(cur) {
+			best[k] = l
+		}
+	}
+
+	children := make(map[string][]SubjectLink)
+	for _, l := range best {
+		children[l.ParentEntity] = append(children[l.ParentEntity], l)
+	}
+
+	reached := make(map[string]bool)
+	var queue []string
+	for _, r := range roots {
+		if !reached[r.Entity] {
+			reached[r.Entity] = true
+			queue = append(queue, r.Entity)
+		}
+	}
+	var kept []SubjectLink
+	for len(queue) > 0 {
+		entity := queue[0]
+		queue = queue[1:]
+		for _, l := range children[entity] {
+			kept = append(kept, l)
+			if !reached[l.ChildEntity] {
+				reached[l.ChildEntity] = true
+				queue = append(queue, l.ChildEntity)
+			}
+		}
+	}
+
+	roots = append([]SubjectRoot(nil), roots...)
+	sort.Slice(roots, func(i, j int) bool {
+		if roots[i].Entity != roots[j].Entity {
+			return roots[i].Entity < roots[j].Entity
+		}
+		return roots[i].Field < roots[j].Field
+	})
+	sort.Slice(kept, func(i, j int) bool { return kept[i].String() < kept[j].String() })
+
+	return &SubjectGraph{Roots: roots, Links: kept, BuiltAt: time.Now().UTC()}
+}
+
+// linkRank orders duplicate links: declared keys beat inferred ones, then
+// higher confidence wins.
+func linkRank(l SubjectLink) float64 {
+	if l.Source == SubjectLinkForeignKey {
+		return 2 + l.Confidence
+	}
+	return l.Confidence
+}
+
+// Children returns the links leading out of entity.
+func (g *SubjectGraph) Children(entity string) []SubjectLink {
+	if g == nil {
+		return nil
+	}
+	var out []SubjectLink
+	for _, l := range g.Links {
+		if l.ParentEntity == entity {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// =============================================================================
+// Key Discovery — Declared keys and value-overlap inference
+// =============================================================================
+
+// ForeignKey is a declared reference from Entity.Field to
+// RefEntity.RefField. Composite keys are reported one column pair at a time.
+type ForeignKey struct {
+	Entity    string `json:"entity"`
+	Field     string `json:"field"`
+	RefEntity string `json:"ref_entity"`
+	RefField  string `json:"ref_field"`
+}
+
+// Link returns the foreign key as a subject link from the referenced
+// entity to the referencing one.
+func (fk ForeignKey) Link() SubjectLink {
+	return SubjectLink{
+		ParentEntity: fk.RefEntity,
+		ParentField:  fk.RefField,
+		ChildEntity:  fk.Entity,
+		ChildField:   fk.Field,
+		Source:       SubjectLinkForeignKey,
+		Confidence:   1,
+	}
+}
+
+// SchemaKeys are the keys declared in a data source's schema.
+type SchemaKeys struct {
+	// PrimaryKeys maps entity name to its primary key columns.
+	PrimaryKeys map[string][]string `json:"primary_keys"`
+	ForeignKeys []ForeignKey        `json:"foreign_keys"`
+}
+
+// KeyColumn is a column considered for value-overlap inference, with its
+// detected PII type if any.
+type KeyColumn struct {
+	Entity  string        `json:"entity"`
+	Field   string        `json:"field"`
+	PIIType types.PIIType `json:"pii_type,omitempty"`
+}
+
+// LinkCandidate pairs a key column with a column of another entity that may
+// reference it. NameMatch is set when the child's name points at the parent,
+// as "user_id" does at users.id or "customer_email" at customers.email.
+type LinkCandidate struct {
+	Parent    KeyColumn
+	Child     KeyColumn
+	NameMatch bool
+}
+
+const (
+	// overlapMinValues is the fewest distinct child values needed to judge
+	// an overlap.
+	overlapMinValues = 3
+	// overlapNameMatch and overlapTypeMatch are the shares of child values
+	// that must be found among the parent's for a candidate to become a
+	// link. Candidates paired only by PII type need stronger evidence.
+	overlapNameMatch = 0.5
+	overlapTypeMatch = 0.9
+)
+
+// FindLinkCandidates pairs parent key columns with child columns of other
+// entities whose name points at the parent or that carry the same PII type.
+// Other pairs are not worth sampling: integer keys of unrelated tables
+// overlap by chance.
+func FindLinkCandidates(parents, children []KeyColumn) []LinkCandidate {
+	var out []LinkCandidate
+	for _, c := range children {
+		for _, p := range parents {
+			if p.Entity == c.Entity {
+				continue
+			}
+			nameMatch := referencesByName(c.Field, p.Entity, p.Field)
+			if !nameMatch && (p.PIIType == "" || p.PIIType != c.PIIType) {
+				continue
+			}
+			out = append(out, LinkCandidate{Parent: p, Child: c, NameMatch: nameMatch})
+		}
+	}
+	return out
+}
+
+// InferOverlapLink turns a candidate into a link when enough of the child's
+// sampled values appear among the parent's. Values are compared trimmed and
+// case-insensitively.
+func InferOverlapLink(c LinkCandidate, parentValues, childValues []string) (SubjectLink, bool) {
+	parent := make(map[string]bool, len(parentValues))
+	for _, v := range parentValues {
+		if v = normalizeKeyValue(v); v != "" {
+			parent[v] = true
+		}
+	}
+	distinct := make(map[string]bool, len(childValues))
+	for _, v := range childValues {
+		if v = normalizeKeyValue(v); v != "" {
+			distinct[v] = true
+		}
+	}
+	if len(distinct) < overlapMinValues || len(parent) == 0 {
+		return SubjectLink{}, false
+	}
+
+	found := 0
+	for v := range distinct {
+		if parent[v] {
+			found++
+		}
+	}
+	overlap := float64(found) / float64(len(distinct))
+
+	threshold, weight := overlapTypeMatch, 0.8
+	if c.NameMatch {
+		threshold, weight = overlapNameMatch, 1.0
+	}
+	if overlap < threshold {
+		return SubjectLink{}, false
+	}
+	return SubjectLink{
+		ParentEntity: c.Parent.Entity,
+		ParentField:  c.Parent.Field,
+		ChildEntity:  c.Child.Entity,
+		ChildField:   c.Child.Field,
+		Source:       SubjectLinkValueOverlap,
+		Confidence:   math.Round(overlap*weight*100) / 100,
+	}, true
+}
+
+// IsKeyLikeField reports whether a column name looks like a key or a
+// reference to one: "id", "_id", "user_id", "customerId".
+func IsKeyLikeField(name string) bool {
+	lower := strings.ToLower(leafName(name))
+	return lower == "id" || lower == "_id" || strings.HasSuffix(lower, "_id") ||
+		(len(name) > 2 && strings.HasSuffix(leafName(name), "Id"))
+}
+
+// referencesByName reports whether a child column's name refers to the
+// parent column: the same non-generic name, or the parent entity's
+// singular name joined to the parent field ("user_id", "userid",
+// "customer_email").
+func referencesByName(child, parentEntity, parentField string) bool {
+	c := strings.ToLower(leafName(child))
+	pf := strings.ToLower(leafName(parentField))
+	if c == pf {
+		return pf != "id" && pf != "_id"
+	}
+
+	entity := strings.ToLower(parentEntity)
+	if i := strings.LastIndex(entity, "."); i >= 0 {
+		entity = entity[i+1:]
+	}
+	stem := singular(entity)
+	pf = strings.TrimPrefix(pf, "_")
+	for _, name := range []string{stem + "_" + pf, stem + pf, entity + "_" + pf} {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// singular strips a plain English plural: "users" → "user", "categories"
+// → "category", "addresses" → "address".
+func singular(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "ss"):
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// leafName returns the last key of a nested field path.
+func leafName(field string) string {
+	_, keys := SplitFieldPath(field)
+	if len(keys) > 0 {
+		return keys[len(keys)-1]
+	}
+	return field
+}
+
+func normalizeKeyValue(v string) string {
+	return strings.ToLower(strings.TrimSpace(v))
+}
@@ -0,0 +1,118 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+func TestBuildSubjectGraph(t *testing.T) {
+	roots := []SubjectRoot{{Entity: "users", Field: "email", PIIType: types.PIITypeEmail}}
+	links := []SubjectLink{
+		{ParentEntity: "users", ParentField: "id", ChildEntity: "orders", ChildField: "user_id", Source: SubjectLinkValueOverlap, Confidence: 0.9},
+		ForeignKey{Entity: "orders", Field: "user_id", RefEntity: "users", RefField: "id"}.Link(),
+		{ParentEntity: "orders", ParentField: "id", ChildEntity: "order_items", ChildField: "order_id", Source: SubjectLinkForeignKey, Confidence: 1},
+		// Unreachable from users: a product is not the subject's data.
+		{ParentEntity: "products", ParentField: "id", ChildEntity: "order_items", ChildField: "product_id", Source: SubjectLinkForeignKey, Confidence: 1},
+		// Self references are dropped.
+		{ParentEntity: "users", ParentField: "id", ChildEntity: "users", ChildField: "referrer_id", Source: SubjectLinkForeignKey, Confidence: 1},
+	}
+
+	g := BuildSubjectGraph(roots, links)
+	require.NotNil(t, g)
+	require.Len(t, g.Links, 2)
+	assert.Equal(t, "orders.id → order_items.order_id", g.Links[0].String())
+	assert.Equal(t, "users.id → orders.user_id", g.Links[1].String())
+	assert.Equal(t, SubjectLinkForeignKey, g.Links[1].Source, "declared key wins over inferred duplicate")
+
+	assert.Len(t, g.Children("users"), 1)
+	assert.Empty(t, g.Children("order_items"))
+
+	assert.Nil(t, BuildSubjectGraph(nil, links))
+	var none *SubjectGraph
+	assert.Nil(t, none.Children("users"))
+}
+
+func TestFindLinkCandidates(t *testing.T) {
+	parents := []KeyColumn{
+		{Entity: "users", Field: "id"},
+		{Entity: "users", Field: "email", PIIType: types.PIITypeEmail},
+		{Entity: "categories", Field: "id"},
+	}
+	children := []KeyColumn{
+		{Entity: "orders", Field: "user_id"},
+		{Entity: "orders", Field: "contact_email", PIIType: types.PIITypeEmail},
+		{Entity: "products", Field: "category_id"},
+		{Entity: "audit_log", Field: "session_id"},
+	}
+
+	got := make(map[string]bool)
+	for _, c := range FindLinkCandidates(parents, children) {
+		got[c.Parent.Entity+"."+c.Parent.Field+" → "+c.Child.Entity+"."+c.Child.Field] = c.NameMatch
+	}
+	assert.Equal(t, map[string]bool{
+		"users.id → orders.user_id":            true,
+		"users.email → orders.contact_email":   false,
+		"categories.id → products.category_id": true,
+	}, got)
+}
+
+func TestInferOverlapLink(t *testing.T) {
+	byName := LinkCandidate{
+		Parent:    KeyColumn{Entity: "users", Field: "id"},
+		Child:     KeyColumn{Entity: "orders", Field: "user_id"},
+		NameMatch: true,
+	}
+	link, ok := InferOverlapLink(byName, []string{"1", "2", "3", "4"}, []string{"1", "2", "2", "9"})
+	require.True(t, ok)
+	assert.Equal(t, SubjectLinkValueOverlap, link.Source)
+	assert.Equal(t, 0.67, link.Confidence)
+
+	_, ok = InferOverlapLink(byName, []string{"1", "2"}, []string{"7", "8", "9"})
+	assert.False(t, ok, "no overlap")
+	_, ok = InferOverlapLink(byName, []string{"1", "2"}, []string{"1", "2"})
+	assert.False(t, ok, "too few values to judge")
+
+	byType := LinkCandidate{
+		Parent: KeyColumn{Entity: "users", Field: "email", PIIType: types.PIITypeEmail},
+		Child:  KeyColumn{Entity: "orders", Field: "contact_email", PIIType: types.PIITypeEmail},
+	}
+	parents := []string{"a@x.in", "b@x.in", "c@x.in", "d@x.in"}
+	link, ok = InferOverlapLink(byType, parents, []string{" A@x.in", "b@x.in", "c@x.in"})
+	require.True(t, ok)
+	assert.Equal(t, 0.8, link.Confidence)
+	_, ok = InferOverlapLink(byType, parents, []string{"a@x.in", "b@x.in", "z@x.in"})
+	assert.False(t, ok, "type-only candidates need near-complete overlap")
+}
+
+func TestReferencesByName(t *testing.T) {
+	tests := []struct {
+		child, parentEntity, parentField string
+		want                             bool
+	}{
+		{"user_id", "users", "id", true},
+		{"userid", "users", "id", true},
+		{"category_id", "categories", "id", true},
+		{"address_id", "public.addresses", "id", true},
+		{"customer_email", "customers", "email", true},
+		{"profile.user_id", "users", "_id", true},
+		{"account_no", "accounts", "account_no", true},
+		{"id", "users", "id", false},
+		{"owner_id", "users", "id", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, referencesByName(tt.child, tt.parentEntity, tt.parentField), tt.child)
+	}
+}
+
+func TestIsKeyLikeField(t *testing.T) {
+	for _, name := range []string{"id", "_id", "user_id", "customerId", "meta.order_id"} {
+		assert.True(t, IsKeyLikeField(name), name)
+	}
+	for _, name := range []string{"paid", "email", "idea"} {
+		assert.False(t, IsKeyLikeField(name), name)
+	}
+}
@@ -188,6 +188,9 @@ type DataMappingRepository interface {
 	GetByID(ctx context.Context, id types.ID) (*DataMapping, error)
 	GetByClassification(ctx context.Context, classificationID types.ID) (*DataMapping, error)
 	GetUnmapped(ctx context.Context, tenantID types.ID) ([]types.ID, error)
+	// GetCrossBorder returns the tenant's mappings that document a
+	// cross-border transfer.
+	GetCrossBorder(ctx context.Context, tenantID types.ID) ([]DataMapping, error)
 	Update(ctx context.Context, dm *DataMapping) error
 }
 
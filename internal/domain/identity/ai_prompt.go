@@ -0,0 +1,67 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// AI Prompts — Versioned templates per use case
+// =============================================================================
+
+// AIPromptStatus is the lifecycle state of a prompt version.
+type AIPromptStatus string
+
+const (
+	AIPromptDraft   AIPromptStatus = "DRAFT"   // Created, not in use
+	AIPromptActive  AIPromptStatus = "ACTIVE"  // In use for its scope
+	AIPromptRetired AIPromptStatus = "RETIRED" // Replaced or rolled back
+)
+
+// AIPromptVersion is one version of a use case's prompt template. Versions
+// without a tenant are platform-wide; a tenant's active version overrides
+// the platform's, which overrides the builtin template.
+type AIPromptVersion struct {
+	ID           types.ID       `json:"id" db:"id"`
+	TenantID     *types.ID      `json:"tenant_id,omitempty" db:"tenant_id"`
+	UseCase      string         `json:"use_case" db:"use_case"`
+	Version      int            `json:"version" db:"version"` // Sequential per use case and scope
+	SystemPrompt string         `json:"system_prompt" db:"system_prompt"`
+	Template     string         `json:"template" db:"template"` // text/template
+	Description  string         `json:"description" db:"description"`
+	Status       AIPromptStatus `json:"status" db:"status"`
+	CreatedBy    *types.ID      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	ActivatedAt  *time.Time     `json:"activated_at,omitempty" db:"activated_at"`
+}
+
+// Label is the version label recorded with results: "v3" for a platform
+// version and "tenant-v3" for a tenant's own, as each scope numbers its
+// versions separately.
+func (p *AIPromptVersion) Label() string {
+	if p.TenantID != nil {
+		return fmt.Sprintf("tenant-v%d", p.Version)
+	}
+	return fmt.Sprintf("v%d", p.Version)
+}
+
+// AIPromptRepository persists prompt versions.
+type AIPromptRepository interface {
+	// Create stores a draft and assigns it the next version number of its
+	// use case in its scope.
+	Create(ctx context.Context, p *AIPromptVersion) error
+	GetByID(ctx context.Context, id types.ID) (*AIPromptVersion, error)
+	// List returns a tenant's versions and the platform-wide ones, newest
+	// first. An empty useCase lists every use case.
+	List(ctx context.Context, tenantID types.ID, useCase string) ([]AIPromptVersion, error)
+	// GetActive returns the active version of a use case in one scope; a
+	// nil tenantID is the platform scope.
+	GetActive(ctx context.Context, tenantID *types.ID, useCase string) (*AIPromptVersion, error)
+	// Activate makes a version active, retiring the version it replaces in
+	// the same scope.
+	Activate(ctx context.Context, id types.ID) error
+	Retire(ctx context.Context, id types.ID) error
+}
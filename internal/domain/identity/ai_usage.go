@@ -0,0 +1,182 @@
+package identity
+
+import (
+	"context"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// AI Usage — Per-tenant ledger and monthly budgets
+// =============================================================================
+
+// AIUsageRecord is one completed AI provider call billed to a tenant.
+type AIUsageRecord struct {
+	ID           types.ID  `json:"id" db:"id"`
+	TenantID     types.ID  `json:"tenant_id" db:"tenant_id"`
+	Provider     string    `json:"provider" db:"provider"`
+	Model        string    `json:"model" db:"model"`
+	UseCase      string    `json:"use_case" db:"use_case"`
+	InputTokens  int       `json:"input_tokens" db:"input_tokens"`
+	OutputTokens int       `json:"output_tokens" db:"output_tokens"`
+	TotalTokens  int       `json:"total_tokens" db:"total_tokens"`
+	Cost         float64   `json:"cost" db:"cost"` // USD
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// AIUsageBreakdown aggregates a tenant's usage for one provider, model and
+// use case over a period.
+type AIUsageBreakdown struct {
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	UseCase      string  `json:"use_case"`
+	Calls        int     `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	TotalTokens  int64   `json:"total_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// AIUsageTotals sums usage breakdowns.
+type AIUsageTotals struct {
+	Calls        int     `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	TotalTokens  int64   `json:"total_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// SumAIUsage totals a set of breakdowns.
+func SumAIUsage(breakdown []AIUsageBreakdown) AIUsageTotals {
+	var t AIUsageTotals
+	for _, b := range breakdown {
+		t.Calls += b.Calls
+		t.InputTokens += b.InputTokens
+		t.OutputTokens += b.OutputTokens
+		t.TotalTokens += b.TotalTokens
+		t.Cost += b.Cost
+	}
+	return t
+}
+
+// AIUsageReport is a tenant's usage over a period with its breakdown by
+// provider, model and use case, and where the current month stands against
+// the budget.
+type AIUsageReport struct {
+	TenantID  types.ID           `json:"tenant_id"`
+	From      time.Time          `json:"from"`
+	To        time.Time          `json:"to"`
+	Totals    AIUsageTotals      `json:"totals"`
+	Breakdown []AIUsageBreakdown `json:"breakdown"`
+	Budget    *AIBudgetStatus    `json:"budget,omitempty"`
+}
+
+// AIBudgetLevel is where a tenant's month-to-date usage stands against its
+// budget.
+type AIBudgetLevel string
+
+const (
+	AIBudgetOK       AIBudgetLevel = "OK"
+	AIBudgetWarning  AIBudgetLevel = "WARNING"  // Past the soft limit
+	AIBudgetExceeded AIBudgetLevel = "EXCEEDED" // At or past the cap
+)
+
+// DefaultAISoftLimitPercent is the share of the cap at which a tenant is
+// warned when its budget does not set one.
+const DefaultAISoftLimitPercent = 80
+
+// AIBudget is a tenant's monthly AI cap. Either limit may be zero (no cap
+// on that measure). With HardLimit set, calls are refused once the cap is
+// reached; otherwise the tenant is only alerted.
+type AIBudget struct {
+	TenantID          types.ID  `json:"tenant_id" db:"tenant_id"`
+	MonthlyTokenLimit int64     `json:"monthly_token_limit" db:"monthly_token_limit"`
+	MonthlyCostLimit  float64   `json:"monthly_cost_limit" db:"monthly_cost_limit"` // USD
+	SoftLimitPercent  int       `json:"soft_limit_percent" db:"soft_limit_percent"`
+	HardLimit         bool      `json:"hard_limit" db:"hard_limit"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+
+	// The last alert sent, so each level alerts once per month.
+	AlertedPeriod string        `json:"alerted_period,omitempty" db:"alerted_period"` // "2006-01"
+	AlertedLevel  AIBudgetLevel `json:"alerted_level,omitempty" db:"alerted_level"`
+}
+
+// Validate checks the budget's limits.
+func (b *AIBudget) Validate() error {
+	details := map[string]any{}
+	if b.MonthlyTokenLimit < 0 {
+		details["monthly_token_limit"] = "must not be negative"
+	}
+	if b.MonthlyCostLimit < 0 {
+		details["monthly_cost_limit"] = "must not be negative"
+	}
+	if b.SoftLimitPercent < 0 || b.SoftLimitPercent > 100 {
+		details["soft_limit_percent"] = "must be between 0 and 100"
+	}
+	if len(details) > 0 {
+		return types.NewValidationError("invalid AI budget", details)
+	}
+	return nil
+}
+
+// Status reports month-to-date usage against the budget. The level is
+// decided by whichever limit is closer to its cap.
+func (b *AIBudget) Status(period string, used AIUsageTotals) *AIBudgetStatus {
+	soft := b.SoftLimitPercent
+	if soft == 0 {
+		soft = DefaultAISoftLimitPercent
+	}
+
+	var percent float64
+	if b.MonthlyTokenLimit > 0 {
+		percent = max(percent, float64(used.TotalTokens)/float64(b.MonthlyTokenLimit)*100)
+	}
+	if b.MonthlyCostLimit > 0 {
+		percent = max(percent, used.Cost/b.MonthlyCostLimit*100)
+	}
+
+	level := AIBudgetOK
+	switch {
+	case (b.MonthlyTokenLimit > 0 || b.MonthlyCostLimit > 0) && percent >= 100:
+		level = AIBudgetExceeded
+	case percent >= float64(soft):
+		level = AIBudgetWarning
+	}
+
+	return &AIBudgetStatus{
+		Period:      period,
+		TokensUsed:  used.TotalTokens,
+		TokenLimit:  b.MonthlyTokenLimit,
+		CostUsed:    used.Cost,
+		CostLimit:   b.MonthlyCostLimit,
+		PercentUsed: percent,
+		Level:       level,
+		HardLimit:   b.HardLimit,
+	}
+}
+
+// AIBudgetStatus is a tenant's month-to-date usage against its budget.
+type AIBudgetStatus struct {
+	Period      string        `json:"period"` // "2006-01"
+	TokensUsed  int64         `json:"tokens_used"`
+	TokenLimit  int64         `json:"token_limit"`
+	CostUsed    float64       `json:"cost_used"`
+	CostLimit   float64       `json:"cost_limit"`
+	PercentUsed float64       `json:"percent_used"`
+	Level       AIBudgetLevel `json:"level"`
+	HardLimit   bool          `json:"hard_limit"`
+}
+
+// AIUsageRepository persists the AI usage ledger and budgets.
+type AIUsageRepository interface {
+	Record(ctx context.Context, r *AIUsageRecord) error
+	// Summarize groups a tenant's usage in [from, to) by provider, model and
+	// use case.
+	Summarize(ctx context.Context, tenantID types.ID, from, to time.Time) ([]AIUsageBreakdown, error)
+
+	GetBudget(ctx context.Context, tenantID types.ID) (*AIBudget, error)
+	UpsertBudget(ctx context.Context, b *AIBudget) error
+	// MarkBudgetAlerted records the last alert sent for a budget.
+	MarkBudgetAlerted(ctx context.Context, tenantID types.ID, period string, level AIBudgetLevel) error
+}
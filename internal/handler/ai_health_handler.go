@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/complyark/datalens/internal/service/ai"
+	"github.com/complyark/datalens/pkg/httputil"
+)
+
+// AIHealthHandler exposes the health of the AI providers behind the gateway:
+// circuit breaker state, error rate and latency per provider.
+type AIHealthHandler struct {
+	gateway *ai.DefaultGateway
+}
+
+// NewAIHealthHandler creates a new AIHealthHandler.
+func NewAIHealthHandler(gateway *ai.DefaultGateway) *AIHealthHandler {
+	return &AIHealthHandler{gateway: gateway}
+}
+
+// Routes returns a chi.Router with AI provider health routes mounted.
+func (h *AIHealthHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	// GET /api/v2/ai/providers/health — circuit breaker state per provider
+	r.Get("/health", h.Health)
+
+	return r
+}
+
+// Health handles GET /api/v2/ai/providers/health
+func (h *AIHealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	httputil.JSON(w, http.StatusOK, h.gateway.ProviderHealth())
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/complyark/datalens/internal/middleware"
+	"github.com/complyark/datalens/internal/service"
+	"github.com/complyark/datalens/pkg/httputil"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// AIPromptHandler manages the tenant's versions of the AI prompt templates.
+type AIPromptHandler struct {
+	svc *service.AIPromptService
+}
+
+// NewAIPromptHandler creates a new AIPromptHandler.
+func NewAIPromptHandler(svc *service.AIPromptService) *AIPromptHandler {
+	return &AIPromptHandler{svc: svc}
+}
+
+// Routes returns a chi.Router with AI prompt routes mounted.
+// Mounted at /api/v2/ai/prompts.
+func (h *AIPromptHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	// GET /api/v2/ai/prompts?use_case= — tenant and platform versions
+	r.Get("/", h.List)
+
+	// GET /api/v2/ai/prompts/builtin — compiled-in template per use case
+	r.Get("/builtin", h.Builtin)
+
+	// POST /api/v2/ai/prompts — create a draft version
+	r.Post("/", h.Create)
+
+	// POST /api/v2/ai/prompts/{id}/activate — use a version (or roll back to it)
+	r.Post("/{id}/activate", h.Activate)
+
+	// POST /api/v2/ai/prompts/{id}/retire — stop using a version
+	r.Post("/{id}/retire", h.Retire)
+
+	return r
+}
+
+// List handles GET /api/v2/ai/prompts
+func (h *AIPromptHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	versions, err := h.svc.ListVersions(r.Context(), tenantID, r.URL.Query().Get("use_case"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, versions)
+}
+
+// Builtin handles GET /api/v2/ai/prompts/builtin
+func (h *AIPromptHandler) Builtin(w http.ResponseWriter, r *http.Request) {
+	httputil.JSON(w, http.StatusOK, h.svc.BuiltinPrompts())
+}
+
+// CreateAIPromptRequest is the request body for POST /api/v2/ai/prompts.
+type CreateAIPromptRequest struct {
+	UseCase      string `json:"use_case"`
+	SystemPrompt string `json:"system_prompt"`
+	Template     string `json:"template"`
+	Description  string `json:"description"`
+}
+
+// Create handles POST /api/v2/ai/prompts
+func (h *AIPromptHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	var req CreateAIPromptRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.ErrorResponse(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	var createdBy *types.ID
+	if userID, ok := middleware.UserIDFromContext(r.Context()); ok {
+		createdBy = &userID
+	}
+
+	version, err := h.svc.CreateVersion(r.Context(), service.CreateVersionRequest{
+		TenantID:     &tenantID,
+		UseCase:      req.UseCase,
+		SystemPrompt: req.SystemPrompt,
+		Template:     req.Template,
+		Description:  req.Description,
+		CreatedBy:    createdBy,
+	})
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusCreated, version)
+}
+
+// Activate handles POST /api/v2/ai/prompts/{id}/activate
+func (h *AIPromptHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	id, err := httputil.ParseID(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	version, err := h.svc.Activate(r.Context(), &tenantID, id)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, version)
+}
+
+// Retire handles POST /api/v2/ai/prompts/{id}/retire
+func (h *AIPromptHandler) Retire(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	id, err := httputil.ParseID(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	version, err := h.svc.Retire(r.Context(), &tenantID, id)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, version)
+}
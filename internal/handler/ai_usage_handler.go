@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/internal/middleware"
+	"github.com/complyark/datalens/internal/service"
+	"github.com/complyark/datalens/pkg/httputil"
+)
+
+// AIUsageHandler exposes the tenant's AI usage ledger and monthly budget.
+type AIUsageHandler struct {
+	svc *service.AIUsageService
+}
+
+// NewAIUsageHandler creates a new AIUsageHandler.
+func NewAIUsageHandler(svc *service.AIUsageService) *AIUsageHandler {
+	return &AIUsageHandler{svc: svc}
+}
+
+// Routes returns a chi.Router with AI usage routes mounted.
+func (h *AIUsageHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	// GET /api/v2/ai/usage?from=&to= — usage by provider, model and use case
+	r.Get("/usage", h.GetUsage)
+
+	// GET /api/v2/ai/budget — current monthly budget
+	r.Get("/budget", h.GetBudget)
+
+	// PUT /api/v2/ai/budget — set the monthly budget (tenant admins only)
+	r.With(middleware.RequireRole(identity.RoleAdmin)).Put("/budget", h.SetBudget)
+
+	return r
+}
+
+// GetUsage handles GET /api/v2/ai/usage. The period defaults to the
+// current calendar month.
+func (h *AIUsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		t, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			httputil.ErrorResponse(w, http.StatusBadRequest, "VALIDATION_ERROR", "invalid from format, use RFC3339")
+			return
+		}
+		from = t
+	}
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			httputil.ErrorResponse(w, http.StatusBadRequest, "VALIDATION_ERROR", "invalid to format, use RFC3339")
+			return
+		}
+		to = t
+	}
+
+	report, err := h.svc.GetUsage(r.Context(), tenantID, from, to)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, report)
+}
+
+// GetBudget handles GET /api/v2/ai/budget
+func (h *AIUsageHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	budget, err := h.svc.GetBudget(r.Context(), tenantID)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, budget)
+}
+
+// SetAIBudgetRequest is the request body for PUT /api/v2/ai/budget.
+type SetAIBudgetRequest struct {
+	MonthlyTokenLimit int64   `json:"monthly_token_limit"`
+	MonthlyCostLimit  float64 `json:"monthly_cost_limit"`
+	SoftLimitPercent  int     `json:"soft_limit_percent"`
+	HardLimit         bool    `json:"hard_limit"`
+}
+
+// SetBudget handles PUT /api/v2/ai/budget
+func (h *AIUsageHandler) SetBudget(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	var req SetAIBudgetRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.ErrorResponse(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	budget, err := h.svc.SetBudget(r.Context(), &identity.AIBudget{
+		TenantID:          tenantID,
+		MonthlyTokenLimit: req.MonthlyTokenLimit,
+		MonthlyCostLimit:  req.MonthlyCostLimit,
+		SoftLimitPercent:  req.SoftLimitPercent,
+		HardLimit:         req.HardLimit,
+	})
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, budget)
+}
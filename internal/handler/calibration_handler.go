@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/complyark/datalens/internal/middleware"
+	"github.com/complyark/datalens/internal/service"
+	"github.com/complyark/datalens/pkg/httputil"
+)
+
+// CalibrationHandler exposes per-tenant detection calibration.
+type CalibrationHandler struct {
+	svc *service.CalibrationService
+}
+
+// NewCalibrationHandler creates a new CalibrationHandler.
+func NewCalibrationHandler(svc *service.CalibrationService) *CalibrationHandler {
+	return &CalibrationHandler{svc: svc}
+}
+
+// Routes returns a chi.Router with calibration routes mounted.
+func (h *CalibrationHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	// GET /api/v2/discovery/calibration — current calibration and precision report
+	r.Get("/", h.Get)
+
+	// POST /api/v2/discovery/calibration/run — recalibrate now from all feedback
+	r.Post("/run", h.Run)
+
+	return r
+}
+
+// Get handles GET /api/v2/discovery/calibration
+func (h *CalibrationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	c, err := h.svc.Get(r.Context(), tenantID)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, c)
+}
+
+// Run handles POST /api/v2/discovery/calibration/run
+func (h *CalibrationHandler) Run(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	c, err := h.svc.Calibrate(r.Context(), tenantID)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, c)
+}
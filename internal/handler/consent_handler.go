@@ -3,7 +3,9 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -48,6 +50,7 @@ func (h *ConsentHandler) Routes() chi.Router {
 	r.Put("/widgets/{id}/activate", h.activateWidget)
 	r.Put("/widgets/{id}/pause", h.pauseWidget)
 	r.Get("/widgets/{id}/embed-code", h.getEmbedCode)
+	r.Get("/widgets/{id}/audits", h.listWidgetAudits)
 
 	r.Get("/sessions", h.listSessions) // Actually getSessionsBySubject as per service, but simplified
 	r.Get("/history/{subjectId}", h.getHistory)
@@ -161,8 +164,20 @@ func (h *ConsentHandler) deleteWidget(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// activateWidget takes an optional {"override_reason": "..."} body to
+// activate despite HIGH dark-pattern findings.
 func (h *ConsentHandler) activateWidget(w http.ResponseWriter, r *http.Request) {
-	h.setWidgetStatus(w, r, h.service.ActivateWidget)
+	var req struct {
+		OverrideReason string `json:"override_reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httputil.ErrorResponse(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid json body")
+		return
+	}
+
+	h.setWidgetStatus(w, r, func(ctx context.Context, id types.ID) (*consent.ConsentWidget, error) {
+		return h.service.ActivateWidgetWithOverride(ctx, id, req.OverrideReason)
+	})
 }
 
 func (h *ConsentHandler) pauseWidget(w http.ResponseWriter, r *http.Request) {
@@ -186,6 +201,23 @@ func (h *ConsentHandler) setWidgetStatus(w http.ResponseWriter, r *http.Request,
 	httputil.JSON(w, http.StatusOK, widget)
 }
 
+func (h *ConsentHandler) listWidgetAudits(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := types.ParseID(idStr)
+	if err != nil {
+		httputil.ErrorResponse(w, http.StatusBadRequest, "INVALID_ID", "invalid widget id")
+		return
+	}
+
+	audits, err := h.service.ListWidgetAudits(r.Context(), id)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, audits)
+}
+
 func (h *ConsentHandler) listSessions(w http.ResponseWriter, r *http.Request) {
 	subjectIDStr := r.URL.Query().Get("subject_id")
 
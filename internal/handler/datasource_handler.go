@@ -41,6 +41,7 @@ func (h *DataSourceHandler) Routes() chi.Router {
 
 	// Scan actions
 	r.Post("/{id}/scan", h.Scan)
+	r.Post("/{id}/scan/cancel", h.CancelScan)
 	r.Get("/{id}/scan/status", h.GetScanStatus)
 	r.Get("/{id}/scan/history", h.GetScanHistory)
 
@@ -332,6 +333,29 @@ func (h *DataSourceHandler) Scan(w http.ResponseWriter, r *http.Request) {
 	httputil.JSON(w, http.StatusAccepted, run)
 }
 
+// CancelScan cancels the active scan on a data source.
+func (h *DataSourceHandler) CancelScan(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := middleware.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusForbidden, "TENANT_REQUIRED", "tenant context is required")
+		return
+	}
+
+	id, err := httputil.ParseID(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	run, err := h.scanSvc.CancelScan(r.Context(), id, tenantID)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, run)
+}
+
 // GetScanStatus returns the status of the latest scan.
 func (h *DataSourceHandler) GetScanStatus(w http.ResponseWriter, r *http.Request) {
 	id, err := httputil.ParseID(chi.URLParam(r, "id"))
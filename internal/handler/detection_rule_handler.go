@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/complyark/datalens/internal/service"
+	"github.com/complyark/datalens/pkg/httputil"
+)
+
+// DetectionRuleHandler handles HTTP requests for tenant custom detection rules.
+type DetectionRuleHandler struct {
+	service *service.DetectionRuleService
+}
+
+// NewDetectionRuleHandler creates a new DetectionRuleHandler.
+func NewDetectionRuleHandler(s *service.DetectionRuleService) *DetectionRuleHandler {
+	return &DetectionRuleHandler{service: s}
+}
+
+// Routes returns a chi.Router with custom detection rule routes.
+// Mounted at /api/v2/discovery/rules.
+func (h *DetectionRuleHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Post("/", h.Create)
+	r.Get("/", h.List)
+	r.Post("/test", h.Test)
+	r.Get("/{id}", h.GetByID)
+	r.Put("/{id}", h.Update)
+	r.Delete("/{id}", h.Delete)
+	return r
+}
+
+// Create handles POST /api/v2/discovery/rules.
+func (h *DetectionRuleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateDetectionRuleRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	rule, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusCreated, rule)
+}
+
+// List handles GET /api/v2/discovery/rules.
+func (h *DetectionRuleHandler) List(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.List(r.Context())
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, rules)
+}
+
+// Test handles POST /api/v2/discovery/rules/test.
+func (h *DetectionRuleHandler) Test(w http.ResponseWriter, r *http.Request) {
+	var req service.TestDetectionRuleRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	results, err := h.service.Test(r.Context(), req)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, results)
+}
+
+// GetByID handles GET /api/v2/discovery/rules/{id}.
+func (h *DetectionRuleHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	rule, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, rule)
+}
+
+// Update handles PUT /api/v2/discovery/rules/{id}.
+func (h *DetectionRuleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	var req service.UpdateDetectionRuleRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	rule, err := h.service.Update(r.Context(), id, req)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, rule)
+}
+
+// Delete handles DELETE /api/v2/discovery/rules/{id}.
+func (h *DetectionRuleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
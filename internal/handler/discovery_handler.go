@@ -46,6 +46,7 @@ func (h *DiscoveryHandler) Routes() chi.Router {
 
 	// Trigger Scan (Async)
 	r.Post("/data-sources/{sourceID}/scan", h.ScanDataSource)
+	r.Post("/data-sources/{sourceID}/scan/cancel", h.CancelScan)
 
 	// Test Connection
 	r.Post("/data-sources/{sourceID}/test", h.TestConnection)
@@ -64,6 +65,7 @@ func (h *DiscoveryHandler) Routes() chi.Router {
 	// Fields for an entity
 	r.Get("/entities/{entityID}/fields", h.ListFields)
 	r.Get("/fields/{fieldID}", h.GetField)
+	r.Post("/fields/{fieldID}/review", h.MarkFieldReviewed)
 
 	// Classifications (Review Queue)
 	r.Get("/classifications", h.GetClassifications)
@@ -162,6 +164,34 @@ func (h *DiscoveryHandler) ScanDataSource(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// CancelScan cancels the active scan of a data source.
+// POST /api/v2/data-sources/{sourceID}/scan/cancel
+func (h *DiscoveryHandler) CancelScan(w http.ResponseWriter, r *http.Request) {
+	sourceID, err := httputil.ParseID(chi.URLParam(r, "sourceID"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	tenantID, ok := mw.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "tenant context missing")
+		return
+	}
+
+	run, err := h.scanService.CancelScan(r.Context(), sourceID, tenantID)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Scan cancelled",
+		"job_id":  run.ID,
+		"status":  run.Status,
+	})
+}
+
 // GetScanStatus returns the status of a specific scan job or the latest for a source.
 // GET /api/v2/data-sources/{sourceID}/scan/status
 func (h *DiscoveryHandler) GetScanStatus(w http.ResponseWriter, r *http.Request) {
@@ -291,3 +321,27 @@ func (h *DiscoveryHandler) GetField(w http.ResponseWriter, r *http.Request) {
 
 	httputil.JSON(w, http.StatusOK, field)
 }
+
+// MarkFieldReviewed clears the review flag a scan sets on new columns.
+// POST /api/v2/discovery/fields/{fieldID}/review
+func (h *DiscoveryHandler) MarkFieldReviewed(w http.ResponseWriter, r *http.Request) {
+	fieldID, err := httputil.ParseID(chi.URLParam(r, "fieldID"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	tenantID, ok := mw.TenantIDFromContext(r.Context())
+	if !ok {
+		httputil.ErrorResponse(w, http.StatusUnauthorized, "UNAUTHORIZED", "tenant context missing")
+		return
+	}
+
+	field, err := h.service.MarkFieldReviewed(r.Context(), tenantID, fieldID)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, field)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/service"
 	"github.com/complyark/datalens/pkg/httputil"
 	"github.com/complyark/datalens/pkg/types"
 )
@@ -41,6 +42,14 @@ func (m *MockScanOrchestrator) GetHistory(ctx context.Context, dataSourceID type
 	return args.Get(0).([]discovery.ScanRun), args.Error(1)
 }
 
+func (m *MockScanOrchestrator) CancelScan(ctx context.Context, dataSourceID types.ID, tenantID types.ID) (*discovery.ScanRun, error) {
+	args := m.Called(ctx, dataSourceID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.ScanRun), args.Error(1)
+}
+
 // Minimal Repository Mocks needed for Handler
 type MockInventoryRepo struct{ mock.Mock }
 
@@ -118,6 +127,44 @@ func TestDiscoveryHandler_GetScanHistory(t *testing.T) {
 	scanSvc.AssertExpectations(t)
 }
 
+func TestDiscoveryHandler_CancelScan(t *testing.T) {
+	// Setup
+	scanSvc := new(MockScanOrchestrator)
+	handler := NewDiscoveryHandler(nil, scanSvc, nil, nil, nil)
+
+	r := chi.NewRouter()
+	r.Post("/data-sources/{sourceID}/scan/cancel", handler.CancelScan)
+
+	dsID := types.NewID()
+	tenantID := types.NewID()
+	runID := types.NewID()
+
+	cancelledRun := &discovery.ScanRun{
+		BaseEntity: types.BaseEntity{ID: runID},
+		Status:     discovery.ScanStatusCancelled,
+	}
+	scanSvc.On("CancelScan", mock.Anything, dsID, tenantID).Return(cancelledRun, nil)
+
+	// Request
+	req := httptest.NewRequest("POST", "/data-sources/"+dsID.String()+"/scan/cancel", nil)
+	ctx := context.WithValue(req.Context(), types.ContextKeyTenantID, tenantID)
+	req = req.WithContext(ctx)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// Verify
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp httputil.Response
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, runID.String(), data["job_id"])
+	assert.Equal(t, string(discovery.ScanStatusCancelled), data["status"])
+	scanSvc.AssertExpectations(t)
+}
+
 // =============================================================================
 // Mock DiscoveryOrchestrator for GetClassifications
 // =============================================================================
@@ -126,9 +173,20 @@ type MockDiscoveryOrchestrator struct {
 	mock.Mock
 }
 
-func (m *MockDiscoveryOrchestrator) ScanDataSource(ctx context.Context, dataSourceID types.ID) error {
+func (m *MockDiscoveryOrchestrator) ScanDataSource(ctx context.Context, dataSourceID types.ID) (*discovery.ScanStats, error) {
 	args := m.Called(ctx, dataSourceID)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.ScanStats), args.Error(1)
+}
+
+func (m *MockDiscoveryOrchestrator) ScanDataSourceWithOptions(ctx context.Context, dataSourceID types.ID, opts service.ScanOptions) (*discovery.ScanStats, error) {
+	args := m.Called(ctx, dataSourceID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.ScanStats), args.Error(1)
 }
 
 func (m *MockDiscoveryOrchestrator) TestConnection(ctx context.Context, dataSourceID types.ID) error {
@@ -144,6 +202,14 @@ func (m *MockDiscoveryOrchestrator) GetClassifications(ctx context.Context, tena
 	return args.Get(0).(*types.PaginatedResult[discovery.PIIClassification]), args.Error(1)
 }
 
+func (m *MockDiscoveryOrchestrator) MarkFieldReviewed(ctx context.Context, tenantID, fieldID types.ID) (*discovery.DataField, error) {
+	args := m.Called(ctx, tenantID, fieldID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.DataField), args.Error(1)
+}
+
 func TestDiscoveryHandler_GetClassifications(t *testing.T) {
 	// Setup
 	discSvc := new(MockDiscoveryOrchestrator)
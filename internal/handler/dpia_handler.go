@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/complyark/datalens/internal/domain/compliance"
+	"github.com/complyark/datalens/internal/service"
+	"github.com/complyark/datalens/pkg/httputil"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// DPIAHandler handles HTTP requests for Data Protection Impact Assessments.
+type DPIAHandler struct {
+	service *service.DPIAService
+}
+
+// NewDPIAHandler creates a new DPIAHandler.
+func NewDPIAHandler(s *service.DPIAService) *DPIAHandler {
+	return &DPIAHandler{service: s}
+}
+
+// Routes returns a chi.Router with DPIA routes.
+// Mounted at /api/v2/dpia.
+func (h *DPIAHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", h.List)    // ?status=
+	r.Post("/", h.Create) // Pre-filled from the inventory
+	r.Get("/{id}", h.Get)
+	r.Put("/{id}", h.Update)                          // Edit a draft
+	r.Post("/{id}/refresh", h.Refresh)                // Re-take the inventory snapshot
+	r.Post("/{id}/draft", h.Draft)                    // AI-draft narrative, risks and mitigations
+	r.Post("/{id}/submit", h.Submit)                  // DRAFT → IN_REVIEW
+	r.Post("/{id}/approve", h.Approve)                // IN_REVIEW → APPROVED (linked into the RoPA)
+	r.Post("/{id}/request-changes", h.RequestChanges) // IN_REVIEW → DRAFT
+	r.Post("/{id}/review", h.Review)                  // Periodic review: still accurate
+	r.Post("/{id}/reopen", h.Reopen)                  // Periodic review: processing changed
+	r.Post("/{id}/archive", h.Archive)                // Processing ended
+	return r
+}
+
+// List handles GET /api/v2/dpia
+func (h *DPIAHandler) List(w http.ResponseWriter, r *http.Request) {
+	pagination := httputil.ParsePagination(r)
+
+	var status *compliance.DPIAStatus
+	if s := r.URL.Query().Get("status"); s != "" {
+		st := compliance.DPIAStatus(s)
+		status = &st
+	}
+
+	result, err := h.service.List(r.Context(), status, pagination)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSONWithPagination(w, result.Items, pagination.Page, pagination.PageSize, result.Total)
+}
+
+// Create handles POST /api/v2/dpia
+func (h *DPIAHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateDPIARequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	d, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusCreated, d)
+}
+
+// Get handles GET /api/v2/dpia/{id}
+func (h *DPIAHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.service.Get)
+}
+
+// Update handles PUT /api/v2/dpia/{id}
+func (h *DPIAHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req service.UpdateDPIARequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+	h.act(w, r, func(ctx context.Context, id types.ID) (*compliance.DPIA, error) {
+		return h.service.Update(ctx, id, req)
+	})
+}
+
+// Refresh handles POST /api/v2/dpia/{id}/refresh
+func (h *DPIAHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.service.Refresh)
+}
+
+// Draft handles POST /api/v2/dpia/{id}/draft
+func (h *DPIAHandler) Draft(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.service.Draft)
+}
+
+// Submit handles POST /api/v2/dpia/{id}/submit
+func (h *DPIAHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.service.Submit)
+}
+
+// Archive handles POST /api/v2/dpia/{id}/archive
+func (h *DPIAHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.service.Archive)
+}
+
+// DPIAReviewRequest is the request body of the review decisions.
+type DPIAReviewRequest struct {
+	Notes string `json:"notes"`
+}
+
+// Approve handles POST /api/v2/dpia/{id}/approve
+func (h *DPIAHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.Approve)
+}
+
+// RequestChanges handles POST /api/v2/dpia/{id}/request-changes
+func (h *DPIAHandler) RequestChanges(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.RequestChanges)
+}
+
+// Review handles POST /api/v2/dpia/{id}/review
+func (h *DPIAHandler) Review(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.CompleteReview)
+}
+
+// Reopen handles POST /api/v2/dpia/{id}/reopen
+func (h *DPIAHandler) Reopen(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.Reopen)
+}
+
+// review decodes the optional review notes and applies a decision.
+func (h *DPIAHandler) review(w http.ResponseWriter, r *http.Request, decide func(context.Context, types.ID, string) (*compliance.DPIA, error)) {
+	var req DPIAReviewRequest
+	if r.ContentLength != 0 {
+		if err := httputil.DecodeJSON(r, &req); err != nil {
+			httputil.ErrorFromDomain(w, err)
+			return
+		}
+	}
+	h.act(w, r, func(ctx context.Context, id types.ID) (*compliance.DPIA, error) {
+		return decide(ctx, id, req.Notes)
+	})
+}
+
+// act runs fn on the DPIA in the path and writes the result.
+func (h *DPIAHandler) act(w http.ResponseWriter, r *http.Request, fn func(context.Context, types.ID) (*compliance.DPIA, error)) {
+	id, err := httputil.ParseID(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	d, err := fn(r.Context(), id)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, d)
+}
@@ -12,6 +12,7 @@ import (
 type NoticeHandler struct {
 	service            *service.NoticeService
 	translationService *service.TranslationService
+	generator          *service.NoticeGeneratorService
 }
 
 func NewNoticeHandler(service *service.NoticeService, translationService *service.TranslationService) *NoticeHandler {
@@ -21,10 +22,21 @@ func NewNoticeHandler(service *service.NoticeService, translationService *servic
 	}
 }
 
+// SetGenerator enables the routes generating notices from the RoPA.
+func (h *NoticeHandler) SetGenerator(g *service.NoticeGeneratorService) {
+	h.generator = g
+}
+
 func (h *NoticeHandler) Routes() chi.Router {
 	r := chi.NewRouter()
 	r.Post("/", h.Create)
 	r.Get("/", h.List)
+	if h.generator != nil {
+		// Generated from the published RoPA
+		r.Post("/generate", h.Generate)
+		r.Get("/sync-status", h.SyncStatus)
+		r.Post("/{id}/regenerate", h.Regenerate)
+	}
 	r.Get("/{id}", h.GetByID)
 	r.Put("/{id}", h.Update)
 	r.Post("/{id}/publish", h.Publish)
@@ -248,3 +260,44 @@ func (h *NoticeHandler) CheckCompliance(w http.ResponseWriter, r *http.Request)
 	}
 	httputil.JSON(w, http.StatusOK, report)
 }
+
+func (h *NoticeHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	var req service.GenerateNoticeRequest
+	if r.ContentLength != 0 {
+		if err := httputil.DecodeJSON(r, &req); err != nil {
+			httputil.ErrorFromDomain(w, err)
+			return
+		}
+	}
+
+	notice, err := h.generator.Generate(r.Context(), req)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+	httputil.JSON(w, http.StatusCreated, notice)
+}
+
+func (h *NoticeHandler) Regenerate(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.ParseID(chi.URLParam(r, "id"))
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	notice, err := h.generator.Regenerate(r.Context(), id)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+	httputil.JSON(w, http.StatusCreated, notice)
+}
+
+func (h *NoticeHandler) SyncStatus(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.generator.SyncStatus(r.Context())
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+	httputil.JSON(w, http.StatusOK, statuses)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/complyark/datalens/internal/middleware"
+	"github.com/complyark/datalens/internal/service"
+	"github.com/complyark/datalens/pkg/httputil"
+	"github.com/complyark/datalens/pkg/redact"
+)
+
+// maxRedactionBody bounds a redaction request body: the service's 1 MiB
+// input limit plus room for JSON string escaping and options.
+const maxRedactionBody = 4 << 20
+
+// RedactionHandler exposes the PII redaction API, for customers to scrub
+// their own prompts and logs. It is meant for API keys: redaction needs
+// the PII:REDACT permission, restoring pseudonyms PII:DETOKENIZE.
+type RedactionHandler struct {
+	svc *service.RedactionService
+}
+
+// NewRedactionHandler creates a new RedactionHandler.
+func NewRedactionHandler(svc *service.RedactionService) *RedactionHandler {
+	return &RedactionHandler{svc: svc}
+}
+
+// Routes returns a chi.Router with redaction routes mounted.
+// Mounted at /api/v2/redact.
+func (h *RedactionHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	// POST /api/v2/redact — detect and redact or pseudonymize text or JSON
+	r.With(middleware.RequirePermission("PII", "REDACT")).Post("/", h.Redact)
+
+	// POST /api/v2/redact/restore — put vault values back in place of tokens
+	r.With(middleware.RequirePermission("PII", "DETOKENIZE")).Post("/restore", h.Restore)
+
+	return r
+}
+
+// Redact handles POST /api/v2/redact
+func (h *RedactionHandler) Redact(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRedactionBody)
+	var req redact.Request
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	resp, err := h.svc.Redact(r.Context(), req)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, resp)
+}
+
+// Restore handles POST /api/v2/redact/restore
+func (h *RedactionHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRedactionBody)
+	var req redact.RestoreRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	resp, err := h.svc.Restore(r.Context(), req)
+	if err != nil {
+		httputil.ErrorFromDomain(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, resp)
+}
@@ -32,10 +32,11 @@ type GoogleConnector struct {
 	fileScanner *shared.FileScanner
 	logger      *slog.Logger
 	cfg         *config.Config
+	cipher      crypto.CredentialCipher
 }
 
 // NewGoogleConnector creates a new GoogleConnector.
-func NewGoogleConnector(cfg *config.Config, detector *detection.ComposableDetector) *GoogleConnector {
+func NewGoogleConnector(cfg *config.Config, detector *detection.ComposableDetector, cipher crypto.CredentialCipher) *GoogleConnector {
 	if cfg == nil {
 		cfg, _ = config.Load()
 	}
@@ -43,6 +44,7 @@ func NewGoogleConnector(cfg *config.Config, detector *detection.ComposableDetect
 		fileScanner: shared.NewFileScanner(detector, slog.Default()),
 		logger:      slog.Default().With("connector", "google"),
 		cfg:         cfg,
+		cipher:      cipher,
 	}
 }
 
@@ -70,13 +72,7 @@ func (c *GoogleConnector) Connect(ctx context.Context, ds *discovery.DataSource)
 	}
 
 	// Decrypt
-	key := c.cfg.App.SecretKey
-	if len(key) < 32 {
-		key = fmt.Sprintf("%-32s", key)
-	}
-	key = key[:32]
-
-	credsJSON, err := crypto.Decrypt(ds.Credentials, key)
+	credsJSON, err := c.cipher.Decrypt(ctx, ds.TenantID, ds.Credentials)
 	if err != nil {
 		return fmt.Errorf("decrypt credentials: %w", err)
 	}
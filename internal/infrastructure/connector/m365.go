@@ -31,10 +31,11 @@ type M365Connector struct {
 	fileScanner *shared.FileScanner
 	logger      *slog.Logger
 	cfg         *config.Config
+	cipher      crypto.CredentialCipher
 }
 
 // NewM365Connector creates a new M365Connector.
-func NewM365Connector(detector *detection.ComposableDetector) *M365Connector {
+func NewM365Connector(detector *detection.ComposableDetector, cipher crypto.CredentialCipher) *M365Connector {
 	// We load config temporarily to get secrets if needed, but Connect loads it too.
 	// Actually we need config for ClientID/Secret.
 	// We'll load it in Connect or here?
@@ -45,6 +46,7 @@ func NewM365Connector(detector *detection.ComposableDetector) *M365Connector {
 		fileScanner: shared.NewFileScanner(detector, slog.Default()),
 		logger:      slog.Default().With("connector", "m365"),
 		cfg:         cfg,
+		cipher:      cipher,
 	}
 }
 
@@ -72,14 +74,7 @@ func (c *M365Connector) Connect(ctx context.Context, ds *discovery.DataSource) e
 		return fmt.Errorf("credentials required")
 	}
 
-	// Key padding logic (same as in m365_auth_service.go, should be refactored but copying for speed)
-	key := c.cfg.App.SecretKey
-	if len(key) < 32 {
-		key = fmt.Sprintf("%-32s", key)
-	}
-	key = key[:32]
-
-	credsJSON, err := crypto.Decrypt(ds.Credentials, key)
+	credsJSON, err := c.cipher.Decrypt(ctx, ds.TenantID, ds.Credentials)
 	if err != nil {
 		return fmt.Errorf("decrypt credentials: %w", err)
 	}
@@ -12,7 +12,7 @@ import (
 	"github.com/complyark/datalens/internal/config"
 	"github.com/complyark/datalens/internal/domain/discovery"
 	"github.com/complyark/datalens/internal/infrastructure/connector/shared"
-	"github.com/complyark/datalens/pkg/crypto" // Assuming crypto package exists and has Decrypt
+	"github.com/complyark/datalens/pkg/crypto"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/microsoft"
 )
@@ -21,11 +21,12 @@ import (
 type OutlookConnector struct {
 	client *http.Client
 	cfg    *config.Config
+	cipher crypto.CredentialCipher
 }
 
 // NewOutlookConnector creates a new OutlookConnector.
-func NewOutlookConnector(cfg *config.Config) *OutlookConnector {
-	return &OutlookConnector{cfg: cfg}
+func NewOutlookConnector(cfg *config.Config, cipher crypto.CredentialCipher) *OutlookConnector {
+	return &OutlookConnector{cfg: cfg, cipher: cipher}
 }
 
 // Compile-time check
@@ -50,7 +51,7 @@ func (c *OutlookConnector) Capabilities() discovery.ConnectorCapabilities {
 func (c *OutlookConnector) Connect(ctx context.Context, ds *discovery.DataSource) error {
 	// 1. Decrypt Credentials
 	// Credentials stored as encrypted JSON: {"refresh_token": "..."}
-	credsJSON, err := crypto.Decrypt(ds.Credentials, c.cfg.App.SecretKey[:32]) // Ensure 32 bytes
+	credsJSON, err := c.cipher.Decrypt(ctx, ds.TenantID, ds.Credentials)
 	if err != nil {
 		return fmt.Errorf("decrypt credentials: %w", err)
 	}
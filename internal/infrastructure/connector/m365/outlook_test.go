@@ -8,11 +8,12 @@ import (
 
 	"github.com/complyark/datalens/internal/config"
 	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/pkg/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestOutlookConnector_Capabilities(t *testing.T) {
-	c := NewOutlookConnector(&config.Config{})
+	c := NewOutlookConnector(&config.Config{}, crypto.NewStaticCipher(""))
 	caps := c.Capabilities()
 	assert.True(t, caps.CanDiscover)
 	assert.True(t, caps.CanSample)
@@ -38,7 +39,7 @@ func TestOutlookConnector_DiscoverSchema(t *testing.T) {
 	defer server.Close()
 
 	// Create Connector within test with mocked client
-	c := NewOutlookConnector(&config.Config{})
+	c := NewOutlookConnector(&config.Config{}, crypto.NewStaticCipher(""))
 
 	// Inject client directly (white-box test)
 	// We need to override the Base URL which is hardcoded in specific methods or use transport interception?
@@ -70,7 +71,7 @@ func TestOutlookConnector_DiscoverSchema(t *testing.T) {
 }
 
 func TestOutlookConnector_GetFields(t *testing.T) {
-	c := NewOutlookConnector(&config.Config{})
+	c := NewOutlookConnector(&config.Config{}, crypto.NewStaticCipher(""))
 	fields, err := c.GetFields(context.Background(), "Inbox")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, fields)
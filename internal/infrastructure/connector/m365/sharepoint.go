@@ -15,16 +15,16 @@ import (
 
 // Microsoft365Connector implements discovery.Connector for OneDrive and SharePoint.
 type Microsoft365Connector struct {
-	client    *GraphClient
-	secretKey string
-	logger    *slog.Logger
+	client *GraphClient
+	cipher crypto.CredentialCipher
+	logger *slog.Logger
 }
 
 // NewMicrosoft365Connector creates a new M365 connector.
-func NewMicrosoft365Connector(secretKey string) *Microsoft365Connector {
+func NewMicrosoft365Connector(cipher crypto.CredentialCipher) *Microsoft365Connector {
 	return &Microsoft365Connector{
-		secretKey: secretKey,
-		logger:    slog.Default().With("connector", "microsoft365"),
+		cipher: cipher,
+		logger: slog.Default().With("connector", "microsoft365"),
 	}
 }
 
@@ -35,15 +35,7 @@ func (c *Microsoft365Connector) Connect(ctx context.Context, ds *discovery.DataS
 	}
 
 	// 1. Decrypt Credentials
-	// Ensure secret key is correct length (32 bytes for AES-256)
-	// Just locally truncating or padding if needed, assuming shared.Encrypt logic aligns
-	key := c.secretKey
-	if len(key) < 32 {
-		key = fmt.Sprintf("%-32s", key)
-	}
-	key = key[:32]
-
-	decryptedJSON, err := crypto.Decrypt(ds.Credentials, key)
+	decryptedJSON, err := c.cipher.Decrypt(ctx, ds.TenantID, ds.Credentials)
 	if err != nil {
 		return fmt.Errorf("decrypt credentials: %w", err)
 	}
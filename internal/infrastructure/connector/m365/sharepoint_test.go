@@ -5,10 +5,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/complyark/datalens/pkg/crypto"
 )
 
 func TestMicrosoft365Connector_Capabilities(t *testing.T) {
-	connector := NewMicrosoft365Connector("secret-key")
+	connector := NewMicrosoft365Connector(crypto.NewStaticCipher("secret-key"))
 	caps := connector.Capabilities()
 
 	assert.True(t, caps.CanDiscover)
@@ -17,7 +19,7 @@ func TestMicrosoft365Connector_Capabilities(t *testing.T) {
 }
 
 func TestMicrosoft365Connector_GetFields(t *testing.T) {
-	connector := NewMicrosoft365Connector("secret-key")
+	connector := NewMicrosoft365Connector(crypto.NewStaticCipher("secret-key"))
 	fields, err := connector.GetFields(context.Background(), "some-id")
 
 	assert.NoError(t, err)
@@ -29,6 +31,6 @@ func TestMicrosoft365Connector_GetFields(t *testing.T) {
 func TestMicrosoft365Connector_ScanDrive_Logic(t *testing.T) {
 	// thorough testing would require mocking http.Client in GraphClient
 	// For now, we verify the struct and method existence.
-	connector := NewMicrosoft365Connector("secret-key")
+	connector := NewMicrosoft365Connector(crypto.NewStaticCipher("secret-key"))
 	assert.NotNil(t, connector)
 }
@@ -28,15 +28,20 @@ func NewMongoDBConnector() *MongoDBConnector {
 }
 
 // Compile-time check
-var _ discovery.Connector = (*MongoDBConnector)(nil)
+var (
+	_ discovery.Connector     = (*MongoDBConnector)(nil)
+	_ discovery.PathConnector = (*MongoDBConnector)(nil)
+	_ discovery.RowSampler    = (*MongoDBConnector)(nil)
+)
 
 // Capabilities returns the supported operations.
 func (c *MongoDBConnector) Capabilities() discovery.ConnectorCapabilities {
 	return discovery.ConnectorCapabilities{
 		CanDiscover:             true,
 		CanSample:               true,
-		CanDelete:               false,
+		CanDelete:               true,
 		CanUpdate:               false,
+		CanExport:               true,
 		SupportsStreaming:       true,
 		SupportsIncremental:     false, // MongoDB schema is dynamic, hard to track "modified tables" efficiently without oplog
 		SupportsSchemaDiscovery: true,
@@ -253,6 +258,46 @@ func (c *MongoDBConnector) SampleData(ctx context.Context, entity, field string,
 	return samples, nil
 }
 
+// SampleRows returns up to limit documents with the given fields (dotted
+// paths allowed) formatted as text.
+func (c *MongoDBConnector) SampleRows(ctx context.Context, entity string, fields []string, limit int) ([]map[string]string, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	projection := bson.D{{Key: "_id", Value: 0}}
+	for _, f := range fields {
+		projection = append(projection, bson.E{Key: f, Value: 1})
+	}
+	opts := options.Find().SetLimit(int64(limit)).SetProjection(projection)
+
+	coll := c.client.Database(c.dbName).Collection(entity)
+	cursor, err := coll.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("find sample rows: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []map[string]string
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		row := make(map[string]string, len(fields))
+		for _, f := range fields {
+			if val := getNestedValue(doc, f); val != nil {
+				row[f] = fmt.Sprintf("%v", val)
+			}
+		}
+		results = append(results, row)
+	}
+	return results, cursor.Err()
+}
+
 func getNestedValue(doc bson.M, path string) interface{} {
 	parts := strings.Split(path, ".")
 	current := doc
@@ -283,12 +328,127 @@ func (c *MongoDBConnector) Close() error {
 	return nil
 }
 
-// Delete is a stub for MongoDB.
+// mongoFilter converts a DSR filter into an equality query. Keys may be
+// dotted paths into subdocuments, which MongoDB matches natively.
+func mongoFilter(filter map[string]string) bson.D {
+	keys := make([]string, 0, len(filter))
+	for k := range filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	query := make(bson.D, 0, len(keys))
+	for _, k := range keys {
+		query = append(query, bson.E{Key: k, Value: filter[k]})
+	}
+	return query
+}
+
+// Delete removes documents matching the filter.
 func (c *MongoDBConnector) Delete(ctx context.Context, entity string, filter map[string]string) (int64, error) {
-	return 0, fmt.Errorf("delete not supported for mongodb yet")
+	if c.client == nil {
+		return 0, fmt.Errorf("not connected")
+	}
+	if len(filter) == 0 {
+		return 0, fmt.Errorf("refusing to delete with empty filter")
+	}
+
+	coll := c.client.Database(c.dbName).Collection(entity)
+	res, err := coll.DeleteMany(ctx, mongoFilter(filter))
+	if err != nil {
+		return 0, fmt.Errorf("delete failed: %w", err)
+	}
+	return res.DeletedCount, nil
 }
 
-// Export is a stub for MongoDB.
+// Export retrieves documents matching the filter.
 func (c *MongoDBConnector) Export(ctx context.Context, entity string, filter map[string]string) ([]map[string]interface{}, error) {
-	return nil, fmt.Errorf("export not supported for mongodb yet")
+	if c.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	return c.find(ctx, entity, mongoFilter(filter), nil)
+}
+
+// DeletePaths removes subdocument fields with $unset, leaving the rest of
+// each document in place.
+func (c *MongoDBConnector) DeletePaths(ctx context.Context, entity string, paths []string, filter map[string]string) (int64, error) {
+	if c.client == nil {
+		return 0, fmt.Errorf("not connected")
+	}
+	if len(filter) == 0 {
+		return 0, fmt.Errorf("refusing to delete with empty filter")
+	}
+	if len(paths) == 0 {
+		return 0, nil
+	}
+
+	unset := make(bson.D, 0, len(paths))
+	for _, p := range paths {
+		unset = append(unset, bson.E{Key: p, Value: ""})
+	}
+
+	coll := c.client.Database(c.dbName).Collection(entity)
+	res, err := coll.UpdateMany(ctx, mongoFilter(filter), bson.D{{Key: "$unset", Value: unset}})
+	if err != nil {
+		return 0, fmt.Errorf("delete paths failed: %w", err)
+	}
+	return res.ModifiedCount, nil
+}
+
+// ExportPaths returns the values at the given paths for documents matching
+// the filter, keyed by path.
+func (c *MongoDBConnector) ExportPaths(ctx context.Context, entity string, paths []string, filter map[string]string) ([]map[string]interface{}, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	projection := bson.D{{Key: "_id", Value: 0}}
+	for _, p := range paths {
+		projection = append(projection, bson.E{Key: p, Value: 1})
+	}
+
+	docs, err := c.find(ctx, entity, mongoFilter(filter), projection)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		row := make(map[string]interface{}, len(paths))
+		for _, p := range paths {
+			if val := getNestedValue(bson.M(doc), p); val != nil {
+				row[p] = val
+			}
+		}
+		results = append(results, row)
+	}
+	return results, nil
+}
+
+// find runs a query and decodes every matching document.
+func (c *MongoDBConnector) find(ctx context.Context, entity string, filter bson.D, projection bson.D) ([]map[string]interface{}, error) {
+	opts := options.Find()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+
+	coll := c.client.Database(c.dbName).Collection(entity)
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("export query failed: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []map[string]interface{}
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decode failed: %w", err)
+		}
+		results = append(results, doc)
+	}
+	return results, cursor.Err()
 }
@@ -22,7 +22,11 @@ func NewMySQLConnector() *MySQLConnector {
 }
 
 // Compile-time check
-var _ discovery.Connector = (*MySQLConnector)(nil)
+var (
+	_ discovery.Connector     = (*MySQLConnector)(nil)
+	_ discovery.RowSampler    = (*MySQLConnector)(nil)
+	_ discovery.KeyDiscoverer = (*MySQLConnector)(nil)
+)
 
 // Capabilities returns the supported operations for MySQL.
 func (c *MySQLConnector) Capabilities() discovery.ConnectorCapabilities {
@@ -191,6 +195,86 @@ func (c *MySQLConnector) SampleData(ctx context.Context, entity, field string, l
 	return samples, nil
 }
 
+// SampleRows returns up to limit rows with the given columns as text.
+func (c *MySQLConnector) SampleRows(ctx context.Context, entity string, fields []string, limit int) ([]map[string]string, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	selects := make([]string, len(fields))
+	for i, f := range fields {
+		selects[i] = fmt.Sprintf("CAST(%s AS CHAR)", quoteMySQL(f))
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s LIMIT %d", strings.Join(selects, ", "), quoteMySQL(entity), limit)
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query sample rows: %w", err)
+	}
+	defer rows.Close()
+
+	var results []map[string]string
+	for rows.Next() {
+		raw := make([]sql.NullString, len(fields))
+		ptrs := make([]interface{}, len(fields))
+		for i := range raw {
+			ptrs[i] = &raw[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(fields))
+		for i, f := range fields {
+			if raw[i].Valid {
+				row[f] = raw[i].String
+			}
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
+
+// DiscoverKeys reads declared primary and foreign keys of the connected
+// database from information_schema.
+func (c *MySQLConnector) DiscoverKeys(ctx context.Context) (*discovery.SchemaKeys, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	query := `
+		SELECT TABLE_NAME, COLUMN_NAME, CONSTRAINT_NAME,
+		       COALESCE(REFERENCED_TABLE_NAME, ''), COALESCE(REFERENCED_COLUMN_NAME, '')
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE()
+		  AND (CONSTRAINT_NAME = 'PRIMARY' OR REFERENCED_TABLE_NAME IS NOT NULL)
+		ORDER BY TABLE_NAME, ORDINAL_POSITION`
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := &discovery.SchemaKeys{PrimaryKeys: make(map[string][]string)}
+	for rows.Next() {
+		var table, column, constraint, refTable, refColumn string
+		if err := rows.Scan(&table, &column, &constraint, &refTable, &refColumn); err != nil {
+			return nil, err
+		}
+		if constraint == "PRIMARY" {
+			keys.PrimaryKeys[table] = append(keys.PrimaryKeys[table], column)
+			continue
+		}
+		keys.ForeignKeys = append(keys.ForeignKeys, discovery.ForeignKey{
+			Entity: table, Field: column, RefEntity: refTable, RefField: refColumn,
+		})
+	}
+	return keys, rows.Err()
+}
+
 // Close releases the MySQL connection.
 func (c *MySQLConnector) Close() error {
 	if c.db != nil {
@@ -0,0 +1,70 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFlattenJSONPaths(t *testing.T) {
+	docs := []string{
+		`{"name": "Jane", "contact": {"email": "jane@example.com", "phone": null}, "tags": ["a"]}`,
+		`{"contact": {"phone": "+91 98765 43210", "address": {"city": "Pune"}}, "dotted.key": 1}`,
+	}
+
+	paths := make(map[string]string)
+	for _, raw := range docs {
+		var doc interface{}
+		require.NoError(t, json.Unmarshal([]byte(raw), &doc))
+		flattenJSONPaths("profile", doc, 0, paths)
+	}
+
+	assert.Equal(t, map[string]string{
+		"profile.name":                 "string",
+		"profile.contact.email":        "string",
+		"profile.contact.phone":        "string", // null in the first document
+		"profile.contact.address.city": "string",
+		"profile.tags":                 "array",
+	}, paths)
+}
+
+func TestPgWhere_NestedPaths(t *testing.T) {
+	where, args := pgWhere(map[string]string{
+		"profile.contact.email": "jane@example.com",
+		"id":                    "42",
+	}, []interface{}{[]string{"ssn"}})
+
+	assert.Equal(t, `"id" = $2 AND "profile" #>> $3 = $4`, where)
+	assert.Equal(t, []interface{}{[]string{"ssn"}, "42", []string{"contact", "email"}, "jane@example.com"}, args)
+}
+
+func TestGroupPathsByColumn(t *testing.T) {
+	columns, byColumn, err := groupPathsByColumn([]string{"profile.email", "meta.ip", "profile.address.city"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"profile", "meta"}, columns)
+	assert.Equal(t, [][]string{{"email"}, {"address", "city"}}, byColumn["profile"])
+
+	_, _, err = groupPathsByColumn([]string{"email"})
+	assert.Error(t, err, "top-level columns are not paths")
+}
+
+func TestPathConnectors_RequireConnection(t *testing.T) {
+	ctx := context.Background()
+	filter := map[string]string{"profile.email": "jane@example.com"}
+
+	_, err := NewPostgresConnector().DeletePaths(ctx, "users", []string{"profile.email"}, filter)
+	assert.Error(t, err)
+	_, err = NewMongoDBConnector().DeletePaths(ctx, "users", []string{"profile.email"}, filter)
+	assert.Error(t, err)
+}
+
+func TestMongoFilter_Sorted(t *testing.T) {
+	assert.Equal(t, bson.D{
+		{Key: "customer.email", Value: "jane@example.com"},
+		{Key: "id", Value: "42"},
+	}, mongoFilter(map[string]string{"id": "42", "customer.email": "jane@example.com"}))
+}
@@ -0,0 +1,363 @@
+// Package plugin runs out-of-process connectors built with
+// pkg/connectorplugin. Each Connect launches a fresh plugin process which
+// is stopped on Close, so a crashing plugin only fails the scan or DSR
+// operation that was using it.
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/pkg/connectorplugin"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// DefaultHandshakeTimeout bounds how long a plugin may take to start and
+// complete the handshake.
+const DefaultHandshakeTimeout = 10 * time.Second
+
+// closeTimeout bounds the Close RPC sent to a plugin before it is stopped.
+const closeTimeout = 5 * time.Second
+
+// Plugin is a connector plugin executable whose manifest has been
+// verified by a successful handshake.
+type Plugin struct {
+	path             string
+	manifest         connectorplugin.Manifest
+	handshakeTimeout time.Duration
+	logger           *slog.Logger
+}
+
+// Load launches the executable at path, performs the handshake to learn
+// its manifest and negotiated capabilities, and stops it again.
+func Load(ctx context.Context, path string, handshakeTimeout time.Duration, logger *slog.Logger) (*Plugin, error) {
+	if handshakeTimeout <= 0 {
+		handshakeTimeout = DefaultHandshakeTimeout
+	}
+	proc, hs, err := launch(ctx, path, handshakeTimeout, logger)
+	if err != nil {
+		return nil, err
+	}
+	closeCtx, cancel := context.WithTimeout(ctx, closeTimeout)
+	_ = proc.client.Close(closeCtx)
+	cancel()
+	proc.stop()
+
+	return &Plugin{
+		path:             path,
+		manifest:         hs.Manifest,
+		handshakeTimeout: handshakeTimeout,
+		logger:           logger,
+	}, nil
+}
+
+// LoadAll loads every plugin in paths. Plugins that fail to load are logged
+// and skipped so one broken executable does not block startup.
+func LoadAll(ctx context.Context, paths []string, handshakeTimeout time.Duration, logger *slog.Logger) []*Plugin {
+	plugins := make([]*Plugin, 0, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		p, err := Load(ctx, path, handshakeTimeout, logger)
+		if err != nil {
+			logger.Error("failed to load connector plugin", "path", path, "error", err)
+			continue
+		}
+		logger.Info("connector plugin loaded",
+			"path", path,
+			"name", p.manifest.Name,
+			"version", p.manifest.Version,
+			"data_source_type", p.Type(),
+		)
+		plugins = append(plugins, p)
+	}
+	return plugins
+}
+
+// Type returns the data source type served by the plugin.
+func (p *Plugin) Type() types.DataSourceType {
+	return types.NormalizeDataSourceType(p.manifest.DataSourceType)
+}
+
+// Manifest returns the plugin's manifest with negotiated capabilities.
+func (p *Plugin) Manifest() connectorplugin.Manifest {
+	return p.manifest
+}
+
+// NewConnector returns a connector backed by this plugin. Its signature
+// matches connector.ConnectorFactory. Plugins that negotiated SupportsScan
+// get a connector that also implements Scan.
+func (p *Plugin) NewConnector() discovery.Connector {
+	c := &Connector{plugin: p}
+	if p.manifest.Capabilities.SupportsScan {
+		return &ScanningConnector{Connector: c}
+	}
+	return c
+}
+
+// =============================================================================
+// Connector — discovery.Connector backed by a plugin process
+// =============================================================================
+
+// Connector adapts a plugin process to discovery.Connector.
+type Connector struct {
+	plugin *Plugin
+	proc   *process
+	caps   connectorplugin.Capabilities
+}
+
+// Compile-time check
+var _ discovery.Connector = (*Connector)(nil)
+
+// Capabilities returns the capabilities negotiated with the plugin.
+func (c *Connector) Capabilities() discovery.ConnectorCapabilities {
+	caps := c.plugin.manifest.Capabilities
+	if c.proc != nil {
+		caps = c.caps
+	}
+	return toDomainCapabilities(caps)
+}
+
+// Connect starts the plugin process and forwards the data source to it.
+func (c *Connector) Connect(ctx context.Context, ds *discovery.DataSource) error {
+	if c.proc != nil {
+		return fmt.Errorf("plugin %s: already connected", c.plugin.manifest.Name)
+	}
+
+	proc, hs, err := launch(ctx, c.plugin.path, c.plugin.handshakeTimeout, c.plugin.logger)
+	if err != nil {
+		return err
+	}
+	// The executable may have been replaced since it was loaded.
+	if types.NormalizeDataSourceType(hs.Manifest.DataSourceType) != c.plugin.Type() {
+		proc.kill()
+		return fmt.Errorf("plugin %s now serves %s, expected %s", c.plugin.path, hs.Manifest.DataSourceType, c.plugin.Type())
+	}
+
+	if err := proc.client.Connect(ctx, toWireDataSource(ds)); err != nil {
+		err = proc.wrap(err)
+		proc.kill()
+		return fmt.Errorf("plugin %s connect: %w", c.plugin.manifest.Name, err)
+	}
+
+	c.proc = proc
+	c.caps = hs.Manifest.Capabilities
+	return nil
+}
+
+// DiscoverSchema forwards to the plugin.
+func (c *Connector) DiscoverSchema(ctx context.Context, input discovery.DiscoveryInput) (*discovery.DataInventory, []discovery.DataEntity, error) {
+	if err := c.ready(); err != nil {
+		return nil, nil, err
+	}
+	resp, err := c.proc.client.DiscoverSchema(ctx, connectorplugin.DiscoveryInput{ChangedSince: input.ChangedSince})
+	if err != nil {
+		return nil, nil, c.fail("discover schema", err)
+	}
+
+	inv := &discovery.DataInventory{
+		TotalEntities: resp.Inventory.TotalEntities,
+		TotalFields:   resp.Inventory.TotalFields,
+		LastScannedAt: time.Now(),
+	}
+	entities := make([]discovery.DataEntity, 0, len(resp.Entities))
+	for _, e := range resp.Entities {
+		entityType := discovery.EntityType(e.Type)
+		if entityType == "" {
+			entityType = discovery.EntityTypeTable
+		}
+		entities = append(entities, discovery.DataEntity{
+			Name:     e.Name,
+			Schema:   e.Schema,
+			Type:     entityType,
+			RowCount: e.RowCount,
+		})
+	}
+	return inv, entities, nil
+}
+
+// GetFields forwards to the plugin.
+func (c *Connector) GetFields(ctx context.Context, entityID string) ([]discovery.DataField, error) {
+	if err := c.ready(); err != nil {
+		return nil, err
+	}
+	fields, err := c.proc.client.GetFields(ctx, entityID)
+	if err != nil {
+		return nil, c.fail("get fields", err)
+	}
+	result := make([]discovery.DataField, 0, len(fields))
+	for _, f := range fields {
+		result = append(result, discovery.DataField{
+			Name:         f.Name,
+			DataType:     f.DataType,
+			Nullable:     f.Nullable,
+			IsPrimaryKey: f.IsPrimaryKey,
+			IsForeignKey: f.IsForeignKey,
+		})
+	}
+	return result, nil
+}
+
+// SampleData forwards to the plugin.
+func (c *Connector) SampleData(ctx context.Context, entity, field string, limit int) ([]string, error) {
+	if err := c.ready(); err != nil {
+		return nil, err
+	}
+	if !c.caps.CanSample {
+		return nil, fmt.Errorf("plugin %s does not support sampling", c.plugin.manifest.Name)
+	}
+	values, err := c.proc.client.SampleData(ctx, entity, field, limit)
+	if err != nil {
+		return nil, c.fail("sample data", err)
+	}
+	return values, nil
+}
+
+// Delete forwards to the plugin.
+func (c *Connector) Delete(ctx context.Context, entity string, filter map[string]string) (int64, error) {
+	if err := c.ready(); err != nil {
+		return 0, err
+	}
+	if !c.caps.CanDelete {
+		return 0, fmt.Errorf("plugin %s does not support delete", c.plugin.manifest.Name)
+	}
+	n, err := c.proc.client.Delete(ctx, entity, filter)
+	if err != nil {
+		return 0, c.fail("delete", err)
+	}
+	return n, nil
+}
+
+// Export forwards to the plugin.
+func (c *Connector) Export(ctx context.Context, entity string, filter map[string]string) ([]map[string]interface{}, error) {
+	if err := c.ready(); err != nil {
+		return nil, err
+	}
+	if !c.caps.CanExport {
+		return nil, fmt.Errorf("plugin %s does not support export", c.plugin.manifest.Name)
+	}
+	records, err := c.proc.client.Export(ctx, entity, filter)
+	if err != nil {
+		return nil, c.fail("export", err)
+	}
+	return records, nil
+}
+
+// Close asks the plugin to release its resources and stops the process.
+func (c *Connector) Close() error {
+	if c.proc == nil {
+		return nil
+	}
+	proc := c.proc
+	c.proc = nil
+
+	if !proc.alive() {
+		proc.kill()
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	err := proc.client.Close(ctx)
+	proc.stop()
+	return proc.wrap(err)
+}
+
+func (c *Connector) ready() error {
+	if c.proc == nil {
+		return fmt.Errorf("plugin %s: not connected", c.plugin.manifest.Name)
+	}
+	if !c.proc.alive() {
+		return fmt.Errorf("plugin %s: %w", c.plugin.manifest.Name, ErrPluginExited)
+	}
+	return nil
+}
+
+func (c *Connector) fail(op string, err error) error {
+	return fmt.Errorf("plugin %s %s: %w", c.plugin.manifest.Name, op, c.proc.wrap(err))
+}
+
+// =============================================================================
+// ScanningConnector — plugins that negotiated SupportsScan
+// =============================================================================
+
+// ScanningConnector is a plugin connector that drives its own traversal
+// and detection. It satisfies connector.ScannableConnector.
+type ScanningConnector struct {
+	*Connector
+}
+
+// Compile-time check
+var _ discovery.ScannableConnector = (*ScanningConnector)(nil)
+
+// Scan streams findings from the plugin.
+func (c *ScanningConnector) Scan(ctx context.Context, ds *discovery.DataSource, onFinding func(discovery.PIIClassification)) error {
+	if err := c.ready(); err != nil {
+		return err
+	}
+	err := c.proc.client.Scan(ctx, toWireDataSource(ds), func(f connectorplugin.Finding) {
+		onFinding(toDomainClassification(ds, f))
+	})
+	if err != nil {
+		return c.fail("scan", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// Mapping
+// =============================================================================
+
+func toWireDataSource(ds *discovery.DataSource) connectorplugin.DataSource {
+	return connectorplugin.DataSource{
+		ID:          ds.ID.String(),
+		TenantID:    ds.TenantID.String(),
+		Name:        ds.Name,
+		Type:        string(ds.Type),
+		Host:        ds.Host,
+		Port:        ds.Port,
+		Database:    ds.Database,
+		Credentials: ds.Credentials,
+		Config:      ds.Config,
+	}
+}
+
+func toDomainCapabilities(c connectorplugin.Capabilities) discovery.ConnectorCapabilities {
+	return discovery.ConnectorCapabilities{
+		CanDiscover:             c.CanDiscover,
+		CanSample:               c.CanSample,
+		CanDelete:               c.CanDelete,
+		CanUpdate:               c.CanUpdate,
+		CanExport:               c.CanExport,
+		SupportsStreaming:       c.SupportsStreaming,
+		SupportsIncremental:     c.SupportsIncremental,
+		SupportsSchemaDiscovery: c.SupportsSchemaDiscovery,
+		SupportsDataSampling:    c.SupportsDataSampling,
+		SupportsParallelScan:    c.SupportsParallelScan,
+		MaxConcurrency:          c.MaxConcurrency,
+	}
+}
+
+func toDomainClassification(ds *discovery.DataSource, f connectorplugin.Finding) discovery.PIIClassification {
+	method := types.DetectionMethod(f.DetectionMethod)
+	if method == "" {
+		method = types.DetectionMethodRegex
+	}
+	return discovery.PIIClassification{
+		DataSourceID:    ds.ID,
+		EntityName:      f.EntityName,
+		FieldName:       f.FieldName,
+		Category:        types.PIICategory(f.Category),
+		Type:            types.PIIType(f.Type),
+		Sensitivity:     types.SensitivityLevel(f.Sensitivity),
+		Confidence:      f.Confidence,
+		DetectionMethod: method,
+		Status:          types.VerificationPending,
+		Reasoning:       f.Reasoning,
+	}
+}
@@ -0,0 +1,207 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/pkg/connectorplugin"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// TestMain doubles as the plugin executable: when launched by the host
+// (magic cookie set) the test binary serves testPlugin instead of running
+// the tests.
+func TestMain(m *testing.M) {
+	if os.Getenv(connectorplugin.MagicCookieKey) == connectorplugin.MagicCookieValue {
+		if err := connectorplugin.Serve(testManifest, &testPlugin{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+var testManifest = connectorplugin.Manifest{
+	Name:           "inhouse-crm",
+	Version:        "1.0.0",
+	DataSourceType: "inhouse_crm",
+	Capabilities: connectorplugin.Capabilities{
+		CanDiscover:  true,
+		CanSample:    true,
+		CanExport:    true,
+		SupportsScan: true,
+	},
+}
+
+type testPlugin struct{}
+
+func (p *testPlugin) Connect(_ context.Context, ds *connectorplugin.DataSource) error {
+	if ds.Credentials != "secret" {
+		return errors.New("invalid credentials")
+	}
+	return nil
+}
+
+func (p *testPlugin) DiscoverSchema(_ context.Context, _ connectorplugin.DiscoveryInput) (*connectorplugin.Inventory, []connectorplugin.Entity, error) {
+	return &connectorplugin.Inventory{TotalEntities: 1, TotalFields: 1}, []connectorplugin.Entity{{Name: "customers"}}, nil
+}
+
+func (p *testPlugin) GetFields(_ context.Context, entity string) ([]connectorplugin.Field, error) {
+	if entity == "crash" {
+		os.Exit(3)
+	}
+	return []connectorplugin.Field{{Name: "email", DataType: "text"}}, nil
+}
+
+func (p *testPlugin) SampleData(_ context.Context, _, _ string, _ int) ([]string, error) {
+	return []string{"jane@example.com"}, nil
+}
+
+func (p *testPlugin) Delete(_ context.Context, _ string, _ map[string]string) (int64, error) {
+	return 0, errors.New("unreachable: delete not advertised")
+}
+
+func (p *testPlugin) Export(_ context.Context, _ string, _ map[string]string) ([]map[string]any, error) {
+	return []map[string]any{{"email": "jane@example.com"}}, nil
+}
+
+func (p *testPlugin) Close() error { return nil }
+
+func (p *testPlugin) Scan(_ context.Context, _ *connectorplugin.DataSource, onFinding func(connectorplugin.Finding) error) error {
+	return onFinding(connectorplugin.Finding{
+		EntityName: "customers", FieldName: "email",
+		Category: "CONTACT", Type: "EMAIL", Sensitivity: "MEDIUM", Confidence: 0.95,
+	})
+}
+
+func loadTestPlugin(t *testing.T) *Plugin {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	p, err := Load(context.Background(), os.Args[0], 10*time.Second, logger)
+	require.NoError(t, err)
+	return p
+}
+
+func testDataSource() *discovery.DataSource {
+	ds := &discovery.DataSource{
+		Name:        "CRM",
+		Type:        "INHOUSE_CRM",
+		Credentials: "secret",
+	}
+	ds.ID = types.NewID()
+	ds.TenantID = types.NewID()
+	return ds
+}
+
+func TestLoad_Handshake(t *testing.T) {
+	p := loadTestPlugin(t)
+
+	assert.Equal(t, types.DataSourceType("INHOUSE_CRM"), p.Type())
+	assert.Equal(t, "inhouse-crm", p.Manifest().Name)
+
+	conn := p.NewConnector()
+	caps := conn.Capabilities()
+	assert.True(t, caps.CanDiscover)
+	assert.False(t, caps.CanDelete)
+
+	_, ok := conn.(discovery.ScannableConnector)
+	assert.True(t, ok, "plugin advertising SupportsScan should be scannable")
+}
+
+func TestLoad_InvalidExecutable(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	_, err := Load(context.Background(), "/nonexistent/plugin", time.Second, logger)
+	assert.Error(t, err)
+
+	plugins := LoadAll(context.Background(), []string{"/nonexistent/plugin"}, time.Second, logger)
+	assert.Empty(t, plugins)
+}
+
+func TestConnector_RoundTrip(t *testing.T) {
+	p := loadTestPlugin(t)
+	conn := p.NewConnector()
+	ctx := context.Background()
+
+	bad := testDataSource()
+	bad.Credentials = "wrong"
+	assert.Error(t, conn.Connect(ctx, bad))
+
+	ds := testDataSource()
+	require.NoError(t, conn.Connect(ctx, ds))
+	defer conn.Close()
+
+	inv, entities, err := conn.DiscoverSchema(ctx, discovery.DiscoveryInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, inv.TotalEntities)
+	require.Len(t, entities, 1)
+	assert.Equal(t, discovery.EntityTypeTable, entities[0].Type)
+
+	fields, err := conn.GetFields(ctx, "customers")
+	require.NoError(t, err)
+	assert.Equal(t, "email", fields[0].Name)
+
+	samples, err := conn.SampleData(ctx, "customers", "email", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jane@example.com"}, samples)
+
+	_, err = conn.Delete(ctx, "customers", map[string]string{"email": "jane@example.com"})
+	assert.ErrorContains(t, err, "does not support delete")
+
+	var findings []discovery.PIIClassification
+	require.NoError(t, conn.(discovery.ScannableConnector).Scan(ctx, ds, func(c discovery.PIIClassification) {
+		findings = append(findings, c)
+	}))
+	require.Len(t, findings, 1)
+	assert.Equal(t, ds.ID, findings[0].DataSourceID)
+	assert.Equal(t, types.PIIType("EMAIL"), findings[0].Type)
+	assert.Equal(t, types.VerificationPending, findings[0].Status)
+
+	require.NoError(t, conn.Close())
+}
+
+func TestConnector_CrashIsolation(t *testing.T) {
+	p := loadTestPlugin(t)
+	conn := p.NewConnector()
+	ctx := context.Background()
+
+	require.NoError(t, conn.Connect(ctx, testDataSource()))
+
+	_, err := conn.GetFields(ctx, "crash")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrPluginExited)
+
+	// Subsequent calls fail fast instead of hanging, and Close is clean.
+	_, err = conn.GetFields(ctx, "customers")
+	assert.ErrorIs(t, err, ErrPluginExited)
+	assert.NoError(t, conn.Close())
+
+	// A fresh connector gets a fresh process.
+	conn = p.NewConnector()
+	require.NoError(t, conn.Connect(ctx, testDataSource()))
+	_, err = conn.GetFields(ctx, "customers")
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Close())
+}
+
+func TestParseHandshakeLine(t *testing.T) {
+	addr, err := parseHandshakeLine("DATALENS_PLUGIN|1|127.0.0.1:4000\n")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:4000", addr)
+
+	_, err = parseHandshakeLine("DATALENS_PLUGIN|99|127.0.0.1:4000")
+	assert.ErrorContains(t, err, "unsupported plugin protocol version")
+
+	_, err = parseHandshakeLine("hello world")
+	assert.Error(t, err)
+}
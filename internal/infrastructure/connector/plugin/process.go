@@ -0,0 +1,243 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/complyark/datalens/pkg/connectorplugin"
+)
+
+// ErrPluginExited is returned when the plugin process is no longer running,
+// typically because it crashed mid-operation.
+var ErrPluginExited = errors.New("connector plugin process exited")
+
+// stopGracePeriod is how long a plugin gets to exit after its stdin is
+// closed before it is killed.
+const stopGracePeriod = 5 * time.Second
+
+// exitProbeTimeout is how long an Unavailable RPC error waits to see
+// whether the plugin process died.
+const exitProbeTimeout = time.Second
+
+// hostCapabilities is everything the host can drive. The plugin's manifest
+// is intersected with it during the handshake.
+var hostCapabilities = connectorplugin.Capabilities{
+	CanDiscover:             true,
+	CanSample:               true,
+	CanDelete:               true,
+	CanUpdate:               true,
+	CanExport:               true,
+	SupportsStreaming:       true,
+	SupportsIncremental:     true,
+	SupportsSchemaDiscovery: true,
+	SupportsDataSampling:    true,
+	SupportsParallelScan:    true,
+	SupportsScan:            true,
+}
+
+// process is a running plugin executable and the gRPC client talking to it.
+type process struct {
+	path   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	client *connectorplugin.Client
+	logger *slog.Logger
+
+	exited  chan struct{}
+	exitErr error
+}
+
+// launch starts the plugin executable, waits for its handshake line, dials
+// it, and negotiates the protocol. On any failure the process is killed.
+func launch(ctx context.Context, path string, timeout time.Duration, logger *slog.Logger) (*process, *connectorplugin.HandshakeResponse, error) {
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(),
+		connectorplugin.MagicCookieKey+"="+connectorplugin.MagicCookieValue,
+		connectorplugin.ProtocolVersionsKey+"="+joinVersions(connectorplugin.SupportedProtocolVersions),
+	)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("plugin stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("plugin stdout: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("plugin stderr: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("start plugin %s: %w", path, err)
+	}
+
+	p := &process{
+		path:   path,
+		cmd:    cmd,
+		stdin:  stdin,
+		logger: logger.With("plugin", path, "pid", cmd.Process.Pid),
+		exited: make(chan struct{}),
+	}
+	go p.forwardLogs(stderr)
+	go func() {
+		p.exitErr = cmd.Wait()
+		close(p.exited)
+	}()
+
+	addr, err := p.readHandshakeLine(stdout, timeout)
+	if err != nil {
+		p.kill()
+		return nil, nil, err
+	}
+
+	client, err := connectorplugin.Dial(addr)
+	if err != nil {
+		p.kill()
+		return nil, nil, err
+	}
+	p.client = client
+
+	hsCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	hs, err := client.Handshake(hsCtx, hostCapabilities)
+	if err != nil {
+		p.kill()
+		return nil, nil, fmt.Errorf("plugin %s handshake: %w", path, p.wrap(err))
+	}
+	if hs.Manifest.DataSourceType == "" {
+		p.kill()
+		return nil, nil, fmt.Errorf("plugin %s handshake: manifest has no data source type", path)
+	}
+
+	return p, hs, nil
+}
+
+// readHandshakeLine waits for "DATALENS_PLUGIN|<version>|<addr>" on stdout.
+// Anything the plugin prints afterwards is forwarded to the log.
+func (p *process) readHandshakeLine(stdout io.Reader, timeout time.Duration) (string, error) {
+	lines := make(chan string, 1)
+	scanner := bufio.NewScanner(stdout)
+	go func() {
+		if scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+		for scanner.Scan() {
+			p.logger.Info("plugin output", "line", scanner.Text())
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case line, ok := <-lines:
+		if !ok {
+			return "", fmt.Errorf("plugin %s exited before handshake: %w", p.path, ErrPluginExited)
+		}
+		return parseHandshakeLine(line)
+	case <-timer.C:
+		return "", fmt.Errorf("plugin %s did not complete handshake within %s", p.path, timeout)
+	}
+}
+
+func parseHandshakeLine(line string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 3 || parts[0] != connectorplugin.HandshakePrefix {
+		return "", fmt.Errorf("invalid plugin handshake line %q", line)
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid plugin protocol version %q", parts[1])
+	}
+	supported := false
+	for _, v := range connectorplugin.SupportedProtocolVersions {
+		if v == version {
+			supported = true
+		}
+	}
+	if !supported {
+		return "", fmt.Errorf("unsupported plugin protocol version %d (host supports %v)", version, connectorplugin.SupportedProtocolVersions)
+	}
+	return parts[2], nil
+}
+
+func (p *process) forwardLogs(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.logger.Warn("plugin stderr", "line", scanner.Text())
+	}
+}
+
+// alive reports whether the plugin process is still running.
+func (p *process) alive() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// wrap annotates an RPC error with ErrPluginExited when the failure was
+// caused by the plugin process dying.
+func (p *process) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	// The broken connection is usually noticed before the process has
+	// been reaped, so give it a moment to exit.
+	if status.Code(err) == codes.Unavailable {
+		select {
+		case <-p.exited:
+		case <-time.After(exitProbeTimeout):
+		}
+	}
+	if !p.alive() {
+		return fmt.Errorf("%w (%v): %v", ErrPluginExited, p.exitErr, err)
+	}
+	return err
+}
+
+// stop asks the plugin to exit by closing its stdin and kills it if it
+// does not exit within the grace period.
+func (p *process) stop() {
+	_ = p.stdin.Close()
+	select {
+	case <-p.exited:
+	case <-time.After(stopGracePeriod):
+		p.kill()
+	}
+}
+
+func (p *process) kill() {
+	if p.alive() {
+		_ = p.cmd.Process.Kill()
+		<-p.exited
+	}
+	if p.client != nil {
+		_ = p.client.Disconnect()
+	}
+}
+
+func joinVersions(versions []int) string {
+	parts := make([]string, len(versions))
+	for i, v := range versions {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
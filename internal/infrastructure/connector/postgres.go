@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/complyark/datalens/internal/domain/discovery"
@@ -21,8 +20,12 @@ func NewPostgresConnector() *PostgresConnector {
 	return &PostgresConnector{}
 }
 
-// Compile-time check
-var _ discovery.Connector = (*PostgresConnector)(nil)
+// Compile-time checks
+var (
+	_ discovery.Connector     = (*PostgresConnector)(nil)
+	_ discovery.RowSampler    = (*PostgresConnector)(nil)
+	_ discovery.KeyDiscoverer = (*PostgresConnector)(nil)
+)
 
 // Capabilities returns the supported operations.
 // Capabilities returns the supported operations.
@@ -149,6 +152,7 @@ func (c *PostgresConnector) GetFields(ctx context.Context, entityID string) ([]d
 	defer rows.Close()
 
 	var fields []discovery.DataField
+	var jsonColumns []string
 	for rows.Next() {
 		var name, dtype, nullableStr string
 		if err := rows.Scan(&name, &dtype, &nullableStr); err != nil {
@@ -160,6 +164,20 @@ func (c *PostgresConnector) GetFields(ctx context.Context, entityID string) ([]d
 			DataType: dtype,
 			Nullable: nullableStr == "YES",
 		})
+		if isJSONType(dtype) {
+			jsonColumns = append(jsonColumns, name)
+		}
+	}
+	rows.Close()
+
+	// JSON/JSONB columns are opaque as a whole; expand them into one
+	// virtual field per key path so each path is classified on its own.
+	for _, col := range jsonColumns {
+		nested, err := c.expandJSONColumn(ctx, entityID, col)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, nested...)
 	}
 
 	return fields, nil
@@ -179,20 +197,15 @@ func (c *PostgresConnector) SampleData(ctx context.Context, entity, field string
 	// If entity has schema "schema.table", pgx.Identifier{"schema", "table"}.Sanitize() is better.
 	// But we only have string.
 	// Let's try to split by dot?
-	parts := strings.Split(entity, ".")
-	var safeEntityStr string
-	if len(parts) == 2 {
-		safeEntityStr = pgx.Identifier{parts[0], parts[1]}.Sanitize()
-	} else {
-		safeEntityStr = pgx.Identifier{entity}.Sanitize()
-	}
+	safeEntityStr := sanitizeTable(entity)
 
-	safeField := pgx.Identifier{field}.Sanitize()
+	// Nested fields ("column.key.sub") are read out of the document.
+	safeField, args := pgFieldText(field, nil)
 
-	query := fmt.Sprintf("SELECT %s::text FROM %s WHERE %s IS NOT NULL LIMIT %d",
+	query := fmt.Sprintf("SELECT (%s)::text FROM %s WHERE %s IS NOT NULL LIMIT %d",
 		safeField, safeEntityStr, safeField, limit)
 
-	rows, err := c.conn.Query(ctx, query)
+	rows, err := c.conn.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query samples: %w", err)
 	}
@@ -210,6 +223,118 @@ func (c *PostgresConnector) SampleData(ctx context.Context, entity, field string
 	return samples, nil
 }
 
+// SampleRows returns up to limit rows with the given columns or nested
+// paths as text, for analyses that need values together.
+func (c *PostgresConnector) SampleRows(ctx context.Context, entity string, fields []string, limit int) ([]map[string]string, error) {
+	if c.conn == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	selects := make([]string, len(fields))
+	for i, f := range fields {
+		var expr string
+		expr, args = pgFieldText(f, args)
+		selects[i] = fmt.Sprintf("(%s)::text", expr)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s LIMIT %d", strings.Join(selects, ", "), sanitizeTable(entity), limit)
+
+	rows, err := c.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query sample rows: %w", err)
+	}
+	defer rows.Close()
+
+	var results []map[string]string
+	for rows.Next() {
+		raw := make([]*string, len(fields))
+		ptrs := make([]interface{}, len(fields))
+		for i := range raw {
+			ptrs[i] = &raw[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(fields))
+		for i, f := range fields {
+			if raw[i] != nil {
+				row[f] = *raw[i]
+			}
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
+
+// DiscoverKeys reads declared primary and foreign keys from
+// information_schema. Composite keys are reported column by column.
+func (c *PostgresConnector) DiscoverKeys(ctx context.Context) (*discovery.SchemaKeys, error) {
+	if c.conn == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	keys := &discovery.SchemaKeys{PrimaryKeys: make(map[string][]string)}
+
+	pkQuery := `
+		SELECT kcu.table_name, kcu.column_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+		  ON kcu.constraint_name = tc.constraint_name
+		 AND kcu.constraint_schema = tc.constraint_schema
+		WHERE tc.constraint_type = 'PRIMARY KEY'
+		  AND tc.table_schema NOT IN ('information_schema', 'pg_catalog')
+		ORDER BY kcu.table_name, kcu.ordinal_position`
+
+	rows, err := c.conn.Query(ctx, pkQuery)
+	if err != nil {
+		return nil, fmt.Errorf("query primary keys: %w", err)
+	}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		keys.PrimaryKeys[table] = append(keys.PrimaryKeys[table], column)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// key_column_usage.position_in_unique_constraint pairs each referencing
+	// column with the referenced column at the same position.
+	fkQuery := `
+		SELECT kcu.table_name, kcu.column_name, ref.table_name, ref.column_name
+		FROM information_schema.referential_constraints rc
+		JOIN information_schema.key_column_usage kcu
+		  ON kcu.constraint_name = rc.constraint_name
+		 AND kcu.constraint_schema = rc.constraint_schema
+		JOIN information_schema.key_column_usage ref
+		  ON ref.constraint_name = rc.unique_constraint_name
+		 AND ref.constraint_schema = rc.unique_constraint_schema
+		 AND ref.ordinal_position = kcu.position_in_unique_constraint
+		WHERE kcu.table_schema NOT IN ('information_schema', 'pg_catalog')
+		ORDER BY kcu.table_name, kcu.column_name`
+
+	rows, err = c.conn.Query(ctx, fkQuery)
+	if err != nil {
+		return nil, fmt.Errorf("query foreign keys: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var fk discovery.ForeignKey
+		if err := rows.Scan(&fk.Entity, &fk.Field, &fk.RefEntity, &fk.RefField); err != nil {
+			return nil, err
+		}
+		keys.ForeignKeys = append(keys.ForeignKeys, fk)
+	}
+	return keys, rows.Err()
+}
+
 func (c *PostgresConnector) Close() error {
 	if c.conn != nil {
 		c.conn.Close()
@@ -229,30 +354,12 @@ func (c *PostgresConnector) Delete(ctx context.Context, entity string, filter ma
 
 	// 1. Sanitize Table Name
 	// We assume entity is "schema.table" or just "table"
-	parts := strings.Split(entity, ".")
-	var safeTable string
-	if len(parts) == 2 {
-		safeTable = pgx.Identifier{parts[0], parts[1]}.Sanitize()
-	} else {
-		safeTable = pgx.Identifier{entity}.Sanitize()
-	}
+	safeTable := sanitizeTable(entity)
 
-	// 2. Build WHERE clause dynamically
-	var conditions []string
-	var args []interface{}
-	argIdx := 1
+	// 2. Build WHERE clause; keys may be columns or nested paths
+	where, args := pgWhere(filter, nil)
 
-	// Sort keys for deterministic order (good for testing/logging, not strictly needed for SQL)
-	// But map iteration is random.
-	// For now, simple iteration.
-	for col, val := range filter {
-		safeCol := pgx.Identifier{col}.Sanitize()
-		conditions = append(conditions, fmt.Sprintf("%s = $%d", safeCol, argIdx))
-		args = append(args, val)
-		argIdx++
-	}
-
-	query := fmt.Sprintf("DELETE FROM %s WHERE %s", safeTable, strings.Join(conditions, " AND "))
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", safeTable, where)
 
 	// 3. Execute
 	tag, err := c.conn.Exec(ctx, query, args...)
@@ -270,28 +377,14 @@ func (c *PostgresConnector) Export(ctx context.Context, entity string, filter ma
 	}
 
 	// 1. Sanitize Table Name
-	parts := strings.Split(entity, ".")
-	var safeTable string
-	if len(parts) == 2 {
-		safeTable = pgx.Identifier{parts[0], parts[1]}.Sanitize()
-	} else {
-		safeTable = pgx.Identifier{entity}.Sanitize()
-	}
+	safeTable := sanitizeTable(entity)
 
-	// 2. Build WHERE clause dynamically
-	var conditions []string
-	var args []interface{}
-	argIdx := 1
-
-	for col, val := range filter {
-		conditions = append(conditions, fmt.Sprintf("%s = $%d", pgx.Identifier{col}.Sanitize(), argIdx))
-		args = append(args, val)
-		argIdx++
-	}
+	// 2. Build WHERE clause; keys may be columns or nested paths
+	where, args := pgWhere(filter, nil)
 
 	var query string
-	if len(conditions) > 0 {
-		query = fmt.Sprintf("SELECT * FROM %s WHERE %s", safeTable, strings.Join(conditions, " AND "))
+	if where != "" {
+		query = fmt.Sprintf("SELECT * FROM %s WHERE %s", safeTable, where)
 	} else {
 		// If no filter, return everything? Or error? Connector interface implies filter is for narrowing.
 		// DSR access usually means "exported data for THIS user".
@@ -0,0 +1,285 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+)
+
+// Compile-time check
+var _ discovery.PathConnector = (*PostgresConnector)(nil)
+
+const (
+	// jsonSampleDocs is how many documents are read from a JSON/JSONB column
+	// to discover its key paths.
+	jsonSampleDocs = 20
+
+	// maxJSONDepth bounds how deep nested objects are expanded.
+	maxJSONDepth = 5
+
+	// maxJSONPaths caps the virtual fields emitted per column so that
+	// free-form documents with unbounded keys do not flood the inventory.
+	maxJSONPaths = 100
+)
+
+// isJSONType reports whether an information_schema data type holds documents.
+func isJSONType(dataType string) bool {
+	return dataType == "json" || dataType == "jsonb"
+}
+
+// expandJSONColumn samples documents from a JSON/JSONB column and returns a
+// virtual field for every leaf key path found, named "column.key.sub".
+func (c *PostgresConnector) expandJSONColumn(ctx context.Context, entity, column string) ([]discovery.DataField, error) {
+	safeCol := pgx.Identifier{column}.Sanitize()
+	query := fmt.Sprintf("SELECT %s::text FROM %s WHERE %s IS NOT NULL LIMIT %d",
+		safeCol, sanitizeTable(entity), safeCol, jsonSampleDocs)
+
+	rows, err := c.conn.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("sample json column %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	paths := make(map[string]string)
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var doc interface{}
+		if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+			continue
+		}
+		flattenJSONPaths(column, doc, 0, paths)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(paths))
+	for name := range paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > maxJSONPaths {
+		names = names[:maxJSONPaths]
+	}
+
+	fields := make([]discovery.DataField, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, discovery.DataField{
+			Name:     name,
+			DataType: paths[name],
+			Nullable: true,
+		})
+	}
+	return fields, nil
+}
+
+// flattenJSONPaths records the leaf paths of a decoded JSON value and their
+// types. Objects are descended into; arrays and scalars are leaves. The
+// first non-null type seen for a path wins.
+func flattenJSONPaths(prefix string, v interface{}, depth int, out map[string]string) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for key, val := range obj {
+		if key == "" || strings.Contains(key, discovery.FieldPathSeparator) {
+			// Keys containing the separator cannot be addressed by path.
+			continue
+		}
+		path := prefix + discovery.FieldPathSeparator + key
+		if nested, isObj := val.(map[string]interface{}); isObj && depth+1 < maxJSONDepth {
+			flattenJSONPaths(path, nested, depth+1, out)
+			continue
+		}
+		if existing, seen := out[path]; !seen || existing == "null" {
+			out[path] = jsonValueType(val)
+		}
+	}
+}
+
+// jsonValueType names the JSON type of a decoded value.
+func jsonValueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// sanitizeTable quotes "schema.table" or "table" as an identifier.
+func sanitizeTable(entity string) string {
+	parts := strings.Split(entity, ".")
+	if len(parts) == 2 {
+		return pgx.Identifier{parts[0], parts[1]}.Sanitize()
+	}
+	return pgx.Identifier{entity}.Sanitize()
+}
+
+// pgFieldText returns a SQL expression for a field's value as text. Nested
+// fields read through the document with #>>, taking the key path as a
+// text[] argument appended to args.
+func pgFieldText(field string, args []interface{}) (string, []interface{}) {
+	column, keys := discovery.SplitFieldPath(field)
+	if len(keys) == 0 {
+		return pgx.Identifier{field}.Sanitize(), args
+	}
+	args = append(args, keys)
+	return fmt.Sprintf("%s #>> $%d", pgx.Identifier{column}.Sanitize(), len(args)), args
+}
+
+// pgWhere builds an AND-ed equality clause from a filter whose keys may be
+// columns or nested paths. Keys are sorted so the SQL is deterministic.
+func pgWhere(filter map[string]string, args []interface{}) (string, []interface{}) {
+	keys := make([]string, 0, len(filter))
+	for k := range filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	conditions := make([]string, 0, len(keys))
+	for _, k := range keys {
+		var expr string
+		expr, args = pgFieldText(k, args)
+		args = append(args, filter[k])
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", expr, len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// groupPathsByColumn splits nested field names into their document column
+// and key paths, preserving the order columns first appear in.
+func groupPathsByColumn(paths []string) ([]string, map[string][][]string, error) {
+	var columns []string
+	byColumn := make(map[string][][]string)
+	for _, p := range paths {
+		column, keys := discovery.SplitFieldPath(p)
+		if len(keys) == 0 {
+			return nil, nil, fmt.Errorf("field %q is not a nested path", p)
+		}
+		if _, seen := byColumn[column]; !seen {
+			columns = append(columns, column)
+		}
+		byColumn[column] = append(byColumn[column], keys)
+	}
+	return columns, byColumn, nil
+}
+
+// DeletePaths removes nested keys from JSONB documents with the #- operator,
+// leaving the rest of each row and document intact.
+func (c *PostgresConnector) DeletePaths(ctx context.Context, entity string, paths []string, filter map[string]string) (int64, error) {
+	if c.conn == nil {
+		return 0, fmt.Errorf("not connected")
+	}
+	if len(filter) == 0 {
+		return 0, fmt.Errorf("refusing to delete with empty filter")
+	}
+	if len(paths) == 0 {
+		return 0, nil
+	}
+
+	columns, byColumn, err := groupPathsByColumn(paths)
+	if err != nil {
+		return 0, err
+	}
+
+	var args []interface{}
+	sets := make([]string, 0, len(columns))
+	for _, column := range columns {
+		safeCol := pgx.Identifier{column}.Sanitize()
+		expr := safeCol
+		for _, keys := range byColumn[column] {
+			args = append(args, keys)
+			expr = fmt.Sprintf("%s #- $%d::text[]", expr, len(args))
+		}
+		sets = append(sets, fmt.Sprintf("%s = %s", safeCol, expr))
+	}
+
+	where, args := pgWhere(filter, args)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", sanitizeTable(entity), strings.Join(sets, ", "), where)
+
+	tag, err := c.conn.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete paths failed: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ExportPaths returns the values at nested paths for rows matching the
+// filter. Values are decoded from JSON, so objects and arrays keep their
+// structure in the export.
+func (c *PostgresConnector) ExportPaths(ctx context.Context, entity string, paths []string, filter map[string]string) ([]map[string]interface{}, error) {
+	if c.conn == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	selects := make([]string, 0, len(paths))
+	for _, p := range paths {
+		column, keys := discovery.SplitFieldPath(p)
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("field %q is not a nested path", p)
+		}
+		args = append(args, keys)
+		selects = append(selects, fmt.Sprintf("(%s #> $%d::text[])::text", pgx.Identifier{column}.Sanitize(), len(args)))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), sanitizeTable(entity))
+	if len(filter) > 0 {
+		var where string
+		where, args = pgWhere(filter, args)
+		query += " WHERE " + where
+	}
+
+	rows, err := c.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("export paths query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var results []map[string]interface{}
+	for rows.Next() {
+		raw := make([]*string, len(paths))
+		ptrs := make([]interface{}, len(paths))
+		for i := range raw {
+			ptrs[i] = &raw[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(paths))
+		for i, p := range paths {
+			if raw[i] == nil {
+				continue
+			}
+			var v interface{}
+			if err := json.Unmarshal([]byte(*raw[i]), &v); err != nil {
+				v = *raw[i]
+			}
+			row[p] = v
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
@@ -9,8 +9,11 @@ import (
 	"github.com/complyark/datalens/internal/infrastructure/connector/aws"
 	"github.com/complyark/datalens/internal/infrastructure/connector/azure"
 	"github.com/complyark/datalens/internal/infrastructure/connector/m365"
+	"github.com/complyark/datalens/internal/infrastructure/connector/plugin"
+	"github.com/complyark/datalens/internal/infrastructure/connector/shared"
 	"github.com/complyark/datalens/internal/service/ai"
 	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/crypto"
 	"github.com/complyark/datalens/pkg/types"
 )
 
@@ -23,12 +26,17 @@ type ConnectorFactory func() discovery.Connector
 type ConnectorRegistry struct {
 	mu        sync.RWMutex
 	factories map[types.DataSourceType]ConnectorFactory
+
+	settingsMu sync.RWMutex
+	cipher     crypto.CredentialCipher
+	secrets    *shared.SecretManager
 }
 
 // NewConnectorRegistry creates a registry pre-loaded with built-in connectors.
 func NewConnectorRegistry(cfg *config.Config, detector *detection.ComposableDetector, parser ai.ParsingService) *ConnectorRegistry {
 	r := &ConnectorRegistry{
 		factories: make(map[types.DataSourceType]ConnectorFactory),
+		cipher:    crypto.NewStaticCipher(cfg.App.SecretKey),
 	}
 
 	// Register built-in connectors
@@ -66,22 +74,22 @@ func NewConnectorRegistry(cfg *config.Config, detector *detection.ComposableDete
 
 	// Microsoft Connectors
 	r.Register(types.DataSourceOutlook, func() discovery.Connector {
-		return m365.NewOutlookConnector(cfg)
+		return m365.NewOutlookConnector(cfg, r.credentialCipher())
 	})
 	r.Register(types.DataSourceMicrosoft365, func() discovery.Connector {
 		// Prefer the one in connector package that uses detector/filescanner
-		return NewM365Connector(detector)
+		return NewM365Connector(detector, r.credentialCipher())
 	})
 	r.Register(types.DataSourceOneDrive, func() discovery.Connector {
-		return NewM365Connector(detector)
+		return NewM365Connector(detector, r.credentialCipher())
 	})
 
 	// Google Connectors
 	r.Register(types.DataSourceGoogleDrive, func() discovery.Connector {
-		return NewGoogleConnector(cfg, detector)
+		return NewGoogleConnector(cfg, detector, r.credentialCipher())
 	})
 	r.Register(types.DataSourceGoogleWorkspace, func() discovery.Connector {
-		return NewGoogleConnector(cfg, detector)
+		return NewGoogleConnector(cfg, detector, r.credentialCipher())
 	})
 
 	// File Upload Connector
@@ -90,6 +98,30 @@ func NewConnectorRegistry(cfg *config.Config, detector *detection.ComposableDete
 	return r
 }
 
+// SetCredentialCipher replaces the cipher that OAuth-based connectors use
+// to decrypt stored credentials. It defaults to the static APP_SECRET_KEY
+// cipher.
+func (r *ConnectorRegistry) SetCredentialCipher(cipher crypto.CredentialCipher) {
+	r.settingsMu.Lock()
+	defer r.settingsMu.Unlock()
+	r.cipher = cipher
+}
+
+func (r *ConnectorRegistry) credentialCipher() crypto.CredentialCipher {
+	r.settingsMu.RLock()
+	defer r.settingsMu.RUnlock()
+	return r.cipher
+}
+
+// SetSecretManager enables secret references in data source credentials.
+// Connectors returned by GetConnector then resolve references at Connect
+// (and Scan) time; without a manager, references are passed through as-is.
+func (r *ConnectorRegistry) SetSecretManager(secrets *shared.SecretManager) {
+	r.settingsMu.Lock()
+	defer r.settingsMu.Unlock()
+	r.secrets = secrets
+}
+
 // Register adds a connector factory for a data source type.
 // Overwrites any existing factory for the same type.
 func (r *ConnectorRegistry) Register(dsType types.DataSourceType, factory ConnectorFactory) {
@@ -98,6 +130,28 @@ func (r *ConnectorRegistry) Register(dsType types.DataSourceType, factory Connec
 	r.factories[dsType] = factory
 }
 
+// RegisterPlugins registers out-of-process connector plugins. Plugins may
+// only add new data source types; a plugin claiming a type that is already
+// registered is rejected so it cannot shadow a built-in connector.
+func (r *ConnectorRegistry) RegisterPlugins(plugins []*plugin.Plugin) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var conflicts []string
+	for _, p := range plugins {
+		dsType := p.Type()
+		if _, exists := r.factories[dsType]; exists {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", dsType, p.Manifest().Name))
+			continue
+		}
+		r.factories[dsType] = p.NewConnector
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("connector plugins conflict with registered types: %v", conflicts)
+	}
+	return nil
+}
+
 // GetConnector returns a new Connector for the given data source type.
 func (r *ConnectorRegistry) GetConnector(dsType types.DataSourceType) (discovery.Connector, error) {
 	r.mu.RLock()
@@ -110,7 +164,15 @@ func (r *ConnectorRegistry) GetConnector(dsType types.DataSourceType) (discovery
 	if !ok {
 		return nil, fmt.Errorf("unsupported data source type: %s (normalized: %s, registered: %v)", dsType, normalized, r.SupportedTypes())
 	}
-	return factory(), nil
+	conn := factory()
+
+	r.settingsMu.RLock()
+	secrets := r.secrets
+	r.settingsMu.RUnlock()
+	if secrets != nil {
+		conn = withSecretResolution(conn, secrets)
+	}
+	return conn, nil
 }
 
 // SupportedTypes returns a slice of all registered data source types.
@@ -2,6 +2,8 @@ package connector
 
 import (
 	"context"
+	"io"
+	"log/slog"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/complyark/datalens/internal/config"
 	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/infrastructure/connector/shared"
 	"github.com/complyark/datalens/pkg/types"
 )
 
@@ -43,6 +46,16 @@ func (m *MockConnector) Capabilities() discovery.ConnectorCapabilities {
 	return discovery.ConnectorCapabilities{CanDiscover: true, CanSample: true}
 }
 
+func (m *MockConnector) Delete(ctx context.Context, entity string, filter map[string]string) (int64, error) {
+	args := m.Called(ctx, entity, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockConnector) Export(ctx context.Context, entity string, filter map[string]string) ([]map[string]interface{}, error) {
+	args := m.Called(ctx, entity, filter)
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
 func (m *MockConnector) Close() error {
 	return m.Called().Error(0)
 }
@@ -107,3 +120,61 @@ func TestConnectorRegistry_SupportedTypes(t *testing.T) {
 	assert.Contains(t, supportedTypes, types.DataSourcePostgreSQL)
 	assert.Contains(t, supportedTypes, types.DataSourceMySQL)
 }
+
+func TestConnectorRegistry_ResolvesSecretRefs(t *testing.T) {
+	tenantID := types.NewID()
+	secretVar := "DATALENS_SECRET_" + shared.EnvTenantID(tenantID) + "_DB"
+	t.Setenv(secretVar, "user:pass")
+
+	registry := NewConnectorRegistry(&config.Config{}, nil, nil)
+	secrets, err := NewSecretManager(config.SecretsConfig{EnvPrefix: "DATALENS_SECRET_{tenant_id}_"}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	registry.SetSecretManager(secrets)
+
+	mockConn := &MockConnector{}
+	registry.Register("MOCK", func() discovery.Connector { return mockConn })
+
+	ds := &discovery.DataSource{Type: "MOCK", Credentials: "secretref:env://" + secretVar}
+	ds.TenantID = tenantID
+	mockConn.On("Connect", mock.Anything, mock.MatchedBy(func(got *discovery.DataSource) bool {
+		return got.Credentials == "user:pass"
+	})).Return(nil)
+
+	conn, err := registry.GetConnector("MOCK")
+	require.NoError(t, err)
+	require.NoError(t, conn.Connect(context.Background(), ds))
+
+	mockConn.AssertExpectations(t)
+	assert.Equal(t, "secretref:env://"+secretVar, ds.Credentials, "caller's data source must keep the reference")
+	assert.Same(t, mockConn, Unwrap(conn))
+
+	ds.Credentials = "secretref:env://HOME"
+	assert.ErrorIs(t, conn.Connect(context.Background(), ds), shared.ErrSecretRefDenied)
+
+	// Another tenant cannot reach this tenant's variables
+	other := *ds
+	other.TenantID = types.NewID()
+	other.Credentials = "secretref:env://" + secretVar
+	assert.ErrorIs(t, conn.Connect(context.Background(), &other), shared.ErrSecretRefDenied)
+}
+
+func TestNewSecretManager_RequiresTenantScope(t *testing.T) {
+	t.Setenv("DATALENS_SECRET_DB", "user:pass")
+	secrets, err := NewSecretManager(config.SecretsConfig{EnvPrefix: "DATALENS_SECRET_", FileDirs: []string{"/run/secrets"}}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+
+	ds := &discovery.DataSource{Credentials: "secretref:env://DATALENS_SECRET_DB"}
+	ds.TenantID = types.NewID()
+	_, err = secrets.ResolveCredentials(context.Background(), ds)
+	assert.Error(t, err, "unscoped env prefix must not be registered")
+
+	// An unscoped Vault path is dropped, so no tenant can read below it
+	secrets, err = NewSecretManager(config.SecretsConfig{
+		VaultAddr:         "http://127.0.0.1:1",
+		VaultAllowedPaths: []string{"secret/data/shared", "secret/data/datalens/{tenant_id}"},
+	}, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	ds.Credentials = "secretref:vault://secret/data/shared/db#password"
+	_, err = secrets.ResolveCredentials(context.Background(), ds)
+	assert.ErrorIs(t, err, shared.ErrSecretRefDenied)
+}
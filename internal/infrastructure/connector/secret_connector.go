@@ -0,0 +1,124 @@
+package connector
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/complyark/datalens/internal/config"
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/infrastructure/connector/shared"
+)
+
+// NewSecretManager builds a SecretManager with the env and file resolvers
+// and, when VAULT_ADDR is set, the Vault KV resolver. Every tenant shares
+// the API's environment, filesystem and Vault token, so only prefixes,
+// directories and Vault paths scoped per tenant with "{tenant_id}" are used.
+func NewSecretManager(cfg config.SecretsConfig, auditor shared.SecretAuditor, logger *slog.Logger) (*shared.SecretManager, error) {
+	m := shared.NewSecretManager(cfg.CacheTTL, auditor, logger)
+	if shared.IsTenantScoped(cfg.EnvPrefix) {
+		m.Register(shared.SecretSchemeEnv, shared.NewEnvSecretResolver(cfg.EnvPrefix))
+	} else if cfg.EnvPrefix != "" {
+		logger.Warn("env secret references disabled: SECRETS_ENV_PREFIX must contain {tenant_id}", "prefix", cfg.EnvPrefix)
+	}
+	if dirs := tenantScoped(cfg.FileDirs, "SECRETS_FILE_DIRS", logger); len(dirs) > 0 {
+		m.Register(shared.SecretSchemeFile, shared.NewFileSecretResolver(dirs))
+	}
+	if cfg.VaultAddr != "" {
+		vault, err := shared.NewVaultSecretResolver(shared.VaultSecretConfig{
+			Address:      cfg.VaultAddr,
+			Token:        cfg.VaultToken,
+			Namespace:    cfg.VaultNamespace,
+			AllowedPaths: tenantScoped(cfg.VaultAllowedPaths, "SECRETS_VAULT_ALLOWED_PATHS", logger),
+		})
+		if err != nil {
+			return nil, err
+		}
+		m.Register(shared.SecretSchemeVault, vault)
+	}
+	return m, nil
+}
+
+// tenantScoped returns the entries of the named setting that contain
+// "{tenant_id}", warning about the rest.
+func tenantScoped(entries []string, setting string, logger *slog.Logger) []string {
+	var scoped []string
+	for _, e := range entries {
+		if shared.IsTenantScoped(e) {
+			scoped = append(scoped, e)
+		} else if strings.TrimSpace(e) != "" {
+			logger.Warn("secret location ignored: "+setting+" entries must contain {tenant_id}", "entry", e)
+		}
+	}
+	return scoped
+}
+
+// secretResolvingConnector resolves secret references in the data source's
+// credentials before handing it to the wrapped connector. The caller's
+// DataSource is never modified, so resolved secrets are not persisted.
+type secretResolvingConnector struct {
+	discovery.Connector
+	secrets *shared.SecretManager
+}
+
+// secretResolvingScanner additionally preserves ScannableConnector.
+type secretResolvingScanner struct {
+	secretResolvingConnector
+	scanner ScannableConnector
+}
+
+func withSecretResolution(conn discovery.Connector, secrets *shared.SecretManager) discovery.Connector {
+	base := secretResolvingConnector{Connector: conn, secrets: secrets}
+	if scanner, ok := conn.(ScannableConnector); ok {
+		return &secretResolvingScanner{secretResolvingConnector: base, scanner: scanner}
+	}
+	return &base
+}
+
+func (c *secretResolvingConnector) resolved(ctx context.Context, ds *discovery.DataSource) (*discovery.DataSource, error) {
+	if !shared.IsSecretRef(ds.Credentials) {
+		return ds, nil
+	}
+	creds, err := c.secrets.ResolveCredentials(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+	resolved := *ds
+	resolved.Credentials = creds
+	return &resolved, nil
+}
+
+// Connect resolves credentials and connects the wrapped connector.
+func (c *secretResolvingConnector) Connect(ctx context.Context, ds *discovery.DataSource) error {
+	resolved, err := c.resolved(ctx, ds)
+	if err != nil {
+		return err
+	}
+	return c.Connector.Connect(ctx, resolved)
+}
+
+// Unwrap returns the wrapped connector.
+func (c *secretResolvingConnector) Unwrap() discovery.Connector {
+	return c.Connector
+}
+
+// Scan resolves credentials and scans with the wrapped connector.
+func (c *secretResolvingScanner) Scan(ctx context.Context, ds *discovery.DataSource, callback func(discovery.PIIClassification)) error {
+	resolved, err := c.resolved(ctx, ds)
+	if err != nil {
+		return err
+	}
+	return c.scanner.Scan(ctx, resolved, callback)
+}
+
+// Unwrap returns the connector underneath any wrappers added by the
+// registry, for callers that need connector-specific methods.
+func Unwrap(conn discovery.Connector) discovery.Connector {
+	for {
+		w, ok := conn.(interface{ Unwrap() discovery.Connector })
+		if !ok {
+			return conn
+		}
+		conn = w.Unwrap()
+	}
+}
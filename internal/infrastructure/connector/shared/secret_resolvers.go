@@ -0,0 +1,231 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// maxSecretSize bounds how much of a secret file or Vault response is read.
+const maxSecretSize = 64 << 10
+
+// tenantPlaceholder in an allowed prefix, directory or Vault path is
+// replaced with the tenant resolving the reference.
+const tenantPlaceholder = "{tenant_id}"
+
+// EnvSecretResolver resolves env:// references from the process
+// environment. Only variables starting with the configured prefix can be
+// referenced, so tenants cannot read unrelated settings such as database
+// passwords or signing keys. "{tenant_id}" in the prefix is replaced with
+// the tenant ID, upper-cased with dashes as underscores, so each tenant
+// only reaches its own variables. An empty prefix rejects every reference.
+type EnvSecretResolver struct {
+	prefix string
+	lookup func(string) (string, bool)
+}
+
+// NewEnvSecretResolver creates an EnvSecretResolver.
+func NewEnvSecretResolver(prefix string) *EnvSecretResolver {
+	return &EnvSecretResolver{prefix: prefix, lookup: os.LookupEnv}
+}
+
+// Resolve implements SecretResolver.
+func (r *EnvSecretResolver) Resolve(_ context.Context, tenantID types.ID, ref SecretRef) (string, error) {
+	prefix := strings.ReplaceAll(r.prefix, tenantPlaceholder, EnvTenantID(tenantID))
+	if prefix == "" || !strings.HasPrefix(ref.Path, prefix) {
+		return "", fmt.Errorf("%w: environment variable %s does not start with %q", ErrSecretRefDenied, ref.Path, prefix)
+	}
+	value, ok := r.lookup(ref.Path)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %s", ErrSecretNotFound, ref.Path)
+	}
+	return selectSecretKey(value, ref)
+}
+
+// EnvTenantID returns a tenant ID as it appears in environment variable
+// names: upper-cased, with dashes as underscores.
+func EnvTenantID(tenantID types.ID) string {
+	return strings.ToUpper(strings.ReplaceAll(tenantID.String(), "-", "_"))
+}
+
+// IsTenantScoped reports whether an allowed prefix, directory or path
+// contains the "{tenant_id}" placeholder.
+func IsTenantScoped(pattern string) bool {
+	return strings.Contains(pattern, tenantPlaceholder)
+}
+
+// FileSecretResolver resolves file:// references to mounted secrets, such
+// as Kubernetes or Docker secrets on an agent host. Files must live under
+// one of the allowed directories after symlinks are resolved.
+// "{tenant_id}" in a directory is replaced with the tenant ID.
+type FileSecretResolver struct {
+	dirs []string
+}
+
+// NewFileSecretResolver creates a FileSecretResolver for the given
+// directories.
+func NewFileSecretResolver(dirs []string) *FileSecretResolver {
+	var clean []string
+	for _, d := range dirs {
+		if d = strings.TrimSpace(d); d != "" {
+			clean = append(clean, filepath.Clean(d))
+		}
+	}
+	return &FileSecretResolver{dirs: clean}
+}
+
+// Resolve implements SecretResolver.
+func (r *FileSecretResolver) Resolve(_ context.Context, tenantID types.ID, ref SecretRef) (string, error) {
+	p := filepath.Clean(ref.Path)
+	if !r.allowed(tenantID, p) {
+		return "", fmt.Errorf("%w: %s is outside the allowed secret directories", ErrSecretRefDenied, p)
+	}
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: %s", ErrSecretNotFound, p)
+		}
+		return "", fmt.Errorf("resolve secret file %s: %w", p, err)
+	}
+	if !r.allowed(tenantID, resolved) {
+		return "", fmt.Errorf("%w: %s links outside the allowed secret directories", ErrSecretRefDenied, p)
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return "", fmt.Errorf("open secret file %s: %w", p, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxSecretSize))
+	if err != nil {
+		return "", fmt.Errorf("read secret file %s: %w", p, err)
+	}
+	return selectSecretKey(strings.TrimRight(string(data), "\r\n"), ref)
+}
+
+func (r *FileSecretResolver) allowed(tenantID types.ID, p string) bool {
+	for _, dir := range r.dirs {
+		dir = strings.ReplaceAll(dir, tenantPlaceholder, tenantID.String())
+		candidates := []string{dir}
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil && resolved != dir {
+			candidates = append(candidates, resolved)
+		}
+		for _, d := range candidates {
+			if rel, err := filepath.Rel(d, p); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VaultSecretConfig configures a VaultSecretResolver.
+type VaultSecretConfig struct {
+	Address   string // e.g. https://vault.internal:8200
+	Token     string
+	Namespace string
+	// AllowedPaths are path prefixes references may read. "{tenant_id}" is
+	// replaced with the tenant resolving the reference.
+	AllowedPaths []string
+	Client       *http.Client
+}
+
+// VaultSecretResolver resolves vault:// references against the HashiCorp
+// Vault KV secrets engine. The path is the full API path below /v1, so KV
+// v2 paths include "data/" (e.g. "secret/data/datalens/<tenant>/db"); both
+// KV v1 and v2 responses are understood.
+type VaultSecretResolver struct {
+	cfg    VaultSecretConfig
+	client *http.Client
+}
+
+// NewVaultSecretResolver creates a VaultSecretResolver.
+func NewVaultSecretResolver(cfg VaultSecretConfig) (*VaultSecretResolver, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("vault address is required")
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+	return &VaultSecretResolver{cfg: cfg, client: client}, nil
+}
+
+// Resolve implements SecretResolver.
+func (r *VaultSecretResolver) Resolve(ctx context.Context, tenantID types.ID, ref SecretRef) (string, error) {
+	p := path.Clean("/" + ref.Path)[1:]
+	if !r.allowed(tenantID, p) {
+		return "", fmt.Errorf("%w: vault path %s", ErrSecretRefDenied, p)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.cfg.Address+"/v1/"+p, nil)
+	if err != nil {
+		return "", fmt.Errorf("vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", r.cfg.Token)
+	if r.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.cfg.Namespace)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault read %s: %w", p, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretSize))
+	if err != nil {
+		return "", fmt.Errorf("vault read %s: %w", p, err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: vault path %s", ErrSecretNotFound, p)
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(data, &e)
+		return "", fmt.Errorf("vault read %s: status %d: %s", p, resp.StatusCode, strings.Join(e.Errors, "; "))
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", fmt.Errorf("decode vault response for %s: %w", p, err)
+	}
+	fields := body.Data
+	// KV v2 nests the secret under data.data alongside data.metadata.
+	if inner, ok := fields["data"].(map[string]any); ok {
+		if _, v2 := fields["metadata"]; v2 {
+			fields = inner
+		}
+	}
+	if fields == nil {
+		return "", fmt.Errorf("%w: vault path %s", ErrSecretNotFound, p)
+	}
+	return secretField(fields, ref)
+}
+
+func (r *VaultSecretResolver) allowed(tenantID types.ID, p string) bool {
+	for _, prefix := range r.cfg.AllowedPaths {
+		prefix = strings.Trim(strings.ReplaceAll(strings.TrimSpace(prefix), tenantPlaceholder, tenantID.String()), "/")
+		if prefix == "" {
+			continue
+		}
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,289 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// SecretRefPrefix marks DataSource.Credentials as a reference to a secret
+// held elsewhere rather than the secret itself, e.g.
+//
+//	secretref:vault://secret/data/datalens/<tenant>/orders-db#password
+//	secretref:env://DATALENS_SECRET_<TENANT>_ORDERS_DB
+//	secretref:file:///run/secrets/datalens/<tenant>/orders-db
+//
+// The optional "#key" selects one field from a Vault secret or a JSON
+// env/file value. Without it, Vault secrets with several fields resolve to
+// a JSON object, which ParseCredentials already understands.
+const SecretRefPrefix = "secretref:"
+
+// Secret reference schemes.
+const (
+	SecretSchemeVault = "vault"
+	SecretSchemeEnv   = "env"
+	SecretSchemeFile  = "file"
+)
+
+var (
+	// ErrSecretNotFound is returned when a referenced secret does not exist.
+	ErrSecretNotFound = errors.New("secret not found")
+	// ErrSecretRefDenied is returned when a reference points outside the
+	// locations the resolver is allowed to read.
+	ErrSecretRefDenied = errors.New("secret reference not allowed")
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SecretRef is a parsed secret reference.
+type SecretRef struct {
+	Scheme string
+	Path   string
+	Key    string
+}
+
+// String returns the reference without the "secretref:" prefix. It never
+// contains secret material and is safe to log.
+func (r SecretRef) String() string {
+	s := r.Scheme + "://" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// IsSecretRef reports whether creds is a secret reference.
+func IsSecretRef(creds string) bool {
+	return strings.HasPrefix(strings.TrimSpace(creds), SecretRefPrefix)
+}
+
+// ParseSecretRef parses a "secretref:" credentials string.
+func ParseSecretRef(creds string) (SecretRef, error) {
+	raw, ok := strings.CutPrefix(strings.TrimSpace(creds), SecretRefPrefix)
+	if !ok {
+		return SecretRef{}, fmt.Errorf("not a secret reference")
+	}
+	scheme, rest, ok := strings.Cut(raw, "://")
+	if !ok {
+		return SecretRef{}, fmt.Errorf("invalid secret reference %q: expected <scheme>://<path>", raw)
+	}
+
+	ref := SecretRef{Scheme: strings.ToLower(scheme), Path: rest}
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		ref.Path, ref.Key = rest[:i], rest[i+1:]
+		if ref.Key == "" {
+			return SecretRef{}, fmt.Errorf("invalid secret reference %q: empty key", raw)
+		}
+	}
+
+	switch ref.Scheme {
+	case SecretSchemeVault:
+		ref.Path = strings.Trim(ref.Path, "/")
+	case SecretSchemeEnv:
+		if !envNamePattern.MatchString(ref.Path) {
+			return SecretRef{}, fmt.Errorf("invalid secret reference %q: bad environment variable name", raw)
+		}
+	case SecretSchemeFile:
+		if !strings.HasPrefix(ref.Path, "/") {
+			return SecretRef{}, fmt.Errorf("invalid secret reference %q: file path must be absolute", raw)
+		}
+	default:
+		return SecretRef{}, fmt.Errorf("invalid secret reference %q: unsupported scheme %q", raw, ref.Scheme)
+	}
+	if ref.Path == "" {
+		return SecretRef{}, fmt.Errorf("invalid secret reference %q: empty path", raw)
+	}
+	return ref, nil
+}
+
+// SecretResolver fetches the value a reference points to. Implementations
+// must confine lookups to what tenantID may read.
+type SecretResolver interface {
+	Resolve(ctx context.Context, tenantID types.ID, ref SecretRef) (string, error)
+}
+
+// SecretResolution describes one resolution for auditing. It never carries
+// the secret value.
+type SecretResolution struct {
+	TenantID     types.ID
+	DataSourceID types.ID
+	Ref          SecretRef
+	Cached       bool
+	Err          error
+}
+
+// SecretAuditor records secret resolutions.
+type SecretAuditor interface {
+	SecretResolved(ctx context.Context, r SecretResolution)
+}
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// SecretManager resolves secret references in data source credentials by
+// dispatching to the resolver registered for each scheme. Values are
+// cached per tenant and reference for the configured TTL, and every
+// resolution — cached or not, successful or not — is logged and passed to
+// the auditor.
+type SecretManager struct {
+	ttl     time.Duration
+	auditor SecretAuditor
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu        sync.RWMutex
+	resolvers map[string]SecretResolver
+	cache     map[string]cachedSecret
+}
+
+// NewSecretManager creates a SecretManager. A zero ttl disables caching;
+// auditor may be nil.
+func NewSecretManager(ttl time.Duration, auditor SecretAuditor, logger *slog.Logger) *SecretManager {
+	return &SecretManager{
+		ttl:       ttl,
+		auditor:   auditor,
+		logger:    logger.With("component", "secret_manager"),
+		now:       time.Now,
+		resolvers: make(map[string]SecretResolver),
+		cache:     make(map[string]cachedSecret),
+	}
+}
+
+// Register sets the resolver for a scheme, replacing any existing one.
+func (m *SecretManager) Register(scheme string, resolver SecretResolver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resolvers[strings.ToLower(scheme)] = resolver
+}
+
+// ResolveCredentials returns ds.Credentials with a secret reference
+// replaced by the secret it points to. Other credentials are returned
+// unchanged.
+func (m *SecretManager) ResolveCredentials(ctx context.Context, ds *discovery.DataSource) (string, error) {
+	if !IsSecretRef(ds.Credentials) {
+		return ds.Credentials, nil
+	}
+	ref, err := ParseSecretRef(ds.Credentials)
+	if err != nil {
+		return "", err
+	}
+	value, err := m.resolve(ctx, ds.TenantID, ds.ID, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolve credentials %s: %w", ref, err)
+	}
+	return value, nil
+}
+
+// Resolve implements SecretResolver.
+func (m *SecretManager) Resolve(ctx context.Context, tenantID types.ID, ref SecretRef) (string, error) {
+	return m.resolve(ctx, tenantID, types.ID{}, ref)
+}
+
+func (m *SecretManager) resolve(ctx context.Context, tenantID, dataSourceID types.ID, ref SecretRef) (string, error) {
+	key := tenantID.String() + "|" + ref.String()
+
+	m.mu.RLock()
+	entry, hit := m.cache[key]
+	resolver := m.resolvers[ref.Scheme]
+	m.mu.RUnlock()
+
+	if hit && m.now().Before(entry.expiresAt) {
+		m.record(ctx, SecretResolution{TenantID: tenantID, DataSourceID: dataSourceID, Ref: ref, Cached: true})
+		return entry.value, nil
+	}
+
+	var value string
+	var err error
+	if resolver == nil {
+		err = fmt.Errorf("no resolver configured for scheme %q", ref.Scheme)
+	} else {
+		value, err = resolver.Resolve(ctx, tenantID, ref)
+	}
+	m.record(ctx, SecretResolution{TenantID: tenantID, DataSourceID: dataSourceID, Ref: ref, Err: err})
+	if err != nil {
+		return "", err
+	}
+
+	if m.ttl > 0 {
+		m.mu.Lock()
+		m.cache[key] = cachedSecret{value: value, expiresAt: m.now().Add(m.ttl)}
+		m.mu.Unlock()
+	}
+	return value, nil
+}
+
+// Purge drops all cached values, forcing the next resolution of every
+// reference to go to its backend.
+func (m *SecretManager) Purge() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache = make(map[string]cachedSecret)
+}
+
+func (m *SecretManager) record(ctx context.Context, r SecretResolution) {
+	attrs := []any{
+		"tenant_id", r.TenantID,
+		"data_source_id", r.DataSourceID,
+		"ref", r.Ref.String(),
+		"cached", r.Cached,
+	}
+	if r.Err != nil {
+		m.logger.WarnContext(ctx, "secret resolution failed", append(attrs, "error", r.Err)...)
+	} else {
+		m.logger.InfoContext(ctx, "secret resolved", attrs...)
+	}
+	if m.auditor != nil {
+		m.auditor.SecretResolved(ctx, r)
+	}
+}
+
+// selectSecretKey picks ref.Key out of a JSON object value. Without a key
+// the value is returned as-is.
+func selectSecretKey(value string, ref SecretRef) (string, error) {
+	if ref.Key == "" {
+		return value, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object, cannot select key %q", ref.Scheme+"://"+ref.Path, ref.Key)
+	}
+	return secretField(fields, ref)
+}
+
+// secretField returns ref.Key from fields, or, without a key, the only
+// field or the whole object as JSON.
+func secretField(fields map[string]any, ref SecretRef) (string, error) {
+	if ref.Key == "" {
+		if len(fields) == 1 {
+			for _, v := range fields {
+				return stringifySecret(v)
+			}
+		}
+		b, err := json.Marshal(fields)
+		return string(b), err
+	}
+	v, ok := fields[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("%w: key %q", ErrSecretNotFound, ref.Key)
+	}
+	return stringifySecret(v)
+}
+
+func stringifySecret(v any) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
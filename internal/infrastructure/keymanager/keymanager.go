@@ -0,0 +1,63 @@
+// Package keymanager builds the credential cipher selected in
+// config.CryptoConfig.
+package keymanager
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/complyark/datalens/internal/config"
+	"github.com/complyark/datalens/internal/repository"
+	"github.com/complyark/datalens/pkg/crypto"
+)
+
+// Provider names accepted in CRYPTO_MASTER_KEY_PROVIDER.
+const (
+	ProviderStatic = "static"
+	ProviderLocal  = "local"
+	ProviderVault  = "vault"
+)
+
+// NewCredentialCipher returns the cipher for stored credentials. With the
+// static provider this is the legacy single-key cipher; otherwise it is an
+// envelope-encrypting KeyManager that can still read legacy ciphertexts.
+func NewCredentialCipher(cfg *config.Config, pool *pgxpool.Pool) (crypto.CredentialCipher, error) {
+	if cfg.Crypto.MasterKeyProvider == "" || cfg.Crypto.MasterKeyProvider == ProviderStatic {
+		return crypto.NewStaticCipher(cfg.App.SecretKey), nil
+	}
+	km, _, err := NewKeyManager(cfg, pool)
+	if err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+// NewKeyManager builds an envelope-encrypting KeyManager and returns its
+// master key provider, which the key admin command rotates directly.
+func NewKeyManager(cfg *config.Config, pool *pgxpool.Pool) (*crypto.KeyManager, crypto.MasterKeyProvider, error) {
+	provider, err := NewMasterKeyProvider(cfg.Crypto)
+	if err != nil {
+		return nil, nil, err
+	}
+	legacy := crypto.NewStaticCipher(cfg.App.SecretKey)
+	return crypto.NewKeyManager(provider, repository.NewDataKeyRepo(pool), legacy), provider, nil
+}
+
+// NewMasterKeyProvider creates the configured master key provider.
+func NewMasterKeyProvider(cfg config.CryptoConfig) (crypto.MasterKeyProvider, error) {
+	switch cfg.MasterKeyProvider {
+	case ProviderLocal:
+		return crypto.NewLocalFileProvider(cfg.LocalKeyFile)
+	case ProviderVault:
+		return crypto.NewVaultTransitProvider(crypto.VaultTransitConfig{
+			Address:   cfg.VaultAddr,
+			Token:     cfg.VaultToken,
+			Namespace: cfg.VaultNamespace,
+			Mount:     cfg.VaultTransitMount,
+			KeyName:   cfg.VaultTransitKey,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported master key provider %q (want %q or %q)", cfg.MasterKeyProvider, ProviderLocal, ProviderVault)
+	}
+}
@@ -33,6 +33,15 @@ const (
 	StreamSubject = "scan.jobs.>"
 	JobSubject    = "scan.jobs.created"
 	ConsumerName  = "scan-worker"
+
+	// AckWait is how long a job may go without a heartbeat before JetStream
+	// redelivers it to another worker, which then resumes from the run's
+	// last checkpoint.
+	AckWait = 2 * time.Minute
+
+	// heartbeatInterval must stay well below AckWait so long-running scans
+	// are not redelivered while still making progress.
+	heartbeatInterval = 30 * time.Second
 )
 
 // NewNATSScanQueue creates a new NATSScanQueue.
@@ -91,6 +100,7 @@ func (q *NATSScanQueue) Subscribe(ctx context.Context, handler func(ctx context.
 		Durable:       ConsumerName,
 		FilterSubject: JobSubject,
 		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       AckWait,
 	})
 	if err != nil {
 		return fmt.Errorf("create consumer: %w", err)
@@ -106,14 +116,23 @@ func (q *NATSScanQueue) Subscribe(ctx context.Context, handler func(ctx context.
 			return
 		}
 
-		q.logger.Info("processing scan job", "job_id", jobID)
+		if meta, err := msg.Metadata(); err == nil && meta.NumDelivered > 1 {
+			q.logger.Info("processing redelivered scan job", "job_id", jobID, "delivery", meta.NumDelivered)
+		} else {
+			q.logger.Info("processing scan job", "job_id", jobID)
+		}
 
-		// Create a context for the handler
-		// Ideally with a timeout, but scan can be long.
-		// Let's use Background for now or a long timeout.
-		hCtx := context.Background()
+		// Scans can run far longer than AckWait; keep the message alive
+		// with in-progress heartbeats until the handler returns. The
+		// handler's context ends with ctx, so a worker shutting down
+		// interrupts its scans and they resume on redelivery.
+		hCtx, stop := context.WithCancel(ctx)
+		go q.heartbeat(hCtx, msg, jobID)
 
-		if err := handler(hCtx, jobID); err != nil {
+		err := handler(hCtx, jobID)
+		stop()
+
+		if err != nil {
 			q.logger.Error("scan job failed", "job_id", jobID, "error", err)
 			// Nak with delay? Or Term?
 			// For now, let's Nak so it retries.
@@ -129,11 +148,28 @@ func (q *NATSScanQueue) Subscribe(ctx context.Context, handler func(ctx context.
 		return fmt.Errorf("consume: %w", err)
 	}
 
-	// We are not returning the ConsumeContext to stop it, which leaks if we intend to stop.
-	// But in this app, we run until shutdown.
-	// For correctness, we should handle shutdown, but typical app wiring handles this via main context cancel?
-	// The `Consume` method returns a `ConsumeContext`.
-	_ = cons
+	// Stop taking new jobs once the worker shuts down.
+	go func() {
+		<-ctx.Done()
+		cons.Stop()
+	}()
 
 	return nil
 }
+
+// heartbeat signals JetStream that the job is still being worked on.
+func (q *NATSScanQueue) heartbeat(ctx context.Context, msg jetstream.Msg, jobID string) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := msg.InProgress(); err != nil {
+				q.logger.Warn("scan job heartbeat failed", "job_id", jobID, "error", err)
+			}
+		}
+	}
+}
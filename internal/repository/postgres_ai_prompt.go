@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// AIPromptRepo implements identity.AIPromptRepository.
+type AIPromptRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewAIPromptRepo creates a new AIPromptRepo.
+func NewAIPromptRepo(pool *pgxpool.Pool) *AIPromptRepo {
+	return &AIPromptRepo{pool: pool}
+}
+
+const aiPromptColumns = `id, tenant_id, use_case, version, system_prompt, template, description,
+	status, created_by, created_at, activated_at`
+
+func scanAIPrompt(row pgx.Row) (*identity.AIPromptVersion, error) {
+	p := &identity.AIPromptVersion{}
+	var status string
+	if err := row.Scan(&p.ID, &p.TenantID, &p.UseCase, &p.Version, &p.SystemPrompt, &p.Template,
+		&p.Description, &status, &p.CreatedBy, &p.CreatedAt, &p.ActivatedAt); err != nil {
+		return nil, err
+	}
+	p.Status = identity.AIPromptStatus(status)
+	return p, nil
+}
+
+// aiPromptCreateAttempts bounds the retries of a Create that lost a race
+// for its version number.
+const aiPromptCreateAttempts = 3
+
+// Create stores a draft with the next version number of its use case in its
+// scope. Concurrent creates in one scope may pick the same number; the one
+// that loses on the unique index retries with the next.
+func (r *AIPromptRepo) Create(ctx context.Context, p *identity.AIPromptVersion) error {
+	p.ID = types.NewID()
+	p.Status = identity.AIPromptDraft
+	p.CreatedAt = time.Now().UTC()
+
+	var err error
+	for attempt := 0; attempt < aiPromptCreateAttempts; attempt++ {
+		err = r.pool.QueryRow(ctx, `
+			INSERT INTO ai_prompt_versions (
+				id, tenant_id, use_case, version, system_prompt, template, description,
+				status, created_by, created_at
+			) VALUES (
+				$1, $2, $3,
+				(SELECT COALESCE(MAX(version), 0) + 1 FROM ai_prompt_versions
+				 WHERE use_case = $3 AND tenant_id IS NOT DISTINCT FROM $2),
+				$4, $5, $6, $7, $8, $9
+			)
+			RETURNING version`,
+			p.ID, p.TenantID, p.UseCase, p.SystemPrompt, p.Template, p.Description,
+			string(p.Status), p.CreatedBy, p.CreatedAt,
+		).Scan(&p.Version)
+
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("create ai prompt version: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a prompt version.
+func (r *AIPromptRepo) GetByID(ctx context.Context, id types.ID) (*identity.AIPromptVersion, error) {
+	p, err := scanAIPrompt(r.pool.QueryRow(ctx,
+		`SELECT `+aiPromptColumns+` FROM ai_prompt_versions WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.NewNotFoundError("ai prompt version", id)
+		}
+		return nil, fmt.Errorf("get ai prompt version: %w", err)
+	}
+	return p, nil
+}
+
+// List returns the tenant's and the platform-wide versions, newest first.
+func (r *AIPromptRepo) List(ctx context.Context, tenantID types.ID, useCase string) ([]identity.AIPromptVersion, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+aiPromptColumns+`
+		FROM ai_prompt_versions
+		WHERE (tenant_id = $1 OR tenant_id IS NULL) AND ($2 = '' OR use_case = $2)
+		ORDER BY use_case, version DESC`,
+		tenantID, useCase,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list ai prompt versions: %w", err)
+	}
+	defer rows.Close()
+
+	var out []identity.AIPromptVersion
+	for rows.Next() {
+		p, err := scanAIPrompt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan ai prompt version: %w", err)
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+// GetActive returns the active version of a use case in one scope.
+func (r *AIPromptRepo) GetActive(ctx context.Context, tenantID *types.ID, useCase string) (*identity.AIPromptVersion, error) {
+	p, err := scanAIPrompt(r.pool.QueryRow(ctx, `
+		SELECT `+aiPromptColumns+`
+		FROM ai_prompt_versions
+		WHERE tenant_id IS NOT DISTINCT FROM $1 AND use_case = $2 AND status = 'ACTIVE'`,
+		tenantID, useCase,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.NewNotFoundError("active ai prompt", useCase)
+		}
+		return nil, fmt.Errorf("get active ai prompt: %w", err)
+	}
+	return p, nil
+}
+
+// Activate makes a version active and retires the one it replaces, in one
+// transaction.
+func (r *AIPromptRepo) Activate(ctx context.Context, id types.ID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE ai_prompt_versions old SET status = 'RETIRED'
+		FROM ai_prompt_versions p
+		WHERE p.id = $1 AND old.id <> p.id AND old.status = 'ACTIVE'
+			AND old.use_case = p.use_case AND old.tenant_id IS NOT DISTINCT FROM p.tenant_id`,
+		id,
+	); err != nil {
+		return fmt.Errorf("retire active ai prompt: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE ai_prompt_versions SET status = 'ACTIVE', activated_at = NOW()
+		WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("activate ai prompt version: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return types.NewNotFoundError("ai prompt version", id)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Retire takes a version out of use.
+func (r *AIPromptRepo) Retire(ctx context.Context, id types.ID) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE ai_prompt_versions SET status = 'RETIRED' WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("retire ai prompt version: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return types.NewNotFoundError("ai prompt version", id)
+	}
+	return nil
+}
+
+// Compile-time check.
+var _ identity.AIPromptRepository = (*AIPromptRepo)(nil)
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// AIUsageRepo implements identity.AIUsageRepository.
+type AIUsageRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewAIUsageRepo creates a new AIUsageRepo.
+func NewAIUsageRepo(pool *pgxpool.Pool) *AIUsageRepo {
+	return &AIUsageRepo{pool: pool}
+}
+
+// Record appends one call to the usage ledger.
+func (r *AIUsageRepo) Record(ctx context.Context, rec *identity.AIUsageRecord) error {
+	rec.ID = types.NewID()
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now().UTC()
+	}
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO ai_usage_records (
+			id, tenant_id, provider, model, use_case,
+			input_tokens, output_tokens, total_tokens, cost, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		rec.ID, rec.TenantID, rec.Provider, rec.Model, rec.UseCase,
+		rec.InputTokens, rec.OutputTokens, rec.TotalTokens, rec.Cost, rec.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("record ai usage: %w", err)
+	}
+	return nil
+}
+
+// Summarize groups a tenant's usage in [from, to) by provider, model and use case.
+func (r *AIUsageRepo) Summarize(ctx context.Context, tenantID types.ID, from, to time.Time) ([]identity.AIUsageBreakdown, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT provider, model, use_case, COUNT(*),
+			COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0),
+			COALESCE(SUM(total_tokens), 0), COALESCE(SUM(cost), 0)::float8
+		FROM ai_usage_records
+		WHERE tenant_id = $1 AND created_at >= $2 AND created_at < $3
+		GROUP BY provider, model, use_case
+		ORDER BY provider, model, use_case`,
+		tenantID, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("summarize ai usage: %w", err)
+	}
+	defer rows.Close()
+
+	var out []identity.AIUsageBreakdown
+	for rows.Next() {
+		var b identity.AIUsageBreakdown
+		if err := rows.Scan(&b.Provider, &b.Model, &b.UseCase, &b.Calls,
+			&b.InputTokens, &b.OutputTokens, &b.TotalTokens, &b.Cost); err != nil {
+			return nil, fmt.Errorf("scan ai usage: %w", err)
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// GetBudget retrieves the tenant's AI budget.
+func (r *AIUsageRepo) GetBudget(ctx context.Context, tenantID types.ID) (*identity.AIBudget, error) {
+	b := &identity.AIBudget{TenantID: tenantID}
+	var alertedLevel string
+	err := r.pool.QueryRow(ctx, `
+		SELECT monthly_token_limit, monthly_cost_limit::float8, soft_limit_percent, hard_limit,
+			alerted_period, alerted_level, updated_at
+		FROM ai_budgets
+		WHERE tenant_id = $1`, tenantID,
+	).Scan(&b.MonthlyTokenLimit, &b.MonthlyCostLimit, &b.SoftLimitPercent, &b.HardLimit,
+		&b.AlertedPeriod, &alertedLevel, &b.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.NewNotFoundError("ai budget", tenantID)
+		}
+		return nil, fmt.Errorf("get ai budget: %w", err)
+	}
+	b.AlertedLevel = identity.AIBudgetLevel(alertedLevel)
+	return b, nil
+}
+
+// UpsertBudget stores the tenant's AI budget, replacing any previous one.
+// Changing the limits clears the alert state so the new limits alert afresh.
+func (r *AIUsageRepo) UpsertBudget(ctx context.Context, b *identity.AIBudget) error {
+	b.UpdatedAt = time.Now().UTC()
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO ai_budgets (
+			tenant_id, monthly_token_limit, monthly_cost_limit, soft_limit_percent, hard_limit, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			monthly_token_limit = EXCLUDED.monthly_token_limit,
+			monthly_cost_limit = EXCLUDED.monthly_cost_limit,
+			soft_limit_percent = EXCLUDED.soft_limit_percent,
+			hard_limit = EXCLUDED.hard_limit,
+			alerted_period = '',
+			alerted_level = '',
+			updated_at = EXCLUDED.updated_at`,
+		b.TenantID, b.MonthlyTokenLimit, b.MonthlyCostLimit, b.SoftLimitPercent, b.HardLimit, b.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert ai budget: %w", err)
+	}
+	b.AlertedPeriod, b.AlertedLevel = "", ""
+	return nil
+}
+
+// MarkBudgetAlerted records the last alert sent for a budget.
+func (r *AIUsageRepo) MarkBudgetAlerted(ctx context.Context, tenantID types.ID, period string, level identity.AIBudgetLevel) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE ai_budgets SET alerted_period = $2, alerted_level = $3
+		WHERE tenant_id = $1`,
+		tenantID, period, string(level),
+	)
+	if err != nil {
+		return fmt.Errorf("mark ai budget alerted: %w", err)
+	}
+	return nil
+}
+
+// Compile-time check.
+var _ identity.AIUsageRepository = (*AIUsageRepo)(nil)
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// DetectionCalibrationRepo implements discovery.DetectionCalibrationRepository.
+type DetectionCalibrationRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewDetectionCalibrationRepo creates a new DetectionCalibrationRepo.
+func NewDetectionCalibrationRepo(pool *pgxpool.Pool) *DetectionCalibrationRepo {
+	return &DetectionCalibrationRepo{pool: pool}
+}
+
+// GetByTenant retrieves the tenant's current calibration.
+func (r *DetectionCalibrationRepo) GetByTenant(ctx context.Context, tenantID types.ID) (*discovery.DetectionCalibration, error) {
+	c := &discovery.DetectionCalibration{TenantID: tenantID}
+	var weights, thresholds, suppressed, report []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT strategy_weights, type_thresholds, suppressed_columns, report, calibrated_at
+		FROM detection_calibrations
+		WHERE tenant_id = $1`, tenantID,
+	).Scan(&weights, &thresholds, &suppressed, &report, &c.CalibratedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.NewNotFoundError("detection calibration", tenantID)
+		}
+		return nil, fmt.Errorf("get detection calibration: %w", err)
+	}
+
+	for _, f := range []struct {
+		data []byte
+		dst  any
+	}{
+		{weights, &c.StrategyWeights},
+		{thresholds, &c.TypeThresholds},
+		{suppressed, &c.SuppressedColumns},
+		{report, &c.Report},
+	} {
+		if err := json.Unmarshal(f.data, f.dst); err != nil {
+			return nil, fmt.Errorf("unmarshal detection calibration: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// Upsert stores the tenant's calibration, replacing any previous one.
+func (r *DetectionCalibrationRepo) Upsert(ctx context.Context, c *discovery.DetectionCalibration) error {
+	weights, err := json.Marshal(c.StrategyWeights)
+	if err != nil {
+		return fmt.Errorf("marshal strategy weights: %w", err)
+	}
+	thresholds, err := json.Marshal(c.TypeThresholds)
+	if err != nil {
+		return fmt.Errorf("marshal type thresholds: %w", err)
+	}
+	suppressed, err := json.Marshal(c.SuppressedColumns)
+	if err != nil {
+		return fmt.Errorf("marshal suppressed columns: %w", err)
+	}
+	report, err := json.Marshal(c.Report)
+	if err != nil {
+		return fmt.Errorf("marshal calibration report: %w", err)
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO detection_calibrations (
+			tenant_id, strategy_weights, type_thresholds, suppressed_columns, report, calibrated_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			strategy_weights = EXCLUDED.strategy_weights,
+			type_thresholds = EXCLUDED.type_thresholds,
+			suppressed_columns = EXCLUDED.suppressed_columns,
+			report = EXCLUDED.report,
+			calibrated_at = EXCLUDED.calibrated_at`,
+		c.TenantID, weights, thresholds, suppressed, report, c.CalibratedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert detection calibration: %w", err)
+	}
+	return nil
+}
+
+// Compile-time check.
+var _ discovery.DetectionCalibrationRepository = (*DetectionCalibrationRepo)(nil)
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/complyark/datalens/pkg/crypto"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// PostgresDataKeyRepo implements crypto.DataKeyStore.
+type PostgresDataKeyRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewDataKeyRepo creates a new PostgresDataKeyRepo.
+func NewDataKeyRepo(pool *pgxpool.Pool) *PostgresDataKeyRepo {
+	return &PostgresDataKeyRepo{pool: pool}
+}
+
+// Compile-time check
+var _ crypto.DataKeyStore = (*PostgresDataKeyRepo)(nil)
+
+const dataKeyColumns = `tenant_id, version, wrapped_key, master_key_id, active, created_at, rotated_at`
+
+// GetActive returns the tenant's active data key.
+func (r *PostgresDataKeyRepo) GetActive(ctx context.Context, tenantID types.ID) (*crypto.DataKey, error) {
+	query := `SELECT ` + dataKeyColumns + ` FROM tenant_data_keys WHERE tenant_id = $1 AND active`
+	return scanDataKey(r.pool.QueryRow(ctx, query, tenantID))
+}
+
+// Get returns a specific data key version.
+func (r *PostgresDataKeyRepo) Get(ctx context.Context, tenantID types.ID, version int) (*crypto.DataKey, error) {
+	query := `SELECT ` + dataKeyColumns + ` FROM tenant_data_keys WHERE tenant_id = $1 AND version = $2`
+	return scanDataKey(r.pool.QueryRow(ctx, query, tenantID, version))
+}
+
+// Create stores a new data key and deactivates the tenant's previous key
+// in the same transaction.
+func (r *PostgresDataKeyRepo) Create(ctx context.Context, key *crypto.DataKey) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE tenant_data_keys SET active = FALSE WHERE tenant_id = $1 AND active`,
+		key.TenantID,
+	); err != nil {
+		return fmt.Errorf("deactivate data keys: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO tenant_data_keys (`+dataKeyColumns+`)
+		VALUES ($1, $2, $3, $4, TRUE, $5, $6)`,
+		key.TenantID, key.Version, key.WrappedKey, key.MasterKeyID, key.CreatedAt, key.RotatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return crypto.ErrDataKeyExists
+		}
+		return fmt.Errorf("create data key: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UpdateWrappedKey replaces the wrapped form of a data key.
+func (r *PostgresDataKeyRepo) UpdateWrappedKey(ctx context.Context, tenantID types.ID, version int, wrapped []byte, masterKeyID string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE tenant_data_keys
+		SET wrapped_key = $3, master_key_id = $4, rotated_at = NOW()
+		WHERE tenant_id = $1 AND version = $2`,
+		tenantID, version, wrapped, masterKeyID,
+	)
+	if err != nil {
+		return fmt.Errorf("update wrapped data key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return crypto.ErrDataKeyNotFound
+	}
+	return nil
+}
+
+// ListAll returns every data key.
+func (r *PostgresDataKeyRepo) ListAll(ctx context.Context) ([]crypto.DataKey, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+dataKeyColumns+` FROM tenant_data_keys ORDER BY tenant_id, version`)
+	if err != nil {
+		return nil, fmt.Errorf("list data keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []crypto.DataKey
+	for rows.Next() {
+		k, err := scanDataKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func scanDataKey(row pgx.Row) (*crypto.DataKey, error) {
+	var k crypto.DataKey
+	err := row.Scan(&k.TenantID, &k.Version, &k.WrappedKey, &k.MasterKeyID, &k.Active, &k.CreatedAt, &k.RotatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, crypto.ErrDataKeyNotFound
+		}
+		return nil, fmt.Errorf("scan data key: %w", err)
+	}
+	return &k, nil
+}
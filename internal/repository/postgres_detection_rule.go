@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// CustomDetectionRuleRepo implements discovery.CustomDetectionRuleRepository.
+type CustomDetectionRuleRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewCustomDetectionRuleRepo creates a new CustomDetectionRuleRepo.
+func NewCustomDetectionRuleRepo(pool *pgxpool.Pool) *CustomDetectionRuleRepo {
+	return &CustomDetectionRuleRepo{pool: pool}
+}
+
+const detectionRuleColumns = `
+	id, tenant_id, name, description, type_label, category, sensitivity,
+	pattern, validator, column_hints, enabled, created_at, updated_at`
+
+// Create persists a new custom detection rule.
+func (r *CustomDetectionRuleRepo) Create(ctx context.Context, rule *discovery.CustomDetectionRule) error {
+	validatorJSON, err := json.Marshal(rule.Validator)
+	if err != nil {
+		return fmt.Errorf("marshal rule validator: %w", err)
+	}
+	rule.ID = types.NewID()
+	rule.CreatedAt = time.Now().UTC()
+	rule.UpdatedAt = rule.CreatedAt
+	if rule.ColumnHints == nil {
+		rule.ColumnHints = []string{}
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO custom_detection_rules (`+detectionRuleColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		rule.ID, rule.TenantID, rule.Name, rule.Description, rule.TypeLabel, rule.Category, rule.Sensitivity,
+		rule.Pattern, validatorJSON, rule.ColumnHints, rule.Enabled, rule.CreatedAt, rule.UpdatedAt,
+	)
+	if err != nil {
+		return mapRuleError(err, rule.TypeLabel, "create")
+	}
+	return nil
+}
+
+// GetByID retrieves a tenant's custom detection rule.
+func (r *CustomDetectionRuleRepo) GetByID(ctx context.Context, tenantID, id types.ID) (*discovery.CustomDetectionRule, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+detectionRuleColumns+`
+		FROM custom_detection_rules
+		WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+
+	rule, err := scanDetectionRule(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.NewNotFoundError("custom detection rule", id)
+		}
+		return nil, fmt.Errorf("get custom detection rule: %w", err)
+	}
+	return rule, nil
+}
+
+// GetByTenant lists all custom detection rules for a tenant.
+func (r *CustomDetectionRuleRepo) GetByTenant(ctx context.Context, tenantID types.ID) ([]discovery.CustomDetectionRule, error) {
+	return r.list(ctx, `
+		SELECT `+detectionRuleColumns+`
+		FROM custom_detection_rules
+		WHERE tenant_id = $1
+		ORDER BY name`, tenantID)
+}
+
+// GetEnabledByTenant lists the rules applied to a tenant's scans.
+func (r *CustomDetectionRuleRepo) GetEnabledByTenant(ctx context.Context, tenantID types.ID) ([]discovery.CustomDetectionRule, error) {
+	return r.list(ctx, `
+		SELECT `+detectionRuleColumns+`
+		FROM custom_detection_rules
+		WHERE tenant_id = $1 AND enabled
+		ORDER BY name`, tenantID)
+}
+
+// Update modifies a custom detection rule.
+func (r *CustomDetectionRuleRepo) Update(ctx context.Context, rule *discovery.CustomDetectionRule) error {
+	validatorJSON, err := json.Marshal(rule.Validator)
+	if err != nil {
+		return fmt.Errorf("marshal rule validator: %w", err)
+	}
+	rule.UpdatedAt = time.Now().UTC()
+	if rule.ColumnHints == nil {
+		rule.ColumnHints = []string{}
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE custom_detection_rules SET
+			name = $3, description = $4, type_label = $5, category = $6, sensitivity = $7,
+			pattern = $8, validator = $9, column_hints = $10, enabled = $11, updated_at = $12
+		WHERE tenant_id = $1 AND id = $2`,
+		rule.TenantID, rule.ID, rule.Name, rule.Description, rule.TypeLabel, rule.Category, rule.Sensitivity,
+		rule.Pattern, validatorJSON, rule.ColumnHints, rule.Enabled, rule.UpdatedAt,
+	)
+	if err != nil {
+		return mapRuleError(err, rule.TypeLabel, "update")
+	}
+	if tag.RowsAffected() == 0 {
+		return types.NewNotFoundError("custom detection rule", rule.ID)
+	}
+	return nil
+}
+
+// Delete removes a custom detection rule.
+func (r *CustomDetectionRuleRepo) Delete(ctx context.Context, tenantID, id types.ID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM custom_detection_rules WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("delete custom detection rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return types.NewNotFoundError("custom detection rule", id)
+	}
+	return nil
+}
+
+func (r *CustomDetectionRuleRepo) list(ctx context.Context, query string, tenantID types.ID) ([]discovery.CustomDetectionRule, error) {
+	rows, err := r.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("query custom detection rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []discovery.CustomDetectionRule
+	for rows.Next() {
+		rule, err := scanDetectionRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan custom detection rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+func scanDetectionRule(row pgx.Row) (*discovery.CustomDetectionRule, error) {
+	var rule discovery.CustomDetectionRule
+	var validatorJSON []byte
+	if err := row.Scan(
+		&rule.ID, &rule.TenantID, &rule.Name, &rule.Description, &rule.TypeLabel, &rule.Category, &rule.Sensitivity,
+		&rule.Pattern, &validatorJSON, &rule.ColumnHints, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(validatorJSON, &rule.Validator); err != nil {
+		return nil, fmt.Errorf("unmarshal rule validator: %w", err)
+	}
+	return &rule, nil
+}
+
+func mapRuleError(err error, typeLabel, op string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return types.NewConflictError("custom detection rule", "type_label", typeLabel)
+	}
+	return fmt.Errorf("%s custom detection rule: %w", op, err)
+}
@@ -30,27 +30,27 @@ func NewDataInventoryRepo(pool *pgxpool.Pool) *DataInventoryRepo {
 func (r *DataInventoryRepo) Create(ctx context.Context, inv *discovery.DataInventory) error {
 	inv.ID = types.NewID()
 	query := `
-		INSERT INTO data_inventories (id, data_source_id, total_entities, total_fields, pii_fields_count, last_scanned_at, schema_version)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO data_inventories (id, data_source_id, total_entities, total_fields, pii_fields_count, last_scanned_at, schema_version, schema_snapshot, subject_graph)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING created_at, updated_at`
 
 	return r.pool.QueryRow(ctx, query,
 		inv.ID, inv.DataSourceID, inv.TotalEntities, inv.TotalFields,
-		inv.PIIFieldsCount, inv.LastScannedAt, inv.SchemaVersion,
+		inv.PIIFieldsCount, inv.LastScannedAt, inv.SchemaVersion, schemaSnapshotOrEmpty(inv.SchemaSnapshot), inv.SubjectGraph,
 	).Scan(&inv.CreatedAt, &inv.UpdatedAt)
 }
 
 func (r *DataInventoryRepo) GetByID(ctx context.Context, id types.ID) (*discovery.DataInventory, error) {
 	query := `
 		SELECT id, data_source_id, total_entities, total_fields, pii_fields_count,
-		       last_scanned_at, schema_version, created_at, updated_at
+		       last_scanned_at, schema_version, schema_snapshot, subject_graph, created_at, updated_at
 		FROM data_inventories
 		WHERE id = $1`
 
 	inv := &discovery.DataInventory{}
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&inv.ID, &inv.DataSourceID, &inv.TotalEntities, &inv.TotalFields,
-		&inv.PIIFieldsCount, &inv.LastScannedAt, &inv.SchemaVersion,
+		&inv.PIIFieldsCount, &inv.LastScannedAt, &inv.SchemaVersion, &inv.SchemaSnapshot, &inv.SubjectGraph,
 		&inv.CreatedAt, &inv.UpdatedAt,
 	)
 	if err != nil {
@@ -65,7 +65,7 @@ func (r *DataInventoryRepo) GetByID(ctx context.Context, id types.ID) (*discover
 func (r *DataInventoryRepo) GetByDataSource(ctx context.Context, dataSourceID types.ID) (*discovery.DataInventory, error) {
 	query := `
 		SELECT id, data_source_id, total_entities, total_fields, pii_fields_count,
-		       last_scanned_at, schema_version, created_at, updated_at
+		       last_scanned_at, schema_version, schema_snapshot, subject_graph, created_at, updated_at
 		FROM data_inventories
 		WHERE data_source_id = $1
 		ORDER BY created_at DESC
@@ -74,7 +74,7 @@ func (r *DataInventoryRepo) GetByDataSource(ctx context.Context, dataSourceID ty
 	inv := &discovery.DataInventory{}
 	err := r.pool.QueryRow(ctx, query, dataSourceID).Scan(
 		&inv.ID, &inv.DataSourceID, &inv.TotalEntities, &inv.TotalFields,
-		&inv.PIIFieldsCount, &inv.LastScannedAt, &inv.SchemaVersion,
+		&inv.PIIFieldsCount, &inv.LastScannedAt, &inv.SchemaVersion, &inv.SchemaSnapshot, &inv.SubjectGraph,
 		&inv.CreatedAt, &inv.UpdatedAt,
 	)
 	if err != nil {
@@ -90,13 +90,14 @@ func (r *DataInventoryRepo) Update(ctx context.Context, inv *discovery.DataInven
 	query := `
 		UPDATE data_inventories
 		SET total_entities = $2, total_fields = $3, pii_fields_count = $4,
-		    last_scanned_at = $5, schema_version = $6, updated_at = NOW()
+		    last_scanned_at = $5, schema_version = $6, schema_snapshot = $7,
+		    subject_graph = $8, updated_at = NOW()
 		WHERE id = $1
 		RETURNING updated_at`
 
 	err := r.pool.QueryRow(ctx, query,
 		inv.ID, inv.TotalEntities, inv.TotalFields, inv.PIIFieldsCount,
-		inv.LastScannedAt, inv.SchemaVersion,
+		inv.LastScannedAt, inv.SchemaVersion, schemaSnapshotOrEmpty(inv.SchemaSnapshot), inv.SubjectGraph,
 	).Scan(&inv.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -107,6 +108,14 @@ func (r *DataInventoryRepo) Update(ctx context.Context, inv *discovery.DataInven
 	return nil
 }
 
+// schemaSnapshotOrEmpty avoids writing NULL into the NOT NULL snapshot column.
+func schemaSnapshotOrEmpty(s discovery.SchemaSnapshot) discovery.SchemaSnapshot {
+	if s == nil {
+		return discovery.SchemaSnapshot{}
+	}
+	return s
+}
+
 // Compile-time check.
 var _ discovery.DataInventoryRepository = (*DataInventoryRepo)(nil)
 
@@ -127,20 +136,22 @@ func NewDataEntityRepo(pool *pgxpool.Pool) *DataEntityRepo {
 func (r *DataEntityRepo) Create(ctx context.Context, entity *discovery.DataEntity) error {
 	entity.ID = types.NewID()
 	query := `
-		INSERT INTO data_entities (id, inventory_id, name, schema_name, type, row_count, pii_confidence)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO data_entities (id, inventory_id, name, schema_name, type, row_count, pii_confidence,
+		                           reid_risk, reid_risk_score)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING created_at, updated_at`
 
 	return r.pool.QueryRow(ctx, query,
 		entity.ID, entity.InventoryID, entity.Name, entity.Schema,
 		entity.Type, entity.RowCount, entity.PIIConfidence,
+		entity.ReidRisk, reidRiskScore(entity.ReidRisk),
 	).Scan(&entity.CreatedAt, &entity.UpdatedAt)
 }
 
 func (r *DataEntityRepo) GetByID(ctx context.Context, id types.ID) (*discovery.DataEntity, error) {
 	query := `
 		SELECT id, inventory_id, name, schema_name, type, row_count, pii_confidence,
-		       created_at, updated_at
+		       reid_risk, created_at, updated_at
 		FROM data_entities
 		WHERE id = $1`
 
@@ -148,7 +159,7 @@ func (r *DataEntityRepo) GetByID(ctx context.Context, id types.ID) (*discovery.D
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&entity.ID, &entity.InventoryID, &entity.Name, &entity.Schema,
 		&entity.Type, &entity.RowCount, &entity.PIIConfidence,
-		&entity.CreatedAt, &entity.UpdatedAt,
+		&entity.ReidRisk, &entity.CreatedAt, &entity.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -162,7 +173,7 @@ func (r *DataEntityRepo) GetByID(ctx context.Context, id types.ID) (*discovery.D
 func (r *DataEntityRepo) GetByInventory(ctx context.Context, inventoryID types.ID) ([]discovery.DataEntity, error) {
 	query := `
 		SELECT id, inventory_id, name, schema_name, type, row_count, pii_confidence,
-		       created_at, updated_at
+		       reid_risk, created_at, updated_at
 		FROM data_entities
 		WHERE inventory_id = $1
 		ORDER BY name ASC`
@@ -179,7 +190,7 @@ func (r *DataEntityRepo) GetByInventory(ctx context.Context, inventoryID types.I
 		if err := rows.Scan(
 			&entity.ID, &entity.InventoryID, &entity.Name, &entity.Schema,
 			&entity.Type, &entity.RowCount, &entity.PIIConfidence,
-			&entity.CreatedAt, &entity.UpdatedAt,
+			&entity.ReidRisk, &entity.CreatedAt, &entity.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan data entity: %w", err)
 		}
@@ -192,13 +203,14 @@ func (r *DataEntityRepo) Update(ctx context.Context, entity *discovery.DataEntit
 	query := `
 		UPDATE data_entities
 		SET name = $2, schema_name = $3, type = $4, row_count = $5,
-		    pii_confidence = $6, updated_at = NOW()
+		    pii_confidence = $6, reid_risk = $7, reid_risk_score = $8, updated_at = NOW()
 		WHERE id = $1
 		RETURNING updated_at`
 
 	err := r.pool.QueryRow(ctx, query,
 		entity.ID, entity.Name, entity.Schema, entity.Type,
 		entity.RowCount, entity.PIIConfidence,
+		entity.ReidRisk, reidRiskScore(entity.ReidRisk),
 	).Scan(&entity.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -221,8 +233,55 @@ func (r *DataEntityRepo) Delete(ctx context.Context, id types.ID) error {
 	return nil
 }
 
-// Compile-time check.
-var _ discovery.DataEntityRepository = (*DataEntityRepo)(nil)
+// GetReidentificationRisks lists assessed entities across the tenant's data
+// sources, highest score first.
+func (r *DataEntityRepo) GetReidentificationRisks(ctx context.Context, tenantID types.ID, minScore float64, limit int) ([]discovery.EntityReidentificationRisk, error) {
+	query := `
+		SELECT e.id, e.name, ds.id, ds.name, e.reid_risk
+		FROM data_entities e
+		JOIN data_inventories inv ON inv.id = e.inventory_id
+		JOIN data_sources ds ON ds.id = inv.data_source_id
+		WHERE ds.tenant_id = $1 AND ds.deleted_at IS NULL
+		  AND e.reid_risk IS NOT NULL AND e.reid_risk_score >= $2
+		ORDER BY e.reid_risk_score DESC, e.name ASC`
+	args := []interface{}{tenantID, minScore}
+	if limit > 0 {
+		query += ` LIMIT $3`
+		args = append(args, limit)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list reidentification risks: %w", err)
+	}
+	defer rows.Close()
+
+	var results []discovery.EntityReidentificationRisk
+	for rows.Next() {
+		var item discovery.EntityReidentificationRisk
+		if err := rows.Scan(
+			&item.EntityID, &item.EntityName, &item.DataSourceID, &item.DataSourceName, &item.Risk,
+		); err != nil {
+			return nil, fmt.Errorf("scan reidentification risk: %w", err)
+		}
+		results = append(results, item)
+	}
+	return results, rows.Err()
+}
+
+// reidRiskScore extracts the sortable score column from an assessment.
+func reidRiskScore(risk *discovery.ReidentificationRisk) *float64 {
+	if risk == nil {
+		return nil
+	}
+	return &risk.Score
+}
+
+// Compile-time checks.
+var (
+	_ discovery.DataEntityRepository           = (*DataEntityRepo)(nil)
+	_ discovery.ReidentificationRiskRepository = (*DataEntityRepo)(nil)
+)
 
 // =============================================================================
 // DataFieldRepo
@@ -241,20 +300,20 @@ func NewDataFieldRepo(pool *pgxpool.Pool) *DataFieldRepo {
 func (r *DataFieldRepo) Create(ctx context.Context, field *discovery.DataField) error {
 	field.ID = types.NewID()
 	query := `
-		INSERT INTO data_fields (id, entity_id, name, data_type, nullable, is_primary_key, is_foreign_key)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO data_fields (id, entity_id, name, data_type, nullable, is_primary_key, is_foreign_key, needs_review)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING created_at, updated_at`
 
 	return r.pool.QueryRow(ctx, query,
 		field.ID, field.EntityID, field.Name, field.DataType,
-		field.Nullable, field.IsPrimaryKey, field.IsForeignKey,
+		field.Nullable, field.IsPrimaryKey, field.IsForeignKey, field.NeedsReview,
 	).Scan(&field.CreatedAt, &field.UpdatedAt)
 }
 
 func (r *DataFieldRepo) GetByID(ctx context.Context, id types.ID) (*discovery.DataField, error) {
 	query := `
 		SELECT id, entity_id, name, data_type, nullable, is_primary_key, is_foreign_key,
-		       created_at, updated_at
+		       needs_review, created_at, updated_at
 		FROM data_fields
 		WHERE id = $1`
 
@@ -262,7 +321,7 @@ func (r *DataFieldRepo) GetByID(ctx context.Context, id types.ID) (*discovery.Da
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&field.ID, &field.EntityID, &field.Name, &field.DataType,
 		&field.Nullable, &field.IsPrimaryKey, &field.IsForeignKey,
-		&field.CreatedAt, &field.UpdatedAt,
+		&field.NeedsReview, &field.CreatedAt, &field.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -276,7 +335,7 @@ func (r *DataFieldRepo) GetByID(ctx context.Context, id types.ID) (*discovery.Da
 func (r *DataFieldRepo) GetByEntity(ctx context.Context, entityID types.ID) ([]discovery.DataField, error) {
 	query := `
 		SELECT id, entity_id, name, data_type, nullable, is_primary_key, is_foreign_key,
-		       created_at, updated_at
+		       needs_review, created_at, updated_at
 		FROM data_fields
 		WHERE entity_id = $1
 		ORDER BY name ASC`
@@ -293,7 +352,7 @@ func (r *DataFieldRepo) GetByEntity(ctx context.Context, entityID types.ID) ([]d
 		if err := rows.Scan(
 			&field.ID, &field.EntityID, &field.Name, &field.DataType,
 			&field.Nullable, &field.IsPrimaryKey, &field.IsForeignKey,
-			&field.CreatedAt, &field.UpdatedAt,
+			&field.NeedsReview, &field.CreatedAt, &field.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan data field: %w", err)
 		}
@@ -306,13 +365,13 @@ func (r *DataFieldRepo) Update(ctx context.Context, field *discovery.DataField)
 	query := `
 		UPDATE data_fields
 		SET name = $2, data_type = $3, nullable = $4,
-		    is_primary_key = $5, is_foreign_key = $6, updated_at = NOW()
+		    is_primary_key = $5, is_foreign_key = $6, needs_review = $7, updated_at = NOW()
 		WHERE id = $1
 		RETURNING updated_at`
 
 	err := r.pool.QueryRow(ctx, query,
 		field.ID, field.Name, field.DataType, field.Nullable,
-		field.IsPrimaryKey, field.IsForeignKey,
+		field.IsPrimaryKey, field.IsForeignKey, field.NeedsReview,
 	).Scan(&field.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
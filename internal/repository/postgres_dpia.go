@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/complyark/datalens/internal/domain/compliance"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// DPIARepo implements compliance.DPIARepository.
+type DPIARepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewDPIARepo creates a new DPIARepo.
+func NewDPIARepo(pool *pgxpool.Pool) *DPIARepo {
+	return &DPIARepo{pool: pool}
+}
+
+const dpiaColumns = `id, tenant_id, title, description, purpose_ids, data_source_ids, status,
+	context, narrative, risks, mitigations, risk_level, ai_draft,
+	review_interval_days, created_by, submitted_by, reviewed_by, review_notes,
+	approved_at, last_reviewed_at, next_review_at, created_at, updated_at`
+
+// dpiaJSON holds the JSONB columns of a DPIA.
+type dpiaJSON struct {
+	purposeIDs, dataSourceIDs, context, risks, mitigations, aiDraft []byte
+}
+
+func marshalDPIA(d *compliance.DPIA) (*dpiaJSON, error) {
+	var j dpiaJSON
+	var err error
+	if j.purposeIDs, err = json.Marshal(nonNil(d.PurposeIDs)); err != nil {
+		return nil, fmt.Errorf("marshal purpose_ids: %w", err)
+	}
+	if j.dataSourceIDs, err = json.Marshal(nonNil(d.DataSourceIDs)); err != nil {
+		return nil, fmt.Errorf("marshal data_source_ids: %w", err)
+	}
+	if j.context, err = json.Marshal(d.Context); err != nil {
+		return nil, fmt.Errorf("marshal context: %w", err)
+	}
+	if j.risks, err = json.Marshal(nonNil(d.Risks)); err != nil {
+		return nil, fmt.Errorf("marshal risks: %w", err)
+	}
+	if j.mitigations, err = json.Marshal(nonNil(d.Mitigations)); err != nil {
+		return nil, fmt.Errorf("marshal mitigations: %w", err)
+	}
+	if d.AIDraft != nil {
+		if j.aiDraft, err = json.Marshal(d.AIDraft); err != nil {
+			return nil, fmt.Errorf("marshal ai_draft: %w", err)
+		}
+	}
+	return &j, nil
+}
+
+// nonNil stores empty lists as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func scanDPIA(row pgx.Row) (*compliance.DPIA, error) {
+	d := &compliance.DPIA{}
+	var j dpiaJSON
+	var status, riskLevel string
+	if err := row.Scan(
+		&d.ID, &d.TenantID, &d.Title, &d.Description, &j.purposeIDs, &j.dataSourceIDs, &status,
+		&j.context, &d.Narrative, &j.risks, &j.mitigations, &riskLevel, &j.aiDraft,
+		&d.ReviewIntervalDays, &d.CreatedBy, &d.SubmittedBy, &d.ReviewedBy, &d.ReviewNotes,
+		&d.ApprovedAt, &d.LastReviewedAt, &d.NextReviewAt, &d.CreatedAt, &d.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	d.Status = compliance.DPIAStatus(status)
+	d.RiskLevel = compliance.DPIARiskLevel(riskLevel)
+
+	for _, f := range []struct {
+		name string
+		data []byte
+		dst  any
+	}{
+		{"purpose_ids", j.purposeIDs, &d.PurposeIDs},
+		{"data_source_ids", j.dataSourceIDs, &d.DataSourceIDs},
+		{"context", j.context, &d.Context},
+		{"risks", j.risks, &d.Risks},
+		{"mitigations", j.mitigations, &d.Mitigations},
+		{"ai_draft", j.aiDraft, &d.AIDraft},
+	} {
+		if len(f.data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(f.data, f.dst); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %w", f.name, err)
+		}
+	}
+	return d, nil
+}
+
+func (r *DPIARepo) Create(ctx context.Context, d *compliance.DPIA) error {
+	d.ID = types.NewID()
+	d.CreatedAt = time.Now().UTC()
+	d.UpdatedAt = d.CreatedAt
+
+	j, err := marshalDPIA(d)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO dpias (`+dpiaColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+		        $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)`,
+		d.ID, d.TenantID, d.Title, d.Description, j.purposeIDs, j.dataSourceIDs, string(d.Status),
+		j.context, d.Narrative, j.risks, j.mitigations, string(d.RiskLevel), j.aiDraft,
+		d.ReviewIntervalDays, d.CreatedBy, d.SubmittedBy, d.ReviewedBy, d.ReviewNotes,
+		d.ApprovedAt, d.LastReviewedAt, d.NextReviewAt, d.CreatedAt, d.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create dpia: %w", err)
+	}
+	return nil
+}
+
+func (r *DPIARepo) GetByID(ctx context.Context, id types.ID) (*compliance.DPIA, error) {
+	d, err := scanDPIA(r.pool.QueryRow(ctx, `SELECT `+dpiaColumns+` FROM dpias WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.NewNotFoundError("DPIA", id)
+		}
+		return nil, fmt.Errorf("get dpia: %w", err)
+	}
+	return d, nil
+}
+
+func (r *DPIARepo) ListByTenant(ctx context.Context, tenantID types.ID, filter compliance.DPIAFilter) (*types.PaginatedResult[compliance.DPIA], error) {
+	where := `WHERE tenant_id = $1`
+	args := []any{tenantID}
+	if filter.Status != nil {
+		args = append(args, string(*filter.Status))
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM dpias `+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count dpias: %w", err)
+	}
+
+	pagination := filter.Pagination
+	args = append(args, pagination.Limit(), pagination.Offset())
+	items, err := r.query(ctx, fmt.Sprintf(`SELECT %s FROM dpias %s ORDER BY updated_at DESC LIMIT $%d OFFSET $%d`,
+		dpiaColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("list dpias: %w", err)
+	}
+
+	return &types.PaginatedResult[compliance.DPIA]{
+		Items:      items,
+		Total:      total,
+		Page:       pagination.Page,
+		PageSize:   pagination.PageSize,
+		TotalPages: (total + pagination.PageSize - 1) / pagination.PageSize,
+	}, nil
+}
+
+func (r *DPIARepo) GetApproved(ctx context.Context, tenantID types.ID) ([]compliance.DPIA, error) {
+	items, err := r.query(ctx, `SELECT `+dpiaColumns+` FROM dpias
+		WHERE tenant_id = $1 AND status IN ('APPROVED', 'REVIEW_DUE')
+		ORDER BY approved_at`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get approved dpias: %w", err)
+	}
+	return items, nil
+}
+
+func (r *DPIARepo) GetReviewsDue(ctx context.Context, before time.Time) ([]compliance.DPIA, error) {
+	items, err := r.query(ctx, `SELECT `+dpiaColumns+` FROM dpias
+		WHERE status = 'APPROVED' AND next_review_at < $1
+		ORDER BY next_review_at`, before)
+	if err != nil {
+		return nil, fmt.Errorf("get dpia reviews due: %w", err)
+	}
+	return items, nil
+}
+
+func (r *DPIARepo) Update(ctx context.Context, d *compliance.DPIA) error {
+	d.UpdatedAt = time.Now().UTC()
+	j, err := marshalDPIA(d)
+	if err != nil {
+		return err
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE dpias SET
+			title = $2, description = $3, purpose_ids = $4, data_source_ids = $5, status = $6,
+			context = $7, narrative = $8, risks = $9, mitigations = $10, risk_level = $11, ai_draft = $12,
+			review_interval_days = $13, submitted_by = $14, reviewed_by = $15, review_notes = $16,
+			approved_at = $17, last_reviewed_at = $18, next_review_at = $19, updated_at = $20
+		WHERE id = $1`,
+		d.ID, d.Title, d.Description, j.purposeIDs, j.dataSourceIDs, string(d.Status),
+		j.context, d.Narrative, j.risks, j.mitigations, string(d.RiskLevel), j.aiDraft,
+		d.ReviewIntervalDays, d.SubmittedBy, d.ReviewedBy, d.ReviewNotes,
+		d.ApprovedAt, d.LastReviewedAt, d.NextReviewAt, d.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update dpia: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return types.NewNotFoundError("DPIA", d.ID)
+	}
+	return nil
+}
+
+func (r *DPIARepo) query(ctx context.Context, sql string, args ...any) ([]compliance.DPIA, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []compliance.DPIA
+	for rows.Next() {
+		d, err := scanDPIA(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan dpia: %w", err)
+		}
+		items = append(items, *d)
+	}
+	return items, rows.Err()
+}
+
+// Compile-time check.
+var _ compliance.DPIARepository = (*DPIARepo)(nil)
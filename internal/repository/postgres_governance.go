@@ -404,6 +404,33 @@ func (r *PostgresDataMappingRepository) GetUnmapped(ctx context.Context, tenantI
 	return ids, nil
 }
 
+// GetCrossBorder retrieves the tenant's mappings that document a cross-border transfer.
+func (r *PostgresDataMappingRepository) GetCrossBorder(ctx context.Context, tenantID types.ID) ([]governance.DataMapping, error) {
+	query := `
+		SELECT id, tenant_id, classification_id, purpose_ids, retention_days, third_party_ids, notes, mapped_by, mapped_at, cross_border, created_at, updated_at
+		FROM governance_data_mappings
+		WHERE tenant_id = $1 AND cross_border IS NOT NULL AND cross_border != 'null'::jsonb
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get cross-border data mappings: %w", err)
+	}
+	defer rows.Close()
+
+	var mappings []governance.DataMapping
+	for rows.Next() {
+		var dm governance.DataMapping
+		if err := rows.Scan(
+			&dm.ID, &dm.TenantID, &dm.ClassificationID, &dm.PurposeIDs, &dm.RetentionDays, &dm.ThirdPartyIDs, &dm.Notes, &dm.MappedBy, &dm.MappedAt, &dm.CrossBorder, &dm.CreatedAt, &dm.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan cross-border data mapping: %w", err)
+		}
+		mappings = append(mappings, dm)
+	}
+	return mappings, rows.Err()
+}
+
 // Update updates an existing data mapping.
 func (r *PostgresDataMappingRepository) Update(ctx context.Context, dm *governance.DataMapping) error {
 	query := `
@@ -20,23 +20,23 @@ func NewPostgresNoticeRepository(db *pgxpool.Pool) *PostgresNoticeRepository {
 
 func (r *PostgresNoticeRepository) Create(ctx context.Context, n *consent.ConsentNotice) error {
 	query := `INSERT INTO consent_notices (
-		id, tenant_id, series_id, title, content, version, status, purposes, widget_ids, regulation, published_at, created_at, updated_at
-	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+		id, tenant_id, series_id, title, content, version, status, purposes, widget_ids, regulation, schema, generation, published_at, created_at, updated_at
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
 
 	_, err := r.db.Exec(ctx, query,
-		n.ID, n.TenantID, n.SeriesID, n.Title, n.Content, n.Version, n.Status, n.Purposes, n.WidgetIDs, n.Regulation, n.PublishedAt, n.CreatedAt, n.UpdatedAt,
+		n.ID, n.TenantID, n.SeriesID, n.Title, n.Content, n.Version, n.Status, n.Purposes, n.WidgetIDs, n.Regulation, n.Schema, n.Generation, n.PublishedAt, n.CreatedAt, n.UpdatedAt,
 	)
 	return err
 }
 
 func (r *PostgresNoticeRepository) GetByID(ctx context.Context, id types.ID) (*consent.ConsentNotice, error) {
 	query := `SELECT 
-		id, tenant_id, series_id, title, content, version, status, purposes, widget_ids, regulation, published_at, created_at, updated_at
+		id, tenant_id, series_id, title, content, version, status, purposes, widget_ids, regulation, schema, generation, published_at, created_at, updated_at
 	FROM consent_notices WHERE id = $1`
 
 	var n consent.ConsentNotice
 	err := r.db.QueryRow(ctx, query, id).Scan(
-		&n.ID, &n.TenantID, &n.SeriesID, &n.Title, &n.Content, &n.Version, &n.Status, &n.Purposes, &n.WidgetIDs, &n.Regulation, &n.PublishedAt, &n.CreatedAt, &n.UpdatedAt,
+		&n.ID, &n.TenantID, &n.SeriesID, &n.Title, &n.Content, &n.Version, &n.Status, &n.Purposes, &n.WidgetIDs, &n.Regulation, &n.Schema, &n.Generation, &n.PublishedAt, &n.CreatedAt, &n.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, types.NewNotFoundError("notice not found", map[string]any{"id": id})
@@ -49,7 +49,7 @@ func (r *PostgresNoticeRepository) GetByID(ctx context.Context, id types.ID) (*c
 
 func (r *PostgresNoticeRepository) GetByTenant(ctx context.Context, tenantID types.ID) ([]consent.ConsentNotice, error) {
 	query := `SELECT 
-		id, tenant_id, series_id, title, content, version, status, purposes, widget_ids, regulation, published_at, created_at, updated_at
+		id, tenant_id, series_id, title, content, version, status, purposes, widget_ids, regulation, schema, generation, published_at, created_at, updated_at
 	FROM consent_notices WHERE tenant_id = $1 ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, tenantID)
@@ -62,7 +62,7 @@ func (r *PostgresNoticeRepository) GetByTenant(ctx context.Context, tenantID typ
 	for rows.Next() {
 		var n consent.ConsentNotice
 		if err := rows.Scan(
-			&n.ID, &n.TenantID, &n.SeriesID, &n.Title, &n.Content, &n.Version, &n.Status, &n.Purposes, &n.WidgetIDs, &n.Regulation, &n.PublishedAt, &n.CreatedAt, &n.UpdatedAt,
+			&n.ID, &n.TenantID, &n.SeriesID, &n.Title, &n.Content, &n.Version, &n.Status, &n.Purposes, &n.WidgetIDs, &n.Regulation, &n.Schema, &n.Generation, &n.PublishedAt, &n.CreatedAt, &n.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -73,11 +73,11 @@ func (r *PostgresNoticeRepository) GetByTenant(ctx context.Context, tenantID typ
 
 func (r *PostgresNoticeRepository) Update(ctx context.Context, n *consent.ConsentNotice) error {
 	query := `UPDATE consent_notices SET
-		title = $1, content = $2, purposes = $3, widget_ids = $4, regulation = $5, updated_at = $6
-	WHERE id = $7 AND tenant_id = $8`
+		title = $1, content = $2, purposes = $3, widget_ids = $4, regulation = $5, schema = $6, generation = $7, updated_at = $8
+	WHERE id = $9 AND tenant_id = $10`
 
 	cmd, err := r.db.Exec(ctx, query,
-		n.Title, n.Content, n.Purposes, n.WidgetIDs, n.Regulation, time.Now(),
+		n.Title, n.Content, n.Purposes, n.WidgetIDs, n.Regulation, n.Schema, n.Generation, time.Now(),
 		n.ID, n.TenantID,
 	)
 	if err != nil {
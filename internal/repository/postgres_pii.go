@@ -26,13 +26,13 @@ func (r *PIIClassificationRepo) Create(ctx context.Context, c *discovery.PIIClas
 	c.ID = types.NewID()
 	query := `
 		INSERT INTO pii_classifications (id, field_id, data_source_id, entity_name, field_name,
-		    category, type, sensitivity, confidence, detection_method, status, reasoning)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		    category, type, sensitivity, confidence, detection_method, status, reasoning, prompt_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING created_at, updated_at`
 
 	return r.pool.QueryRow(ctx, query,
 		c.ID, c.FieldID, c.DataSourceID, c.EntityName, c.FieldName,
-		c.Category, c.Type, c.Sensitivity, c.Confidence, c.DetectionMethod, c.Status, c.Reasoning,
+		c.Category, c.Type, c.Sensitivity, c.Confidence, c.DetectionMethod, c.Status, c.Reasoning, c.PromptVersion,
 	).Scan(&c.CreatedAt, &c.UpdatedAt)
 }
 
@@ -40,7 +40,7 @@ func (r *PIIClassificationRepo) GetByID(ctx context.Context, id types.ID) (*disc
 	query := `
 		SELECT id, field_id, data_source_id, entity_name, field_name, category, type,
 		       sensitivity, confidence, detection_method, status, verified_by, verified_at,
-		       reasoning, created_at, updated_at
+		       reasoning, prompt_version, created_at, updated_at
 		FROM pii_classifications
 		WHERE id = $1`
 
@@ -48,7 +48,7 @@ func (r *PIIClassificationRepo) GetByID(ctx context.Context, id types.ID) (*disc
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&c.ID, &c.FieldID, &c.DataSourceID, &c.EntityName, &c.FieldName, &c.Category, &c.Type,
 		&c.Sensitivity, &c.Confidence, &c.DetectionMethod, &c.Status, &c.VerifiedBy, &c.VerifiedAt,
-		&c.Reasoning, &c.CreatedAt, &c.UpdatedAt,
+		&c.Reasoning, &c.PromptVersion, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -70,7 +70,7 @@ func (r *PIIClassificationRepo) GetByDataSource(ctx context.Context, dataSourceI
 	query := `
 		SELECT id, field_id, data_source_id, entity_name, field_name, category, type,
 		       sensitivity, confidence, detection_method, status, verified_by, verified_at,
-		       reasoning, created_at, updated_at
+		       reasoning, prompt_version, created_at, updated_at
 		FROM pii_classifications
 		WHERE data_source_id = $1
 		ORDER BY confidence DESC, created_at DESC
@@ -88,7 +88,7 @@ func (r *PIIClassificationRepo) GetByDataSource(ctx context.Context, dataSourceI
 		if err := rows.Scan(
 			&c.ID, &c.FieldID, &c.DataSourceID, &c.EntityName, &c.FieldName, &c.Category, &c.Type,
 			&c.Sensitivity, &c.Confidence, &c.DetectionMethod, &c.Status, &c.VerifiedBy, &c.VerifiedAt,
-			&c.Reasoning, &c.CreatedAt, &c.UpdatedAt,
+			&c.Reasoning, &c.PromptVersion, &c.CreatedAt, &c.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan pii classification: %w", err)
 		}
@@ -126,7 +126,7 @@ func (r *PIIClassificationRepo) GetPending(ctx context.Context, tenantID types.I
 	query := `
 		SELECT pc.id, pc.field_id, pc.data_source_id, pc.entity_name, pc.field_name, pc.category,
 		       pc.type, pc.sensitivity, pc.confidence, pc.detection_method, pc.status,
-		       pc.verified_by, pc.verified_at, pc.reasoning, pc.created_at, pc.updated_at
+		       pc.verified_by, pc.verified_at, pc.reasoning, pc.prompt_version, pc.created_at, pc.updated_at
 		FROM pii_classifications pc
 		JOIN data_sources ds ON ds.id = pc.data_source_id
 		WHERE ds.tenant_id = $1 AND pc.status = 'PENDING'
@@ -145,7 +145,7 @@ func (r *PIIClassificationRepo) GetPending(ctx context.Context, tenantID types.I
 		if err := rows.Scan(
 			&c.ID, &c.FieldID, &c.DataSourceID, &c.EntityName, &c.FieldName, &c.Category, &c.Type,
 			&c.Sensitivity, &c.Confidence, &c.DetectionMethod, &c.Status, &c.VerifiedBy, &c.VerifiedAt,
-			&c.Reasoning, &c.CreatedAt, &c.UpdatedAt,
+			&c.Reasoning, &c.PromptVersion, &c.CreatedAt, &c.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan pending classification: %w", err)
 		}
@@ -208,7 +208,7 @@ func (r *PIIClassificationRepo) GetClassifications(ctx context.Context, tenantID
 	query := `
 		SELECT pc.id, pc.field_id, pc.data_source_id, pc.entity_name, pc.field_name, pc.category,
 			   pc.type, pc.sensitivity, pc.confidence, pc.detection_method, pc.status,
-			   pc.verified_by, pc.verified_at, pc.reasoning, pc.created_at, pc.updated_at ` +
+			   pc.verified_by, pc.verified_at, pc.reasoning, pc.prompt_version, pc.created_at, pc.updated_at ` +
 		baseQuery +
 		fmt.Sprintf(" ORDER BY pc.created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
 
@@ -226,7 +226,7 @@ func (r *PIIClassificationRepo) GetClassifications(ctx context.Context, tenantID
 		if err := rows.Scan(
 			&c.ID, &c.FieldID, &c.DataSourceID, &c.EntityName, &c.FieldName, &c.Category, &c.Type,
 			&c.Sensitivity, &c.Confidence, &c.DetectionMethod, &c.Status, &c.VerifiedBy, &c.VerifiedAt,
-			&c.Reasoning, &c.CreatedAt, &c.UpdatedAt,
+			&c.Reasoning, &c.PromptVersion, &c.CreatedAt, &c.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan classification: %w", err)
 		}
@@ -319,22 +319,22 @@ func (r *PIIClassificationRepo) BulkCreate(ctx context.Context, classifications
 	// Build batch insert
 	var sb strings.Builder
 	sb.WriteString(`INSERT INTO pii_classifications (id, field_id, data_source_id, entity_name, field_name,
-		category, type, sensitivity, confidence, detection_method, status, reasoning) VALUES `)
+		category, type, sensitivity, confidence, detection_method, status, reasoning, prompt_version) VALUES `)
 
-	args := make([]any, 0, len(classifications)*12)
+	args := make([]any, 0, len(classifications)*13)
 	for i, c := range classifications {
 		if i > 0 {
 			sb.WriteString(", ")
 		}
-		base := i * 12
-		sb.WriteString(fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
+		base := i * 13
+		sb.WriteString(fmt.Sprintf("($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
 			base+1, base+2, base+3, base+4, base+5, base+6,
-			base+7, base+8, base+9, base+10, base+11, base+12))
+			base+7, base+8, base+9, base+10, base+11, base+12, base+13))
 
 		id := types.NewID()
 		classifications[i].ID = id
 		args = append(args, id, c.FieldID, c.DataSourceID, c.EntityName, c.FieldName,
-			c.Category, c.Type, c.Sensitivity, c.Confidence, c.DetectionMethod, c.Status, c.Reasoning)
+			c.Category, c.Type, c.Sensitivity, c.Confidence, c.DetectionMethod, c.Status, c.Reasoning, c.PromptVersion)
 	}
 
 	_, err := r.pool.Exec(ctx, sb.String(), args...)
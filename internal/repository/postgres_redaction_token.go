@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// RedactionTokenRepo implements discovery.RedactionTokenRepository.
+type RedactionTokenRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewRedactionTokenRepo creates a new RedactionTokenRepo.
+func NewRedactionTokenRepo(pool *pgxpool.Pool) *RedactionTokenRepo {
+	return &RedactionTokenRepo{pool: pool}
+}
+
+// Save stores tokens in one batch. A token already stored keeps its
+// ciphertext and takes the later of the two expiries.
+func (r *RedactionTokenRepo) Save(ctx context.Context, tokens []discovery.RedactionToken) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	batch := &pgx.Batch{}
+	for i := range tokens {
+		t := &tokens[i]
+		t.CreatedAt = now
+		batch.Queue(`
+			INSERT INTO redaction_tokens (tenant_id, token, pii_type, ciphertext, created_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (tenant_id, token) DO UPDATE SET
+				expires_at = GREATEST(redaction_tokens.expires_at, EXCLUDED.expires_at)`,
+			t.TenantID, t.Token, string(t.PIIType), t.Ciphertext, t.CreatedAt, t.ExpiresAt,
+		)
+	}
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("save redaction tokens: %w", err)
+	}
+	return nil
+}
+
+// GetByTokens returns the tenant's unexpired tokens among the given ones.
+// Unknown and expired tokens are left out.
+func (r *RedactionTokenRepo) GetByTokens(ctx context.Context, tenantID types.ID, tokens []string) ([]discovery.RedactionToken, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT tenant_id, token, pii_type, ciphertext, created_at, expires_at
+		FROM redaction_tokens
+		WHERE tenant_id = $1 AND token = ANY($2) AND expires_at > NOW()`,
+		tenantID, tokens,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get redaction tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var out []discovery.RedactionToken
+	for rows.Next() {
+		var t discovery.RedactionToken
+		var piiType string
+		if err := rows.Scan(&t.TenantID, &t.Token, &piiType, &t.Ciphertext, &t.CreatedAt, &t.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("scan redaction token: %w", err)
+		}
+		t.PIIType = types.PIIType(piiType)
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// DeleteExpired removes tokens that expired before the given time and
+// returns how many were removed.
+func (r *RedactionTokenRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM redaction_tokens WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired redaction tokens: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -28,12 +28,12 @@ func (r *PostgresScanRunRepo) Create(ctx context.Context, run *discovery.ScanRun
 		INSERT INTO scan_runs (
 			id, data_source_id, tenant_id, type, status, progress, 
 			started_at, completed_at, error_message, 
-			stats,
+			stats, checkpoint,
 			created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, 
 			$7, $8, $9, 
-			$10,
+			$10, $11,
 			NOW(), NOW()
 		)`
 
@@ -49,7 +49,7 @@ func (r *PostgresScanRunRepo) Create(ctx context.Context, run *discovery.ScanRun
 	_, err := r.pool.Exec(ctx, query,
 		run.ID, run.DataSourceID, run.TenantID, run.Type, run.Status, run.Progress,
 		startedAt, completedAt, run.ErrorMessage,
-		run.Stats,
+		run.Stats, run.Checkpoint,
 	)
 	if err != nil {
 		return fmt.Errorf("create scan run: %w", err)
@@ -64,7 +64,7 @@ func (r *PostgresScanRunRepo) GetByID(ctx context.Context, id types.ID) (*discov
 		SELECT 
 			id, data_source_id, tenant_id, type, status, progress, 
 			started_at, completed_at, error_message, 
-			stats
+			stats, checkpoint
 		FROM scan_runs
 		WHERE id = $1`
 
@@ -78,7 +78,7 @@ func (r *PostgresScanRunRepo) GetByDataSource(ctx context.Context, dataSourceID
 		SELECT 
 			id, data_source_id, tenant_id, type, status, progress, 
 			started_at, completed_at, error_message, 
-			stats
+			stats, checkpoint
 		FROM scan_runs
 		WHERE data_source_id = $1
 		ORDER BY created_at DESC`
@@ -106,7 +106,7 @@ func (r *PostgresScanRunRepo) GetActive(ctx context.Context, tenantID types.ID)
 		SELECT 
 			id, data_source_id, tenant_id, type, status, progress, 
 			started_at, completed_at, error_message, 
-			stats
+			stats, checkpoint
 		FROM scan_runs
 		WHERE tenant_id = $1 AND status IN ('PENDING', 'RUNNING')
 		ORDER BY created_at DESC`
@@ -134,7 +134,7 @@ func (r *PostgresScanRunRepo) GetRecent(ctx context.Context, tenantID types.ID,
 		SELECT 
 			id, data_source_id, tenant_id, type, status, progress, 
 			started_at, completed_at, error_message, 
-			stats
+			stats, checkpoint
 		FROM scan_runs
 		WHERE tenant_id = $1
 		ORDER BY created_at DESC
@@ -167,9 +167,9 @@ func (r *PostgresScanRunRepo) Update(ctx context.Context, run *discovery.ScanRun
 		UPDATE scan_runs
 		SET 
 			status = $1, progress = $2, started_at = $3, completed_at = $4, error_message = $5,
-			stats = $6,
+			stats = $6, checkpoint = $7,
 			updated_at = NOW()
-		WHERE id = $7`
+		WHERE id = $8`
 
 	var startedAt, completedAt *time.Time
 	if run.StartedAt != nil {
@@ -181,7 +181,7 @@ func (r *PostgresScanRunRepo) Update(ctx context.Context, run *discovery.ScanRun
 
 	cmd, err := r.pool.Exec(ctx, query,
 		run.Status, run.Progress, startedAt, completedAt, run.ErrorMessage,
-		run.Stats,
+		run.Stats, run.Checkpoint,
 		run.ID,
 	)
 	if err != nil {
@@ -195,6 +195,53 @@ func (r *PostgresScanRunRepo) Update(ctx context.Context, run *discovery.ScanRun
 	return nil
 }
 
+// UpdateCheckpoint persists the progress, stats and checkpoint of a run that
+// is still RUNNING, reporting false when it is not.
+func (r *PostgresScanRunRepo) UpdateCheckpoint(ctx context.Context, run *discovery.ScanRun) (bool, error) {
+	cmd, err := r.pool.Exec(ctx, `
+		UPDATE scan_runs
+		SET progress = $1, stats = $2, checkpoint = $3, updated_at = NOW()
+		WHERE id = $4 AND status = 'RUNNING'`,
+		run.Progress, run.Stats, run.Checkpoint, run.ID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("update scan checkpoint: %w", err)
+	}
+	return cmd.RowsAffected() > 0, nil
+}
+
+// Finish persists the outcome of a run that is still RUNNING, reporting
+// false when it is not.
+func (r *PostgresScanRunRepo) Finish(ctx context.Context, run *discovery.ScanRun) (bool, error) {
+	cmd, err := r.pool.Exec(ctx, `
+		UPDATE scan_runs
+		SET status = $1, progress = $2, completed_at = $3, error_message = $4,
+			stats = $5, checkpoint = $6, updated_at = NOW()
+		WHERE id = $7 AND status = 'RUNNING'`,
+		run.Status, run.Progress, run.CompletedAt, run.ErrorMessage,
+		run.Stats, run.Checkpoint, run.ID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("finish scan run: %w", err)
+	}
+	return cmd.RowsAffected() > 0, nil
+}
+
+// Cancel marks a PENDING or RUNNING run CANCELLED, reporting false when it
+// has already finished.
+func (r *PostgresScanRunRepo) Cancel(ctx context.Context, run *discovery.ScanRun) (bool, error) {
+	cmd, err := r.pool.Exec(ctx, `
+		UPDATE scan_runs
+		SET status = 'CANCELLED', completed_at = $1, updated_at = NOW()
+		WHERE id = $2 AND status IN ('PENDING', 'RUNNING')`,
+		run.CompletedAt, run.ID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cancel scan run: %w", err)
+	}
+	return cmd.RowsAffected() > 0, nil
+}
+
 // Helper to map row to ScanRun
 func scanRowToScanRun(row pgx.Row) (*discovery.ScanRun, error) {
 	var run discovery.ScanRun
@@ -203,7 +250,7 @@ func scanRowToScanRun(row pgx.Row) (*discovery.ScanRun, error) {
 	err := row.Scan(
 		&run.ID, &run.DataSourceID, &run.TenantID, &run.Type, &run.Status, &run.Progress,
 		&startedAt, &completedAt, &run.ErrorMessage,
-		&run.Stats,
+		&run.Stats, &run.Checkpoint,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
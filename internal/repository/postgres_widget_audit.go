@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/complyark/datalens/internal/domain/consent"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// WidgetAuditRepo implements consent.WidgetAuditRepository.
+type WidgetAuditRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewWidgetAuditRepo creates a new WidgetAuditRepo.
+func NewWidgetAuditRepo(pool *pgxpool.Pool) *WidgetAuditRepo {
+	return &WidgetAuditRepo{pool: pool}
+}
+
+const widgetAuditColumns = `id, tenant_id, widget_id, widget_version, trigger, findings,
+	ai_prompt_version, ai_error, override, created_at, updated_at`
+
+func scanWidgetAudit(row pgx.Row) (*consent.WidgetAudit, error) {
+	a := &consent.WidgetAudit{}
+	var trigger string
+	var findings, override []byte
+	if err := row.Scan(
+		&a.ID, &a.TenantID, &a.WidgetID, &a.WidgetVersion, &trigger, &findings,
+		&a.AIPromptVersion, &a.AIError, &override, &a.CreatedAt, &a.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	a.Trigger = consent.WidgetAuditTrigger(trigger)
+	if err := json.Unmarshal(findings, &a.Findings); err != nil {
+		return nil, fmt.Errorf("unmarshal findings: %w", err)
+	}
+	if len(override) > 0 {
+		if err := json.Unmarshal(override, &a.Override); err != nil {
+			return nil, fmt.Errorf("unmarshal override: %w", err)
+		}
+	}
+	return a, nil
+}
+
+func (r *WidgetAuditRepo) Create(ctx context.Context, a *consent.WidgetAudit) error {
+	a.ID = types.NewID()
+	a.CreatedAt = time.Now().UTC()
+	a.UpdatedAt = a.CreatedAt
+
+	findings, err := json.Marshal(nonNil(a.Findings))
+	if err != nil {
+		return fmt.Errorf("marshal findings: %w", err)
+	}
+	var override []byte
+	if a.Override != nil {
+		if override, err = json.Marshal(a.Override); err != nil {
+			return fmt.Errorf("marshal override: %w", err)
+		}
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO consent_widget_audits (`+widgetAuditColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		a.ID, a.TenantID, a.WidgetID, a.WidgetVersion, string(a.Trigger), findings,
+		a.AIPromptVersion, a.AIError, override, a.CreatedAt, a.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create widget audit: %w", err)
+	}
+	return nil
+}
+
+func (r *WidgetAuditRepo) GetLatest(ctx context.Context, widgetID types.ID) (*consent.WidgetAudit, error) {
+	a, err := scanWidgetAudit(r.pool.QueryRow(ctx, `SELECT `+widgetAuditColumns+`
+		FROM consent_widget_audits WHERE widget_id = $1
+		ORDER BY created_at DESC LIMIT 1`, widgetID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, types.NewNotFoundError("widget audit", widgetID)
+		}
+		return nil, fmt.Errorf("get latest widget audit: %w", err)
+	}
+	return a, nil
+}
+
+func (r *WidgetAuditRepo) ListByWidget(ctx context.Context, widgetID types.ID) ([]consent.WidgetAudit, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+widgetAuditColumns+`
+		FROM consent_widget_audits WHERE widget_id = $1
+		ORDER BY created_at DESC`, widgetID)
+	if err != nil {
+		return nil, fmt.Errorf("list widget audits: %w", err)
+	}
+	defer rows.Close()
+
+	var items []consent.WidgetAudit
+	for rows.Next() {
+		a, err := scanWidgetAudit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan widget audit: %w", err)
+		}
+		items = append(items, *a)
+	}
+	return items, rows.Err()
+}
+
+func (r *WidgetAuditRepo) SetOverride(ctx context.Context, id types.ID, o *consent.WidgetAuditOverride) error {
+	override, err := json.Marshal(o)
+	if err != nil {
+		return fmt.Errorf("marshal override: %w", err)
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE consent_widget_audits SET override = $2, updated_at = NOW()
+		WHERE id = $1`, id, override)
+	if err != nil {
+		return fmt.Errorf("set widget audit override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return types.NewNotFoundError("widget audit", id)
+	}
+	return nil
+}
+
+// Compile-time check.
+var _ consent.WidgetAuditRepository = (*WidgetAuditRepo)(nil)
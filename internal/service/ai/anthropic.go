@@ -89,6 +89,16 @@ func (p *AnthropicProvider) Complete(ctx context.Context, prompt string, opts Co
 		},
 		Temperature: temp,
 	}
+	// Anthropic's structured output is a forced tool call whose input is
+	// the answer.
+	if opts.ResponseSchema != nil && p.config.NativeJSONSchema {
+		reqBody.Tools = []anthropicTool{{
+			Name:        opts.ResponseSchema.Name,
+			Description: "Record the answer.",
+			InputSchema: opts.ResponseSchema.Schema,
+		}}
+		reqBody.ToolChoice = &anthropicToolChoice{Type: "tool", Name: opts.ResponseSchema.Name}
+	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
@@ -134,31 +144,50 @@ func (p *AnthropicProvider) Complete(ctx context.Context, prompt string, opts Co
 		return nil, fmt.Errorf("%s: unmarshal response: %w", p.config.Name, err)
 	}
 
-	// Extract text from content blocks
+	// Extract text from content blocks; a forced tool call's input is the
+	// whole answer.
 	var responseText string
 	for _, block := range result.Content {
-		if block.Type == "text" {
+		switch block.Type {
+		case "text":
 			responseText += block.Text
+		case "tool_use":
+			responseText = string(block.Input)
 		}
 	}
 
 	return &CompletionResult{
-		Response:   responseText,
-		Provider:   p.config.Name,
-		Model:      model,
-		TokensUsed: result.Usage.InputTokens + result.Usage.OutputTokens,
-		Duration:   time.Since(start),
+		Response:     responseText,
+		Provider:     p.config.Name,
+		Model:        model,
+		TokensUsed:   result.Usage.InputTokens + result.Usage.OutputTokens,
+		InputTokens:  result.Usage.InputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+		Duration:     time.Since(start),
 	}, nil
 }
 
 // --- Anthropic wire format ---
 
 type anthropicRequest struct {
-	Model       string             `json:"model"`
-	MaxTokens   int                `json:"max_tokens"`
-	System      string             `json:"system,omitempty"`
-	Messages    []anthropicMessage `json:"messages"`
-	Temperature float64            `json:"temperature,omitempty"`
+	Model       string               `json:"model"`
+	MaxTokens   int                  `json:"max_tokens"`
+	System      string               `json:"system,omitempty"`
+	Messages    []anthropicMessage   `json:"messages"`
+	Temperature float64              `json:"temperature,omitempty"`
+	Tools       []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"` // "tool"
+	Name string `json:"name"`
 }
 
 type anthropicMessage struct {
@@ -172,8 +201,9 @@ type anthropicResponse struct {
 }
 
 type anthropicContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	Input json.RawMessage `json:"input,omitempty"` // tool_use blocks
 }
 
 type anthropicUsage struct {
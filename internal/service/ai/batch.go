@@ -0,0 +1,316 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Table-level Batched PII Detection
+// =============================================================================
+//
+// DetectPII classifies one column per call, so a 200-column table costs 200
+// round-trips and repeats the instructions 200 times. DetectPIIBatch sends
+// all columns of an entity in one structured call instead, splitting very
+// wide tables into chunks that fit a token budget.
+
+// DefaultBatchTokenBudget is the estimated token budget of one batched call
+// when PIIBatchInput.TokenBudget is not set. It covers the prompt and the
+// expected response.
+const DefaultBatchTokenBudget = 6000
+
+// batchResponseTokensPerColumn is the response allowance for one column's
+// JSON entry, used both for chunking and for the call's MaxTokens.
+const batchResponseTokensPerColumn = 120
+
+// PIIBatchInput holds every column of one entity for table-level detection.
+// Samples are sanitized by the gateway before they are sent.
+type PIIBatchInput struct {
+	TableName   string           `json:"table_name"`
+	Industry    string           `json:"industry,omitempty"`
+	Columns     []PIIBatchColumn `json:"columns"`
+	TokenBudget int              `json:"token_budget,omitempty"` // Per call; 0 uses DefaultBatchTokenBudget
+}
+
+// PIIBatchColumn is one column of a PIIBatchInput.
+type PIIBatchColumn struct {
+	ColumnName       string   `json:"column_name"`
+	DataType         string   `json:"data_type"`
+	SanitizedSamples []string `json:"sanitized_samples"`
+}
+
+// PIIBatchResult holds the per-column results of a batched detection and
+// what the batch cost compared to one call per column.
+type PIIBatchResult struct {
+	// Results is keyed by column name. Columns the model did not return
+	// are absent.
+	Results    map[string]*PIIDetectionResult `json:"results"`
+	Provider   string                         `json:"provider"`
+	Calls      int                            `json:"calls"`
+	TokensUsed int                            `json:"tokens_used"`
+	Duration   time.Duration                  `json:"duration"`
+
+	// TokensSaved estimates the instruction tokens that per-column calls
+	// would have repeated; LatencySaved the round-trips they would have
+	// added at this batch's average call latency.
+	TokensSaved  int           `json:"tokens_saved"`
+	LatencySaved time.Duration `json:"latency_saved"`
+}
+
+// BatchGateway is implemented by gateways that can classify all columns of
+// an entity in one call. Callers type-assert for it and fall back to
+// Gateway.DetectPII per column.
+type BatchGateway interface {
+	Gateway
+	DetectPIIBatch(ctx context.Context, input PIIBatchInput) (*PIIBatchResult, error)
+}
+
+// Compile-time checks.
+var (
+	_ BatchGateway = (*DefaultGateway)(nil)
+	_ BatchGateway = (*CachedGateway)(nil)
+)
+
+// columnInput is the single-column equivalent of one batch column.
+func (in PIIBatchInput) columnInput(col PIIBatchColumn) PIIDetectionInput {
+	return PIIDetectionInput{
+		TableName:        in.TableName,
+		ColumnName:       col.ColumnName,
+		DataType:         col.DataType,
+		SanitizedSamples: col.SanitizedSamples,
+		Industry:         in.Industry,
+	}
+}
+
+// batchColumnLine renders one column of the batch prompt.
+func batchColumnLine(col PIIBatchColumn) string {
+	return fmt.Sprintf("- %s (%s) samples: %v\n", col.ColumnName, col.DataType, col.SanitizedSamples)
+}
+
+// estimateTokens approximates a prompt's token count at four characters
+// per token, which is close enough for budgeting across providers.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// promptTokens estimates the tokens of a rendered prompt and its system
+// prompt.
+func promptTokens(p *RenderedPrompt) int {
+	return estimateTokens(p.System) + estimateTokens(p.Text)
+}
+
+// chunkBatchColumns splits columns into consecutive chunks whose estimated
+// prompt and response fit the budget; overhead is the tokens of the prompt
+// header every chunk repeats. A column that alone exceeds the budget still
+// gets a chunk of its own.
+func chunkBatchColumns(overhead int, columns []PIIBatchColumn, budget int) [][]PIIBatchColumn {
+	var chunks [][]PIIBatchColumn
+	var current []PIIBatchColumn
+	used := overhead
+	for _, col := range columns {
+		cost := estimateTokens(batchColumnLine(col)) + batchResponseTokensPerColumn
+		if len(current) > 0 && used+cost > budget {
+			chunks = append(chunks, current)
+			current, used = nil, overhead
+		}
+		current = append(current, col)
+		used += cost
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// DetectPIIBatch classifies all columns of an entity, one call per chunk.
+// Raw samples are sanitized before being sent to any provider.
+func (g *DefaultGateway) DetectPIIBatch(ctx context.Context, input PIIBatchInput) (*PIIBatchResult, error) {
+	budget := input.TokenBudget
+	if budget <= 0 {
+		budget = DefaultBatchTokenBudget
+	}
+
+	columns := make([]PIIBatchColumn, len(input.Columns))
+	for i, col := range input.Columns {
+		if len(col.SanitizedSamples) > 0 {
+			col.SanitizedSamples = g.sanitizer.SanitizeSamples(col.SanitizedSamples)
+		}
+		columns[i] = col
+	}
+
+	prompt, err := g.prompts.Render(ctx, PromptPIIBatch, PIIBatchPromptData{TableName: input.TableName, Industry: input.Industry})
+	if err != nil {
+		return nil, fmt.Errorf("batch pii detection: %w", err)
+	}
+	overhead := promptTokens(prompt)
+	out := &PIIBatchResult{Results: make(map[string]*PIIDetectionResult, len(columns))}
+
+	for _, chunk := range chunkBatchColumns(overhead, columns, budget) {
+		var text strings.Builder
+		text.WriteString(prompt.Text)
+		for _, col := range chunk {
+			text.WriteString(batchColumnLine(col))
+		}
+
+		opts := CompletionOptions{
+			UseCase:        "pii_detection",
+			Priority:       "accuracy",
+			MaxTokens:      max(512, batchResponseTokensPerColumn*len(chunk)),
+			Temperature:    0.1,
+			SystemPrompt:   prompt.System,
+			ResponseSchema: piiBatchSchema,
+		}
+
+		start := time.Now()
+		answer, result, err := CompleteJSON(ctx, g, text.String(), opts, func(a *piiBatchAnswer, v *Validation) {
+			validatePIIBatchAnswer(a, chunk, v)
+		})
+		if err != nil && !errors.Is(err, ErrInvalidResponse) {
+			return nil, fmt.Errorf("batch pii detection: %w", err)
+		}
+		elapsed := time.Since(start)
+
+		out.Calls++
+		out.TokensUsed += result.TokensUsed
+		out.Duration += elapsed
+		out.Provider = result.Provider
+
+		if err != nil {
+			g.logger.Warn("rejected batched PII response",
+				"error", err,
+				"provider", result.Provider,
+				"table", input.TableName,
+				"columns", len(chunk),
+			)
+			continue
+		}
+		parsed := matchBatchColumns(answer, chunk)
+		// Attribute the call's tokens evenly so per-column results stay
+		// comparable with single-column ones.
+		share := result.TokensUsed / len(chunk)
+		for name, r := range parsed {
+			r.Provider = result.Provider
+			r.PromptVersion = prompt.Version
+			r.TokensUsed = share
+			r.Duration = elapsed
+			out.Results[name] = r
+		}
+	}
+
+	// The instructions a per-column call would have repeated: its prompt
+	// without the column's own details.
+	single := 0
+	if p, err := g.prompts.Render(ctx, PromptPIIDetection, PIIDetectionInput{TableName: input.TableName, Industry: input.Industry}); err == nil {
+		single = promptTokens(p)
+	}
+	out.TokensSaved, out.LatencySaved = batchSavings(single, overhead, len(columns), out.Calls, out.Duration)
+	return out, nil
+}
+
+// batchSavings estimates what the batched calls saved over one call per
+// column: the repeated per-column instructions (single tokens each) minus
+// the batch's own (batch tokens per call), and the avoided round-trips at
+// the batch's average latency.
+func batchSavings(single, batch, columns, calls int, elapsed time.Duration) (int, time.Duration) {
+	if calls == 0 || columns <= calls {
+		return 0, 0
+	}
+
+	tokens := columns*single - calls*batch
+	if tokens < 0 {
+		tokens = 0
+	}
+	latency := time.Duration(columns-calls) * (elapsed / time.Duration(calls))
+	return tokens, latency
+}
+
+// piiBatchAnswer is the model's answer to a batched detection prompt.
+type piiBatchAnswer struct {
+	Columns []piiBatchEntry `json:"columns"`
+}
+
+type piiBatchEntry struct {
+	Column string `json:"column"`
+	PIIDetectionResult
+}
+
+// validatePIIBatchAnswer coerces every entry and reports requested columns
+// the model left out.
+func validatePIIBatchAnswer(a *piiBatchAnswer, columns []PIIBatchColumn, v *Validation) {
+	for i := range a.Columns {
+		validatePIIResult(&a.Columns[i].PIIDetectionResult, v, fmt.Sprintf("column %s: ", a.Columns[i].Column))
+	}
+
+	matched := matchBatchColumns(a, columns)
+	var missing []string
+	for _, col := range columns {
+		if _, ok := matched[col.ColumnName]; !ok {
+			missing = append(missing, col.ColumnName)
+		}
+	}
+	if len(missing) > 0 {
+		v.Problem("no entry for columns %s", strings.Join(missing, ", "))
+	}
+}
+
+// parseBatchPIIResponse decodes a batched answer and maps it back to the
+// requested columns.
+func parseBatchPIIResponse(response string, columns []PIIBatchColumn) (map[string]*PIIDetectionResult, error) {
+	var answer piiBatchAnswer
+	if err := json.Unmarshal([]byte(extractJSON(response)), &answer); err != nil {
+		return nil, fmt.Errorf("json parse: %w", err)
+	}
+	return matchBatchColumns(&answer, columns), nil
+}
+
+// matchBatchColumns maps the model's per-column entries back to the
+// requested columns. Column names are matched exactly first, then
+// case-insensitively; entries for columns that were not asked about are
+// dropped.
+func matchBatchColumns(answer *piiBatchAnswer, columns []PIIBatchColumn) map[string]*PIIDetectionResult {
+	requested := make(map[string]string, len(columns))
+	for _, col := range columns {
+		requested[col.ColumnName] = col.ColumnName
+	}
+	for _, col := range columns {
+		if _, ok := requested[strings.ToLower(col.ColumnName)]; !ok {
+			requested[strings.ToLower(col.ColumnName)] = col.ColumnName
+		}
+	}
+
+	results := make(map[string]*PIIDetectionResult, len(answer.Columns))
+	for _, entry := range answer.Columns {
+		name, ok := requested[entry.Column]
+		if !ok {
+			name, ok = requested[strings.ToLower(entry.Column)]
+		}
+		if !ok {
+			continue
+		}
+		r := entry.PIIDetectionResult
+		results[name] = &r
+	}
+	return results
+}
+
+// detectEachColumn is the fallback for gateways without batch support: one
+// DetectPII call per column, reported in batch form.
+func detectEachColumn(ctx context.Context, gw Gateway, input PIIBatchInput) (*PIIBatchResult, error) {
+	out := &PIIBatchResult{Results: make(map[string]*PIIDetectionResult, len(input.Columns))}
+	for _, col := range input.Columns {
+		r, err := gw.DetectPII(ctx, input.columnInput(col))
+		if err != nil {
+			return nil, err
+		}
+		out.Results[col.ColumnName] = r
+		out.Calls++
+		out.TokensUsed += r.TokensUsed
+		out.Duration += r.Duration
+		out.Provider = r.Provider
+	}
+	return out, nil
+}
@@ -0,0 +1,157 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// batchProvider answers batched prompts, flagging every column whose name
+// contains "email" as an EMAIL.
+type batchProvider struct {
+	prompts []string
+}
+
+var batchColumnLineRe = regexp.MustCompile(`(?m)^- (\S+) \(`)
+
+func (p *batchProvider) Name() string                       { return "batch" }
+func (p *batchProvider) IsAvailable(_ context.Context) bool { return true }
+
+func (p *batchProvider) Complete(_ context.Context, prompt string, _ CompletionOptions) (*CompletionResult, error) {
+	p.prompts = append(p.prompts, prompt)
+
+	type entry struct {
+		Column string        `json:"column"`
+		IsPII  bool          `json:"is_pii"`
+		Type   types.PIIType `json:"type,omitempty"`
+	}
+	var entries []entry
+	for _, m := range batchColumnLineRe.FindAllStringSubmatch(prompt, -1) {
+		e := entry{Column: strings.ToUpper(m[1])}
+		if strings.Contains(m[1], "email") {
+			e.IsPII, e.Type = true, types.PIITypeEmail
+		}
+		entries = append(entries, e)
+	}
+	body, err := json.Marshal(map[string]any{"columns": entries})
+	if err != nil {
+		return nil, err
+	}
+	return &CompletionResult{Response: string(body), Provider: "batch", TokensUsed: 300, Duration: time.Millisecond}, nil
+}
+
+func newBatchTestGateway() (*DefaultGateway, *batchProvider) {
+	provider := &batchProvider{}
+	reg := NewRegistry()
+	reg.Register("batch", provider, ProviderConfig{})
+	return NewDefaultGateway(NewSelector(reg, []string{"batch"}, nil), nil), provider
+}
+
+func TestDefaultGateway_DetectPIIBatch_OneCall(t *testing.T) {
+	gw, provider := newBatchTestGateway()
+
+	result, err := gw.DetectPIIBatch(context.Background(), PIIBatchInput{
+		TableName: "customers",
+		Columns: []PIIBatchColumn{
+			{ColumnName: "id", DataType: "int", SanitizedSamples: []string{"1", "2"}},
+			{ColumnName: "email", DataType: "varchar", SanitizedSamples: []string{"john@example.com"}},
+			{ColumnName: "created_at", DataType: "timestamp"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, provider.prompts, 1)
+	assert.NotContains(t, provider.prompts[0], "john@example.com", "samples are sanitized")
+	assert.Equal(t, 1, result.Calls)
+	assert.Equal(t, 300, result.TokensUsed)
+
+	require.Len(t, result.Results, 3, "columns are matched case-insensitively")
+	assert.True(t, result.Results["email"].IsPII)
+	assert.Equal(t, types.PIITypeEmail, result.Results["email"].Type)
+	assert.Equal(t, 100, result.Results["email"].TokensUsed)
+	assert.False(t, result.Results["id"].IsPII)
+
+	assert.Positive(t, result.TokensSaved)
+	assert.Equal(t, 2*result.Duration, result.LatencySaved)
+}
+
+func TestDefaultGateway_DetectPIIBatch_ChunksByBudget(t *testing.T) {
+	gw, provider := newBatchTestGateway()
+
+	input := PIIBatchInput{TableName: "wide", TokenBudget: 1200}
+	for i := range 30 {
+		input.Columns = append(input.Columns, PIIBatchColumn{ColumnName: fmt.Sprintf("email_%d", i), DataType: "varchar"})
+	}
+
+	result, err := gw.DetectPIIBatch(context.Background(), input)
+	require.NoError(t, err)
+
+	assert.Greater(t, result.Calls, 1)
+	assert.Less(t, result.Calls, 30)
+	assert.Len(t, provider.prompts, result.Calls)
+	assert.Len(t, result.Results, 30)
+	builtin, _ := BuiltinPrompt(PromptPIIBatch)
+	for _, prompt := range provider.prompts {
+		columns := len(batchColumnLineRe.FindAllString(prompt, -1))
+		estimate := estimateTokens(builtin.System) + estimateTokens(prompt) + columns*batchResponseTokensPerColumn
+		assert.LessOrEqual(t, estimate, input.TokenBudget)
+	}
+}
+
+func TestParseBatchPIIResponse(t *testing.T) {
+	columns := []PIIBatchColumn{{ColumnName: "Email"}, {ColumnName: "phone"}}
+
+	results, err := parseBatchPIIResponse(`{"columns": [
+		{"column": "Email", "is_pii": true, "type": "EMAIL"},
+		{"column": "PHONE", "is_pii": true, "type": "PHONE"},
+		{"column": "invented", "is_pii": true}
+	]}`, columns)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, types.PIITypePhone, results["phone"].Type)
+
+	_, err = parseBatchPIIResponse("not json", columns)
+	assert.Error(t, err)
+}
+
+func TestCachedGateway_DetectPIIBatch(t *testing.T) {
+	gateway, mockNext, s := setupTest(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	input := PIIBatchInput{
+		TableName: "users",
+		Columns: []PIIBatchColumn{
+			{ColumnName: "email", DataType: "varchar"},
+			{ColumnName: "age", DataType: "int"},
+		},
+	}
+	// MockGateway cannot batch, so misses go through DetectPII.
+	mockNext.On("DetectPII", ctx, mock.MatchedBy(func(in PIIDetectionInput) bool { return in.ColumnName == "email" })).
+		Return(&PIIDetectionResult{IsPII: true, Type: types.PIITypeEmail, Provider: "openai", TokensUsed: 40}, nil).Once()
+	mockNext.On("DetectPII", ctx, mock.MatchedBy(func(in PIIDetectionInput) bool { return in.ColumnName == "age" })).
+		Return(&PIIDetectionResult{Provider: "openai", TokensUsed: 30}, nil).Once()
+
+	first, err := gateway.DetectPIIBatch(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, 2, first.Calls)
+	assert.Equal(t, 70, first.TokensUsed)
+	assert.True(t, first.Results["email"].IsPII)
+
+	second, err := gateway.DetectPIIBatch(ctx, input)
+	require.NoError(t, err)
+	assert.Zero(t, second.Calls)
+	assert.Equal(t, "openai (cached)", second.Results["email"].Provider)
+	assert.True(t, second.Results["email"].IsPII)
+	mockNext.AssertExpectations(t)
+}
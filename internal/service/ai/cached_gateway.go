@@ -5,32 +5,27 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/redis/go-redis/v9"
-
-	"github.com/complyark/datalens/internal/config"
-	"github.com/complyark/datalens/pkg/types"
 )
 
-// CachedGateway wraps an AI Gateway with Redis caching and token budgeting.
+// CachedGateway wraps an AI Gateway with Redis caching. Token budgets are
+// enforced by the Selector through AIUsageService.
 type CachedGateway struct {
 	next   Gateway
 	redis  *redis.Client
 	logger *slog.Logger
-	cfg    config.AIConfig
 }
 
 // NewCachedGateway creates a new CachedGateway decorator.
-func NewCachedGateway(next Gateway, rdb *redis.Client, logger *slog.Logger, cfg config.AIConfig) *CachedGateway {
+func NewCachedGateway(next Gateway, rdb *redis.Client, logger *slog.Logger) *CachedGateway {
 	return &CachedGateway{
 		next:   next,
 		redis:  rdb,
 		logger: logger.With("component", "ai_cache"),
-		cfg:    cfg,
 	}
 }
 
@@ -39,15 +34,8 @@ func NewCachedGateway(next Gateway, rdb *redis.Client, logger *slog.Logger, cfg
 // =============================================================================
 
 func (g *CachedGateway) DetectPII(ctx context.Context, input PIIDetectionInput) (*PIIDetectionResult, error) {
-	if err := g.checkBudget(ctx); err != nil {
-		return nil, err
-	}
-
 	key := g.cacheKey("pii", input)
 	if cached, err := g.getFromCache(ctx, key, &PIIDetectionResult{}); err == nil {
-		// On cache hit, we don't track *new* token usage against the budget,
-		// but we might want to log it or track "saved" tokens.
-		// For now, we return the cached result immediately.
 		cached.(*PIIDetectionResult).Duration = 0 // Cached response is instant
 		cached.(*PIIDetectionResult).Provider = cached.(*PIIDetectionResult).Provider + " (cached)"
 		return cached.(*PIIDetectionResult), nil
@@ -63,19 +51,60 @@ func (g *CachedGateway) DetectPII(ctx context.Context, input PIIDetectionInput)
 		g.logger.WarnContext(ctx, "failed to cache pii result", "error", err)
 	}
 
-	// Track usage
-	if err := g.trackUsage(ctx, result.TokensUsed); err != nil {
-		g.logger.WarnContext(ctx, "failed to track token usage", "error", err)
-	}
-
 	return result, nil
 }
 
-func (g *CachedGateway) SuggestPurposes(ctx context.Context, input PurposeSuggestionInput) ([]PurposeSuggestion, error) {
-	if err := g.checkBudget(ctx); err != nil {
+// DetectPIIBatch serves cached columns from Redis and sends only the misses
+// to the wrapped gateway, batched when it supports batching.
+func (g *CachedGateway) DetectPIIBatch(ctx context.Context, input PIIBatchInput) (*PIIBatchResult, error) {
+	out := &PIIBatchResult{Results: make(map[string]*PIIDetectionResult, len(input.Columns))}
+	misses := input
+	misses.Columns = nil
+	keys := make(map[string]string, len(input.Columns))
+	for _, col := range input.Columns {
+		key := g.cacheKey("pii", input.columnInput(col))
+		if cached, err := g.getFromCache(ctx, key, &PIIDetectionResult{}); err == nil {
+			r := cached.(*PIIDetectionResult)
+			r.Duration = 0
+			r.Provider = r.Provider + " (cached)"
+			out.Results[col.ColumnName] = r
+			continue
+		}
+		keys[col.ColumnName] = key
+		misses.Columns = append(misses.Columns, col)
+	}
+	if len(misses.Columns) == 0 {
+		return out, nil
+	}
+
+	var result *PIIBatchResult
+	var err error
+	if next, ok := g.next.(BatchGateway); ok {
+		result, err = next.DetectPIIBatch(ctx, misses)
+	} else {
+		result, err = detectEachColumn(ctx, g.next, misses)
+	}
+	if err != nil {
 		return nil, err
 	}
 
+	for name, r := range result.Results {
+		out.Results[name] = r
+		if err := g.setToCache(ctx, keys[name], r, 24*time.Hour); err != nil {
+			g.logger.WarnContext(ctx, "failed to cache pii result", "error", err)
+		}
+	}
+	out.Provider = result.Provider
+	out.Calls = result.Calls
+	out.TokensUsed = result.TokensUsed
+	out.Duration = result.Duration
+	out.TokensSaved = result.TokensSaved
+	out.LatencySaved = result.LatencySaved
+
+	return out, nil
+}
+
+func (g *CachedGateway) SuggestPurposes(ctx context.Context, input PurposeSuggestionInput) ([]PurposeSuggestion, error) {
 	key := g.cacheKey("purposes", input)
 	var cachedSuggestions []PurposeSuggestion
 	if cached, err := g.getFromCache(ctx, key, &cachedSuggestions); err == nil {
@@ -94,18 +123,10 @@ func (g *CachedGateway) SuggestPurposes(ctx context.Context, input PurposeSugges
 		}
 	}
 
-	// Purpose suggestion token usage is harder to track precisely if not returned by next layer
-	// Assuming a fixed cost or tracking if available in future interface updates.
-	// For now, we skip explicit token tracking for purposes as it's usually low volume.
-
 	return result, nil
 }
 
 func (g *CachedGateway) Complete(ctx context.Context, prompt string, opts CompletionOptions) (*CompletionResult, error) {
-	if err := g.checkBudget(ctx); err != nil {
-		return nil, err
-	}
-
 	// Calculate cache key based on prompt + options
 	cacheInput := struct {
 		Prompt string            `json:"prompt"`
@@ -132,10 +153,6 @@ func (g *CachedGateway) Complete(ctx context.Context, prompt string, opts Comple
 		}
 	}
 
-	if err := g.trackUsage(ctx, result.TokensUsed); err != nil {
-		g.logger.WarnContext(ctx, "failed to track token usage", "error", err)
-	}
-
 	return result, nil
 }
 
@@ -170,69 +187,3 @@ func (g *CachedGateway) setToCache(ctx context.Context, key string, value any, t
 	}
 	return g.redis.Set(ctx, key, bytes, ttl).Err()
 }
-
-// =============================================================================
-// Internals: Budgeting
-// =============================================================================
-
-func (g *CachedGateway) trackUsage(ctx context.Context, tokens int) error {
-	if tokens <= 0 {
-		return nil
-	}
-	tenantID, ok := types.TenantIDFromContext(ctx)
-	if !ok {
-		return nil // System call or no tenant context
-	}
-
-	today := time.Now().Format("20060102")
-	key := fmt.Sprintf("tenant:%s:ai:tokens:%s", tenantID, today)
-
-	// Increment daily usage (expire after 30 days for reporting)
-	pipe := g.redis.Pipeline()
-	pipe.IncrBy(ctx, key, int64(tokens))
-	pipe.Expire(ctx, key, 30*24*time.Hour)
-	_, err := pipe.Exec(ctx)
-	return err
-}
-
-func (g *CachedGateway) checkBudget(ctx context.Context) error {
-	tenantID, ok := types.TenantIDFromContext(ctx)
-	if !ok {
-		return nil
-	}
-
-	// 1. Check if tenant has a custom budget set
-	budgetKey := fmt.Sprintf("tenant:%s:ai:budget", tenantID)
-	budgetStr, err := g.redis.Get(ctx, budgetKey).Result()
-	if errors.Is(err, redis.Nil) {
-		return nil // No specific budget set, allow (or fallback to global plan limit)
-	}
-	if err != nil {
-		g.logger.ErrorContext(ctx, "failed to check budget", "error", err)
-		return nil // Fail open on Redis error
-	}
-
-	var budget int
-	if _, err := fmt.Sscanf(budgetStr, "%d", &budget); err != nil {
-		return nil
-	}
-
-	// 2. Check usage today (simplistic budget = daily limit for now)
-	// Real implementation might aggregate monthly.
-	today := time.Now().Format("20060102")
-	usageKey := fmt.Sprintf("tenant:%s:ai:tokens:%s", tenantID, today)
-	usage, err := g.redis.Get(ctx, usageKey).Int()
-	if err != nil && !errors.Is(err, redis.Nil) {
-		g.logger.ErrorContext(ctx, "failed to get usage", "error", err)
-		return nil
-	}
-
-	if usage >= budget {
-		return types.NewDomainError(
-			types.ErrCodeQuotaExceeded,
-			fmt.Sprintf("AI token budget exceeded (%d/%d)", usage, budget),
-		)
-	}
-
-	return nil
-}
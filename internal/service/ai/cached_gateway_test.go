@@ -2,7 +2,6 @@ package ai
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 	"testing"
 	"time"
@@ -13,7 +12,6 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
-	"github.com/complyark/datalens/internal/config"
 	"github.com/complyark/datalens/pkg/types"
 )
 
@@ -63,10 +61,7 @@ func setupTest(t *testing.T) (*CachedGateway, *MockGateway, *miniredis.Miniredis
 	mockNext := new(MockGateway)
 	logger := slog.Default() // Use default logger for tests
 
-	// Dummy cfg
-	cfg := config.AIConfig{}
-
-	gateway := NewCachedGateway(mockNext, rdb, logger, cfg)
+	gateway := NewCachedGateway(mockNext, rdb, logger)
 	return gateway, mockNext, s
 }
 
@@ -74,10 +69,7 @@ func TestCachedGateway_DetectPII_CacheMiss(t *testing.T) {
 	gateway, mockNext, s := setupTest(t)
 	defer s.Close()
 
-	ctx := context.Background()
-	// Needs tenant context for usage tracking
-	tenantID := types.NewID()
-	ctx = context.WithValue(ctx, types.ContextKeyTenantID, tenantID)
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, types.NewID())
 
 	input := PIIDetectionInput{
 		SanitizedSamples: []string{"My name is John Doe"},
@@ -101,14 +93,10 @@ func TestCachedGateway_DetectPII_CacheMiss(t *testing.T) {
 
 	mockNext.AssertExpectations(t)
 
-	// Verify it's in Redis
-	// Key calculation is hidden, but we can verify usage key
-	// Usage key: tenant:{id}:ai:tokens:{date}
-	today := time.Now().Format("20060102")
-	usageKey := "tenant:" + tenantID.String() + ":ai:tokens:" + today
-	val, err := s.Get(usageKey)
-	require.NoError(t, err)
-	assert.Equal(t, "10", val)
+	// Verify it's in Redis, and that no token usage is kept there
+	keys := s.Keys()
+	require.Len(t, keys, 1)
+	assert.Contains(t, keys[0], "ai:pii:")
 }
 
 func TestCachedGateway_DetectPII_CacheHit(t *testing.T) {
@@ -145,33 +133,6 @@ func TestCachedGateway_DetectPII_CacheHit(t *testing.T) {
 	mockNext.AssertExpectations(t) // Should only be called once
 }
 
-func TestCachedGateway_BudgetExceeded(t *testing.T) {
-	gateway, _, s := setupTest(t)
-	defer s.Close()
-
-	ctx := context.Background()
-	tenantID := types.NewID()
-	ctx = context.WithValue(ctx, types.ContextKeyTenantID, tenantID)
-
-	// Set Budget: 100
-	s.Set("tenant:"+tenantID.String()+":ai:budget", "100")
-
-	// Set Usage: 101
-	today := time.Now().Format("20060102")
-	s.Set("tenant:"+tenantID.String()+":ai:tokens:"+today, "101")
-
-	input := PIIDetectionInput{SanitizedSamples: []string{"Cheap request"}}
-
-	_, err := gateway.DetectPII(ctx, input)
-	assert.Error(t, err)
-
-	// Check error type
-	var domainErr *types.DomainError
-	isDomainErr := errors.As(err, &domainErr)
-	assert.True(t, isDomainErr)
-	assert.Equal(t, types.ErrCodeQuotaExceeded, domainErr.Code)
-}
-
 func TestCachedGateway_RedisFailure_FailOpen(t *testing.T) {
 	gateway, mockNext, s := setupTest(t)
 
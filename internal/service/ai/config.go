@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/complyark/datalens/internal/config"
+)
+
+// ProviderConfigsFromConfig returns a provider configuration for every
+// provider with credentials in cfg, plus the local LLM, which needs none.
+// With LocalOnly set, only the local LLM is configured.
+func ProviderConfigsFromConfig(cfg config.AIConfig) []ProviderConfig {
+	var providers []ProviderConfig
+	if cfg.LocalOnly {
+		cfg.OpenAI.APIKey, cfg.Anthropic.APIKey, cfg.HuggingFace.APIKey = "", "", ""
+	}
+
+	// OpenAI
+	if cfg.OpenAI.APIKey != "" {
+		providers = append(providers, ProviderConfig{
+			Name:              "openai",
+			Type:              ProviderTypeOpenAICompatible,
+			APIKey:            cfg.OpenAI.APIKey,
+			Endpoint:          "https://api.openai.com/v1",
+			DefaultModel:      cfg.OpenAI.Model,
+			RequestsPerMinute: 500,
+			TokensPerMinute:   100000,
+
+			CostPer1KInputTokens:  cfg.OpenAI.InputCostPer1K,
+			CostPer1KOutputTokens: cfg.OpenAI.OutputCostPer1K,
+			NativeJSONSchema:      true,
+		})
+	}
+
+	// Anthropic
+	if cfg.Anthropic.APIKey != "" {
+		providers = append(providers, ProviderConfig{
+			Name:              "anthropic",
+			Type:              ProviderTypeAnthropic,
+			APIKey:            cfg.Anthropic.APIKey,
+			DefaultModel:      cfg.Anthropic.Model,
+			RequestsPerMinute: 500,
+			TokensPerMinute:   100000,
+
+			CostPer1KInputTokens:  cfg.Anthropic.InputCostPer1K,
+			CostPer1KOutputTokens: cfg.Anthropic.OutputCostPer1K,
+			NativeJSONSchema:      true,
+		})
+	}
+
+	// Hugging Face (Generic HTTP)
+	if cfg.HuggingFace.APIKey != "" {
+		providers = append(providers, ProviderConfig{
+			Name:                "huggingface",
+			Type:                ProviderTypeGenericHTTP,
+			APIKey:              cfg.HuggingFace.APIKey,
+			Endpoint:            cfg.HuggingFace.Endpoint + "/" + cfg.HuggingFace.Model,
+			RequestBodyTemplate: `{"inputs": "{{.Prompt}}", "parameters": {"max_new_tokens": {{.MaxTokens}}, "temperature": {{.Temperature}}}}`,
+			ResponseContentPath: "0.generated_text",
+			DefaultModel:        cfg.HuggingFace.Model,
+			RequestsPerMinute:   100,
+			TokensPerMinute:     10000,
+			Timeout:             30 * time.Second,
+		})
+	}
+
+	// Local LLM (Ollama or llama.cpp server). CPU inference is slow, so the
+	// breaker only counts calls as slow once they near the timeout.
+	local := cfg.LocalLLM
+	providers = append(providers, ProviderConfig{
+		Name:              "local",
+		Type:              ProviderTypeLocal,
+		LocalBackend:      local.Backend,
+		Endpoint:          local.Endpoint,
+		DefaultModel:      local.Model,
+		FastModel:         local.FastModel,
+		RequestsPerMinute: 1000,
+		TokensPerMinute:   1000000,
+		Timeout:           local.Timeout,
+		SlowCall:          local.Timeout,
+		NativeJSONSchema:  true,
+
+		ContextWindow:  local.ContextWindow,
+		MaxConcurrency: local.MaxConcurrency,
+		MaxQueue:       local.MaxQueue,
+		PullModel:      local.PullModel,
+	})
+
+	return providers
+}
+
+// FallbackChainFromConfig returns the provider order to try: the configured
+// default first, then the rest.
+func FallbackChainFromConfig(cfg config.AIConfig) []string {
+	if cfg.LocalOnly {
+		return []string{"local"}
+	}
+	return []string{cfg.DefaultProvider, "huggingface", "openai", "anthropic", "local"}
+}
+
+// NewGatewayFromConfig builds the provider registry, selector and gateway
+// described by cfg, with circuit breaking and hedging as configured.
+// Caching and budgeting are layered on by the caller.
+func NewGatewayFromConfig(cfg config.AIConfig, logger *slog.Logger) (*DefaultGateway, error) {
+	registry, err := BuildRegistryFromConfig(ProviderConfigsFromConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("build AI registry: %w", err)
+	}
+	selector := NewSelector(registry, FallbackChainFromConfig(cfg), logger)
+
+	r := cfg.Resilience
+	selector.SetBreakerConfig(BreakerConfig{
+		Window:      r.BreakerWindow,
+		MinRequests: r.BreakerMinRequests,
+		ErrorRate:   r.BreakerErrorRate,
+		SlowCall:    r.BreakerSlowCall,
+		OpenFor:     r.BreakerOpenFor,
+	})
+	for _, useCase := range r.HedgeUseCases {
+		if useCase = strings.TrimSpace(useCase); useCase != "" {
+			selector.SetHedging(useCase, r.HedgeDelay)
+		}
+	}
+	for useCase, provider := range cfg.UseCaseProviders {
+		selector.SetUseCasePreference(useCase, provider)
+	}
+
+	return NewDefaultGateway(selector, logger), nil
+}
@@ -48,6 +48,14 @@ type Provider interface {
 	IsAvailable(ctx context.Context) bool
 }
 
+// StreamingProvider is a Provider that can deliver a completion as it is
+// generated. onChunk receives each piece of text in order; returning an
+// error from it aborts the completion with that error.
+type StreamingProvider interface {
+	Provider
+	CompleteStream(ctx context.Context, prompt string, opts CompletionOptions, onChunk func(chunk string) error) (*CompletionResult, error)
+}
+
 // =============================================================================
 // PII Detection Types
 // =============================================================================
@@ -74,6 +82,7 @@ type PIIDetectionResult struct {
 	Reasoning      string                 `json:"reasoning"`
 	RequiresReview bool                   `json:"requires_review"`
 	Provider       string                 `json:"provider"`
+	PromptVersion  string                 `json:"prompt_version,omitempty"` // "<use case>@<version>" of the prompt used
 	TokensUsed     int                    `json:"tokens_used"`
 	Duration       time.Duration          `json:"duration"`
 }
@@ -113,16 +122,24 @@ type CompletionOptions struct {
 	Temperature  float64       `json:"temperature,omitempty"`
 	CacheTTL     time.Duration `json:"cache_ttl,omitempty"`
 	SystemPrompt string        `json:"system_prompt,omitempty"` // Overrides default system prompt
+
+	// ResponseSchema constrains the answer on providers with a native
+	// structured-output mode; see CompleteJSON.
+	ResponseSchema *ResponseSchema `json:"response_schema,omitempty"`
 }
 
 // CompletionResult holds the raw LLM response with metadata.
+// InputTokens and OutputTokens are zero when the provider reports only a
+// total.
 type CompletionResult struct {
-	Response   string        `json:"response"`
-	Provider   string        `json:"provider"`
-	Model      string        `json:"model"`
-	TokensUsed int           `json:"tokens_used"`
-	Duration   time.Duration `json:"duration"`
-	Cached     bool          `json:"cached"`
+	Response     string        `json:"response"`
+	Provider     string        `json:"provider"`
+	Model        string        `json:"model"`
+	TokensUsed   int           `json:"tokens_used"`
+	InputTokens  int           `json:"input_tokens,omitempty"`
+	OutputTokens int           `json:"output_tokens,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	Cached       bool          `json:"cached"`
 }
 
 // =============================================================================
@@ -136,13 +153,14 @@ const (
 	ProviderTypeOpenAICompatible ProviderType = "openai_compatible"
 	ProviderTypeAnthropic        ProviderType = "anthropic"
 	ProviderTypeGenericHTTP      ProviderType = "generic_http"
+	ProviderTypeLocal            ProviderType = "local" // Ollama or llama.cpp server, native API
 )
 
 // ProviderConfig holds the configuration for a single AI provider.
 type ProviderConfig struct {
 	// Core identity
 	Name string       `json:"name"`
-	Type ProviderType `json:"type"` // "openai_compatible", "anthropic", "generic_http"
+	Type ProviderType `json:"type"` // "openai_compatible", "anthropic", "generic_http", "local"
 
 	// Connection
 	APIKey   string `json:"api_key,omitempty"`
@@ -156,12 +174,19 @@ type ProviderConfig struct {
 	RequestsPerMinute int `json:"requests_per_minute"`
 	TokensPerMinute   int `json:"tokens_per_minute"`
 
-	// Cost
-	CostPer1KTokens float64 `json:"cost_per_1k_tokens"`
+	// Cost — input/output prices take precedence over the blended price
+	// when the provider reports the split.
+	CostPer1KTokens       float64 `json:"cost_per_1k_tokens"`
+	CostPer1KInputTokens  float64 `json:"cost_per_1k_input_tokens,omitempty"`
+	CostPer1KOutputTokens float64 `json:"cost_per_1k_output_tokens,omitempty"`
 
 	// Timeouts
 	Timeout time.Duration `json:"timeout"`
 
+	// SlowCall overrides the circuit breaker's slow-call threshold for
+	// this provider, e.g. for CPU-only inference. 0 keeps the selector's.
+	SlowCall time.Duration `json:"slow_call,omitempty"`
+
 	// Headers — additional HTTP headers (e.g., {"x-api-key": "..."})
 	// Useful for custom auth schemes or Hugging Face tokens.
 	Headers map[string]string `json:"headers,omitempty"`
@@ -175,6 +200,26 @@ type ProviderConfig struct {
 
 	// Anthropic-specific
 	AnthropicVersion string `json:"anthropic_version,omitempty"` // e.g., "2023-06-01"
+
+	// Local-specific — only used when Type = "local"
+	LocalBackend   string `json:"local_backend,omitempty"`   // "ollama" (default) or "llamacpp"
+	ContextWindow  int    `json:"context_window,omitempty"`  // Max tokens per request, capped by the model's window
+	MaxConcurrency int    `json:"max_concurrency,omitempty"` // Requests generated at once (default 1)
+	MaxQueue       int    `json:"max_queue,omitempty"`       // Requests waiting for a slot before reporting busy
+	PullModel      bool   `json:"pull_model,omitempty"`      // Pull missing models (Ollama)
+
+	// NativeJSONSchema sends CompletionOptions.ResponseSchema in the API's
+	// own structured-output form (OpenAI response_format, Anthropic tool
+	// use). Leave unset for OpenAI-compatible servers that lack it.
+	NativeJSONSchema bool `json:"native_json_schema,omitempty"`
+}
+
+// Cost returns the price of a call from its token counts.
+func (c ProviderConfig) Cost(inputTokens, outputTokens, totalTokens int) float64 {
+	if (c.CostPer1KInputTokens > 0 || c.CostPer1KOutputTokens > 0) && inputTokens+outputTokens > 0 {
+		return (float64(inputTokens)*c.CostPer1KInputTokens + float64(outputTokens)*c.CostPer1KOutputTokens) / 1000
+	}
+	return float64(totalTokens) * c.CostPer1KTokens / 1000
 }
 
 // GatewayConfig holds the overall AI Gateway configuration.
@@ -0,0 +1,343 @@
+package ai
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// =============================================================================
+// Provider Health — Circuit breakers
+// =============================================================================
+//
+// The Selector keeps a circuit breaker per provider. Every call's outcome
+// and latency go into a rolling window; once enough calls in the window
+// failed or were slow, the breaker opens and the provider is skipped
+// without being called. After a cool-down a limited number of probe calls
+// are let through (half-open); a successful probe closes the breaker, a
+// failed one reopens it.
+
+// CircuitState is the state of a provider's circuit breaker.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "CLOSED"    // Calls flow normally
+	CircuitOpen     CircuitState = "OPEN"      // Calls are rejected
+	CircuitHalfOpen CircuitState = "HALF_OPEN" // Probe calls test recovery
+)
+
+// BreakerConfig tunes the per-provider circuit breakers.
+type BreakerConfig struct {
+	Window         time.Duration // Rolling window for error and latency rates
+	MinRequests    int           // Calls in the window before the breaker may trip
+	ErrorRate      float64       // Failure share (errors and slow calls) that trips it
+	SlowCall       time.Duration // Calls slower than this count as failures; 0 disables
+	OpenFor        time.Duration // How long an open breaker rejects calls before probing
+	HalfOpenProbes int           // Concurrent probe calls allowed while half-open
+}
+
+// DefaultBreakerConfig returns the breaker settings used when none are set.
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		Window:         time.Minute,
+		MinRequests:    5,
+		ErrorRate:      0.5,
+		SlowCall:       20 * time.Second,
+		OpenFor:        30 * time.Second,
+		HalfOpenProbes: 1,
+	}
+}
+
+// withDefaults fills unset fields from DefaultBreakerConfig.
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	d := DefaultBreakerConfig()
+	if c.Window <= 0 {
+		c.Window = d.Window
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = d.MinRequests
+	}
+	if c.ErrorRate <= 0 || c.ErrorRate > 1 {
+		c.ErrorRate = d.ErrorRate
+	}
+	if c.OpenFor <= 0 {
+		c.OpenFor = d.OpenFor
+	}
+	if c.HalfOpenProbes <= 0 {
+		c.HalfOpenProbes = d.HalfOpenProbes
+	}
+	return c
+}
+
+// ProviderHealth is a snapshot of one provider's circuit breaker.
+type ProviderHealth struct {
+	Provider   string        `json:"provider"`
+	State      CircuitState  `json:"state"`
+	Requests   int           `json:"requests"` // In the current window
+	Failures   int           `json:"failures"` // Errors and slow calls in the window
+	ErrorRate  float64       `json:"error_rate"`
+	AvgLatency time.Duration `json:"avg_latency"`
+	P95Latency time.Duration `json:"p95_latency"`
+	OpenedAt   *time.Time    `json:"opened_at,omitempty"`
+	LastError  string        `json:"last_error,omitempty"`
+}
+
+var (
+	providerRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "datalens_ai_provider_requests_total",
+		Help: "AI provider calls by outcome (success, error, slow, rejected).",
+	}, []string{"provider", "outcome"})
+
+	providerLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "datalens_ai_provider_latency_seconds",
+		Help:    "Latency of AI provider calls.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 20, 30, 60},
+	}, []string{"provider"})
+
+	providerCircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "datalens_ai_provider_circuit_state",
+		Help: "AI provider circuit breaker state (0 closed, 1 half-open, 2 open).",
+	}, []string{"provider"})
+
+	hedgedRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "datalens_ai_hedged_requests_total",
+		Help: "AI requests hedged to a second provider, by use case and winner.",
+	}, []string{"use_case", "winner"})
+)
+
+// callSample is one call's outcome in a breaker's window.
+type callSample struct {
+	at      time.Time
+	failed  bool
+	latency time.Duration
+}
+
+// circuitBreaker tracks one provider's health. Safe for concurrent use.
+type circuitBreaker struct {
+	provider string
+	cfg      BreakerConfig
+
+	mu        sync.Mutex
+	state     CircuitState
+	samples   []callSample
+	openedAt  time.Time
+	probes    int // Probe calls in flight while half-open
+	lastError string
+}
+
+func newCircuitBreaker(provider string, cfg BreakerConfig) *circuitBreaker {
+	b := &circuitBreaker{provider: provider, cfg: cfg, state: CircuitClosed}
+	providerCircuitState.WithLabelValues(provider).Set(0)
+	return b
+}
+
+// allow reports whether a call may be made now. While half-open it admits
+// up to HalfOpenProbes calls; each admitted call must be followed by record.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.cfg.OpenFor {
+		b.setState(CircuitHalfOpen)
+		b.probes = 0
+	}
+
+	switch b.state {
+	case CircuitOpen:
+		providerRequestsTotal.WithLabelValues(b.provider, "rejected").Inc()
+		return false
+	case CircuitHalfOpen:
+		if b.probes >= b.cfg.HalfOpenProbes {
+			providerRequestsTotal.WithLabelValues(b.provider, "rejected").Inc()
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+// record adds a call's outcome. A nil err with a latency past SlowCall
+// still counts as a failure.
+func (b *circuitBreaker) record(now time.Time, err error, latency time.Duration) {
+	slow := err == nil && b.cfg.SlowCall > 0 && latency > b.cfg.SlowCall
+	failed := err != nil || slow
+
+	outcome := "success"
+	switch {
+	case err != nil:
+		outcome = "error"
+	case slow:
+		outcome = "slow"
+	}
+	providerRequestsTotal.WithLabelValues(b.provider, outcome).Inc()
+	providerLatency.WithLabelValues(b.provider).Observe(latency.Seconds())
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.lastError = err.Error()
+	}
+
+	if b.state == CircuitHalfOpen {
+		b.probes--
+		if failed {
+			b.trip(now)
+			return
+		}
+		b.samples = b.samples[:0]
+		b.setState(CircuitClosed)
+	}
+
+	b.samples = append(b.samples, callSample{at: now, failed: failed, latency: latency})
+	b.prune(now)
+
+	if b.state == CircuitClosed && len(b.samples) >= b.cfg.MinRequests {
+		failures := 0
+		for _, s := range b.samples {
+			if s.failed {
+				failures++
+			}
+		}
+		if float64(failures)/float64(len(b.samples)) >= b.cfg.ErrorRate {
+			b.trip(now)
+		}
+	}
+}
+
+// release returns a half-open probe slot without recording an outcome, for
+// calls abandoned by the caller.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// trip opens the breaker. Must be called with mu held.
+func (b *circuitBreaker) trip(now time.Time) {
+	b.openedAt = now
+	b.samples = b.samples[:0]
+	b.setState(CircuitOpen)
+}
+
+// setState changes state and updates the gauge. Must be called with mu held.
+func (b *circuitBreaker) setState(state CircuitState) {
+	b.state = state
+	gauge := map[CircuitState]float64{CircuitClosed: 0, CircuitHalfOpen: 1, CircuitOpen: 2}[state]
+	providerCircuitState.WithLabelValues(b.provider).Set(gauge)
+}
+
+// prune drops samples older than the window. Must be called with mu held.
+func (b *circuitBreaker) prune(now time.Time) {
+	cutoff := now.Add(-b.cfg.Window)
+	i := 0
+	for i < len(b.samples) && b.samples[i].at.Before(cutoff) {
+		i++
+	}
+	b.samples = b.samples[i:]
+}
+
+// snapshot returns the breaker's current health.
+func (b *circuitBreaker) snapshot(now time.Time) ProviderHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prune(now)
+
+	h := ProviderHealth{
+		Provider:  b.provider,
+		State:     b.state,
+		Requests:  len(b.samples),
+		LastError: b.lastError,
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		h.OpenedAt = &openedAt
+	}
+	if len(b.samples) == 0 {
+		return h
+	}
+
+	latencies := make([]time.Duration, len(b.samples))
+	var total time.Duration
+	for i, s := range b.samples {
+		if s.failed {
+			h.Failures++
+		}
+		latencies[i] = s.latency
+		total += s.latency
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	h.ErrorRate = float64(h.Failures) / float64(len(b.samples))
+	h.AvgLatency = total / time.Duration(len(b.samples))
+	h.P95Latency = latencies[(len(latencies)*95-1)/100]
+	return h
+}
+
+// SetBreakerConfig replaces the circuit breaker settings. Existing breakers
+// are reset. Unset fields take their defaults.
+func (s *Selector) SetBreakerConfig(cfg BreakerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breakerCfg = cfg.withDefaults()
+	s.breakers = make(map[string]*circuitBreaker)
+}
+
+// SetHedging enables request hedging for a latency-sensitive use case: when
+// the first provider has not answered within delay, the next provider in the
+// chain is called too and the first successful answer wins.
+func (s *Selector) SetHedging(useCase string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if delay <= 0 {
+		delete(s.hedgeDelays, useCase)
+		return
+	}
+	s.hedgeDelays[useCase] = delay
+}
+
+// Health returns the health of every provider in the fallback chain,
+// in chain order.
+func (s *Selector) Health() []ProviderHealth {
+	now := time.Now()
+	var out []ProviderHealth
+	for _, name := range s.buildChain("") {
+		if s.registry.Get(name) == nil {
+			continue
+		}
+		out = append(out, s.breaker(name).snapshot(now))
+	}
+	return out
+}
+
+// ProviderHealth returns the health of every provider the gateway may use.
+func (g *DefaultGateway) ProviderHealth() []ProviderHealth {
+	return g.selector.Health()
+}
+
+// breaker returns the provider's circuit breaker, creating it on first use.
+func (s *Selector) breaker(name string) *circuitBreaker {
+	s.mu.RLock()
+	b, ok := s.breakers[name]
+	s.mu.RUnlock()
+	if ok {
+		return b
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.breakers[name]; ok {
+		return b
+	}
+	cfg := s.breakerCfg
+	if pc, ok := s.registry.GetConfig(name); ok && pc.SlowCall > 0 {
+		cfg.SlowCall = pc.SlowCall
+	}
+	b = newCircuitBreaker(name, cfg)
+	s.breakers[name] = b
+	return b
+}
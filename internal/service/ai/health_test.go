@@ -0,0 +1,180 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// scriptedProvider answers after delay, failing while fail is set.
+type scriptedProvider struct {
+	name  string
+	delay time.Duration
+	fail  atomic.Bool
+	calls atomic.Int32
+}
+
+func (p *scriptedProvider) Name() string                       { return p.name }
+func (p *scriptedProvider) IsAvailable(_ context.Context) bool { return true }
+
+func (p *scriptedProvider) Complete(ctx context.Context, _ string, _ CompletionOptions) (*CompletionResult, error) {
+	p.calls.Add(1)
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if p.fail.Load() {
+		return nil, errors.New(p.name + ": upstream timeout")
+	}
+	return &CompletionResult{Response: "from " + p.name, Provider: p.name}, nil
+}
+
+func newHealthTestSelector(providers ...*scriptedProvider) *Selector {
+	reg := NewRegistry()
+	var chain []string
+	for _, p := range providers {
+		reg.Register(p.name, p, ProviderConfig{})
+		chain = append(chain, p.name)
+	}
+	return NewSelector(reg, chain, nil)
+}
+
+func TestSelector_CircuitBreaker_OpensAndRecovers(t *testing.T) {
+	primary := &scriptedProvider{name: "primary"}
+	backup := &scriptedProvider{name: "backup"}
+	primary.fail.Store(true)
+
+	sel := newHealthTestSelector(primary, backup)
+	sel.SetBreakerConfig(BreakerConfig{MinRequests: 3, ErrorRate: 0.5, OpenFor: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := sel.CompleteWithFallback(ctx, "test", CompletionOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := primary.calls.Load(); got != 3 {
+		t.Fatalf("primary calls before tripping: got %d, want 3", got)
+	}
+	if state := sel.Health()[0].State; state != CircuitOpen {
+		t.Fatalf("primary state: got %s, want %s", state, CircuitOpen)
+	}
+
+	// While open the primary is skipped without being called.
+	result, err := sel.CompleteWithFallback(ctx, "test", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "backup" || primary.calls.Load() != 3 {
+		t.Errorf("open circuit: got provider %q, primary calls %d", result.Provider, primary.calls.Load())
+	}
+
+	// After the cool-down a successful probe closes the circuit.
+	primary.fail.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	result, err = sel.CompleteWithFallback(ctx, "test", CompletionOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "primary" {
+		t.Errorf("probe: got provider %q, want primary", result.Provider)
+	}
+	if state := sel.Health()[0].State; state != CircuitClosed {
+		t.Errorf("primary state after probe: got %s, want %s", state, CircuitClosed)
+	}
+}
+
+func TestSelector_CircuitBreaker_SlowCallsTrip(t *testing.T) {
+	slow := &scriptedProvider{name: "slow", delay: 20 * time.Millisecond}
+	sel := newHealthTestSelector(slow)
+	sel.SetBreakerConfig(BreakerConfig{MinRequests: 2, SlowCall: 5 * time.Millisecond})
+
+	for range 2 {
+		if _, err := sel.CompleteWithFallback(context.Background(), "test", CompletionOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	health := sel.Health()[0]
+	if health.State != CircuitOpen {
+		t.Errorf("state: got %s, want %s", health.State, CircuitOpen)
+	}
+	if _, err := sel.CompleteWithFallback(context.Background(), "test", CompletionOptions{}); err == nil {
+		t.Error("expected an error with every circuit open")
+	}
+}
+
+// refusingProvider turns every call away with err.
+type refusingProvider struct {
+	name string
+	err  error
+}
+
+func (p *refusingProvider) Name() string                       { return p.name }
+func (p *refusingProvider) IsAvailable(_ context.Context) bool { return true }
+func (p *refusingProvider) Complete(context.Context, string, CompletionOptions) (*CompletionResult, error) {
+	return nil, p.err
+}
+
+func TestSelector_CircuitBreaker_IgnoresCapacityErrors(t *testing.T) {
+	for _, refusal := range []error{ErrProviderBusy, ErrContextWindowExceeded} {
+		local := &refusingProvider{name: "local", err: fmt.Errorf("local: %w", refusal)}
+		backup := &scriptedProvider{name: "backup"}
+		reg := NewRegistry()
+		reg.Register("local", local, ProviderConfig{})
+		reg.Register("backup", backup, ProviderConfig{})
+		sel := NewSelector(reg, []string{"local", "backup"}, nil)
+		sel.SetBreakerConfig(BreakerConfig{MinRequests: 2, ErrorRate: 0.5, OpenFor: time.Minute})
+
+		for range 5 {
+			result, err := sel.CompleteWithFallback(context.Background(), "test", CompletionOptions{})
+			if err != nil || result.Provider != "backup" {
+				t.Fatalf("%v: got %v, %v; want the backup's answer", refusal, result, err)
+			}
+		}
+		if state := sel.Health()[0].State; state != CircuitClosed {
+			t.Errorf("%v: local state: got %s, want %s", refusal, state, CircuitClosed)
+		}
+	}
+}
+
+func TestSelector_Hedging(t *testing.T) {
+	slow := &scriptedProvider{name: "slow", delay: 200 * time.Millisecond}
+	fast := &scriptedProvider{name: "fast", delay: time.Millisecond}
+	sel := newHealthTestSelector(slow, fast)
+	sel.SetHedging("purpose_suggestion", 10*time.Millisecond)
+
+	start := time.Now()
+	result, err := sel.CompleteWithFallback(context.Background(), "test", CompletionOptions{UseCase: "purpose_suggestion"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "fast" {
+		t.Errorf("winner: got %q, want fast", result.Provider)
+	}
+	if elapsed := time.Since(start); elapsed >= 150*time.Millisecond {
+		t.Errorf("hedged call took %s", elapsed)
+	}
+	if slow.calls.Load() != 1 || fast.calls.Load() != 1 {
+		t.Errorf("calls: slow %d, fast %d", slow.calls.Load(), fast.calls.Load())
+	}
+
+	// The cancelled loser is not held against the slow provider.
+	time.Sleep(10 * time.Millisecond)
+	if failures := sel.Health()[0].Failures; failures != 0 {
+		t.Errorf("slow provider failures: got %d, want 0", failures)
+	}
+
+	// Use cases without hedging stay sequential.
+	result, err = sel.CompleteWithFallback(context.Background(), "test", CompletionOptions{UseCase: "pii_detection"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "slow" {
+		t.Errorf("unhedged: got %q, want slow", result.Provider)
+	}
+}
@@ -0,0 +1,690 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// Local Provider — Ollama / llama.cpp server
+// =============================================================================
+//
+// LocalProvider runs completions on a model served from the deployment's
+// own hardware, for tenants whose data must not reach a cloud LLM. Unlike
+// the OpenAI-compatible provider pointed at Ollama, it speaks the server's
+// native API to:
+//
+//   - report available only once the model is present and loaded, pulling
+//     a missing model from the Ollama library if configured
+//   - learn the model's context window, run every request with that window
+//     and trim max tokens to the room the prompt leaves
+//   - stream completions, so slow CPU generation is delivered as it goes
+//   - bound concurrent generations and the queue behind them, since each
+//     extra request slows CPU inference down for every other one
+//
+// Configuration example:
+//
+//	ProviderConfig{
+//	    Name:           "local",
+//	    Type:           ProviderTypeLocal,
+//	    LocalBackend:   "ollama", // or "llamacpp"
+//	    Endpoint:       "http://localhost:11434",
+//	    DefaultModel:   "llama3.2",
+//	    ContextWindow:  8192,
+//	    MaxConcurrency: 1,
+//	    MaxQueue:       8,
+//	    PullModel:      true,
+//	    Timeout:        5 * time.Minute,
+//	}
+
+var (
+	// ErrContextWindowExceeded is returned when a prompt leaves no room for
+	// an answer in the model's context window.
+	ErrContextWindowExceeded = errors.New("ai: prompt exceeds the model context window")
+
+	// ErrProviderBusy is returned when a provider's request queue is full.
+	ErrProviderBusy = errors.New("ai: provider busy")
+)
+
+const (
+	localHealthTTL          = 30 * time.Second
+	localDefaultContext     = 4096 // When neither the config nor the server gives a window
+	localDefaultMaxQueue    = 8
+	localDefaultTimeout     = 5 * time.Minute
+	localMinOutputTokens    = 256 // Smallest answer worth generating
+	localChatOverheadTokens = 32  // Chat template around the system and user messages
+	localPullTimeout        = 30 * time.Minute
+)
+
+// LocalProvider implements StreamingProvider for a local inference server.
+type LocalProvider struct {
+	config  ProviderConfig
+	backend localBackend
+	timeout time.Duration
+
+	slots      chan struct{} // One per concurrent generation
+	maxPending int32         // Generating plus queued
+	pending    atomic.Int32
+
+	checkMu   sync.Mutex // Serializes readiness checks
+	mu        sync.Mutex
+	ready     map[string]bool // Model → present and loadable, as of checkedAt
+	checkedAt time.Time
+	windows   map[string]int // Model → its own maximum context, 0 if unknown
+	pulling   map[string]bool
+}
+
+// NewLocalProvider creates a provider for an Ollama or llama.cpp server.
+func NewLocalProvider(cfg ProviderConfig) (*LocalProvider, error) {
+	// Requests are bounded by a context deadline rather than the client,
+	// which would cut off long streams
+	client := &http.Client{}
+	endpoint := strings.TrimSuffix(strings.TrimRight(cfg.Endpoint, "/"), "/v1")
+
+	var backend localBackend
+	switch cfg.LocalBackend {
+	case "", "ollama":
+		backend = &ollamaBackend{endpoint: endpoint, client: client}
+	case "llamacpp":
+		backend = &llamaCppBackend{endpoint: endpoint, client: client}
+	default:
+		return nil, fmt.Errorf("unknown local backend %q (use ollama or llamacpp)", cfg.LocalBackend)
+	}
+
+	concurrency := cfg.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	queue := cfg.MaxQueue
+	if queue <= 0 {
+		queue = localDefaultMaxQueue
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = localDefaultTimeout
+	}
+
+	return &LocalProvider{
+		config:     cfg,
+		backend:    backend,
+		timeout:    timeout,
+		slots:      make(chan struct{}, concurrency),
+		maxPending: int32(concurrency + queue),
+		ready:      make(map[string]bool),
+		windows:    make(map[string]int),
+		pulling:    make(map[string]bool),
+	}, nil
+}
+
+func (p *LocalProvider) Name() string { return p.config.Name }
+
+// IsAvailable reports whether the default model is ready and the request
+// queue has room. A missing model is pulled in the background if
+// configured; the provider becomes available once the pull completes.
+func (p *LocalProvider) IsAvailable(ctx context.Context) bool {
+	if p.pending.Load() >= p.maxPending {
+		return false
+	}
+	p.refresh(ctx)
+	return p.isReady(p.config.DefaultModel)
+}
+
+// Complete runs a completion, streamed from the server.
+func (p *LocalProvider) Complete(ctx context.Context, prompt string, opts CompletionOptions) (*CompletionResult, error) {
+	return p.CompleteStream(ctx, prompt, opts, nil)
+}
+
+// CompleteStream runs a completion, passing each piece of text to onChunk
+// as it is generated. The request waits for a free generation slot; the
+// provider's timeout covers the wait and the generation.
+func (p *LocalProvider) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions, onChunk func(chunk string) error) (*CompletionResult, error) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	release, err := p.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.config.Name, err)
+	}
+	defer release()
+
+	model := p.config.DefaultModel
+	if opts.Priority == "speed" && p.config.FastModel != "" && p.isReady(p.config.FastModel) {
+		model = p.config.FastModel
+	}
+
+	systemPrompt := opts.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = defaultSystemPrompt
+	}
+	maxTokens := opts.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 1024
+	}
+	temp := opts.Temperature
+	if temp == 0 {
+		temp = 0.1 // Low temperature for structured JSON output
+	}
+
+	window := p.contextWindow(ctx, model)
+	inputTokens := estimateTokens(systemPrompt) + estimateTokens(prompt) + localChatOverheadTokens
+	maxTokens, err = fitContext(window, inputTokens, maxTokens)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w (about %d prompt tokens, %d-token window)", p.config.Name, err, inputTokens, window)
+	}
+
+	req := localRequest{
+		Model:       model,
+		System:      systemPrompt,
+		Prompt:      prompt,
+		MaxTokens:   maxTokens,
+		NumCtx:      window,
+		Temperature: temp,
+	}
+	if p.config.NativeJSONSchema {
+		req.Schema = opts.ResponseSchema
+	}
+
+	var response strings.Builder
+	usage, err := p.backend.stream(ctx, req, func(chunk string) error {
+		response.WriteString(chunk)
+		if onChunk != nil {
+			return onChunk(chunk)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p.config.Name, err)
+	}
+
+	return &CompletionResult{
+		Response:     response.String(),
+		Provider:     p.config.Name,
+		Model:        model,
+		TokensUsed:   usage.InputTokens + usage.OutputTokens,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		Duration:     time.Since(start),
+	}, nil
+}
+
+// acquire takes a generation slot, waiting in the queue if needed. It
+// fails fast with ErrProviderBusy when the queue is full.
+func (p *LocalProvider) acquire(ctx context.Context) (func(), error) {
+	if p.pending.Add(1) > p.maxPending {
+		p.pending.Add(-1)
+		return nil, ErrProviderBusy
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return func() {
+			<-p.slots
+			p.pending.Add(-1)
+		}, nil
+	case <-ctx.Done():
+		p.pending.Add(-1)
+		return nil, ctx.Err()
+	}
+}
+
+// fitContext trims maxTokens to the room the prompt leaves in the window,
+// failing if there is no room for a useful answer.
+func fitContext(window, inputTokens, maxTokens int) (int, error) {
+	room := window - inputTokens
+	if room < min(maxTokens, localMinOutputTokens) {
+		return 0, ErrContextWindowExceeded
+	}
+	return min(maxTokens, room), nil
+}
+
+// contextWindow returns the window to run a model with: the configured
+// window, capped by the model's own maximum when the server reports it.
+func (p *LocalProvider) contextWindow(ctx context.Context, model string) int {
+	p.mu.Lock()
+	modelMax, known := p.windows[model]
+	p.mu.Unlock()
+
+	if !known {
+		var err error
+		if modelMax, err = p.backend.contextWindow(ctx, model); err == nil {
+			p.mu.Lock()
+			p.windows[model] = modelMax
+			p.mu.Unlock()
+		}
+	}
+
+	window := p.config.ContextWindow
+	if modelMax > 0 && (window == 0 || modelMax < window) {
+		window = modelMax
+	}
+	if window == 0 {
+		window = localDefaultContext
+	}
+	return window
+}
+
+func (p *LocalProvider) isReady(model string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.ready[model]
+}
+
+// refresh re-checks which models are ready if the last check is stale,
+// and starts pulling missing ones if configured.
+func (p *LocalProvider) refresh(ctx context.Context) {
+	p.checkMu.Lock()
+	defer p.checkMu.Unlock()
+
+	p.mu.Lock()
+	fresh := time.Since(p.checkedAt) < localHealthTTL
+	p.mu.Unlock()
+	if fresh {
+		return
+	}
+
+	models := []string{p.config.DefaultModel}
+	if p.config.FastModel != "" && p.config.FastModel != p.config.DefaultModel {
+		models = append(models, p.config.FastModel)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	ready, err := p.backend.ready(checkCtx, models)
+	if err != nil {
+		ready = map[string]bool{} // Unreachable
+	}
+
+	p.mu.Lock()
+	p.ready = ready
+	p.checkedAt = time.Now()
+	p.mu.Unlock()
+
+	if err != nil || !p.config.PullModel {
+		return
+	}
+	for _, m := range models {
+		if !ready[m] {
+			p.startPull(m)
+		}
+	}
+}
+
+// startPull pulls a model in the background, once at a time per model.
+func (p *LocalProvider) startPull(model string) {
+	p.mu.Lock()
+	if p.pulling[model] {
+		p.mu.Unlock()
+		return
+	}
+	p.pulling[model] = true
+	p.mu.Unlock()
+
+	logger := slog.Default().With("provider", p.config.Name, "model", model)
+	logger.Info("pulling local model")
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), localPullTimeout)
+		defer cancel()
+		err := p.backend.pull(ctx, model)
+
+		p.mu.Lock()
+		delete(p.pulling, model)
+		if err == nil {
+			p.checkedAt = time.Time{} // Re-check on next use
+		}
+		p.mu.Unlock()
+
+		if err != nil {
+			logger.Error("local model pull failed", "error", err)
+			return
+		}
+		logger.Info("local model pulled")
+	}()
+}
+
+// =============================================================================
+// Backends
+// =============================================================================
+
+// localBackend is the native API of a local inference server.
+type localBackend interface {
+	// ready reports which of models the server can run now.
+	ready(ctx context.Context, models []string) (map[string]bool, error)
+	// pull fetches a model onto the server.
+	pull(ctx context.Context, model string) error
+	// contextWindow returns the model's maximum context, 0 if unknown.
+	contextWindow(ctx context.Context, model string) (int, error)
+	// stream runs a chat completion, passing each piece of text to onChunk.
+	stream(ctx context.Context, req localRequest, onChunk func(string) error) (localUsage, error)
+}
+
+type localRequest struct {
+	Model       string
+	System      string
+	Prompt      string
+	MaxTokens   int
+	NumCtx      int
+	Temperature float64
+	Schema      *ResponseSchema // Constrains decoding when set
+}
+
+type localUsage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// --- Ollama (https://github.com/ollama/ollama/blob/main/docs/api.md) ---
+
+type ollamaBackend struct {
+	endpoint string
+	client   *http.Client
+}
+
+// ollamaTag normalizes a model name to name:tag, as /api/tags lists it.
+func ollamaTag(model string) string {
+	if strings.Contains(model, ":") {
+		return model
+	}
+	return model + ":latest"
+}
+
+func (b *ollamaBackend) ready(ctx context.Context, models []string) (map[string]bool, error) {
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := localJSON(ctx, b.client, http.MethodGet, b.endpoint+"/api/tags", nil, &tags); err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool, len(tags.Models))
+	for _, m := range tags.Models {
+		present[ollamaTag(m.Name)] = true
+	}
+	ready := make(map[string]bool, len(models))
+	for _, m := range models {
+		ready[m] = present[ollamaTag(m)]
+	}
+	return ready, nil
+}
+
+func (b *ollamaBackend) pull(ctx context.Context, model string) error {
+	return localJSON(ctx, b.client, http.MethodPost, b.endpoint+"/api/pull",
+		map[string]any{"model": model, "stream": false}, nil)
+}
+
+func (b *ollamaBackend) contextWindow(ctx context.Context, model string) (int, error) {
+	var show struct {
+		ModelInfo map[string]any `json:"model_info"`
+	}
+	if err := localJSON(ctx, b.client, http.MethodPost, b.endpoint+"/api/show", map[string]any{"model": model}, &show); err != nil {
+		return 0, err
+	}
+	arch, _ := show.ModelInfo["general.architecture"].(string)
+	n, _ := show.ModelInfo[arch+".context_length"].(float64)
+	return int(n), nil
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   map[string]any  `json:"format,omitempty"` // JSON schema
+	Options  ollamaOptions   `json:"options"`
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict"`
+	NumCtx      int     `json:"num_ctx"` // Ollama's own default truncates long prompts silently
+}
+
+type ollamaChatChunk struct {
+	Message         openAIMessage `json:"message"`
+	Done            bool          `json:"done"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+func (b *ollamaBackend) stream(ctx context.Context, req localRequest, onChunk func(string) error) (localUsage, error) {
+	body := ollamaChatRequest{
+		Model: req.Model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: req.System},
+			{Role: "user", Content: req.Prompt},
+		},
+		Stream:  true,
+		Options: ollamaOptions{Temperature: req.Temperature, NumPredict: req.MaxTokens, NumCtx: req.NumCtx},
+	}
+	if req.Schema != nil {
+		body.Format = req.Schema.Schema
+	}
+
+	var usage localUsage
+	err := localStream(ctx, b.client, b.endpoint+"/api/chat", body, func(line []byte) (bool, error) {
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return false, fmt.Errorf("decode stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return false, errors.New(chunk.Error)
+		}
+		if chunk.Message.Content != "" {
+			if err := onChunk(chunk.Message.Content); err != nil {
+				return false, err
+			}
+		}
+		if chunk.Done {
+			usage = localUsage{InputTokens: chunk.PromptEvalCount, OutputTokens: chunk.EvalCount}
+		}
+		return chunk.Done, nil
+	})
+	return usage, err
+}
+
+// --- llama.cpp server (https://github.com/ggml-org/llama.cpp/tree/master/tools/server) ---
+
+// llamaCppBackend serves the one model the server was started with, under
+// any name; its context window is fixed at startup (-c).
+type llamaCppBackend struct {
+	endpoint string
+	client   *http.Client
+}
+
+func (b *llamaCppBackend) ready(ctx context.Context, models []string) (map[string]bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoint+"/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	loaded := resp.StatusCode == http.StatusOK // 503 while the model loads
+	ready := make(map[string]bool, len(models))
+	for _, m := range models {
+		ready[m] = loaded
+	}
+	return ready, nil
+}
+
+func (b *llamaCppBackend) pull(context.Context, string) error {
+	return errors.New("llama.cpp serves the model it was started with; pull it onto the host")
+}
+
+func (b *llamaCppBackend) contextWindow(ctx context.Context, _ string) (int, error) {
+	var props struct {
+		DefaultGenerationSettings struct {
+			NCtx int `json:"n_ctx"`
+		} `json:"default_generation_settings"`
+	}
+	if err := localJSON(ctx, b.client, http.MethodGet, b.endpoint+"/props", nil, &props); err != nil {
+		return 0, err
+	}
+	return props.DefaultGenerationSettings.NCtx, nil
+}
+
+type llamaCppChatRequest struct {
+	openAIRequest
+	Stream        bool                  `json:"stream"`
+	StreamOptions *llamaCppStreamOption `json:"stream_options,omitempty"`
+}
+
+type llamaCppStreamOption struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type llamaCppChunk struct {
+	Choices []struct {
+		Delta openAIMessage `json:"delta"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+func (b *llamaCppBackend) stream(ctx context.Context, req localRequest, onChunk func(string) error) (localUsage, error) {
+	body := llamaCppChatRequest{
+		openAIRequest: openAIRequest{
+			Model: req.Model,
+			Messages: []openAIMessage{
+				{Role: "system", Content: req.System},
+				{Role: "user", Content: req.Prompt},
+			},
+			MaxTokens:   req.MaxTokens,
+			Temperature: req.Temperature,
+		},
+		Stream:        true,
+		StreamOptions: &llamaCppStreamOption{IncludeUsage: true},
+	}
+	if req.Schema != nil {
+		body.ResponseFormat = &openAIResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &openAIJSONSchema{Name: req.Schema.Name, Schema: req.Schema.Schema},
+		}
+	}
+
+	var usage localUsage
+	err := localStream(ctx, b.client, b.endpoint+"/v1/chat/completions", body, func(line []byte) (bool, error) {
+		data, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			return false, nil // SSE comments and blank lines
+		}
+		data = bytes.TrimSpace(data)
+		if string(data) == "[DONE]" {
+			return true, nil
+		}
+		var chunk llamaCppChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return false, fmt.Errorf("decode stream: %w", err)
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content != "" {
+				if err := onChunk(c.Delta.Content); err != nil {
+					return false, err
+				}
+			}
+		}
+		if chunk.Usage != nil {
+			usage = localUsage{InputTokens: chunk.Usage.PromptTokens, OutputTokens: chunk.Usage.CompletionTokens}
+		}
+		return false, nil
+	})
+	return usage, err
+}
+
+// --- HTTP helpers ---
+
+// localJSON makes a JSON request and decodes the response into out, if set.
+func localJSON(ctx context.Context, client *http.Client, method, url string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// localStream posts a streaming request and passes each non-empty line of
+// the response to onLine until it reports done or the stream ends.
+func localStream(ctx context.Context, client *http.Client, url string, in any, onLine func(line []byte) (done bool, err error)) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxResponseBytes)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		done, err := onLine(line)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", err)
+	}
+	return errors.New("stream ended before completion")
+}
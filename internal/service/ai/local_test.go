@@ -0,0 +1,357 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/complyark/datalens/internal/config"
+)
+
+// fakeOllama emulates the Ollama API: a model list, pulls that add to it,
+// model info with a context length, and a streamed chat.
+type fakeOllama struct {
+	mu      sync.Mutex
+	models  []string
+	pulls   int
+	lastReq ollamaChatRequest
+	release chan struct{} // If set, chats wait for it before answering
+	started chan struct{} // If set, signalled as each chat starts
+}
+
+func (f *fakeOllama) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/tags":
+		f.mu.Lock()
+		var models []map[string]string
+		for _, m := range f.models {
+			models = append(models, map[string]string{"name": m})
+		}
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"models": models})
+
+	case "/api/pull":
+		var req struct{ Model string }
+		json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.pulls++
+		f.models = append(f.models, ollamaTag(req.Model))
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+
+	case "/api/show":
+		json.NewEncoder(w).Encode(map[string]any{"model_info": map[string]any{
+			"general.architecture": "llama",
+			"llama.context_length": 4096,
+		}})
+
+	case "/api/chat":
+		var req ollamaChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.lastReq = req
+		f.mu.Unlock()
+		if f.started != nil {
+			f.started <- struct{}{}
+		}
+		if f.release != nil {
+			<-f.release
+		}
+		enc := json.NewEncoder(w)
+		for _, piece := range []string{`{"is_pii":`, ` true}`} {
+			enc.Encode(ollamaChatChunk{Message: openAIMessage{Role: "assistant", Content: piece}})
+			w.(http.Flusher).Flush()
+		}
+		enc.Encode(ollamaChatChunk{Done: true, PromptEvalCount: 40, EvalCount: 6})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeOllama) request() ollamaChatRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastReq
+}
+
+func newTestLocalProvider(t *testing.T, cfg ProviderConfig) *LocalProvider {
+	t.Helper()
+	cfg.Name = "local"
+	if cfg.DefaultModel == "" {
+		cfg.DefaultModel = "llama3.2"
+	}
+	p, err := NewLocalProvider(cfg)
+	if err != nil {
+		t.Fatalf("NewLocalProvider: %v", err)
+	}
+	return p
+}
+
+func TestLocalProvider_PullsMissingModel(t *testing.T) {
+	fake := &fakeOllama{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	p := newTestLocalProvider(t, ProviderConfig{Endpoint: server.URL + "/v1", PullModel: true})
+	if p.IsAvailable(context.Background()) {
+		t.Fatal("should be unavailable before the model is pulled")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !p.IsAvailable(context.Background()) {
+		if time.Now().After(deadline) {
+			t.Fatal("should be available once the pull completes")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if fake.pulls != 1 {
+		t.Errorf("pulls: got %d, want 1", fake.pulls)
+	}
+}
+
+func TestLocalProvider_NoPullWhenDisabled(t *testing.T) {
+	fake := &fakeOllama{models: []string{"mistral:latest"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	p := newTestLocalProvider(t, ProviderConfig{Endpoint: server.URL})
+	if p.IsAvailable(context.Background()) {
+		t.Error("should be unavailable without the model")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if fake.pulls != 0 {
+		t.Errorf("pulls: got %d, want 0", fake.pulls)
+	}
+}
+
+func TestLocalProvider_CompleteStream(t *testing.T) {
+	fake := &fakeOllama{models: []string{"llama3.2:latest"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	p := newTestLocalProvider(t, ProviderConfig{Endpoint: server.URL, ContextWindow: 8192, NativeJSONSchema: true})
+
+	var chunks []string
+	result, err := p.CompleteStream(context.Background(), "classify", CompletionOptions{
+		MaxTokens:      512,
+		ResponseSchema: &ResponseSchema{Name: "pii", Schema: map[string]any{"type": "object"}},
+	}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Errorf("chunks: got %d, want 2", len(chunks))
+	}
+	if result.Response != `{"is_pii": true}` {
+		t.Errorf("response: got %q", result.Response)
+	}
+	if result.InputTokens != 40 || result.OutputTokens != 6 || result.TokensUsed != 46 {
+		t.Errorf("usage: got %d in, %d out, %d total", result.InputTokens, result.OutputTokens, result.TokensUsed)
+	}
+
+	req := fake.request()
+	// The model's 4096-token window caps the configured 8192
+	if req.Options.NumCtx != 4096 {
+		t.Errorf("num_ctx: got %d, want 4096", req.Options.NumCtx)
+	}
+	if req.Options.NumPredict != 512 {
+		t.Errorf("num_predict: got %d, want 512", req.Options.NumPredict)
+	}
+	if req.Format["type"] != "object" {
+		t.Errorf("format: got %v, want the response schema", req.Format)
+	}
+}
+
+func TestLocalProvider_ContextWindow(t *testing.T) {
+	fake := &fakeOllama{models: []string{"llama3.2:latest"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	p := newTestLocalProvider(t, ProviderConfig{Endpoint: server.URL, ContextWindow: 2048})
+
+	// ~1500 prompt tokens leave room for fewer than the 1024 asked for
+	_, err := p.Complete(context.Background(), strings.Repeat("word ", 1200), CompletionOptions{MaxTokens: 1024})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fake.request().Options.NumPredict; got >= 1024 || got < localMinOutputTokens {
+		t.Errorf("num_predict: got %d, want trimmed to the room left", got)
+	}
+
+	_, err = p.Complete(context.Background(), strings.Repeat("word ", 2000), CompletionOptions{})
+	if !errors.Is(err, ErrContextWindowExceeded) {
+		t.Errorf("got %v, want ErrContextWindowExceeded", err)
+	}
+}
+
+func TestLocalProvider_ConcurrencyLimit(t *testing.T) {
+	fake := &fakeOllama{
+		models:  []string{"llama3.2:latest"},
+		release: make(chan struct{}),
+		started: make(chan struct{}, 4),
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	p := newTestLocalProvider(t, ProviderConfig{Endpoint: server.URL, MaxConcurrency: 1, MaxQueue: 1})
+	if !p.IsAvailable(context.Background()) {
+		t.Fatal("should be available")
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.Complete(context.Background(), "test", CompletionOptions{})
+			errs <- err
+		}()
+	}
+
+	// One generates, one queues behind it
+	<-fake.started
+	deadline := time.Now().Add(2 * time.Second)
+	for p.pending.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("second request did not queue")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case <-fake.started:
+		t.Fatal("second request should wait for the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if p.IsAvailable(context.Background()) {
+		t.Error("should be unavailable with a full queue")
+	}
+	if _, err := p.Complete(context.Background(), "test", CompletionOptions{}); !errors.Is(err, ErrProviderBusy) {
+		t.Errorf("got %v, want ErrProviderBusy", err)
+	}
+
+	close(fake.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("queued request failed: %v", err)
+		}
+	}
+}
+
+func TestLocalProvider_LlamaCpp(t *testing.T) {
+	loading := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			if loading {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"status":"ok"}`))
+		case "/props":
+			w.Write([]byte(`{"default_generation_settings":{"n_ctx":2048}}`))
+		case "/v1/chat/completions":
+			var req llamaCppChatRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if !req.Stream || req.MaxTokens != 100 {
+				t.Errorf("request: stream %v, max_tokens %d", req.Stream, req.MaxTokens)
+			}
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hel\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":2}}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := newTestLocalProvider(t, ProviderConfig{Endpoint: server.URL, LocalBackend: "llamacpp"})
+	if p.IsAvailable(context.Background()) {
+		t.Error("should be unavailable while the model loads")
+	}
+
+	loading = false
+	p.checkedAt = time.Time{}
+	if !p.IsAvailable(context.Background()) {
+		t.Error("should be available once loaded")
+	}
+
+	result, err := p.Complete(context.Background(), "hi", CompletionOptions{MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Response != "hello" || result.TokensUsed != 14 {
+		t.Errorf("got %q with %d tokens", result.Response, result.TokensUsed)
+	}
+	if w := p.contextWindow(context.Background(), "any"); w != 2048 {
+		t.Errorf("context window: got %d, want the server's 2048", w)
+	}
+}
+
+func TestSelector_CompleteStreamWithFallback(t *testing.T) {
+	// The local server fails before streaming anything; the cloud provider
+	// answers in one chunk.
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"llama3.2:latest"}]}`))
+		case "/api/chat":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer broken.Close()
+	cloud := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(openAIResponse{Choices: []openAIChoice{{Message: openAIMessage{Content: "from cloud"}}}})
+	}))
+	defer cloud.Close()
+
+	reg := NewRegistry()
+	reg.Register("local", newTestLocalProvider(t, ProviderConfig{Endpoint: broken.URL}), ProviderConfig{})
+	reg.Register("cloud", NewOpenAICompatProvider(ProviderConfig{Name: "cloud", APIKey: "key", Endpoint: cloud.URL}), ProviderConfig{})
+	sel := NewSelector(reg, []string{"local", "cloud"}, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+	var chunks []string
+	result, err := sel.CompleteStreamWithFallback(context.Background(), "test", CompletionOptions{}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "cloud" || len(chunks) != 1 || chunks[0] != "from cloud" {
+		t.Errorf("got %q from %s in %d chunks", result.Response, result.Provider, len(chunks))
+	}
+}
+
+func TestProviderConfigsFromConfig_LocalOnly(t *testing.T) {
+	cfg := config.AIConfig{LocalOnly: true}
+	cfg.OpenAI.APIKey = "sk-test"
+	cfg.Anthropic.APIKey = "sk-ant-test"
+
+	configs := ProviderConfigsFromConfig(cfg)
+	if len(configs) != 1 || configs[0].Name != "local" || configs[0].Type != ProviderTypeLocal {
+		t.Errorf("got %+v, want the local provider alone", configs)
+	}
+	if chain := FallbackChainFromConfig(cfg); len(chain) != 1 || chain[0] != "local" {
+		t.Errorf("fallback chain: got %v", chain)
+	}
+}
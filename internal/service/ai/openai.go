@@ -164,6 +164,15 @@ func (p *OpenAICompatProvider) Complete(ctx context.Context, prompt string, opts
 		MaxTokens:   maxTokens,
 		Temperature: temp,
 	}
+	if opts.ResponseSchema != nil && p.config.NativeJSONSchema {
+		reqBody.ResponseFormat = &openAIResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &openAIJSONSchema{
+				Name:   opts.ResponseSchema.Name,
+				Schema: opts.ResponseSchema.Schema,
+			},
+		}
+	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
@@ -218,11 +227,13 @@ func (p *OpenAICompatProvider) Complete(ctx context.Context, prompt string, opts
 	}
 
 	return &CompletionResult{
-		Response:   result.Choices[0].Message.Content,
-		Provider:   p.config.Name,
-		Model:      model,
-		TokensUsed: result.Usage.TotalTokens,
-		Duration:   time.Since(start),
+		Response:     result.Choices[0].Message.Content,
+		Provider:     p.config.Name,
+		Model:        model,
+		TokensUsed:   result.Usage.TotalTokens,
+		InputTokens:  result.Usage.PromptTokens,
+		OutputTokens: result.Usage.CompletionTokens,
+		Duration:     time.Since(start),
 	}, nil
 }
 
@@ -243,10 +254,22 @@ const maxResponseBytes = 1 << 20
 // --- OpenAI wire format (used by all OpenAI-compatible APIs) ---
 
 type openAIRequest struct {
-	Model       string          `json:"model"`
-	Messages    []openAIMessage `json:"messages"`
-	MaxTokens   int             `json:"max_tokens"`
-	Temperature float64         `json:"temperature"`
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	MaxTokens      int                   `json:"max_tokens"`
+	Temperature    float64               `json:"temperature"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"` // "json_schema"
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict"` // Strict mode requires every property; schemas here leave some optional
 }
 
 type openAIMessage struct {
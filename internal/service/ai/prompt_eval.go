@@ -0,0 +1,189 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// Prompt Evaluation — Offline replay before promotion
+// =============================================================================
+//
+// A candidate prompt is checked against a dataset of recorded detections
+// before it is activated. Each case is run through the real gateway
+// pipeline — rendering, sanitization, structured-output validation and
+// repair — with a mock provider that replays the model answer recorded for
+// the case, and the outcome is scored against the verified label. The
+// replay catches templates that fail to render, drop the column's details
+// or no longer fit the answer format; comparing the candidate's report
+// with the active prompt's shows whether promoting it would regress.
+
+// PromptEvalCase is one recorded PII detection: the column, the answer the
+// model gave for it, and the verified label.
+type PromptEvalCase struct {
+	ID       string            `json:"id"`
+	Input    PIIDetectionInput `json:"input"`
+	Response string            `json:"response"` // Recorded model answer
+	Expected PromptEvalLabel   `json:"expected"`
+}
+
+// PromptEvalLabel is the verified classification of a case.
+type PromptEvalLabel struct {
+	IsPII bool          `json:"is_pii"`
+	Type  types.PIIType `json:"type,omitempty"`
+}
+
+// PromptEvalReport scores one prompt over a dataset.
+type PromptEvalReport struct {
+	Prompt          string              `json:"prompt"` // PromptTemplate.Ref
+	Cases           int                 `json:"cases"`
+	Correct         int                 `json:"correct"`
+	Accuracy        float64             `json:"accuracy"`
+	FalsePositives  int                 `json:"false_positives"`
+	FalseNegatives  int                 `json:"false_negatives"`
+	WrongType       int                 `json:"wrong_type"`
+	Rejected        int                 `json:"rejected"`       // Still invalid after repair
+	RenderErrors    int                 `json:"render_errors"`  // Template failed to render
+	MissingInputs   int                 `json:"missing_inputs"` // Rendered prompt omits the table or column
+	AvgPromptTokens int                 `json:"avg_prompt_tokens"`
+	Failures        []PromptEvalFailure `json:"failures,omitempty"`
+}
+
+// PromptEvalFailure explains one case the prompt got wrong.
+type PromptEvalFailure struct {
+	CaseID string `json:"case_id"`
+	Reason string `json:"reason"`
+}
+
+// LoadPromptEvalDataset reads a JSON array of PromptEvalCase.
+func LoadPromptEvalDataset(path string) ([]PromptEvalCase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read dataset: %w", err)
+	}
+	var cases []PromptEvalCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("parse dataset: %w", err)
+	}
+	return cases, nil
+}
+
+// EvaluatePIIPrompt replays cases through a PIIDetection prompt.
+func EvaluatePIIPrompt(ctx context.Context, prompt PromptTemplate, cases []PromptEvalCase) *PromptEvalReport {
+	report := &PromptEvalReport{Prompt: prompt.Ref(), Cases: len(cases)}
+	sanitizer := NewSanitizer()
+	totalTokens := 0
+
+	for _, c := range cases {
+		fail := func(format string, args ...any) {
+			report.Failures = append(report.Failures, PromptEvalFailure{CaseID: c.ID, Reason: fmt.Sprintf(format, args...)})
+		}
+
+		input := c.Input
+		if len(input.SanitizedSamples) > 0 {
+			input.SanitizedSamples = sanitizer.SanitizeSamples(input.SanitizedSamples)
+		}
+		rendered, err := RenderPrompt(prompt, input)
+		if err != nil {
+			report.RenderErrors++
+			fail("render: %v", err)
+			continue
+		}
+		totalTokens += promptTokens(rendered)
+		if !strings.Contains(rendered.Text, c.Input.TableName) || !strings.Contains(rendered.Text, c.Input.ColumnName) {
+			report.MissingInputs++
+			fail("rendered prompt omits the table or column name")
+		}
+
+		result, _, err := CompleteJSON(ctx, newReplayGateway(c.Response), rendered.Text, CompletionOptions{
+			UseCase:        "pii_detection",
+			SystemPrompt:   rendered.System,
+			ResponseSchema: piiDetectionSchema,
+		}, func(r *PIIDetectionResult, v *Validation) {
+			validatePIIResult(r, v, "")
+		})
+		if errors.Is(err, ErrInvalidResponse) {
+			report.Rejected++
+			fail("answer rejected after repair: %v", err)
+			continue
+		}
+		if err != nil {
+			fail("complete: %v", err)
+			continue
+		}
+
+		switch {
+		case result.IsPII && !c.Expected.IsPII:
+			report.FalsePositives++
+			fail("false positive: got %s", result.Type)
+		case !result.IsPII && c.Expected.IsPII:
+			report.FalseNegatives++
+			fail("false negative: want %s", c.Expected.Type)
+		case result.IsPII && c.Expected.Type != "" && result.Type != c.Expected.Type:
+			report.WrongType++
+			fail("wrong type: got %s, want %s", result.Type, c.Expected.Type)
+		default:
+			report.Correct++
+		}
+	}
+
+	if report.Cases > 0 {
+		report.Accuracy = float64(report.Correct) / float64(report.Cases)
+	}
+	if rendered := report.Cases - report.RenderErrors; rendered > 0 {
+		report.AvgPromptTokens = totalTokens / rendered
+	}
+	return report
+}
+
+// Regressions lists how the report is worse than baseline's; an empty list
+// means the prompt may be promoted.
+func (r *PromptEvalReport) Regressions(baseline *PromptEvalReport) []string {
+	var out []string
+	if r.RenderErrors > 0 {
+		out = append(out, fmt.Sprintf("%d cases failed to render", r.RenderErrors))
+	}
+	if r.MissingInputs > baseline.MissingInputs {
+		out = append(out, fmt.Sprintf("%d prompts omit the table or column (baseline %d)", r.MissingInputs, baseline.MissingInputs))
+	}
+	if r.Accuracy < baseline.Accuracy {
+		out = append(out, fmt.Sprintf("accuracy %.3f below baseline %.3f", r.Accuracy, baseline.Accuracy))
+	}
+	if r.Rejected > baseline.Rejected {
+		out = append(out, fmt.Sprintf("%d answers rejected (baseline %d)", r.Rejected, baseline.Rejected))
+	}
+	return out
+}
+
+// newReplayGateway builds a gateway whose only provider replays answer.
+func newReplayGateway(answer string) *DefaultGateway {
+	registry := NewRegistry()
+	registry.Register("replay", replayProvider{answer: answer}, ProviderConfig{Name: "replay"})
+	return NewDefaultGateway(NewSelector(registry, []string{"replay"}, nil), nil)
+}
+
+// replayProvider is the evaluation's mock provider: it answers every call
+// with the recorded answer.
+type replayProvider struct {
+	answer string
+}
+
+func (p replayProvider) Name() string                       { return "replay" }
+func (p replayProvider) IsAvailable(_ context.Context) bool { return true }
+
+func (p replayProvider) Complete(_ context.Context, prompt string, _ CompletionOptions) (*CompletionResult, error) {
+	return &CompletionResult{
+		Response:     p.answer,
+		Provider:     "replay",
+		Model:        "replay",
+		InputTokens:  estimateTokens(prompt),
+		OutputTokens: estimateTokens(p.answer),
+		TokensUsed:   estimateTokens(prompt) + estimateTokens(p.answer),
+	}, nil
+}
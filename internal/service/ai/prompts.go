@@ -1,104 +1,246 @@
 package ai
 
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+)
+
 // =============================================================================
-// Prompt Templates
+// Prompt Templates — Versioned registry
 // =============================================================================
 //
-// These templates are used by the AI Gateway to construct prompts for
-// LLM providers. They use Go template syntax (text/template).
+// Every gateway use case renders its prompt from a template (text/template
+// syntax). The templates below are compiled in as the "builtin" version of
+// each use case. A PromptSource may supply a newer version — per tenant or
+// platform-wide — which is used instead; if it cannot be loaded or fails to
+// render, the builtin is used so a bad prompt never stops detection.
+//
+// Results record the "<use case>@<version>" they were produced with, so the
+// effect of a prompt change can be compared and rolled back.
 //
 // IMPORTANT: Never include real PII in prompts. Use SanitizedSamples only.
 
-// PIIDetectionPrompt instructs the LLM to analyze column metadata for PII.
-const PIIDetectionPrompt = `You are an expert data privacy analyst specializing in PII (Personally Identifiable Information) detection.
+// Prompt use cases. Each has a builtin template and its own template data.
+const (
+	// PromptPIIDetection classifies one column. Data: PIIDetectionInput.
+	PromptPIIDetection = "pii_detection"
+	// PromptPIIBatch heads a batched detection; the columns are appended
+	// after it. Data: PIIBatchPromptData.
+	PromptPIIBatch = "pii_batch_detection"
+	// PromptPurposeSuggestion suggests processing purposes.
+	// Data: PurposeSuggestionInput.
+	PromptPurposeSuggestion = "purpose_suggestion"
+	// PromptDarkPattern audits content for dark patterns.
+	// Data: DarkPatternPromptData.
+	PromptDarkPattern = "dark_pattern_detection"
+	// PromptDPIADraft drafts the risk narrative and mitigations of a Data
+	// Protection Impact Assessment. Data: DPIAPromptData.
+	PromptDPIADraft = "dpia_draft"
+)
 
-CONTEXT:
-- Table name: {{.TableName}}
-- Column name: {{.ColumnName}}
-- Data type: {{.DataType}}
-- Sample value patterns (anonymized): {{range .SanitizedSamples}}
-  • {{.}}{{end}}
-- Adjacent columns in same table: {{range .AdjacentColumns}}
-  • {{.}}{{end}}
-{{- if .Industry}}
-- Industry: {{.Industry}}
-{{- end}}
+// BuiltinPromptVersion is the version label of the compiled-in templates.
+const BuiltinPromptVersion = "builtin"
 
-TASK:
-Determine if this column contains PII. If yes, classify it precisely.
+// PromptTemplate is one version of a use case's prompt.
+type PromptTemplate struct {
+	UseCase string `json:"use_case"`
+	Version string `json:"version"` // "builtin", or "v<n>" for stored versions
+	System  string `json:"system,omitempty"`
+	Body    string `json:"body"` // text/template
+}
 
-RULES:
-1. Consider the column name AND sample patterns together for context
-2. Consider adjacent columns — "first_name" next to "last_name" and "email" = very likely a person record
-3. Be conservative: if confidence < 0.50, mark requires_review as true
-4. A column named "John" alone is ambiguous; "John" next to an "email" column is very likely a name
-5. Consider Indian data formats: Aadhaar (12 digits), PAN (XXXXX1234X), Indian phone (+91)
-6. Data types matter: VARCHAR/TEXT columns are more likely to contain PII than INT/BOOLEAN
+// Ref is the "<use case>@<version>" label recorded with results.
+func (p PromptTemplate) Ref() string {
+	return p.UseCase + "@" + p.Version
+}
 
-VALID PII CATEGORIES: IDENTITY, CONTACT, FINANCIAL, HEALTH, BIOMETRIC, GENETIC, LOCATION, BEHAVIORAL, PROFESSIONAL, GOVERNMENT_ID, MINOR
+// RenderedPrompt is a template executed against a call's data.
+type RenderedPrompt struct {
+	System  string
+	Text    string
+	Version string // PromptTemplate.Ref of the template used
+}
 
-VALID PII TYPES: NAME, EMAIL, PHONE, ADDRESS, AADHAAR, PAN, PASSPORT, SSN, NATIONAL_ID, DATE_OF_BIRTH, GENDER, BANK_ACCOUNT, CREDIT_CARD, IP_ADDRESS, MAC_ADDRESS, DEVICE_ID, BIOMETRIC, MEDICAL_RECORD, PHOTO, SIGNATURE
+// PIIBatchPromptData is the template data of PromptPIIBatch.
+type PIIBatchPromptData struct {
+	TableName string
+	Industry  string
+}
 
-SENSITIVITY LEVELS:
-- CRITICAL: Direct identity theft risk (Aadhaar, SSN, Credit Card, Bank Account)
-- HIGH: Significant identity impact (PAN, Passport)
-- MEDIUM: Moderate privacy impact (Email, Phone, Address, DOB, Location)
-- LOW: Limited individual impact (Name, Postal Code, IP Address)
+// DarkPatternPromptData is the template data of PromptDarkPattern.
+type DarkPatternPromptData struct {
+	ContentType string // TEXT, CODE or HTML
+	Content     string
+}
 
-Respond ONLY with valid JSON, no markdown:
-{
-  "is_pii": true/false,
-  "category": "CATEGORY_FROM_LIST",
-  "type": "TYPE_FROM_LIST",
-  "sensitivity": "CRITICAL|HIGH|MEDIUM|LOW",
-  "confidence": 0.00-1.00,
-  "reasoning": "brief explanation of your decision",
-  "requires_review": true/false
-}`
+// DPIAPromptData is the template data of PromptDPIADraft: the processing
+// under assessment, summarized one item per line. It carries inventory
+// metadata only, never sampled values.
+type DPIAPromptData struct {
+	Title                 string
+	Description           string
+	Industry              string
+	Purposes              []string
+	DataSources           []string
+	DataCategories        []string
+	ThirdParties          []string
+	CrossBorderTransfers  []string
+	ReidentificationRisks []string
+	RiskSignals           []string // Risks already identified from the inventory
+}
 
-// PurposeSuggestionPrompt instructs the LLM to suggest data processing purposes.
-const PurposeSuggestionPrompt = `You are a data governance expert helping organizations comply with data protection regulations (DPDPA, GDPR).
+// PromptSource supplies the active prompt version of a use case for the
+// tenant in ctx. It returns nil, nil when the builtin should be used.
+type PromptSource interface {
+	ActivePrompt(ctx context.Context, useCase string) (*PromptTemplate, error)
+}
 
-CONTEXT:
-- Data source type: {{.DataSourceType}}
-- Table/Entity: {{.EntityName}}
-- Column: {{.ColumnName}}
-- Detected PII type: {{.PIIType}}
-{{- if .Industry}}
-- Industry: {{.Industry}}
-{{- end}}
-- Sample Values (anonymized): {{range .SampleValues}}
-  • {{.}}{{end}}
+// PromptRegistry renders prompts from the active version of each use case.
+// Safe for concurrent use.
+type PromptRegistry struct {
+	source PromptSource
+	logger *slog.Logger
+}
 
-TASK:
-Determine the most likely data processing purpose(s) for collecting this data.
+// NewPromptRegistry creates a registry over source. A nil source always
+// renders the builtin templates.
+func NewPromptRegistry(source PromptSource, logger *slog.Logger) *PromptRegistry {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &PromptRegistry{source: source, logger: logger}
+}
 
-LEGAL BASES (pick one per purpose):
-- CONSENT: Processing based on explicit user consent
-- CONTRACT: Necessary for contract performance
-- LEGAL_OBLIGATION: Required by law
-- VITAL_INTEREST: Protecting someone's life
-- PUBLIC_INTEREST: Official authority tasks
-- LEGITIMATE_INTEREST: Organization's legitimate interests
-- EMPLOYMENT: Employment relationship
+// Render executes the active template of useCase against data.
+func (r *PromptRegistry) Render(ctx context.Context, useCase string, data any) (*RenderedPrompt, error) {
+	if r != nil && r.source != nil {
+		active, err := r.source.ActivePrompt(ctx, useCase)
+		switch {
+		case err != nil:
+			r.logger.WarnContext(ctx, "failed to load active prompt, using builtin", "use_case", useCase, "error", err)
+		case active != nil:
+			rendered, err := RenderPrompt(*active, data)
+			if err == nil {
+				return rendered, nil
+			}
+			r.logger.WarnContext(ctx, "active prompt failed to render, using builtin",
+				"use_case", useCase,
+				"version", active.Ref(),
+				"error", err,
+			)
+		}
+	}
 
-Respond ONLY with valid JSON, no markdown:
-{
-  "suggested_purposes": [
-    {
-      "code": "short_purpose_code",
-      "description": "Human-readable purpose description",
-      "confidence": 0.00-1.00,
-      "reasoning": "Why this purpose applies"
-    }
-  ],
-  "legal_basis": "LEGAL_BASIS_FROM_LIST",
-  "requires_explicit_consent": true/false,
-  "retention_suggestion": "Suggested retention period (e.g., '3 years after account closure')"
-}`
+	builtin, ok := BuiltinPrompt(useCase)
+	if !ok {
+		return nil, fmt.Errorf("no prompt for use case %q", useCase)
+	}
+	return RenderPrompt(builtin, data)
+}
 
-// DarkPatternPrompt instructs the LLM to detect dark patterns based on India's 2023 Guidelines.
-const DarkPatternPrompt = `You are a compliance officer enforcing the "Guidelines for Prevention and Regulation of Dark Patterns, 2023" (India).
+// RenderPrompt executes one template against data. Unknown fields are
+// errors, so a template written for another use case does not render.
+func RenderPrompt(p PromptTemplate, data any) (*RenderedPrompt, error) {
+	tmpl, err := template.New(p.Ref()).Option("missingkey=error").Parse(p.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt %s: %w", p.Ref(), err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("render prompt %s: %w", p.Ref(), err)
+	}
+	return &RenderedPrompt{System: p.System, Text: b.String(), Version: p.Ref()}, nil
+}
+
+// ValidatePrompt checks that a template parses and renders against example
+// data of its use case.
+func ValidatePrompt(p PromptTemplate) error {
+	data, ok := examplePromptData[p.UseCase]
+	if !ok {
+		return fmt.Errorf("unknown prompt use case %q", p.UseCase)
+	}
+	_, err := RenderPrompt(p, data)
+	return err
+}
+
+// PromptUseCases returns the use cases that have a prompt.
+func PromptUseCases() []string {
+	return []string{PromptPIIDetection, PromptPIIBatch, PromptPurposeSuggestion, PromptDarkPattern, PromptDPIADraft}
+}
+
+// BuiltinPrompt returns the compiled-in template of a use case.
+func BuiltinPrompt(useCase string) (PromptTemplate, bool) {
+	p, ok := builtinPrompts[useCase]
+	return p, ok
+}
+
+// examplePromptData is the data ValidatePrompt renders each use case with.
+var examplePromptData = map[string]any{
+	PromptPIIDetection: PIIDetectionInput{
+		TableName: "customers", ColumnName: "email", DataType: "varchar",
+		SanitizedSamples: []string{"[EMAIL]"}, AdjacentColumns: []string{"first_name"}, Industry: "retail",
+	},
+	PromptPIIBatch: PIIBatchPromptData{TableName: "customers", Industry: "retail"},
+	PromptPurposeSuggestion: PurposeSuggestionInput{
+		DataSourceType: "postgresql", EntityName: "customers", ColumnName: "email",
+		PIIType: "EMAIL", Industry: "retail", SampleValues: []string{"[EMAIL]"},
+	},
+	PromptDarkPattern: DarkPatternPromptData{ContentType: "TEXT", Content: "Only 2 left!"},
+	PromptDPIADraft: DPIAPromptData{
+		Title: "Loyalty programme", Description: "Purchase history used for personalised offers", Industry: "retail",
+		Purposes: []string{"Marketing (CONSENT)"}, DataSources: []string{"crm (postgresql)"},
+		DataCategories: []string{"CONTACT: 12 fields"}, ThirdParties: []string{"MailCo (PROCESSOR, US, DPA SIGNED)"},
+		CrossBorderTransfers: []string{"MailCo → US (SCC)"}, ReidentificationRisks: []string{"crm.customers: HIGH (k=1)"},
+		RiskSignals: []string{"Cross-border transfer: MailCo → US"},
+	},
+}
+
+var builtinPrompts = map[string]PromptTemplate{
+	PromptPIIDetection: {
+		UseCase: PromptPIIDetection,
+		Version: BuiltinPromptVersion,
+		System:  "You are a data privacy analysis assistant specializing in PII detection. Always respond with valid JSON only. No markdown fences.",
+		Body: "Analyze this database column for PII and respond with JSON:\n" +
+			"```json\n{\"is_pii\": bool, \"category\": \"string\", \"type\": \"string\"," +
+			" \"sensitivity\": \"LOW|MEDIUM|HIGH|CRITICAL\", \"confidence\": 0.0-1.0," +
+			" \"reasoning\": \"string\"}\n```\n\n" +
+			"Table: {{.TableName}}\nColumn: {{.ColumnName}}\nData Type: {{.DataType}}\n" +
+			"Sanitized Samples: {{.SanitizedSamples}}\nAdjacent Columns: {{.AdjacentColumns}}\nIndustry: {{.Industry}}",
+	},
+
+	PromptPIIBatch: {
+		UseCase: PromptPIIBatch,
+		Version: BuiltinPromptVersion,
+		System:  "You are a data privacy analysis assistant specializing in PII detection. Classify every column you are given. Always respond with valid JSON only. No markdown fences.",
+		Body: "Analyze every column of this database table for PII and respond with JSON:\n" +
+			"```json\n{\"columns\": [{\"column\": \"string\", \"is_pii\": bool, \"category\": \"string\", \"type\": \"string\"," +
+			" \"sensitivity\": \"LOW|MEDIUM|HIGH|CRITICAL\", \"confidence\": 0.0-1.0, \"reasoning\": \"string\"}]}\n```\n" +
+			"Return exactly one entry per column, with \"column\" set to the column name as given. " +
+			"Use the other columns of the table as context.\n\n" +
+			"Table: {{.TableName}}\nIndustry: {{.Industry}}\nColumns:\n",
+	},
+
+	PromptPurposeSuggestion: {
+		UseCase: PromptPurposeSuggestion,
+		Version: BuiltinPromptVersion,
+		System:  "You are a data privacy compliance assistant. Suggest processing purposes based on GDPR, DPDPA, and other regulations. Respond with valid JSON only.",
+		Body: "Suggest data processing purposes. Respond with JSON:\n" +
+			"```json\n{\"suggestions\": [{\"purpose_code\": \"string\", \"confidence\": 0.0-1.0," +
+			" \"reasoning\": \"string\", \"legal_basis\": \"CONSENT|CONTRACT|...\"," +
+			" \"requires_explicit_consent\": bool}]}\n```\n\n" +
+			"Data Source Type: {{.DataSourceType}}\nEntity: {{.EntityName}}\nColumn: {{.ColumnName}}\n" +
+			"PII Type: {{.PIIType}}\nIndustry: {{.Industry}}\nSample Values: {{.SampleValues}}",
+	},
+
+	// Based on India's Guidelines for Prevention and Regulation of Dark
+	// Patterns, 2023.
+	PromptDarkPattern: {
+		UseCase: PromptDarkPattern,
+		Version: BuiltinPromptVersion,
+		Body: `You are a compliance officer enforcing the "Guidelines for Prevention and Regulation of Dark Patterns, 2023" (India).
 
 CONTEXT:
 - Content Type: {{.ContentType}} (TEXT, CODE, or HTML)
@@ -137,4 +279,53 @@ Respond ONLY with valid JSON, no markdown:
   "confidence": 0.00-1.00,
   "explanation": "Specific quote or element that violates the guideline",
   "cited_clause": "Annexure 1(Clause Number) Pattern Name"
-}`
+}`,
+	},
+	// Drafts for DPDPA s.10(2)(c) (Significant Data Fiduciaries) and GDPR
+	// Art. 35(7). The reviewer edits and approves the draft.
+	PromptDPIADraft: {
+		UseCase: PromptDPIADraft,
+		Version: BuiltinPromptVersion,
+		System:  "You are a data protection officer drafting Data Protection Impact Assessments under India's DPDPA 2023 and the GDPR. Be specific to the processing described; do not invent systems or recipients that are not listed. Respond with valid JSON only. No markdown fences.",
+		Body: `Draft the risk assessment of this processing activity.
+
+Title: {{.Title}}
+Description: {{.Description}}
+Industry: {{.Industry}}
+
+Purposes (legal basis):
+{{range .Purposes}}- {{.}}
+{{else}}- None recorded
+{{end}}
+Data sources:
+{{range .DataSources}}- {{.}}
+{{else}}- None recorded
+{{end}}
+Personal data categories discovered:
+{{range .DataCategories}}- {{.}}
+{{else}}- None recorded
+{{end}}
+Third parties:
+{{range .ThirdParties}}- {{.}}
+{{else}}- None recorded
+{{end}}
+Cross-border transfers:
+{{range .CrossBorderTransfers}}- {{.}}
+{{else}}- None recorded
+{{end}}
+Re-identification risk of datasets:
+{{range .ReidentificationRisks}}- {{.}}
+{{else}}- None assessed
+{{end}}
+Risks already identified from the inventory (assess each, and add any others):
+{{range .RiskSignals}}- {{.}}
+{{else}}- None
+{{end}}
+Respond with JSON:
+{
+  "narrative": "Risk narrative: the nature, scope, context and purposes of the processing, its necessity and proportionality, and the risks to data principals",
+  "risks": [{"title": "string", "description": "string", "likelihood": "LOW|MEDIUM|HIGH", "impact": "LOW|MEDIUM|HIGH"}],
+  "mitigations": [{"risk": "title of the risk addressed", "measure": "string", "residual_risk": "LOW|MEDIUM|HIGH"}]
+}`,
+	},
+}
@@ -0,0 +1,151 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fixedPromptSource serves prompt for its use case, or fails with err.
+type fixedPromptSource struct {
+	prompt *PromptTemplate
+	err    error
+}
+
+func (s fixedPromptSource) ActivePrompt(_ context.Context, useCase string) (*PromptTemplate, error) {
+	if s.err != nil || s.prompt == nil || s.prompt.UseCase != useCase {
+		return nil, s.err
+	}
+	return s.prompt, nil
+}
+
+func TestBuiltinPrompts_Render(t *testing.T) {
+	for _, useCase := range PromptUseCases() {
+		builtin, ok := BuiltinPrompt(useCase)
+		if !ok {
+			t.Fatalf("no builtin prompt for %s", useCase)
+		}
+		if err := ValidatePrompt(builtin); err != nil {
+			t.Errorf("builtin %s: %v", useCase, err)
+		}
+	}
+
+	rendered, err := NewPromptRegistry(nil, nil).Render(context.Background(), PromptPIIDetection, PIIDetectionInput{
+		TableName: "customers", ColumnName: "email", DataType: "varchar",
+		SanitizedSamples: []string{"[EMAIL]"}, AdjacentColumns: []string{"first_name"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Table: customers\nColumn: email\nData Type: varchar\nSanitized Samples: [[EMAIL]]\nAdjacent Columns: [first_name]\nIndustry: "
+	if !strings.HasSuffix(rendered.Text, want) {
+		t.Errorf("rendered prompt ends with:\n%q\nwant suffix:\n%q", rendered.Text[len(rendered.Text)-len(want):], want)
+	}
+	if rendered.Version != "pii_detection@builtin" {
+		t.Errorf("version: got %q", rendered.Version)
+	}
+}
+
+func TestPromptRegistry_ActiveVersionAndFallback(t *testing.T) {
+	ctx := context.Background()
+	data := PIIDetectionInput{TableName: "customers", ColumnName: "email"}
+
+	active := &PromptTemplate{UseCase: PromptPIIDetection, Version: "v3", System: "be brief", Body: "Column {{.ColumnName}}?"}
+	rendered, err := NewPromptRegistry(fixedPromptSource{prompt: active}, nil).Render(ctx, PromptPIIDetection, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rendered.Text != "Column email?" || rendered.System != "be brief" || rendered.Version != "pii_detection@v3" {
+		t.Errorf("active version: got %+v", rendered)
+	}
+
+	tests := map[string]PromptSource{
+		"source error":   fixedPromptSource{err: errors.New("db down")},
+		"unknown field":  fixedPromptSource{prompt: &PromptTemplate{UseCase: PromptPIIDetection, Version: "v4", Body: "{{.Column}}"}},
+		"no active":      fixedPromptSource{},
+		"other use case": fixedPromptSource{prompt: &PromptTemplate{UseCase: PromptDarkPattern, Version: "v1", Body: "x"}},
+		"invalid syntax": fixedPromptSource{prompt: &PromptTemplate{UseCase: PromptPIIDetection, Version: "v5", Body: "{{.ColumnName"}},
+		"no source":      nil,
+	}
+	for name, source := range tests {
+		rendered, err := NewPromptRegistry(source, nil).Render(ctx, PromptPIIDetection, data)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if rendered.Version != "pii_detection@builtin" {
+			t.Errorf("%s: got %q, want the builtin", name, rendered.Version)
+		}
+	}
+}
+
+func TestDefaultGateway_RecordsPromptVersion(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("replay", replayProvider{answer: `{"is_pii": true, "type": "EMAIL", "confidence": 0.9}`}, ProviderConfig{})
+	gw := NewDefaultGateway(NewSelector(registry, []string{"replay"}, nil), nil)
+	gw.SetPromptSource(fixedPromptSource{prompt: &PromptTemplate{
+		UseCase: PromptPIIDetection, Version: "v7", Body: "{{.TableName}}.{{.ColumnName}}",
+	}})
+
+	result, err := gw.DetectPII(context.Background(), PIIDetectionInput{TableName: "customers", ColumnName: "email"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.PromptVersion != "pii_detection@v7" {
+		t.Errorf("prompt version: got %q", result.PromptVersion)
+	}
+
+	registry = NewRegistry()
+	registry.Register("replay", replayProvider{answer: `{"columns": [{"column": "email", "is_pii": true, "type": "EMAIL", "confidence": 0.9}]}`}, ProviderConfig{})
+	gw = NewDefaultGateway(NewSelector(registry, []string{"replay"}, nil), nil)
+	batch, err := gw.DetectPIIBatch(context.Background(), PIIBatchInput{
+		TableName: "customers",
+		Columns:   []PIIBatchColumn{{ColumnName: "email"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := batch.Results["email"]; r == nil || r.PromptVersion != "pii_batch_detection@builtin" {
+		t.Errorf("batch result: got %+v", r)
+	}
+}
+
+func TestEvaluatePIIPrompt(t *testing.T) {
+	cases, err := LoadPromptEvalDataset("testdata/prompt_eval_pii.json")
+	if err != nil {
+		t.Fatalf("load dataset: %v", err)
+	}
+	ctx := context.Background()
+	builtin, _ := BuiltinPrompt(PromptPIIDetection)
+	baseline := EvaluatePIIPrompt(ctx, builtin, cases)
+	if baseline.Accuracy != 1 || len(baseline.Failures) != 0 {
+		t.Fatalf("baseline: got %+v", baseline)
+	}
+
+	body, err := os.ReadFile("testdata/prompts/pii_detection_v2.tmpl")
+	if err != nil {
+		t.Fatalf("read candidate: %v", err)
+	}
+	candidate := PromptTemplate{UseCase: PromptPIIDetection, Version: "candidate", System: builtin.System, Body: string(body)}
+	report := EvaluatePIIPrompt(ctx, candidate, cases)
+	if regressions := report.Regressions(baseline); len(regressions) != 0 {
+		t.Errorf("candidate regressions: %v", regressions)
+	}
+	if report.AvgPromptTokens <= baseline.AvgPromptTokens {
+		t.Errorf("candidate prompt tokens %d should exceed the builtin's %d", report.AvgPromptTokens, baseline.AvgPromptTokens)
+	}
+
+	broken := PromptTemplate{UseCase: PromptPIIDetection, Version: "broken", Body: "Is {{.Column}} PII?"}
+	report = EvaluatePIIPrompt(ctx, broken, cases)
+	if report.RenderErrors != len(cases) || len(report.Regressions(baseline)) == 0 {
+		t.Errorf("broken template: got %+v", report)
+	}
+
+	terse := PromptTemplate{UseCase: PromptPIIDetection, Version: "terse", Body: "Is this column PII? Answer in JSON."}
+	report = EvaluatePIIPrompt(ctx, terse, cases)
+	if report.MissingInputs != len(cases) || len(report.Regressions(baseline)) == 0 {
+		t.Errorf("prompt without the column: got %+v", report)
+	}
+}
@@ -22,6 +22,7 @@ import (
 //	│                                                                  │
 //	│  "openai"       → OpenAICompatProvider (api.openai.com)          │
 //	│  "azure"        → OpenAICompatProvider (*.openai.azure.com)      │
+//	│  "local"        → LocalProvider        (Ollama / llama.cpp)       │
 //	│  "ollama"       → OpenAICompatProvider (localhost:11434)          │
 //	│  "vllm"         → OpenAICompatProvider (your-vllm-server:8000)   │
 //	│  "together"     → OpenAICompatProvider (api.together.xyz)        │
@@ -120,8 +121,10 @@ func BuildFromConfig(cfg ProviderConfig) (Provider, error) {
 		return NewAnthropicProvider(cfg), nil
 	case ProviderTypeGenericHTTP:
 		return NewGenericHTTPProvider(cfg), nil
+	case ProviderTypeLocal:
+		return NewLocalProvider(cfg)
 	default:
-		return nil, fmt.Errorf("unknown provider type: %q (use openai_compatible, anthropic, or generic_http)", cfg.Type)
+		return nil, fmt.Errorf("unknown provider type: %q (use openai_compatible, anthropic, generic_http, or local)", cfg.Type)
 	}
 }
 
@@ -2,7 +2,7 @@ package ai
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -17,6 +17,8 @@ import (
 //   - Fallback chain: try providers in configured order until one works
 //   - Use-case routing: specific providers for specific tasks
 //   - Automatic failover: skip unavailable providers
+//   - Circuit breaking: skip providers that keep failing or timing out
+//   - Hedging: race a second provider for latency-sensitive use cases
 //
 // Example fallback chain: ["openai", "anthropic", "ollama-local"]
 //   - Try OpenAI first (best accuracy for PII detection)
@@ -28,8 +30,13 @@ type Selector struct {
 	registry      *Registry
 	fallbackChain []string // Provider names in priority order
 
-	mu         sync.RWMutex
-	useCaseMap map[string]string // UseCase → preferred provider name
+	mu          sync.RWMutex
+	useCaseMap  map[string]string        // UseCase → preferred provider name
+	hedgeDelays map[string]time.Duration // UseCase → delay before hedging
+	breakerCfg  BreakerConfig
+	breakers    map[string]*circuitBreaker // Provider name → breaker; see health.go
+
+	ledger UsageLedger // optional; see SetUsageLedger
 
 	logger *slog.Logger
 }
@@ -43,6 +50,9 @@ func NewSelector(registry *Registry, fallbackChain []string, logger *slog.Logger
 		registry:      registry,
 		fallbackChain: fallbackChain,
 		useCaseMap:    make(map[string]string),
+		hedgeDelays:   make(map[string]time.Duration),
+		breakerCfg:    DefaultBreakerConfig(),
+		breakers:      make(map[string]*circuitBreaker),
 		logger:        logger,
 	}
 }
@@ -59,26 +69,99 @@ func (s *Selector) SetUseCasePreference(useCase, providerName string) {
 
 // CompleteWithFallback tries providers in the fallback chain until one succeeds.
 // If a use-case preference is set and that provider is available, it's tried first.
+// Providers whose circuit breaker is open are skipped, and use cases with
+// hedging enabled race the next provider when the first is slow.
+// With a usage ledger set, the tenant's budget is checked first and the
+// successful call is recorded.
 func (s *Selector) CompleteWithFallback(ctx context.Context, prompt string, opts CompletionOptions) (*CompletionResult, error) {
+	if s.ledger != nil {
+		if err := s.ledger.CheckBudget(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build the ordered list of providers to try
 	chain := s.buildChain(opts.UseCase)
 
+	s.mu.RLock()
+	hedgeDelay := s.hedgeDelays[opts.UseCase]
+	s.mu.RUnlock()
+
+	var (
+		name   string
+		result *CompletionResult
+		err    error
+	)
+	if hedgeDelay > 0 {
+		name, result, err = s.completeHedged(ctx, chain, prompt, opts, hedgeDelay)
+	} else {
+		name, result, err = s.completeSequential(ctx, chain, prompt, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordUsage(ctx, name, opts, result)
+	return result, nil
+}
+
+// CompleteStreamWithFallback is CompleteWithFallback for callers that show
+// a completion as it is generated. Streaming providers pass text to onChunk
+// as they produce it; others deliver their whole answer as one chunk. A
+// provider that fails before its first chunk falls back to the next one;
+// once text has been delivered, a failure is returned as is.
+func (s *Selector) CompleteStreamWithFallback(ctx context.Context, prompt string, opts CompletionOptions, onChunk func(chunk string) error) (*CompletionResult, error) {
+	if s.ledger != nil {
+		if err := s.ledger.CheckBudget(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	chain := s.buildChain(opts.UseCase)
 	var lastErr error
 	for _, name := range chain {
-		provider := s.registry.Get(name)
+		provider := s.usable(ctx, name, opts.UseCase)
 		if provider == nil {
 			continue
 		}
 
-		if !provider.IsAvailable(ctx) {
-			s.logger.Warn("provider unavailable, skipping",
+		streamed := false
+		result, err := s.attempt(ctx, name, provider, prompt, opts, func(chunk string) error {
+			streamed = true
+			return onChunk(chunk)
+		})
+		if err == nil && !streamed {
+			err = onChunk(result.Response) // Non-streaming provider
+		}
+		if err != nil {
+			if streamed {
+				return nil, err
+			}
+			lastErr = err
+			s.logger.Error("provider failed, trying next",
 				"provider", name,
+				"error", err,
 				"use_case", opts.UseCase,
 			)
 			continue
 		}
 
-		result, err := provider.Complete(ctx, prompt, opts)
+		s.recordUsage(ctx, name, opts, result)
+		return result, nil
+	}
+	return nil, chainError(chain, lastErr)
+}
+
+// completeSequential tries each provider in turn.
+func (s *Selector) completeSequential(ctx context.Context, chain []string, prompt string, opts CompletionOptions) (string, *CompletionResult, error) {
+	var lastErr error
+	for _, name := range chain {
+		provider := s.usable(ctx, name, opts.UseCase)
+		if provider == nil {
+			continue
+		}
+
+		result, err := s.attempt(ctx, name, provider, prompt, opts, nil)
 		if err != nil {
 			lastErr = err
 			s.logger.Error("provider failed, trying next",
@@ -88,23 +171,170 @@ func (s *Selector) CompleteWithFallback(ctx context.Context, prompt string, opts
 			)
 			continue
 		}
+		return name, result, nil
+	}
+	return "", nil, chainError(chain, lastErr)
+}
 
-		// Check if result is empty — some providers return empty on edge cases
-		if result.Response == "" {
-			lastErr = fmt.Errorf("%s: empty response", name)
-			s.logger.Warn("provider returned empty response, trying next",
-				"provider", name,
+// hedgeOutcome is the result of one hedged call.
+type hedgeOutcome struct {
+	name   string
+	result *CompletionResult
+	err    error
+}
+
+// completeHedged starts the first provider and, if it has not answered
+// within delay, starts the next one as well. The first success wins and the
+// other call is cancelled. A failure starts the next provider immediately.
+// Calls still in flight when it returns are billed as they finish.
+func (s *Selector) completeHedged(ctx context.Context, chain []string, prompt string, opts CompletionOptions, delay time.Duration) (string, *CompletionResult, error) {
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outcomes := make(chan hedgeOutcome, len(chain))
+
+	next, inFlight, launched := 0, 0, 0
+	launch := func() {
+		for next < len(chain) {
+			name := chain[next]
+			next++
+			provider := s.usable(ctx, name, opts.UseCase)
+			if provider == nil {
+				continue
+			}
+			go func() {
+				result, err := s.attempt(hedgeCtx, name, provider, prompt, opts, nil)
+				outcomes <- hedgeOutcome{name: name, result: result, err: err}
+			}()
+			inFlight++
+			launched++
+			return
+		}
+	}
+
+	launch()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var lastErr error
+	for inFlight > 0 {
+		select {
+		case o := <-outcomes:
+			inFlight--
+			if o.err == nil {
+				if launched > 1 {
+					hedgedRequestsTotal.WithLabelValues(opts.UseCase, o.name).Inc()
+				}
+				if inFlight > 0 {
+					go s.recordLosingHedges(context.WithoutCancel(ctx), outcomes, inFlight, opts)
+				}
+				return o.name, o.result, nil
+			}
+			lastErr = o.err
+			s.logger.Error("provider failed, trying next",
+				"provider", o.name,
+				"error", o.err,
+				"use_case", opts.UseCase,
 			)
-			continue
+			if inFlight == 0 {
+				launch()
+			}
+		case <-timer.C:
+			before := launched
+			launch()
+			if launched > before {
+				s.logger.Info("provider slow, hedging to next",
+					"use_case", opts.UseCase,
+					"delay", delay,
+				)
+			}
+		case <-ctx.Done():
+			if inFlight > 0 {
+				go s.recordLosingHedges(context.WithoutCancel(ctx), outcomes, inFlight, opts)
+			}
+			return "", nil, ctx.Err()
 		}
+	}
+	return "", nil, chainError(chain, lastErr)
+}
 
-		return result, nil
+// recordLosingHedges waits for the n hedged calls still in flight and
+// records the usage of those that returned a result: a provider that
+// answered before it saw the cancellation has still billed the tokens.
+func (s *Selector) recordLosingHedges(ctx context.Context, outcomes <-chan hedgeOutcome, n int, opts CompletionOptions) {
+	for range n {
+		if o := <-outcomes; o.result != nil {
+			s.recordUsage(ctx, o.name, opts, o.result)
+		}
+	}
+}
+
+// usable returns the named provider if it is registered, available and its
+// circuit breaker admits a call, or nil.
+func (s *Selector) usable(ctx context.Context, name, useCase string) Provider {
+	provider := s.registry.Get(name)
+	if provider == nil {
+		return nil
+	}
+
+	if !provider.IsAvailable(ctx) {
+		s.logger.Warn("provider unavailable, skipping",
+			"provider", name,
+			"use_case", useCase,
+		)
+		return nil
+	}
+
+	if !s.breaker(name).allow(time.Now()) {
+		s.logger.Warn("provider circuit open, skipping",
+			"provider", name,
+			"use_case", useCase,
+		)
+		return nil
+	}
+	return provider
+}
+
+// attempt makes one call and reports its outcome to the provider's breaker.
+// With onChunk set, streaming providers stream into it. Calls abandoned by
+// the caller (cancelled, not timed out), refused because the provider is at
+// capacity, or whose prompt does not fit its context window say nothing
+// about the provider's health and are not counted; the caller moves on to
+// the next provider.
+func (s *Selector) attempt(ctx context.Context, name string, provider Provider, prompt string, opts CompletionOptions, onChunk func(string) error) (*CompletionResult, error) {
+	b := s.breaker(name)
+	start := time.Now()
+
+	var result *CompletionResult
+	var err error
+	if sp, ok := provider.(StreamingProvider); ok && onChunk != nil {
+		result, err = sp.CompleteStream(ctx, prompt, opts, onChunk)
+	} else {
+		result, err = provider.Complete(ctx, prompt, opts)
+	}
+	// Some providers return empty on edge cases
+	if err == nil && result.Response == "" {
+		err = fmt.Errorf("%s: empty response", name)
 	}
 
+	if err != nil && (errors.Is(ctx.Err(), context.Canceled) ||
+		errors.Is(err, ErrProviderBusy) || errors.Is(err, ErrContextWindowExceeded)) {
+		b.release()
+		return nil, err
+	}
+	b.record(time.Now(), err, time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// chainError describes why no provider in chain answered.
+func chainError(chain []string, lastErr error) error {
 	if lastErr != nil {
-		return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
+		return fmt.Errorf("all providers failed, last error: %w", lastErr)
 	}
-	return nil, fmt.Errorf("no providers available in fallback chain: %v", chain)
+	return fmt.Errorf("no providers available in fallback chain: %v", chain)
 }
 
 // buildChain constructs the ordered provider list for a request.
@@ -142,6 +372,7 @@ func (s *Selector) buildChain(useCase string) []string {
 type DefaultGateway struct {
 	selector  *Selector
 	sanitizer *Sanitizer
+	prompts   *PromptRegistry
 	logger    *slog.Logger
 }
 
@@ -153,10 +384,22 @@ func NewDefaultGateway(selector *Selector, logger *slog.Logger) *DefaultGateway
 	return &DefaultGateway{
 		selector:  selector,
 		sanitizer: NewSanitizer(),
+		prompts:   NewPromptRegistry(nil, logger),
 		logger:    logger,
 	}
 }
 
+// SetPromptSource makes the gateway render prompts from the versions source
+// marks active instead of the builtin templates.
+func (g *DefaultGateway) SetPromptSource(source PromptSource) {
+	g.prompts = NewPromptRegistry(source, g.logger)
+}
+
+// Prompts returns the registry the gateway renders its prompts from.
+func (g *DefaultGateway) Prompts() *PromptRegistry {
+	return g.prompts
+}
+
 // DetectPII analyzes a column for PII using the configured AI providers.
 // Raw samples are sanitized before being sent to any provider.
 func (g *DefaultGateway) DetectPII(ctx context.Context, input PIIDetectionInput) (*PIIDetectionResult, error) {
@@ -168,59 +411,50 @@ func (g *DefaultGateway) DetectPII(ctx context.Context, input PIIDetectionInput)
 		sanitizedSamples = g.sanitizer.SanitizeSamples(sanitizedSamples)
 	}
 
-	prompt := fmt.Sprintf(
-		"Analyze this database column for PII and respond with JSON:\n"+
-			"```json\n{\"is_pii\": bool, \"category\": \"string\", \"type\": \"string\","+
-			" \"sensitivity\": \"LOW|MEDIUM|HIGH|CRITICAL\", \"confidence\": 0.0-1.0,"+
-			" \"reasoning\": \"string\"}\n```\n\n"+
-			"Table: %s\nColumn: %s\nData Type: %s\n"+
-			"Sanitized Samples: %v\nAdjacent Columns: %v\nIndustry: %s",
-		input.TableName, input.ColumnName, input.DataType,
-		sanitizedSamples, input.AdjacentColumns, input.Industry,
-	)
-
-	opts := CompletionOptions{
-		UseCase:      "pii_detection",
-		Priority:     "accuracy",
-		MaxTokens:    512,
-		Temperature:  0.1,
-		SystemPrompt: "You are a data privacy analysis assistant specializing in PII detection. Always respond with valid JSON only. No markdown fences.",
-	}
-
-	result, err := g.selector.CompleteWithFallback(ctx, prompt, opts)
+	data := input
+	data.SanitizedSamples = sanitizedSamples
+	prompt, err := g.prompts.Render(ctx, PromptPIIDetection, data)
 	if err != nil {
 		return nil, fmt.Errorf("pii detection: %w", err)
 	}
 
-	// Parse structured response from LLM
-	piiResult, parseErr := parsePIIResponse(result)
-	if parseErr != nil {
-		g.logger.Warn("failed to parse structured PII response, returning raw",
-			"error", parseErr,
+	opts := CompletionOptions{
+		UseCase:        "pii_detection",
+		Priority:       "accuracy",
+		MaxTokens:      512,
+		Temperature:    0.1,
+		SystemPrompt:   prompt.System,
+		ResponseSchema: piiDetectionSchema,
+	}
+
+	piiResult, result, err := CompleteJSON(ctx, g, prompt.Text, opts, func(r *PIIDetectionResult, v *Validation) {
+		validatePIIResult(r, v, "")
+	})
+	if errors.Is(err, ErrInvalidResponse) {
+		g.logger.Warn("rejected structured PII response, returning raw",
+			"error", err,
 			"provider", result.Provider,
 		)
-		// Return raw response as reasoning — detection service will handle
+		// Return raw response as reasoning, flagged for review
 		return &PIIDetectionResult{
-			Reasoning:  result.Response,
-			Provider:   result.Provider,
-			TokensUsed: result.TokensUsed,
-			Duration:   time.Since(start),
+			Reasoning:      result.Response,
+			RequiresReview: true,
+			Provider:       result.Provider,
+			PromptVersion:  prompt.Version,
+			TokensUsed:     result.TokensUsed,
+			Duration:       time.Since(start),
 		}, nil
 	}
+	if err != nil {
+		return nil, fmt.Errorf("pii detection: %w", err)
+	}
 
 	piiResult.Provider = result.Provider
+	piiResult.PromptVersion = prompt.Version
 	piiResult.TokensUsed = result.TokensUsed
 	piiResult.Duration = time.Since(start)
-	return piiResult, nil
-}
 
-// parsePIIResponse attempts to parse the LLM JSON response into a PIIDetectionResult.
-func parsePIIResponse(result *CompletionResult) (*PIIDetectionResult, error) {
-	var parsed PIIDetectionResult
-	if err := json.Unmarshal([]byte(result.Response), &parsed); err != nil {
-		return nil, fmt.Errorf("json parse: %w", err)
-	}
-	return &parsed, nil
+	return piiResult, nil
 }
 
 // SuggestPurposes recommends data processing purposes.
@@ -231,44 +465,44 @@ func (g *DefaultGateway) SuggestPurposes(ctx context.Context, input PurposeSugge
 		sanitizedSamples = g.sanitizer.SanitizeSamples(sanitizedSamples)
 	}
 
-	prompt := fmt.Sprintf(
-		"Suggest data processing purposes. Respond with JSON array:\n"+
-			"```json\n[{\"purpose_code\": \"string\", \"confidence\": 0.0-1.0,"+
-			" \"reasoning\": \"string\", \"legal_basis\": \"CONSENT|CONTRACT|...\","+
-			" \"requires_explicit_consent\": bool}]\n```\n\n"+
-			"Data Source Type: %s\nEntity: %s\nColumn: %s\n"+
-			"PII Type: %s\nIndustry: %s\nSample Values: %v",
-		input.DataSourceType, input.EntityName, input.ColumnName,
-		input.PIIType, input.Industry, sanitizedSamples,
-	)
-
-	opts := CompletionOptions{
-		UseCase:      "purpose_suggestion",
-		Priority:     "accuracy",
-		MaxTokens:    512,
-		Temperature:  0.2,
-		SystemPrompt: "You are a data privacy compliance assistant. Suggest processing purposes based on GDPR, DPDPA, and other regulations. Respond with valid JSON only.",
-	}
-
-	result, err := g.selector.CompleteWithFallback(ctx, prompt, opts)
+	data := input
+	data.SampleValues = sanitizedSamples
+	prompt, err := g.prompts.Render(ctx, PromptPurposeSuggestion, data)
 	if err != nil {
 		return nil, fmt.Errorf("purpose suggestion: %w", err)
 	}
 
-	// Parse JSON array response
-	var suggestions []PurposeSuggestion
-	if err := json.Unmarshal([]byte(result.Response), &suggestions); err != nil {
-		g.logger.Warn("failed to parse purpose suggestions",
+	opts := CompletionOptions{
+		UseCase:        "purpose_suggestion",
+		Priority:       "accuracy",
+		MaxTokens:      512,
+		Temperature:    0.2,
+		SystemPrompt:   prompt.System,
+		ResponseSchema: purposeSuggestionSchema,
+	}
+
+	answer, result, err := CompleteJSON(ctx, g, prompt.Text, opts, validatePurposeSuggestions)
+	if errors.Is(err, ErrInvalidResponse) {
+		g.logger.Warn("rejected purpose suggestions",
 			"error", err,
 			"provider", result.Provider,
 		)
 		return nil, nil
 	}
+	if err != nil {
+		return nil, fmt.Errorf("purpose suggestion: %w", err)
+	}
 
-	return suggestions, nil
+	return answer.Suggestions, nil
 }
 
 // Complete performs a generic completion.
 func (g *DefaultGateway) Complete(ctx context.Context, prompt string, opts CompletionOptions) (*CompletionResult, error) {
 	return g.selector.CompleteWithFallback(ctx, prompt, opts)
 }
+
+// CompleteStream performs a generic LLM completion, passing the text to
+// onChunk as it is generated; see Selector.CompleteStreamWithFallback.
+func (g *DefaultGateway) CompleteStream(ctx context.Context, prompt string, opts CompletionOptions, onChunk func(chunk string) error) (*CompletionResult, error) {
+	return g.selector.CompleteStreamWithFallback(ctx, prompt, opts, onChunk)
+}
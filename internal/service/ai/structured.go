@@ -0,0 +1,443 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// Structured Output — Schema-driven validation and repair
+// =============================================================================
+//
+// Every gateway use case expects a JSON answer of a known shape. CompleteJSON
+// asks for it (through the provider's native JSON-schema mode where one
+// exists), decodes it, and runs the use case's validator, which coerces
+// near-misses (a lower-case enum, a confidence of 85) and reports anything
+// it cannot fix. Answers with problems are re-asked, quoting the problems,
+// a bounded number of times before being rejected.
+
+// MaxRepairAttempts is how many times an invalid answer is re-asked.
+const MaxRepairAttempts = 2
+
+// ErrInvalidResponse is returned when a model's answer is still invalid
+// after all repair attempts.
+var ErrInvalidResponse = errors.New("ai: invalid structured response")
+
+// ResponseSchema is the JSON Schema of a use case's answer. Providers with a
+// native structured-output mode are constrained to it; the rest see it only
+// through the prompt.
+type ResponseSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+}
+
+// Validation collects what a validator coerced and what it could not fix.
+type Validation struct {
+	Problems []string
+	Coerced  int
+}
+
+// Problem records an issue the model must fix.
+func (v *Validation) Problem(format string, args ...any) {
+	v.Problems = append(v.Problems, fmt.Sprintf(format, args...))
+}
+
+// Coerce records that a value was corrected in place.
+func (v *Validation) Coerce() {
+	v.Coerced++
+}
+
+var structuredOutputTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "datalens_ai_structured_output_total",
+	Help: "Structured AI answers by use case and outcome (valid, coerced, repaired, rejected).",
+}, []string{"use_case", "outcome"})
+
+// CompleteJSON asks gw for a JSON answer, decodes it into a T and validates
+// it with validate, which may coerce the value in place. Invalid answers are
+// re-asked up to MaxRepairAttempts times. The returned result is the last
+// call's, with tokens and duration summed over all attempts; it is returned
+// with ErrInvalidResponse too, so callers can fall back to the raw answer.
+func CompleteJSON[T any](ctx context.Context, gw Gateway, prompt string, opts CompletionOptions, validate func(*T, *Validation)) (*T, *CompletionResult, error) {
+	var (
+		total    CompletionResult
+		problems []string
+	)
+	ask := prompt
+	for attempt := 0; attempt <= MaxRepairAttempts; attempt++ {
+		result, err := gw.Complete(ctx, ask, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		total.TokensUsed += result.TokensUsed
+		total.InputTokens += result.InputTokens
+		total.OutputTokens += result.OutputTokens
+		total.Duration += result.Duration
+		total.Response = result.Response
+		total.Provider = result.Provider
+		total.Model = result.Model
+		total.Cached = result.Cached
+
+		value, v := decodeAndValidate(result.Response, validate)
+		if len(v.Problems) == 0 {
+			outcome := "valid"
+			switch {
+			case attempt > 0:
+				outcome = "repaired"
+			case v.Coerced > 0:
+				outcome = "coerced"
+			}
+			structuredOutputTotal.WithLabelValues(opts.UseCase, outcome).Inc()
+			return value, &total, nil
+		}
+
+		problems = v.Problems
+		ask = repairPrompt(prompt, result.Response, problems)
+	}
+
+	structuredOutputTotal.WithLabelValues(opts.UseCase, "rejected").Inc()
+	return nil, &total, fmt.Errorf("%w for %s: %s", ErrInvalidResponse, opts.UseCase, strings.Join(problems, "; "))
+}
+
+// decodeAndValidate decodes the JSON in response and validates it.
+func decodeAndValidate[T any](response string, validate func(*T, *Validation)) (*T, *Validation) {
+	v := &Validation{}
+	var value T
+	if err := json.Unmarshal([]byte(extractJSON(response)), &value); err != nil {
+		v.Problem("the answer is not valid JSON: %v", err)
+		return nil, v
+	}
+	if validate != nil {
+		validate(&value, v)
+	}
+	return &value, v
+}
+
+// extractJSON strips Markdown fences and surrounding prose from a model's
+// answer, returning the outermost JSON object or array.
+func extractJSON(response string) string {
+	s := strings.TrimSpace(response)
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	s = strings.TrimSpace(s)
+
+	start := strings.IndexAny(s, "{[")
+	if start < 0 {
+		return s
+	}
+	closer := byte('}')
+	if s[start] == '[' {
+		closer = ']'
+	}
+	if end := strings.LastIndexByte(s, closer); end > start {
+		return s[start : end+1]
+	}
+	return s[start:]
+}
+
+// repairPrompt re-asks the original question, quoting the invalid answer
+// and what was wrong with it.
+func repairPrompt(prompt, answer string, problems []string) string {
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\nYour previous answer was invalid:\n")
+	b.WriteString(answer)
+	b.WriteString("\n\nProblems:\n")
+	for _, p := range problems {
+		b.WriteString("- ")
+		b.WriteString(p)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nRespond again with corrected JSON only.")
+	return b.String()
+}
+
+// =============================================================================
+// Enum and range coercion
+// =============================================================================
+
+// normalizeEnum upper-cases s and joins words with underscores.
+func normalizeEnum(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "_", "-", "_", ".", "_").Replace(s)
+}
+
+// piiTypeAliases maps common model spellings to PII types.
+var piiTypeAliases = map[string]types.PIIType{
+	"FULL_NAME":          types.PIITypeName,
+	"PERSON_NAME":        types.PIITypeName,
+	"FIRST_NAME":         types.PIITypeName,
+	"LAST_NAME":          types.PIITypeName,
+	"EMAIL_ADDRESS":      types.PIITypeEmail,
+	"PHONE_NUMBER":       types.PIITypePhone,
+	"MOBILE":             types.PIITypePhone,
+	"MOBILE_NUMBER":      types.PIITypePhone,
+	"POSTAL_ADDRESS":     types.PIITypeAddress,
+	"STREET_ADDRESS":     types.PIITypeAddress,
+	"AADHAR":             types.PIITypeAadhaar,
+	"AADHAAR_NUMBER":     types.PIITypeAadhaar,
+	"PAN_NUMBER":         types.PIITypePAN,
+	"PAN_CARD":           types.PIITypePAN,
+	"PASSPORT_NUMBER":    types.PIITypePassport,
+	"DOB":                types.PIITypeDOB,
+	"BIRTH_DATE":         types.PIITypeDOB,
+	"BIRTHDATE":          types.PIITypeDOB,
+	"SEX":                types.PIITypeGender,
+	"ACCOUNT_NUMBER":     types.PIITypeBankAccount,
+	"IBAN":               types.PIITypeBankAccount,
+	"CARD_NUMBER":        types.PIITypeCreditCard,
+	"CREDIT_CARD_NUMBER": types.PIITypeCreditCard,
+	"IP":                 types.PIITypeIPAddress,
+	"MAC":                types.PIITypeMACAddress,
+	"HEALTH_RECORD":      types.PIITypeMedicalRecord,
+	"PASSWORD":           types.PIITypeCredential,
+	"API_KEY":            types.PIITypeCredential,
+	"SECRET":             types.PIITypeCredential,
+}
+
+// coercePIIType maps s onto a PII type, reporting whether it had to change
+// and whether it is valid at all.
+func coercePIIType(s types.PIIType) (t types.PIIType, changed, ok bool) {
+	if s.IsValid() {
+		return s, false, true
+	}
+	n := types.PIIType(normalizeEnum(string(s)))
+	if n.IsValid() {
+		return n, true, true
+	}
+	if alias, found := piiTypeAliases[string(n)]; found {
+		return alias, true, true
+	}
+	return s, false, false
+}
+
+// coerceEnum maps s onto one of valid after normalizing its spelling.
+func coerceEnum[E ~string](s E, valid []E) (e E, changed, ok bool) {
+	for _, v := range valid {
+		if s == v {
+			return s, false, true
+		}
+	}
+	n := E(normalizeEnum(string(s)))
+	for _, v := range valid {
+		if n == v {
+			return n, true, true
+		}
+	}
+	return s, false, false
+}
+
+// CoerceConfidence brings a confidence into [0, 1], reading values between
+// 1 and 100 as percentages.
+func CoerceConfidence(c float64, v *Validation) float64 {
+	switch {
+	case c >= 0 && c <= 1:
+		return c
+	case c > 1 && c <= 100:
+		v.Coerce()
+		return c / 100
+	case c < 0:
+		v.Coerce()
+		return 0
+	default:
+		v.Coerce()
+		return 1
+	}
+}
+
+// CoerceEnum returns value, or the member of valid it normalizes to, and
+// reports any other value as a problem.
+func CoerceEnum[E ~string](field string, value E, valid []E, v *Validation) E {
+	e, changed, ok := coerceEnum(value, valid)
+	if !ok {
+		v.Problem("%s is %q, which is not one of %v", field, value, valid)
+		return value
+	}
+	if changed {
+		v.Coerce()
+	}
+	return e
+}
+
+// CoerceEnumList keeps the members of values that are, or normalize to, one
+// of valid, and reports the rest as problems.
+func CoerceEnumList[E ~string](field string, values []E, valid []E, v *Validation) []E {
+	out := values[:0]
+	for _, value := range values {
+		e, changed, ok := coerceEnum(value, valid)
+		if !ok {
+			v.Problem("%s contains %q, which is not one of %v", field, value, valid)
+			continue
+		}
+		if changed {
+			v.Coerce()
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// =============================================================================
+// Gateway use-case validators and schemas
+// =============================================================================
+
+// validatePIIResult coerces a PII detection answer. A PII answer must name a
+// known type; an unknown category or sensitivity is dropped, since both can
+// be derived from the type.
+func validatePIIResult(r *PIIDetectionResult, v *Validation, label string) {
+	r.Confidence = CoerceConfidence(r.Confidence, v)
+	if !r.IsPII {
+		return
+	}
+
+	t, changed, ok := coercePIIType(r.Type)
+	switch {
+	case !ok && r.Type == "":
+		v.Problem("%sis_pii is true but type is missing", label)
+	case !ok:
+		v.Problem("%stype %q is not one of %v", label, r.Type, types.PIITypes())
+	case changed:
+		v.Coerce()
+		r.Type = t
+	}
+
+	if r.Category != "" {
+		if c, changed, ok := coerceEnum(r.Category, types.PIICategories()); !ok {
+			v.Coerce()
+			r.Category = ""
+		} else if changed {
+			v.Coerce()
+			r.Category = c
+		}
+	}
+	if r.Sensitivity != "" {
+		if s, changed, ok := coerceEnum(r.Sensitivity, types.SensitivityLevels()); !ok {
+			v.Coerce()
+			r.Sensitivity = ""
+		} else if changed {
+			v.Coerce()
+			r.Sensitivity = s
+		}
+	}
+}
+
+// enumStrings converts enum constants for use in a JSON Schema.
+func enumStrings[E ~string](values []E) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
+
+// piiResultProperties is the JSON Schema of a PIIDetectionResult's answer fields.
+func piiResultProperties() map[string]any {
+	return map[string]any{
+		"is_pii":      map[string]any{"type": "boolean"},
+		"category":    map[string]any{"type": "string", "enum": append(enumStrings(types.PIICategories()), "")},
+		"type":        map[string]any{"type": "string", "enum": append(enumStrings(types.PIITypes()), "")},
+		"sensitivity": map[string]any{"type": "string", "enum": append(enumStrings(types.SensitivityLevels()), "")},
+		"confidence":  map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+		"reasoning":   map[string]any{"type": "string"},
+	}
+}
+
+var (
+	piiDetectionSchema = &ResponseSchema{
+		Name: "pii_detection",
+		Schema: map[string]any{
+			"type":       "object",
+			"properties": piiResultProperties(),
+			"required":   []string{"is_pii", "confidence"},
+		},
+	}
+
+	piiBatchSchema = &ResponseSchema{
+		Name: "pii_batch_detection",
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"columns": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": func() map[string]any {
+							p := piiResultProperties()
+							p["column"] = map[string]any{"type": "string"}
+							return p
+						}(),
+						"required": []string{"column", "is_pii", "confidence"},
+					},
+				},
+			},
+			"required": []string{"columns"},
+		},
+	}
+
+	purposeSuggestionSchema = &ResponseSchema{
+		Name: "purpose_suggestion",
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"suggestions": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"purpose_code":              map[string]any{"type": "string"},
+							"confidence":                map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+							"reasoning":                 map[string]any{"type": "string"},
+							"legal_basis":               map[string]any{"type": "string", "enum": enumStrings(types.LegalBases())},
+							"requires_explicit_consent": map[string]any{"type": "boolean"},
+						},
+						"required": []string{"purpose_code", "confidence", "legal_basis"},
+					},
+				},
+			},
+			"required": []string{"suggestions"},
+		},
+	}
+)
+
+// purposeSuggestionAnswer is the purpose suggestion answer. Models sometimes
+// return the bare array, which is accepted too.
+type purposeSuggestionAnswer struct {
+	Suggestions []PurposeSuggestion `json:"suggestions"`
+}
+
+// UnmarshalJSON accepts {"suggestions": [...]} or [...].
+func (a *purposeSuggestionAnswer) UnmarshalJSON(data []byte) error {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		return json.Unmarshal(data, &a.Suggestions)
+	}
+	type plain purposeSuggestionAnswer
+	return json.Unmarshal(data, (*plain)(a))
+}
+
+// validatePurposeSuggestions coerces purpose suggestions; each needs a code
+// and a known legal basis.
+func validatePurposeSuggestions(a *purposeSuggestionAnswer, v *Validation) {
+	for i := range a.Suggestions {
+		s := &a.Suggestions[i]
+		s.Confidence = CoerceConfidence(s.Confidence, v)
+		if strings.TrimSpace(s.PurposeCode) == "" {
+			v.Problem("suggestion %d has no purpose_code", i+1)
+		}
+		b, changed, ok := coerceEnum(s.LegalBasis, types.LegalBases())
+		switch {
+		case !ok:
+			v.Problem("suggestion %d: legal_basis %q is not one of %v", i+1, s.LegalBasis, types.LegalBases())
+		case changed:
+			v.Coerce()
+			s.LegalBasis = b
+		}
+	}
+}
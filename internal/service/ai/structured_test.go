@@ -0,0 +1,180 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// answerGateway answers each Complete call with the next scripted response
+// and records the prompts it was given.
+type answerGateway struct {
+	Gateway
+	answers []string
+	prompts []string
+}
+
+func (g *answerGateway) Complete(_ context.Context, prompt string, _ CompletionOptions) (*CompletionResult, error) {
+	g.prompts = append(g.prompts, prompt)
+	if len(g.answers) == 0 {
+		return nil, errors.New("no more answers")
+	}
+	answer := g.answers[0]
+	g.answers = g.answers[1:]
+	return &CompletionResult{Response: answer, Provider: "scripted", TokensUsed: 10}, nil
+}
+
+func validatePII(r *PIIDetectionResult, v *Validation) { validatePIIResult(r, v, "") }
+
+func TestCompleteJSON_CoercesNearMisses(t *testing.T) {
+	gw := &answerGateway{answers: []string{
+		"```json\n{\"is_pii\": true, \"type\": \"email address\", \"category\": \"contact\", \"sensitivity\": \"extreme\", \"confidence\": 85}\n```",
+	}}
+
+	got, result, err := CompleteJSON(context.Background(), gw, "classify", CompletionOptions{UseCase: "pii_detection"}, validatePII)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Type != types.PIITypeEmail || got.Category != types.PIICategoryContact {
+		t.Errorf("type/category: got %s/%s", got.Type, got.Category)
+	}
+	if got.Sensitivity != "" {
+		t.Errorf("unknown sensitivity should be dropped, got %q", got.Sensitivity)
+	}
+	if got.Confidence != 0.85 {
+		t.Errorf("confidence: got %v, want 0.85", got.Confidence)
+	}
+	if len(gw.prompts) != 1 || result.TokensUsed != 10 {
+		t.Errorf("calls: got %d, tokens %d", len(gw.prompts), result.TokensUsed)
+	}
+}
+
+func TestCompleteJSON_RepairsInvalidAnswer(t *testing.T) {
+	gw := &answerGateway{answers: []string{
+		`{"is_pii": true, "type": "FAVOURITE_COLOUR", "confidence": 0.9}`,
+		`{"is_pii": true, "type": "PHONE", "confidence": 0.9}`,
+	}}
+
+	got, result, err := CompleteJSON(context.Background(), gw, "classify", CompletionOptions{UseCase: "pii_detection"}, validatePII)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Type != types.PIITypePhone {
+		t.Errorf("type: got %s, want PHONE", got.Type)
+	}
+	if len(gw.prompts) != 2 {
+		t.Fatalf("calls: got %d, want 2", len(gw.prompts))
+	}
+	if !strings.HasPrefix(gw.prompts[1], "classify") || !strings.Contains(gw.prompts[1], `"FAVOURITE_COLOUR"`) {
+		t.Errorf("repair prompt should repeat the question and quote the problem:\n%s", gw.prompts[1])
+	}
+	if result.TokensUsed != 20 {
+		t.Errorf("tokens: got %d, want 20 (summed over attempts)", result.TokensUsed)
+	}
+}
+
+func TestCompleteJSON_RejectsAfterMaxRepairs(t *testing.T) {
+	gw := &answerGateway{answers: []string{"not json", "still not json", "nope", "never asked"}}
+
+	got, result, err := CompleteJSON(context.Background(), gw, "classify", CompletionOptions{UseCase: "pii_detection"}, validatePII)
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("error: got %v, want ErrInvalidResponse", err)
+	}
+	if got != nil {
+		t.Errorf("value: got %+v, want nil", got)
+	}
+	if len(gw.prompts) != MaxRepairAttempts+1 {
+		t.Errorf("calls: got %d, want %d", len(gw.prompts), MaxRepairAttempts+1)
+	}
+	if result == nil || result.Response != "nope" {
+		t.Errorf("result should carry the last raw answer, got %+v", result)
+	}
+}
+
+func TestPurposeSuggestionAnswer_AcceptsBareArray(t *testing.T) {
+	gw := &answerGateway{answers: []string{
+		`[{"purpose_code": "MARKETING", "confidence": 70, "legal_basis": "consent"}]`,
+	}}
+
+	got, _, err := CompleteJSON(context.Background(), gw, "suggest", CompletionOptions{}, validatePurposeSuggestions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Suggestions) != 1 {
+		t.Fatalf("suggestions: got %d, want 1", len(got.Suggestions))
+	}
+	s := got.Suggestions[0]
+	if s.LegalBasis != types.LegalBasisConsent || s.Confidence != 0.7 {
+		t.Errorf("suggestion: got %s/%v", s.LegalBasis, s.Confidence)
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{"a": 1}`, `{"a": 1}`},
+		{"```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{`Here is the answer: {"a": {"b": 2}} Hope that helps.`, `{"a": {"b": 2}}`},
+		{`Sure! [1, 2]`, `[1, 2]`},
+	}
+	for _, tt := range tests {
+		if got := extractJSON(tt.in); got != tt.want {
+			t.Errorf("extractJSON(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAIProvider_NativeJSONSchema(t *testing.T) {
+	var req openAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(openAIResponse{
+			Choices: []openAIChoice{{Message: openAIMessage{Role: "assistant", Content: `{"is_pii": false}`}}},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatProvider(ProviderConfig{
+		Name: "openai", Type: ProviderTypeOpenAICompatible, APIKey: "sk-test",
+		Endpoint: server.URL, DefaultModel: "gpt-4o-mini", NativeJSONSchema: true,
+	})
+	if _, err := provider.Complete(context.Background(), "test", CompletionOptions{ResponseSchema: piiDetectionSchema}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.ResponseFormat == nil || req.ResponseFormat.Type != "json_schema" || req.ResponseFormat.JSONSchema.Name != "pii_detection" {
+		t.Errorf("response_format: got %+v", req.ResponseFormat)
+	}
+}
+
+func TestAnthropicProvider_NativeJSONSchema(t *testing.T) {
+	var req anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(anthropicResponse{
+			Content: []anthropicContentBlock{{Type: "tool_use", Input: json.RawMessage(`{"is_pii":true,"type":"EMAIL"}`)}},
+		})
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider(ProviderConfig{
+		Name: "anthropic", Type: ProviderTypeAnthropic, APIKey: "sk-ant-test",
+		Endpoint: server.URL, DefaultModel: "claude-3-5-sonnet", NativeJSONSchema: true,
+	})
+	result, err := provider.Complete(context.Background(), "test", CompletionOptions{ResponseSchema: piiDetectionSchema})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(req.Tools) != 1 || req.ToolChoice == nil || req.ToolChoice.Name != "pii_detection" {
+		t.Errorf("tools: got %+v, tool_choice %+v", req.Tools, req.ToolChoice)
+	}
+	if result.Response != `{"is_pii":true,"type":"EMAIL"}` {
+		t.Errorf("response: got %q", result.Response)
+	}
+}
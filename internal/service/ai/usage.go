@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// =============================================================================
+// Usage Accounting
+// =============================================================================
+//
+// The Selector reports every successful provider call to a UsageLedger,
+// which persists it against the calling tenant and enforces the tenant's
+// budget before the next call. Cache hits never reach the Selector and so
+// are not billed.
+
+// UsageRecord describes one completed provider call.
+type UsageRecord struct {
+	Provider     string        `json:"provider"`
+	Model        string        `json:"model"`
+	UseCase      string        `json:"use_case"`
+	InputTokens  int           `json:"input_tokens"`
+	OutputTokens int           `json:"output_tokens"`
+	TotalTokens  int           `json:"total_tokens"`
+	Cost         float64       `json:"cost"` // USD, from the provider's configured prices
+	Duration     time.Duration `json:"duration"`
+}
+
+// UsageLedger records AI usage and enforces budgets. The tenant is taken
+// from the context; calls without one are system calls.
+type UsageLedger interface {
+	// CheckBudget returns an error when the tenant may not make another
+	// call, typically a QUOTA_EXCEEDED domain error.
+	CheckBudget(ctx context.Context) error
+
+	// RecordUsage persists one completed call.
+	RecordUsage(ctx context.Context, record UsageRecord) error
+}
+
+// SetUsageLedger enables usage recording and budget enforcement for every
+// call made through the selector.
+func (s *Selector) SetUsageLedger(ledger UsageLedger) {
+	s.ledger = ledger
+}
+
+// recordUsage prices a successful call and hands it to the ledger. Ledger
+// failures are logged; the completion itself has already succeeded.
+func (s *Selector) recordUsage(ctx context.Context, providerName string, opts CompletionOptions, result *CompletionResult) {
+	if s.ledger == nil {
+		return
+	}
+	cfg, _ := s.registry.GetConfig(providerName)
+	record := UsageRecord{
+		Provider:     providerName,
+		Model:        result.Model,
+		UseCase:      opts.UseCase,
+		InputTokens:  result.InputTokens,
+		OutputTokens: result.OutputTokens,
+		TotalTokens:  result.TokensUsed,
+		Cost:         cfg.Cost(result.InputTokens, result.OutputTokens, result.TokensUsed),
+		Duration:     result.Duration,
+	}
+	if err := s.ledger.RecordUsage(ctx, record); err != nil {
+		s.logger.Warn("failed to record AI usage",
+			"provider", providerName,
+			"use_case", opts.UseCase,
+			"error", err,
+		)
+	}
+}
+
+// SetUsageLedger enables usage recording and budget enforcement for every
+// call made through the gateway.
+func (g *DefaultGateway) SetUsageLedger(ledger UsageLedger) {
+	g.selector.SetUsageLedger(ledger)
+}
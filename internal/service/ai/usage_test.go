@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingLedger struct {
+	budgetErr error
+
+	mu      sync.Mutex
+	records []UsageRecord
+}
+
+func (l *recordingLedger) CheckBudget(_ context.Context) error { return l.budgetErr }
+
+func (l *recordingLedger) RecordUsage(_ context.Context, record UsageRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, record)
+	return nil
+}
+
+func (l *recordingLedger) providers() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []string
+	for _, r := range l.records {
+		out = append(out, r.Provider)
+	}
+	return out
+}
+
+func newUsageTestSelector(t *testing.T, calls *int) *Selector {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		json.NewEncoder(w).Encode(openAIResponse{
+			Choices: []openAIChoice{{Message: openAIMessage{Content: "ok"}}},
+			Usage:   openAIUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := ProviderConfig{
+		Name:                  "openai",
+		APIKey:                "key",
+		Endpoint:              server.URL,
+		DefaultModel:          "test-model",
+		CostPer1KInputTokens:  0.002,
+		CostPer1KOutputTokens: 0.01,
+	}
+	reg := NewRegistry()
+	reg.Register("openai", NewOpenAICompatProvider(cfg), cfg)
+	return NewSelector(reg, []string{"openai"}, nil)
+}
+
+func TestSelector_RecordsUsage(t *testing.T) {
+	var calls int
+	sel := newUsageTestSelector(t, &calls)
+	ledger := &recordingLedger{}
+	sel.SetUsageLedger(ledger)
+
+	if _, err := sel.CompleteWithFallback(context.Background(), "test", CompletionOptions{UseCase: "pii_detection"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ledger.records) != 1 {
+		t.Fatalf("records: got %d, want 1", len(ledger.records))
+	}
+	rec := ledger.records[0]
+	if rec.Provider != "openai" || rec.UseCase != "pii_detection" {
+		t.Errorf("record: got provider %q use case %q", rec.Provider, rec.UseCase)
+	}
+	if rec.InputTokens != 1000 || rec.OutputTokens != 500 || rec.TotalTokens != 1500 {
+		t.Errorf("tokens: got %d/%d/%d", rec.InputTokens, rec.OutputTokens, rec.TotalTokens)
+	}
+	if want := 0.002 + 0.005; math.Abs(rec.Cost-want) > 1e-9 {
+		t.Errorf("cost: got %f, want %f", rec.Cost, want)
+	}
+}
+
+// deafProvider answers after delay without watching for cancellation, as
+// a provider whose answer is already on the wire does.
+type deafProvider struct {
+	name  string
+	delay time.Duration
+}
+
+func (p *deafProvider) Name() string                       { return p.name }
+func (p *deafProvider) IsAvailable(_ context.Context) bool { return true }
+
+func (p *deafProvider) Complete(_ context.Context, _ string, _ CompletionOptions) (*CompletionResult, error) {
+	time.Sleep(p.delay)
+	return &CompletionResult{Response: "from " + p.name, Provider: p.name, InputTokens: 100, OutputTokens: 50, TokensUsed: 150}, nil
+}
+
+func TestSelector_Hedging_RecordsLoserUsage(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("slow", &deafProvider{name: "slow", delay: 30 * time.Millisecond}, ProviderConfig{})
+	reg.Register("fast", &deafProvider{name: "fast", delay: time.Millisecond}, ProviderConfig{})
+	sel := NewSelector(reg, []string{"slow", "fast"}, nil)
+	sel.SetHedging("purpose_suggestion", 5*time.Millisecond)
+	ledger := &recordingLedger{}
+	sel.SetUsageLedger(ledger)
+
+	result, err := sel.CompleteWithFallback(context.Background(), "test", CompletionOptions{UseCase: "purpose_suggestion"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider != "fast" {
+		t.Fatalf("winner: got %q, want fast", result.Provider)
+	}
+
+	// The loser answered despite the cancellation and is billed too.
+	deadline := time.Now().Add(time.Second)
+	for len(ledger.providers()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	got := ledger.providers()
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"fast", "slow"}) {
+		t.Errorf("recorded providers: got %v, want [fast slow]", got)
+	}
+}
+
+func TestSelector_BudgetRefusesCall(t *testing.T) {
+	var calls int
+	sel := newUsageTestSelector(t, &calls)
+	refused := errors.New("budget exhausted")
+	ledger := &recordingLedger{budgetErr: refused}
+	sel.SetUsageLedger(ledger)
+
+	_, err := sel.CompleteWithFallback(context.Background(), "test", CompletionOptions{})
+	if !errors.Is(err, refused) {
+		t.Fatalf("error: got %v, want %v", err, refused)
+	}
+	if calls != 0 {
+		t.Errorf("provider calls: got %d, want 0", calls)
+	}
+	if len(ledger.records) != 0 {
+		t.Errorf("records: got %d, want 0", len(ledger.records))
+	}
+}
+
+func TestProviderConfig_Cost(t *testing.T) {
+	split := ProviderConfig{CostPer1KTokens: 1, CostPer1KInputTokens: 0.003, CostPer1KOutputTokens: 0.015}
+	if got, want := split.Cost(2000, 1000, 3000), 0.006+0.015; math.Abs(got-want) > 1e-9 {
+		t.Errorf("split cost: got %f, want %f", got, want)
+	}
+	// Without an input/output split the blended price applies.
+	if got, want := split.Cost(0, 0, 3000), 3.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("blended cost: got %f, want %f", got, want)
+	}
+	if got := (ProviderConfig{}).Cost(1000, 1000, 2000); got != 0 {
+		t.Errorf("unpriced cost: got %f, want 0", got)
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/internal/service/ai"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// aiPromptCacheTTL bounds how long an active prompt lookup is reused. The
+// gateway asks for the active prompt on every call, so lookups are cached;
+// activations made through this service take effect at once in this
+// process and within the TTL elsewhere.
+const aiPromptCacheTTL = 30 * time.Second
+
+// AIPromptService manages versioned AI prompt templates and serves the
+// active version of each use case to the AI gateway. It is the gateway's
+// PromptSource: a tenant's active version wins over the platform-wide one,
+// which wins over the builtin template.
+type AIPromptService struct {
+	repo   identity.AIPromptRepository
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedAIPrompt
+}
+
+type cachedAIPrompt struct {
+	prompt  *ai.PromptTemplate // nil: use the builtin
+	expires time.Time
+}
+
+// NewAIPromptService creates a new AIPromptService.
+func NewAIPromptService(repo identity.AIPromptRepository, logger *slog.Logger) *AIPromptService {
+	return &AIPromptService{
+		repo:   repo,
+		logger: logger.With("service", "ai_prompt"),
+		now:    time.Now,
+		cache:  make(map[string]cachedAIPrompt),
+	}
+}
+
+// CreateVersionRequest holds the input for a new prompt version.
+type CreateVersionRequest struct {
+	TenantID     *types.ID // nil creates a platform-wide version
+	UseCase      string
+	SystemPrompt string
+	Template     string
+	Description  string
+	CreatedBy    *types.ID
+}
+
+// CreateVersion stores a draft prompt version after checking that its
+// template renders for its use case.
+func (s *AIPromptService) CreateVersion(ctx context.Context, req CreateVersionRequest) (*identity.AIPromptVersion, error) {
+	if !slices.Contains(ai.PromptUseCases(), req.UseCase) {
+		return nil, types.NewValidationError("unknown prompt use case", map[string]any{
+			"use_case": req.UseCase, "valid": ai.PromptUseCases(),
+		})
+	}
+	if strings.TrimSpace(req.Template) == "" {
+		return nil, types.NewValidationError("template is required", nil)
+	}
+	if err := ai.ValidatePrompt(ai.PromptTemplate{
+		UseCase: req.UseCase,
+		Version: "draft",
+		System:  req.SystemPrompt,
+		Body:    req.Template,
+	}); err != nil {
+		return nil, types.NewValidationError("template does not render", map[string]any{"template": err.Error()})
+	}
+
+	p := &identity.AIPromptVersion{
+		TenantID:     req.TenantID,
+		UseCase:      req.UseCase,
+		SystemPrompt: req.SystemPrompt,
+		Template:     req.Template,
+		Description:  req.Description,
+		CreatedBy:    req.CreatedBy,
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListVersions returns the tenant's and the platform-wide versions, newest
+// first. An empty useCase lists every use case.
+func (s *AIPromptService) ListVersions(ctx context.Context, tenantID types.ID, useCase string) ([]identity.AIPromptVersion, error) {
+	versions, err := s.repo.List(ctx, tenantID, useCase)
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		versions = []identity.AIPromptVersion{}
+	}
+	return versions, nil
+}
+
+// BuiltinPrompts returns the compiled-in template of every use case, the
+// starting point for new versions.
+func (s *AIPromptService) BuiltinPrompts() []ai.PromptTemplate {
+	out := make([]ai.PromptTemplate, 0, len(ai.PromptUseCases()))
+	for _, useCase := range ai.PromptUseCases() {
+		if p, ok := ai.BuiltinPrompt(useCase); ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Activate puts a version in use for its scope, retiring the version it
+// replaces. Activating an older version rolls back to it. Versions of other
+// scopes are not found.
+func (s *AIPromptService) Activate(ctx context.Context, tenantID *types.ID, id types.ID) (*identity.AIPromptVersion, error) {
+	p, err := s.getInScope(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Activate(ctx, id); err != nil {
+		return nil, err
+	}
+	s.invalidate(p.TenantID, p.UseCase)
+	s.logger.InfoContext(ctx, "AI prompt version activated",
+		"use_case", p.UseCase,
+		"version", p.Label(),
+		"tenant_id", p.TenantID,
+	)
+	return s.repo.GetByID(ctx, id)
+}
+
+// Retire takes a version out of use. Retiring the active version of a
+// tenant falls back to the platform-wide version, and that to the builtin.
+func (s *AIPromptService) Retire(ctx context.Context, tenantID *types.ID, id types.ID) (*identity.AIPromptVersion, error) {
+	p, err := s.getInScope(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Retire(ctx, id); err != nil {
+		return nil, err
+	}
+	s.invalidate(p.TenantID, p.UseCase)
+	return s.repo.GetByID(ctx, id)
+}
+
+// ActivePrompt returns the active prompt of useCase for the tenant in ctx,
+// or nil when the builtin applies. It implements ai.PromptSource.
+func (s *AIPromptService) ActivePrompt(ctx context.Context, useCase string) (*ai.PromptTemplate, error) {
+	var tenantID *types.ID
+	if id, ok := types.TenantIDFromContext(ctx); ok {
+		tenantID = &id
+	}
+
+	key := aiPromptCacheKey(tenantID, useCase)
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expires) {
+		return cached.prompt, nil
+	}
+
+	var active *identity.AIPromptVersion
+	var err error
+	if tenantID != nil {
+		active, err = s.repo.GetActive(ctx, tenantID, useCase)
+	}
+	if tenantID == nil || types.IsNotFoundError(err) {
+		active, err = s.repo.GetActive(ctx, nil, useCase)
+	}
+
+	var prompt *ai.PromptTemplate
+	switch {
+	case err == nil:
+		prompt = &ai.PromptTemplate{
+			UseCase: active.UseCase,
+			Version: active.Label(),
+			System:  active.SystemPrompt,
+			Body:    active.Template,
+		}
+	case !types.IsNotFoundError(err):
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[key] = cachedAIPrompt{prompt: prompt, expires: s.now().Add(aiPromptCacheTTL)}
+	s.mu.Unlock()
+	return prompt, nil
+}
+
+// getInScope loads a version, hiding versions of other scopes.
+func (s *AIPromptService) getInScope(ctx context.Context, tenantID *types.ID, id types.ID) (*identity.AIPromptVersion, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if (p.TenantID == nil) != (tenantID == nil) || (p.TenantID != nil && *p.TenantID != *tenantID) {
+		return nil, types.NewNotFoundError("ai prompt version", id)
+	}
+	return p, nil
+}
+
+// invalidate drops cached lookups a change in scope may affect: one
+// tenant's, or every tenant's for a platform-wide change.
+func (s *AIPromptService) invalidate(tenantID *types.ID, useCase string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tenantID != nil {
+		delete(s.cache, aiPromptCacheKey(tenantID, useCase))
+		return
+	}
+	for key := range s.cache {
+		if strings.HasSuffix(key, "|"+useCase) {
+			delete(s.cache, key)
+		}
+	}
+}
+
+func aiPromptCacheKey(tenantID *types.ID, useCase string) string {
+	if tenantID == nil {
+		return "platform|" + useCase
+	}
+	return tenantID.String() + "|" + useCase
+}
+
+// Compile-time check.
+var _ ai.PromptSource = (*AIPromptService)(nil)
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/internal/service/ai"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+type MockAIPromptRepo struct {
+	mock.Mock
+}
+
+func (m *MockAIPromptRepo) Create(ctx context.Context, p *identity.AIPromptVersion) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+func (m *MockAIPromptRepo) GetByID(ctx context.Context, id types.ID) (*identity.AIPromptVersion, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*identity.AIPromptVersion), args.Error(1)
+}
+
+func (m *MockAIPromptRepo) List(ctx context.Context, tenantID types.ID, useCase string) ([]identity.AIPromptVersion, error) {
+	args := m.Called(ctx, tenantID, useCase)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]identity.AIPromptVersion), args.Error(1)
+}
+
+func (m *MockAIPromptRepo) GetActive(ctx context.Context, tenantID *types.ID, useCase string) (*identity.AIPromptVersion, error) {
+	args := m.Called(ctx, tenantID, useCase)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*identity.AIPromptVersion), args.Error(1)
+}
+
+func (m *MockAIPromptRepo) Activate(ctx context.Context, id types.ID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAIPromptRepo) Retire(ctx context.Context, id types.ID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func newTestAIPromptService(repo *MockAIPromptRepo) *AIPromptService {
+	return NewAIPromptService(repo, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+}
+
+func TestAIPromptService_ActivePrompt_TenantThenPlatformThenBuiltin(t *testing.T) {
+	repo := new(MockAIPromptRepo)
+	svc := newTestAIPromptService(repo)
+	tenantID := types.NewID()
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, tenantID)
+	notFound := types.NewNotFoundError("active ai prompt", ai.PromptPIIDetection)
+
+	// No tenant override: the platform-wide version applies.
+	repo.On("GetActive", ctx, &tenantID, ai.PromptPIIDetection).Return(nil, notFound).Once()
+	repo.On("GetActive", ctx, (*types.ID)(nil), ai.PromptPIIDetection).Return(&identity.AIPromptVersion{
+		UseCase: ai.PromptPIIDetection, Version: 4, Template: "{{.ColumnName}}",
+	}, nil).Once()
+
+	prompt, err := svc.ActivePrompt(ctx, ai.PromptPIIDetection)
+	require.NoError(t, err)
+	require.NotNil(t, prompt)
+	assert.Equal(t, "pii_detection@v4", prompt.Ref())
+
+	// Cached: no further lookups within the TTL.
+	prompt, err = svc.ActivePrompt(ctx, ai.PromptPIIDetection)
+	require.NoError(t, err)
+	assert.Equal(t, "pii_detection@v4", prompt.Ref())
+
+	// Neither scope has an active version: the builtin applies.
+	repo.On("GetActive", ctx, &tenantID, ai.PromptPurposeSuggestion).Return(nil, notFound).Once()
+	repo.On("GetActive", ctx, (*types.ID)(nil), ai.PromptPurposeSuggestion).Return(nil, notFound).Once()
+	prompt, err = svc.ActivePrompt(ctx, ai.PromptPurposeSuggestion)
+	require.NoError(t, err)
+	assert.Nil(t, prompt)
+
+	repo.AssertExpectations(t)
+}
+
+func TestAIPromptService_ActivePrompt_CacheExpires(t *testing.T) {
+	repo := new(MockAIPromptRepo)
+	svc := newTestAIPromptService(repo)
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	repo.On("GetActive", ctx, (*types.ID)(nil), ai.PromptDarkPattern).Return(&identity.AIPromptVersion{
+		UseCase: ai.PromptDarkPattern, Version: 1, Template: "x",
+	}, nil).Twice()
+
+	_, err := svc.ActivePrompt(ctx, ai.PromptDarkPattern)
+	require.NoError(t, err)
+	now = now.Add(aiPromptCacheTTL + time.Second)
+	_, err = svc.ActivePrompt(ctx, ai.PromptDarkPattern)
+	require.NoError(t, err)
+
+	repo.AssertExpectations(t)
+}
+
+func TestAIPromptService_CreateVersion_RejectsTemplateThatDoesNotRender(t *testing.T) {
+	repo := new(MockAIPromptRepo)
+	svc := newTestAIPromptService(repo)
+	tenantID := types.NewID()
+
+	_, err := svc.CreateVersion(context.Background(), CreateVersionRequest{
+		TenantID: &tenantID, UseCase: ai.PromptPIIDetection, Template: "Is {{.Column}} PII?",
+	})
+	assert.ErrorIs(t, err, types.ErrValidation)
+
+	_, err = svc.CreateVersion(context.Background(), CreateVersionRequest{
+		TenantID: &tenantID, UseCase: "poetry", Template: "hello",
+	})
+	assert.ErrorIs(t, err, types.ErrValidation)
+
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAIPromptService_Activate_InvalidatesCacheAndChecksScope(t *testing.T) {
+	repo := new(MockAIPromptRepo)
+	svc := newTestAIPromptService(repo)
+	tenantID := types.NewID()
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, tenantID)
+
+	v1 := &identity.AIPromptVersion{ID: types.NewID(), TenantID: &tenantID, UseCase: ai.PromptPIIDetection, Version: 1, Template: "v1 {{.ColumnName}}"}
+	v2 := &identity.AIPromptVersion{ID: types.NewID(), TenantID: &tenantID, UseCase: ai.PromptPIIDetection, Version: 2, Template: "v2 {{.ColumnName}}"}
+
+	repo.On("GetActive", ctx, &tenantID, ai.PromptPIIDetection).Return(v1, nil).Once()
+	prompt, err := svc.ActivePrompt(ctx, ai.PromptPIIDetection)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-v1", prompt.Version)
+
+	repo.On("GetByID", ctx, v2.ID).Return(v2, nil)
+	repo.On("Activate", ctx, v2.ID).Return(nil)
+	_, err = svc.Activate(ctx, &tenantID, v2.ID)
+	require.NoError(t, err)
+
+	repo.On("GetActive", ctx, &tenantID, ai.PromptPIIDetection).Return(v2, nil).Once()
+	prompt, err = svc.ActivePrompt(ctx, ai.PromptPIIDetection)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-v2", prompt.Version)
+
+	// Another tenant's version is not found.
+	otherTenant := types.NewID()
+	_, err = svc.Activate(ctx, &otherTenant, v2.ID)
+	assert.True(t, types.IsNotFoundError(err))
+	// Nor is it from the platform scope.
+	_, err = svc.Activate(ctx, nil, v2.ID)
+	assert.True(t, types.IsNotFoundError(err))
+
+	repo.AssertNumberOfCalls(t, "Activate", 1)
+}
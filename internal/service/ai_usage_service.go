@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/internal/service/ai"
+	"github.com/complyark/datalens/pkg/eventbus"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// aiBudgetCacheTTL bounds how long a tenant's budget and month-to-date
+// usage are reused. Every AI call checks and records against the budget, so
+// both are cached and calls made through this process are added to the
+// cached usage as they are recorded; calls made by other processes are
+// picked up within the TTL.
+const aiBudgetCacheTTL = 30 * time.Second
+
+// AIUsageService keeps the per-tenant AI usage ledger, enforces monthly AI
+// budgets and alerts tenants as they approach their cap. It is the AI
+// selector's UsageLedger.
+type AIUsageService struct {
+	repo     identity.AIUsageRepository
+	eventBus eventbus.EventBus
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[types.ID]*cachedAIBudget
+}
+
+// cachedAIBudget is a tenant's budget and its usage in period. Entries are
+// kept past expiry so a failed reload can still tell whether the tenant has
+// a hard limit.
+type cachedAIBudget struct {
+	budget  *identity.AIBudget // nil: the tenant has no budget
+	period  string
+	used    identity.AIUsageTotals
+	expires time.Time
+}
+
+// NewAIUsageService creates a new AIUsageService.
+func NewAIUsageService(repo identity.AIUsageRepository, eventBus eventbus.EventBus, logger *slog.Logger) *AIUsageService {
+	return &AIUsageService{
+		repo:     repo,
+		eventBus: eventBus,
+		logger:   logger.With("service", "ai_usage"),
+		now:      func() time.Time { return time.Now().UTC() },
+		cache:    make(map[types.ID]*cachedAIBudget),
+	}
+}
+
+// CheckBudget refuses the call when the tenant in ctx has a hard budget and
+// has used it up this month. Calls without a tenant, or by tenants without
+// a hard budget, are allowed. A hard budget fails closed: when the budget
+// or usage cannot be loaded the call is refused unless the tenant is known
+// to have no hard limit.
+func (s *AIUsageService) CheckBudget(ctx context.Context) error {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	state, err := s.budgetState(ctx, tenantID)
+	if err != nil {
+		if state != nil && (state.budget == nil || !state.budget.HardLimit) {
+			s.logger.WarnContext(ctx, "failed to load AI budget", "tenant_id", tenantID, "error", err)
+			return nil
+		}
+		return fmt.Errorf("check AI budget: %w", err)
+	}
+	if state.budget == nil || !state.budget.HardLimit {
+		return nil
+	}
+	if state.budget.Status(state.period, state.used).Level == identity.AIBudgetExceeded {
+		return types.NewQuotaExceededError(fmt.Sprintf("monthly AI budget exhausted for %s", state.period))
+	}
+	return nil
+}
+
+// RecordUsage persists one call against the tenant in ctx and raises a
+// budget alert when the call takes the tenant past its soft limit or cap.
+// Each level alerts once per month.
+func (s *AIUsageService) RecordUsage(ctx context.Context, record ai.UsageRecord) error {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if err := s.repo.Record(ctx, &identity.AIUsageRecord{
+		TenantID:     tenantID,
+		Provider:     record.Provider,
+		Model:        record.Model,
+		UseCase:      record.UseCase,
+		InputTokens:  record.InputTokens,
+		OutputTokens: record.OutputTokens,
+		TotalTokens:  record.TotalTokens,
+		Cost:         record.Cost,
+		CreatedAt:    s.now(),
+	}); err != nil {
+		return err
+	}
+	s.addUsage(tenantID, record)
+
+	state, err := s.budgetState(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if state.budget == nil {
+		return nil
+	}
+	budget := state.budget
+	status := budget.Status(state.period, state.used)
+	if status.Level == identity.AIBudgetOK || !shouldAlertAIBudget(budget, status) {
+		return nil
+	}
+
+	eventType := eventbus.EventAIBudgetThreshold
+	if status.Level == identity.AIBudgetExceeded {
+		eventType = eventbus.EventAIBudgetExceeded
+	}
+	if err := s.repo.MarkBudgetAlerted(ctx, tenantID, status.Period, status.Level); err != nil {
+		return err
+	}
+	s.markAlerted(tenantID, status)
+	if s.eventBus != nil {
+		_ = s.eventBus.Publish(ctx, eventbus.NewEvent(eventType, "ai", tenantID, status))
+	}
+	s.logger.InfoContext(ctx, "AI budget alert raised",
+		"tenant_id", tenantID,
+		"level", status.Level,
+		"percent_used", status.PercentUsed,
+	)
+	return nil
+}
+
+// GetUsage reports a tenant's usage in [from, to) and, when the tenant has a
+// budget, where the current month stands against it.
+func (s *AIUsageService) GetUsage(ctx context.Context, tenantID types.ID, from, to time.Time) (*identity.AIUsageReport, error) {
+	if !to.After(from) {
+		return nil, types.NewValidationError("'to' must be after 'from'", nil)
+	}
+	breakdown, err := s.repo.Summarize(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if breakdown == nil {
+		breakdown = []identity.AIUsageBreakdown{}
+	}
+	report := &identity.AIUsageReport{
+		TenantID:  tenantID,
+		From:      from,
+		To:        to,
+		Totals:    identity.SumAIUsage(breakdown),
+		Breakdown: breakdown,
+	}
+
+	budget, err := s.repo.GetBudget(ctx, tenantID)
+	switch {
+	case err == nil:
+		if report.Budget, err = s.monthStatus(ctx, budget); err != nil {
+			return nil, err
+		}
+	case !types.IsNotFoundError(err):
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetBudget returns the tenant's AI budget.
+func (s *AIUsageService) GetBudget(ctx context.Context, tenantID types.ID) (*identity.AIBudget, error) {
+	return s.repo.GetBudget(ctx, tenantID)
+}
+
+// SetBudget creates or replaces the tenant's AI budget.
+func (s *AIUsageService) SetBudget(ctx context.Context, budget *identity.AIBudget) (*identity.AIBudget, error) {
+	if budget.SoftLimitPercent == 0 {
+		budget.SoftLimitPercent = identity.DefaultAISoftLimitPercent
+	}
+	if err := budget.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertBudget(ctx, budget); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	delete(s.cache, budget.TenantID)
+	s.mu.Unlock()
+	return budget, nil
+}
+
+// budgetState returns the tenant's budget and month-to-date usage, from the
+// cache when fresh. On error it returns the last cached state, if any, so
+// the caller can tell whether a hard limit applies.
+func (s *AIUsageService) budgetState(ctx context.Context, tenantID types.ID) (*cachedAIBudget, error) {
+	now := s.now()
+	period := now.Format("2006-01")
+
+	s.mu.Lock()
+	var stale *cachedAIBudget
+	cached, ok := s.cache[tenantID]
+	if ok {
+		c := *cached
+		stale = &c
+	}
+	s.mu.Unlock()
+	if ok && stale.period == period && now.Before(stale.expires) {
+		return stale, nil
+	}
+
+	state := &cachedAIBudget{period: period, expires: now.Add(aiBudgetCacheTTL)}
+	budget, err := s.repo.GetBudget(ctx, tenantID)
+	switch {
+	case err == nil:
+		state.budget = budget
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		breakdown, err := s.repo.Summarize(ctx, tenantID, from, from.AddDate(0, 1, 0))
+		if err != nil {
+			// The budget is known even though the usage is not
+			return &cachedAIBudget{budget: budget, period: period}, err
+		}
+		state.used = identity.SumAIUsage(breakdown)
+	case !types.IsNotFoundError(err):
+		return stale, err
+	}
+
+	s.mu.Lock()
+	s.cache[tenantID] = state
+	s.mu.Unlock()
+	c := *state
+	return &c, nil
+}
+
+// addUsage adds a recorded call to the tenant's cached usage.
+func (s *AIUsageService) addUsage(tenantID types.ID, record ai.UsageRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.cache[tenantID]; ok {
+		c.used.Calls++
+		c.used.InputTokens += int64(record.InputTokens)
+		c.used.OutputTokens += int64(record.OutputTokens)
+		c.used.TotalTokens += int64(record.TotalTokens)
+		c.used.Cost += record.Cost
+	}
+}
+
+// markAlerted records a raised alert on the cached budget so it is not
+// raised again before the cache reloads.
+func (s *AIUsageService) markAlerted(tenantID types.ID, status *identity.AIBudgetStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.cache[tenantID]; ok && c.budget != nil {
+		b := *c.budget
+		b.AlertedPeriod, b.AlertedLevel = status.Period, status.Level
+		c.budget = &b
+	}
+}
+
+// monthStatus measures the current calendar month's usage against budget.
+func (s *AIUsageService) monthStatus(ctx context.Context, budget *identity.AIBudget) (*identity.AIBudgetStatus, error) {
+	now := s.now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	breakdown, err := s.repo.Summarize(ctx, budget.TenantID, from, from.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	return budget.Status(from.Format("2006-01"), identity.SumAIUsage(breakdown)), nil
+}
+
+// shouldAlertAIBudget reports whether status is a new alert: the first of
+// the month, or an escalation from a warning to exceeded.
+func shouldAlertAIBudget(budget *identity.AIBudget, status *identity.AIBudgetStatus) bool {
+	if budget.AlertedPeriod != status.Period {
+		return true
+	}
+	return budget.AlertedLevel == identity.AIBudgetWarning && status.Level == identity.AIBudgetExceeded
+}
+
+// Compile-time check.
+var _ ai.UsageLedger = (*AIUsageService)(nil)
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/internal/service/ai"
+	"github.com/complyark/datalens/pkg/eventbus"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+type MockAIUsageRepo struct {
+	mock.Mock
+}
+
+func (m *MockAIUsageRepo) Record(ctx context.Context, r *identity.AIUsageRecord) error {
+	args := m.Called(ctx, r)
+	return args.Error(0)
+}
+
+func (m *MockAIUsageRepo) Summarize(ctx context.Context, tenantID types.ID, from, to time.Time) ([]identity.AIUsageBreakdown, error) {
+	args := m.Called(ctx, tenantID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]identity.AIUsageBreakdown), args.Error(1)
+}
+
+func (m *MockAIUsageRepo) GetBudget(ctx context.Context, tenantID types.ID) (*identity.AIBudget, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*identity.AIBudget), args.Error(1)
+}
+
+func (m *MockAIUsageRepo) UpsertBudget(ctx context.Context, b *identity.AIBudget) error {
+	args := m.Called(ctx, b)
+	return args.Error(0)
+}
+
+func (m *MockAIUsageRepo) MarkBudgetAlerted(ctx context.Context, tenantID types.ID, period string, level identity.AIBudgetLevel) error {
+	args := m.Called(ctx, tenantID, period, level)
+	return args.Error(0)
+}
+
+func newTestAIUsageService(repo *MockAIUsageRepo, eb eventbus.EventBus) *AIUsageService {
+	svc := NewAIUsageService(repo, eb, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	svc.now = func() time.Time { return time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestAIUsageService_CheckBudget_HardLimitExhausted(t *testing.T) {
+	repo := new(MockAIUsageRepo)
+	svc := newTestAIUsageService(repo, nil)
+	tenantID := types.NewID()
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, tenantID)
+
+	repo.On("GetBudget", ctx, tenantID).Return(&identity.AIBudget{
+		TenantID: tenantID, MonthlyTokenLimit: 1000, HardLimit: true,
+	}, nil)
+	repo.On("Summarize", ctx, tenantID,
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	).Return([]identity.AIUsageBreakdown{{Provider: "openai", Calls: 3, TotalTokens: 1200}}, nil)
+
+	err := svc.CheckBudget(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, types.ErrQuotaExceeded)
+}
+
+func TestAIUsageService_CheckBudget_SoftLimitAllows(t *testing.T) {
+	repo := new(MockAIUsageRepo)
+	svc := newTestAIUsageService(repo, nil)
+	tenantID := types.NewID()
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, tenantID)
+
+	repo.On("GetBudget", ctx, tenantID).Return(&identity.AIBudget{TenantID: tenantID, MonthlyTokenLimit: 1000}, nil)
+	repo.On("Summarize", ctx, tenantID, mock.Anything, mock.Anything).
+		Return([]identity.AIUsageBreakdown{{Provider: "openai", Calls: 3, TotalTokens: 1200}}, nil)
+
+	assert.NoError(t, svc.CheckBudget(ctx))
+	assert.NoError(t, svc.CheckBudget(context.Background()), "system calls are never refused")
+}
+
+func TestAIUsageService_CheckBudget_FailsClosed(t *testing.T) {
+	repo := new(MockAIUsageRepo)
+	svc := newTestAIUsageService(repo, nil)
+	tenantID := types.NewID()
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, tenantID)
+
+	// Usage cannot be summed for a hard budget
+	repo.On("GetBudget", ctx, tenantID).Return(&identity.AIBudget{
+		TenantID: tenantID, MonthlyTokenLimit: 1000, HardLimit: true,
+	}, nil).Once()
+	repo.On("Summarize", ctx, tenantID, mock.Anything, mock.Anything).Return(nil, errors.New("db down")).Once()
+	assert.Error(t, svc.CheckBudget(ctx))
+
+	// The budget itself cannot be loaded and nothing is known about it
+	repo.On("GetBudget", ctx, tenantID).Return(nil, errors.New("db down")).Once()
+	assert.Error(t, svc.CheckBudget(ctx))
+
+	// A tenant known to have only a soft budget keeps working
+	otherID := types.NewID()
+	otherCtx := context.WithValue(context.Background(), types.ContextKeyTenantID, otherID)
+	repo.On("GetBudget", otherCtx, otherID).Return(&identity.AIBudget{TenantID: otherID, MonthlyTokenLimit: 1000}, nil).Once()
+	repo.On("Summarize", otherCtx, otherID, mock.Anything, mock.Anything).Return([]identity.AIUsageBreakdown{}, nil).Once()
+	require.NoError(t, svc.CheckBudget(otherCtx))
+	svc.now = func() time.Time { return time.Date(2026, 3, 15, 13, 0, 0, 0, time.UTC) }
+	repo.On("GetBudget", otherCtx, otherID).Return(nil, errors.New("db down")).Once()
+	assert.NoError(t, svc.CheckBudget(otherCtx))
+}
+
+func TestAIUsageService_CachesBudgetState(t *testing.T) {
+	repo := new(MockAIUsageRepo)
+	svc := newTestAIUsageService(repo, nil)
+	tenantID := types.NewID()
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, tenantID)
+
+	repo.On("GetBudget", ctx, tenantID).Return(&identity.AIBudget{
+		TenantID: tenantID, MonthlyTokenLimit: 1000, SoftLimitPercent: 99, HardLimit: true,
+	}, nil).Once()
+	repo.On("Summarize", ctx, tenantID, mock.Anything, mock.Anything).
+		Return([]identity.AIUsageBreakdown{{Provider: "openai", Calls: 1, TotalTokens: 900}}, nil).Once()
+	repo.On("Record", ctx, mock.Anything).Return(nil)
+	repo.On("MarkBudgetAlerted", ctx, tenantID, "2026-03", identity.AIBudgetExceeded).Return(nil).Once()
+
+	require.NoError(t, svc.CheckBudget(ctx))
+	require.NoError(t, svc.RecordUsage(ctx, ai.UsageRecord{Provider: "openai", TotalTokens: 150}))
+	assert.ErrorIs(t, svc.CheckBudget(ctx), types.ErrQuotaExceeded, "recorded usage counts without a reload")
+	repo.AssertExpectations(t)
+}
+
+func TestAIUsageService_RecordUsage_AlertsOncePerLevel(t *testing.T) {
+	repo := new(MockAIUsageRepo)
+	eb := new(MockBreachEventBus)
+	svc := newTestAIUsageService(repo, eb)
+	tenantID := types.NewID()
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, tenantID)
+
+	budget := &identity.AIBudget{TenantID: tenantID, MonthlyCostLimit: 10, SoftLimitPercent: 80}
+	repo.On("Record", ctx, mock.MatchedBy(func(r *identity.AIUsageRecord) bool {
+		return r.TenantID == tenantID && r.Provider == "anthropic" && r.Cost == 0.5
+	})).Return(nil)
+	repo.On("GetBudget", ctx, tenantID).Return(budget, nil)
+	repo.On("Summarize", ctx, tenantID, mock.Anything, mock.Anything).
+		Return([]identity.AIUsageBreakdown{{Provider: "anthropic", Calls: 10, Cost: 8.5}}, nil)
+	repo.On("MarkBudgetAlerted", ctx, tenantID, "2026-03", identity.AIBudgetWarning).
+		Run(func(mock.Arguments) {
+			budget.AlertedPeriod, budget.AlertedLevel = "2026-03", identity.AIBudgetWarning
+		}).Return(nil).Once()
+	eb.On("Publish", ctx, mock.MatchedBy(func(e eventbus.Event) bool {
+		return e.Type == eventbus.EventAIBudgetThreshold && e.TenantID == tenantID
+	})).Return(nil).Once()
+
+	record := ai.UsageRecord{Provider: "anthropic", TotalTokens: 100, Cost: 0.5}
+	require.NoError(t, svc.RecordUsage(ctx, record))
+	require.NoError(t, svc.RecordUsage(ctx, record), "a second call at the same level does not alert")
+
+	repo.AssertExpectations(t)
+	eb.AssertExpectations(t)
+}
+
+func TestAIUsageService_GetUsage(t *testing.T) {
+	repo := new(MockAIUsageRepo)
+	svc := newTestAIUsageService(repo, nil)
+	ctx := context.Background()
+	tenantID := types.NewID()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.On("Summarize", ctx, tenantID, from, to).Return([]identity.AIUsageBreakdown{
+		{Provider: "openai", UseCase: "pii_detection", Calls: 4, TotalTokens: 4000, Cost: 0.04},
+		{Provider: "anthropic", UseCase: "purpose_suggestion", Calls: 1, TotalTokens: 500, Cost: 0.01},
+	}, nil)
+	repo.On("GetBudget", ctx, tenantID).Return(nil, types.NewNotFoundError("ai budget", tenantID))
+
+	report, err := svc.GetUsage(ctx, tenantID, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Totals.Calls)
+	assert.Equal(t, int64(4500), report.Totals.TotalTokens)
+	assert.InDelta(t, 0.05, report.Totals.Cost, 1e-9)
+	assert.Len(t, report.Breakdown, 2)
+	assert.Nil(t, report.Budget)
+
+	_, err = svc.GetUsage(ctx, tenantID, to, from)
+	assert.Error(t, err)
+}
+
+func TestAIBudget_Status(t *testing.T) {
+	b := &identity.AIBudget{MonthlyTokenLimit: 1000, MonthlyCostLimit: 10}
+
+	assert.Equal(t, identity.AIBudgetOK, b.Status("2026-03", identity.AIUsageTotals{TotalTokens: 100, Cost: 1}).Level)
+	assert.Equal(t, identity.AIBudgetWarning, b.Status("2026-03", identity.AIUsageTotals{TotalTokens: 100, Cost: 8}).Level,
+		"the closer limit decides the level")
+	assert.Equal(t, identity.AIBudgetExceeded, b.Status("2026-03", identity.AIUsageTotals{TotalTokens: 1000}).Level)
+	assert.Equal(t, identity.AIBudgetOK, (&identity.AIBudget{}).Status("2026-03", identity.AIUsageTotals{TotalTokens: 1e9}).Level,
+		"no limits, no alerts")
+}
@@ -2,9 +2,8 @@ package analytics
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/complyark/datalens/internal/service/ai"
 )
@@ -12,21 +11,30 @@ import (
 // DarkPatternService analyzes content for dark patterns.
 type DarkPatternService struct {
 	aiGateway ai.Gateway
+	prompts   *ai.PromptRegistry
 }
 
 // NewDarkPatternService creates a new instance of DarkPatternService.
 func NewDarkPatternService(gateway ai.Gateway) *DarkPatternService {
 	return &DarkPatternService{
 		aiGateway: gateway,
+		prompts:   ai.NewPromptRegistry(nil, nil),
 	}
 }
 
+// SetPromptRegistry renders the analysis prompt from the registry's active
+// version instead of the builtin template.
+func (s *DarkPatternService) SetPromptRegistry(prompts *ai.PromptRegistry) {
+	s.prompts = prompts
+}
+
 // DarkPatternAnalysisResult holds the result of the analysis.
 type DarkPatternAnalysisResult struct {
 	DetectedPatterns []DarkPatternType `json:"detected_patterns"`
 	Confidence       float64           `json:"confidence"`
 	Explanation      string            `json:"explanation"`
 	CitedClause      string            `json:"cited_clause"`
+	PromptVersion    string            `json:"prompt_version,omitempty"`
 }
 
 // DarkPatternType represents the specific dark pattern found.
@@ -48,49 +56,73 @@ const (
 	DarkPatternRogueMalwares          DarkPatternType = "ROGUE_MALWARES"
 )
 
-// AnalyzeContent analyzes the given content for dark patterns.
-func (s *DarkPatternService) AnalyzeContent(ctx context.Context, contentType string, content string) (*DarkPatternAnalysisResult, error) {
-	// Construct the prompt using the template
-	// We use the gateway's Complete method which takes a raw prompt string.
-	// So we need to format the template ourselves or let the gateway handle it if it supported templates.
-	// The current Gateway.Complete takes a string.
-
-	// Use text/template used in prompts.go manually here?
-	// Or just do simple string replacement since it's simple enough.
-	// prompts.go has Go templates, so I should process it.
+// DarkPatternTypes returns every DarkPatternType.
+func DarkPatternTypes() []DarkPatternType {
+	return []DarkPatternType{
+		DarkPatternFalseUrgency, DarkPatternBasketSneaking, DarkPatternConfirmShaming,
+		DarkPatternForcedAction, DarkPatternSubscriptionTrap, DarkPatternInterfaceInterference,
+		DarkPatternBaitAndSwitch, DarkPatternDripPricing, DarkPatternDisguisedAdvertisement,
+		DarkPatternNagging, DarkPatternTrickQuestion, DarkPatternSaaSBilling, DarkPatternRogueMalwares,
+	}
+}
 
-	// Let's create a helper to process the template first.
-	// Actually, for simplicity and to avoid importing text/template every time, I'll specific format here
-	// But sticking to the pattern, lets match how others might use it.
-	// The other services "SuggestPurposes" likely does this internally in the Gateway or Service.
-	// I'll do it here.
+// darkPatternSchema is the JSON Schema of a dark pattern analysis.
+var darkPatternSchema = func() *ai.ResponseSchema {
+	patterns := make([]string, 0, len(DarkPatternTypes()))
+	for _, p := range DarkPatternTypes() {
+		patterns = append(patterns, string(p))
+	}
+	return &ai.ResponseSchema{
+		Name: "dark_pattern_analysis",
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"detected_patterns": map[string]any{
+					"type":  "array",
+					"items": map[string]any{"type": "string", "enum": patterns},
+				},
+				"confidence":   map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+				"explanation":  map[string]any{"type": "string"},
+				"cited_clause": map[string]any{"type": "string"},
+			},
+			"required": []string{"detected_patterns", "confidence"},
+		},
+	}
+}()
 
-	// Format the prompt
-	prompt := ai.DarkPatternPrompt
-	prompt = strings.ReplaceAll(prompt, "{{.ContentType}}", contentType)
-	prompt = strings.ReplaceAll(prompt, "{{.Content}}", content)
+// validateDarkPatternAnalysis coerces pattern names onto the known types
+// and the confidence into [0, 1].
+func validateDarkPatternAnalysis(r *DarkPatternAnalysisResult, v *ai.Validation) {
+	r.DetectedPatterns = ai.CoerceEnumList("detected_patterns", r.DetectedPatterns, DarkPatternTypes(), v)
+	r.Confidence = ai.CoerceConfidence(r.Confidence, v)
+}
 
-	resp, err := s.aiGateway.Complete(ctx, prompt, ai.CompletionOptions{
-		UseCase:     "dark_pattern_detection",
-		Priority:    "accuracy", // Sensitivity implies we want accuracy over speed? Or maybe speed for UI?
-		Temperature: 0.1,        // Low temperature for deterministic classification
-		MaxTokens:   1000,
+// AnalyzeContent analyzes the given content for dark patterns.
+func (s *DarkPatternService) AnalyzeContent(ctx context.Context, contentType string, content string) (*DarkPatternAnalysisResult, error) {
+	prompt, err := s.prompts.Render(ctx, ai.PromptDarkPattern, ai.DarkPatternPromptData{
+		ContentType: contentType,
+		Content:     content,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("ai gateway error: %w", err)
+		return nil, fmt.Errorf("render prompt: %w", err)
 	}
 
-	// Parse the response
-	var result DarkPatternAnalysisResult
-	if err := json.Unmarshal([]byte(resp.Response), &result); err != nil {
-		// Clean the response if it contains markdown code blocks
-		cleaned := strings.TrimPrefix(resp.Response, "```json")
-		cleaned = strings.TrimPrefix(cleaned, "```")
-		cleaned = strings.TrimSuffix(cleaned, "```")
-		if err := json.Unmarshal([]byte(cleaned), &result); err != nil {
-			return nil, fmt.Errorf("failed to parse AI response: %w. Response: %s", err, resp.Response)
-		}
+	// Parse and validate the response, re-asking on unknown patterns
+	result, _, err := ai.CompleteJSON(ctx, s.aiGateway, prompt.Text, ai.CompletionOptions{
+		UseCase:        "dark_pattern_detection",
+		SystemPrompt:   prompt.System,
+		Priority:       "accuracy", // Sensitivity implies we want accuracy over speed? Or maybe speed for UI?
+		Temperature:    0.1,        // Low temperature for deterministic classification
+		MaxTokens:      1000,
+		ResponseSchema: darkPatternSchema,
+	}, validateDarkPatternAnalysis)
+	if errors.Is(err, ai.ErrInvalidResponse) {
+		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ai gateway error: %w", err)
 	}
 
-	return &result, nil
+	result.PromptVersion = prompt.Version
+	return result, nil
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/complyark/datalens/internal/domain/audit"
+	"github.com/complyark/datalens/internal/infrastructure/connector/shared"
 	"github.com/complyark/datalens/pkg/types"
 )
 
@@ -79,6 +80,25 @@ func (s *AuditService) Log(
 	}()
 }
 
+// SecretResolved records the resolution of a data source credential
+// reference. It implements shared.SecretAuditor. The actor is the user on
+// the context, or the system (zero ID) for background scans.
+func (s *AuditService) SecretResolved(ctx context.Context, r shared.SecretResolution) {
+	userID, _ := types.UserIDFromContext(ctx)
+
+	action := "SECRET_RESOLVED"
+	details := map[string]any{
+		"ref":    r.Ref.String(),
+		"scheme": r.Ref.Scheme,
+		"cached": r.Cached,
+	}
+	if r.Err != nil {
+		action = "SECRET_RESOLUTION_FAILED"
+		details["error"] = r.Err.Error()
+	}
+	s.Log(ctx, userID, action, "DATA_SOURCE", r.DataSourceID, nil, details, r.TenantID)
+}
+
 // ListByTenant retrieves paginated, filtered audit logs for a tenant.
 func (s *AuditService) ListByTenant(ctx context.Context, tenantID types.ID, filters audit.AuditFilters, pagination types.Pagination) (*types.PaginatedResult[audit.AuditLog], error) {
 	return s.repo.ListByTenant(ctx, tenantID, filters, pagination)
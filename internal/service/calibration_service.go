@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// Calibration learning parameters.
+const (
+	// Method weights shrink each method's observed precision towards a
+	// prior, so a handful of reviews cannot swing a weight far.
+	calibrationPriorPrecision = 0.80
+	calibrationPriorStrength  = 10.0
+	minCalibrationWeight      = 0.25
+	maxCalibrationWeight      = 1.25
+
+	// Types need this many reviews before a threshold is learned. The
+	// threshold is the lowest confidence at which reviewed detections of the
+	// type reach the target precision.
+	minTypeFeedback     = 10
+	targetTypePrecision = 0.90
+	maxTypeThreshold    = 0.95
+
+	// A column/type pair is suppressed after this many rejections with no
+	// verification.
+	minColumnRejections = 3
+
+	calibrationPageSize = 500
+)
+
+// CalibrationService learns per-tenant detector calibration from detection
+// feedback and supplies it to scans.
+type CalibrationService struct {
+	feedbackRepo discovery.DetectionFeedbackRepository
+	repo         discovery.DetectionCalibrationRepository
+	tenantRepo   identity.TenantRepository
+	logger       *slog.Logger
+}
+
+// NewCalibrationService creates a new CalibrationService.
+func NewCalibrationService(
+	feedbackRepo discovery.DetectionFeedbackRepository,
+	repo discovery.DetectionCalibrationRepository,
+	tenantRepo identity.TenantRepository,
+	logger *slog.Logger,
+) *CalibrationService {
+	return &CalibrationService{
+		feedbackRepo: feedbackRepo,
+		repo:         repo,
+		tenantRepo:   tenantRepo,
+		logger:       logger.With("service", "calibration"),
+	}
+}
+
+// Calibrate recomputes and stores the tenant's calibration from all of its
+// feedback. The result applies from the tenant's next scan.
+func (s *CalibrationService) Calibrate(ctx context.Context, tenantID types.ID) (*discovery.DetectionCalibration, error) {
+	c, err := s.calibrate(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, types.NewValidationError("no detection feedback to calibrate from", nil)
+	}
+	return c, nil
+}
+
+// CalibrateAll recalibrates every active tenant that has feedback. It is
+// run periodically by the scheduler and returns the number of tenants
+// calibrated.
+func (s *CalibrationService) CalibrateAll(ctx context.Context) (int, error) {
+	tenants, err := s.tenantRepo.GetAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list tenants: %w", err)
+	}
+
+	calibrated := 0
+	for _, tenant := range tenants {
+		if tenant.Status != identity.TenantActive {
+			continue
+		}
+		c, err := s.calibrate(ctx, tenant.ID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "detection calibration failed", "tenant_id", tenant.ID, "error", err)
+			continue
+		}
+		if c != nil {
+			calibrated++
+		}
+	}
+	return calibrated, nil
+}
+
+// Get returns the tenant's current calibration and its report.
+func (s *CalibrationService) Get(ctx context.Context, tenantID types.ID) (*discovery.DetectionCalibration, error) {
+	return s.repo.GetByTenant(ctx, tenantID)
+}
+
+// CalibrationForTenant implements CalibrationProvider. It returns nil when
+// the tenant has not been calibrated.
+func (s *CalibrationService) CalibrationForTenant(ctx context.Context, tenantID types.ID) (*detection.Calibration, error) {
+	c, err := s.repo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toDetectionCalibration(c), nil
+}
+
+// calibrate returns nil without storing anything when the tenant has no
+// feedback.
+func (s *CalibrationService) calibrate(ctx context.Context, tenantID types.ID) (*discovery.DetectionCalibration, error) {
+	feedback, err := s.loadFeedback(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(feedback) == 0 {
+		return nil, nil
+	}
+
+	c := learnCalibration(feedback)
+	c.TenantID = tenantID
+	c.CalibratedAt = time.Now().UTC()
+	if err := s.repo.Upsert(ctx, c); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "detection calibration updated",
+		slog.String("tenant_id", tenantID.String()),
+		slog.Int("feedback", c.Report.FeedbackCount),
+		slog.Float64("precision_before", c.Report.PrecisionBefore),
+		slog.Float64("precision_after", c.Report.PrecisionAfter),
+		slog.Int("suppressed_columns", len(c.SuppressedColumns)),
+	)
+	return c, nil
+}
+
+func (s *CalibrationService) loadFeedback(ctx context.Context, tenantID types.ID) ([]discovery.DetectionFeedback, error) {
+	var all []discovery.DetectionFeedback
+	for page := 1; ; page++ {
+		result, err := s.feedbackRepo.GetByTenant(ctx, tenantID, types.Pagination{Page: page, PageSize: calibrationPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("load detection feedback: %w", err)
+		}
+		all = append(all, result.Items...)
+		if len(result.Items) < calibrationPageSize || len(all) >= result.Total {
+			return all, nil
+		}
+	}
+}
+
+// learnCalibration derives method weights, type thresholds and column
+// suppressions from feedback, then replays the feedback through them to
+// report precision before and after. A detection counts as correct only
+// when it was verified; corrections and rejections are false positives for
+// the original type.
+func learnCalibration(feedback []discovery.DetectionFeedback) *discovery.DetectionCalibration {
+	c := &discovery.DetectionCalibration{
+		StrategyWeights: make(map[types.DetectionMethod]float64),
+		TypeThresholds:  make(map[types.PIIType]float64),
+	}
+
+	// 1. Method weights from smoothed per-method precision.
+	methods := make(map[types.DetectionMethod]*discovery.MethodCalibration)
+	for _, fb := range feedback {
+		m, ok := methods[fb.OriginalMethod]
+		if !ok {
+			m = &discovery.MethodCalibration{Method: fb.OriginalMethod}
+			methods[fb.OriginalMethod] = m
+		}
+		switch fb.FeedbackType {
+		case discovery.FeedbackVerified:
+			m.Verified++
+		case discovery.FeedbackCorrected:
+			m.Corrected++
+		case discovery.FeedbackRejected:
+			m.Rejected++
+		}
+	}
+	for method, m := range methods {
+		total := m.Verified + m.Corrected + m.Rejected
+		m.Precision = ratio(m.Verified, total)
+		smoothed := (float64(m.Verified) + calibrationPriorStrength*calibrationPriorPrecision) /
+			(float64(total) + calibrationPriorStrength)
+		m.Weight = round3(clamp(smoothed/calibrationPriorPrecision, minCalibrationWeight, maxCalibrationWeight))
+		if m.Weight != 1.0 {
+			c.StrategyWeights[method] = m.Weight
+		}
+		c.Report.Methods = append(c.Report.Methods, *m)
+	}
+	sort.Slice(c.Report.Methods, func(i, j int) bool { return c.Report.Methods[i].Method < c.Report.Methods[j].Method })
+
+	weighted := &detection.Calibration{MethodWeights: c.StrategyWeights}
+	adjusted := func(fb discovery.DetectionFeedback) float64 {
+		return weighted.AdjustConfidence(fb.OriginalMethod, fb.OriginalConfidence)
+	}
+
+	// 2. Type thresholds on weight-adjusted confidence, which the detector
+	// compares each result against before merging.
+	byType := make(map[types.PIIType][]discovery.DetectionFeedback)
+	for _, fb := range feedback {
+		byType[fb.OriginalType] = append(byType[fb.OriginalType], fb)
+	}
+	for piiType, items := range byType {
+		if len(items) < minTypeFeedback {
+			continue
+		}
+		if t := learnThreshold(items, adjusted); t > 0 {
+			c.TypeThresholds[piiType] = t
+		}
+	}
+
+	// 3. Column suppressions.
+	type columnKey struct {
+		column  string
+		piiType types.PIIType
+	}
+	rejected := make(map[columnKey]int)
+	verified := make(map[columnKey]bool)
+	for _, fb := range feedback {
+		if fb.ColumnName == "" {
+			continue
+		}
+		key := columnKey{detection.NormalizeColumn(fb.ColumnName), fb.OriginalType}
+		switch fb.FeedbackType {
+		case discovery.FeedbackRejected:
+			rejected[key]++
+		case discovery.FeedbackVerified:
+			verified[key] = true
+		}
+	}
+	for key, n := range rejected {
+		if n >= minColumnRejections && !verified[key] {
+			c.SuppressedColumns = append(c.SuppressedColumns, discovery.SuppressedColumn{
+				Column: key.column, Type: key.piiType, Rejections: n,
+			})
+		}
+	}
+	sort.Slice(c.SuppressedColumns, func(i, j int) bool {
+		a, b := c.SuppressedColumns[i], c.SuppressedColumns[j]
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Type < b.Type
+	})
+
+	// 4. Replay the feedback through the calibration.
+	calibrated := toDetectionCalibration(c)
+	type tally struct{ total, verified, kept, keptVerified int }
+	overall := tally{}
+	perType := make(map[types.PIIType]*tally)
+	for _, fb := range feedback {
+		t, ok := perType[fb.OriginalType]
+		if !ok {
+			t = &tally{}
+			perType[fb.OriginalType] = t
+		}
+		isVerified := fb.FeedbackType == discovery.FeedbackVerified
+		kept := calibrated.Allows(fb.ColumnName, fb.OriginalType, adjusted(fb))
+		for _, tl := range []*tally{&overall, t} {
+			tl.total++
+			if isVerified {
+				tl.verified++
+			}
+			if kept {
+				tl.kept++
+				if isVerified {
+					tl.keptVerified++
+				}
+			}
+		}
+	}
+
+	c.Report.FeedbackCount = overall.total
+	c.Report.PrecisionBefore = round3(ratio(overall.verified, overall.total))
+	c.Report.PrecisionAfter = round3(ratio(overall.keptVerified, overall.kept))
+	c.Report.Retained = overall.kept
+	c.Report.RetainedVerified = overall.keptVerified
+	for piiType, t := range perType {
+		c.Report.Types = append(c.Report.Types, discovery.TypeCalibration{
+			Type:            piiType,
+			Feedback:        t.total,
+			Threshold:       c.TypeThresholds[piiType],
+			PrecisionBefore: round3(ratio(t.verified, t.total)),
+			PrecisionAfter:  round3(ratio(t.keptVerified, t.kept)),
+		})
+	}
+	sort.Slice(c.Report.Types, func(i, j int) bool { return c.Report.Types[i].Type < c.Report.Types[j].Type })
+
+	return c
+}
+
+// learnThreshold returns the lowest confidence at which the reviewed
+// detections reach targetTypePrecision, or, failing that, the one with the
+// best precision. It returns 0 when no threshold would filter anything.
+func learnThreshold(items []discovery.DetectionFeedback, confidence func(discovery.DetectionFeedback) float64) float64 {
+	type scored struct {
+		conf     float64
+		verified bool
+	}
+	all := make([]scored, len(items))
+	for i, fb := range items {
+		all[i] = scored{confidence(fb), fb.FeedbackType == discovery.FeedbackVerified}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].conf < all[j].conf })
+
+	// verifiedFrom[i] counts verified items at index i and above.
+	verifiedFrom := make([]int, len(all)+1)
+	for i := len(all) - 1; i >= 0; i-- {
+		verifiedFrom[i] = verifiedFrom[i+1]
+		if all[i].verified {
+			verifiedFrom[i]++
+		}
+	}
+
+	best, bestPrecision := 0.0, -1.0
+	for i := range all {
+		if i > 0 && all[i].conf == all[i-1].conf {
+			continue // same candidate threshold as i-1
+		}
+		precision := ratio(verifiedFrom[i], len(all)-i)
+		if precision >= targetTypePrecision {
+			best = all[i].conf
+			break
+		}
+		if precision > bestPrecision {
+			best, bestPrecision = all[i].conf, precision
+		}
+	}
+
+	best = round3(math.Min(best, maxTypeThreshold))
+	if best <= all[0].conf {
+		return 0
+	}
+	return best
+}
+
+func toDetectionCalibration(c *discovery.DetectionCalibration) *detection.Calibration {
+	out := &detection.Calibration{
+		MethodWeights:  c.StrategyWeights,
+		TypeThresholds: c.TypeThresholds,
+	}
+	for _, s := range c.SuppressedColumns {
+		out.SuppressedColumns = append(out.SuppressedColumns, detection.ColumnSuppression{Column: s.Column, Type: s.Type})
+	}
+	return out
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
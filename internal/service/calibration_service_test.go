@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/config"
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/internal/infrastructure/connector"
+	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// calibrationFeedback builds a feedback history where:
+//   - AI email detections are half wrong; the wrong ones are on "notes" at lower confidence
+//   - regex Aadhaar detections are always right
+//   - heuristic phone detections on "ref_no" are always rejected
+func calibrationFeedback(tenantID types.ID) []discovery.DetectionFeedback {
+	fb := func(ft discovery.FeedbackType, piiType types.PIIType, method types.DetectionMethod, conf float64, column string) discovery.DetectionFeedback {
+		return discovery.DetectionFeedback{
+			TenantID:           tenantID,
+			FeedbackType:       ft,
+			OriginalType:       piiType,
+			OriginalMethod:     method,
+			OriginalConfidence: conf,
+			ColumnName:         column,
+		}
+	}
+
+	var out []discovery.DetectionFeedback
+	for i := 0; i < 10; i++ {
+		out = append(out,
+			fb(discovery.FeedbackVerified, types.PIITypeEmail, types.DetectionMethodAI, 0.9, "email"),
+			fb(discovery.FeedbackRejected, types.PIITypeEmail, types.DetectionMethodAI, 0.6, "notes"),
+		)
+	}
+	for i := 0; i < 5; i++ {
+		out = append(out, fb(discovery.FeedbackVerified, types.PIITypeAadhaar, types.DetectionMethodRegex, 0.95, "aadhaar"))
+	}
+	for _, col := range []string{"ref_no", "REF_NO", "ref-no"} {
+		out = append(out, fb(discovery.FeedbackRejected, types.PIITypePhone, types.DetectionMethodHeuristic, 0.7, col))
+	}
+	return out
+}
+
+func TestLearnCalibration(t *testing.T) {
+	c := learnCalibration(calibrationFeedback(types.NewID()))
+
+	// Method weights: AI precision 0.5 shrinks to 0.75; regex rises above 1.
+	assert.InDelta(t, 0.75, c.StrategyWeights[types.DetectionMethodAI], 0.001)
+	assert.Greater(t, c.StrategyWeights[types.DetectionMethodRegex], 1.0)
+	assert.Less(t, c.StrategyWeights[types.DetectionMethodHeuristic], 1.0)
+
+	// EMAIL threshold sits between the rejected (0.45) and verified (0.675) detections.
+	assert.InDelta(t, 0.675, c.TypeThresholds[types.PIITypeEmail], 0.001)
+	_, ok := c.TypeThresholds[types.PIITypeAadhaar]
+	assert.False(t, ok, "types with too little feedback get no threshold")
+
+	// Repeated rejections on the same normalized column are suppressed.
+	assert.Equal(t, []discovery.SuppressedColumn{
+		{Column: "notes", Type: types.PIITypeEmail, Rejections: 10},
+		{Column: "refno", Type: types.PIITypePhone, Rejections: 3},
+	}, c.SuppressedColumns)
+
+	// The report replays feedback: 15 of 28 were right before, all retained ones after.
+	assert.Equal(t, 28, c.Report.FeedbackCount)
+	assert.InDelta(t, 0.536, c.Report.PrecisionBefore, 0.001)
+	assert.Equal(t, 1.0, c.Report.PrecisionAfter)
+	assert.Equal(t, 15, c.Report.Retained)
+	assert.Equal(t, 15, c.Report.RetainedVerified)
+	require.Len(t, c.Report.Types, 3)
+}
+
+func TestCalibrationService_CalibrateAll(t *testing.T) {
+	ctx := context.Background()
+	feedbackRepo := newMockDetectionFeedbackRepo()
+	calRepo := newMockDetectionCalibrationRepo()
+	tenantRepo := newMockTenantRepo()
+
+	withFeedback := &identity.Tenant{Name: "A", Domain: "a.local", Status: identity.TenantActive}
+	without := &identity.Tenant{Name: "B", Domain: "b.local", Status: identity.TenantActive}
+	require.NoError(t, tenantRepo.Create(ctx, withFeedback))
+	require.NoError(t, tenantRepo.Create(ctx, without))
+	for _, fb := range calibrationFeedback(withFeedback.ID) {
+		fb := fb
+		require.NoError(t, feedbackRepo.Create(ctx, &fb))
+	}
+
+	svc := NewCalibrationService(feedbackRepo, calRepo, tenantRepo, slog.Default())
+
+	n, err := svc.CalibrateAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stored, err := svc.Get(ctx, withFeedback.ID)
+	require.NoError(t, err)
+	assert.Equal(t, withFeedback.ID, stored.TenantID)
+	assert.False(t, stored.CalibratedAt.IsZero())
+
+	// Uncalibrated tenants scan with the shared detector.
+	cal, err := svc.CalibrationForTenant(ctx, without.ID)
+	require.NoError(t, err)
+	assert.Nil(t, cal)
+
+	_, err = svc.Calibrate(ctx, without.ID)
+	assert.ErrorIs(t, err, types.ErrValidation)
+}
+
+func TestDiscoveryService_ScanDataSource_AppliesCalibration(t *testing.T) {
+	// Setup Mocks
+	dsRepo := newMockDataSourceRepo()
+	piiRepo := newMockPIIClassificationRepo()
+	connectorMock := new(MockConnector)
+	mockStrategy := new(MockStrategy)
+	detector := detection.NewComposableDetector(mockStrategy)
+
+	registry := connector.NewConnectorRegistry(&config.Config{}, detector, nil)
+	testDSType := types.DataSourceType("TEST_MOCK")
+	registry.Register(testDSType, func() discovery.Connector {
+		return connectorMock
+	})
+
+	svc := NewDiscoveryService(dsRepo, newMockDataInventoryRepo(), newMockDataEntityRepo(), newMockDataFieldRepo(),
+		piiRepo, newMockScanRunRepo(), registry, detector, nil, newMockEventBus(), slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{TenantID: types.NewID()},
+		Name:         "Test DB",
+		Type:         testDSType,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+
+	// Reviewers have rejected PHONE on ref_no three times.
+	calRepo := newMockDetectionCalibrationRepo()
+	cal := learnCalibration(calibrationFeedback(ds.TenantID))
+	cal.TenantID = ds.TenantID
+	require.NoError(t, calRepo.Upsert(ctx, cal))
+	svc.SetCalibrationProvider(NewCalibrationService(newMockDetectionFeedbackRepo(), calRepo, newMockTenantRepo(), slog.Default()))
+
+	connectorMock.On("Connect", ctx, mock.Anything).Return(nil)
+	connectorMock.On("Close").Return(nil)
+	connectorMock.On("DiscoverSchema", ctx, mock.Anything).
+		Return(&discovery.DataInventory{}, []discovery.DataEntity{{Name: "orders", Type: discovery.EntityTypeTable}}, nil)
+	connectorMock.On("GetFields", ctx, "orders").Return([]discovery.DataField{{Name: "ref_no", DataType: "varchar"}}, nil)
+	connectorMock.On("SampleData", ctx, "orders", "ref_no", 10).Return([]string{"9876543210"}, nil)
+	mockStrategy.On("Detect", ctx, mock.Anything).Return([]detection.Result{{
+		Category:    types.PIICategoryContact,
+		Type:        types.PIITypePhone,
+		Sensitivity: types.SensitivityMedium,
+		Confidence:  0.9,
+		Method:      types.DetectionMethodHeuristic,
+	}}, nil)
+
+	// Execute
+	stats, err := svc.ScanDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+
+	// The suppressed detection is not recorded
+	assert.Equal(t, 0, stats.PIIDetected)
+	classifications, err := piiRepo.GetByDataSource(ctx, ds.ID, types.Pagination{})
+	require.NoError(t, err)
+	assert.Empty(t, classifications.Items)
+}
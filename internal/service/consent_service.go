@@ -37,6 +37,7 @@ type UpdateWidgetRequest struct {
 	Domain         *string               `json:"domain,omitempty"`
 	Config         *consent.WidgetConfig `json:"config,omitempty"`
 	AllowedOrigins *[]string             `json:"allowed_origins,omitempty"`
+	OverrideReason string                `json:"override_reason,omitempty"` // Apply despite HIGH dark-pattern findings
 }
 
 // RecordConsentRequest holds input for recording consent decisions.
@@ -93,6 +94,7 @@ type ConsentService struct {
 	widgetRepo  consent.ConsentWidgetRepository
 	sessionRepo consent.ConsentSessionRepository
 	historyRepo consent.ConsentHistoryRepository
+	auditor     *WidgetAuditService
 
 	eventBus   eventbus.EventBus
 	cache      cache.ConsentCache
@@ -125,6 +127,12 @@ func NewConsentService(
 	}
 }
 
+// SetWidgetAuditor audits widgets for dark patterns on activation and
+// config version bumps, blocking HIGH findings unless overridden.
+func (s *ConsentService) SetWidgetAuditor(auditor *WidgetAuditService) {
+	s.auditor = auditor
+}
+
 // =============================================================================
 // Widget CRUD
 // =============================================================================
@@ -222,13 +230,22 @@ func (s *ConsentService) UpdateWidget(ctx context.Context, id types.ID, req Upda
 		widget.AllowedOrigins = *req.AllowedOrigins
 	}
 
-	// Bump version on config change
+	// Bump version on config change; a live widget only takes a config
+	// that passes the dark-pattern audit, which is stored once the widget is
+	// saved.
+	save := func() error {
+		if err := s.widgetRepo.Update(ctx, widget); err != nil {
+			return fmt.Errorf("update widget: %w", err)
+		}
+		return nil
+	}
 	if configChanged {
 		widget.Version++
-	}
-
-	if err := s.widgetRepo.Update(ctx, widget); err != nil {
-		return nil, fmt.Errorf("update widget: %w", err)
+		if err := s.auditWidget(ctx, widget, consent.WidgetAuditConfigUpdate, req.OverrideReason, widget.Status == consent.WidgetStatusActive, save); err != nil {
+			return nil, err
+		}
+	} else if err := save(); err != nil {
+		return nil, err
 	}
 
 	s.logger.Info("consent widget updated",
@@ -299,15 +316,49 @@ func (s *ConsentService) DeleteWidget(ctx context.Context, id types.ID) error {
 
 // ActivateWidget sets a widget's status to ACTIVE.
 func (s *ConsentService) ActivateWidget(ctx context.Context, id types.ID) (*consent.ConsentWidget, error) {
-	return s.setWidgetStatus(ctx, id, consent.WidgetStatusActive)
+	return s.ActivateWidgetWithOverride(ctx, id, "")
+}
+
+// ActivateWidgetWithOverride sets a widget's status to ACTIVE, overriding
+// HIGH dark-pattern findings if a reason is given.
+func (s *ConsentService) ActivateWidgetWithOverride(ctx context.Context, id types.ID, overrideReason string) (*consent.ConsentWidget, error) {
+	return s.setWidgetStatus(ctx, id, consent.WidgetStatusActive, func(w *consent.ConsentWidget) error {
+		return s.auditWidget(ctx, w, consent.WidgetAuditActivation, overrideReason, true, nil)
+	})
 }
 
 // PauseWidget sets a widget's status to PAUSED.
 func (s *ConsentService) PauseWidget(ctx context.Context, id types.ID) (*consent.ConsentWidget, error) {
-	return s.setWidgetStatus(ctx, id, consent.WidgetStatusPaused)
+	return s.setWidgetStatus(ctx, id, consent.WidgetStatusPaused, nil)
 }
 
-func (s *ConsentService) setWidgetStatus(ctx context.Context, id types.ID, status consent.WidgetStatus) (*consent.ConsentWidget, error) {
+// ListWidgetAudits returns the dark-pattern audits of a widget, newest first.
+func (s *ConsentService) ListWidgetAudits(ctx context.Context, id types.ID) ([]consent.WidgetAudit, error) {
+	widget, err := s.GetWidget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.auditor == nil {
+		return []consent.WidgetAudit{}, nil
+	}
+	return s.auditor.ListAudits(ctx, widget.ID)
+}
+
+// auditWidget runs the dark-pattern audit if a widget auditor is set, and
+// save, if given, once the audit passes.
+func (s *ConsentService) auditWidget(ctx context.Context, w *consent.ConsentWidget, trigger consent.WidgetAuditTrigger, overrideReason string, enforce bool, save func() error) error {
+	if s.auditor == nil {
+		if save != nil {
+			return save()
+		}
+		return nil
+	}
+	_, err := s.auditor.Audit(ctx, w, trigger, overrideReason, enforce, save)
+	return err
+}
+
+// setWidgetStatus changes a widget's status once check, if any, passes.
+func (s *ConsentService) setWidgetStatus(ctx context.Context, id types.ID, status consent.WidgetStatus, check func(*consent.ConsentWidget) error) (*consent.ConsentWidget, error) {
 	tenantID, ok := types.TenantIDFromContext(ctx)
 	if !ok {
 		return nil, types.NewForbiddenError("tenant context required")
@@ -320,6 +371,11 @@ func (s *ConsentService) setWidgetStatus(ctx context.Context, id types.ID, statu
 	if widget.TenantID != tenantID {
 		return nil, types.NewNotFoundError("consent widget", id)
 	}
+	if check != nil {
+		if err := check(widget); err != nil {
+			return nil, err
+		}
+	}
 
 	widget.Status = status
 	if err := s.widgetRepo.Update(ctx, widget); err != nil {
@@ -14,6 +14,7 @@ type DashboardService struct {
 	dsRepo      discovery.DataSourceRepository
 	piiRepo     discovery.PIIClassificationRepository
 	scanRunRepo discovery.ScanRunRepository
+	reidRisks   discovery.ReidentificationRiskRepository
 	logger      *slog.Logger
 }
 
@@ -32,6 +33,23 @@ func NewDashboardService(
 	}
 }
 
+// SetReidentificationRiskRepo adds the re-identification risk summary to
+// the dashboard.
+func (s *DashboardService) SetReidentificationRiskRepo(repo discovery.ReidentificationRiskRepository) {
+	s.reidRisks = repo
+}
+
+// dashboardTopReidRisks is the number of riskiest entities listed.
+const dashboardTopReidRisks = 5
+
+// ReidentificationSummary ranks entities by re-identification risk so
+// pseudonymization work can be prioritized.
+type ReidentificationSummary struct {
+	AssessedEntities int                                    `json:"assessed_entities"`
+	ByLevel          map[string]int                         `json:"by_level"` // e.g. "HIGH": 3
+	TopEntities      []discovery.EntityReidentificationRisk `json:"top_entities"`
+}
+
 // DashboardStats holds aggregated metrics for the dashboard.
 type DashboardStats struct {
 	TotalDataSources int                 `json:"total_data_sources"`
@@ -41,6 +59,8 @@ type DashboardStats struct {
 	PIIByCategory    map[string]int      `json:"pii_by_category"` // e.g. "CONTACT": 5
 	RecentScans      []discovery.ScanRun `json:"recent_scans"`
 	PendingReviews   int                 `json:"pending_reviews"`
+
+	ReidentificationRisk *ReidentificationSummary `json:"reidentification_risk,omitempty"`
 }
 
 // GetStats returns aggregated statistics for the tenant.
@@ -101,5 +121,33 @@ func (s *DashboardService) GetStats(ctx context.Context, tenantID types.ID) (*Da
 		stats.PIIByCategory = piiCounts.ByCategory
 	}
 
+	// 5. Re-identification risk
+	if s.reidRisks != nil {
+		risks, err := s.reidRisks.GetReidentificationRisks(ctx, tenantID, 0, 0)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to get reidentification risks", "error", err)
+		} else {
+			stats.ReidentificationRisk = summarizeReidRisks(risks)
+		}
+	}
+
 	return stats, nil
 }
+
+// summarizeReidRisks counts entities per risk level and keeps the riskiest.
+// risks must be sorted by score, highest first.
+func summarizeReidRisks(risks []discovery.EntityReidentificationRisk) *ReidentificationSummary {
+	summary := &ReidentificationSummary{
+		AssessedEntities: len(risks),
+		ByLevel:          make(map[string]int),
+		TopEntities:      make([]discovery.EntityReidentificationRisk, 0, dashboardTopReidRisks),
+	}
+	for _, r := range risks {
+		summary.ByLevel[string(r.Risk.Level)]++
+	}
+	if len(risks) > dashboardTopReidRisks {
+		risks = risks[:dashboardTopReidRisks]
+	}
+	summary.TopEntities = append(summary.TopEntities, risks...)
+	return summary
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/complyark/datalens/internal/domain/discovery"
 	"github.com/complyark/datalens/internal/infrastructure/connector"
 	m365 "github.com/complyark/datalens/internal/infrastructure/connector/m365"
+	"github.com/complyark/datalens/internal/infrastructure/connector/shared"
 	"github.com/complyark/datalens/pkg/eventbus"
 	"github.com/complyark/datalens/pkg/types"
 )
@@ -55,6 +56,9 @@ func (s *DataSourceService) Create(ctx context.Context, in CreateDataSourceInput
 	if in.Type == "" {
 		return nil, types.NewValidationError("type is required", nil)
 	}
+	if err := validateCredentials(in.Credentials); err != nil {
+		return nil, err
+	}
 
 	ds := &discovery.DataSource{
 		Name:        in.Name,
@@ -180,6 +184,9 @@ func (s *DataSourceService) Update(ctx context.Context, in UpdateDataSourceInput
 		ds.Database = in.Database
 	}
 	if in.Credentials != "" {
+		if err := validateCredentials(in.Credentials); err != nil {
+			return nil, err
+		}
 		ds.Credentials = in.Credentials
 	}
 	if in.Config != "" {
@@ -266,6 +273,18 @@ func (s *DataSourceService) Delete(ctx context.Context, id types.ID) error {
 	return nil
 }
 
+// validateCredentials rejects malformed secret references up front rather
+// than at the first scan. Plain credentials are not inspected.
+func validateCredentials(creds string) error {
+	if !shared.IsSecretRef(creds) {
+		return nil
+	}
+	if _, err := shared.ParseSecretRef(creds); err != nil {
+		return types.NewValidationError(err.Error(), map[string]any{"field": "credentials"})
+	}
+	return nil
+}
+
 // ListM365Users retrieves a list of users from an M365 data source.
 // It requires the data source to be of type Microsoft365 (or OneDrive/Outlook).
 func (s *DataSourceService) ListM365Users(ctx context.Context, id types.ID) ([]m365.User, error) {
@@ -295,7 +314,7 @@ func (s *DataSourceService) ListM365Users(ctx context.Context, id types.ID) ([]m
 	// Yes, `m365.go` says `package connector`.
 	// So we can assertion using `connector.M365Connector`.
 
-	m365Conn, ok := conn.(*connector.M365Connector)
+	m365Conn, ok := connector.Unwrap(conn).(*connector.M365Connector)
 	if !ok {
 		return nil, fmt.Errorf("connector does not support user listing")
 	}
@@ -323,7 +342,7 @@ func (s *DataSourceService) ListM365Sites(ctx context.Context, id types.ID) ([]m
 		return nil, fmt.Errorf("get connector: %w", err)
 	}
 
-	m365Conn, ok := conn.(*connector.M365Connector)
+	m365Conn, ok := connector.Unwrap(conn).(*connector.M365Connector)
 	if !ok {
 		return nil, fmt.Errorf("connector does not support site listing")
 	}
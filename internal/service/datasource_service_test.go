@@ -77,6 +77,29 @@ func TestDataSourceService_Create_MissingType(t *testing.T) {
 	assert.Contains(t, err.Error(), "type")
 }
 
+func TestDataSourceService_Create_InvalidSecretRef(t *testing.T) {
+	svc, _, _ := newTestDataSourceService()
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, CreateDataSourceInput{
+		TenantID:    types.NewID(),
+		Name:        "My DB",
+		Type:        "POSTGRESQL",
+		Credentials: "secretref:s3://bucket/key",
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, types.ErrValidation)
+
+	ds, err := svc.Create(ctx, CreateDataSourceInput{
+		TenantID:    types.NewID(),
+		Name:        "My DB",
+		Type:        "POSTGRESQL",
+		Credentials: "secretref:vault://secret/data/datalens/db#password",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "secretref:vault://secret/data/datalens/db#password", ds.Credentials)
+}
+
 func TestDataSourceService_GetByID(t *testing.T) {
 	svc, _, _ := newTestDataSourceService()
 	ctx := context.Background()
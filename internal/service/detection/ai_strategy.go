@@ -24,7 +24,8 @@ import (
 //	  ├── HeuristicStrategy (column names, weight 0.4)
 //	  └── AIStrategy        ← this file (LLM, weight 0.8)
 //	          │
-//	          └── Gateway.DetectPII() → OpenAI / Anthropic / Ollama / ...
+//	          ├── Gateway.DetectPII()           → one column per call
+//	          └── BatchGateway.DetectPIIBatch() → all columns of an entity
 //
 // The AI strategy has the highest weight because LLMs provide the best
 // contextual understanding, but lower-weight strategies act as guardrails
@@ -83,12 +84,64 @@ func (s *AIStrategy) Detect(ctx context.Context, input Input) ([]Result, error)
 		return nil, fmt.Errorf("ai strategy: %w", err)
 	}
 
-	// If the AI says it's not PII, return no results
-	if !aiResult.IsPII {
-		return nil, nil
+	return aiResults(aiResult), nil
+}
+
+// DetectBatch classifies all columns of an entity in as few gateway calls
+// as the token budget allows. It returns ErrBatchUnsupported when the
+// gateway cannot batch, so the detector falls back to Detect per column.
+func (s *AIStrategy) DetectBatch(ctx context.Context, inputs []Input) ([][]Result, *BatchUsage, error) {
+	gateway, ok := s.gateway.(ai.BatchGateway)
+	if !ok || len(inputs) == 0 {
+		return nil, nil, ErrBatchUnsupported
+	}
+
+	batch := ai.PIIBatchInput{
+		TableName: inputs[0].TableName,
+		Industry:  inputs[0].Industry,
+		Columns:   make([]ai.PIIBatchColumn, len(inputs)),
+	}
+	for i, input := range inputs {
+		samples := input.SanitizedSamples
+		if len(samples) == 0 {
+			samples = input.Samples
+		}
+		batch.Columns[i] = ai.PIIBatchColumn{
+			ColumnName:       input.ColumnName,
+			DataType:         input.DataType,
+			SanitizedSamples: samples,
+		}
+	}
+
+	batchResult, err := gateway.DetectPIIBatch(ctx, batch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ai strategy: %w", err)
+	}
+
+	results := make([][]Result, len(inputs))
+	for i, input := range inputs {
+		if r, ok := batchResult.Results[input.ColumnName]; ok {
+			results[i] = aiResults(r)
+		}
+	}
+	usage := &BatchUsage{
+		Columns:      len(inputs),
+		Calls:        batchResult.Calls,
+		TokensUsed:   batchResult.TokensUsed,
+		TokensSaved:  batchResult.TokensSaved,
+		Duration:     batchResult.Duration,
+		LatencySaved: batchResult.LatencySaved,
+	}
+	return results, usage, nil
+}
+
+// aiResults converts a gateway result into detection Results; a column the
+// AI judged not to be PII yields none.
+func aiResults(aiResult *ai.PIIDetectionResult) []Result {
+	if aiResult == nil || !aiResult.IsPII {
+		return nil
 	}
 
-	// Convert AI result to detection Result
 	result := Result{
 		Category:    aiResult.Category,
 		Type:        aiResult.Type,
@@ -96,6 +149,8 @@ func (s *AIStrategy) Detect(ctx context.Context, input Input) ([]Result, error)
 		Confidence:  aiResult.Confidence,
 		Method:      types.DetectionMethodAI,
 		Reasoning:   aiResult.Reasoning,
+
+		PromptVersion: aiResult.PromptVersion,
 	}
 
 	// Validate category/type — AI sometimes returns unexpected values
@@ -106,7 +161,7 @@ func (s *AIStrategy) Detect(ctx context.Context, input Input) ([]Result, error)
 		result.Sensitivity = inferSensitivity(result.Category)
 	}
 
-	return []Result{result}, nil
+	return []Result{result}
 }
 
 // inferCategory maps a PIIType to its most likely PIICategory
@@ -133,6 +188,8 @@ func inferCategory(piiType types.PIIType) types.PIICategory {
 		types.PIITypeMedicalRecord: types.PIICategoryHealth,
 		types.PIITypePhoto:         types.PIICategoryIdentity,
 		types.PIITypeSignature:     types.PIICategoryIdentity,
+		types.PIITypeOrganization:  types.PIICategoryProfessional,
+		types.PIITypeCredential:    types.PIICategoryCredentials,
 	}
 
 	if cat, ok := categoryMap[piiType]; ok {
@@ -144,7 +201,7 @@ func inferCategory(piiType types.PIIType) types.PIICategory {
 // inferSensitivity maps a PIICategory to its default sensitivity level.
 func inferSensitivity(category types.PIICategory) types.SensitivityLevel {
 	switch category {
-	case types.PIICategoryBiometric, types.PIICategoryGenetic, types.PIICategoryHealth, types.PIICategoryMinor:
+	case types.PIICategoryBiometric, types.PIICategoryGenetic, types.PIICategoryHealth, types.PIICategoryMinor, types.PIICategoryCredentials:
 		return types.SensitivityCritical
 	case types.PIICategoryGovernmentID, types.PIICategoryFinancial:
 		return types.SensitivityHigh
@@ -162,13 +219,18 @@ func inferSensitivity(category types.PIICategory) types.SensitivityLevel {
 // =============================================================================
 
 // NewDefaultDetector creates a ComposableDetector with the standard strategy
-// stack: Pattern (regex), Heuristic (column names), and AI (LLM).
+// stack: Pattern (regex), Secret (credentials), Heuristic (column names),
+// Industry, Indic (multi-script text), NER (offline named entities) and AI
+// (LLM).
 // Pass nil for gateway to skip the AI strategy (offline mode).
 func NewDefaultDetector(gateway ai.Gateway) *ComposableDetector {
 	strategies := []Strategy{
 		NewPatternStrategy(),
+		NewSecretStrategy(),
 		NewHeuristicStrategy(),
 		NewIndustryStrategy(),
+		NewIndicStrategy(),
+		NewNERStrategy(nil),
 	}
 
 	if gateway != nil {
@@ -178,8 +240,9 @@ func NewDefaultDetector(gateway ai.Gateway) *ComposableDetector {
 	return NewComposableDetector(strategies...)
 }
 
-// NewOfflineDetector creates a detector with only pattern + heuristic
-// strategies (no AI). Useful for testing or when no LLM is configured.
+// NewOfflineDetector creates a detector with every strategy except AI:
+// nothing leaves the process. Use it when no LLM is configured or a tenant
+// forbids sending data to one; WithNERModel swaps in a custom NER model.
 func NewOfflineDetector() *ComposableDetector {
 	return NewDefaultDetector(nil)
 }
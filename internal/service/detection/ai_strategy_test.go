@@ -67,6 +67,8 @@ func TestAIStrategy_Detect(t *testing.T) {
 			Sensitivity: types.SensitivityMedium,
 			Confidence:  0.95,
 			Reasoning:   "Names identified",
+
+			PromptVersion: "pii_detection@v2",
 		}, nil)
 
 		results, err := strategy.Detect(ctx, input)
@@ -77,6 +79,7 @@ func TestAIStrategy_Detect(t *testing.T) {
 		assert.Equal(t, types.PIITypeName, results[0].Type)
 		assert.Equal(t, 0.95, results[0].Confidence)
 		assert.Equal(t, types.DetectionMethodAI, results[0].Method)
+		assert.Equal(t, "pii_detection@v2", results[0].PromptVersion)
 
 		mockGateway.AssertExpectations(t)
 	})
@@ -169,3 +172,109 @@ func TestAIStrategy_Detect(t *testing.T) {
 		assert.ErrorIs(t, err, context.Canceled)
 	})
 }
+
+// MockBatchGateway adds table-level batching to MockGateway.
+type MockBatchGateway struct {
+	MockGateway
+}
+
+func (m *MockBatchGateway) DetectPIIBatch(ctx context.Context, input ai.PIIBatchInput) (*ai.PIIBatchResult, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ai.PIIBatchResult), args.Error(1)
+}
+
+func TestAIStrategy_DetectBatch(t *testing.T) {
+	ctx := context.Background()
+	inputs := []detection.Input{
+		{TableName: "users", ColumnName: "full_name", Samples: []string{"John Doe"}},
+		{TableName: "users", ColumnName: "sku", Samples: []string{"SKU-1"}},
+		{TableName: "users", ColumnName: "notes"},
+	}
+
+	t.Run("batch_gateway", func(t *testing.T) {
+		gw := new(MockBatchGateway)
+		gw.On("DetectPIIBatch", ctx, mock.MatchedBy(func(in ai.PIIBatchInput) bool {
+			return in.TableName == "users" && len(in.Columns) == 3 && in.Columns[0].SanitizedSamples[0] == "John Doe"
+		})).Return(&ai.PIIBatchResult{
+			Results: map[string]*ai.PIIDetectionResult{
+				"full_name": {IsPII: true, Type: types.PIITypeName, Confidence: 0.9},
+				"sku":       {IsPII: false},
+			},
+			Calls:       1,
+			TokensUsed:  400,
+			TokensSaved: 250,
+		}, nil)
+
+		results, usage, err := detection.NewAIStrategy(gw, 0.8).DetectBatch(ctx, inputs)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		require.Len(t, results[0], 1)
+		assert.Equal(t, types.PIITypeName, results[0][0].Type)
+		assert.Equal(t, types.PIICategoryIdentity, results[0][0].Category)
+		assert.Empty(t, results[1])
+		assert.Empty(t, results[2], "columns missing from the response have no AI result")
+		assert.Equal(t, &detection.BatchUsage{Columns: 3, Calls: 1, TokensUsed: 400, TokensSaved: 250}, usage)
+		gw.AssertNotCalled(t, "DetectPII", mock.Anything, mock.Anything)
+	})
+
+	t.Run("plain_gateway", func(t *testing.T) {
+		_, _, err := detection.NewAIStrategy(new(MockGateway), 0.8).DetectBatch(ctx, inputs)
+		assert.ErrorIs(t, err, detection.ErrBatchUnsupported)
+	})
+
+	t.Run("gateway_error", func(t *testing.T) {
+		gw := new(MockBatchGateway)
+		gw.On("DetectPIIBatch", ctx, mock.Anything).Return(nil, errors.New("rate limited"))
+		_, _, err := detection.NewAIStrategy(gw, 0.8).DetectBatch(ctx, inputs)
+		assert.ErrorContains(t, err, "rate limited")
+	})
+}
+
+func TestComposableDetector_DetectBatch(t *testing.T) {
+	ctx := context.Background()
+	inputs := []detection.Input{
+		{TableName: "users", ColumnName: "email", Samples: []string{"a@example.com", "b@example.com"}},
+		{TableName: "users", ColumnName: "nickname", Samples: []string{"ace", "bee"}},
+	}
+
+	gw := new(MockBatchGateway)
+	gw.On("DetectPIIBatch", ctx, mock.Anything).Return(&ai.PIIBatchResult{
+		Results: map[string]*ai.PIIDetectionResult{
+			"email":    {IsPII: true, Type: types.PIITypeEmail, Category: types.PIICategoryContact, Confidence: 0.95},
+			"nickname": {IsPII: true, Type: types.PIITypeName, Confidence: 0.7},
+		},
+		Calls:      1,
+		TokensUsed: 200,
+	}, nil).Once()
+
+	detector := detection.NewDefaultDetector(gw)
+	reports, usage, err := detector.DetectBatch(ctx, inputs)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	gw.AssertExpectations(t)
+
+	// AI results merge with the per-column strategies as in Detect.
+	require.NotNil(t, reports[0].TopMatch)
+	assert.Equal(t, types.PIITypeEmail, reports[0].TopMatch.Type)
+	assert.Contains(t, reports[0].TopMatch.Methods, types.DetectionMethodAI)
+	assert.Contains(t, reports[0].TopMatch.Methods, types.DetectionMethodRegex)
+	require.NotNil(t, reports[1].TopMatch)
+	assert.Equal(t, types.PIITypeName, reports[1].TopMatch.Type)
+	assert.Equal(t, "nickname", reports[1].ColumnName)
+	assert.Len(t, reports[1].Strategies, 7)
+
+	require.NotNil(t, usage)
+	assert.Equal(t, 1, usage.Calls)
+	assert.Equal(t, 200, usage.TokensUsed)
+
+	// Without batching the detector falls back to one call per column.
+	plain := new(MockGateway)
+	plain.On("DetectPII", ctx, mock.Anything).Return(&ai.PIIDetectionResult{IsPII: false}, nil).Twice()
+	_, usage, err = detection.NewDefaultDetector(plain).DetectBatch(ctx, inputs)
+	require.NoError(t, err)
+	assert.Nil(t, usage)
+	plain.AssertExpectations(t)
+}
@@ -0,0 +1,109 @@
+package detection
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// =============================================================================
+// Entity-level Batch Detection
+// =============================================================================
+//
+// Most strategies are cheap per column, but the AI strategy pays a network
+// round-trip and a prompt preamble for every call. A BatchStrategy sees all
+// columns of an entity at once; ComposableDetector.DetectBatch runs it once
+// per entity and merges its per-column results with those of the other
+// strategies exactly as Detect would.
+
+// ErrBatchUnsupported is returned by BatchStrategy.DetectBatch when the
+// strategy cannot batch in its current configuration. The detector then
+// falls back to Detect per column.
+var ErrBatchUnsupported = errors.New("detection: strategy cannot batch")
+
+// BatchStrategy is implemented by strategies that can classify all columns
+// of an entity in one pass.
+type BatchStrategy interface {
+	Strategy
+
+	// DetectBatch returns one result slice per input, in input order, and
+	// what the batch cost.
+	DetectBatch(ctx context.Context, inputs []Input) ([][]Result, *BatchUsage, error)
+}
+
+// BatchUsage reports the cost of a batched strategy run and its estimated
+// savings over running the strategy per column.
+type BatchUsage struct {
+	Columns      int           `json:"columns"`
+	Calls        int           `json:"calls"`
+	TokensUsed   int           `json:"tokens_used"`
+	TokensSaved  int           `json:"tokens_saved"`
+	Duration     time.Duration `json:"duration"`
+	LatencySaved time.Duration `json:"latency_saved"`
+}
+
+// Add accumulates other into u. A nil other is ignored.
+func (u *BatchUsage) Add(other *BatchUsage) {
+	if other == nil {
+		return
+	}
+	u.Columns += other.Columns
+	u.Calls += other.Calls
+	u.TokensUsed += other.TokensUsed
+	u.TokensSaved += other.TokensSaved
+	u.Duration += other.Duration
+	u.LatencySaved += other.LatencySaved
+}
+
+// DetectBatch runs all strategies against the columns of one entity and
+// returns one report per input, in input order. Batch strategies run once
+// for the whole entity; the rest run per column. The returned usage sums the
+// batch strategies' usage and is nil when none of them batched.
+func (d *ComposableDetector) DetectBatch(ctx context.Context, inputs []Input) ([]*Report, *BatchUsage, error) {
+	runs := make([]*detectionRun, len(inputs))
+	for i, input := range inputs {
+		runs[i] = d.newRun(input.ColumnName)
+	}
+
+	var usage *BatchUsage
+	for _, strategy := range d.strategies {
+		if bs, ok := strategy.(BatchStrategy); ok && len(inputs) > 0 {
+			start := time.Now()
+			results, u, err := bs.DetectBatch(ctx, inputs)
+			if !errors.Is(err, ErrBatchUnsupported) {
+				// The batch's time is shared evenly across its columns.
+				share := time.Since(start) / time.Duration(len(inputs))
+				for i, run := range runs {
+					var columnResults []Result
+					if err == nil && i < len(results) {
+						columnResults = results[i]
+					}
+					d.record(run, strategy, columnResults, err, share)
+				}
+				if u != nil {
+					if usage == nil {
+						usage = &BatchUsage{}
+					}
+					usage.Add(u)
+				}
+				continue
+			}
+		}
+
+		for i, input := range inputs {
+			start := time.Now()
+			results, err := strategy.Detect(ctx, input)
+			d.record(runs[i], strategy, results, err, time.Since(start))
+		}
+	}
+
+	reports := make([]*Report, len(runs))
+	for i, run := range runs {
+		var elapsed time.Duration
+		for _, s := range run.report.Strategies {
+			elapsed += s.Duration
+		}
+		reports[i] = d.finish(run, elapsed)
+	}
+	return reports, usage, nil
+}
@@ -0,0 +1,257 @@
+package detection
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// Benchmark — Accuracy of a detector configuration on a labeled dataset
+// =============================================================================
+
+// BenchmarkDataset is a labeled set of columns. Cases with no expected type
+// are negatives: columns that must not be reported as PII.
+type BenchmarkDataset struct {
+	Name  string          `json:"name"`
+	Cases []BenchmarkCase `json:"cases"`
+}
+
+// BenchmarkCase is one column with its samples and expected classification.
+type BenchmarkCase struct {
+	Name             string            `json:"name"`
+	Input            Input             `json:"input"`
+	ExpectedType     types.PIIType     `json:"expected_type,omitempty"`
+	ExpectedCategory types.PIICategory `json:"expected_category,omitempty"`
+}
+
+//go:embed benchmark_columns.json
+var benchmarkColumns []byte
+
+// DefaultBenchmarkDataset returns the built-in dataset, built from the
+// seeder corpus.
+func DefaultBenchmarkDataset() (*BenchmarkDataset, error) {
+	return parseBenchmarkDataset(benchmarkColumns, "benchmark_columns.json")
+}
+
+// LoadBenchmarkDataset reads a dataset from a JSON file.
+func LoadBenchmarkDataset(path string) (*BenchmarkDataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read benchmark dataset: %w", err)
+	}
+	return parseBenchmarkDataset(data, path)
+}
+
+func parseBenchmarkDataset(data []byte, name string) (*BenchmarkDataset, error) {
+	var ds BenchmarkDataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, fmt.Errorf("decode benchmark dataset: %w", err)
+	}
+	if len(ds.Cases) == 0 {
+		return nil, fmt.Errorf("benchmark dataset %s has no cases", name)
+	}
+	return &ds, nil
+}
+
+// BenchmarkMetrics are counts and scores for one PII type, or micro-averaged
+// over all types.
+type BenchmarkMetrics struct {
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// BenchmarkScore summarizes one detector run over the dataset.
+type BenchmarkScore struct {
+	// Overall is micro-averaged over all PII types.
+	Overall BenchmarkMetrics                   `json:"overall"`
+	ByType  map[types.PIIType]BenchmarkMetrics `json:"by_type"`
+
+	// Accuracy is the share of cases whose top match (or absence of one)
+	// is exactly the expected type.
+	Accuracy float64 `json:"accuracy"`
+	// CategoryAccuracy is the share of cases with an expected category
+	// whose top match has that category.
+	CategoryAccuracy float64 `json:"category_accuracy"`
+
+	Misses []BenchmarkMiss `json:"misses,omitempty"`
+}
+
+// BenchmarkMiss is a case the detector got wrong. An empty type means no
+// PII was expected or reported.
+type BenchmarkMiss struct {
+	Case      string        `json:"case"`
+	Expected  types.PIIType `json:"expected,omitempty"`
+	Predicted types.PIIType `json:"predicted,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// BenchmarkReport is the full result, stable across runs so reports from
+// two commits can be diffed: maps marshal in key order, misses are sorted
+// and no timings are included.
+type BenchmarkReport struct {
+	Dataset    string                    `json:"dataset"`
+	Detector   string                    `json:"detector"`
+	Cases      int                       `json:"cases"`
+	Strategies []string                  `json:"strategies"`
+	Combined   BenchmarkScore            `json:"combined"`
+	ByStrategy map[string]BenchmarkScore `json:"by_strategy,omitempty"`
+}
+
+// RunBenchmark scores the detector on the dataset. With perStrategy set,
+// each strategy is also scored on its own, as a detector of one.
+func RunBenchmark(ctx context.Context, d *ComposableDetector, detectorName string, ds *BenchmarkDataset, perStrategy bool) (*BenchmarkReport, error) {
+	report := &BenchmarkReport{
+		Dataset:    ds.Name,
+		Detector:   detectorName,
+		Cases:      len(ds.Cases),
+		Strategies: d.StrategyNames(),
+	}
+
+	combined, err := scoreBenchmark(ctx, d, ds)
+	if err != nil {
+		return nil, err
+	}
+	report.Combined = *combined
+
+	if perStrategy {
+		report.ByStrategy = make(map[string]BenchmarkScore, len(d.strategies))
+		for _, s := range d.strategies {
+			single := &ComposableDetector{strategies: []Strategy{s}, calibration: d.calibration}
+			score, err := scoreBenchmark(ctx, single, ds)
+			if err != nil {
+				return nil, err
+			}
+			report.ByStrategy[s.Name()] = *score
+		}
+	}
+	return report, nil
+}
+
+func scoreBenchmark(ctx context.Context, d *ComposableDetector, ds *BenchmarkDataset) (*BenchmarkScore, error) {
+	score := &BenchmarkScore{ByType: make(map[types.PIIType]BenchmarkMetrics)}
+	counts := make(map[types.PIIType]*BenchmarkMetrics)
+	get := func(t types.PIIType) *BenchmarkMetrics {
+		if counts[t] == nil {
+			counts[t] = &BenchmarkMetrics{}
+		}
+		return counts[t]
+	}
+
+	correct, categoryCases, categoryCorrect := 0, 0, 0
+	for _, c := range ds.Cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var predicted types.PIIType
+		var predictedCategory types.PIICategory
+		var errText string
+		report, err := d.Detect(ctx, c.Input)
+		if err != nil {
+			errText = err.Error()
+		} else if report.IsPII && report.TopMatch != nil {
+			predicted = report.TopMatch.Type
+			predictedCategory = report.TopMatch.Category
+		}
+
+		if predicted != "" {
+			if predicted == c.ExpectedType {
+				get(predicted).TruePositives++
+			} else {
+				get(predicted).FalsePositives++
+			}
+		}
+		if c.ExpectedType != "" && predicted != c.ExpectedType {
+			get(c.ExpectedType).FalseNegatives++
+		}
+
+		if predicted == c.ExpectedType {
+			correct++
+		} else {
+			score.Misses = append(score.Misses, BenchmarkMiss{
+				Case: c.Name, Expected: c.ExpectedType, Predicted: predicted, Error: errText,
+			})
+		}
+		if c.ExpectedCategory != "" {
+			categoryCases++
+			if predictedCategory == c.ExpectedCategory {
+				categoryCorrect++
+			}
+		}
+	}
+
+	var overall BenchmarkMetrics
+	for t, m := range counts {
+		m.finish()
+		score.ByType[t] = *m
+		overall.TruePositives += m.TruePositives
+		overall.FalsePositives += m.FalsePositives
+		overall.FalseNegatives += m.FalseNegatives
+	}
+	overall.finish()
+	score.Overall = overall
+
+	score.Accuracy = ratio(correct, len(ds.Cases))
+	score.CategoryAccuracy = ratio(categoryCorrect, categoryCases)
+	sort.Slice(score.Misses, func(i, j int) bool { return score.Misses[i].Case < score.Misses[j].Case })
+	return score, nil
+}
+
+// finish computes precision, recall and F1 from the counts.
+func (m *BenchmarkMetrics) finish() {
+	m.Precision = ratio(m.TruePositives, m.TruePositives+m.FalsePositives)
+	m.Recall = ratio(m.TruePositives, m.TruePositives+m.FalseNegatives)
+	if m.Precision+m.Recall > 0 {
+		m.F1 = round4(2 * m.Precision * m.Recall / (m.Precision + m.Recall))
+	}
+}
+
+// ratio returns n/d rounded to four places, or 0 when d is 0.
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return round4(float64(n) / float64(d))
+}
+
+func round4(f float64) float64 {
+	return math.Round(f*10000) / 10000
+}
+
+// StrategyNames lists the detector's strategies in run order.
+func (d *ComposableDetector) StrategyNames() []string {
+	names := make([]string, len(d.strategies))
+	for i, s := range d.strategies {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// Only returns a detector that runs just the named strategies, keeping
+// calibration. It fails if a name does not match any strategy.
+func (d *ComposableDetector) Only(names ...string) (*ComposableDetector, error) {
+	byName := make(map[string]Strategy, len(d.strategies))
+	for _, s := range d.strategies {
+		byName[s.Name()] = s
+	}
+	strategies := make([]Strategy, 0, len(names))
+	for _, n := range names {
+		s, ok := byName[n]
+		if !ok {
+			return nil, fmt.Errorf("unknown strategy %q (have %v)", n, d.StrategyNames())
+		}
+		strategies = append(strategies, s)
+	}
+	return &ComposableDetector{strategies: strategies, calibration: d.calibration}, nil
+}
@@ -0,0 +1,125 @@
+package detection
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// columnStrategy reports a fixed type for each column name it knows.
+type columnStrategy struct {
+	name    string
+	columns map[string]types.PIIType
+}
+
+func (s *columnStrategy) Name() string                  { return s.name }
+func (s *columnStrategy) Method() types.DetectionMethod { return types.DetectionMethodHeuristic }
+func (s *columnStrategy) Weight() float64               { return 1 }
+func (s *columnStrategy) Detect(_ context.Context, input Input) ([]Result, error) {
+	t, ok := s.columns[input.ColumnName]
+	if !ok {
+		return nil, nil
+	}
+	return []Result{{Type: t, Category: inferCategory(t), Confidence: 0.9, Method: s.Method()}}, nil
+}
+
+func TestRunBenchmark_Metrics(t *testing.T) {
+	ds := &BenchmarkDataset{
+		Name: "tiny",
+		Cases: []BenchmarkCase{
+			{Name: "a", Input: Input{ColumnName: "email"}, ExpectedType: types.PIITypeEmail, ExpectedCategory: types.PIICategoryContact},
+			{Name: "b", Input: Input{ColumnName: "mail"}, ExpectedType: types.PIITypeEmail, ExpectedCategory: types.PIICategoryContact},
+			{Name: "c", Input: Input{ColumnName: "phone"}, ExpectedType: types.PIITypePhone, ExpectedCategory: types.PIICategoryContact},
+			{Name: "d", Input: Input{ColumnName: "status"}},
+		},
+	}
+	good := &columnStrategy{name: "good", columns: map[string]types.PIIType{
+		"email": types.PIITypeEmail,
+		"phone": types.PIITypePhone,
+	}}
+	noisy := &columnStrategy{name: "noisy", columns: map[string]types.PIIType{
+		"status": types.PIITypeName,
+		"mail":   types.PIITypeEmail,
+	}}
+
+	report, err := RunBenchmark(context.Background(), NewComposableDetector(good, noisy), "test", ds, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	email := report.Combined.ByType[types.PIITypeEmail]
+	if email.TruePositives != 2 || email.F1 != 1 {
+		t.Errorf("EMAIL = %+v, want 2 true positives and F1 1", email)
+	}
+	name := report.Combined.ByType[types.PIITypeName]
+	if name.FalsePositives != 1 || name.Precision != 0 {
+		t.Errorf("NAME = %+v, want one false positive", name)
+	}
+	if got := report.Combined.Overall; got.TruePositives != 3 || got.FalsePositives != 1 || got.FalseNegatives != 0 {
+		t.Errorf("overall = %+v", got)
+	}
+	if report.Combined.Accuracy != 0.75 || report.Combined.CategoryAccuracy != 1 {
+		t.Errorf("accuracy %v category %v", report.Combined.Accuracy, report.Combined.CategoryAccuracy)
+	}
+	wantMiss := []BenchmarkMiss{{Case: "d", Predicted: types.PIITypeName}}
+	if !reflect.DeepEqual(report.Combined.Misses, wantMiss) {
+		t.Errorf("misses = %+v, want %+v", report.Combined.Misses, wantMiss)
+	}
+
+	// On its own, the good strategy misses "b" but makes no false positives.
+	alone := report.ByStrategy["good"].Overall
+	if alone.TruePositives != 2 || alone.FalsePositives != 0 || alone.FalseNegatives != 1 {
+		t.Errorf("good strategy alone = %+v", alone)
+	}
+	if !reflect.DeepEqual(report.Strategies, []string{"good", "noisy"}) {
+		t.Errorf("strategies = %v", report.Strategies)
+	}
+}
+
+// TestBenchmark_OfflineCorpus guards the offline detector's accuracy on the
+// seeder corpus; run cmd/detbench for the full per-type report.
+func TestBenchmark_OfflineCorpus(t *testing.T) {
+	ds, err := DefaultBenchmarkDataset()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	report, err := RunBenchmark(ctx, NewOfflineDetector(), "offline", ds, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	overall := report.Combined.Overall
+	t.Logf("precision %.3f recall %.3f F1 %.3f", overall.Precision, overall.Recall, overall.F1)
+	if overall.F1 < 0.85 {
+		t.Errorf("offline F1 %.3f below 0.85; misses: %+v", overall.F1, report.Combined.Misses)
+	}
+
+	// Reports must be byte-identical between runs to be diffable.
+	again, err := RunBenchmark(ctx, NewOfflineDetector(), "offline", ds, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := json.Marshal(report)
+	second, _ := json.Marshal(again)
+	if string(first) != string(second) {
+		t.Error("benchmark report differs between identical runs")
+	}
+}
+
+func TestComposableDetector_Only(t *testing.T) {
+	d := NewOfflineDetector()
+	only, err := d.Only("pattern", "heuristic")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := only.StrategyNames(); !reflect.DeepEqual(got, []string{"pattern", "heuristic"}) {
+		t.Errorf("StrategyNames = %v", got)
+	}
+	if _, err := d.Only("nope"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
@@ -0,0 +1,88 @@
+package detection
+
+import (
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// Calibration tunes a detector for one tenant using what reviewers have
+// verified, corrected and rejected. A nil Calibration leaves detection
+// unchanged.
+type Calibration struct {
+	// MethodWeights scales each method's strategy weight and the confidence
+	// of its results. 1.0 (or a missing entry) leaves a method unchanged.
+	MethodWeights map[types.DetectionMethod]float64
+
+	// TypeThresholds is the minimum weight-adjusted confidence a strategy's
+	// result for a PII type needs to count towards a detection. It applies
+	// before results are merged, as that is the confidence it is learned on.
+	// Types without an entry are always reported.
+	TypeThresholds map[types.PIIType]float64
+
+	// SuppressedColumns lists column/type pairs reviewers keep rejecting.
+	SuppressedColumns []ColumnSuppression
+}
+
+// ColumnSuppression drops detections of Type on columns whose normalized
+// name equals Column. An empty Type suppresses every type on the column.
+type ColumnSuppression struct {
+	Column string        `json:"column"`
+	Type   types.PIIType `json:"type,omitempty"`
+}
+
+// NormalizeColumn returns the form of a column name used for suppression
+// matching: lower case with underscores, hyphens and spaces removed.
+func NormalizeColumn(name string) string {
+	return normalizeColumnName(name)
+}
+
+// MethodWeight returns the weight multiplier for a method.
+func (c *Calibration) MethodWeight(method types.DetectionMethod) float64 {
+	if c == nil {
+		return 1.0
+	}
+	if w, ok := c.MethodWeights[method]; ok && w > 0 {
+		return w
+	}
+	return 1.0
+}
+
+// AdjustConfidence applies the method weight to a single result's
+// confidence, capped at 1.0.
+func (c *Calibration) AdjustConfidence(method types.DetectionMethod, confidence float64) float64 {
+	confidence *= c.MethodWeight(method)
+	if confidence > 1.0 {
+		return 1.0
+	}
+	return confidence
+}
+
+// Threshold returns the minimum confidence for a PII type, or 0.
+func (c *Calibration) Threshold(piiType types.PIIType) float64 {
+	if c == nil {
+		return 0
+	}
+	return c.TypeThresholds[piiType]
+}
+
+// Suppressed reports whether detections of piiType on column are suppressed.
+func (c *Calibration) Suppressed(column string, piiType types.PIIType) bool {
+	if c == nil || len(c.SuppressedColumns) == 0 {
+		return false
+	}
+	norm := normalizeColumnName(column)
+	for _, s := range c.SuppressedColumns {
+		if s.Column == norm && (s.Type == "" || s.Type == piiType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether a weight-adjusted result on column survives the
+// calibration.
+func (c *Calibration) Allows(column string, piiType types.PIIType, confidence float64) bool {
+	if c.Suppressed(column, piiType) {
+		return false
+	}
+	return confidence >= c.Threshold(piiType)
+}
@@ -0,0 +1,94 @@
+package detection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// fixedStrategy returns the same results for every input.
+type fixedStrategy struct {
+	method  types.DetectionMethod
+	results []Result
+}
+
+func (s fixedStrategy) Name() string                  { return string(s.method) }
+func (s fixedStrategy) Method() types.DetectionMethod { return s.method }
+func (s fixedStrategy) Weight() float64               { return 0.9 }
+func (s fixedStrategy) Detect(context.Context, Input) ([]Result, error) {
+	return s.results, nil
+}
+
+func TestComposableDetector_WithCalibration(t *testing.T) {
+	heuristic := fixedStrategy{method: types.DetectionMethodHeuristic, results: []Result{
+		{Type: types.PIITypeName, Category: types.PIICategoryIdentity, Confidence: 0.8, Method: types.DetectionMethodHeuristic},
+		{Type: types.PIITypePhone, Category: types.PIICategoryContact, Confidence: 0.9, Method: types.DetectionMethodHeuristic},
+	}}
+	base := NewComposableDetector(heuristic)
+	ctx := context.Background()
+
+	calibrated := base.WithCalibration(&Calibration{
+		MethodWeights:     map[types.DetectionMethod]float64{types.DetectionMethodHeuristic: 0.5},
+		TypeThresholds:    map[types.PIIType]float64{types.PIITypeName: 0.5},
+		SuppressedColumns: []ColumnSuppression{{Column: "refnumber", Type: types.PIITypePhone}},
+	})
+
+	// Heuristic weight halves confidence; NAME (0.40) now falls below its threshold.
+	report, err := calibrated.Detect(ctx, Input{ColumnName: "customer"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Detections) != 1 || report.Detections[0].Type != types.PIITypePhone {
+		t.Fatalf("expected only PHONE to survive, got %+v", report.Detections)
+	}
+	if got := report.Detections[0].FinalConfidence; got < 0.449 || got > 0.451 {
+		t.Errorf("expected weighted confidence 0.45, got %.3f", got)
+	}
+
+	// Suppression matches the normalized column name.
+	report, _ = calibrated.Detect(ctx, Input{ColumnName: "Ref_Number"})
+	if report.IsPII {
+		t.Errorf("expected suppressed column to report no PII, got %+v", report.Detections)
+	}
+
+	// The shared detector is unaffected.
+	report, _ = base.Detect(ctx, Input{ColumnName: "Ref_Number"})
+	if len(report.Detections) != 2 {
+		t.Errorf("expected base detector to report both types, got %+v", report.Detections)
+	}
+}
+
+func TestComposableDetector_WithCalibration_ThresholdsEachResult(t *testing.T) {
+	heuristic := fixedStrategy{method: types.DetectionMethodHeuristic, results: []Result{
+		{Type: types.PIITypeName, Category: types.PIICategoryIdentity, Confidence: 0.8, Method: types.DetectionMethodHeuristic},
+	}}
+	regex := fixedStrategy{method: types.DetectionMethodRegex, results: []Result{
+		{Type: types.PIITypeName, Category: types.PIICategoryIdentity, Confidence: 0.9, Method: types.DetectionMethodRegex},
+	}}
+	calibrated := NewComposableDetector(heuristic, regex).WithCalibration(&Calibration{
+		MethodWeights:  map[types.DetectionMethod]float64{types.DetectionMethodHeuristic: 0.5},
+		TypeThresholds: map[types.PIIType]float64{types.PIITypeName: 0.5},
+	})
+
+	// The heuristic result (0.40) is below the threshold, so it neither lowers
+	// the merged confidence nor earns the multi-method boost.
+	report, err := calibrated.Detect(context.Background(), Input{ColumnName: "customer"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Detections) != 1 {
+		t.Fatalf("expected one detection, got %+v", report.Detections)
+	}
+	det := report.Detections[0]
+	if det.FinalConfidence != 0.9 || len(det.Methods) != 1 || det.Methods[0] != types.DetectionMethodRegex {
+		t.Errorf("expected the regex result alone at 0.90, got %.3f from %v", det.FinalConfidence, det.Methods)
+	}
+}
+
+func TestCalibration_Nil(t *testing.T) {
+	var c *Calibration
+	if c.MethodWeight(types.DetectionMethodAI) != 1.0 || c.Threshold(types.PIITypeEmail) != 0 || !c.Allows("email", types.PIITypeEmail, 0.1) {
+		t.Error("expected a nil calibration to leave detection unchanged")
+	}
+}
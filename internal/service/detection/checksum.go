@@ -0,0 +1,133 @@
+package detection
+
+import "strings"
+
+// Checksum algorithms a custom rule may require of its matches.
+const (
+	ChecksumLuhn     = "LUHN"     // Credit cards, IMEI and many account numbers
+	ChecksumVerhoeff = "VERHOEFF" // Aadhaar and other Indian identifiers
+	ChecksumMod97    = "MOD97"    // ISO 7064 MOD 97-10 (IBAN-style)
+)
+
+// ValidChecksum reports whether name is a supported checksum algorithm.
+func ValidChecksum(name string) bool {
+	switch strings.ToUpper(name) {
+	case ChecksumLuhn, ChecksumVerhoeff, ChecksumMod97:
+		return true
+	}
+	return false
+}
+
+// verifyChecksum applies the named algorithm to value. Separators (spaces
+// and hyphens) are ignored.
+func verifyChecksum(name, value string) bool {
+	value = stripSeparators(value)
+	switch strings.ToUpper(name) {
+	case ChecksumLuhn:
+		return luhnValid(value)
+	case ChecksumVerhoeff:
+		return verhoeffValid(value)
+	case ChecksumMod97:
+		return mod97Valid(value)
+	}
+	return false
+}
+
+func stripSeparators(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// luhnValid implements the Luhn mod 10 check over a digit string.
+func luhnValid(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+var (
+	verhoeffD = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffP = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+)
+
+// verhoeffValid implements the Verhoeff dihedral-group check over a digit
+// string whose last digit is the check digit.
+func verhoeffValid(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	c := 0
+	for i := 0; i < len(s); i++ {
+		ch := s[len(s)-1-i]
+		if ch < '0' || ch > '9' {
+			return false
+		}
+		c = verhoeffD[c][verhoeffP[i%8][ch-'0']]
+	}
+	return c == 0
+}
+
+// mod97Valid implements ISO 7064 MOD 97-10 as used by IBAN: the first four
+// characters move to the end, letters become 10–35, and the result must be
+// 1 mod 97.
+func mod97Valid(s string) bool {
+	if len(s) < 5 {
+		return false
+	}
+	s = strings.ToUpper(s[4:] + s[:4])
+	rem := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
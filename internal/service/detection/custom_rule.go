@@ -0,0 +1,205 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// CustomTypePrefix namespaces the PIIType of tenant-defined rules so a
+// custom label can never merge with a built-in type.
+const CustomTypePrefix = "CUSTOM:"
+
+// CustomPIIType returns the PIIType reported for a custom rule label.
+func CustomPIIType(label string) types.PIIType {
+	return types.PIIType(CustomTypePrefix + strings.ToUpper(label))
+}
+
+// CustomRule is a tenant-defined identifier, such as a customer ID or
+// policy number. A rule needs a pattern, column hints, or both.
+type CustomRule struct {
+	Name        string
+	TypeLabel   string
+	Category    types.PIICategory
+	Sensitivity types.SensitivityLevel
+	Pattern     string   // RE2 regex matched against sample values
+	ColumnHints []string // Column names (or fragments) that suggest this type
+	Validator   RuleValidator
+}
+
+// RuleValidator filters regex matches before they count towards detection.
+type RuleValidator struct {
+	Checksum  string   `json:"checksum,omitempty"` // One of the Checksum* constants
+	MinLength int      `json:"min_length,omitempty"`
+	MaxLength int      `json:"max_length,omitempty"`
+	Prefixes  []string `json:"prefixes,omitempty"`
+}
+
+// Validate checks a single match. Length and checksum are measured with
+// separators removed.
+func (v RuleValidator) Validate(match string) bool {
+	stripped := stripSeparators(match)
+	if v.MinLength > 0 && len(stripped) < v.MinLength {
+		return false
+	}
+	if v.MaxLength > 0 && len(stripped) > v.MaxLength {
+		return false
+	}
+	if len(v.Prefixes) > 0 {
+		ok := false
+		for _, p := range v.Prefixes {
+			if strings.HasPrefix(match, p) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if v.Checksum != "" && !verifyChecksum(v.Checksum, stripped) {
+		return false
+	}
+	return true
+}
+
+type compiledRule struct {
+	CustomRule
+	regex *regexp.Regexp
+	hints []string
+}
+
+// CustomRuleStrategy detects tenant-defined identifiers. Sample matches
+// that pass the rule's validator score like PatternStrategy; a column-name
+// hint alone scores like HeuristicStrategy, and both together add a small
+// boost.
+type CustomRuleStrategy struct {
+	rules []compiledRule
+}
+
+// NewCustomRuleStrategy compiles rules into a strategy.
+func NewCustomRuleStrategy(rules []CustomRule) (*CustomRuleStrategy, error) {
+	s := &CustomRuleStrategy{rules: make([]compiledRule, 0, len(rules))}
+	for _, r := range rules {
+		c := compiledRule{CustomRule: r}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid pattern: %w", r.Name, err)
+			}
+			c.regex = re
+		}
+		for _, h := range r.ColumnHints {
+			if n := normalizeColumnName(h); n != "" {
+				c.hints = append(c.hints, n)
+			}
+		}
+		if c.regex == nil && len(c.hints) == 0 {
+			return nil, fmt.Errorf("rule %q: a pattern or column hints are required", r.Name)
+		}
+		s.rules = append(s.rules, c)
+	}
+	return s, nil
+}
+
+func (s *CustomRuleStrategy) Name() string                  { return "custom_rules" }
+func (s *CustomRuleStrategy) Method() types.DetectionMethod { return types.DetectionMethodCustom }
+func (s *CustomRuleStrategy) Weight() float64               { return 0.95 }
+
+// Detect evaluates every rule against the column name and samples.
+func (s *CustomRuleStrategy) Detect(ctx context.Context, input Input) ([]Result, error) {
+	column := normalizeColumnName(input.ColumnName)
+
+	var results []Result
+	for _, r := range s.rules {
+		hinted := false
+		for _, h := range r.hints {
+			if strings.Contains(column, h) {
+				hinted = true
+				break
+			}
+		}
+
+		matched := 0
+		if r.regex != nil {
+			for _, sample := range input.Samples {
+				if r.matchesSample(sample) {
+					matched++
+				}
+			}
+		}
+
+		var confidence float64
+		var reasoning string
+		switch {
+		case matched > 0:
+			rate := float64(matched) / float64(len(input.Samples))
+			confidence = 0.90 * (0.7 + 0.3*rate)
+			reasoning = fmt.Sprintf("Custom rule %q matched %d of %d samples", r.Name, matched, len(input.Samples))
+			if hinted {
+				confidence += 0.05
+				reasoning += " and column name"
+			}
+		case hinted && (r.regex == nil || len(input.Samples) == 0):
+			// Without samples to contradict it, a hint is heuristic evidence.
+			confidence = 0.70
+			reasoning = fmt.Sprintf("Column name '%s' matches custom rule %q", input.ColumnName, r.Name)
+		default:
+			continue
+		}
+		if confidence > 0.99 {
+			confidence = 0.99
+		}
+
+		results = append(results, Result{
+			Category:    r.Category,
+			Type:        CustomPIIType(r.TypeLabel),
+			Sensitivity: r.Sensitivity,
+			Confidence:  confidence,
+			Method:      types.DetectionMethodCustom,
+			Reasoning:   reasoning,
+		})
+	}
+	return results, nil
+}
+
+// matchesSample reports whether any regex match in sample passes the
+// rule's validator.
+func (r compiledRule) matchesSample(sample string) bool {
+	for _, m := range r.regex.FindAllString(sample, 8) {
+		if r.Validator.Validate(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectSpans locates matches of pattern rules that pass their validator,
+// scored like a matching one-sample column. Hint-only rules need a column
+// name and find nothing in text.
+func (s *CustomRuleStrategy) DetectSpans(ctx context.Context, text string) ([]Span, error) {
+	var spans []Span
+	for _, r := range s.rules {
+		if r.regex == nil {
+			continue
+		}
+		for _, loc := range r.regex.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] || !r.Validator.Validate(text[loc[0]:loc[1]]) {
+				continue
+			}
+			spans = append(spans, Span{
+				Start:       loc[0],
+				End:         loc[1],
+				Category:    r.Category,
+				Type:        CustomPIIType(r.TypeLabel),
+				Sensitivity: r.Sensitivity,
+				Confidence:  0.90,
+				Method:      types.DetectionMethodCustom,
+			})
+		}
+	}
+	return spans, nil
+}
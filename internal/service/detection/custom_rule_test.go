@@ -0,0 +1,142 @@
+package detection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+func TestChecksums(t *testing.T) {
+	tests := []struct {
+		checksum string
+		value    string
+		want     bool
+	}{
+		{ChecksumLuhn, "4111 1111 1111 1111", true},
+		{ChecksumLuhn, "4111111111111112", false},
+		{ChecksumVerhoeff, "2341-2341-2346", true},
+		{ChecksumVerhoeff, "234123412345", false},
+		{ChecksumMod97, "GB82WEST12345698765432", true},
+		{ChecksumMod97, "GB82WEST12345698765433", false},
+		{"CRC32", "1234", false},
+	}
+	for _, tt := range tests {
+		if got := verifyChecksum(tt.checksum, tt.value); got != tt.want {
+			t.Errorf("verifyChecksum(%s, %q) = %v, want %v", tt.checksum, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCustomRuleStrategy_PatternWithValidator(t *testing.T) {
+	s, err := NewCustomRuleStrategy([]CustomRule{{
+		Name:        "Policy number",
+		TypeLabel:   "policy_number",
+		Category:    types.PIICategoryFinancial,
+		Sensitivity: types.SensitivityHigh,
+		Pattern:     `\bPOL-\d{8}\b`,
+		Validator:   RuleValidator{MinLength: 11, Prefixes: []string{"POL-1"}},
+	}})
+	if err != nil {
+		t.Fatalf("NewCustomRuleStrategy failed: %v", err)
+	}
+
+	results, err := s.Detect(context.Background(), Input{
+		ColumnName: "ref",
+		Samples:    []string{"POL-12345678", "see POL-10000001 attached", "POL-99999999", "none"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	r := results[0]
+	if r.Type != "CUSTOM:POLICY_NUMBER" {
+		t.Errorf("expected type CUSTOM:POLICY_NUMBER, got %s", r.Type)
+	}
+	if r.Method != types.DetectionMethodCustom || r.Category != types.PIICategoryFinancial || r.Sensitivity != types.SensitivityHigh {
+		t.Errorf("unexpected result mapping: %+v", r)
+	}
+	// 2 of 4 samples pass the prefix validator: 0.90 × (0.7 + 0.3 × 0.5)
+	if want := 0.765; r.Confidence < want-0.001 || r.Confidence > want+0.001 {
+		t.Errorf("expected confidence %.3f, got %.3f", want, r.Confidence)
+	}
+}
+
+func TestCustomRuleStrategy_ChecksumRejectsLookalikes(t *testing.T) {
+	s, err := NewCustomRuleStrategy([]CustomRule{{
+		Name:      "Member card",
+		TypeLabel: "MEMBER_CARD",
+		Pattern:   `\d{16}`,
+		Validator: RuleValidator{Checksum: ChecksumLuhn},
+	}})
+	if err != nil {
+		t.Fatalf("NewCustomRuleStrategy failed: %v", err)
+	}
+
+	results, _ := s.Detect(context.Background(), Input{Samples: []string{"1234567812345678", "1111222233334445"}})
+	if len(results) != 0 {
+		t.Errorf("expected no results for failing checksums, got %+v", results)
+	}
+	results, _ = s.Detect(context.Background(), Input{Samples: []string{"4111111111111111"}})
+	if len(results) != 1 {
+		t.Errorf("expected a result for a valid checksum, got %+v", results)
+	}
+}
+
+func TestCustomRuleStrategy_ColumnHints(t *testing.T) {
+	s, err := NewCustomRuleStrategy([]CustomRule{
+		{Name: "Employee code", TypeLabel: "EMPLOYEE_CODE", ColumnHints: []string{"emp_code", "employee-id"}},
+		{Name: "Customer ID", TypeLabel: "CUSTOMER_ID", Pattern: `^C\d{6}$`, ColumnHints: []string{"cust_id"}},
+	})
+	if err != nil {
+		t.Fatalf("NewCustomRuleStrategy failed: %v", err)
+	}
+	ctx := context.Background()
+
+	// Hint-only rule.
+	results, _ := s.Detect(ctx, Input{ColumnName: "HR_EMP_CODE"})
+	if len(results) != 1 || results[0].Type != "CUSTOM:EMPLOYEE_CODE" || results[0].Confidence != 0.70 {
+		t.Errorf("expected hint-only match at 0.70, got %+v", results)
+	}
+
+	// Pattern and hint together are boosted above the pattern alone.
+	withHint, _ := s.Detect(ctx, Input{ColumnName: "cust_id", Samples: []string{"C123456"}})
+	withoutHint, _ := s.Detect(ctx, Input{ColumnName: "ref", Samples: []string{"C123456"}})
+	if len(withHint) != 1 || len(withoutHint) != 1 || withHint[0].Confidence <= withoutHint[0].Confidence {
+		t.Errorf("expected hint to boost confidence: with=%+v without=%+v", withHint, withoutHint)
+	}
+
+	// Samples that contradict the pattern override the hint.
+	results, _ = s.Detect(ctx, Input{ColumnName: "cust_id", Samples: []string{"n/a"}})
+	if len(results) != 0 {
+		t.Errorf("expected no results when samples do not match, got %+v", results)
+	}
+}
+
+func TestNewCustomRuleStrategy_Invalid(t *testing.T) {
+	if _, err := NewCustomRuleStrategy([]CustomRule{{Name: "bad", Pattern: `(`}}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+	if _, err := NewCustomRuleStrategy([]CustomRule{{Name: "empty"}}); err == nil {
+		t.Error("expected error for rule without pattern or hints")
+	}
+}
+
+func TestComposableDetector_WithStrategies(t *testing.T) {
+	base := NewComposableDetector(NewPatternStrategy())
+	custom, _ := NewCustomRuleStrategy([]CustomRule{{Name: "Customer ID", TypeLabel: "CUSTOMER_ID", Pattern: `^C\d{6}$`}})
+
+	extended := base.WithStrategies(custom)
+	report, err := extended.Detect(context.Background(), Input{ColumnName: "ref", Samples: []string{"C123456"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.TopMatch == nil || report.TopMatch.Type != "CUSTOM:CUSTOMER_ID" {
+		t.Errorf("expected custom top match, got %+v", report.TopMatch)
+	}
+	if len(base.strategies) != 1 {
+		t.Errorf("expected base detector to be unchanged, has %d strategies", len(base.strategies))
+	}
+}
@@ -19,7 +19,8 @@ import (
 //	0.50–0.80 → MANUAL_REVIEW (human inspects)
 //	< 0.50  → LOW_CONFIDENCE (flagged)
 type ComposableDetector struct {
-	strategies []Strategy
+	strategies  []Strategy
+	calibration *Calibration
 }
 
 // taggedResult pairs a detection result with the strategy's weight.
@@ -36,50 +37,95 @@ func NewComposableDetector(strategies ...Strategy) *ComposableDetector {
 	}
 }
 
+// WithStrategies returns a detector that runs this detector's strategies
+// followed by extra ones, such as a tenant's custom rules for one scan.
+// The receiver is not modified.
+func (d *ComposableDetector) WithStrategies(extra ...Strategy) *ComposableDetector {
+	strategies := make([]Strategy, 0, len(d.strategies)+len(extra))
+	strategies = append(strategies, d.strategies...)
+	strategies = append(strategies, extra...)
+	return &ComposableDetector{strategies: strategies, calibration: d.calibration}
+}
+
+// WithCalibration returns a detector that applies a tenant's feedback
+// calibration: adjusted strategy weights, per-type confidence thresholds
+// and suppressed columns. The receiver is not modified.
+func (d *ComposableDetector) WithCalibration(c *Calibration) *ComposableDetector {
+	return &ComposableDetector{strategies: d.strategies, calibration: c}
+}
+
 // Detect runs all strategies against the input and produces a merged report.
 func (d *ComposableDetector) Detect(ctx context.Context, input Input) (*Report, error) {
 	start := time.Now()
-
-	report := &Report{
-		ColumnName: input.ColumnName,
-		Strategies: make([]StrategyOutcome, 0, len(d.strategies)),
-	}
-
-	// Collect all raw results from all strategies
-	var allResults []taggedResult
+	run := d.newRun(input.ColumnName)
 
 	for _, strategy := range d.strategies {
 		stratStart := time.Now()
 		results, err := strategy.Detect(ctx, input)
-		stratDuration := time.Since(stratStart)
-
-		outcome := StrategyOutcome{
-			Name:     strategy.Name(),
-			Method:   strategy.Method(),
-			Found:    len(results) > 0,
-			Results:  len(results),
-			Duration: stratDuration,
-		}
-		if err != nil {
-			outcome.Error = err.Error()
-		}
-		report.Strategies = append(report.Strategies, outcome)
+		d.record(run, strategy, results, err, time.Since(stratStart))
+	}
+
+	return d.finish(run, time.Since(start)), nil
+}
+
+// detectionRun collects one column's strategy outcomes and raw results
+// until they are merged into its report.
+type detectionRun struct {
+	report  *Report
+	results []taggedResult
+}
 
-		for _, r := range results {
-			allResults = append(allResults, taggedResult{result: r, weight: strategy.Weight()})
+func (d *ComposableDetector) newRun(column string) *detectionRun {
+	return &detectionRun{report: &Report{
+		ColumnName: column,
+		Strategies: make([]StrategyOutcome, 0, len(d.strategies)),
+	}}
+}
+
+// record adds one strategy's outcome for the run's column, applying the
+// tenant calibration to its weight and confidences and dropping results
+// below their type's threshold.
+func (d *ComposableDetector) record(run *detectionRun, strategy Strategy, results []Result, err error, duration time.Duration) {
+	outcome := StrategyOutcome{
+		Name:     strategy.Name(),
+		Method:   strategy.Method(),
+		Found:    len(results) > 0,
+		Results:  len(results),
+		Duration: duration,
+	}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+	run.report.Strategies = append(run.report.Strategies, outcome)
+
+	for _, r := range results {
+		weight := strategy.Weight()
+		if d.calibration != nil {
+			weight *= d.calibration.MethodWeight(r.Method)
+			r.Confidence = d.calibration.AdjustConfidence(r.Method, r.Confidence)
+			if r.Confidence < d.calibration.Threshold(r.Type) {
+				continue
+			}
 		}
+		run.results = append(run.results, taggedResult{result: r, weight: weight})
 	}
+}
 
-	if len(allResults) == 0 {
+// finish merges the run's results into its report.
+func (d *ComposableDetector) finish(run *detectionRun, duration time.Duration) *Report {
+	report := run.report
+	report.Duration = duration
+	if len(run.results) == 0 {
 		report.IsPII = false
-		report.Duration = time.Since(start)
-		return report, nil
+		return report
 	}
 
 	// Merge results: group by PIIType, then compute weighted confidence
-	report.Detections = d.mergeResults(allResults)
+	report.Detections = d.mergeResults(run.results)
+	if d.calibration != nil {
+		report.Detections = d.applyCalibration(report.ColumnName, report.Detections)
+	}
 	report.IsPII = len(report.Detections) > 0
-	report.Duration = time.Since(start)
 
 	// Set top match (highest confidence)
 	if len(report.Detections) > 0 {
@@ -87,7 +133,7 @@ func (d *ComposableDetector) Detect(ctx context.Context, input Input) (*Report,
 		report.TopMatch = &top
 	}
 
-	return report, nil
+	return report
 }
 
 // mergeResults groups raw results by PIIType and computes weighted confidence.
@@ -99,6 +145,7 @@ func (d *ComposableDetector) mergeResults(tagged []taggedResult) []MergedDetecti
 		sensitivity types.SensitivityLevel
 		methods     map[types.DetectionMethod]bool
 		reasoning   []string
+		prompt      string
 		weightedSum float64
 		totalWeight float64
 	}
@@ -119,6 +166,9 @@ func (d *ComposableDetector) mergeResults(tagged []taggedResult) []MergedDetecti
 		}
 		g.methods[r.Method] = true
 		g.reasoning = append(g.reasoning, r.Reasoning)
+		if g.prompt == "" {
+			g.prompt = r.PromptVersion
+		}
 		g.weightedSum += t.weight * r.Confidence
 		g.totalWeight += t.weight
 
@@ -156,6 +206,7 @@ func (d *ComposableDetector) mergeResults(tagged []taggedResult) []MergedDetecti
 			Methods:         methods,
 			Reasoning:       mergeReasoning(g.reasoning),
 			RequiresReview:  finalConf < 0.80,
+			PromptVersion:   g.prompt,
 		})
 	}
 
@@ -167,6 +218,18 @@ func (d *ComposableDetector) mergeResults(tagged []taggedResult) []MergedDetecti
 	return merged
 }
 
+// applyCalibration drops detections the tenant's calibration suppresses on
+// the column. Type thresholds were already applied to each result.
+func (d *ComposableDetector) applyCalibration(column string, detections []MergedDetection) []MergedDetection {
+	kept := detections[:0]
+	for _, det := range detections {
+		if !d.calibration.Suppressed(column, det.Type) {
+			kept = append(kept, det)
+		}
+	}
+	return kept
+}
+
 // boostMultiMethod increases confidence when multiple strategies agree.
 // 2 strategies agreeing: +5%, 3+: +10%.
 func boostMultiMethod(confidence float64, methodCount int) float64 {
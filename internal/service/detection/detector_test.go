@@ -104,7 +104,7 @@ func TestComposableDetector_AadhaarMultiMethod(t *testing.T) {
 	input := Input{
 		ColumnName: "aadhaar_number",
 		DataType:   "varchar",
-		Samples:    []string{"2345 6789 0123", "9876 5432 1098"},
+		Samples:    []string{"2341 2341 2346", "9876 5432 1096"}, // Verhoeff-valid
 	}
 
 	report, err := detector.Detect(context.Background(), input)
@@ -145,9 +145,9 @@ func TestComposableDetector_StrategyOutcomes(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// Should have outcomes for all strategies (pattern + heuristic + industry)
-	if len(report.Strategies) != 3 {
-		t.Errorf("expected 3 strategy outcomes, got %d", len(report.Strategies))
+	// Should have outcomes for all strategies (pattern + secret + heuristic + industry + indic + ner)
+	if len(report.Strategies) != 6 {
+		t.Errorf("expected 6 strategy outcomes, got %d", len(report.Strategies))
 	}
 
 	// Verify strategy outcomes are recorded
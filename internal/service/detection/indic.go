@@ -0,0 +1,286 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// IndicStrategy detects PII written in Indian scripts (Devanagari, Bengali,
+// Tamil, Telugu and others) and in transliterated "Hinglish" text, which
+// the pattern and heuristic strategies cannot read. Samples are normalized
+// first — Unicode composition, joiners removed, Indic digits mapped to
+// ASCII — and then checked for:
+//   - phone numbers, Aadhaar numbers and PIN codes written in Indic digits
+//   - addresses, by PIN code, state and district gazetteers and address words
+//   - personal names, by a gazetteer of common given names, surnames and
+//     honorifics in native script and transliteration
+//
+// Base confidence: 0.80–0.95 by type, scaled by the share of samples matched.
+type IndicStrategy struct{}
+
+// NewIndicStrategy creates a new Indic multi-script detection strategy.
+func NewIndicStrategy() *IndicStrategy {
+	return &IndicStrategy{}
+}
+
+func (s *IndicStrategy) Name() string                  { return "indic" }
+func (s *IndicStrategy) Method() types.DetectionMethod { return types.DetectionMethodIndic }
+func (s *IndicStrategy) Weight() float64               { return 0.85 }
+
+// minIndicMatchRate is the share of samples that must match a type for it
+// to be reported. Free text columns mention names and places now and then;
+// a column of them mentions one in most rows.
+const minIndicMatchRate = 0.3
+
+// indicKind describes one PII type this strategy reports.
+type indicKind struct {
+	piiType     types.PIIType
+	category    types.PIICategory
+	sensitivity types.SensitivityLevel
+	base        float64
+	label       string
+}
+
+var (
+	indicAadhaar = indicKind{types.PIITypeAadhaar, types.PIICategoryGovernmentID, types.SensitivityCritical, 0.95, "Aadhaar numbers in Indic digits"}
+	indicPhone   = indicKind{types.PIITypePhone, types.PIICategoryContact, types.SensitivityMedium, 0.90, "phone numbers in Indic digits"}
+	indicPIN     = indicKind{types.PIITypeAddress, types.PIICategoryContact, types.SensitivityLow, 0.85, "PIN codes in Indic digits"}
+	indicAddress = indicKind{types.PIITypeAddress, types.PIICategoryContact, types.SensitivityMedium, 0.85, "Indian addresses"}
+	indicName    = indicKind{types.PIITypeName, types.PIICategoryIdentity, types.SensitivityLow, 0.80, "Indian personal names"}
+)
+
+var (
+	indicMobileRe = regexp.MustCompile(`^(?:\+?91|0)?[6-9]\d{9}$`)
+	indicPINRe    = regexp.MustCompile(`^[1-9]\d{5}$`)
+	pinInTextRe   = regexp.MustCompile(`(?:^|\D)[1-9]\d{2}\s?\d{3}(?:\D|$)`)
+)
+
+// Detect classifies each sample as at most one Indic PII type and reports
+// the types that cover enough of the samples.
+func (s *IndicStrategy) Detect(ctx context.Context, input Input) ([]Result, error) {
+	type tally struct {
+		kind    indicKind
+		matches int
+	}
+	counts := make(map[indicKind]*tally)
+	scripts := make(map[string]bool)
+	total := 0
+
+	for _, sample := range input.Samples {
+		if strings.TrimSpace(sample) == "" {
+			continue
+		}
+		total++
+
+		kind, ok := classifyIndicSample(sample)
+		if !ok {
+			continue
+		}
+		if counts[kind] == nil {
+			counts[kind] = &tally{kind: kind}
+		}
+		counts[kind].matches++
+		scripts[dominantScript(sample)] = true
+	}
+
+	if total == 0 || len(counts) == 0 {
+		return nil, nil
+	}
+
+	// PIN-only samples and full addresses are both ADDRESS; when a column
+	// has both, count them together so the type is reported once.
+	if pin, ok := counts[indicPIN]; ok {
+		if addr, ok := counts[indicAddress]; ok {
+			addr.matches += pin.matches
+			delete(counts, indicPIN)
+		}
+	}
+
+	scriptNames := make([]string, 0, len(scripts))
+	for name := range scripts {
+		scriptNames = append(scriptNames, name)
+	}
+	sort.Strings(scriptNames)
+
+	var results []Result
+	for _, t := range counts {
+		rate := float64(t.matches) / float64(total)
+		if rate < minIndicMatchRate {
+			continue
+		}
+		results = append(results, Result{
+			Category:    t.kind.category,
+			Type:        t.kind.piiType,
+			Sensitivity: t.kind.sensitivity,
+			Confidence:  t.kind.base * (0.7 + 0.3*rate),
+			Method:      types.DetectionMethodIndic,
+			Reasoning: fmt.Sprintf("%d of %d samples look like %s (%s)",
+				t.matches, total, t.kind.label, strings.Join(scriptNames, ", ")),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Confidence > results[j].Confidence })
+	return results, nil
+}
+
+// classifyIndicSample decides which Indic PII type, if any, a sample is.
+// Numeric identifiers are only claimed when written in Indic digits; ASCII
+// digits are left to the pattern strategy.
+func classifyIndicSample(sample string) (indicKind, bool) {
+	normalized := NormalizeIndic(sample)
+
+	if hasIndicDigits(sample) {
+		compact := stripSeparators(normalized)
+		switch {
+		case ValidAadhaar(compact):
+			return indicAadhaar, true
+		case indicMobileRe.MatchString(compact):
+			return indicPhone, true
+		case indicPINRe.MatchString(compact):
+			return indicPIN, true
+		}
+	}
+
+	tokens := indicTokens(normalized)
+	if len(tokens) == 0 {
+		return indicKind{}, false
+	}
+	if looksLikeIndicAddress(normalized, tokens) {
+		return indicAddress, true
+	}
+	if looksLikeIndicName(tokens) {
+		return indicName, true
+	}
+	return indicKind{}, false
+}
+
+// looksLikeIndicAddress requires two of three signals: a PIN code, a known
+// state or district, and an address word such as "nagar" or "मार्ग".
+func looksLikeIndicAddress(normalized string, tokens []string) bool {
+	if len(tokens) < 3 {
+		return false
+	}
+	signals := 0
+	if pinInTextRe.MatchString(normalized) {
+		signals++
+	}
+	if indicGazetteer.hasPlace(tokens) {
+		signals++
+	}
+	if indicGazetteer.hasAny(tokens, indicGazetteer.addressWords) {
+		signals++
+	}
+	return signals >= 2
+}
+
+// looksLikeIndicName accepts one to four purely alphabetic words where at
+// least one is a known given name, surname or honorific.
+func looksLikeIndicName(tokens []string) bool {
+	if len(tokens) > 4 {
+		return false
+	}
+	for _, tok := range tokens {
+		for _, r := range tok {
+			if !unicode.IsLetter(r) && !unicode.IsMark(r) {
+				return false
+			}
+		}
+	}
+	return indicGazetteer.hasAny(tokens, indicGazetteer.nameWords)
+}
+
+// =============================================================================
+// Script Normalization
+// =============================================================================
+
+// indicScripts are the Indian scripts dominantScript can name.
+var indicScripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Devanagari", unicode.Devanagari},
+	{"Bengali", unicode.Bengali},
+	{"Gurmukhi", unicode.Gurmukhi},
+	{"Gujarati", unicode.Gujarati},
+	{"Odia", unicode.Oriya},
+	{"Tamil", unicode.Tamil},
+	{"Telugu", unicode.Telugu},
+	{"Kannada", unicode.Kannada},
+	{"Malayalam", unicode.Malayalam},
+}
+
+// NormalizeIndic prepares text in any Indian script for matching: it
+// composes characters (NFC), removes zero-width joiners, maps Indic digits
+// to ASCII, lower-cases Latin letters and collapses whitespace.
+func NormalizeIndic(s string) string {
+	s = norm.NFC.String(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '\u200c' || r == '\u200d': // ZWNJ, ZWJ
+			continue
+		case indicDigitValue(r) >= 0:
+			b.WriteByte(byte('0' + indicDigitValue(r)))
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// indicDigitValue returns the value of a digit from one of the Brahmic
+// script blocks (U+0900–U+0DFF), or -1. Each block places its zero at
+// offset 0x66.
+func indicDigitValue(r rune) int {
+	if r < 0x0900 || r > 0x0DFF {
+		return -1
+	}
+	off := int(r & 0x7F)
+	if off < 0x66 || off > 0x6F {
+		return -1
+	}
+	return off - 0x66
+}
+
+func hasIndicDigits(s string) bool {
+	for _, r := range s {
+		if indicDigitValue(r) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dominantScript names the Indic script with the most letters in s, or
+// "Latin" when there are none.
+func dominantScript(s string) string {
+	best, bestCount := "Latin", 0
+	for _, sc := range indicScripts {
+		n := 0
+		for _, r := range s {
+			if unicode.Is(sc.table, r) {
+				n++
+			}
+		}
+		if n > bestCount {
+			best, bestCount = sc.name, n
+		}
+	}
+	return best
+}
+
+// indicTokens splits normalized text into words, keeping combining marks
+// (vowel signs, viramas) with their letters.
+func indicTokens(normalized string) []string {
+	return strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsDigit(r)
+	})
+}
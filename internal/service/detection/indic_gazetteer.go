@@ -0,0 +1,212 @@
+package detection
+
+import (
+	"strings"
+)
+
+// indicGazetteer holds the word lists IndicStrategy matches against. Every
+// entry is stored in NormalizeIndic form, so native-script entries match
+// regardless of how the source text was composed.
+var indicGazetteer = newIndicWordLists()
+
+type indicWordLists struct {
+	nameWords    map[string]bool
+	addressWords map[string]bool
+	// places holds states, union territories and major districts/cities.
+	// Multi-word places ("tamil nadu", "उत्तर प्रदेश") are matched as
+	// phrases.
+	places map[string]bool
+}
+
+func newIndicWordLists() *indicWordLists {
+	return &indicWordLists{
+		nameWords:    indicWordSet(indicNameWords),
+		addressWords: indicWordSet(indicAddressWords),
+		places:       indicWordSet(indicStates, indicDistricts),
+	}
+}
+
+func indicWordSet(lists ...[]string) map[string]bool {
+	set := make(map[string]bool)
+	for _, list := range lists {
+		for _, w := range list {
+			set[NormalizeIndic(w)] = true
+		}
+	}
+	return set
+}
+
+// hasAny reports whether any token is in the set.
+func (g *indicWordLists) hasAny(tokens []string, set map[string]bool) bool {
+	for _, tok := range tokens {
+		if set[tok] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPlace reports whether the tokens contain a known place name, trying
+// three-, two- and one-word phrases.
+func (g *indicWordLists) hasPlace(tokens []string) bool {
+	for n := 3; n >= 1; n-- {
+		for i := 0; i+n <= len(tokens); i++ {
+			if g.places[strings.Join(tokens[i:i+n], " ")] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// indicNameWords are common given names, surnames and honorifics across
+// regions, in transliteration and native script. The list favours surnames:
+// most Indian full names carry a common one.
+var indicNameWords = []string{
+	// Honorifics
+	"shri", "shree", "sri", "smt", "shrimati", "kumari", "thiru", "thirumathi", "selvi",
+	"श्री", "श्रीमती", "सुश्री", "कुमारी", "শ্রী", "শ্রীমতী", "திரு", "திருமதி", "శ్రీ", "ಶ್ರೀ", "ശ്രീ",
+
+	// Surnames — transliterated
+	"sharma", "verma", "varma", "singh", "kaur", "kumar", "kumari", "gupta", "patel", "shah",
+	"mehta", "desai", "joshi", "mishra", "pandey", "tiwari", "tripathi", "dubey", "shukla",
+	"srivastava", "saxena", "agarwal", "aggarwal", "jain", "yadav", "chauhan", "rathore",
+	"thakur", "chaudhary", "choudhury", "reddy", "rao", "naidu", "nair", "menon", "pillai",
+	"iyer", "iyengar", "krishnan", "subramanian", "raman", "swamy", "murthy", "gowda", "hegde",
+	"shetty", "kulkarni", "deshpande", "patil", "pawar", "naik", "das", "ghosh", "bose",
+	"banerjee", "chatterjee", "mukherjee", "chakraborty", "bhattacharya", "sen", "dutta",
+	"roy", "saha", "mondal", "biswas", "pal", "khan", "ahmed", "hussain", "ansari",
+	"siddiqui", "qureshi", "sheikh", "fernandes", "dsouza", "gill", "sandhu", "sidhu",
+	"malhotra", "kapoor", "khanna", "chopra", "arora", "bhatia", "bajaj", "bhat", "devi",
+
+	// Given names — transliterated
+	"rajesh", "ramesh", "suresh", "mahesh", "ganesh", "dinesh", "mukesh", "rakesh", "amit",
+	"rahul", "vijay", "sanjay", "ajay", "arjun", "ravi", "anil", "sunil", "deepak", "manoj",
+	"ashok", "arun", "anand", "vivek", "abhishek", "karthik", "senthil", "murugan",
+	"venkatesh", "srinivas", "sourav", "subhash", "imran", "salman", "gurpreet", "harpreet",
+	"manpreet", "priya", "pooja", "neha", "anjali", "ananya", "divya", "swati", "sunita",
+	"anita", "kavita", "rekha", "meena", "geeta", "sita", "lakshmi", "sridevi", "bharathi",
+	"fatima", "ayesha",
+
+	// Devanagari
+	"शर्मा", "वर्मा", "सिंह", "कुमार", "गुप्ता", "पटेल", "शाह", "मेहता", "जोशी", "मिश्रा",
+	"पांडेय", "पाण्डेय", "तिवारी", "त्रिपाठी", "दुबे", "शुक्ला", "श्रीवास्तव", "सक्सेना",
+	"अग्रवाल", "जैन", "यादव", "चौहान", "ठाकुर", "चौधरी", "देवी", "खान", "पाटिल", "कुलकर्णी",
+	"राम", "रमेश", "सुरेश", "राजेश", "महेश", "अमित", "राहुल", "विजय", "संजय", "रवि", "अनिल",
+	"दीपक", "मनोज", "प्रिया", "पूजा", "नेहा", "सुनीता", "अनीता", "कविता", "गीता", "सीता",
+	"लक्ष्मी",
+
+	// Bengali
+	"দাস", "ঘোষ", "বসু", "সেন", "রায়", "দত্ত", "চক্রবর্তী", "বন্দ্যোপাধ্যায়", "মুখোপাধ্যায়",
+	"চট্টোপাধ্যায়", "বিশ্বাস", "মণ্ডল", "সাহা", "পাল", "কুমার", "দেবী", "সৌরভ", "সুভাষ",
+	"অমিত", "প্রিয়া", "অনিতা", "রাহুল",
+
+	// Tamil
+	"குமார்", "ராஜ்", "முருகன்", "செல்வி", "லட்சுமி", "கார்த்திக்", "செந்தில்", "பாரதி",
+	"ரவி", "சுப்பிரமணியன்", "கிருஷ்ணன்", "ராமன்", "பிரியா", "ஆனந்த்", "கணேஷ்", "வெங்கடேஷ்",
+	"மீனா", "ஐயர்", "பிள்ளை",
+
+	// Telugu
+	"రెడ్డి", "రావు", "నాయుడు", "శ్రీనివాస్", "లక్ష్మి", "వెంకటేష్", "కుమార్",
+
+	// Kannada
+	"ಗೌಡ", "ಹೆಗ್ಡೆ", "ಶೆಟ್ಟಿ", "ಕುಮಾರ್", "ರಾವ್",
+
+	// Malayalam
+	"നായർ", "മേനോൻ", "പിള്ള", "കുമാർ",
+
+	// Gujarati
+	"પટેલ", "શાહ", "મહેતા", "દેસાઈ",
+
+	// Gurmukhi
+	"ਸਿੰਘ", "ਕੌਰ", "ਗਿੱਲ", "ਸੰਧੂ",
+
+	// Odia
+	"ପଟ୍ଟନାୟକ", "ମହାପାତ୍ର", "ଦାସ",
+}
+
+// indicAddressWords are words that mark a line as an address: road and
+// locality suffixes, administrative units and postal terms.
+var indicAddressWords = []string{
+	// Transliterated and English
+	"nagar", "marg", "road", "rd", "street", "lane", "gali", "galli", "mohalla", "chowk",
+	"colony", "sector", "vihar", "puram", "layout", "cross", "block", "flat", "hno",
+	"apartment", "apartments", "society", "tehsil", "taluk", "taluka", "district", "dist",
+	"zila", "jila", "gram", "village", "vill", "post", "po", "near", "opp", "pin", "pincode",
+
+	// Devanagari
+	"नगर", "मार्ग", "रोड", "गली", "मोहल्ला", "चौक", "कॉलोनी", "सेक्टर", "विहार", "ग्राम",
+	"गांव", "गाँव", "जिला", "ज़िला", "तहसील", "पोस्ट", "पिन", "मकान",
+
+	// Bengali
+	"রোড", "নগর", "গ্রাম", "জেলা", "পোস্ট", "পিন", "সরণি", "লেন",
+
+	// Tamil
+	"சாலை", "தெரு", "நகர்", "கிராமம்", "மாவட்டம்", "அஞ்சல்",
+
+	// Telugu
+	"రోడ్డు", "నగర్", "గ్రామం", "జిల్లా",
+
+	// Kannada
+	"ರಸ್ತೆ", "ನಗರ", "ಗ್ರಾಮ", "ಜಿಲ್ಲೆ",
+
+	// Malayalam
+	"റോഡ്", "നഗർ", "ജില്ല",
+
+	// Gujarati
+	"રોડ", "નગર", "ગામ", "જિલ્લો",
+
+	// Gurmukhi
+	"ਰੋਡ", "ਨਗਰ", "ਪਿੰਡ", "ਜ਼ਿਲ੍ਹਾ",
+
+	// Odia
+	"ରୋଡ", "ନଗର", "ଜିଲ୍ଲା",
+}
+
+// indicStates are states and union territories.
+var indicStates = []string{
+	"andhra pradesh", "arunachal pradesh", "assam", "bihar", "chhattisgarh", "goa",
+	"gujarat", "haryana", "himachal pradesh", "jharkhand", "karnataka", "kerala",
+	"madhya pradesh", "maharashtra", "manipur", "meghalaya", "mizoram", "nagaland",
+	"odisha", "orissa", "punjab", "rajasthan", "sikkim", "tamil nadu", "telangana",
+	"tripura", "uttar pradesh", "uttarakhand", "west bengal", "delhi", "new delhi",
+	"jammu and kashmir", "ladakh", "puducherry", "pondicherry", "chandigarh",
+
+	"उत्तर प्रदेश", "मध्य प्रदेश", "महाराष्ट्र", "बिहार", "राजस्थान", "दिल्ली", "नई दिल्ली",
+	"हरियाणा", "गुजरात", "झारखंड", "उत्तराखंड", "छत्तीसगढ़", "हिमाचल प्रदेश",
+	"পশ্চিমবঙ্গ", "ত্রিপুরা", "আসাম",
+	"தமிழ்நாடு", "புதுச்சேரி",
+	"తెలంగాణ", "ఆంధ్రప్రదేశ్", "ఆంధ్ర ప్రదేశ్",
+	"ಕರ್ನಾಟಕ",
+	"കേരളം",
+	"ગુજરાત",
+	"ਪੰਜਾਬ",
+	"ଓଡ଼ିଶା",
+}
+
+// indicDistricts are major districts and cities.
+var indicDistricts = []string{
+	"mumbai", "pune", "nagpur", "thane", "nashik", "aurangabad", "solapur", "kolhapur",
+	"bengaluru", "bangalore", "mysuru", "mysore", "mangaluru", "mangalore", "hubli", "belagavi",
+	"chennai", "madurai", "coimbatore", "tiruchirappalli", "salem", "tirunelveli",
+	"hyderabad", "secunderabad", "warangal", "visakhapatnam", "vijayawada", "guntur", "nellore",
+	"kolkata", "howrah", "durgapur", "siliguri", "asansol",
+	"lucknow", "kanpur", "varanasi", "agra", "prayagraj", "allahabad", "meerut", "bareilly",
+	"aligarh", "gorakhpur", "noida", "ghaziabad",
+	"patna", "gaya", "ranchi", "jamshedpur",
+	"jaipur", "jodhpur", "udaipur", "ahmedabad", "surat", "vadodara", "rajkot",
+	"bhopal", "indore", "gwalior", "jabalpur", "raipur", "bilaspur",
+	"ludhiana", "amritsar", "jalandhar", "gurugram", "gurgaon", "faridabad", "dehradun",
+	"kochi", "ernakulam", "thiruvananthapuram", "kozhikode", "bhubaneswar", "cuttack", "guwahati",
+
+	"मुंबई", "पुणे", "नागपुर", "लखनऊ", "कानपुर", "वाराणसी", "आगरा", "प्रयागराज", "पटना",
+	"जयपुर", "भोपाल", "इंदौर", "नोएडा", "गुड़गांव", "गुरुग्राम", "रांची", "देहरादून",
+	"কলকাতা", "হাওড়া", "শিলিগুড়ি",
+	"சென்னை", "மதுரை", "கோயம்புத்தூர்", "சேலம்",
+	"హైదరాబాద్", "విజయవాడ", "విశాఖపట్నం",
+	"ಬೆಂಗಳೂರು", "ಮೈಸೂರು", "ಮಂಗಳೂರು",
+	"കൊച്ചി", "തിരുവനന്തപുരം", "കോഴിക്കോട്",
+	"અમદાવાદ", "સુરત", "વડોદરા",
+	"ਅੰਮ੍ਰਿਤਸਰ", "ਲੁਧਿਆਣਾ",
+	"ଭୁବନେଶ୍ୱର", "କଟକ",
+}
@@ -0,0 +1,128 @@
+package detection
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+type indicCorpus struct {
+	Cases []struct {
+		Name     string        `json:"name"`
+		Language string        `json:"language"`
+		Column   string        `json:"column"`
+		Samples  []string      `json:"samples"`
+		Expect   types.PIIType `json:"expect"`
+	} `json:"cases"`
+}
+
+func loadIndicCorpus(t *testing.T) indicCorpus {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "indic_corpus.json"))
+	if err != nil {
+		t.Fatalf("read corpus: %v", err)
+	}
+	var corpus indicCorpus
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		t.Fatalf("parse corpus: %v", err)
+	}
+	return corpus
+}
+
+// TestIndicStrategy_Corpus runs the labeled multilingual corpus. Positive
+// cases must report the labeled type as the top result; negative cases must
+// report nothing.
+func TestIndicStrategy_Corpus(t *testing.T) {
+	s := NewIndicStrategy()
+	corpus := loadIndicCorpus(t)
+	if len(corpus.Cases) == 0 {
+		t.Fatal("corpus is empty")
+	}
+
+	for _, tc := range corpus.Cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			results, err := s.Detect(context.Background(), Input{ColumnName: tc.Column, Samples: tc.Samples})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.Expect == "" {
+				if len(results) != 0 {
+					t.Errorf("expected no detection, got %s (%s)", results[0].Type, results[0].Reasoning)
+				}
+				return
+			}
+			if len(results) == 0 {
+				t.Fatalf("expected %s, got nothing", tc.Expect)
+			}
+			if results[0].Type != tc.Expect {
+				t.Errorf("expected top match %s, got %s (%s)", tc.Expect, results[0].Type, results[0].Reasoning)
+			}
+			if results[0].Method != types.DetectionMethodIndic {
+				t.Errorf("expected method INDIC, got %s", results[0].Method)
+			}
+		})
+	}
+}
+
+func TestNormalizeIndic(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"devanagari digits", "९८७६५ ४३२१०", "98765 43210"},
+		{"tamil digits", "௯௮௭௬", "9876"},
+		{"case and whitespace", "  Laxmi   NAGAR ", "laxmi nagar"},
+		{"zero-width joiner removed", "\u0915\u094d\u200d\u0937", "\u0915\u094d\u0937"},
+		{"vowel sign composed", "\u0b95\u0bc6\u0bbe", "\u0b95\u0bca"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeIndic(tt.in); got != tt.want {
+			t.Errorf("%s: NormalizeIndic(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDominantScript(t *testing.T) {
+	tests := map[string]string{
+		"राजेश कुमार":   "Devanagari",
+		"সৌরভ ঘোষ":      "Bengali",
+		"செந்தில் ராஜ்": "Tamil",
+		"Rajesh Kumar":  "Latin",
+	}
+	for in, want := range tests {
+		if got := dominantScript(in); got != want {
+			t.Errorf("dominantScript(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestIndicStrategy_ReasoningNamesScripts(t *testing.T) {
+	results, err := NewIndicStrategy().Detect(context.Background(), Input{
+		ColumnName: "mobile",
+		Samples:    []string{"९८७६५४३२१०", "௯௮௭௬௫௪௩௨௧௦"},
+	})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("expected one result, got %v (err %v)", results, err)
+	}
+	if !strings.Contains(results[0].Reasoning, "Devanagari, Tamil") {
+		t.Errorf("reasoning should name the scripts seen: %s", results[0].Reasoning)
+	}
+}
+
+func TestDefaultDetector_IndicAddress(t *testing.T) {
+	report, err := NewOfflineDetector().Detect(context.Background(), Input{
+		ColumnName: "pata",
+		Samples:    []string{"मकान 12, लक्ष्मी नगर, दिल्ली ११००९२", "सेक्टर 18, नोएडा २०१३०१"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.TopMatch == nil || report.TopMatch.Type != types.PIITypeAddress {
+		t.Fatalf("expected ADDRESS, got %+v", report.TopMatch)
+	}
+}
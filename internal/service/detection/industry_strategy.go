@@ -2,6 +2,7 @@ package detection
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -21,6 +22,7 @@ type industryPattern struct {
 	Type        types.PIIType
 	Category    types.PIICategory
 	Sensitivity types.SensitivityLevel
+	Validate    Validator // optional check-digit or structure check
 }
 
 // NewIndustryStrategy creates a new IndustryStrategy with built-in pattern packs.
@@ -60,6 +62,7 @@ func (s *IndustryStrategy) Detect(ctx context.Context, input Input) ([]Result, e
 
 	for _, p := range patterns {
 		matchCount := 0
+		validCount := 0
 		sampleCount := 0
 
 		targetSamples := input.SanitizedSamples
@@ -78,6 +81,9 @@ func (s *IndustryStrategy) Detect(ctx context.Context, input Input) ([]Result, e
 			sampleCount++
 			if p.Regex.MatchString(sample) {
 				matchCount++
+				if p.Validate != nil && p.Validate(sample) {
+					validCount++
+				}
 			}
 		}
 
@@ -89,6 +95,15 @@ func (s *IndustryStrategy) Detect(ctx context.Context, input Input) ([]Result, e
 			if confidence > 0.95 {
 				confidence = 0.95 // Cap at 0.95 for regex
 			}
+			reasoning := "Matched industry pattern: " + p.Name
+			if p.Validate != nil {
+				// Validated identifiers are scored on the share passing validation.
+				confidence = validatedConfidence(validCount, matchCount, sampleCount)
+				if confidence == 0 {
+					continue
+				}
+				reasoning += fmt.Sprintf(" (%d of %d matches passed validation)", validCount, matchCount)
+			}
 
 			results = append(results, Result{
 				Category:    p.Category,
@@ -96,7 +111,7 @@ func (s *IndustryStrategy) Detect(ctx context.Context, input Input) ([]Result, e
 				Sensitivity: p.Sensitivity,
 				Confidence:  confidence,
 				Method:      types.DetectionMethodIndustry,
-				Reasoning:   "Matched industry pattern: " + p.Name,
+				Reasoning:   reasoning,
 			})
 		}
 	}
@@ -126,7 +141,8 @@ func (s *IndustryStrategy) loadHealthcarePatterns() {
 	s.patterns["healthcare"] = []industryPattern{
 		{
 			Name:        "NPI (National Provider Identifier)",
-			Regex:       regexp.MustCompile(`^\d{10}$`), // Simplified NPI (Luhn check needed for strict)
+			Regex:       regexp.MustCompile(`^[12]\d{9}$`),
+			Validate:    ValidNPI,
 			Type:        types.PIITypeNationalID,
 			Category:    types.PIICategoryProfessional,
 			Sensitivity: types.SensitivityMedium,
@@ -159,7 +175,8 @@ func (s *IndustryStrategy) loadFinancialPatterns() {
 		},
 		{
 			Name:        "IBAN",
-			Regex:       regexp.MustCompile(`^[A-Z]{2}\d{2}[A-Z0-9]{11,30}$`), // Simplified
+			Regex:       regexp.MustCompile(`^[A-Z]{2}\d{2}[A-Z0-9]{11,30}$`),
+			Validate:    ValidIBAN,
 			Type:        types.PIITypeBankAccount,
 			Category:    types.PIICategoryFinancial,
 			Sensitivity: types.SensitivityHigh,
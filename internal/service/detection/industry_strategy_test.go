@@ -38,7 +38,7 @@ func TestIndustryStrategy_Detect(t *testing.T) {
 		{
 			name:          "Healthcare - NPI",
 			industry:      "Healthcare",
-			samples:       []string{"1234567893", "9876543210"}, // Valid 10 digits
+			samples:       []string{"1234567893", "1245319599"}, // Luhn-valid NPIs
 			wantMatch:     true,
 			wantCategory:  types.PIICategoryProfessional,
 			wantType:      types.PIITypeNationalID,
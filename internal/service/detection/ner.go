@@ -0,0 +1,261 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// NERStrategy finds people, addresses and organizations in free text with
+// an offline named-entity model — no LLM, no network, pure Go. It is meant
+// for unstructured content (documents, notes, message bodies) and stays
+// silent on short structured values, which the pattern and heuristic
+// strategies already cover.
+//
+// Base confidence: 0.70–0.75 by entity, scaled by the share of samples
+// containing it.
+type NERStrategy struct {
+	model *NERModel
+}
+
+// NewNERStrategy creates an NER strategy. A nil model uses DefaultNERModel.
+func NewNERStrategy(model *NERModel) *NERStrategy {
+	if model == nil {
+		model = DefaultNERModel()
+	}
+	return &NERStrategy{model: model}
+}
+
+func (s *NERStrategy) Name() string                  { return "ner" }
+func (s *NERStrategy) Method() types.DetectionMethod { return types.DetectionMethodNER }
+func (s *NERStrategy) Weight() float64               { return 0.75 }
+
+const (
+	// minNERTokens is the average number of tokens per sample below which
+	// samples are treated as structured values rather than free text.
+	minNERTokens = 4
+
+	// maxNERTokens caps how much of one sample is tagged.
+	maxNERTokens = 5000
+)
+
+// nerEntity maps a model label to the PII type it reports.
+type nerEntity struct {
+	piiType     types.PIIType
+	category    types.PIICategory
+	sensitivity types.SensitivityLevel
+	base        float64
+	label       string
+}
+
+var nerEntities = map[string]nerEntity{
+	"PER":  {types.PIITypeName, types.PIICategoryIdentity, types.SensitivityLow, 0.75, "person names"},
+	"ADDR": {types.PIITypeAddress, types.PIICategoryContact, types.SensitivityMedium, 0.75, "postal addresses"},
+	"ORG":  {types.PIITypeOrganization, types.PIICategoryProfessional, types.SensitivityLow, 0.70, "organization names"},
+}
+
+// Detect tags each sample and reports every entity type found.
+func (s *NERStrategy) Detect(ctx context.Context, input Input) ([]Result, error) {
+	tokenized := make([][]string, 0, len(input.Samples))
+	totalTokens := 0
+	for _, sample := range input.Samples {
+		tokens := nerTokenize(sample)
+		if len(tokens) == 0 {
+			continue
+		}
+		if len(tokens) > maxNERTokens {
+			tokens = tokens[:maxNERTokens]
+		}
+		tokenized = append(tokenized, tokens)
+		totalTokens += len(tokens)
+	}
+	if len(tokenized) == 0 || totalTokens < minNERTokens*len(tokenized) {
+		return nil, nil
+	}
+
+	found := make(map[string]int)
+	for _, tokens := range tokenized {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, span := range NEREntities(tokens, s.model.Tag(tokens)) {
+			seen[span.Label] = true
+		}
+		for label := range seen {
+			found[label]++
+		}
+	}
+
+	var results []Result
+	for label, n := range found {
+		entity, ok := nerEntities[label]
+		if !ok {
+			continue
+		}
+		rate := float64(n) / float64(len(tokenized))
+		results = append(results, Result{
+			Category:    entity.category,
+			Type:        entity.piiType,
+			Sensitivity: entity.sensitivity,
+			Confidence:  entity.base * (0.7 + 0.3*rate),
+			Method:      types.DetectionMethodNER,
+			Reasoning:   fmt.Sprintf("Named-entity model found %s in %d of %d text samples", entity.label, n, len(tokenized)),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Confidence > results[j].Confidence })
+	return results, nil
+}
+
+// NERSpan is one entity found in a token sequence; End is exclusive.
+type NERSpan struct {
+	Label string `json:"label"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// NEREntities groups BIO tags into entity spans. An I- tag that does not
+// continue an entity of the same label starts a new one.
+func NEREntities(tokens, tags []string) []NERSpan {
+	var spans []NERSpan
+	var cur *NERSpan
+	closeSpan := func(end int) {
+		if cur != nil {
+			cur.End = end
+			cur.Text = strings.Join(tokens[cur.Start:end], " ")
+			spans = append(spans, *cur)
+			cur = nil
+		}
+	}
+	for i, tag := range tags {
+		prefix, label, ok := strings.Cut(tag, "-")
+		if !ok {
+			closeSpan(i)
+			continue
+		}
+		if prefix == "B" || cur == nil || cur.Label != label {
+			closeSpan(i)
+			cur = &NERSpan{Label: label, Start: i}
+		}
+	}
+	closeSpan(len(tags))
+	return spans
+}
+
+// nerTokenize splits text on whitespace and separates leading and trailing
+// punctuation into tokens of their own: "Pune," → "Pune", ",".
+func nerTokenize(text string) []string {
+	var tokens []string
+	for _, o := range nerTokenOffsets(text) {
+		tokens = append(tokens, text[o[0]:o[1]])
+	}
+	return tokens
+}
+
+// nerTokenOffsets returns the byte offsets of the tokens nerTokenize
+// produces, as [start, end) pairs.
+func nerTokenOffsets(text string) [][2]int {
+	var offsets [][2]int
+	i := 0
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+		fieldStart := i
+		for i < len(text) {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			i += size
+		}
+		start, end := fieldStart, i
+
+		for start < end {
+			r, size := utf8.DecodeRuneInString(text[start:end])
+			if !isNERPunct(r) {
+				break
+			}
+			offsets = append(offsets, [2]int{start, start + size})
+			start += size
+		}
+		var trailing [][2]int
+		for end > start {
+			r, size := utf8.DecodeLastRuneInString(text[start:end])
+			if !isNERPunct(r) {
+				break
+			}
+			trailing = append(trailing, [2]int{end - size, end})
+			end -= size
+		}
+		if start < end {
+			offsets = append(offsets, [2]int{start, end})
+		}
+		for j := len(trailing) - 1; j >= 0; j-- {
+			offsets = append(offsets, trailing[j])
+		}
+	}
+	return offsets
+}
+
+func isNERPunct(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+}
+
+// DetectSpans tags text and returns the entities found, tagging at most
+// maxNERTokens tokens at a time. Unlike Detect there is no minimum length:
+// a short prompt naming one person is worth redacting.
+func (s *NERStrategy) DetectSpans(ctx context.Context, text string) ([]Span, error) {
+	offsets := nerTokenOffsets(text)
+	var spans []Span
+	for from := 0; from < len(offsets); from += maxNERTokens {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		window := offsets[from:min(from+maxNERTokens, len(offsets))]
+		tokens := make([]string, len(window))
+		for i, o := range window {
+			tokens[i] = text[o[0]:o[1]]
+		}
+		for _, e := range NEREntities(tokens, s.model.Tag(tokens)) {
+			entity, ok := nerEntities[e.Label]
+			start, end := window[e.Start][0], window[e.End-1][1]
+			// A run of digits is no name or address, whatever its context
+			if !ok || !strings.ContainsFunc(text[start:end], unicode.IsLetter) {
+				continue
+			}
+			spans = append(spans, Span{
+				Start:       start,
+				End:         end,
+				Category:    entity.category,
+				Type:        entity.piiType,
+				Sensitivity: entity.sensitivity,
+				Confidence:  entity.base,
+				Method:      types.DetectionMethodNER,
+			})
+		}
+	}
+	return spans, nil
+}
+
+// WithNERModel returns a detector whose NER strategy uses model instead of
+// the built-in one, e.g. a model trained on a tenant's own documents. The
+// receiver is not modified.
+func (d *ComposableDetector) WithNERModel(model *NERModel) *ComposableDetector {
+	strategies := make([]Strategy, len(d.strategies))
+	for i, s := range d.strategies {
+		if _, ok := s.(*NERStrategy); ok {
+			s = NewNERStrategy(model)
+		}
+		strategies[i] = s
+	}
+	return &ComposableDetector{strategies: strategies, calibration: d.calibration}
+}
@@ -0,0 +1,89 @@
+package detection
+
+import (
+	"strings"
+)
+
+// nerGazetteer maps a lower-cased token to the word classes it belongs to.
+// Classes are model features, not decisions: "bank" is an organization
+// suffix, but the model learns when it actually ends an organization name.
+var nerGazetteer = buildNERGazetteer()
+
+func buildNERGazetteer() map[string][]string {
+	g := make(map[string][]string)
+	add := func(class string, lists ...[]string) {
+		for _, list := range lists {
+			for _, phrase := range list {
+				// Multi-word entries contribute each word.
+				for _, w := range strings.Fields(strings.ToLower(phrase)) {
+					if !containsString(g[w], class) {
+						g[w] = append(g[w], class)
+					}
+				}
+			}
+		}
+	}
+
+	add("title", nerTitles)
+	add("name", nerNames, latinOnly(indicNameWords))
+	add("org", nerOrgSuffixes)
+	add("place", indicStates, indicDistricts, nerWorldPlaces)
+	add("addr", nerAddressWords, latinOnly(indicAddressWords))
+	return g
+}
+
+// nerGazetteerClasses returns the gazetteer classes of a lower-cased token.
+func nerGazetteerClasses(lower string) []string {
+	return nerGazetteer[lower]
+}
+
+func latinOnly(words []string) []string {
+	var out []string
+	for _, w := range words {
+		if w != "" && w[0] < 0x80 {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+var nerTitles = []string{
+	"mr", "mrs", "ms", "miss", "dr", "prof", "sir", "madam", "shri", "smt", "sri", "kumari",
+}
+
+// nerNames adds common English given names and surnames to the
+// transliterated Indian names shared with IndicStrategy.
+var nerNames = []string{
+	"john", "mary", "david", "sarah", "michael", "emma", "james", "olivia", "robert", "linda",
+	"thomas", "anna", "william", "elizabeth", "richard", "jennifer", "joseph", "susan",
+	"charles", "margaret", "daniel", "lisa", "matthew", "karen", "mark", "laura", "paul",
+	"sophie", "peter", "helen", "george", "kate", "smith", "johnson", "brown", "williams",
+	"miller", "wilson", "taylor", "anderson", "davis", "clark", "martin", "thompson",
+}
+
+var nerOrgSuffixes = []string{
+	"ltd", "limited", "pvt", "private", "inc", "llc", "llp", "corp", "corporation", "company",
+	"co", "bank", "university", "college", "school", "hospital", "clinic", "technologies",
+	"technology", "solutions", "systems", "services", "group", "foundation", "trust",
+	"industries", "labs", "insurance", "enterprises", "associates", "consultancy", "ventures",
+	"holdings", "finance", "pharma", "motors", "infotech", "logistics",
+}
+
+var nerWorldPlaces = []string{
+	"london", "new york", "singapore", "dubai", "san francisco", "toronto", "sydney",
+	"paris", "berlin", "tokyo", "hong kong", "kuala lumpur", "california", "texas",
+}
+
+var nerAddressWords = []string{
+	"street", "avenue", "ave", "boulevard", "suite", "floor", "building", "tower", "plot",
+	"house", "flat", "apt", "apartment", "unit", "no", "highway", "phase", "stage", "main",
+}
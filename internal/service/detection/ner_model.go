@@ -0,0 +1,387 @@
+package detection
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NERModel is a greedy left-to-right token tagger: an averaged perceptron
+// over word shape, context and gazetteer features, predicting BIO tags
+// (B-PER, I-ADDR, O, ...). It is small enough to train in well under a
+// second and runs on CPU with no dependencies.
+type NERModel struct {
+	Version int      `json:"version"`
+	Labels  []string `json:"labels"`
+	// Weights maps feature → label → weight.
+	Weights map[string]map[string]float64 `json:"weights"`
+}
+
+// nerModelVersion is bumped whenever nerFeatures changes, since a model
+// only works with the features it was trained on.
+const nerModelVersion = 1
+
+// NERSentence is one tokenized sentence with a BIO tag per token.
+type NERSentence struct {
+	Tokens []string
+	Tags   []string
+}
+
+//go:embed ner_seed.conll
+var nerSeedCorpus string
+
+var (
+	defaultNERModel     *NERModel
+	defaultNERModelOnce sync.Once
+)
+
+// DefaultNERModel returns the built-in model, trained on first use from the
+// embedded seed corpus: template sentences of Indian and English names,
+// organizations and addresses, with distractor sentences that are not PII.
+func DefaultNERModel() *NERModel {
+	defaultNERModelOnce.Do(func() {
+		sentences, err := NERSeedSentences()
+		if err != nil {
+			panic(fmt.Sprintf("detection: embedded NER corpus: %v", err))
+		}
+		defaultNERModel = TrainNERModel(sentences, defaultNERIterations)
+	})
+	return defaultNERModel
+}
+
+// defaultNERIterations is the number of training passes for the built-in
+// model.
+const defaultNERIterations = 5
+
+// NERSeedSentences returns the embedded seed corpus, so custom models can
+// be trained on it plus a tenant's own labeled text.
+func NERSeedSentences() ([]NERSentence, error) {
+	return ParseNERCorpus(strings.NewReader(nerSeedCorpus))
+}
+
+// LoadNERModel reads a model saved with Save.
+func LoadNERModel(path string) (*NERModel, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open ner model: %w", err)
+	}
+	defer f.Close()
+
+	var m NERModel
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return nil, fmt.Errorf("decode ner model: %w", err)
+	}
+	if m.Version != nerModelVersion {
+		return nil, fmt.Errorf("ner model version %d is not supported (want %d)", m.Version, nerModelVersion)
+	}
+	if len(m.Labels) == 0 || len(m.Weights) == 0 {
+		return nil, fmt.Errorf("ner model %s is empty", path)
+	}
+	return &m, nil
+}
+
+// Save writes the model as JSON.
+func (m *NERModel) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m)
+}
+
+// ParseNERCorpus reads CoNLL-style training data: one "token<TAB>tag" per
+// line, sentences separated by blank lines.
+func ParseNERCorpus(r io.Reader) ([]NERSentence, error) {
+	var (
+		sentences []NERSentence
+		current   NERSentence
+		lineNo    int
+	)
+	flush := func() {
+		if len(current.Tokens) > 0 {
+			sentences = append(sentences, current)
+		}
+		current = NERSentence{}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		token, tag, ok := strings.Cut(line, "\t")
+		if !ok || token == "" || tag == "" {
+			return nil, fmt.Errorf("line %d: want token<TAB>tag", lineNo)
+		}
+		current.Tokens = append(current.Tokens, token)
+		current.Tags = append(current.Tags, tag)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return sentences, nil
+}
+
+// TrainNERModel trains a model with the averaged perceptron. Sentences are
+// shuffled with a fixed seed between iterations, so training is
+// deterministic for a given corpus.
+func TrainNERModel(sentences []NERSentence, iterations int) *NERModel {
+	labelSet := make(map[string]bool)
+	for _, s := range sentences {
+		for _, tag := range s.Tags {
+			labelSet[tag] = true
+		}
+	}
+	labels := make([]string, 0, len(labelSet))
+	for l := range labelSet {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	p := newPerceptron(labels)
+	order := make([]int, len(sentences))
+	for i := range order {
+		order[i] = i
+	}
+	rnd := rand.New(rand.NewSource(1))
+
+	for iter := 0; iter < iterations; iter++ {
+		for _, idx := range order {
+			s := sentences[idx]
+			lower := lowerTokens(s.Tokens)
+			prev, prev2 := nerStart, nerStart2
+			for i := range s.Tokens {
+				feats := nerFeatures(s.Tokens, lower, i, prev, prev2)
+				guess := p.predict(feats)
+				p.update(s.Tags[i], guess, feats)
+				prev2, prev = prev, guess
+			}
+		}
+		rnd.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+
+	return &NERModel{Version: nerModelVersion, Labels: labels, Weights: p.average()}
+}
+
+// Tag predicts a BIO tag for every token.
+func (m *NERModel) Tag(tokens []string) []string {
+	lower := lowerTokens(tokens)
+	tags := make([]string, len(tokens))
+	prev, prev2 := nerStart, nerStart2
+	scores := make(map[string]float64, len(m.Labels))
+	for i := range tokens {
+		for _, l := range m.Labels {
+			scores[l] = 0
+		}
+		for _, f := range nerFeatures(tokens, lower, i, prev, prev2) {
+			for label, w := range m.Weights[f] {
+				scores[label] += w
+			}
+		}
+		tags[i] = bestLabel(m.Labels, scores)
+		prev2, prev = prev, tags[i]
+	}
+	return tags
+}
+
+// bestLabel picks the highest-scoring label, breaking ties by label order.
+func bestLabel(labels []string, scores map[string]float64) string {
+	best, bestScore := labels[0], math.Inf(-1)
+	for _, l := range labels {
+		if scores[l] > bestScore {
+			best, bestScore = l, scores[l]
+		}
+	}
+	return best
+}
+
+// =============================================================================
+// Features
+// =============================================================================
+
+const (
+	nerStart  = "-START-"
+	nerStart2 = "-START2-"
+)
+
+// nerFeatures describes token i given the two previous predicted tags.
+func nerFeatures(tokens, lower []string, i int, prev, prev2 string) []string {
+	word := tokens[i]
+	w := lower[i]
+	shape := wordShape(word)
+
+	feats := []string{
+		"bias",
+		"w=" + w,
+		"shape=" + shape,
+		"suf3=" + suffix(w, 3),
+		"pre3=" + prefix(w, 3),
+		"p1=" + prev,
+		"p1p2=" + prev + "|" + prev2,
+		"p1shape=" + prev + "|" + shape,
+	}
+	if i == 0 {
+		feats = append(feats, "first", "first_shape="+shape)
+	}
+	for _, g := range nerGazetteerClasses(w) {
+		feats = append(feats, "gaz="+g, "p1gaz="+prev+"|"+g)
+	}
+
+	if i > 0 {
+		feats = append(feats, "w-1="+lower[i-1], "shape-1="+wordShape(tokens[i-1]))
+		for _, g := range nerGazetteerClasses(lower[i-1]) {
+			feats = append(feats, "gaz-1="+g)
+		}
+	} else {
+		feats = append(feats, "w-1="+nerStart)
+	}
+	if i+1 < len(tokens) {
+		feats = append(feats, "w+1="+lower[i+1], "shape+1="+wordShape(tokens[i+1]))
+		for _, g := range nerGazetteerClasses(lower[i+1]) {
+			feats = append(feats, "gaz+1="+g)
+		}
+	} else {
+		feats = append(feats, "w+1=-END-")
+	}
+	if i+2 < len(tokens) {
+		for _, g := range nerGazetteerClasses(lower[i+2]) {
+			feats = append(feats, "gaz+2="+g)
+		}
+	}
+	return feats
+}
+
+// wordShape compresses a word to its character classes: "Rajesh" → "Xx",
+// "MG" → "X", "560001" → "d", "B-12" → "X-d".
+func wordShape(word string) string {
+	var b strings.Builder
+	var last rune
+	for _, r := range word {
+		var c rune
+		switch {
+		case unicode.IsUpper(r):
+			c = 'X'
+		case unicode.IsLower(r):
+			c = 'x'
+		case unicode.IsDigit(r):
+			c = 'd'
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			c = 'a' // caseless scripts
+		default:
+			c = r
+		}
+		if c != last {
+			b.WriteRune(c)
+			last = c
+		}
+	}
+	return b.String()
+}
+
+func lowerTokens(tokens []string) []string {
+	out := make([]string, len(tokens))
+	for i, t := range tokens {
+		out[i] = strings.ToLower(t)
+	}
+	return out
+}
+
+func suffix(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[len(r)-n:])
+}
+
+func prefix(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// =============================================================================
+// Averaged Perceptron
+// =============================================================================
+
+type perceptron struct {
+	labels    []string
+	weights   map[string]map[string]float64
+	totals    map[string]map[string]float64
+	stamps    map[string]map[string]int
+	instances int
+}
+
+func newPerceptron(labels []string) *perceptron {
+	return &perceptron{
+		labels:  labels,
+		weights: make(map[string]map[string]float64),
+		totals:  make(map[string]map[string]float64),
+		stamps:  make(map[string]map[string]int),
+	}
+}
+
+func (p *perceptron) predict(feats []string) string {
+	scores := make(map[string]float64, len(p.labels))
+	for _, f := range feats {
+		for label, w := range p.weights[f] {
+			scores[label] += w
+		}
+	}
+	return bestLabel(p.labels, scores)
+}
+
+func (p *perceptron) update(truth, guess string, feats []string) {
+	p.instances++
+	if truth == guess {
+		return
+	}
+	for _, f := range feats {
+		p.adjust(f, truth, 1)
+		p.adjust(f, guess, -1)
+	}
+}
+
+func (p *perceptron) adjust(feat, label string, delta float64) {
+	if p.weights[feat] == nil {
+		p.weights[feat] = make(map[string]float64)
+		p.totals[feat] = make(map[string]float64)
+		p.stamps[feat] = make(map[string]int)
+	}
+	w := p.weights[feat][label]
+	p.totals[feat][label] += float64(p.instances-p.stamps[feat][label]) * w
+	p.stamps[feat][label] = p.instances
+	p.weights[feat][label] = w + delta
+}
+
+// average returns the weights averaged over every training instance, which
+// generalizes far better than the final weights. Near-zero weights are
+// dropped to keep saved models small.
+func (p *perceptron) average() map[string]map[string]float64 {
+	out := make(map[string]map[string]float64, len(p.weights))
+	for feat, labels := range p.weights {
+		for label, w := range labels {
+			total := p.totals[feat][label] + float64(p.instances-p.stamps[feat][label])*w
+			avg := math.Round(total/float64(p.instances)*1000) / 1000
+			if avg == 0 {
+				continue
+			}
+			if out[feat] == nil {
+				out[feat] = make(map[string]float64)
+			}
+			out[feat][label] = avg
+		}
+	}
+	return out
+}
@@ -0,0 +1,188 @@
+package detection
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// TestNERModel_HeldOut scores the default model on sentences built from
+// names, organizations and places that do not appear in the seed corpus.
+func TestNERModel_HeldOut(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "ner_eval.conll"))
+	if err != nil {
+		t.Fatalf("open eval corpus: %v", err)
+	}
+	defer f.Close()
+	sentences, err := ParseNERCorpus(f)
+	if err != nil {
+		t.Fatalf("parse eval corpus: %v", err)
+	}
+
+	model := DefaultNERModel()
+	type counts struct{ tp, fp, fn int }
+	byLabel := make(map[string]*counts)
+	get := func(label string) *counts {
+		if byLabel[label] == nil {
+			byLabel[label] = &counts{}
+		}
+		return byLabel[label]
+	}
+
+	for _, s := range sentences {
+		gold := make(map[NERSpan]bool)
+		for _, span := range NEREntities(s.Tokens, s.Tags) {
+			gold[span] = true
+		}
+		for _, span := range NEREntities(s.Tokens, model.Tag(s.Tokens)) {
+			if gold[span] {
+				get(span.Label).tp++
+				delete(gold, span)
+			} else {
+				get(span.Label).fp++
+			}
+		}
+		for span := range gold {
+			get(span.Label).fn++
+		}
+	}
+
+	for _, label := range []string{"PER", "ADDR", "ORG"} {
+		c := get(label)
+		precision := float64(c.tp) / float64(c.tp+c.fp)
+		recall := float64(c.tp) / float64(c.tp+c.fn)
+		f1 := 2 * precision * recall / (precision + recall)
+		t.Logf("%-4s precision %.3f recall %.3f F1 %.3f", label, precision, recall, f1)
+		if f1 < 0.85 {
+			t.Errorf("%s: entity F1 %.3f below 0.85", label, f1)
+		}
+	}
+}
+
+func TestNERStrategy_FreeText(t *testing.T) {
+	s := NewNERStrategy(nil)
+	results, err := s.Detect(context.Background(), Input{
+		ColumnName: "content",
+		Samples: []string{
+			"Dear Mr Anand Kulkarni, your replacement card will be couriered to 14 Residency Road, Shivajinagar, Bengaluru 560025 by Skyline Finance Pvt Ltd.",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make(map[types.PIIType]bool)
+	for _, r := range results {
+		got[r.Type] = true
+		if r.Method != types.DetectionMethodNER {
+			t.Errorf("expected method NER, got %s", r.Method)
+		}
+	}
+	for _, want := range []types.PIIType{types.PIITypeName, types.PIITypeAddress, types.PIITypeOrganization} {
+		if !got[want] {
+			t.Errorf("expected %s in %+v", want, results)
+		}
+	}
+}
+
+func TestNERStrategy_SkipsStructuredValues(t *testing.T) {
+	results, err := NewNERStrategy(nil).Detect(context.Background(), Input{
+		ColumnName: "customer",
+		Samples:    []string{"John Smith", "Priya Sharma", "Amit Verma"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("short values are not free text, got %+v", results)
+	}
+}
+
+func TestNERStrategy_CleanText(t *testing.T) {
+	results, err := NewNERStrategy(nil).Detect(context.Background(), Input{
+		ColumnName: "content",
+		Samples:    []string{"The quarterly report is attached. Please review the figures before the Board Meeting on Friday."},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no entities, got %+v", results)
+	}
+}
+
+func TestNEREntities(t *testing.T) {
+	tokens := []string{"Mr", "Ravi", "Rao", "of", "Acme", "Labs", "at", "Pune"}
+	tags := []string{"O", "B-PER", "I-PER", "O", "I-ORG", "I-ORG", "O", "B-ADDR"}
+
+	want := []NERSpan{
+		{Label: "PER", Start: 1, End: 3, Text: "Ravi Rao"},
+		{Label: "ORG", Start: 4, End: 6, Text: "Acme Labs"},
+		{Label: "ADDR", Start: 7, End: 8, Text: "Pune"},
+	}
+	if got := NEREntities(tokens, tags); !reflect.DeepEqual(got, want) {
+		t.Errorf("NEREntities = %+v, want %+v", got, want)
+	}
+}
+
+func TestNERTokenize(t *testing.T) {
+	got := nerTokenize(`Flat 3B, "Shanti Vihar", Pune.`)
+	want := []string{"Flat", "3B", ",", `"`, "Shanti", "Vihar", `"`, ",", "Pune", "."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nerTokenize = %q, want %q", got, want)
+	}
+}
+
+func TestNERModel_SaveLoad(t *testing.T) {
+	sentences := []NERSentence{
+		{Tokens: []string{"Dear", "Asha", "Rao", ","}, Tags: []string{"O", "B-PER", "I-PER", "O"}},
+		{Tokens: []string{"Report", "attached", "."}, Tags: []string{"O", "O", "O"}},
+	}
+	model := TrainNERModel(sentences, 3)
+
+	path := filepath.Join(t.TempDir(), "ner.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := model.Save(f); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	f.Close()
+
+	loaded, err := LoadNERModel(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	tokens := sentences[0].Tokens
+	if got, want := loaded.Tag(tokens), model.Tag(tokens); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded model tags %v, original %v", got, want)
+	}
+
+	if _, err := LoadNERModel(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing model file")
+	}
+}
+
+func TestComposableDetector_WithNERModel(t *testing.T) {
+	custom := TrainNERModel([]NERSentence{{Tokens: []string{"x"}, Tags: []string{"O"}}}, 1)
+	base := NewOfflineDetector()
+	d := base.WithNERModel(custom)
+
+	for i, s := range d.strategies {
+		if ner, ok := s.(*NERStrategy); ok {
+			if ner.model != custom {
+				t.Error("expected NER strategy to use the custom model")
+			}
+			if base.strategies[i].(*NERStrategy).model == custom {
+				t.Error("base detector should be unchanged")
+			}
+			return
+		}
+	}
+	t.Fatal("offline detector has no NER strategy")
+}
@@ -2,7 +2,9 @@ package detection
 
 import (
 	"context"
+	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/complyark/datalens/pkg/types"
@@ -11,7 +13,8 @@ import (
 // PatternStrategy detects PII by matching sample values against compiled
 // regex patterns. Ported from DataLens v1 with added India-specific patterns.
 //
-// Base confidence: 0.90 (regex matches are high-confidence).
+// Base confidence: 0.90 (regex matches are high-confidence). Patterns with a
+// Validator are instead scored by the share of samples passing validation.
 type PatternStrategy struct{}
 
 // NewPatternStrategy creates a new regex-based detection strategy.
@@ -38,6 +41,8 @@ func (s *PatternStrategy) Detect(ctx context.Context, input Input) ([]Result, er
 		piiType     types.PIIType
 		sensitivity types.SensitivityLevel
 		matches     int
+		validated   bool
+		valid       int
 	}
 
 	counts := make(map[types.PIIType]*matchInfo)
@@ -51,10 +56,14 @@ func (s *PatternStrategy) Detect(ctx context.Context, input Input) ([]Result, er
 						category:    p.category,
 						piiType:     p.piiType,
 						sensitivity: p.sensitivity,
+						validated:   p.validate != nil,
 					}
 					counts[p.piiType] = info
 				}
 				info.matches++
+				if p.validate != nil && p.validate(sample) {
+					info.valid++
+				}
 			}
 		}
 	}
@@ -67,9 +76,19 @@ func (s *PatternStrategy) Detect(ctx context.Context, input Input) ([]Result, er
 	total := len(input.Samples)
 	var results []Result
 	for _, info := range counts {
-		detectionRate := float64(info.matches) / float64(total)
-		// adjustedScore = baseScore × (0.7 + 0.3 × detectionRate)
-		confidence := 0.90 * (0.7 + 0.3*detectionRate)
+		var confidence float64
+		reasoning := "Regex pattern matched"
+		if info.validated {
+			confidence = validatedConfidence(info.valid, info.matches, total)
+			if confidence == 0 {
+				continue
+			}
+			reasoning = fmt.Sprintf("Regex pattern matched; %d of %d matches passed validation", info.valid, info.matches)
+		} else {
+			detectionRate := float64(info.matches) / float64(total)
+			// adjustedScore = baseScore × (0.7 + 0.3 × detectionRate)
+			confidence = 0.90 * (0.7 + 0.3*detectionRate)
+		}
 
 		results = append(results, Result{
 			Category:    info.category,
@@ -77,7 +96,7 @@ func (s *PatternStrategy) Detect(ctx context.Context, input Input) ([]Result, er
 			Sensitivity: info.sensitivity,
 			Confidence:  confidence,
 			Method:      types.DetectionMethodRegex,
-			Reasoning:   "Regex pattern matched",
+			Reasoning:   reasoning,
 		})
 	}
 
@@ -94,6 +113,7 @@ type piiPattern struct {
 	piiType     types.PIIType
 	sensitivity types.SensitivityLevel
 	regex       *regexp.Regexp
+	validate    Validator // optional; nil means shape alone is trusted
 }
 
 var piiPatterns = []piiPattern{
@@ -129,6 +149,7 @@ var piiPatterns = []piiPattern{
 		piiType:     types.PIITypeAadhaar,
 		sensitivity: types.SensitivityCritical,
 		regex:       regexp.MustCompile(`^[2-9]\d{3}[\s\-]?\d{4}[\s\-]?\d{4}$`),
+		validate:    ValidAadhaar,
 	},
 
 	// --- PAN (India) ---
@@ -138,6 +159,27 @@ var piiPatterns = []piiPattern{
 		piiType:     types.PIITypePAN,
 		sensitivity: types.SensitivityHigh,
 		regex:       regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`),
+		validate:    ValidPAN,
+	},
+
+	// --- GSTIN (India) ---
+	{
+		name:        "gstin",
+		category:    types.PIICategoryGovernmentID,
+		piiType:     types.PIITypeNationalID,
+		sensitivity: types.SensitivityMedium,
+		regex:       regexp.MustCompile(`^\d{2}[A-Z]{5}\d{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`),
+		validate:    ValidGSTIN,
+	},
+
+	// --- IFSC (India) ---
+	{
+		name:        "ifsc",
+		category:    types.PIICategoryFinancial,
+		piiType:     types.PIITypeBankAccount,
+		sensitivity: types.SensitivityLow,
+		regex:       regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`),
+		validate:    ValidIFSC,
 	},
 
 	// --- Credit Card (Visa, MasterCard, Amex, Discover) ---
@@ -147,6 +189,7 @@ var piiPatterns = []piiPattern{
 		piiType:     types.PIITypeCreditCard,
 		sensitivity: types.SensitivityCritical,
 		regex:       regexp.MustCompile(`^(?:4\d{3}|5[1-5]\d{2}|3[47]\d{2}|6(?:011|5\d{2}))[\s\-]?\d{4}[\s\-]?\d{4}[\s\-]?\d{4}$`),
+		validate:    ValidCardNumber,
 	},
 
 	// --- SSN (US) ---
@@ -156,6 +199,7 @@ var piiPatterns = []piiPattern{
 		piiType:     types.PIITypeSSN,
 		sensitivity: types.SensitivityCritical,
 		regex:       regexp.MustCompile(`^\d{3}-\d{2}-\d{4}$`),
+		validate:    ValidSSN,
 	},
 
 	// --- IP Address (IPv4) ---
@@ -185,3 +229,49 @@ var piiPatterns = []piiPattern{
 		regex:       regexp.MustCompile(`^[A-Z][1-9]\d{6}[1-9]$`),
 	},
 }
+
+// piiSpanPatterns are piiPatterns unanchored, to find values inside text.
+var piiSpanPatterns = func() []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(piiPatterns))
+	for i, p := range piiPatterns {
+		src := p.regex.String()
+		flags := ""
+		if strings.HasPrefix(src, "(?i)") {
+			flags, src = "(?i)", src[len("(?i)"):]
+		}
+		src = strings.TrimSuffix(strings.TrimPrefix(src, "^"), "$")
+		out[i] = regexp.MustCompile(flags + "(?:" + src + ")")
+	}
+	return out
+}()
+
+// DetectSpans finds pattern matches inside text. Each match is scored as a
+// one-sample column would be: validated matches at 0.99, failing ones
+// dropped, the rest at the base 0.90.
+func (s *PatternStrategy) DetectSpans(ctx context.Context, text string) ([]Span, error) {
+	var spans []Span
+	for i, p := range piiPatterns {
+		for _, loc := range piiSpanPatterns[i].FindAllStringIndex(text, -1) {
+			if !atWordBoundary(text, loc[0], loc[1]) {
+				continue
+			}
+			confidence := 0.90
+			if p.validate != nil {
+				if !p.validate(text[loc[0]:loc[1]]) {
+					continue
+				}
+				confidence = validatedConfidence(1, 1, 1)
+			}
+			spans = append(spans, Span{
+				Start:       loc[0],
+				End:         loc[1],
+				Category:    p.category,
+				Type:        p.piiType,
+				Sensitivity: p.sensitivity,
+				Confidence:  confidence,
+				Method:      types.DetectionMethodRegex,
+			})
+		}
+	}
+	return spans, nil
+}
@@ -40,7 +40,7 @@ func TestPatternStrategy_Aadhaar(t *testing.T) {
 	input := Input{
 		ColumnName: "aadhaar_number",
 		DataType:   "varchar",
-		Samples:    []string{"2345 6789 0123", "9876 5432 1098"},
+		Samples:    []string{"2341 2341 2346", "9876 5432 1096"}, // Verhoeff-valid
 	}
 
 	results, err := s.Detect(context.Background(), input)
@@ -118,3 +118,43 @@ func TestPatternStrategy_Metadata(t *testing.T) {
 		t.Errorf("weight out of range: %f", s.Weight())
 	}
 }
+
+func TestPatternStrategy_AadhaarRejectsLookalikes(t *testing.T) {
+	s := NewPatternStrategy()
+
+	// Twelve-digit order numbers share Aadhaar's shape but fail Verhoeff.
+	results, err := s.Detect(context.Background(), Input{
+		ColumnName: "order_ref",
+		Samples:    []string{"2345 6789 0123", "9876 5432 1098", "4567 8901 2345"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, r := range results {
+		if r.Type == types.PIITypeAadhaar {
+			t.Errorf("expected no AADHAAR detection for invalid check digits, got %+v", r)
+		}
+	}
+}
+
+func TestPatternStrategy_ConfidenceFollowsValidationRate(t *testing.T) {
+	s := NewPatternStrategy()
+	confidence := func(samples []string) float64 {
+		results, _ := s.Detect(context.Background(), Input{Samples: samples})
+		for _, r := range results {
+			if r.Type == types.PIITypeAadhaar {
+				return r.Confidence
+			}
+		}
+		return 0
+	}
+
+	all := confidence([]string{"234123412346", "987654321096", "456789012341"})
+	most := confidence([]string{"234123412346", "987654321096", "456789012345"})
+	if all < 0.95 {
+		t.Errorf("expected all-valid samples to auto-verify, got %.3f", all)
+	}
+	if most >= all || most == 0 {
+		t.Errorf("expected lower confidence when some samples fail: all=%.3f most=%.3f", all, most)
+	}
+}
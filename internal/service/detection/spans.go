@@ -0,0 +1,129 @@
+package detection
+
+import (
+	"context"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// Span Detection — locating PII in free text for redaction
+// =============================================================================
+
+// Span is one detection located in free text. Start and End are byte
+// offsets into the text; End is exclusive.
+type Span struct {
+	Start       int                    `json:"start"`
+	End         int                    `json:"end"`
+	Category    types.PIICategory      `json:"category"`
+	Type        types.PIIType          `json:"type"`
+	Sensitivity types.SensitivityLevel `json:"sensitivity"`
+	Confidence  float64                `json:"confidence"`
+	Method      types.DetectionMethod  `json:"method"`
+}
+
+// SpanStrategy is implemented by strategies that can say where in a text
+// they found something, not just whether a column holds it. Strategies
+// that only judge whole values (heuristic, industry, AI) do not.
+type SpanStrategy interface {
+	DetectSpans(ctx context.Context, text string) ([]Span, error)
+}
+
+// DetectSpans runs every span-capable strategy over text and returns the
+// spans found, sorted by position. Where spans overlap, the more confident
+// one wins, then the longer. A failing strategy is skipped; its error is
+// returned only if no strategy succeeded.
+func (d *ComposableDetector) DetectSpans(ctx context.Context, text string) ([]Span, error) {
+	return d.DetectSpansWhere(ctx, text, nil)
+}
+
+// DetectSpansWhere is DetectSpans keeping only the spans keep accepts.
+// The filter runs before overlaps are resolved, so a span the caller does
+// not want never hides one it does.
+func (d *ComposableDetector) DetectSpansWhere(ctx context.Context, text string, keep func(Span) bool) ([]Span, error) {
+	var all []Span
+	var firstErr error
+	ran := false
+	for _, s := range d.strategies {
+		ss, ok := s.(SpanStrategy)
+		if !ok {
+			continue
+		}
+		spans, err := ss.DetectSpans(ctx, text)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		ran = true
+		for _, sp := range spans {
+			if d.calibration != nil {
+				sp.Confidence = d.calibration.AdjustConfidence(sp.Method, sp.Confidence)
+			}
+			if keep == nil || keep(sp) {
+				all = append(all, sp)
+			}
+		}
+	}
+	if !ran && firstErr != nil {
+		return nil, firstErr
+	}
+	return resolveOverlaps(all), nil
+}
+
+// resolveOverlaps keeps the best of each run of overlapping spans in one
+// sweep by position, so large inputs with many matches stay O(n log n).
+func resolveOverlaps(spans []Span) []Span {
+	if len(spans) == 0 {
+		return nil
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	kept := spans[:0]
+	best := spans[0]
+	for _, sp := range spans[1:] {
+		if sp.Start < best.End {
+			if betterSpan(sp, best) {
+				best = sp
+			}
+			continue
+		}
+		kept = append(kept, best)
+		best = sp
+	}
+	return append(kept, best)
+}
+
+// betterSpan reports whether a should win over the overlapping b: the more
+// confident, then the longer.
+func betterSpan(a, b Span) bool {
+	if a.Confidence != b.Confidence {
+		return a.Confidence > b.Confidence
+	}
+	return a.End-a.Start > b.End-b.Start
+}
+
+// atWordBoundary reports whether text[start:end] is not part of a longer
+// word or number, so "98765432101" does not yield a ten-digit phone number.
+func atWordBoundary(text string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:start])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -71,6 +71,9 @@ type Result struct {
 	Confidence  float64                `json:"confidence"` // 0.0–1.0
 	Method      types.DetectionMethod  `json:"method"`
 	Reasoning   string                 `json:"reasoning"`
+
+	// PromptVersion is the AI prompt that produced the result, if any.
+	PromptVersion string `json:"prompt_version,omitempty"`
 }
 
 // =============================================================================
@@ -104,7 +107,8 @@ type MergedDetection struct {
 	FinalConfidence float64                 `json:"final_confidence"` // Weighted merge of all strategies
 	Methods         []types.DetectionMethod `json:"methods"`          // Which strategies found this
 	Reasoning       string                  `json:"reasoning"`
-	RequiresReview  bool                    `json:"requires_review"` // true if confidence < 0.80
+	RequiresReview  bool                    `json:"requires_review"`          // true if confidence < 0.80
+	PromptVersion   string                  `json:"prompt_version,omitempty"` // AI prompt behind the detection, if any
 }
 
 // StrategyOutcome records what a single strategy found (or didn't find).
@@ -0,0 +1,200 @@
+package detection
+
+import (
+	"strings"
+)
+
+// Validator confirms that a value matching a pattern's shape is
+// structurally valid for its identifier type — check digits, reserved
+// ranges and embedded codes. Regexes alone cannot tell a real Aadhaar from
+// any other 12-digit number.
+type Validator func(value string) bool
+
+// minValidatedPassRate is the share of shape matches that must pass
+// validation for a validated pattern to be reported at all. Roughly one in
+// ten random digit strings passes a mod-10 check digit by chance, so a
+// column where most matches fail is not that identifier.
+const minValidatedPassRate = 0.5
+
+// validatedConfidence scores a pattern whose matches were validated.
+// passRate (valid ÷ shape matches) dominates, since failing a check digit
+// is strong evidence against the type; coverage (valid ÷ samples) adjusts
+// it the same way the regex score uses the detection rate.
+//
+//	confidence = 0.99 × passRate × (0.7 + 0.3 × coverage)
+func validatedConfidence(valid, matched, total int) float64 {
+	if valid == 0 || matched == 0 || total == 0 {
+		return 0
+	}
+	passRate := float64(valid) / float64(matched)
+	if passRate < minValidatedPassRate {
+		return 0
+	}
+	coverage := float64(valid) / float64(total)
+	return 0.99 * passRate * (0.7 + 0.3*coverage)
+}
+
+// =============================================================================
+// Identifier Validators
+// =============================================================================
+
+// ValidAadhaar checks a 12-digit Aadhaar number: it cannot start with 0 or
+// 1 and its last digit is a Verhoeff check digit.
+func ValidAadhaar(value string) bool {
+	s := stripSeparators(value)
+	if len(s) != 12 || s[0] < '2' || s[0] > '9' {
+		return false
+	}
+	return verhoeffValid(s)
+}
+
+// ValidCardNumber checks a 13–19 digit payment card number with Luhn.
+func ValidCardNumber(value string) bool {
+	s := stripSeparators(value)
+	if len(s) < 13 || len(s) > 19 {
+		return false
+	}
+	return luhnValid(s)
+}
+
+// ValidNPI checks a US National Provider Identifier. NPIs are 10 digits
+// starting with 1 or 2, and the Luhn check runs over the number prefixed
+// with the card issuer identifier 80840.
+func ValidNPI(value string) bool {
+	s := stripSeparators(value)
+	if len(s) != 10 || (s[0] != '1' && s[0] != '2') {
+		return false
+	}
+	return luhnValid("80840" + s)
+}
+
+// panEntityTypes are the holder-type codes allowed in the fourth character
+// of a PAN: Association of persons, Body of individuals, Company, Firm,
+// Government, HUF, artificial Juridical person, Local authority, Person and
+// Trust.
+const panEntityTypes = "ABCFGHJLPT"
+
+// ValidPAN checks an Indian Permanent Account Number: five letters, four
+// digits and a letter, where the fourth letter is a known holder type.
+func ValidPAN(value string) bool {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	if len(s) != 10 {
+		return false
+	}
+	for i := 0; i < 10; i++ {
+		c := s[i]
+		switch {
+		case i < 5 || i == 9:
+			if c < 'A' || c > 'Z' {
+				return false
+			}
+		default:
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+	}
+	return strings.IndexByte(panEntityTypes, s[3]) >= 0
+}
+
+// ValidIFSC checks an Indian Financial System Code: a four-letter bank
+// code, a reserved 0, and a six-character branch code.
+func ValidIFSC(value string) bool {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	if len(s) != 11 || s[4] != '0' {
+		return false
+	}
+	for i := 0; i < 4; i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	for i := 5; i < 11; i++ {
+		if !isAlnum(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+const base36 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// ValidGSTIN checks an Indian GST identification number: a two-digit state
+// code, the holder's PAN, an entity number, the literal Z, and a base-36
+// check character.
+func ValidGSTIN(value string) bool {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	if len(s) != 15 {
+		return false
+	}
+	if s[0] < '0' || s[0] > '9' || s[1] < '0' || s[1] > '9' {
+		return false
+	}
+	state := int(s[0]-'0')*10 + int(s[1]-'0')
+	if (state < 1 || state > 38) && state != 97 && state != 99 {
+		return false
+	}
+	if !ValidPAN(s[2:12]) || s[12] == '0' || !isAlnum(s[12]) || s[13] != 'Z' {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 14; i++ {
+		v := strings.IndexByte(base36, s[i])
+		if v < 0 {
+			return false
+		}
+		v *= i%2 + 1
+		sum += v/36 + v%36
+	}
+	return s[14] == base36[(36-sum%36)%36]
+}
+
+// ibanLengths holds the registered IBAN length for common countries.
+// Countries not listed are accepted at any length from 15 to 34.
+var ibanLengths = map[string]int{
+	"AE": 23, "AT": 20, "BE": 16, "BH": 22, "CH": 21, "CY": 28, "CZ": 24,
+	"DE": 22, "DK": 18, "EE": 20, "ES": 24, "FI": 18, "FR": 27, "GB": 22,
+	"GR": 27, "HR": 21, "HU": 28, "IE": 22, "IT": 27, "LT": 20, "LU": 20,
+	"LV": 21, "MT": 31, "NL": 18, "NO": 15, "PL": 28, "PT": 25, "QA": 29,
+	"RO": 24, "SA": 24, "SE": 24, "SI": 19, "SK": 24, "TR": 26,
+}
+
+// ValidIBAN checks an International Bank Account Number: country code,
+// registered length and the ISO 7064 mod-97 check digits.
+func ValidIBAN(value string) bool {
+	s := strings.ToUpper(stripSeparators(value))
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	if s[0] < 'A' || s[0] > 'Z' || s[1] < 'A' || s[1] > 'Z' {
+		return false
+	}
+	if want, ok := ibanLengths[s[:2]]; ok && len(s) != want {
+		return false
+	}
+	return mod97Valid(s)
+}
+
+// ValidSSN checks a US Social Security Number against the SSA's never-issued
+// ranges: area 000, 666 and 900–999, group 00 and serial 0000.
+func ValidSSN(value string) bool {
+	s := stripSeparators(value)
+	if len(s) != 9 {
+		return false
+	}
+	for i := 0; i < 9; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	area, group, serial := s[:3], s[3:5], s[5:]
+	if area == "000" || area == "666" || area[0] == '9' {
+		return false
+	}
+	return group != "00" && serial != "0000"
+}
+
+func isAlnum(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z')
+}
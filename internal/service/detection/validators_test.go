@@ -0,0 +1,70 @@
+package detection
+
+import "testing"
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate Validator
+		value    string
+		want     bool
+	}{
+		{"aadhaar valid", ValidAadhaar, "2341 2341 2346", true},
+		{"aadhaar bad check digit", ValidAadhaar, "2341 2341 2345", false},
+		{"aadhaar leading 1", ValidAadhaar, "1234 5678 9012", false},
+
+		{"card valid", ValidCardNumber, "5500-0000-0000-0004", true},
+		{"card bad luhn", ValidCardNumber, "4111111111111112", false},
+		{"card too short", ValidCardNumber, "4111111111", false},
+
+		{"npi valid", ValidNPI, "1234567893", true},
+		{"npi bad luhn", ValidNPI, "1234567890", false},
+		{"npi bad prefix", ValidNPI, "3234567893", false},
+
+		{"pan person", ValidPAN, "ABCPE1234F", true},
+		{"pan company", ValidPAN, "AAACR5055K", true},
+		{"pan unknown holder type", ValidPAN, "ABCDE1234F", false},
+		{"pan bad shape", ValidPAN, "ABCP1E234F", false},
+
+		{"ifsc valid", ValidIFSC, "SBIN0001234", true},
+		{"ifsc reserved char", ValidIFSC, "SBIN1001234", false},
+
+		{"gstin valid", ValidGSTIN, "27AAPFU0939F1ZV", true},
+		{"gstin bad check char", ValidGSTIN, "27AAPFU0939F1ZW", false},
+		{"gstin bad state", ValidGSTIN, "45AAPFU0939F1ZV", false},
+		{"gstin bad embedded pan", ValidGSTIN, "27AAPDU0939F1ZV", false},
+
+		{"iban valid", ValidIBAN, "DE89370400440532013000", true},
+		{"iban spaced", ValidIBAN, "GB82 WEST 1234 5698 7654 32", true},
+		{"iban bad check digits", ValidIBAN, "DE89370400440532013001", false},
+		{"iban wrong length", ValidIBAN, "DE8937040044053201300", false},
+
+		{"ssn valid", ValidSSN, "123-45-6789", true},
+		{"ssn area 000", ValidSSN, "000-12-3456", false},
+		{"ssn area 666", ValidSSN, "666-12-3456", false},
+		{"ssn area 9xx", ValidSSN, "912-34-5678", false},
+		{"ssn group 00", ValidSSN, "123-00-4567", false},
+		{"ssn serial 0000", ValidSSN, "123-45-0000", false},
+	}
+	for _, tt := range tests {
+		if got := tt.validate(tt.value); got != tt.want {
+			t.Errorf("%s: validate(%q) = %v, want %v", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValidatedConfidence(t *testing.T) {
+	if got := validatedConfidence(0, 5, 5); got != 0 {
+		t.Errorf("expected 0 with no valid matches, got %.3f", got)
+	}
+	// One in ten random digit strings passes a check digit by chance.
+	if got := validatedConfidence(1, 10, 10); got != 0 {
+		t.Errorf("expected chance-level pass rate to be dropped, got %.3f", got)
+	}
+	if got := validatedConfidence(4, 4, 4); got < 0.98 {
+		t.Errorf("expected near-certain confidence when every sample validates, got %.3f", got)
+	}
+	if full, partial := validatedConfidence(4, 4, 8), validatedConfidence(4, 4, 4); full >= partial {
+		t.Errorf("expected coverage to raise confidence: %.3f vs %.3f", full, partial)
+	}
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+const (
+	maxRulePatternLength = 1024
+	maxRuleColumnHints   = 50
+	maxRuleTestSamples   = 100
+)
+
+var ruleTypeLabelPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,39}$`)
+
+var ruleCategories = map[types.PIICategory]bool{
+	types.PIICategoryIdentity:     true,
+	types.PIICategoryContact:      true,
+	types.PIICategoryFinancial:    true,
+	types.PIICategoryHealth:       true,
+	types.PIICategoryBiometric:    true,
+	types.PIICategoryGenetic:      true,
+	types.PIICategoryLocation:     true,
+	types.PIICategoryBehavioral:   true,
+	types.PIICategoryProfessional: true,
+	types.PIICategoryGovernmentID: true,
+	types.PIICategoryMinor:        true,
+	types.PIICategoryCredentials:  true,
+}
+
+var ruleSensitivities = map[types.SensitivityLevel]bool{
+	types.SensitivityLow:      true,
+	types.SensitivityMedium:   true,
+	types.SensitivityHigh:     true,
+	types.SensitivityCritical: true,
+}
+
+// DetectionRuleService manages tenant-defined custom detection rules and
+// supplies them to scans as an additional detection strategy.
+type DetectionRuleService struct {
+	repo     discovery.CustomDetectionRuleRepository
+	auditSvc *AuditService
+	logger   *slog.Logger
+}
+
+// NewDetectionRuleService creates a new DetectionRuleService.
+func NewDetectionRuleService(repo discovery.CustomDetectionRuleRepository, auditSvc *AuditService, logger *slog.Logger) *DetectionRuleService {
+	return &DetectionRuleService{
+		repo:     repo,
+		auditSvc: auditSvc,
+		logger:   logger.With("service", "detection_rule"),
+	}
+}
+
+// CreateDetectionRuleRequest holds input for creating a custom rule.
+type CreateDetectionRuleRequest struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	TypeLabel   string                  `json:"type_label"`
+	Category    types.PIICategory       `json:"category"`
+	Sensitivity types.SensitivityLevel  `json:"sensitivity"`
+	Pattern     string                  `json:"pattern"`
+	Validator   discovery.RuleValidator `json:"validator"`
+	ColumnHints []string                `json:"column_hints"`
+	Enabled     *bool                   `json:"enabled,omitempty"`
+}
+
+// UpdateDetectionRuleRequest holds input for updating a custom rule.
+type UpdateDetectionRuleRequest struct {
+	Name        *string                  `json:"name,omitempty"`
+	Description *string                  `json:"description,omitempty"`
+	TypeLabel   *string                  `json:"type_label,omitempty"`
+	Category    *types.PIICategory       `json:"category,omitempty"`
+	Sensitivity *types.SensitivityLevel  `json:"sensitivity,omitempty"`
+	Pattern     *string                  `json:"pattern,omitempty"`
+	Validator   *discovery.RuleValidator `json:"validator,omitempty"`
+	ColumnHints []string                 `json:"column_hints,omitempty"`
+	Enabled     *bool                    `json:"enabled,omitempty"`
+}
+
+// TestDetectionRuleRequest runs a rule definition against sample values
+// without saving it.
+type TestDetectionRuleRequest struct {
+	Rule       CreateDetectionRuleRequest `json:"rule"`
+	ColumnName string                     `json:"column_name"`
+	Samples    []string                   `json:"samples"`
+}
+
+// Create validates and stores a custom rule for the tenant.
+func (s *DetectionRuleService) Create(ctx context.Context, req CreateDetectionRuleRequest) (*discovery.CustomDetectionRule, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+
+	rule := ruleFromRequest(req)
+	rule.TenantID = tenantID
+	if err := validateDetectionRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	userID, _ := types.UserIDFromContext(ctx)
+	s.auditSvc.Log(ctx, userID, "DETECTION_RULE_CREATE", "DETECTION_RULE", rule.ID, nil,
+		map[string]any{"name": rule.Name, "type_label": rule.TypeLabel}, tenantID)
+
+	s.logger.Info("custom detection rule created",
+		slog.String("tenant_id", tenantID.String()),
+		slog.String("rule_id", rule.ID.String()),
+	)
+	return rule, nil
+}
+
+// List returns all custom rules for the tenant.
+func (s *DetectionRuleService) List(ctx context.Context) ([]discovery.CustomDetectionRule, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	return s.repo.GetByTenant(ctx, tenantID)
+}
+
+// GetByID returns one of the tenant's custom rules.
+func (s *DetectionRuleService) GetByID(ctx context.Context, id types.ID) (*discovery.CustomDetectionRule, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	return s.repo.GetByID(ctx, tenantID, id)
+}
+
+// Update modifies a custom rule.
+func (s *DetectionRuleService) Update(ctx context.Context, id types.ID, req UpdateDetectionRuleRequest) (*discovery.CustomDetectionRule, error) {
+	rule, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	old := map[string]any{"name": rule.Name, "type_label": rule.TypeLabel, "pattern": rule.Pattern, "enabled": rule.Enabled}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+	if req.TypeLabel != nil {
+		rule.TypeLabel = strings.ToUpper(strings.TrimSpace(*req.TypeLabel))
+	}
+	if req.Category != nil {
+		rule.Category = *req.Category
+	}
+	if req.Sensitivity != nil {
+		rule.Sensitivity = *req.Sensitivity
+	}
+	if req.Pattern != nil {
+		rule.Pattern = *req.Pattern
+	}
+	if req.Validator != nil {
+		rule.Validator = *req.Validator
+	}
+	if req.ColumnHints != nil {
+		rule.ColumnHints = req.ColumnHints
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if err := validateDetectionRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	userID, _ := types.UserIDFromContext(ctx)
+	s.auditSvc.Log(ctx, userID, "DETECTION_RULE_UPDATE", "DETECTION_RULE", rule.ID, old,
+		map[string]any{"name": rule.Name, "type_label": rule.TypeLabel, "pattern": rule.Pattern, "enabled": rule.Enabled}, rule.TenantID)
+	return rule, nil
+}
+
+// Delete removes a custom rule.
+func (s *DetectionRuleService) Delete(ctx context.Context, id types.ID) error {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return types.NewForbiddenError("tenant context required")
+	}
+	if err := s.repo.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+
+	userID, _ := types.UserIDFromContext(ctx)
+	s.auditSvc.Log(ctx, userID, "DETECTION_RULE_DELETE", "DETECTION_RULE", id, nil, nil, tenantID)
+	return nil
+}
+
+// Test runs an unsaved rule against sample values so tenants can check a
+// definition before enabling it.
+func (s *DetectionRuleService) Test(ctx context.Context, req TestDetectionRuleRequest) ([]detection.Result, error) {
+	if len(req.Samples) > maxRuleTestSamples {
+		return nil, types.NewValidationError(fmt.Sprintf("at most %d samples are allowed", maxRuleTestSamples), nil)
+	}
+	rule := ruleFromRequest(req.Rule)
+	if err := validateDetectionRule(rule); err != nil {
+		return nil, err
+	}
+	strategy, err := detection.NewCustomRuleStrategy([]detection.CustomRule{toDetectionRule(*rule)})
+	if err != nil {
+		return nil, types.NewValidationError(err.Error(), nil)
+	}
+	results, err := strategy.Detect(ctx, detection.Input{ColumnName: req.ColumnName, Samples: req.Samples})
+	if err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = []detection.Result{}
+	}
+	return results, nil
+}
+
+// StrategiesForTenant implements TenantStrategyProvider. It returns the
+// tenant's enabled rules as a single strategy, or none.
+func (s *DetectionRuleService) StrategiesForTenant(ctx context.Context, tenantID types.ID) ([]detection.Strategy, error) {
+	rules, err := s.repo.GetEnabledByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("load custom detection rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	specs := make([]detection.CustomRule, 0, len(rules))
+	for _, r := range rules {
+		specs = append(specs, toDetectionRule(r))
+	}
+	strategy, err := detection.NewCustomRuleStrategy(specs)
+	if err != nil {
+		return nil, fmt.Errorf("compile custom detection rules: %w", err)
+	}
+	return []detection.Strategy{strategy}, nil
+}
+
+func ruleFromRequest(req CreateDetectionRuleRequest) *discovery.CustomDetectionRule {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &discovery.CustomDetectionRule{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		TypeLabel:   strings.ToUpper(strings.TrimSpace(req.TypeLabel)),
+		Category:    req.Category,
+		Sensitivity: req.Sensitivity,
+		Pattern:     req.Pattern,
+		Validator:   req.Validator,
+		ColumnHints: req.ColumnHints,
+		Enabled:     enabled,
+	}
+}
+
+func toDetectionRule(r discovery.CustomDetectionRule) detection.CustomRule {
+	return detection.CustomRule{
+		Name:        r.Name,
+		TypeLabel:   r.TypeLabel,
+		Category:    r.Category,
+		Sensitivity: r.Sensitivity,
+		Pattern:     r.Pattern,
+		ColumnHints: r.ColumnHints,
+		Validator: detection.RuleValidator{
+			Checksum:  r.Validator.Checksum,
+			MinLength: r.Validator.MinLength,
+			MaxLength: r.Validator.MaxLength,
+			Prefixes:  r.Validator.Prefixes,
+		},
+	}
+}
+
+func validateDetectionRule(r *discovery.CustomDetectionRule) error {
+	if r.Name == "" {
+		return types.NewValidationError("name is required", nil)
+	}
+	if !ruleTypeLabelPattern.MatchString(r.TypeLabel) {
+		return types.NewValidationError("type_label must be 1-40 characters of A-Z, 0-9 and _, starting with a letter",
+			map[string]any{"type_label": r.TypeLabel})
+	}
+	if !ruleCategories[r.Category] {
+		return types.NewValidationError("invalid category", map[string]any{"category": r.Category})
+	}
+	if !ruleSensitivities[r.Sensitivity] {
+		return types.NewValidationError("invalid sensitivity", map[string]any{"sensitivity": r.Sensitivity})
+	}
+	if r.Pattern == "" && len(r.ColumnHints) == 0 {
+		return types.NewValidationError("a pattern or column_hints is required", nil)
+	}
+	if len(r.Pattern) > maxRulePatternLength {
+		return types.NewValidationError(fmt.Sprintf("pattern must be at most %d characters", maxRulePatternLength), nil)
+	}
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return types.NewValidationError("invalid pattern: "+err.Error(), nil)
+		}
+	}
+	if len(r.ColumnHints) > maxRuleColumnHints {
+		return types.NewValidationError(fmt.Sprintf("at most %d column_hints are allowed", maxRuleColumnHints), nil)
+	}
+
+	v := r.Validator
+	if v.Checksum != "" {
+		if !detection.ValidChecksum(v.Checksum) {
+			return types.NewValidationError("unsupported checksum", map[string]any{
+				"checksum": v.Checksum,
+				"allowed":  []string{detection.ChecksumLuhn, detection.ChecksumVerhoeff, detection.ChecksumMod97},
+			})
+		}
+		r.Validator.Checksum = strings.ToUpper(v.Checksum)
+	}
+	if v.MinLength < 0 || v.MaxLength < 0 || (v.MaxLength > 0 && v.MinLength > v.MaxLength) {
+		return types.NewValidationError("invalid validator length bounds", nil)
+	}
+	if (v.Checksum != "" || v.MinLength > 0 || v.MaxLength > 0 || len(v.Prefixes) > 0) && r.Pattern == "" {
+		return types.NewValidationError("validator requires a pattern", nil)
+	}
+	return nil
+}
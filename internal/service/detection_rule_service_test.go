@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/config"
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/infrastructure/connector"
+	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+func newTestDetectionRuleService() (*DetectionRuleService, *mockCustomDetectionRuleRepo) {
+	repo := newMockCustomDetectionRuleRepo()
+	auditSvc := NewAuditService(newMockAuditRepo(), slog.Default())
+	return NewDetectionRuleService(repo, auditSvc, slog.Default()), repo
+}
+
+func policyRuleRequest() CreateDetectionRuleRequest {
+	return CreateDetectionRuleRequest{
+		Name:        "Policy number",
+		TypeLabel:   "policy_number",
+		Category:    types.PIICategoryFinancial,
+		Sensitivity: types.SensitivityHigh,
+		Pattern:     `^POL-\d{8}$`,
+		ColumnHints: []string{"policy_no"},
+	}
+}
+
+func TestDetectionRuleService_Create(t *testing.T) {
+	svc, repo := newTestDetectionRuleService()
+	tenantID := types.NewID()
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, tenantID)
+
+	rule, err := svc.Create(ctx, policyRuleRequest())
+	require.NoError(t, err)
+	assert.Equal(t, tenantID, rule.TenantID)
+	assert.Equal(t, "POLICY_NUMBER", rule.TypeLabel)
+	assert.True(t, rule.Enabled)
+	assert.Len(t, repo.rules, 1)
+
+	_, err = svc.Create(context.Background(), policyRuleRequest())
+	assert.Error(t, err, "tenant context is required")
+}
+
+func TestDetectionRuleService_Create_Validation(t *testing.T) {
+	svc, _ := newTestDetectionRuleService()
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, types.NewID())
+
+	tests := []struct {
+		name   string
+		mutate func(*CreateDetectionRuleRequest)
+	}{
+		{"bad label", func(r *CreateDetectionRuleRequest) { r.TypeLabel = "1-bad label" }},
+		{"bad category", func(r *CreateDetectionRuleRequest) { r.Category = "SECRET_SAUCE" }},
+		{"bad pattern", func(r *CreateDetectionRuleRequest) { r.Pattern = `(` }},
+		{"bad checksum", func(r *CreateDetectionRuleRequest) { r.Validator.Checksum = "CRC32" }},
+		{"no pattern or hints", func(r *CreateDetectionRuleRequest) { r.Pattern = ""; r.ColumnHints = nil }},
+		{"validator without pattern", func(r *CreateDetectionRuleRequest) {
+			r.Pattern = ""
+			r.Validator.MinLength = 5
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := policyRuleRequest()
+			tt.mutate(&req)
+			_, err := svc.Create(ctx, req)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, types.ErrValidation)
+		})
+	}
+}
+
+func TestDetectionRuleService_Test(t *testing.T) {
+	svc, _ := newTestDetectionRuleService()
+
+	results, err := svc.Test(context.Background(), TestDetectionRuleRequest{
+		Rule:       policyRuleRequest(),
+		ColumnName: "ref",
+		Samples:    []string{"POL-12345678", "POL-87654321"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, types.PIIType("CUSTOM:POLICY_NUMBER"), results[0].Type)
+}
+
+func TestDetectionRuleService_StrategiesForTenant_OnlyEnabled(t *testing.T) {
+	svc, repo := newTestDetectionRuleService()
+	tenantID := types.NewID()
+	ctx := context.Background()
+
+	strategies, err := svc.StrategiesForTenant(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Empty(t, strategies)
+
+	require.NoError(t, repo.Create(ctx, &discovery.CustomDetectionRule{
+		TenantID: tenantID, Name: "Disabled", TypeLabel: "DISABLED", Pattern: `x`, Enabled: false,
+	}))
+	strategies, err = svc.StrategiesForTenant(ctx, tenantID)
+	require.NoError(t, err)
+	assert.Empty(t, strategies)
+
+	require.NoError(t, repo.Create(ctx, &discovery.CustomDetectionRule{
+		TenantID: tenantID, Name: "Policy", TypeLabel: "POLICY_NUMBER", Pattern: `^POL-\d{8}$`, Enabled: true,
+	}))
+	strategies, err = svc.StrategiesForTenant(ctx, tenantID)
+	require.NoError(t, err)
+	require.Len(t, strategies, 1)
+	assert.Equal(t, types.DetectionMethodCustom, strategies[0].Method())
+}
+
+func TestDiscoveryService_ScanDataSource_AppliesTenantRules(t *testing.T) {
+	// Setup Mocks
+	dsRepo := newMockDataSourceRepo()
+	piiRepo := newMockPIIClassificationRepo()
+	connectorMock := new(MockConnector)
+	mockStrategy := new(MockStrategy)
+	detector := detection.NewComposableDetector(mockStrategy)
+
+	registry := connector.NewConnectorRegistry(&config.Config{}, detector, nil)
+	testDSType := types.DataSourceType("TEST_MOCK")
+	registry.Register(testDSType, func() discovery.Connector {
+		return connectorMock
+	})
+
+	ruleSvc, ruleRepo := newTestDetectionRuleService()
+	svc := NewDiscoveryService(dsRepo, newMockDataInventoryRepo(), newMockDataEntityRepo(), newMockDataFieldRepo(),
+		piiRepo, newMockScanRunRepo(), registry, detector, ruleSvc, newMockEventBus(), slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{TenantID: types.NewID()},
+		Name:         "Test DB",
+		Type:         testDSType,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+	require.NoError(t, ruleRepo.Create(ctx, &discovery.CustomDetectionRule{
+		TenantID:    ds.TenantID,
+		Name:        "Policy number",
+		TypeLabel:   "POLICY_NUMBER",
+		Category:    types.PIICategoryFinancial,
+		Sensitivity: types.SensitivityHigh,
+		Pattern:     `^POL-\d{8}$`,
+		Enabled:     true,
+	}))
+
+	connectorMock.On("Connect", ctx, mock.Anything).Return(nil)
+	connectorMock.On("Close").Return(nil)
+	connectorMock.On("DiscoverSchema", ctx, mock.Anything).
+		Return(&discovery.DataInventory{}, []discovery.DataEntity{{Name: "policies", Type: discovery.EntityTypeTable}}, nil)
+	connectorMock.On("GetFields", ctx, "policies").Return([]discovery.DataField{{Name: "ref", DataType: "varchar"}}, nil)
+	connectorMock.On("SampleData", ctx, "policies", "ref", 10).Return([]string{"POL-12345678", "POL-87654321"}, nil)
+	mockStrategy.On("Detect", ctx, mock.Anything).Return([]detection.Result{}, nil)
+
+	// Execute
+	stats, err := svc.ScanDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.PIIDetected)
+
+	classifications, err := piiRepo.GetByDataSource(ctx, ds.ID, types.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, classifications.Items, 1)
+	assert.Equal(t, types.PIIType("CUSTOM:POLICY_NUMBER"), classifications.Items[0].Type)
+	assert.Equal(t, types.DetectionMethodCustom, classifications.Items[0].DetectionMethod)
+}
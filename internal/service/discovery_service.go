@@ -13,6 +13,18 @@ import (
 	"github.com/complyark/datalens/pkg/types"
 )
 
+// TenantStrategyProvider supplies tenant-specific detection strategies,
+// such as custom rules, that are added to the detector for each scan.
+type TenantStrategyProvider interface {
+	StrategiesForTenant(ctx context.Context, tenantID types.ID) ([]detection.Strategy, error)
+}
+
+// CalibrationProvider supplies a tenant's feedback-learned detector
+// calibration, or nil if the tenant has none.
+type CalibrationProvider interface {
+	CalibrationForTenant(ctx context.Context, tenantID types.ID) (*detection.Calibration, error)
+}
+
 // DiscoveryService orchestrates the scanning and PII detection process.
 type DiscoveryService struct {
 	dsRepo        discovery.DataSourceRepository
@@ -22,10 +34,12 @@ type DiscoveryService struct {
 	piiRepo       discovery.PIIClassificationRepository
 	scanRunRepo   discovery.ScanRunRepository
 
-	registry *connector.ConnectorRegistry
-	detector *detection.ComposableDetector
-	eventBus eventbus.EventBus
-	logger   *slog.Logger
+	registry         *connector.ConnectorRegistry
+	detector         *detection.ComposableDetector
+	tenantStrategies TenantStrategyProvider
+	calibrations     CalibrationProvider
+	eventBus         eventbus.EventBus
+	logger           *slog.Logger
 }
 
 // NewDiscoveryService creates a new DiscoveryService.
@@ -38,26 +52,76 @@ func NewDiscoveryService(
 	scanRunRepo discovery.ScanRunRepository,
 	registry *connector.ConnectorRegistry,
 	detector *detection.ComposableDetector,
+	tenantStrategies TenantStrategyProvider,
 	eb eventbus.EventBus,
 	logger *slog.Logger,
 ) *DiscoveryService {
 	return &DiscoveryService{
-		dsRepo:        dsRepo,
-		inventoryRepo: inventoryRepo,
-		entityRepo:    entityRepo,
-		fieldRepo:     fieldRepo,
-		piiRepo:       piiRepo,
-		scanRunRepo:   scanRunRepo,
-		registry:      registry,
-		detector:      detector,
-		eventBus:      eb,
-		logger:        logger.With("service", "discovery"),
+		dsRepo:           dsRepo,
+		inventoryRepo:    inventoryRepo,
+		entityRepo:       entityRepo,
+		fieldRepo:        fieldRepo,
+		piiRepo:          piiRepo,
+		scanRunRepo:      scanRunRepo,
+		registry:         registry,
+		detector:         detector,
+		tenantStrategies: tenantStrategies,
+		eventBus:         eb,
+		logger:           logger.With("service", "discovery"),
+	}
+}
+
+// ScanOptions controls checkpointing for a single scan invocation.
+type ScanOptions struct {
+	// Checkpoint, if set, lists entities already completed by an earlier
+	// attempt of the same scan run. Those entities are skipped and their
+	// counts are carried into the returned stats.
+	Checkpoint *discovery.ScanCheckpoint
+
+	// OnCheckpoint is invoked after each entity has been fully processed.
+	// Returning an error aborts the scan (e.g. when the run was cancelled).
+	OnCheckpoint func(ctx context.Context, cp discovery.ScanCheckpoint) error
+}
+
+// SetCalibrationProvider enables per-tenant detector calibration on scans.
+func (s *DiscoveryService) SetCalibrationProvider(p CalibrationProvider) {
+	s.calibrations = p
+}
+
+// detectorFor returns the shared detector extended with the tenant's own
+// strategies and calibration. Failing to load either degrades to the shared
+// behaviour rather than failing the scan.
+func (s *DiscoveryService) detectorFor(ctx context.Context, tenantID types.ID) *detection.ComposableDetector {
+	detector := s.detector
+	if s.tenantStrategies != nil {
+		extra, err := s.tenantStrategies.StrategiesForTenant(ctx, tenantID)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to load tenant detection strategies", "tenant_id", tenantID, "error", err)
+		} else if len(extra) > 0 {
+			detector = detector.WithStrategies(extra...)
+		}
+	}
+	if s.calibrations != nil {
+		cal, err := s.calibrations.CalibrationForTenant(ctx, tenantID)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to load detection calibration", "tenant_id", tenantID, "error", err)
+		} else if cal != nil {
+			detector = detector.WithCalibration(cal)
+		}
 	}
+	return detector
 }
 
 // ScanDataSource initiates a full scan of a data source.
 // It detects schema changes and scans for PII.
 func (s *DiscoveryService) ScanDataSource(ctx context.Context, dataSourceID types.ID) (*discovery.ScanStats, error) {
+	return s.ScanDataSourceWithOptions(ctx, dataSourceID, ScanOptions{})
+}
+
+// ScanDataSourceWithOptions scans a data source, resuming from and reporting
+// checkpoints as configured in opts. Cancelling ctx stops the scan between
+// fields and entities.
+func (s *DiscoveryService) ScanDataSourceWithOptions(ctx context.Context, dataSourceID types.ID, opts ScanOptions) (*discovery.ScanStats, error) {
 	start := time.Now()
 
 	// 1. Fetch Data Source
@@ -143,6 +207,10 @@ func (s *DiscoveryService) ScanDataSource(ctx context.Context, dataSourceID type
 		inventory = existingInv
 	}
 
+	// Tenant strategies (custom rules) apply to the pull-based path below;
+	// scannable connectors run their own detector.
+	detector := s.detectorFor(ctx, ds.TenantID)
+
 	// 6. Check for Scannable Connector (Custom Scan Logic / Streaming)
 	piiCount := 0
 	if scannable, ok := conn.(connector.ScannableConnector); ok {
@@ -262,8 +330,42 @@ func (s *DiscoveryService) ScanDataSource(ctx context.Context, dataSourceID type
 	}
 
 	// 6. Process Entities (Standard Loop)
-	// piiCount is already declared above
+	var checkpoint discovery.ScanCheckpoint
+	if opts.Checkpoint != nil {
+		checkpoint = *opts.Checkpoint
+		checkpoint.CompletedEntities = append([]string(nil), opts.Checkpoint.CompletedEntities...)
+		if len(checkpoint.CompletedEntities) > 0 {
+			s.logger.InfoContext(ctx, "resuming scan from checkpoint",
+				"data_source_id", ds.ID,
+				"completed_entities", len(checkpoint.CompletedEntities))
+		}
+	}
+	checkpoint.TotalEntities = len(entities)
+	piiCount = checkpoint.PIIDetected
+	fieldsScanned := checkpoint.FieldsScanned
+
+	// Schema drift tracking: the previous snapshot is the baseline; a
+	// missing baseline (first scan) records the schema without alerting.
+	previousSchema := inventory.SchemaSnapshot
+	currentSchema := make(discovery.SchemaSnapshot, len(entities))
+	newFieldPII := make(map[string]types.PIIType)
+	var aiStats *discovery.AIDetectionStats
+
 	for _, entity := range entities {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if checkpoint.IsCompleted(entity.Name) {
+			// Processed before the scan was interrupted; its fields were
+			// stored then, and may not be in the previous snapshot
+			if fields, ok := s.storedEntitySchema(ctx, inventory.ID, entity.Name); ok {
+				currentSchema[entity.Name] = fields
+			} else if prev, ok := previousSchema[entity.Name]; ok {
+				currentSchema[entity.Name] = prev
+			}
+			continue
+		}
+
 		entity.InventoryID = inventory.ID
 
 		// Create/Update Entity (Simplified: always create if not exists, skipping update logic for brevity)
@@ -297,13 +399,27 @@ func (s *DiscoveryService) ScanDataSource(ctx context.Context, dataSourceID type
 		fields, err := conn.GetFields(ctx, entity.Name)
 		if err != nil {
 			s.logger.WarnContext(ctx, "failed to get fields", "entity", entity.Name, "error", err)
+			if prev, ok := previousSchema[entity.Name]; ok {
+				currentSchema[entity.Name] = prev
+			}
 			continue
 		}
 
 		existingFields, _ := s.fieldRepo.GetByEntity(ctx, entityID)
+		entitySchema := make(map[string]string, len(fields))
+		piiTypes := make(map[string]types.PIIType)
+		currentSchema[entity.Name] = entitySchema
+		inputs := make([]detection.Input, 0, len(fields))
+		scanned := make([]scannedField, 0, len(fields))
 
 		for _, field := range fields {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			field.EntityID = entityID
+			fieldsScanned++
+			entitySchema[field.Name] = field.DataType
+			isNewField := len(previousSchema) > 0 && !previousSchema.HasField(entity.Name, field.Name)
 
 			// Check if field exists
 			var fieldID types.ID
@@ -312,47 +428,73 @@ func (s *DiscoveryService) ScanDataSource(ctx context.Context, dataSourceID type
 				if ef.Name == field.Name {
 					fieldID = ef.ID
 					fExists = true
+					if ef.DataType != field.DataType {
+						// Column was retyped at the source — keep inventory in sync
+						ef.DataType = field.DataType
+						if err := s.fieldRepo.Update(ctx, &ef); err != nil {
+							s.logger.WarnContext(ctx, "failed to update retyped field", "field", field.Name, "error", err)
+						}
+					}
 					break
 				}
 			}
 
 			if !fExists {
+				// Every column new since the last scan awaits review, even
+				// if it has no data to detect PII in yet
+				field.NeedsReview = isNewField
 				if err := s.fieldRepo.Create(ctx, &field); err != nil {
 					return nil, err
 				}
 				fieldID = field.ID
 			}
 
-			// 8. Sample & Detect PII
+			// 8. Sample; detection runs once the entity's fields are collected
 			samples, err := conn.SampleData(ctx, entity.Name, field.Name, 10) // Limit 10 samples
 			if err != nil {
 				s.logger.WarnContext(ctx, "failed to sample data", "field", field.Name, "error", err)
 			}
 
-			// Only run detection if we have samples or use column name heuristic
-			detectionInput := detection.Input{
+			inputs = append(inputs, detection.Input{
 				TableName:  entity.Name,
 				ColumnName: field.Name,
 				DataType:   field.DataType,
 				Samples:    samples,
 				// AdjacentColumns: ... (could gather all field names first)
-			}
+			})
+			scanned = append(scanned, scannedField{id: fieldID, name: field.Name, isNew: isNewField})
+		}
 
-			report, err := s.detector.Detect(ctx, detectionInput)
-			if err != nil {
-				s.logger.WarnContext(ctx, "detection failed", "field", field.Name, "error", err)
-				continue
+		// 9. Detect PII for the whole entity at once so batch strategies
+		// (AI) make one call per table instead of one per column.
+		reports, usage, err := detector.DetectBatch(ctx, inputs)
+		if err != nil {
+			s.logger.WarnContext(ctx, "detection failed", "entity", entity.Name, "error", err)
+		}
+		if usage != nil {
+			if aiStats == nil {
+				aiStats = &discovery.AIDetectionStats{}
 			}
+			aiStats.Columns += usage.Columns
+			aiStats.Calls += usage.Calls
+			aiStats.TokensUsed += usage.TokensUsed
+			aiStats.TokensSaved += usage.TokensSaved
+			aiStats.Latency += usage.Duration
+			aiStats.LatencySaved += usage.LatencySaved
+		}
 
+		for i, report := range reports {
+			field := scanned[i]
 			if report.IsPII && report.TopMatch != nil {
 				piiCount++
+				piiTypes[field.name] = report.TopMatch.Type
 
 				// Create Classification
 				cl := discovery.PIIClassification{
-					FieldID:         fieldID,
+					FieldID:         field.id,
 					DataSourceID:    ds.ID,
 					EntityName:      entity.Name,
-					FieldName:       field.Name,
+					FieldName:       field.name,
 					Category:        report.TopMatch.Category,
 					Type:            report.TopMatch.Type,
 					Sensitivity:     report.TopMatch.Sensitivity,
@@ -360,6 +502,11 @@ func (s *DiscoveryService) ScanDataSource(ctx context.Context, dataSourceID type
 					DetectionMethod: report.TopMatch.Methods[0], // Primary method
 					Status:          types.VerificationPending,
 					Reasoning:       report.TopMatch.Reasoning,
+					PromptVersion:   report.TopMatch.PromptVersion,
+				}
+				if field.isNew {
+					cl.Reasoning = "New column since last scan; " + cl.Reasoning
+					newFieldPII[entity.Name+"."+field.name] = cl.Type
 				}
 
 				if err := s.piiRepo.Create(ctx, &cl); err != nil {
@@ -367,8 +514,34 @@ func (s *DiscoveryService) ScanDataSource(ctx context.Context, dataSourceID type
 				}
 			}
 		}
+
+		s.assessReidentification(ctx, conn, entityID, entity.Name, fields, piiTypes)
+
+		// Entity fully processed — record checkpoint
+		now := time.Now()
+		checkpoint.CompletedEntities = append(checkpoint.CompletedEntities, entity.Name)
+		checkpoint.FieldsScanned = fieldsScanned
+		checkpoint.PIIDetected = piiCount
+		checkpoint.UpdatedAt = &now
+		if opts.OnCheckpoint != nil {
+			if err := opts.OnCheckpoint(ctx, checkpoint); err != nil {
+				return nil, fmt.Errorf("checkpoint: %w", err)
+			}
+		}
 	}
 
+	// Incremental scans only return changed entities; carry the rest over
+	// so they are not reported as removed.
+	if !discoveryInput.ChangedSince.IsZero() {
+		for name, fields := range previousSchema {
+			if _, ok := currentSchema[name]; !ok {
+				currentSchema[name] = fields
+			}
+		}
+	}
+	schemaChanges := s.detectSchemaDrift(ctx, ds, inventory, currentSchema, newFieldPII)
+	s.buildSubjectGraph(ctx, conn, ds, inventory)
+
 	// Update inventory stats
 	inventory.PIIFieldsCount = piiCount
 	s.inventoryRepo.Update(ctx, inventory)
@@ -378,12 +551,96 @@ func (s *DiscoveryService) ScanDataSource(ctx context.Context, dataSourceID type
 
 	return &discovery.ScanStats{
 		EntitiesScanned: len(entities),
-		FieldsScanned:   inventory.TotalFields,
+		FieldsScanned:   fieldsScanned,
 		PIIDetected:     piiCount,
 		Duration:        duration,
+		SchemaChanges:   schemaChanges,
+		AIDetection:     aiStats,
 	}, nil
 }
 
+// detectSchemaDrift diffs the scanned schema against the inventory's last
+// snapshot, publishes a schema.drift_detected event when it changed, and
+// records the new snapshot on the inventory. It returns the change count.
+func (s *DiscoveryService) detectSchemaDrift(
+	ctx context.Context,
+	ds *discovery.DataSource,
+	inventory *discovery.DataInventory,
+	current discovery.SchemaSnapshot,
+	newFieldPII map[string]types.PIIType,
+) int {
+	previous := inventory.SchemaSnapshot
+	inventory.SchemaSnapshot = current
+	inventory.SchemaVersion = current.Fingerprint()
+
+	if len(previous) == 0 || previous.Fingerprint() == inventory.SchemaVersion {
+		return 0
+	}
+
+	drift := discovery.DiffSchemas(previous, current)
+	if !drift.HasChanges() {
+		return 0
+	}
+	drift.DataSourceID = ds.ID
+	drift.DataSourceName = ds.Name
+	for i := range drift.AddedFields {
+		f := &drift.AddedFields[i]
+		f.PIIType = newFieldPII[f.Entity+"."+f.Field]
+	}
+
+	s.logger.InfoContext(ctx, "schema drift detected",
+		"data_source_id", ds.ID,
+		"added_fields", len(drift.AddedFields),
+		"removed_fields", len(drift.RemovedFields),
+		"retyped_fields", len(drift.RetypedFields),
+		"added_entities", len(drift.AddedEntities),
+		"removed_entities", len(drift.RemovedEntities),
+	)
+
+	if s.eventBus != nil {
+		event := eventbus.NewEvent(eventbus.EventSchemaDriftDetected, "discovery", ds.TenantID, drift)
+		if err := s.eventBus.Publish(ctx, event); err != nil {
+			s.logger.WarnContext(ctx, "failed to publish schema drift event", "error", err)
+		}
+	}
+
+	return drift.ChangeCount()
+}
+
+// scannedField remembers a sampled field until its entity's detection
+// reports come back.
+// storedEntitySchema returns the field types of the inventory's entity as
+// stored, or false if they cannot be read.
+func (s *DiscoveryService) storedEntitySchema(ctx context.Context, inventoryID types.ID, name string) (map[string]string, bool) {
+	entities, err := s.entityRepo.GetByInventory(ctx, inventoryID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to load stored entities", "entity", name, "error", err)
+		return nil, false
+	}
+	for _, e := range entities {
+		if e.Name != name {
+			continue
+		}
+		fields, err := s.fieldRepo.GetByEntity(ctx, e.ID)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to load stored fields", "entity", name, "error", err)
+			return nil, false
+		}
+		schema := make(map[string]string, len(fields))
+		for _, f := range fields {
+			schema[f.Name] = f.DataType
+		}
+		return schema, true
+	}
+	return nil, false
+}
+
+type scannedField struct {
+	id    types.ID
+	name  string
+	isNew bool
+}
+
 func (s *DiscoveryService) logError(ctx context.Context, dsID types.ID, msg string, err error) {
 	s.logger.ErrorContext(ctx, msg, "data_source_id", dsID, "error", err)
 }
@@ -393,6 +650,36 @@ func (s *DiscoveryService) GetClassifications(ctx context.Context, tenantID type
 	return s.piiRepo.GetClassifications(ctx, tenantID, filter)
 }
 
+// MarkFieldReviewed clears the review flag a scan sets on new columns. The
+// field must belong to one of the tenant's data sources.
+func (s *DiscoveryService) MarkFieldReviewed(ctx context.Context, tenantID, fieldID types.ID) (*discovery.DataField, error) {
+	field, err := s.fieldRepo.GetByID(ctx, fieldID)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.entityRepo.GetByID(ctx, field.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	inventory, err := s.inventoryRepo.GetByID(ctx, entity.InventoryID)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := s.dsRepo.GetByID(ctx, inventory.DataSourceID)
+	if err != nil {
+		return nil, err
+	}
+	if ds.TenantID != tenantID {
+		return nil, types.NewNotFoundError("DataField", fieldID)
+	}
+
+	field.NeedsReview = false
+	if err := s.fieldRepo.Update(ctx, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
 // TestConnection tests connectivity to a data source.
 // It resolves the connector and calls its Connect/Close methods.
 func (s *DiscoveryService) TestConnection(ctx context.Context, dataSourceID types.ID) error {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -13,6 +14,7 @@ import (
 	"github.com/complyark/datalens/internal/domain/discovery"
 	"github.com/complyark/datalens/internal/infrastructure/connector"
 	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/eventbus"
 	"github.com/complyark/datalens/pkg/types"
 )
 
@@ -41,6 +43,19 @@ func (m *MockStrategy) Detect(ctx context.Context, input detection.Input) ([]det
 	return args.Get(0).([]detection.Result), args.Error(1)
 }
 
+// MockBatchStrategy is a MockStrategy that classifies whole entities.
+type MockBatchStrategy struct {
+	MockStrategy
+}
+
+func (m *MockBatchStrategy) DetectBatch(ctx context.Context, inputs []detection.Input) ([][]detection.Result, *detection.BatchUsage, error) {
+	args := m.Called(ctx, inputs)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([][]detection.Result), args.Get(1).(*detection.BatchUsage), args.Error(2)
+}
+
 // =============================================================================
 // Tests
 // =============================================================================
@@ -68,7 +83,7 @@ func TestDiscoveryService_ScanDataSource_Success(t *testing.T) {
 
 	// Setup Service
 	scanRunRepo := newMockScanRunRepo()
-	svc := NewDiscoveryService(dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, scanRunRepo, registry, detector, eb, slog.Default())
+	svc := NewDiscoveryService(dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, scanRunRepo, registry, detector, nil, eb, slog.Default())
 
 	ctx := context.Background()
 	tenantID := types.NewID()
@@ -122,7 +137,7 @@ func TestDiscoveryService_ScanDataSource_Success(t *testing.T) {
 	mockStrategy.On("Detect", ctx, mock.Anything).Return(expectedDetection, nil)
 
 	// Execute
-	err := svc.ScanDataSource(ctx, ds.ID)
+	_, err := svc.ScanDataSource(ctx, ds.ID)
 	require.NoError(t, err)
 
 	// Verify Persistence
@@ -152,3 +167,316 @@ func TestDiscoveryService_ScanDataSource_Success(t *testing.T) {
 	connectorMock.AssertExpectations(t)
 	mockStrategy.AssertExpectations(t)
 }
+
+func TestDiscoveryService_ScanDataSourceWithOptions_SkipsCheckpointedEntities(t *testing.T) {
+	// Setup Mocks
+	dsRepo := newMockDataSourceRepo()
+	connectorMock := new(MockConnector)
+	mockStrategy := new(MockStrategy)
+	detector := detection.NewComposableDetector(mockStrategy)
+
+	registry := connector.NewConnectorRegistry(&config.Config{}, detector, nil)
+	testDSType := types.DataSourceType("TEST_MOCK")
+	registry.Register(testDSType, func() discovery.Connector {
+		return connectorMock
+	})
+
+	svc := NewDiscoveryService(dsRepo, newMockDataInventoryRepo(), newMockDataEntityRepo(), newMockDataFieldRepo(),
+		newMockPIIClassificationRepo(), newMockScanRunRepo(), registry, detector, nil, newMockEventBus(), slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{TenantID: types.NewID()},
+		Name:         "Test DB",
+		Type:         testDSType,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+
+	connectorMock.On("Connect", ctx, mock.Anything).Return(nil)
+	connectorMock.On("Close").Return(nil)
+	entities := []discovery.DataEntity{
+		{Name: "users", Type: discovery.EntityTypeTable},
+		{Name: "orders", Type: discovery.EntityTypeTable},
+	}
+	connectorMock.On("DiscoverSchema", ctx, mock.Anything).Return(&discovery.DataInventory{}, entities, nil)
+
+	// Only "orders" should be processed — "users" is checkpointed
+	connectorMock.On("GetFields", ctx, "orders").Return([]discovery.DataField{{Name: "email", DataType: "varchar"}}, nil)
+	connectorMock.On("SampleData", ctx, "orders", "email", 10).Return([]string{"a@example.com"}, nil)
+	mockStrategy.On("Detect", ctx, mock.Anything).Return([]detection.Result{{
+		Category:    types.PIICategoryContact,
+		Type:        types.PIITypeEmail,
+		Sensitivity: types.SensitivityMedium,
+		Confidence:  0.95,
+		Method:      types.DetectionMethodAI,
+	}}, nil)
+
+	var checkpoints []discovery.ScanCheckpoint
+	opts := ScanOptions{
+		Checkpoint: &discovery.ScanCheckpoint{CompletedEntities: []string{"users"}, FieldsScanned: 4, PIIDetected: 2},
+		OnCheckpoint: func(_ context.Context, cp discovery.ScanCheckpoint) error {
+			checkpoints = append(checkpoints, cp)
+			return nil
+		},
+	}
+
+	// Execute
+	stats, err := svc.ScanDataSourceWithOptions(ctx, ds.ID, opts)
+	require.NoError(t, err)
+
+	// Verify counts carry over from the checkpoint
+	assert.Equal(t, 3, stats.PIIDetected)
+	assert.Equal(t, 5, stats.FieldsScanned)
+	require.Len(t, checkpoints, 1)
+	assert.Equal(t, []string{"users", "orders"}, checkpoints[0].CompletedEntities)
+	assert.Equal(t, 2, checkpoints[0].TotalEntities)
+	connectorMock.AssertNotCalled(t, "GetFields", ctx, "users")
+}
+
+func TestDiscoveryService_ScanDataSourceWithOptions_ResumeSnapshotsStoredFields(t *testing.T) {
+	// Setup Mocks
+	dsRepo := newMockDataSourceRepo()
+	invRepo := newMockDataInventoryRepo()
+	entityRepo := newMockDataEntityRepo()
+	fieldRepo := newMockDataFieldRepo()
+	connectorMock := new(MockConnector)
+	mockStrategy := new(MockStrategy)
+	detector := detection.NewComposableDetector(mockStrategy)
+
+	registry := connector.NewConnectorRegistry(&config.Config{}, detector, nil)
+	testDSType := types.DataSourceType("TEST_MOCK")
+	registry.Register(testDSType, func() discovery.Connector {
+		return connectorMock
+	})
+
+	svc := NewDiscoveryService(dsRepo, invRepo, entityRepo, fieldRepo,
+		newMockPIIClassificationRepo(), newMockScanRunRepo(), registry, detector, nil, newMockEventBus(), slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{TenantID: types.NewID()},
+		Name:         "Test DB",
+		Type:         testDSType,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+
+	// The last completed scan only saw "orders"; "users" first appeared in
+	// the interrupted run, which stored its fields before stopping.
+	inv := &discovery.DataInventory{
+		DataSourceID:   ds.ID,
+		SchemaSnapshot: discovery.SchemaSnapshot{"orders": {"email": "varchar"}},
+	}
+	require.NoError(t, invRepo.Create(ctx, inv))
+	users := &discovery.DataEntity{InventoryID: inv.ID, Name: "users", Type: discovery.EntityTypeTable}
+	require.NoError(t, entityRepo.Create(ctx, users))
+	require.NoError(t, fieldRepo.Create(ctx, &discovery.DataField{EntityID: users.ID, Name: "phone", DataType: "text"}))
+
+	connectorMock.On("Connect", ctx, mock.Anything).Return(nil)
+	connectorMock.On("Close").Return(nil)
+	entities := []discovery.DataEntity{
+		{Name: "users", Type: discovery.EntityTypeTable},
+		{Name: "orders", Type: discovery.EntityTypeTable},
+	}
+	connectorMock.On("DiscoverSchema", ctx, mock.Anything).Return(&discovery.DataInventory{}, entities, nil)
+	connectorMock.On("GetFields", ctx, "orders").Return([]discovery.DataField{{Name: "email", DataType: "varchar"}}, nil)
+	connectorMock.On("SampleData", ctx, "orders", "email", 10).Return([]string{"a@example.com"}, nil)
+	mockStrategy.On("Detect", ctx, mock.Anything).Return([]detection.Result{}, nil)
+
+	opts := ScanOptions{
+		Checkpoint: &discovery.ScanCheckpoint{CompletedEntities: []string{"users"}},
+	}
+
+	// Execute
+	_, err := svc.ScanDataSourceWithOptions(ctx, ds.ID, opts)
+	require.NoError(t, err)
+
+	// The resumed entity is snapshotted from its stored fields
+	stored, err := invRepo.GetByDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"phone": "text"}, stored.SchemaSnapshot["users"])
+	assert.Equal(t, map[string]string{"email": "varchar"}, stored.SchemaSnapshot["orders"])
+}
+
+func TestDiscoveryService_ScanDataSource_PublishesSchemaDrift(t *testing.T) {
+	// Setup Mocks
+	dsRepo := newMockDataSourceRepo()
+	invRepo := newMockDataInventoryRepo()
+	connectorMock := new(MockConnector)
+	mockStrategy := new(MockStrategy)
+	detector := detection.NewComposableDetector(mockStrategy)
+	eb := newMockEventBus()
+
+	registry := connector.NewConnectorRegistry(&config.Config{}, detector, nil)
+	testDSType := types.DataSourceType("TEST_MOCK")
+	registry.Register(testDSType, func() discovery.Connector {
+		return connectorMock
+	})
+
+	fieldRepo := newMockDataFieldRepo()
+	svc := NewDiscoveryService(dsRepo, invRepo, newMockDataEntityRepo(), fieldRepo,
+		newMockPIIClassificationRepo(), newMockScanRunRepo(), registry, detector, nil, eb, slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{TenantID: types.NewID()},
+		Name:         "Test DB",
+		Type:         testDSType,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+
+	// Previous inventory knew users(id, email)
+	previous := discovery.SchemaSnapshot{"users": {"id": "uuid", "email": "varchar"}}
+	require.NoError(t, invRepo.Create(ctx, &discovery.DataInventory{
+		DataSourceID:   ds.ID,
+		SchemaSnapshot: previous,
+		SchemaVersion:  previous.Fingerprint(),
+	}))
+
+	connectorMock.On("Connect", ctx, mock.Anything).Return(nil)
+	connectorMock.On("Close").Return(nil)
+	connectorMock.On("DiscoverSchema", ctx, mock.Anything).
+		Return(&discovery.DataInventory{}, []discovery.DataEntity{{Name: "users", Type: discovery.EntityTypeTable}}, nil)
+
+	// Engineering shipped a new pan_number column, and a referrer_contact
+	// column with no data yet
+	connectorMock.On("GetFields", ctx, "users").Return([]discovery.DataField{
+		{Name: "id", DataType: "uuid"},
+		{Name: "email", DataType: "varchar"},
+		{Name: "pan_number", DataType: "varchar"},
+		{Name: "referrer_contact", DataType: "varchar"},
+	}, nil)
+	connectorMock.On("SampleData", ctx, "users", mock.Anything, 10).Return([]string{}, nil)
+
+	mockStrategy.On("Detect", ctx, mock.MatchedBy(func(in detection.Input) bool { return in.ColumnName == "pan_number" })).
+		Return([]detection.Result{{
+			Category:    types.PIICategoryGovernmentID,
+			Type:        types.PIITypePAN,
+			Sensitivity: types.SensitivityHigh,
+			Confidence:  0.9,
+			Method:      types.DetectionMethodHeuristic,
+			Reasoning:   "column name",
+		}}, nil)
+	mockStrategy.On("Detect", ctx, mock.Anything).Return([]detection.Result{}, nil)
+
+	// Execute
+	stats, err := svc.ScanDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+
+	// Verify drift was recorded and published
+	assert.Equal(t, 2, stats.SchemaChanges)
+	require.Len(t, eb.Events, 1)
+	assert.Equal(t, eventbus.EventSchemaDriftDetected, eb.Events[0].Type)
+	drift := eb.Events[0].Data.(discovery.SchemaDrift)
+	require.Len(t, drift.AddedFields, 2)
+	assert.Equal(t, "pan_number", drift.AddedFields[0].Field)
+	assert.Equal(t, types.PIITypePAN, drift.AddedFields[0].PIIType)
+	assert.Empty(t, drift.AddedFields[1].PIIType)
+
+	// Both new columns await review, detected or not
+	needsReview := map[string]bool{}
+	for _, f := range fieldRepo.fields {
+		needsReview[f.Name] = f.NeedsReview
+	}
+	assert.Equal(t, map[string]bool{"id": false, "email": false, "pan_number": true, "referrer_contact": true}, needsReview)
+
+	inv, err := invRepo.GetByDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+	assert.True(t, inv.SchemaSnapshot.HasField("users", "pan_number"))
+	assert.Equal(t, inv.SchemaSnapshot.Fingerprint(), inv.SchemaVersion)
+}
+
+func TestDiscoveryService_MarkFieldReviewed(t *testing.T) {
+	dsRepo := newMockDataSourceRepo()
+	invRepo := newMockDataInventoryRepo()
+	entityRepo := newMockDataEntityRepo()
+	fieldRepo := newMockDataFieldRepo()
+	svc := NewDiscoveryService(dsRepo, invRepo, entityRepo, fieldRepo,
+		newMockPIIClassificationRepo(), newMockScanRunRepo(), nil, nil, nil, newMockEventBus(), slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{TenantEntity: types.TenantEntity{TenantID: types.NewID()}, Name: "CRM"}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+	inv := &discovery.DataInventory{DataSourceID: ds.ID}
+	require.NoError(t, invRepo.Create(ctx, inv))
+	entity := &discovery.DataEntity{InventoryID: inv.ID, Name: "users"}
+	require.NoError(t, entityRepo.Create(ctx, entity))
+	field := &discovery.DataField{EntityID: entity.ID, Name: "referrer_contact", NeedsReview: true}
+	require.NoError(t, fieldRepo.Create(ctx, field))
+
+	// Another tenant cannot clear the flag
+	_, err := svc.MarkFieldReviewed(ctx, types.NewID(), field.ID)
+	assert.True(t, types.IsNotFoundError(err))
+	stored, err := fieldRepo.GetByID(ctx, field.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.NeedsReview)
+
+	reviewed, err := svc.MarkFieldReviewed(ctx, ds.TenantID, field.ID)
+	require.NoError(t, err)
+	assert.False(t, reviewed.NeedsReview)
+}
+
+func TestDiscoveryService_ScanDataSource_BatchesDetectionPerEntity(t *testing.T) {
+	dsRepo := newMockDataSourceRepo()
+	piiRepo := newMockPIIClassificationRepo()
+	conn := new(MockConnector)
+
+	strategy := new(MockBatchStrategy)
+	detector := detection.NewComposableDetector(strategy)
+	registry := connector.NewConnectorRegistry(&config.Config{}, detector, nil)
+	dsType := types.DataSourceType("TEST_BATCH")
+	registry.Register(dsType, func() discovery.Connector { return conn })
+	svc := NewDiscoveryService(dsRepo, newMockDataInventoryRepo(), newMockDataEntityRepo(), newMockDataFieldRepo(), piiRepo, newMockScanRunRepo(), registry, detector, nil, newMockEventBus(), slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{BaseEntity: types.BaseEntity{ID: types.NewID()}, TenantID: types.NewID()},
+		Name:         "CRM",
+		Type:         dsType,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+
+	conn.On("Connect", ctx, mock.Anything).Return(nil)
+	conn.On("Close").Return(nil)
+	conn.On("DiscoverSchema", ctx, mock.Anything).Return(
+		&discovery.DataInventory{DataSourceID: ds.ID},
+		[]discovery.DataEntity{{Name: "contacts"}, {Name: "accounts"}},
+		nil,
+	)
+	conn.On("GetFields", ctx, "contacts").Return([]discovery.DataField{{Name: "email"}, {Name: "score"}}, nil)
+	conn.On("GetFields", ctx, "accounts").Return([]discovery.DataField{{Name: "owner_phone"}}, nil)
+	conn.On("SampleData", ctx, mock.Anything, mock.Anything, 10).Return([]string{}, nil)
+
+	email := detection.Result{Category: types.PIICategoryContact, Type: types.PIITypeEmail, Sensitivity: types.SensitivityMedium, Confidence: 0.9, Method: types.DetectionMethodAI}
+	phone := detection.Result{Category: types.PIICategoryContact, Type: types.PIITypePhone, Sensitivity: types.SensitivityMedium, Confidence: 0.9, Method: types.DetectionMethodAI}
+	// One call per entity, with every column of that entity.
+	strategy.On("DetectBatch", ctx, mock.MatchedBy(func(in []detection.Input) bool {
+		return len(in) == 2 && in[0].ColumnName == "email" && in[1].ColumnName == "score"
+	})).Return([][]detection.Result{{email}, nil}, &detection.BatchUsage{Columns: 2, Calls: 1, TokensUsed: 300, TokensSaved: 120, Duration: 2 * time.Second, LatencySaved: 2 * time.Second}, nil).Once()
+	strategy.On("DetectBatch", ctx, mock.MatchedBy(func(in []detection.Input) bool {
+		return len(in) == 1 && in[0].ColumnName == "owner_phone"
+	})).Return([][]detection.Result{{phone}}, &detection.BatchUsage{Columns: 1, Calls: 1, TokensUsed: 150, Duration: time.Second}, nil).Once()
+
+	stats, err := svc.ScanDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+	strategy.AssertExpectations(t)
+	strategy.AssertNotCalled(t, "Detect", mock.Anything, mock.Anything)
+
+	assert.Equal(t, 2, stats.PIIDetected)
+	assert.Equal(t, &discovery.AIDetectionStats{
+		Columns:      3,
+		Calls:        2,
+		TokensUsed:   450,
+		TokensSaved:  120,
+		Latency:      3 * time.Second,
+		LatencySaved: 2 * time.Second,
+	}, stats.AIDetection)
+
+	classifications, err := piiRepo.GetByDataSource(ctx, ds.ID, types.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, classifications.Items, 2)
+	byField := map[string]types.PIIType{}
+	for _, c := range classifications.Items {
+		byField[c.EntityName+"."+c.FieldName] = c.Type
+	}
+	assert.Equal(t, map[string]types.PIIType{"contacts.email": types.PIITypeEmail, "accounts.owner_phone": types.PIITypePhone}, byField)
+}
@@ -0,0 +1,927 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/complyark/datalens/internal/domain/compliance"
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/domain/governance"
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/internal/service/ai"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// DPIAService manages Data Protection Impact Assessments: a DPIA is
+// pre-filled from the tenant's inventory, drafted by the AI gateway, edited,
+// reviewed and approved, then reviewed periodically. Approved DPIAs are
+// linked into the RoPA.
+type DPIAService struct {
+	dpiaRepo       compliance.DPIARepository
+	purposeRepo    governance.PurposeRepository
+	dsRepo         discovery.DataSourceRepository
+	piiRepo        discovery.PIIClassificationRepository
+	thirdPartyRepo governance.ThirdPartyRepository
+	mappingRepo    governance.DataMappingRepository
+	tenantRepo     identity.TenantRepository
+	reidRisks      discovery.ReidentificationRiskRepository
+	aiGateway      ai.Gateway
+	prompts        *ai.PromptRegistry
+	auditSvc       *AuditService
+	logger         *slog.Logger
+	now            func() time.Time
+}
+
+// NewDPIAService creates a new DPIAService.
+func NewDPIAService(
+	dpiaRepo compliance.DPIARepository,
+	purposeRepo governance.PurposeRepository,
+	dsRepo discovery.DataSourceRepository,
+	piiRepo discovery.PIIClassificationRepository,
+	thirdPartyRepo governance.ThirdPartyRepository,
+	mappingRepo governance.DataMappingRepository,
+	tenantRepo identity.TenantRepository,
+	auditSvc *AuditService,
+	logger *slog.Logger,
+) *DPIAService {
+	return &DPIAService{
+		dpiaRepo:       dpiaRepo,
+		purposeRepo:    purposeRepo,
+		dsRepo:         dsRepo,
+		piiRepo:        piiRepo,
+		thirdPartyRepo: thirdPartyRepo,
+		mappingRepo:    mappingRepo,
+		tenantRepo:     tenantRepo,
+		prompts:        ai.NewPromptRegistry(nil, nil),
+		auditSvc:       auditSvc,
+		logger:         logger.With("service", "dpia"),
+		now:            time.Now,
+	}
+}
+
+// SetReidentificationRiskRepo adds the re-identification risk of the
+// assessed datasets to DPIA contexts.
+func (s *DPIAService) SetReidentificationRiskRepo(repo discovery.ReidentificationRiskRepository) {
+	s.reidRisks = repo
+}
+
+// SetAIGateway enables AI drafting of the risk narrative and mitigations,
+// with the prompt rendered from prompts.
+func (s *DPIAService) SetAIGateway(gateway ai.Gateway, prompts *ai.PromptRegistry) {
+	s.aiGateway = gateway
+	if prompts != nil {
+		s.prompts = prompts
+	}
+}
+
+// dpiaSensitiveCategories are the categories whose processing is itself a
+// high-risk indicator, as in the DPDPA adapter.
+var dpiaSensitiveCategories = []types.PIICategory{
+	types.PIICategoryHealth,
+	types.PIICategoryBiometric,
+	types.PIICategoryGenetic,
+	types.PIICategoryFinancial,
+	types.PIICategoryMinor,
+}
+
+// dpiaHighRiskReidScore is the minimum re-identification score (MEDIUM and
+// above) of the datasets listed in a DPIA context.
+const dpiaHighRiskReidScore = 20
+
+// CreateDPIARequest holds input for a new DPIA.
+type CreateDPIARequest struct {
+	Title              string     `json:"title"`
+	Description        string     `json:"description"`
+	PurposeIDs         []types.ID `json:"purpose_ids"`     // Empty: every purpose
+	DataSourceIDs      []types.ID `json:"data_source_ids"` // Empty: every data source
+	ReviewIntervalDays int        `json:"review_interval_days"`
+}
+
+// UpdateDPIARequest holds edits to a draft DPIA. Nil fields are unchanged;
+// changing the scope refreshes the context.
+type UpdateDPIARequest struct {
+	Title              *string                      `json:"title"`
+	Description        *string                      `json:"description"`
+	PurposeIDs         *[]types.ID                  `json:"purpose_ids"`
+	DataSourceIDs      *[]types.ID                  `json:"data_source_ids"`
+	Narrative          *string                      `json:"narrative"`
+	Risks              *[]compliance.DPIARisk       `json:"risks"`
+	Mitigations        *[]compliance.DPIAMitigation `json:"mitigations"`
+	ReviewIntervalDays *int                         `json:"review_interval_days"`
+}
+
+// Create starts a DPIA in DRAFT, with its context pre-filled from the
+// inventory and the risks that context signals.
+func (s *DPIAService) Create(ctx context.Context, req CreateDPIARequest) (*compliance.DPIA, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	userID, _ := types.UserIDFromContext(ctx)
+
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, types.NewValidationError("title is required", nil)
+	}
+	if req.ReviewIntervalDays < 0 {
+		return nil, types.NewValidationError("review_interval_days must not be negative", nil)
+	}
+	if req.ReviewIntervalDays == 0 {
+		req.ReviewIntervalDays = compliance.DefaultDPIAReviewIntervalDays
+	}
+
+	d := &compliance.DPIA{
+		TenantEntity:       types.TenantEntity{TenantID: tenantID},
+		Title:              req.Title,
+		Description:        req.Description,
+		PurposeIDs:         req.PurposeIDs,
+		DataSourceIDs:      req.DataSourceIDs,
+		Status:             compliance.DPIAStatusDraft,
+		ReviewIntervalDays: req.ReviewIntervalDays,
+	}
+	if userID != (types.ID{}) {
+		d.CreatedBy = &userID
+	}
+	if err := s.prefill(ctx, d); err != nil {
+		return nil, err
+	}
+
+	if err := s.dpiaRepo.Create(ctx, d); err != nil {
+		return nil, fmt.Errorf("create dpia: %w", err)
+	}
+
+	s.auditSvc.Log(ctx, userID, "DPIA_CREATE", "DPIA", d.ID, nil, map[string]any{"title": d.Title, "risk_level": d.RiskLevel}, tenantID)
+	return d, nil
+}
+
+// Get retrieves a DPIA of the tenant.
+func (s *DPIAService) Get(ctx context.Context, id types.ID) (*compliance.DPIA, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	d, err := s.dpiaRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.TenantID != tenantID {
+		return nil, types.NewNotFoundError("DPIA", id)
+	}
+	return d, nil
+}
+
+// List retrieves the tenant's DPIAs, optionally of one status.
+func (s *DPIAService) List(ctx context.Context, status *compliance.DPIAStatus, pagination types.Pagination) (*types.PaginatedResult[compliance.DPIA], error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	return s.dpiaRepo.ListByTenant(ctx, tenantID, compliance.DPIAFilter{Status: status, Pagination: pagination})
+}
+
+// Update applies edits to a draft DPIA.
+func (s *DPIAService) Update(ctx context.Context, id types.ID, req UpdateDPIARequest) (*compliance.DPIA, error) {
+	d, err := s.getDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			return nil, types.NewValidationError("title is required", nil)
+		}
+		d.Title = *req.Title
+	}
+	if req.Description != nil {
+		d.Description = *req.Description
+	}
+	if req.Narrative != nil {
+		d.Narrative = *req.Narrative
+	}
+	if req.Risks != nil {
+		risks, err := rateDPIARisks(*req.Risks)
+		if err != nil {
+			return nil, err
+		}
+		d.Risks = risks
+		d.RiskLevel = compliance.HighestDPIARisk(risks)
+	}
+	if req.Mitigations != nil {
+		d.Mitigations = *req.Mitigations
+	}
+	if req.ReviewIntervalDays != nil {
+		if *req.ReviewIntervalDays <= 0 {
+			return nil, types.NewValidationError("review_interval_days must be positive", nil)
+		}
+		d.ReviewIntervalDays = *req.ReviewIntervalDays
+	}
+	if req.PurposeIDs != nil || req.DataSourceIDs != nil {
+		if req.PurposeIDs != nil {
+			d.PurposeIDs = *req.PurposeIDs
+		}
+		if req.DataSourceIDs != nil {
+			d.DataSourceIDs = *req.DataSourceIDs
+		}
+		if err := s.refreshContext(ctx, d); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.dpiaRepo.Update(ctx, d); err != nil {
+		return nil, fmt.Errorf("update dpia: %w", err)
+	}
+	userID, _ := types.UserIDFromContext(ctx)
+	s.auditSvc.Log(ctx, userID, "DPIA_EDIT", "DPIA", d.ID, nil, map[string]any{"risk_level": d.RiskLevel}, d.TenantID)
+	return d, nil
+}
+
+// Refresh re-takes a draft DPIA's context from the current inventory.
+func (s *DPIAService) Refresh(ctx context.Context, id types.ID) (*compliance.DPIA, error) {
+	d, err := s.getDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshContext(ctx, d); err != nil {
+		return nil, err
+	}
+	if err := s.dpiaRepo.Update(ctx, d); err != nil {
+		return nil, fmt.Errorf("update dpia: %w", err)
+	}
+	return d, nil
+}
+
+// Draft has the AI gateway write the risk narrative, risks and mitigations
+// of a draft DPIA from its context. The result replaces them and is left
+// for the assessor to edit.
+func (s *DPIAService) Draft(ctx context.Context, id types.ID) (*compliance.DPIA, error) {
+	if s.aiGateway == nil {
+		return nil, types.NewValidationError("AI drafting is not configured", nil)
+	}
+	d, err := s.getDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	industry := ""
+	if tenant, err := s.tenantRepo.GetByID(ctx, d.TenantID); err == nil {
+		industry = tenant.Industry
+	}
+	prompt, err := s.prompts.Render(ctx, ai.PromptDPIADraft, dpiaPromptData(d, industry))
+	if err != nil {
+		return nil, fmt.Errorf("render prompt: %w", err)
+	}
+
+	answer, result, err := ai.CompleteJSON(ctx, s.aiGateway, prompt.Text, ai.CompletionOptions{
+		UseCase:        ai.PromptDPIADraft,
+		SystemPrompt:   prompt.System,
+		Priority:       "accuracy",
+		Temperature:    0.2,
+		MaxTokens:      3000,
+		ResponseSchema: dpiaDraftSchema,
+	}, validateDPIADraft)
+	if err != nil {
+		return nil, fmt.Errorf("draft dpia: %w", err)
+	}
+
+	d.Narrative = answer.Narrative
+	d.Risks = answer.Risks
+	d.Mitigations = answer.Mitigations
+	d.RiskLevel = compliance.HighestDPIARisk(d.Risks)
+	d.AIDraft = &compliance.DPIADraftInfo{
+		Provider:      result.Provider,
+		Model:         result.Model,
+		PromptVersion: prompt.Version,
+		DraftedAt:     s.now().UTC(),
+	}
+	if err := s.dpiaRepo.Update(ctx, d); err != nil {
+		return nil, fmt.Errorf("update dpia: %w", err)
+	}
+
+	userID, _ := types.UserIDFromContext(ctx)
+	s.auditSvc.Log(ctx, userID, "DPIA_AI_DRAFT", "DPIA", d.ID, nil, map[string]any{
+		"provider": result.Provider, "model": result.Model, "prompt_version": prompt.Version,
+	}, d.TenantID)
+	return d, nil
+}
+
+// Submit sends a draft DPIA for review. It needs a narrative, and every
+// HIGH risk needs a mitigation.
+func (s *DPIAService) Submit(ctx context.Context, id types.ID) (*compliance.DPIA, error) {
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(d.Narrative) == "" {
+		return nil, types.NewValidationError("a risk narrative is required before review", nil)
+	}
+	if unmitigated := unmitigatedDPIARisks(d); len(unmitigated) > 0 {
+		return nil, types.NewValidationError("every HIGH risk needs a mitigation", map[string]any{"risks": unmitigated})
+	}
+
+	return s.transition(ctx, d, compliance.DPIAStatusInReview, "DPIA_SUBMIT", func(d *compliance.DPIA, userID *types.ID, _ time.Time) {
+		d.SubmittedBy = userID
+	})
+}
+
+// Approve approves a DPIA in review and schedules its first periodic review.
+func (s *DPIAService) Approve(ctx context.Context, id types.ID, notes string) (*compliance.DPIA, error) {
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != compliance.DPIAStatusInReview {
+		return nil, types.NewValidationError("invalid transition", map[string]any{"status": "only a DPIA in review can be approved"})
+	}
+	return s.transition(ctx, d, compliance.DPIAStatusApproved, "DPIA_APPROVE", func(d *compliance.DPIA, userID *types.ID, now time.Time) {
+		d.ReviewedBy = userID
+		d.ReviewNotes = notes
+		d.ApprovedAt = &now
+		d.LastReviewedAt = &now
+		next := now.AddDate(0, 0, d.ReviewIntervalDays)
+		d.NextReviewAt = &next
+	})
+}
+
+// RequestChanges returns a DPIA in review to its assessor.
+func (s *DPIAService) RequestChanges(ctx context.Context, id types.ID, notes string) (*compliance.DPIA, error) {
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != compliance.DPIAStatusInReview {
+		return nil, types.NewValidationError("invalid transition", map[string]any{"status": "only a DPIA in review can be returned"})
+	}
+	return s.transition(ctx, d, compliance.DPIAStatusDraft, "DPIA_REQUEST_CHANGES", func(d *compliance.DPIA, userID *types.ID, _ time.Time) {
+		d.ReviewedBy = userID
+		d.ReviewNotes = notes
+	})
+}
+
+// CompleteReview records a periodic review that found the approved DPIA
+// still accurate, and schedules the next one.
+func (s *DPIAService) CompleteReview(ctx context.Context, id types.ID, notes string) (*compliance.DPIA, error) {
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !d.IsApproved() {
+		return nil, types.NewValidationError("invalid transition", map[string]any{"status": "only an approved DPIA can be reviewed"})
+	}
+	return s.transition(ctx, d, compliance.DPIAStatusApproved, "DPIA_REVIEW", func(d *compliance.DPIA, userID *types.ID, now time.Time) {
+		d.ReviewedBy = userID
+		d.ReviewNotes = notes
+		d.LastReviewedAt = &now
+		next := now.AddDate(0, 0, d.ReviewIntervalDays)
+		d.NextReviewAt = &next
+	})
+}
+
+// Reopen returns an approved DPIA to DRAFT, with a refreshed context, when
+// the processing has changed. It leaves the RoPA until approved again.
+func (s *DPIAService) Reopen(ctx context.Context, id types.ID, notes string) (*compliance.DPIA, error) {
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !d.IsApproved() {
+		return nil, types.NewValidationError("invalid transition", map[string]any{"status": "only an approved DPIA can be reopened"})
+	}
+	if err := s.refreshContext(ctx, d); err != nil {
+		return nil, err
+	}
+	return s.transition(ctx, d, compliance.DPIAStatusDraft, "DPIA_REOPEN", func(d *compliance.DPIA, _ *types.ID, _ time.Time) {
+		d.ReviewNotes = notes
+		d.ApprovedAt = nil
+		d.NextReviewAt = nil
+	})
+}
+
+// Archive retires a DPIA whose processing has ended.
+func (s *DPIAService) Archive(ctx context.Context, id types.ID) (*compliance.DPIA, error) {
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.transition(ctx, d, compliance.DPIAStatusArchived, "DPIA_ARCHIVE", func(d *compliance.DPIA, _ *types.ID, _ time.Time) {
+		d.NextReviewAt = nil
+	})
+}
+
+// MarkReviewsDue moves approved DPIAs of every tenant whose periodic review
+// has come due to REVIEW_DUE. They stay in the RoPA meanwhile.
+func (s *DPIAService) MarkReviewsDue(ctx context.Context) (int, error) {
+	due, err := s.dpiaRepo.GetReviewsDue(ctx, s.now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("get dpia reviews due: %w", err)
+	}
+
+	marked := 0
+	for i := range due {
+		d := &due[i]
+		d.Status = compliance.DPIAStatusReviewDue
+		if err := s.dpiaRepo.Update(ctx, d); err != nil {
+			s.logger.ErrorContext(ctx, "failed to mark DPIA review due", "dpia_id", d.ID, "error", err)
+			continue
+		}
+		s.auditSvc.Log(ctx, types.ID{}, "DPIA_REVIEW_DUE", "DPIA", d.ID, nil, map[string]any{"next_review_at": d.NextReviewAt}, d.TenantID)
+		marked++
+	}
+	return marked, nil
+}
+
+// getDraft loads a DPIA that may still be edited.
+func (s *DPIAService) getDraft(ctx context.Context, id types.ID) (*compliance.DPIA, error) {
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status != compliance.DPIAStatusDraft {
+		return nil, types.NewValidationError("only a draft DPIA can be edited", map[string]any{"status": d.Status})
+	}
+	return d, nil
+}
+
+// transition validates and applies a status change, then stores and audits
+// it. apply sets the fields that change with it.
+func (s *DPIAService) transition(
+	ctx context.Context,
+	d *compliance.DPIA,
+	status compliance.DPIAStatus,
+	action string,
+	apply func(d *compliance.DPIA, userID *types.ID, now time.Time),
+) (*compliance.DPIA, error) {
+	if err := d.ValidateTransition(status); err != nil {
+		return nil, types.NewValidationError("invalid transition", map[string]any{"status": err.Error()})
+	}
+
+	var actor *types.ID
+	userID, ok := types.UserIDFromContext(ctx)
+	if ok {
+		actor = &userID
+	}
+	old := d.Status
+	d.Status = status
+	apply(d, actor, s.now().UTC())
+
+	if err := s.dpiaRepo.Update(ctx, d); err != nil {
+		return nil, fmt.Errorf("update dpia: %w", err)
+	}
+
+	s.auditSvc.Log(ctx, userID, action, "DPIA", d.ID, map[string]any{"status": old}, map[string]any{"status": status}, d.TenantID)
+	s.logger.InfoContext(ctx, "DPIA status changed",
+		slog.String("dpia_id", d.ID.String()),
+		slog.String("from", string(old)),
+		slog.String("to", string(status)),
+	)
+	return d, nil
+}
+
+// =============================================================================
+// Pre-fill — context and risk signals from the inventory
+// =============================================================================
+
+// prefill takes a new DPIA's context and seeds its risks with the signals
+// in it.
+func (s *DPIAService) prefill(ctx context.Context, d *compliance.DPIA) error {
+	c, err := s.buildContext(ctx, d)
+	if err != nil {
+		return err
+	}
+	d.Context = *c
+	d.Risks = dpiaRiskSignals(c)
+	d.RiskLevel = compliance.HighestDPIARisk(d.Risks)
+	return nil
+}
+
+// refreshContext re-takes a DPIA's context. Risks are re-seeded only while
+// nobody has assessed them yet.
+func (s *DPIAService) refreshContext(ctx context.Context, d *compliance.DPIA) error {
+	if d.Narrative == "" && d.AIDraft == nil {
+		return s.prefill(ctx, d)
+	}
+	c, err := s.buildContext(ctx, d)
+	if err != nil {
+		return err
+	}
+	d.Context = *c
+	return nil
+}
+
+// buildContext snapshots the inventory in the DPIA's scope: purposes, data
+// sources, discovered PII categories, third parties, cross-border transfers
+// and re-identification risk.
+func (s *DPIAService) buildContext(ctx context.Context, d *compliance.DPIA) (*compliance.DPIAContext, error) {
+	tenantID := d.TenantID
+	c := &compliance.DPIAContext{GeneratedAt: s.now().UTC()}
+	inPurposes := func(ids []types.ID) bool {
+		return len(d.PurposeIDs) == 0 || slices.ContainsFunc(ids, func(id types.ID) bool { return slices.Contains(d.PurposeIDs, id) })
+	}
+	inSources := func(id types.ID) bool {
+		return len(d.DataSourceIDs) == 0 || slices.Contains(d.DataSourceIDs, id)
+	}
+
+	purposes, err := s.purposeRepo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get purposes: %w", err)
+	}
+	for _, p := range purposes {
+		if !inPurposes([]types.ID{p.ID}) {
+			continue
+		}
+		c.Purposes = append(c.Purposes, compliance.DPIAPurpose{
+			ID: p.ID, Name: p.Name, LegalBasis: string(p.LegalBasis), RequiresConsent: p.RequiresConsent,
+		})
+	}
+
+	dataSources, err := s.dsRepo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get data sources: %w", err)
+	}
+	dsNames := make(map[types.ID]string, len(dataSources))
+	for _, ds := range dataSources {
+		dsNames[ds.ID] = ds.Name
+		if inSources(ds.ID) {
+			c.DataSources = append(c.DataSources, compliance.DPIADataSource{ID: ds.ID, Name: ds.Name, Type: string(ds.Type)})
+		}
+	}
+
+	categories, err := s.categoryCounts(ctx, tenantID, d.DataSourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	for category, fields := range categories {
+		c.DataCategories = append(c.DataCategories, compliance.DPIADataCategory{
+			Category:  category,
+			Fields:    fields,
+			Sensitive: slices.Contains(dpiaSensitiveCategories, types.PIICategory(category)),
+		})
+	}
+	sort.Slice(c.DataCategories, func(i, j int) bool { return c.DataCategories[i].Category < c.DataCategories[j].Category })
+
+	homeCountry := "India"
+	if tenant, err := s.tenantRepo.GetByID(ctx, tenantID); err == nil && tenant.Country != "" {
+		homeCountry = tenant.Country
+	}
+
+	thirdParties, err := s.thirdPartyRepo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get third parties: %w", err)
+	}
+	tpNames := make(map[types.ID]string, len(thirdParties))
+	seenTransfers := make(map[string]bool)
+	for _, tp := range thirdParties {
+		tpNames[tp.ID] = tp.Name
+		if !tp.IsActive || !inPurposes(tp.PurposeIDs) {
+			continue
+		}
+		c.ThirdParties = append(c.ThirdParties, compliance.DPIAThirdParty{
+			ID: tp.ID, Name: tp.Name, Type: string(tp.Type), Country: tp.Country, DPAStatus: tp.DPAStatus,
+		})
+		if tp.Country != "" && !sameCountry(tp.Country, homeCountry) {
+			seenTransfers[tp.Name+"|"+strings.ToLower(tp.Country)] = true
+			c.CrossBorderTransfers = append(c.CrossBorderTransfers, compliance.DPIATransfer{
+				Recipient:   tp.Name,
+				Country:     tp.Country,
+				Safeguarded: tp.DPAStatus == governance.DPAStatusSigned,
+			})
+		}
+	}
+
+	mappings, err := s.mappingRepo.GetCrossBorder(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get cross-border mappings: %w", err)
+	}
+	for _, m := range mappings {
+		if m.CrossBorder == nil || !inPurposes(m.PurposeIDs) {
+			continue
+		}
+		recipient := "Unnamed recipient"
+		for _, id := range m.ThirdPartyIDs {
+			if name, ok := tpNames[id]; ok {
+				recipient = name
+				break
+			}
+		}
+		key := recipient + "|" + strings.ToLower(m.CrossBorder.DestinationCountry)
+		if seenTransfers[key] {
+			continue
+		}
+		seenTransfers[key] = true
+		c.CrossBorderTransfers = append(c.CrossBorderTransfers, compliance.DPIATransfer{
+			Recipient:      recipient,
+			Country:        m.CrossBorder.DestinationCountry,
+			LegalMechanism: m.CrossBorder.LegalMechanism,
+			Safeguarded:    m.CrossBorder.LegalMechanism != "" && strings.EqualFold(m.CrossBorder.ApprovalStatus, "APPROVED"),
+		})
+	}
+
+	if s.reidRisks != nil {
+		risks, err := s.reidRisks.GetReidentificationRisks(ctx, tenantID, dpiaHighRiskReidScore, 0)
+		if err != nil {
+			return nil, fmt.Errorf("get re-identification risks: %w", err)
+		}
+		for _, r := range risks {
+			if !inSources(r.DataSourceID) {
+				continue
+			}
+			c.ReidentificationRisks = append(c.ReidentificationRisks, compliance.DPIAReidentificationRisk{
+				EntityID:       r.EntityID,
+				EntityName:     r.EntityName,
+				DataSourceName: r.DataSourceName,
+				Score:          r.Risk.Score,
+				Level:          string(r.Risk.Level),
+				K:              r.Risk.K,
+			})
+		}
+	}
+
+	return c, nil
+}
+
+// categoryCounts counts discovered PII fields by category, tenant-wide or
+// over the given data sources.
+func (s *DPIAService) categoryCounts(ctx context.Context, tenantID types.ID, dataSourceIDs []types.ID) (map[string]int, error) {
+	if len(dataSourceIDs) == 0 {
+		counts, err := s.piiRepo.GetCounts(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("count pii: %w", err)
+		}
+		return counts.ByCategory, nil
+	}
+
+	counts := make(map[string]int)
+	for _, dsID := range dataSourceIDs {
+		for page := 1; ; page++ {
+			result, err := s.piiRepo.GetClassifications(ctx, tenantID, discovery.ClassificationFilter{
+				DataSourceID: &dsID,
+				Pagination:   types.Pagination{Page: page, PageSize: 500},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("get classifications: %w", err)
+			}
+			for _, c := range result.Items {
+				if c.Status == types.VerificationRejected {
+					continue
+				}
+				counts[string(c.Category)]++
+			}
+			if page >= result.TotalPages {
+				break
+			}
+		}
+	}
+	return counts, nil
+}
+
+// countryAliases folds the spellings of a country onto one name.
+var countryAliases = map[string]string{"in": "india", "ind": "india", "bharat": "india"}
+
+func sameCountry(a, b string) bool {
+	norm := func(s string) string {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if alias, ok := countryAliases[s]; ok {
+			return alias
+		}
+		return s
+	}
+	return norm(a) == norm(b)
+}
+
+// dpiaRiskSignals derives the risks a DPIA context indicates on its own.
+// They seed a new DPIA and are given to the AI draft to assess.
+func dpiaRiskSignals(c *compliance.DPIAContext) []compliance.DPIARisk {
+	var risks []compliance.DPIARisk
+	add := func(title, description string, likelihood, impact compliance.DPIARiskLevel) {
+		risks = append(risks, compliance.DPIARisk{
+			Title:       title,
+			Description: description,
+			Likelihood:  likelihood,
+			Impact:      impact,
+			Level:       compliance.DPIARiskLevelFor(likelihood, impact),
+		})
+	}
+
+	var sensitive []string
+	for _, cat := range c.DataCategories {
+		if cat.Category == string(types.PIICategoryMinor) {
+			add("Processing of children's data",
+				fmt.Sprintf("%d fields hold data of children, which requires verifiable parental consent and bars tracking and targeted advertising (DPDPA s.9).", cat.Fields),
+				compliance.DPIARiskMedium, compliance.DPIARiskHigh)
+			continue
+		}
+		if cat.Sensitive {
+			sensitive = append(sensitive, fmt.Sprintf("%s (%d fields)", cat.Category, cat.Fields))
+		}
+	}
+	if len(sensitive) > 0 {
+		add("Processing of sensitive personal data",
+			"Sensitive categories are processed: "+strings.Join(sensitive, ", ")+". Their disclosure or misuse causes significant harm to data principals.",
+			compliance.DPIARiskMedium, compliance.DPIARiskHigh)
+	}
+
+	var unsafeguarded, safeguarded []string
+	for _, t := range c.CrossBorderTransfers {
+		flow := t.Recipient + " → " + t.Country
+		if t.Safeguarded {
+			safeguarded = append(safeguarded, flow)
+		} else {
+			unsafeguarded = append(unsafeguarded, flow)
+		}
+	}
+	if len(unsafeguarded) > 0 {
+		add("Cross-border transfer without documented safeguards",
+			"Personal data leaves the country without a signed DPA or approved transfer mechanism: "+strings.Join(unsafeguarded, ", ")+". Transfers may be restricted by notification under DPDPA s.16.",
+			compliance.DPIARiskHigh, compliance.DPIARiskMedium)
+	}
+	if len(safeguarded) > 0 {
+		add("Cross-border transfers",
+			"Personal data is transferred abroad under documented safeguards: "+strings.Join(safeguarded, ", ")+".",
+			compliance.DPIARiskLow, compliance.DPIARiskMedium)
+	}
+
+	var noDPA []string
+	for _, tp := range c.ThirdParties {
+		if tp.Type == string(governance.ThirdPartyProcessor) && tp.DPAStatus != governance.DPAStatusSigned {
+			noDPA = append(noDPA, tp.Name)
+		}
+	}
+	if len(noDPA) > 0 {
+		add("Processors without a signed data processing agreement",
+			"No signed DPA binds these processors to the purposes and security safeguards (DPDPA s.8(2)): "+strings.Join(noDPA, ", ")+".",
+			compliance.DPIARiskMedium, compliance.DPIARiskMedium)
+	}
+
+	var reidentifiable []string
+	for _, r := range c.ReidentificationRisks {
+		if r.Level == string(discovery.ReidentificationLevelHigh) {
+			reidentifiable = append(reidentifiable, fmt.Sprintf("%s.%s (k=%d)", r.DataSourceName, r.EntityName, r.K))
+		}
+	}
+	if len(reidentifiable) > 0 {
+		add("Re-identification of data principals",
+			"Combinations of quasi-identifiers single out most records of: "+strings.Join(reidentifiable, ", ")+".",
+			compliance.DPIARiskMedium, compliance.DPIARiskHigh)
+	}
+
+	return risks
+}
+
+// rateDPIARisks checks edited risks and rates their level.
+func rateDPIARisks(risks []compliance.DPIARisk) ([]compliance.DPIARisk, error) {
+	levels := compliance.DPIARiskLevels()
+	for i := range risks {
+		r := &risks[i]
+		if strings.TrimSpace(r.Title) == "" {
+			return nil, types.NewValidationError("every risk needs a title", map[string]any{"index": i})
+		}
+		if !slices.Contains(levels, r.Likelihood) || !slices.Contains(levels, r.Impact) {
+			return nil, types.NewValidationError("likelihood and impact must be LOW, MEDIUM or HIGH", map[string]any{"risk": r.Title})
+		}
+		r.Level = compliance.DPIARiskLevelFor(r.Likelihood, r.Impact)
+	}
+	return risks, nil
+}
+
+// unmitigatedDPIARisks lists the HIGH risks no mitigation addresses.
+func unmitigatedDPIARisks(d *compliance.DPIA) []string {
+	var out []string
+	for _, r := range d.Risks {
+		if r.Level != compliance.DPIARiskHigh {
+			continue
+		}
+		mitigated := slices.ContainsFunc(d.Mitigations, func(m compliance.DPIAMitigation) bool {
+			return strings.EqualFold(strings.TrimSpace(m.Risk), strings.TrimSpace(r.Title)) && strings.TrimSpace(m.Measure) != ""
+		})
+		if !mitigated {
+			out = append(out, r.Title)
+		}
+	}
+	return out
+}
+
+// =============================================================================
+// AI draft
+// =============================================================================
+
+// dpiaPromptData summarizes a DPIA's context for the draft prompt.
+func dpiaPromptData(d *compliance.DPIA, industry string) ai.DPIAPromptData {
+	c := d.Context
+	data := ai.DPIAPromptData{Title: d.Title, Description: d.Description, Industry: industry}
+	for _, p := range c.Purposes {
+		data.Purposes = append(data.Purposes, fmt.Sprintf("%s (%s)", p.Name, p.LegalBasis))
+	}
+	for _, ds := range c.DataSources {
+		data.DataSources = append(data.DataSources, fmt.Sprintf("%s (%s)", ds.Name, ds.Type))
+	}
+	for _, cat := range c.DataCategories {
+		line := fmt.Sprintf("%s: %d fields", cat.Category, cat.Fields)
+		if cat.Sensitive {
+			line += " (sensitive)"
+		}
+		data.DataCategories = append(data.DataCategories, line)
+	}
+	for _, tp := range c.ThirdParties {
+		data.ThirdParties = append(data.ThirdParties, fmt.Sprintf("%s (%s, %s, DPA %s)", tp.Name, tp.Type, tp.Country, tp.DPAStatus))
+	}
+	for _, t := range c.CrossBorderTransfers {
+		safeguard := t.LegalMechanism
+		if !t.Safeguarded {
+			safeguard = "no documented safeguard"
+		} else if safeguard == "" {
+			safeguard = "signed DPA"
+		}
+		data.CrossBorderTransfers = append(data.CrossBorderTransfers, fmt.Sprintf("%s → %s (%s)", t.Recipient, t.Country, safeguard))
+	}
+	for _, r := range c.ReidentificationRisks {
+		data.ReidentificationRisks = append(data.ReidentificationRisks,
+			fmt.Sprintf("%s.%s: %s (score %.0f, k=%d)", r.DataSourceName, r.EntityName, r.Level, r.Score, r.K))
+	}
+	for _, r := range dpiaRiskSignals(&c) {
+		data.RiskSignals = append(data.RiskSignals, fmt.Sprintf("%s [%s]: %s", r.Title, r.Level, r.Description))
+	}
+	return data
+}
+
+// dpiaDraftAnswer is the AI draft of a DPIA.
+type dpiaDraftAnswer struct {
+	Narrative   string                      `json:"narrative"`
+	Risks       []compliance.DPIARisk       `json:"risks"`
+	Mitigations []compliance.DPIAMitigation `json:"mitigations"`
+}
+
+// dpiaDraftSchema is the JSON Schema of a DPIA draft.
+var dpiaDraftSchema = func() *ai.ResponseSchema {
+	level := map[string]any{"type": "string", "enum": []string{"LOW", "MEDIUM", "HIGH"}}
+	return &ai.ResponseSchema{
+		Name: "dpia_draft",
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"narrative": map[string]any{"type": "string"},
+				"risks": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"title":       map[string]any{"type": "string"},
+							"description": map[string]any{"type": "string"},
+							"likelihood":  level,
+							"impact":      level,
+						},
+						"required": []string{"title", "description", "likelihood", "impact"},
+					},
+				},
+				"mitigations": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"risk":          map[string]any{"type": "string"},
+							"measure":       map[string]any{"type": "string"},
+							"residual_risk": level,
+						},
+						"required": []string{"risk", "measure"},
+					},
+				},
+			},
+			"required": []string{"narrative", "risks", "mitigations"},
+		},
+	}
+}()
+
+// validateDPIADraft requires a narrative and titled risks, coerces the risk
+// grades and rates each risk's level.
+func validateDPIADraft(a *dpiaDraftAnswer, v *ai.Validation) {
+	if strings.TrimSpace(a.Narrative) == "" {
+		v.Problem("narrative is empty")
+	}
+	levels := compliance.DPIARiskLevels()
+	for i := range a.Risks {
+		r := &a.Risks[i]
+		if strings.TrimSpace(r.Title) == "" {
+			v.Problem("risks[%d] has no title", i)
+		}
+		r.Likelihood = ai.CoerceEnum(fmt.Sprintf("risks[%d].likelihood", i), r.Likelihood, levels, v)
+		r.Impact = ai.CoerceEnum(fmt.Sprintf("risks[%d].impact", i), r.Impact, levels, v)
+		r.Level = compliance.DPIARiskLevelFor(r.Likelihood, r.Impact)
+	}
+	for i := range a.Mitigations {
+		m := &a.Mitigations[i]
+		if strings.TrimSpace(m.Measure) == "" {
+			v.Problem("mitigations[%d] has no measure", i)
+		}
+		if m.ResidualRisk != "" {
+			m.ResidualRisk = ai.CoerceEnum(fmt.Sprintf("mitigations[%d].residual_risk", i), m.ResidualRisk, levels, v)
+		}
+	}
+	if a.Risks == nil {
+		a.Risks = []compliance.DPIARisk{}
+	}
+	if a.Mitigations == nil {
+		a.Mitigations = []compliance.DPIAMitigation{}
+	}
+}
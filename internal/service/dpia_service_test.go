@@ -0,0 +1,453 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/domain/compliance"
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/domain/governance"
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/internal/service/ai"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// =============================================================================
+// Local mocks
+// =============================================================================
+
+type mockDPIARepo struct {
+	mu    sync.Mutex
+	dpias map[types.ID]*compliance.DPIA
+}
+
+func newMockDPIARepo() *mockDPIARepo {
+	return &mockDPIARepo{dpias: make(map[types.ID]*compliance.DPIA)}
+}
+
+func (r *mockDPIARepo) Create(_ context.Context, d *compliance.DPIA) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d.ID = types.NewID()
+	cp := *d
+	r.dpias[d.ID] = &cp
+	return nil
+}
+func (r *mockDPIARepo) GetByID(_ context.Context, id types.ID) (*compliance.DPIA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.dpias[id]
+	if !ok {
+		return nil, types.NewNotFoundError("DPIA", id)
+	}
+	cp := *d
+	return &cp, nil
+}
+func (r *mockDPIARepo) ListByTenant(_ context.Context, tenantID types.ID, filter compliance.DPIAFilter) (*types.PaginatedResult[compliance.DPIA], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var items []compliance.DPIA
+	for _, d := range r.dpias {
+		if d.TenantID == tenantID && (filter.Status == nil || d.Status == *filter.Status) {
+			items = append(items, *d)
+		}
+	}
+	return &types.PaginatedResult[compliance.DPIA]{Items: items, Total: len(items)}, nil
+}
+func (r *mockDPIARepo) GetApproved(_ context.Context, tenantID types.ID) ([]compliance.DPIA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var items []compliance.DPIA
+	for _, d := range r.dpias {
+		if d.TenantID == tenantID && d.IsApproved() {
+			items = append(items, *d)
+		}
+	}
+	return items, nil
+}
+func (r *mockDPIARepo) GetReviewsDue(_ context.Context, before time.Time) ([]compliance.DPIA, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var items []compliance.DPIA
+	for _, d := range r.dpias {
+		if d.Status == compliance.DPIAStatusApproved && d.NextReviewAt != nil && d.NextReviewAt.Before(before) {
+			items = append(items, *d)
+		}
+	}
+	return items, nil
+}
+func (r *mockDPIARepo) Update(_ context.Context, d *compliance.DPIA) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *d
+	r.dpias[d.ID] = &cp
+	return nil
+}
+
+type mockThirdPartyRepo struct {
+	parties []governance.ThirdParty
+}
+
+func (r *mockThirdPartyRepo) Create(_ context.Context, tp *governance.ThirdParty) error {
+	tp.ID = types.NewID()
+	r.parties = append(r.parties, *tp)
+	return nil
+}
+func (r *mockThirdPartyRepo) GetByID(_ context.Context, id types.ID) (*governance.ThirdParty, error) {
+	for i := range r.parties {
+		if r.parties[i].ID == id {
+			return &r.parties[i], nil
+		}
+	}
+	return nil, types.NewNotFoundError("ThirdParty", id)
+}
+func (r *mockThirdPartyRepo) GetByTenant(_ context.Context, tenantID types.ID) ([]governance.ThirdParty, error) {
+	var out []governance.ThirdParty
+	for _, tp := range r.parties {
+		if tp.TenantID == tenantID {
+			out = append(out, tp)
+		}
+	}
+	return out, nil
+}
+func (r *mockThirdPartyRepo) Update(_ context.Context, _ *governance.ThirdParty) error { return nil }
+func (r *mockThirdPartyRepo) Delete(_ context.Context, _ types.ID) error               { return nil }
+
+type mockDataMappingRepo struct {
+	mappings []governance.DataMapping
+}
+
+func (r *mockDataMappingRepo) Create(_ context.Context, dm *governance.DataMapping) error {
+	r.mappings = append(r.mappings, *dm)
+	return nil
+}
+func (r *mockDataMappingRepo) GetByID(_ context.Context, id types.ID) (*governance.DataMapping, error) {
+	return nil, types.NewNotFoundError("DataMapping", id)
+}
+func (r *mockDataMappingRepo) GetByClassification(_ context.Context, id types.ID) (*governance.DataMapping, error) {
+	return nil, types.NewNotFoundError("DataMapping", id)
+}
+func (r *mockDataMappingRepo) GetUnmapped(_ context.Context, _ types.ID) ([]types.ID, error) {
+	return nil, nil
+}
+func (r *mockDataMappingRepo) GetCrossBorder(_ context.Context, tenantID types.ID) ([]governance.DataMapping, error) {
+	var out []governance.DataMapping
+	for _, m := range r.mappings {
+		if m.TenantID == tenantID && m.CrossBorder != nil {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+func (r *mockDataMappingRepo) Update(_ context.Context, _ *governance.DataMapping) error { return nil }
+
+type mockDPIAGateway struct {
+	mock.Mock
+}
+
+func (m *mockDPIAGateway) DetectPII(_ context.Context, _ ai.PIIDetectionInput) (*ai.PIIDetectionResult, error) {
+	return nil, nil
+}
+func (m *mockDPIAGateway) SuggestPurposes(_ context.Context, _ ai.PurposeSuggestionInput) ([]ai.PurposeSuggestion, error) {
+	return nil, nil
+}
+func (m *mockDPIAGateway) Complete(ctx context.Context, prompt string, opts ai.CompletionOptions) (*ai.CompletionResult, error) {
+	args := m.Called(ctx, prompt, opts)
+	if r := args.Get(0); r != nil {
+		return r.(*ai.CompletionResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// =============================================================================
+// Fixture
+// =============================================================================
+
+type dpiaFixture struct {
+	svc      *DPIAService
+	repo     *mockDPIARepo
+	tenantID types.ID
+	ctx      context.Context
+	dsID     types.ID
+	purpose  types.ID
+	now      time.Time
+}
+
+// newDPIAFixture seeds an Indian tenant with a marketing purpose, a CRM
+// holding health and children's data, an unsigned US processor and a
+// documented transfer to Singapore.
+func newDPIAFixture(t *testing.T) *dpiaFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	tenantRepo := newMockTenantRepo()
+	tenant := &identity.Tenant{Name: "Acme Health", Industry: "healthcare", Country: "IN"}
+	require.NoError(t, tenantRepo.Create(ctx, tenant))
+
+	purposeRepo := newMockPurposeRepo()
+	marketing := &governance.Purpose{TenantEntity: types.TenantEntity{TenantID: tenant.ID}, Name: "Marketing", LegalBasis: types.LegalBasisConsent, RequiresConsent: true}
+	require.NoError(t, purposeRepo.Create(ctx, marketing))
+
+	dsRepo := newMockDataSourceRepo()
+	crm := &discovery.DataSource{TenantEntity: types.TenantEntity{TenantID: tenant.ID}, Name: "CRM", Type: types.DataSourcePostgreSQL}
+	require.NoError(t, dsRepo.Create(ctx, crm))
+
+	piiRepo := newMockPIIClassificationRepo()
+	for _, c := range []discovery.PIIClassification{
+		{DataSourceID: crm.ID, Category: types.PIICategoryHealth, Status: types.VerificationVerified},
+		{DataSourceID: crm.ID, Category: types.PIICategoryHealth, Status: types.VerificationVerified},
+		{DataSourceID: crm.ID, Category: types.PIICategoryMinor, Status: types.VerificationVerified},
+		{DataSourceID: crm.ID, Category: types.PIICategoryContact, Status: types.VerificationVerified},
+		{DataSourceID: crm.ID, Category: types.PIICategoryFinancial, Status: types.VerificationRejected},
+	} {
+		c := c
+		require.NoError(t, piiRepo.Create(ctx, &c))
+	}
+
+	tpRepo := &mockThirdPartyRepo{}
+	mailer := &governance.ThirdParty{TenantEntity: types.TenantEntity{TenantID: tenant.ID}, Name: "MailCo", Type: governance.ThirdPartyProcessor, Country: "US", IsActive: true, DPAStatus: "PENDING", PurposeIDs: []types.ID{marketing.ID}}
+	require.NoError(t, tpRepo.Create(ctx, mailer))
+	local := &governance.ThirdParty{TenantEntity: types.TenantEntity{TenantID: tenant.ID}, Name: "LocalPay", Type: governance.ThirdPartyProcessor, Country: "India", IsActive: true, DPAStatus: governance.DPAStatusSigned, PurposeIDs: []types.ID{marketing.ID}}
+	require.NoError(t, tpRepo.Create(ctx, local))
+
+	mappingRepo := &mockDataMappingRepo{}
+	require.NoError(t, mappingRepo.Create(ctx, &governance.DataMapping{
+		TenantEntity: types.TenantEntity{TenantID: tenant.ID},
+		PurposeIDs:   []types.ID{marketing.ID},
+		CrossBorder:  &governance.CrossBorderTransfer{DestinationCountry: "Singapore", LegalMechanism: "SCC", ApprovalStatus: "APPROVED"},
+	}))
+
+	repo := newMockDPIARepo()
+	svc := NewDPIAService(repo, purposeRepo, dsRepo, piiRepo, tpRepo, mappingRepo, tenantRepo,
+		NewAuditService(newMockAuditRepo(), newTestLogger()), newTestLogger())
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	ctx = context.WithValue(ctx, types.ContextKeyTenantID, tenant.ID)
+	ctx = context.WithValue(ctx, types.ContextKeyUserID, types.NewID())
+	return &dpiaFixture{svc: svc, repo: repo, tenantID: tenant.ID, ctx: ctx, dsID: crm.ID, purpose: marketing.ID, now: now}
+}
+
+func riskTitles(risks []compliance.DPIARisk) []string {
+	var out []string
+	for _, r := range risks {
+		out = append(out, r.Title)
+	}
+	return out
+}
+
+// =============================================================================
+// Tests
+// =============================================================================
+
+func TestDPIAService_Create_PrefillsFromInventory(t *testing.T) {
+	f := newDPIAFixture(t)
+
+	d, err := f.svc.Create(f.ctx, CreateDPIARequest{
+		Title:         "Patient marketing",
+		PurposeIDs:    []types.ID{f.purpose},
+		DataSourceIDs: []types.ID{f.dsID},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, compliance.DPIAStatusDraft, d.Status)
+	assert.Equal(t, compliance.DefaultDPIAReviewIntervalDays, d.ReviewIntervalDays)
+	assert.NotNil(t, d.CreatedBy)
+
+	c := d.Context
+	require.Len(t, c.Purposes, 1)
+	assert.Equal(t, "Marketing", c.Purposes[0].Name)
+	require.Len(t, c.DataSources, 1)
+
+	// Rejected classifications are not counted.
+	assert.Equal(t, []compliance.DPIADataCategory{
+		{Category: "CONTACT", Fields: 1},
+		{Category: "HEALTH", Fields: 2, Sensitive: true},
+		{Category: "MINOR", Fields: 1, Sensitive: true},
+	}, c.DataCategories)
+
+	// "IN" and "India" are the same country: only MailCo and the mapping are transfers.
+	require.Len(t, c.CrossBorderTransfers, 2)
+	assert.Equal(t, compliance.DPIATransfer{Recipient: "MailCo", Country: "US"}, c.CrossBorderTransfers[0])
+	assert.Equal(t, "Singapore", c.CrossBorderTransfers[1].Country)
+	assert.True(t, c.CrossBorderTransfers[1].Safeguarded)
+
+	assert.ElementsMatch(t, []string{
+		"Processing of children's data",
+		"Processing of sensitive personal data",
+		"Cross-border transfer without documented safeguards",
+		"Cross-border transfers",
+		"Processors without a signed data processing agreement",
+	}, riskTitles(d.Risks))
+	assert.Equal(t, compliance.DPIARiskHigh, d.RiskLevel)
+}
+
+func TestDPIAService_Create_RequiresTitle(t *testing.T) {
+	f := newDPIAFixture(t)
+
+	_, err := f.svc.Create(f.ctx, CreateDPIARequest{Title: "  "})
+	assert.ErrorIs(t, err, types.ErrValidation)
+}
+
+func TestDPIAService_Lifecycle(t *testing.T) {
+	f := newDPIAFixture(t)
+	d, err := f.svc.Create(f.ctx, CreateDPIARequest{Title: "Patient marketing", DataSourceIDs: []types.ID{f.dsID}, ReviewIntervalDays: 180})
+	require.NoError(t, err)
+
+	// No narrative yet.
+	_, err = f.svc.Submit(f.ctx, d.ID)
+	assert.ErrorIs(t, err, types.ErrValidation)
+
+	// HIGH risks still unmitigated.
+	narrative := "Health and children's data are processed for marketing."
+	_, err = f.svc.Update(f.ctx, d.ID, UpdateDPIARequest{Narrative: &narrative})
+	require.NoError(t, err)
+	_, err = f.svc.Submit(f.ctx, d.ID)
+	require.ErrorIs(t, err, types.ErrValidation)
+
+	var mitigations []compliance.DPIAMitigation
+	for _, title := range unmitigatedDPIARisks(f.mustGet(t, d.ID)) {
+		mitigations = append(mitigations, compliance.DPIAMitigation{Risk: strings.ToUpper(title), Measure: "Addressed", ResidualRisk: compliance.DPIARiskLow})
+	}
+	_, err = f.svc.Update(f.ctx, d.ID, UpdateDPIARequest{Mitigations: &mitigations})
+	require.NoError(t, err)
+
+	d, err = f.svc.Submit(f.ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, compliance.DPIAStatusInReview, d.Status)
+	assert.NotNil(t, d.SubmittedBy)
+
+	// Editing is closed while in review.
+	_, err = f.svc.Update(f.ctx, d.ID, UpdateDPIARequest{Narrative: &narrative})
+	assert.ErrorIs(t, err, types.ErrValidation)
+
+	d, err = f.svc.Approve(f.ctx, d.ID, "Looks good")
+	require.NoError(t, err)
+	assert.Equal(t, compliance.DPIAStatusApproved, d.Status)
+	require.NotNil(t, d.NextReviewAt)
+	assert.Equal(t, f.now.AddDate(0, 0, 180), *d.NextReviewAt)
+
+	// Approving twice is rejected.
+	_, err = f.svc.Approve(f.ctx, d.ID, "")
+	assert.ErrorIs(t, err, types.ErrValidation)
+
+	d, err = f.svc.Reopen(f.ctx, d.ID, "New vendor")
+	require.NoError(t, err)
+	assert.Equal(t, compliance.DPIAStatusDraft, d.Status)
+	assert.Nil(t, d.ApprovedAt)
+	assert.Nil(t, d.NextReviewAt)
+	assert.Equal(t, narrative, d.Narrative, "reopening keeps the assessment")
+
+	d, err = f.svc.Archive(f.ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, compliance.DPIAStatusArchived, d.Status)
+}
+
+func TestDPIAService_Get_OtherTenant(t *testing.T) {
+	f := newDPIAFixture(t)
+	d, err := f.svc.Create(f.ctx, CreateDPIARequest{Title: "Patient marketing"})
+	require.NoError(t, err)
+
+	other := context.WithValue(context.Background(), types.ContextKeyTenantID, types.NewID())
+	_, err = f.svc.Get(other, d.ID)
+	assert.True(t, types.IsNotFoundError(err))
+}
+
+func TestDPIAService_Draft(t *testing.T) {
+	f := newDPIAFixture(t)
+	d, err := f.svc.Create(f.ctx, CreateDPIARequest{Title: "Patient marketing", DataSourceIDs: []types.ID{f.dsID}})
+	require.NoError(t, err)
+
+	_, err = f.svc.Draft(f.ctx, d.ID)
+	require.ErrorIs(t, err, types.ErrValidation, "drafting needs a gateway")
+
+	gw := new(mockDPIAGateway)
+	gw.On("Complete", mock.Anything, mock.MatchedBy(func(prompt string) bool {
+		return strings.Contains(prompt, "MailCo → US (no documented safeguard)") && strings.Contains(prompt, "HEALTH: 2 fields (sensitive)")
+	}), mock.MatchedBy(func(opts ai.CompletionOptions) bool {
+		return opts.UseCase == ai.PromptDPIADraft && opts.ResponseSchema != nil
+	})).Return(&ai.CompletionResult{
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Response: `{"narrative": "The processing is high risk.",
+			"risks": [{"title": "Profiling of patients", "description": "Health data drives targeting.", "likelihood": "medium", "impact": "HIGH"}],
+			"mitigations": [{"risk": "Profiling of patients", "measure": "Exclude health data from segments", "residual_risk": "LOW"}]}`,
+	}, nil).Once()
+	f.svc.SetAIGateway(gw, nil)
+
+	d, err = f.svc.Draft(f.ctx, d.ID)
+	require.NoError(t, err)
+	gw.AssertExpectations(t)
+
+	assert.Equal(t, "The processing is high risk.", d.Narrative)
+	require.Len(t, d.Risks, 1)
+	assert.Equal(t, compliance.DPIARiskMedium, d.Risks[0].Likelihood, "grades are coerced")
+	assert.Equal(t, compliance.DPIARiskHigh, d.Risks[0].Level)
+	assert.Equal(t, compliance.DPIARiskHigh, d.RiskLevel)
+	require.NotNil(t, d.AIDraft)
+	assert.Equal(t, "gpt-4o", d.AIDraft.Model)
+	assert.Equal(t, "dpia_draft@builtin", d.AIDraft.PromptVersion)
+
+	// The drafted mitigation covers the drafted HIGH risk.
+	d, err = f.svc.Submit(f.ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, compliance.DPIAStatusInReview, d.Status)
+}
+
+func TestDPIAService_MarkReviewsDue(t *testing.T) {
+	f := newDPIAFixture(t)
+	due, err := f.svc.Create(f.ctx, CreateDPIARequest{Title: "Due"})
+	require.NoError(t, err)
+	notDue, err := f.svc.Create(f.ctx, CreateDPIARequest{Title: "Not due"})
+	require.NoError(t, err)
+
+	past, future := f.now.AddDate(0, 0, -1), f.now.AddDate(0, 1, 0)
+	for id, next := range map[types.ID]time.Time{due.ID: past, notDue.ID: future} {
+		d := f.mustGet(t, id)
+		d.Status = compliance.DPIAStatusApproved
+		d.NextReviewAt = &next
+		require.NoError(t, f.repo.Update(f.ctx, d))
+	}
+
+	marked, err := f.svc.MarkReviewsDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, marked)
+	assert.Equal(t, compliance.DPIAStatusReviewDue, f.mustGet(t, due.ID).Status)
+	assert.Equal(t, compliance.DPIAStatusApproved, f.mustGet(t, notDue.ID).Status)
+
+	// Completing the review schedules the next one.
+	d, err := f.svc.CompleteReview(f.ctx, due.ID, "Still accurate")
+	require.NoError(t, err)
+	assert.Equal(t, compliance.DPIAStatusApproved, d.Status)
+	assert.Equal(t, f.now.AddDate(0, 0, compliance.DefaultDPIAReviewIntervalDays), *d.NextReviewAt)
+}
+
+func TestLinkDPIAs(t *testing.T) {
+	marketing, billing := types.NewID(), types.NewID()
+	content := compliance.RoPAContent{Purposes: []compliance.RoPAPurpose{{ID: marketing}, {ID: billing}}}
+
+	scoped := compliance.DPIA{Title: "Marketing", PurposeIDs: []types.ID{marketing}, Status: compliance.DPIAStatusApproved}
+	scoped.ID = types.NewID()
+	wide := compliance.DPIA{Title: "Everything", Status: compliance.DPIAStatusReviewDue}
+	wide.ID = types.NewID()
+
+	linkDPIAs(&content, []compliance.DPIA{scoped, wide})
+
+	require.Len(t, content.DPIAs, 2)
+	assert.Equal(t, []types.ID{scoped.ID, wide.ID}, content.Purposes[0].DPIAIDs)
+	assert.Equal(t, []types.ID{wide.ID}, content.Purposes[1].DPIAIDs)
+}
+
+func (f *dpiaFixture) mustGet(t *testing.T, id types.ID) *compliance.DPIA {
+	t.Helper()
+	d, err := f.repo.GetByID(f.ctx, id)
+	require.NoError(t, err)
+	return d
+}
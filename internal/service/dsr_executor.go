@@ -21,10 +21,15 @@ type DSRExecutor struct {
 	dsrRepo        compliance.DSRRepository
 	dsRepo         discovery.DataSourceRepository
 	piiRepo        discovery.PIIClassificationRepository
+	inventoryRepo  discovery.DataInventoryRepository
 	connRegistry   *connector.ConnectorRegistry
 	eventBus       eventbus.EventBus
 	logger         *slog.Logger
 	maxConcurrency int
+
+	// startVerification runs AutoVerify once an execution completes. It
+	// verifies in the background; tests replace it to verify in line.
+	startVerification func(dsrID types.ID)
 }
 
 // NewDSRExecutor creates a new DSRExecutor.
@@ -36,7 +41,7 @@ func NewDSRExecutor(
 	eventBus eventbus.EventBus,
 	logger *slog.Logger,
 ) *DSRExecutor {
-	return &DSRExecutor{
+	e := &DSRExecutor{
 		dsrRepo:        dsrRepo,
 		dsRepo:         dsRepo,
 		piiRepo:        piiRepo,
@@ -45,6 +50,31 @@ func NewDSRExecutor(
 		logger:         logger.With("service", "dsr_executor"),
 		maxConcurrency: 5, // Default concurrency limit
 	}
+	e.startVerification = e.verifyInBackground
+	return e
+}
+
+// SetInventoryRepo enables subject-graph traversal: with it, DSRs also
+// reach records in entities that hold no identifier of their own but join
+// to a matched entity by key.
+func (e *DSRExecutor) SetInventoryRepo(repo discovery.DataInventoryRepository) {
+	e.inventoryRepo = repo
+}
+
+// subjectGraph returns the data source's subject graph, or nil when none
+// has been built or traversal is not enabled.
+func (e *DSRExecutor) subjectGraph(ctx context.Context, dataSourceID types.ID) *discovery.SubjectGraph {
+	if e.inventoryRepo == nil {
+		return nil
+	}
+	inv, err := e.inventoryRepo.GetByDataSource(ctx, dataSourceID)
+	if err != nil {
+		if !types.IsNotFoundError(err) {
+			e.logger.WarnContext(ctx, "failed to load subject graph", "data_source_id", dataSourceID, "error", err)
+		}
+		return nil
+	}
+	return inv.SubjectGraph
 }
 
 // ExecuteDSR executes all tasks for a DSR request.
@@ -128,22 +158,23 @@ func (e *DSRExecutor) ExecuteDSR(ctx context.Context, dsrID types.ID) error {
 
 	// 7. Auto Verify Result if Completed
 	if dsr.Status == compliance.DSRStatusCompleted {
-		go func() {
-			// Run Verification in background
-			verifyCtx := context.Background() // Isolate context for background task
-			// Usually we want to pass tenant context if needed, but context.Background is safer for fire-and-forget
-			// However, AutoVerify needs repo access which might rely on context (though repos here take ctx param).
-			// Let's use a new context derived from Background but maybe copy values?
-			// For now, simple Background. Logger might lose trace ID.
-			if err := e.AutoVerify(verifyCtx, dsrID); err != nil {
-				e.logger.ErrorContext(verifyCtx, "dsr auto-verification failed", "dsr_id", dsrID, "error", err)
-			}
-		}()
+		e.startVerification(dsrID)
 	}
 
 	return nil
 }
 
+// verifyInBackground runs AutoVerify on its own goroutine, detached from
+// the execution's context so verification outlives the job.
+func (e *DSRExecutor) verifyInBackground(dsrID types.ID) {
+	go func() {
+		verifyCtx := context.Background()
+		if err := e.AutoVerify(verifyCtx, dsrID); err != nil {
+			e.logger.ErrorContext(verifyCtx, "dsr auto-verification failed", "dsr_id", dsrID, "error", err)
+		}
+	}()
+}
+
 // executeTask executes a single DSR task against a data source.
 func (e *DSRExecutor) executeTask(ctx context.Context, dsr *compliance.DSR, task *compliance.DSRTask) error {
 	task.Status = compliance.TaskStatusRunning
@@ -210,52 +241,54 @@ func (e *DSRExecutor) executeAccessRequest(ctx context.Context, dsr *compliance.
 	defer conn.Close()
 
 	// 4. Find PII fields for retrieval
-	pagination := types.Pagination{Page: 1, PageSize: 1000}
-	piiResult, err := e.piiRepo.GetByDataSource(ctx, ds.ID, pagination)
+	classifications, err := loadClassifications(ctx, e.piiRepo, ds.ID)
 	if err != nil {
 		return nil, fmt.Errorf("fetch pii classifications: %w", err)
 	}
 
-	// 5. Group by entity
-	entityFields := make(map[string][]string)
-	for _, pii := range piiResult.Items {
-		entityFields[pii.EntityName] = append(entityFields[pii.EntityName], pii.FieldName)
-	}
+	// 5. Plan per-entity targets
+	targets := planDSRTargets(classifications, dsr.SubjectIdentifiers)
+	pathConn, canPaths := asPathConnector(conn)
 
 	// 6. Execute Export
 	accessResults := make([]map[string]interface{}, 0)
 	var totalRecords int64
 
-	for entityName, fields := range entityFields {
-		filter := make(map[string]string)
-		for _, field := range fields {
-			for idKey, idVal := range dsr.SubjectIdentifiers {
-				if strings.EqualFold(idKey, field) {
-					filter[field] = idVal
-				}
-			}
-		}
-
-		if len(filter) == 0 {
-			// e.logger.WarnContext(ctx, "no matching identifiers", "entity", entityName)
-			continue
+	for _, target := range targets {
+		var records []map[string]interface{}
+		if len(target.Paths) > 0 && canPaths {
+			records, err = pathConn.ExportPaths(ctx, target.Entity, target.Paths, target.Filter)
+		} else {
+			records, err = conn.Export(ctx, target.Entity, target.Filter)
 		}
-
-		records, err := conn.Export(ctx, entityName, filter)
 		if err != nil {
-			e.logger.ErrorContext(ctx, "export failed", "entity", entityName, "error", err)
+			e.logger.ErrorContext(ctx, "export failed", "entity", target.Entity, "error", err)
 			continue
 		}
 
 		if len(records) > 0 {
 			totalRecords += int64(len(records))
-			accessResults = append(accessResults, map[string]interface{}{
-				"entity":  entityName,
+			entry := map[string]interface{}{
+				"entity":  target.Entity,
 				"records": records,
-			})
+			}
+			if len(target.Paths) > 0 && canPaths {
+				entry["paths"] = target.Paths
+			}
+			accessResults = append(accessResults, entry)
 		}
 	}
 
+	// 7. Follow the subject graph to dependent entities
+	for _, target := range followSubjectGraph(ctx, conn, e.subjectGraph(ctx, ds.ID), targets, e.logger) {
+		totalRecords += int64(len(target.Records))
+		accessResults = append(accessResults, map[string]interface{}{
+			"entity":  target.Entity,
+			"records": target.Records,
+			"via":     target.Via.String(),
+		})
+	}
+
 	// Emit event
 	e.eventBus.Publish(ctx, eventbus.NewEvent(eventbus.EventDSRDataAccessed, "dsr_executor", dsr.TenantID, map[string]any{
 		"dsr_id":         dsr.ID,
@@ -314,42 +347,56 @@ func (e *DSRExecutor) executeErasureRequest(ctx context.Context, dsr *compliance
 	defer conn.Close()
 
 	// 4. Find PII fields for deletion
-	pagination := types.Pagination{Page: 1, PageSize: 1000}
-	piiResult, err := e.piiRepo.GetByDataSource(ctx, ds.ID, pagination)
+	classifications, err := loadClassifications(ctx, e.piiRepo, ds.ID)
 	if err != nil {
 		return nil, fmt.Errorf("fetch pii classifications: %w", err)
 	}
 
-	// 5. Group by entity for deletion
-	entityFields := make(map[string][]string)
-	for _, pii := range piiResult.Items {
-		entityFields[pii.EntityName] = append(entityFields[pii.EntityName], pii.FieldName)
-	}
+	// 5. Plan per-entity targets. Entities whose PII sits only inside
+	// semi-structured columns lose those paths, not the whole record.
+	targets := planDSRTargets(classifications, dsr.SubjectIdentifiers)
+	pathConn, canPaths := asPathConnector(conn)
 
-	// 6. Execute deletion
+	// 6. Resolve dependent entities through the subject graph while the
+	// matched records still exist, then erase the deepest first so child
+	// rows go before the rows they reference. Only declared foreign keys
+	// are erased through; records reached over an inferred link may belong
+	// to someone else and are left for review.
+	linked := followSubjectGraph(ctx, conn, e.subjectGraph(ctx, ds.ID), targets, e.logger)
 	deletionLog := make([]map[string]interface{}, 0)
+	plan := make([]dsrEntityTarget, 0, len(linked)+len(targets))
+	for i := len(linked) - 1; i >= 0; i-- {
+		target := linked[i]
+		if target.Inferred {
+			deletionLog = append(deletionLog, map[string]interface{}{
+				"entity":  target.Entity,
+				"status":  "NEEDS_REVIEW",
+				"count":   len(target.Records),
+				"filters": target.Filter,
+				"via":     target.Via.String(),
+			})
+			continue
+		}
+		plan = append(plan, target)
+	}
+	plan = append(plan, targets...)
+
+	// 7. Execute deletion
 	var totalDeleted int64
 
-	for entityName, fields := range entityFields {
-		filter := make(map[string]string)
-		for _, field := range fields {
-			for idKey, idVal := range dsr.SubjectIdentifiers {
-				if strings.EqualFold(idKey, field) {
-					filter[field] = idVal
-				}
-			}
-		}
+	for _, target := range plan {
+		byPath := len(target.Paths) > 0 && canPaths
 
-		if len(filter) == 0 {
-			e.logger.WarnContext(ctx, "no matching identifiers for entity deletion", "entity", entityName)
-			continue
+		var count int64
+		if byPath {
+			count, err = pathConn.DeletePaths(ctx, target.Entity, target.Paths, target.Filter)
+		} else {
+			count, err = conn.Delete(ctx, target.Entity, target.Filter)
 		}
-
-		count, err := conn.Delete(ctx, entityName, filter)
 		if err != nil {
-			e.logger.ErrorContext(ctx, "failed to delete entity", "entity", entityName, "error", err)
+			e.logger.ErrorContext(ctx, "failed to delete entity", "entity", target.Entity, "error", err)
 			deletionLog = append(deletionLog, map[string]interface{}{
-				"entity": entityName,
+				"entity": target.Entity,
 				"status": "FAILED",
 				"error":  err.Error(),
 			})
@@ -357,19 +404,27 @@ func (e *DSRExecutor) executeErasureRequest(ctx context.Context, dsr *compliance
 		}
 
 		totalDeleted += count
-		deletionLog = append(deletionLog, map[string]interface{}{
-			"entity":  entityName,
+		entry := map[string]interface{}{
+			"entity":  target.Entity,
 			"status":  "DELETED",
 			"count":   count,
-			"filters": filter,
-		})
+			"filters": target.Filter,
+		}
+		if byPath {
+			entry["status"] = "PATHS_REMOVED"
+			entry["paths"] = target.Paths
+		}
+		if target.Via != nil {
+			entry["via"] = target.Via.String()
+		}
+		deletionLog = append(deletionLog, entry)
 	}
 
 	// Emit deletion event
 	e.eventBus.Publish(ctx, eventbus.NewEvent(eventbus.EventDSRDataDeleted, "dsr_executor", dsr.TenantID, map[string]any{
 		"dsr_id":         dsr.ID,
 		"data_source_id": ds.ID,
-		"entities_count": len(entityFields),
+		"entities_count": len(plan),
 		"total_deleted":  totalDeleted,
 	}))
 
@@ -559,43 +614,28 @@ func (e *DSRExecutor) verifyErasure(ctx context.Context, dsr *compliance.DSR, ta
 	defer conn.Close()
 
 	// Re-scan finding PII fields
-	pagination := types.Pagination{Page: 1, PageSize: 1000}
-	piiResult, err := e.piiRepo.GetByDataSource(ctx, ds.ID, pagination)
+	classifications, err := loadClassifications(ctx, e.piiRepo, ds.ID)
 	if err != nil {
 		return false, nil, err
 	}
 
-	entityFields := make(map[string][]string)
-	for _, pii := range piiResult.Items {
-		entityFields[pii.EntityName] = append(entityFields[pii.EntityName], pii.FieldName)
-	}
-
 	foundRecords := make(map[string]int)
 	totalFound := 0
 
-	for entityName, fields := range entityFields {
-		filter := make(map[string]string)
-		for _, field := range fields {
-			for idKey, idVal := range dsr.SubjectIdentifiers {
-				if strings.EqualFold(idKey, field) {
-					filter[field] = idVal
-				}
-			}
-		}
-
-		if len(filter) == 0 {
-			continue
-		}
-
+	// Path-erased records no longer match a filter on the removed paths, so
+	// a plain Export covers both erasure styles. Targets reached through the
+	// subject graph are found via the erased records and cannot be resolved
+	// again, so only directly matched targets are re-checked.
+	for _, target := range planDSRTargets(classifications, dsr.SubjectIdentifiers) {
 		// Use Export to check if records still exist
-		records, err := conn.Export(ctx, entityName, filter)
+		records, err := conn.Export(ctx, target.Entity, target.Filter)
 		if err != nil {
 			// If error is "not found" or similar, it might be good, but generally Export shouldn't fail if empty
 			return false, nil, err
 		}
 
 		if len(records) > 0 {
-			foundRecords[entityName] = len(records)
+			foundRecords[target.Entity] = len(records)
 			totalFound += len(records)
 		}
 	}
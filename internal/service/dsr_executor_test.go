@@ -48,6 +48,8 @@ func setupExecutorTest(t *testing.T) (*DSRExecutor, *mockDSRRepository, *mockDat
 	})
 
 	executor := NewDSRExecutor(dsrRepo, dsRepo, piiRepo, registry, eb, logger)
+	// Execution tests do not verify; verification tests opt in below.
+	executor.startVerification = func(types.ID) {}
 	return executor, dsrRepo, dsRepo, piiRepo, mockConn, eb
 }
 
@@ -507,6 +509,9 @@ func TestExecuteDSR_AutoVerify_Erasure_Success(t *testing.T) {
 	// Setup
 	executor, dsrRepo, dsRepo, piiRepo, mockConn, eb := setupExecutorTest(t)
 	ctx := context.Background()
+	executor.startVerification = func(id types.ID) {
+		assert.NoError(t, executor.AutoVerify(ctx, id))
+	}
 
 	tenantID := types.NewID()
 	dsID := types.NewID() // Use same ID for both
@@ -562,7 +567,7 @@ func TestExecuteDSR_AutoVerify_Erasure_Success(t *testing.T) {
 	mockConn.On("Connect", mock.Anything, mock.MatchedBy(func(d *discovery.DataSource) bool { return d.ID == dsID })).Return(nil).Times(2)
 
 	// Execution: Delete -> 1
-	mockConn.On("Delete", mock.Anything, "users", map[string]string{"email": "john@example.com"}).Return(int64(1), nil).Once()
+	mockConn.On("Delete", mock.Anything, "users", map[string]string{"email": "john@example.com"}).Return(1, nil).Once()
 
 	// Verification: Export -> [] (Empty)
 	mockConn.On("Export", mock.Anything, "users", map[string]string{"email": "john@example.com"}).
@@ -616,3 +621,80 @@ func TestExecuteDSR_AutoVerify_Erasure_Success(t *testing.T) {
 	}
 	assert.True(t, foundVerified, "Expected DSRVerified event")
 }
+
+func TestExecuteDSR_Erasure_NestedPaths(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	dsrRepo := newMockDSRRepository()
+	dsRepo := newMockDataSourceRepo()
+	piiRepo := newMockPIIClassificationRepo()
+	eb := newMockEventBus()
+
+	mockConn := new(MockPathConnector)
+	registry := connector.NewConnectorRegistry(&config.Config{}, detection.NewDefaultDetector(nil), nil)
+	registry.Register(types.DataSourceMongoDB, func() discovery.Connector {
+		return mockConn
+	})
+	executor := NewDSRExecutor(dsrRepo, dsRepo, piiRepo, registry, eb, logger)
+	executor.startVerification = func(types.ID) {}
+	ctx := context.Background()
+
+	tenantID := types.NewID()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{BaseEntity: types.BaseEntity{ID: types.NewID()}, TenantID: tenantID},
+		Name:         "Mongo",
+		Type:         types.DataSourceMongoDB,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+
+	dsr := &compliance.DSR{
+		ID:                 types.NewID(),
+		TenantID:           tenantID,
+		RequestType:        compliance.RequestTypeErasure,
+		Status:             compliance.DSRStatusApproved,
+		SubjectIdentifiers: map[string]string{"email": "john@example.com"},
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+	require.NoError(t, dsrRepo.Create(ctx, dsr))
+	require.NoError(t, dsrRepo.CreateTask(ctx, &compliance.DSRTask{
+		ID:           types.NewID(),
+		DSRID:        dsr.ID,
+		DataSourceID: ds.ID,
+		TenantID:     tenantID,
+		TaskType:     compliance.RequestTypeErasure,
+		Status:       compliance.TaskStatusPending,
+		CreatedAt:    time.Now(),
+	}))
+
+	// All PII on "orders" lives inside the customer subdocument
+	for _, field := range []string{"customer.email", "customer.phone"} {
+		require.NoError(t, piiRepo.Create(ctx, &discovery.PIIClassification{
+			BaseEntity:   types.BaseEntity{ID: types.NewID()},
+			DataSourceID: ds.ID,
+			EntityName:   "orders",
+			FieldName:    field,
+		}))
+	}
+
+	filter := map[string]string{"customer.email": "john@example.com"}
+	mockConn.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	mockConn.On("Close").Return(nil)
+	mockConn.On("DeletePaths", mock.Anything, "orders", []string{"customer.email", "customer.phone"}, filter).Return(3, nil).Once()
+
+	require.NoError(t, executor.ExecuteDSR(ctx, dsr.ID))
+
+	// Paths were removed; the orders themselves were not deleted
+	mockConn.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+
+	tasks, err := dsrRepo.GetTasksByDSR(ctx, dsr.ID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	result, ok := tasks[0].Result.(map[string]interface{})
+	require.True(t, ok)
+	deletions, ok := result["deletions"].([]map[string]interface{})
+	require.True(t, ok)
+	require.Len(t, deletions, 1)
+	assert.Equal(t, "PATHS_REMOVED", deletions[0]["status"])
+	assert.Equal(t, []string{"customer.email", "customer.phone"}, deletions[0]["paths"])
+	assert.Equal(t, int64(3), result["total_deleted"])
+}
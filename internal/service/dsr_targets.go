@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/infrastructure/connector"
+)
+
+// dsrEntityTarget describes how a DSR reaches the subject's data in one
+// entity of a data source.
+type dsrEntityTarget struct {
+	Entity string
+	// Filter selects the subject's records, keyed by field name. Keys may be
+	// nested paths such as "profile.email".
+	Filter map[string]string
+	// Paths lists the PII fields to act on when every PII field in the
+	// entity lives inside a semi-structured column. Such entities are
+	// exported and erased path by path instead of record by record.
+	Paths []string
+
+	// Via is the subject-graph link this target was reached through, nil
+	// for targets matched directly on the subject's identifiers.
+	Via *discovery.SubjectLink
+	// Depth counts the links between the target and a directly matched
+	// one; erasure removes deeper targets first.
+	Depth int
+	// Inferred is set when the path to the target crosses a link that was
+	// inferred rather than declared. Erasure does not delete through such
+	// links; it reports them for review.
+	Inferred bool
+	// Records are the rows read while following the graph, reused by
+	// access requests instead of exporting the entity again.
+	Records []map[string]interface{}
+}
+
+// planDSRTargets groups PII classifications by entity and matches the
+// subject's identifiers against their fields. An identifier matches a field
+// by name or, for nested fields, by the last key of the path, so "email"
+// matches "profile.contact.email". Entities without a matching identifier
+// are left out.
+func planDSRTargets(items []discovery.PIIClassification, identifiers map[string]string) []dsrEntityTarget {
+	entityFields := make(map[string][]string)
+	for _, pii := range items {
+		entityFields[pii.EntityName] = append(entityFields[pii.EntityName], pii.FieldName)
+	}
+
+	entities := make([]string, 0, len(entityFields))
+	for name := range entityFields {
+		entities = append(entities, name)
+	}
+	sort.Strings(entities)
+
+	var targets []dsrEntityTarget
+	for _, entityName := range entities {
+		fields := entityFields[entityName]
+		sort.Strings(fields)
+		filter := make(map[string]string)
+		nestedOnly := true
+		for _, field := range fields {
+			if !discovery.IsNestedField(field) {
+				nestedOnly = false
+			}
+			for idKey, idVal := range identifiers {
+				if identifierMatchesField(idKey, field) {
+					filter[field] = idVal
+				}
+			}
+		}
+
+		if len(filter) == 0 {
+			continue
+		}
+
+		target := dsrEntityTarget{Entity: entityName, Filter: filter}
+		if nestedOnly {
+			target.Paths = fields
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+func identifierMatchesField(idKey, field string) bool {
+	if strings.EqualFold(idKey, field) {
+		return true
+	}
+	if !discovery.IsNestedField(field) {
+		return false
+	}
+	_, keys := discovery.SplitFieldPath(field)
+	return strings.EqualFold(idKey, keys[len(keys)-1])
+}
+
+// asPathConnector returns the connector's path operations when it supports
+// them, looking beneath registry wrappers.
+func asPathConnector(conn discovery.Connector) (discovery.PathConnector, bool) {
+	pc, ok := connector.Unwrap(conn).(discovery.PathConnector)
+	return pc, ok
+}
+
+const (
+	// subjectGraphMaxDepth bounds how many links a DSR follows from a
+	// directly matched entity.
+	subjectGraphMaxDepth = 5
+	// subjectGraphMaxValues caps the key values followed along one link
+	// per record set, so a wrongly inferred link cannot fan a DSR out over
+	// a whole table.
+	subjectGraphMaxValues = 100
+)
+
+// followSubjectGraph reaches the subject's records in dependent entities.
+// Starting from the directly matched targets, it reads each reached
+// entity's records, takes the values of every outgoing link's parent field
+// and targets the child entity's records holding them. Targets come back
+// in breadth-first order with their Records filled in; each link value
+// gets its own target since filters only express equality. Targets below
+// an inferred link are marked Inferred. Export failures are logged and end
+// that branch.
+func followSubjectGraph(
+	ctx context.Context,
+	conn discovery.Connector,
+	graph *discovery.SubjectGraph,
+	direct []dsrEntityTarget,
+	logger *slog.Logger,
+) []dsrEntityTarget {
+	if graph == nil || len(graph.Links) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	frontier := make([]dsrEntityTarget, 0, len(direct))
+	for _, t := range direct {
+		seen[targetKey(t.Entity, t.Filter)] = true
+		frontier = append(frontier, t)
+	}
+
+	var linked []dsrEntityTarget
+	for depth := 1; depth <= subjectGraphMaxDepth && len(frontier) > 0; depth++ {
+		var next []dsrEntityTarget
+		for _, parent := range frontier {
+			links := graph.Children(parent.Entity)
+			if len(links) == 0 {
+				continue
+			}
+			records := parent.Records
+			if records == nil {
+				var err error
+				records, err = conn.Export(ctx, parent.Entity, parent.Filter)
+				if err != nil {
+					logger.ErrorContext(ctx, "export for subject graph failed", "entity", parent.Entity, "error", err)
+					continue
+				}
+			}
+
+			for i := range links {
+				link := links[i]
+				for _, value := range linkValues(records, link.ParentField) {
+					filter := map[string]string{link.ChildField: value}
+					key := targetKey(link.ChildEntity, filter)
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+
+					child := dsrEntityTarget{
+						Entity:   link.ChildEntity,
+						Filter:   filter,
+						Via:      &link,
+						Depth:    depth,
+						Inferred: parent.Inferred || link.Source != discovery.SubjectLinkForeignKey,
+					}
+					childRecords, err := conn.Export(ctx, child.Entity, child.Filter)
+					if err != nil {
+						logger.ErrorContext(ctx, "export for subject graph failed", "entity", child.Entity, "via", link.String(), "error", err)
+						continue
+					}
+					if len(childRecords) == 0 {
+						continue
+					}
+					child.Records = childRecords
+					linked = append(linked, child)
+					next = append(next, child)
+				}
+			}
+		}
+		frontier = next
+	}
+	return linked
+}
+
+// linkValues collects the distinct non-empty values of field across the
+// records, in first-seen order and capped at subjectGraphMaxValues.
+func linkValues(records []map[string]interface{}, field string) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, record := range records {
+		v, ok := recordValue(record, field)
+		if !ok || v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		values = append(values, v)
+		if len(values) == subjectGraphMaxValues {
+			break
+		}
+	}
+	return values
+}
+
+// recordValue reads a field, or a dotted path into nested documents, from
+// an exported record as text.
+func recordValue(record map[string]interface{}, field string) (string, bool) {
+	v, ok := record[field]
+	if !ok && discovery.IsNestedField(field) {
+		column, keys := discovery.SplitFieldPath(field)
+		v, ok = record[column]
+		for _, k := range keys {
+			doc, isDoc := v.(map[string]interface{})
+			if !ok || !isDoc {
+				return "", false
+			}
+			v, ok = doc[k]
+		}
+	}
+	if !ok || v == nil {
+		return "", false
+	}
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case []byte:
+		return string(val), true
+	case interface{ Hex() string }:
+		return val.Hex(), true // document IDs such as MongoDB ObjectIDs
+	default:
+		return fmt.Sprint(val), true
+	}
+}
+
+// targetKey identifies an entity and filter for de-duplication.
+func targetKey(entity string, filter map[string]string) string {
+	keys := make([]string, 0, len(filter))
+	for k := range filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(entity)
+	for _, k := range keys {
+		b.WriteString("\x00")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(filter[k])
+	}
+	return b.String()
+}
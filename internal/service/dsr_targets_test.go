@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+)
+
+func TestPlanDSRTargets(t *testing.T) {
+	items := []discovery.PIIClassification{
+		{EntityName: "users", FieldName: "email"},
+		{EntityName: "users", FieldName: "phone"},
+		{EntityName: "orders", FieldName: "customer.contact.email"},
+		{EntityName: "orders", FieldName: "customer.name"},
+		{EntityName: "events", FieldName: "payload.ip"},
+	}
+	identifiers := map[string]string{"Email": "john@example.com"}
+
+	targets := planDSRTargets(items, identifiers)
+	require.Len(t, targets, 2, "entities without a matching identifier are skipped")
+
+	// Sorted by entity name
+	assert.Equal(t, "orders", targets[0].Entity)
+	assert.Equal(t, map[string]string{"customer.contact.email": "john@example.com"}, targets[0].Filter)
+	assert.Equal(t, []string{"customer.contact.email", "customer.name"}, targets[0].Paths)
+
+	assert.Equal(t, "users", targets[1].Entity)
+	assert.Equal(t, map[string]string{"email": "john@example.com"}, targets[1].Filter)
+	assert.Empty(t, targets[1].Paths, "entities with top-level PII are handled per record")
+}
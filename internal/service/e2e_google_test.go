@@ -162,7 +162,7 @@ func TestGoogle_E2E_Mock(t *testing.T) {
 	// Discovery Service
 	discoverySvc := NewDiscoveryService(
 		dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, scanRepo,
-		registry, detector, eventBus, logger,
+		registry, detector, nil, eventBus, logger,
 	)
 
 	// Scan Service
@@ -167,7 +167,7 @@ func TestM365_E2E_Mock(t *testing.T) {
 	// Signature: (dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, scanRunRepo, registry, detector, eb, logger)
 	discoverySvc := NewDiscoveryService(
 		dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, scanRepo,
-		registry, detector, eventBus, logger,
+		registry, detector, nil, eventBus, logger,
 	)
 
 	// Setup Scan Service
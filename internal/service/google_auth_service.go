@@ -26,11 +26,12 @@ type GoogleAuthService struct {
 	dsRepo      discovery.DataSourceRepository
 	eventBus    eventbus.EventBus
 	cfg         *config.Config
+	cipher      crypto.CredentialCipher
 	logger      *slog.Logger
 }
 
 // NewGoogleAuthService creates a new GoogleAuthService.
-func NewGoogleAuthService(cfg *config.Config, dsRepo discovery.DataSourceRepository, eb eventbus.EventBus, logger *slog.Logger) *GoogleAuthService {
+func NewGoogleAuthService(cfg *config.Config, dsRepo discovery.DataSourceRepository, eb eventbus.EventBus, cipher crypto.CredentialCipher, logger *slog.Logger) *GoogleAuthService {
 	oauthConfig := &oauth2.Config{
 		ClientID:     cfg.Google.ClientID,
 		ClientSecret: cfg.Google.ClientSecret,
@@ -49,6 +50,7 @@ func NewGoogleAuthService(cfg *config.Config, dsRepo discovery.DataSourceReposit
 		dsRepo:      dsRepo,
 		eventBus:    eb,
 		cfg:         cfg,
+		cipher:      cipher,
 		logger:      logger.With("service", "google_auth"),
 	}
 }
@@ -84,13 +86,7 @@ func (s *GoogleAuthService) ExchangeAndConnect(ctx context.Context, code string,
 	}
 	credsJSON, _ := json.Marshal(creds)
 
-	key := s.cfg.App.SecretKey
-	if len(key) < 32 {
-		key = fmt.Sprintf("%-32s", key)
-	}
-	key = key[:32]
-
-	encryptedCreds, err := crypto.Encrypt(string(credsJSON), key)
+	encryptedCreds, err := s.cipher.Encrypt(ctx, tenantID, string(credsJSON))
 	if err != nil {
 		return nil, fmt.Errorf("encrypt credentials: %w", err)
 	}
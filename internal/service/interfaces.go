@@ -10,8 +10,10 @@ import (
 // DiscoveryOrchestrator defines the interface for discovery operations.
 type DiscoveryOrchestrator interface {
 	ScanDataSource(ctx context.Context, dataSourceID types.ID) (*discovery.ScanStats, error)
+	ScanDataSourceWithOptions(ctx context.Context, dataSourceID types.ID, opts ScanOptions) (*discovery.ScanStats, error)
 	TestConnection(ctx context.Context, dataSourceID types.ID) error
 	GetClassifications(ctx context.Context, tenantID types.ID, filter discovery.ClassificationFilter) (*types.PaginatedResult[discovery.PIIClassification], error)
+	MarkFieldReviewed(ctx context.Context, tenantID, fieldID types.ID) (*discovery.DataField, error)
 }
 
 // ScanOrchestrator defines the interface for scan job management.
@@ -19,4 +21,5 @@ type ScanOrchestrator interface {
 	EnqueueScan(ctx context.Context, dataSourceID types.ID, tenantID types.ID, scanType discovery.ScanType) (*discovery.ScanRun, error)
 	GetScan(ctx context.Context, id types.ID) (*discovery.ScanRun, error)
 	GetHistory(ctx context.Context, dataSourceID types.ID) ([]discovery.ScanRun, error)
+	CancelScan(ctx context.Context, dataSourceID types.ID, tenantID types.ID) (*discovery.ScanRun, error)
 }
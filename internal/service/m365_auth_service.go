@@ -21,12 +21,13 @@ type M365AuthService struct {
 	oauthConfig *oauth2.Config
 	dsRepo      discovery.DataSourceRepository
 	eventBus    eventbus.EventBus
-	cfg         *config.Config // Need full config for secret key
+	cfg         *config.Config
+	cipher      crypto.CredentialCipher
 	logger      *slog.Logger
 }
 
 // NewM365AuthService creates a new M365AuthService.
-func NewM365AuthService(cfg *config.Config, dsRepo discovery.DataSourceRepository, eb eventbus.EventBus, logger *slog.Logger) *M365AuthService {
+func NewM365AuthService(cfg *config.Config, dsRepo discovery.DataSourceRepository, eb eventbus.EventBus, cipher crypto.CredentialCipher, logger *slog.Logger) *M365AuthService {
 	oauthConfig := &oauth2.Config{
 		ClientID:     cfg.Microsoft.ClientID,
 		ClientSecret: cfg.Microsoft.ClientSecret,
@@ -40,6 +41,7 @@ func NewM365AuthService(cfg *config.Config, dsRepo discovery.DataSourceRepositor
 		dsRepo:      dsRepo,
 		eventBus:    eb,
 		cfg:         cfg,
+		cipher:      cipher,
 		logger:      logger.With("service", "m365_auth"),
 	}
 }
@@ -94,19 +96,7 @@ func (s *M365AuthService) ExchangeAndConnect(ctx context.Context, code string, t
 	}
 	credsJSON, _ := json.Marshal(creds)
 
-	// Encrypt using App Secret Key (must be 32 bytes, ensure padding or hashing in real app if not)
-	// For this task, assuming APP_SECRET_KEY is valid 32 chars or we need to handle it.
-	// We'll use a derived key or just slice if it's long enough.
-	// The config loaded "change-me-in-prod" which is < 32 bytes...
-	// Wait, AES-256 needs 32 bytes.
-	// If APP_SECRET_KEY is short, we should pad it.
-	key := s.cfg.App.SecretKey
-	if len(key) < 32 {
-		key = fmt.Sprintf("%-32s", key) // Pad with spaces
-	}
-	key = key[:32] // Truncate to 32
-
-	encryptedCreds, err := crypto.Encrypt(string(credsJSON), key)
+	encryptedCreds, err := s.cipher.Encrypt(ctx, tenantID, string(credsJSON))
 	if err != nil {
 		return nil, fmt.Errorf("encrypt credentials: %w", err)
 	}
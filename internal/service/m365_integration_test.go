@@ -70,7 +70,7 @@ func TestM365AuthService_Integration(t *testing.T) {
 	// We need to inject the HTTP client into the context to intercept the request.
 
 	dsRepo := repository.NewDataSourceRepo(pool)
-	authService := NewM365AuthService(cfg, dsRepo, eventBus, logger)
+	authService := NewM365AuthService(cfg, dsRepo, eventBus, crypto.NewStaticCipher(cfg.App.SecretKey), logger)
 
 	// Context with Mock HTTP Client
 	ctx := context.Background()
@@ -6,6 +6,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -484,7 +485,13 @@ func (r *mockPIIClassificationRepo) GetByDataSource(_ context.Context, dsID type
 			items = append(items, *c)
 		}
 	}
-	return &types.PaginatedResult[discovery.PIIClassification]{Items: items, Total: len(items)}, nil
+	total := len(items)
+	if p.PageSize > 0 {
+		sort.Slice(items, func(i, j int) bool { return items[i].ID.String() < items[j].ID.String() })
+		start := min(max(p.Page-1, 0)*p.PageSize, total)
+		items = items[start:min(start+p.PageSize, total)]
+	}
+	return &types.PaginatedResult[discovery.PIIClassification]{Items: items, Total: total}, nil
 }
 func (r *mockPIIClassificationRepo) Update(_ context.Context, c *discovery.PIIClassification) error {
 	r.mu.Lock()
@@ -582,6 +589,108 @@ func (r *mockDetectionFeedbackRepo) GetAccuracyStats(_ context.Context, tID type
 	return &discovery.AccuracyStats{}, nil
 }
 
+// =============================================================================
+// Mock Custom Detection Rule Repository
+// =============================================================================
+
+type mockCustomDetectionRuleRepo struct {
+	mu    sync.Mutex
+	rules map[types.ID]*discovery.CustomDetectionRule
+}
+
+func newMockCustomDetectionRuleRepo() *mockCustomDetectionRuleRepo {
+	return &mockCustomDetectionRuleRepo{
+		rules: make(map[types.ID]*discovery.CustomDetectionRule),
+	}
+}
+
+func (r *mockCustomDetectionRuleRepo) Create(_ context.Context, rule *discovery.CustomDetectionRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rule.ID == (types.ID{}) {
+		rule.ID = types.NewID()
+	}
+	r.rules[rule.ID] = rule
+	return nil
+}
+func (r *mockCustomDetectionRuleRepo) GetByID(_ context.Context, tenantID, id types.ID) (*discovery.CustomDetectionRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rule, ok := r.rules[id]
+	if !ok || rule.TenantID != tenantID {
+		return nil, types.NewNotFoundError("CustomDetectionRule", id)
+	}
+	return rule, nil
+}
+func (r *mockCustomDetectionRuleRepo) GetByTenant(_ context.Context, tenantID types.ID) ([]discovery.CustomDetectionRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []discovery.CustomDetectionRule
+	for _, rule := range r.rules {
+		if rule.TenantID == tenantID {
+			result = append(result, *rule)
+		}
+	}
+	return result, nil
+}
+func (r *mockCustomDetectionRuleRepo) GetEnabledByTenant(_ context.Context, tenantID types.ID) ([]discovery.CustomDetectionRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []discovery.CustomDetectionRule
+	for _, rule := range r.rules {
+		if rule.TenantID == tenantID && rule.Enabled {
+			result = append(result, *rule)
+		}
+	}
+	return result, nil
+}
+func (r *mockCustomDetectionRuleRepo) Update(_ context.Context, rule *discovery.CustomDetectionRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules[rule.ID] = rule
+	return nil
+}
+func (r *mockCustomDetectionRuleRepo) Delete(_ context.Context, tenantID, id types.ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rule, ok := r.rules[id]; !ok || rule.TenantID != tenantID {
+		return types.NewNotFoundError("CustomDetectionRule", id)
+	}
+	delete(r.rules, id)
+	return nil
+}
+
+// =============================================================================
+// Mock Detection Calibration Repository
+// =============================================================================
+
+type mockDetectionCalibrationRepo struct {
+	mu           sync.Mutex
+	calibrations map[types.ID]*discovery.DetectionCalibration
+}
+
+func newMockDetectionCalibrationRepo() *mockDetectionCalibrationRepo {
+	return &mockDetectionCalibrationRepo{
+		calibrations: make(map[types.ID]*discovery.DetectionCalibration),
+	}
+}
+
+func (r *mockDetectionCalibrationRepo) GetByTenant(_ context.Context, tenantID types.ID) (*discovery.DetectionCalibration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.calibrations[tenantID]
+	if !ok {
+		return nil, types.NewNotFoundError("detection calibration", tenantID)
+	}
+	return c, nil
+}
+func (r *mockDetectionCalibrationRepo) Upsert(_ context.Context, c *discovery.DetectionCalibration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calibrations[c.TenantID] = c
+	return nil
+}
+
 // =============================================================================
 // Mock Data Inventory Repository
 // =============================================================================
@@ -835,6 +944,42 @@ func (r *mockScanRunRepo) Update(_ context.Context, run *discovery.ScanRun) erro
 	return nil
 }
 
+func (r *mockScanRunRepo) UpdateCheckpoint(_ context.Context, run *discovery.ScanRun) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.runs[run.ID]
+	if !ok || stored.Status != discovery.ScanStatusRunning {
+		return false, nil
+	}
+	r.runs[run.ID] = run
+	return true, nil
+}
+
+func (r *mockScanRunRepo) Finish(_ context.Context, run *discovery.ScanRun) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.runs[run.ID]
+	if !ok || stored.Status != discovery.ScanStatusRunning {
+		return false, nil
+	}
+	r.runs[run.ID] = run
+	return true, nil
+}
+
+func (r *mockScanRunRepo) Cancel(_ context.Context, run *discovery.ScanRun) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.runs[run.ID]
+	if !ok || stored.Status.IsTerminal() {
+		return false, nil
+	}
+	cancelled := *stored
+	cancelled.Status = discovery.ScanStatusCancelled
+	cancelled.CompletedAt = run.CompletedAt
+	r.runs[run.ID] = &cancelled
+	return true, nil
+}
+
 // =============================================================================
 // Mock Audit Repository
 // =============================================================================
@@ -947,6 +1092,49 @@ func (m *MockConnector) Export(ctx context.Context, entity string, filter map[st
 	return args.Get(0).([]map[string]interface{}), args.Error(1)
 }
 
+// MockPathConnector is a MockConnector that also supports nested paths.
+type MockPathConnector struct {
+	MockConnector
+}
+
+func (m *MockPathConnector) DeletePaths(ctx context.Context, entity string, paths []string, filter map[string]string) (int64, error) {
+	args := m.Called(ctx, entity, paths, filter)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockPathConnector) ExportPaths(ctx context.Context, entity string, paths []string, filter map[string]string) ([]map[string]interface{}, error) {
+	args := m.Called(ctx, entity, paths, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
+// MockKeyDiscoverer is a MockConnector that also reports declared keys.
+type MockKeyDiscoverer struct {
+	MockConnector
+}
+
+func (m *MockKeyDiscoverer) DiscoverKeys(ctx context.Context) (*discovery.SchemaKeys, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.SchemaKeys), args.Error(1)
+}
+
+type MockRowSampler struct {
+	MockConnector
+}
+
+func (m *MockRowSampler) SampleRows(ctx context.Context, entity string, fields []string, limit int) ([]map[string]string, error) {
+	args := m.Called(ctx, entity, fields, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]string), args.Error(1)
+}
+
 // =============================================================================
 // Mock Notice Repository
 // =============================================================================
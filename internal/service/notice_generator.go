@@ -0,0 +1,619 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/complyark/datalens/internal/domain/compliance"
+	"github.com/complyark/datalens/internal/domain/consent"
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/pkg/eventbus"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// Notice generator events.
+const (
+	EventNoticeGenerated = "consent.notice_generated"
+	EventNoticeOutOfSync = "consent.notice_out_of_sync"
+)
+
+// NoticeGeneratorService drafts DPDPA privacy notices from the published
+// RoPA, flags generated notices the RoPA has moved away from, and has
+// published generated notices translated into the Indic languages.
+type NoticeGeneratorService struct {
+	noticeRepo     consent.ConsentNoticeRepository
+	ropaRepo       compliance.RoPARepository
+	dpoRepo        compliance.DPOContactRepository
+	tenantRepo     identity.TenantRepository
+	translationSvc *TranslationService
+	eventBus       eventbus.EventBus
+	logger         *slog.Logger
+	now            func() time.Time
+}
+
+// NewNoticeGeneratorService creates a new NoticeGeneratorService.
+func NewNoticeGeneratorService(
+	noticeRepo consent.ConsentNoticeRepository,
+	ropaRepo compliance.RoPARepository,
+	dpoRepo compliance.DPOContactRepository,
+	tenantRepo identity.TenantRepository,
+	translationSvc *TranslationService,
+	eventBus eventbus.EventBus,
+	logger *slog.Logger,
+) *NoticeGeneratorService {
+	return &NoticeGeneratorService{
+		noticeRepo:     noticeRepo,
+		ropaRepo:       ropaRepo,
+		dpoRepo:        dpoRepo,
+		tenantRepo:     tenantRepo,
+		translationSvc: translationSvc,
+		eventBus:       eventBus,
+		logger:         logger.With("service", "notice_generator"),
+		now:            time.Now,
+	}
+}
+
+// GenerateNoticeRequest holds input for a generated notice.
+type GenerateNoticeRequest struct {
+	Title      string     `json:"title"`       // Default: "Privacy Notice"
+	PurposeIDs []types.ID `json:"purpose_ids"` // Empty: every active purpose
+}
+
+// NoticeSyncStatus reports whether a generated notice matches the RoPA.
+type NoticeSyncStatus struct {
+	NoticeID    types.ID             `json:"notice_id"`
+	SeriesID    types.ID             `json:"series_id"`
+	Title       string               `json:"title"`
+	Version     int                  `json:"version"`
+	Status      consent.NoticeStatus `json:"status"`
+	RoPAVersion string               `json:"ropa_version"` // Generated from
+	OutOfSync   bool                 `json:"out_of_sync"`
+	StaleFields []string             `json:"stale_fields,omitempty"`
+}
+
+// Generate drafts a notice from the latest published RoPA. The draft carries
+// the Schedule I schema fields and is published through NoticeService.
+func (s *NoticeGeneratorService) Generate(ctx context.Context, req GenerateNoticeRequest) (*consent.ConsentNotice, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = "Privacy Notice"
+	}
+
+	n := &consent.ConsentNotice{
+		TenantEntity: types.TenantEntity{TenantID: tenantID},
+		SeriesID:     types.NewID(),
+		Title:        title,
+		Version:      1,
+	}
+	if err := s.draft(ctx, n, req.PurposeIDs); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Regenerate drafts the next version of a generated notice from the latest
+// published RoPA, keeping its purpose scope.
+func (s *NoticeGeneratorService) Regenerate(ctx context.Context, noticeID types.ID) (*consent.ConsentNotice, error) {
+	prev, err := s.get(ctx, noticeID)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Generation == nil {
+		return nil, types.NewValidationError("notice was not generated from the RoPA", map[string]any{"id": noticeID})
+	}
+	latest, err := s.noticeRepo.GetLatestVersion(ctx, prev.SeriesID)
+	if err != nil {
+		return nil, fmt.Errorf("get latest notice version: %w", err)
+	}
+
+	n := &consent.ConsentNotice{
+		TenantEntity: types.TenantEntity{TenantID: prev.TenantID},
+		SeriesID:     prev.SeriesID,
+		Title:        prev.Title,
+		Version:      max(latest, prev.Version) + 1,
+		WidgetIDs:    prev.WidgetIDs,
+	}
+	if err := s.draft(ctx, n, prev.Generation.Scope); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// SyncStatus lists the tenant's generated notices with their sync status.
+func (s *NoticeGeneratorService) SyncStatus(ctx context.Context) ([]NoticeSyncStatus, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	notices, err := s.noticeRepo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list notices: %w", err)
+	}
+
+	out := []NoticeSyncStatus{}
+	for _, n := range notices {
+		if n.Generation == nil || n.Status == consent.NoticeStatusArchived {
+			continue
+		}
+		out = append(out, NoticeSyncStatus{
+			NoticeID:    n.ID,
+			SeriesID:    n.SeriesID,
+			Title:       n.Title,
+			Version:     n.Version,
+			Status:      n.Status,
+			RoPAVersion: n.Generation.RoPAVersion,
+			OutOfSync:   n.Generation.OutOfSync,
+			StaleFields: n.Generation.StaleFields,
+		})
+	}
+	return out, nil
+}
+
+// RoPAPublished checks the tenant's generated notices against a newly
+// published RoPA version and flags those it would generate differently.
+// It implements RoPAPublishHook.
+func (s *NoticeGeneratorService) RoPAPublished(ctx context.Context, version *compliance.RoPAVersion) {
+	notices, err := s.noticeRepo.GetByTenant(ctx, version.TenantID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list notices for RoPA sync", "tenant_id", version.TenantID, "error", err)
+		return
+	}
+
+	homeCountry, _ := s.tenantDetails(ctx, version.TenantID)
+	now := s.now().UTC()
+	for i := range notices {
+		n := &notices[i]
+		if n.Generation == nil || n.Status == consent.NoticeStatusArchived {
+			continue
+		}
+		basis, _ := deriveNoticeBasis(&version.Content, n.Generation.Scope, homeCountry)
+		stale := staleNoticeFields(n.Generation.Basis, basis)
+		wasOutOfSync := n.Generation.OutOfSync
+
+		n.Generation.OutOfSync = len(stale) > 0
+		n.Generation.StaleFields = stale
+		n.Generation.CheckedAt = &now
+		n.Generation.CheckedAgainst = version.Version
+		if err := s.noticeRepo.Update(ctx, n); err != nil {
+			s.logger.ErrorContext(ctx, "failed to update notice sync status", "notice_id", n.ID, "error", err)
+			continue
+		}
+
+		if n.Generation.OutOfSync && !wasOutOfSync {
+			s.publishEvent(ctx, EventNoticeOutOfSync, n.TenantID, map[string]any{
+				"id":           n.ID,
+				"series_id":    n.SeriesID,
+				"ropa_version": version.Version,
+				"stale_fields": stale,
+			})
+			s.logger.InfoContext(ctx, "generated notice out of sync with RoPA",
+				slog.String("notice_id", n.ID.String()),
+				slog.String("ropa_version", version.Version),
+				slog.Any("stale_fields", stale),
+			)
+		}
+	}
+}
+
+// Start subscribes to notice publication to translate published generated
+// notices into the Indic languages.
+func (s *NoticeGeneratorService) Start(ctx context.Context) error {
+	if s.translationSvc == nil {
+		return nil
+	}
+	if _, err := s.eventBus.Subscribe(ctx, "consent.notice_published", s.handleNoticePublished); err != nil {
+		return fmt.Errorf("subscribe consent.notice_published: %w", err)
+	}
+	return nil
+}
+
+func (s *NoticeGeneratorService) handleNoticePublished(ctx context.Context, event eventbus.Event) error {
+	var payload struct {
+		ID types.ID `json:"id"`
+	}
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("marshal event data: %w", err)
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("unmarshal event data: %w", err)
+	}
+
+	n, err := s.noticeRepo.GetByID(ctx, payload.ID)
+	if err != nil {
+		return fmt.Errorf("get notice: %w", err)
+	}
+	if n.Generation == nil {
+		return nil // Hand-written notices are translated on request
+	}
+
+	translations, err := s.translationSvc.TranslateNotice(ctx, n.ID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to translate generated notice", "notice_id", n.ID, "error", err)
+		return err
+	}
+	s.logger.InfoContext(ctx, "generated notice translated", "notice_id", n.ID, "version", n.Version, "languages", len(translations))
+	return nil
+}
+
+// draft fills n from the latest published RoPA and stores it as a DRAFT.
+func (s *NoticeGeneratorService) draft(ctx context.Context, n *consent.ConsentNotice, scope []types.ID) error {
+	ropa, err := s.latestPublishedRoPA(ctx, n.TenantID)
+	if err != nil {
+		return err
+	}
+
+	homeCountry, orgName := s.tenantDetails(ctx, n.TenantID)
+	if ropa.Content.OrganizationName != "" {
+		orgName = ropa.Content.OrganizationName
+	}
+	var dpo *compliance.DPOContact
+	if c, err := s.dpoRepo.Get(ctx, n.TenantID); err == nil {
+		dpo = c
+		if c.OrgName != "" {
+			orgName = c.OrgName
+		}
+	}
+
+	basis, purposes := deriveNoticeBasis(&ropa.Content, scope, homeCountry)
+	if len(purposes) == 0 {
+		return types.NewValidationError("the published RoPA has no active purposes in scope", map[string]any{"purpose_ids": scope})
+	}
+
+	n.Schema = noticeSchema(basis, orgName, dpo)
+	n.Content, err = renderNotice(noticeView{
+		OrgName:     orgName,
+		HomeCountry: homeCountry,
+		Schema:      n.Schema,
+		Purposes:    purposes,
+		Retention:   noticeRetention(&ropa.Content, purposes),
+		Recipients:  noticeRecipients(&ropa.Content),
+	})
+	if err != nil {
+		return err
+	}
+
+	n.Purposes = make([]types.ID, 0, len(purposes))
+	for _, p := range purposes {
+		n.Purposes = append(n.Purposes, p.ID)
+	}
+	now := s.now().UTC()
+	n.ID = types.NewID()
+	n.CreatedAt = now
+	n.UpdatedAt = now
+	n.Status = consent.NoticeStatusDraft
+	n.Regulation = "DPDPA_2023"
+	if n.WidgetIDs == nil {
+		n.WidgetIDs = []types.ID{}
+	}
+	n.Generation = &consent.NoticeGeneration{
+		RoPAVersionID: ropa.ID,
+		RoPAVersion:   ropa.Version,
+		Scope:         scope,
+		Basis:         basis,
+		GeneratedAt:   now,
+	}
+
+	if err := s.noticeRepo.Create(ctx, n); err != nil {
+		return fmt.Errorf("create notice: %w", err)
+	}
+
+	s.publishEvent(ctx, EventNoticeGenerated, n.TenantID, map[string]any{
+		"id":           n.ID,
+		"series_id":    n.SeriesID,
+		"version":      n.Version,
+		"ropa_version": ropa.Version,
+	})
+	return nil
+}
+
+// tenantDetails returns the tenant's home country, India by default, and
+// its name.
+func (s *NoticeGeneratorService) tenantDetails(ctx context.Context, tenantID types.ID) (country, name string) {
+	country = "India"
+	if tenant, err := s.tenantRepo.GetByID(ctx, tenantID); err == nil {
+		if tenant.Country != "" {
+			country = tenant.Country
+		}
+		name = tenant.Name
+	}
+	return country, name
+}
+
+// latestPublishedRoPA returns the tenant's PUBLISHED RoPA version.
+func (s *NoticeGeneratorService) latestPublishedRoPA(ctx context.Context, tenantID types.ID) (*compliance.RoPAVersion, error) {
+	versions, err := s.ropaRepo.ListVersions(ctx, tenantID, types.Pagination{Page: 1, PageSize: 1000})
+	if err != nil {
+		return nil, fmt.Errorf("list ropa versions: %w", err)
+	}
+	for i := range versions.Items {
+		if versions.Items[i].Status == compliance.RoPAStatusPublished {
+			return &versions.Items[i], nil
+		}
+	}
+	return nil, types.NewValidationError("publish a RoPA version before generating notices", nil)
+}
+
+func (s *NoticeGeneratorService) get(ctx context.Context, id types.ID) (*consent.ConsentNotice, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	n, err := s.noticeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if n.TenantID != tenantID {
+		return nil, types.NewNotFoundError("notice not found", map[string]any{"id": id})
+	}
+	return n, nil
+}
+
+// publishEvent publishes a domain event (best-effort)
+func (s *NoticeGeneratorService) publishEvent(ctx context.Context, eventType string, tenantID types.ID, data any) {
+	event := eventbus.NewEvent(eventType, "consent", tenantID, data)
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Error("failed to publish event",
+			slog.String("event_type", eventType),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// =============================================================================
+// Derivation — RoPA to notice
+// =============================================================================
+
+// deriveNoticeBasis derives the RoPA-backed notice fields for the active
+// purposes in scope (every active purpose if scope is empty), and returns
+// those purposes. Transfers are to third parties outside homeCountry.
+func deriveNoticeBasis(c *compliance.RoPAContent, scope []types.ID, homeCountry string) (consent.NoticeRoPABasis, []compliance.RoPAPurpose) {
+	var purposes []compliance.RoPAPurpose
+	for _, p := range c.Purposes {
+		if p.IsActive && (len(scope) == 0 || slices.Contains(scope, p.ID)) {
+			purposes = append(purposes, p)
+		}
+	}
+	sort.Slice(purposes, func(i, j int) bool { return purposes[i].Name < purposes[j].Name })
+
+	var basis consent.NoticeRoPABasis
+	names := make(map[string]bool, len(purposes))
+	for _, p := range purposes {
+		basis.Purposes = append(basis.Purposes, p.Name)
+		names[p.Name] = true
+	}
+
+	// Data types: the categories retained for the purposes in scope, or
+	// every category on record.
+	categories := make(map[string]bool)
+	for _, r := range c.RetentionPolicies {
+		if names[r.PurposeName] {
+			for _, cat := range r.DataCategories {
+				categories[cat] = true
+			}
+		}
+	}
+	if len(categories) == 0 {
+		for _, cat := range c.DataCategories {
+			categories[cat] = true
+		}
+	}
+	for cat := range categories {
+		basis.DataTypesCollected = append(basis.DataTypesCollected, humanizeCode(cat))
+	}
+	sort.Strings(basis.DataTypesCollected)
+
+	recipients := make(map[string]bool)
+	for _, tp := range c.ThirdParties {
+		recipients[humanizeCode(tp.Type)+"s"] = true
+	}
+	for r := range recipients {
+		basis.SharingCategories = append(basis.SharingCategories, r)
+	}
+	sort.Strings(basis.SharingCategories)
+	if len(basis.SharingCategories) == 0 {
+		basis.SharingCategories = []string{"None"}
+	}
+
+	basis.CrossBorderTransfer = noticeTransfers(c, homeCountry)
+
+	var retention []string
+	for _, r := range noticeRetention(c, purposes) {
+		retention = append(retention, r.Purpose+": "+r.Period)
+	}
+	basis.RetentionPeriod = strings.Join(retention, "; ")
+	if basis.RetentionPeriod == "" {
+		basis.RetentionPeriod = "Until the purpose is served or consent is withdrawn, whichever is earlier, unless retention is required by law."
+	}
+	return basis, purposes
+}
+
+// noticeTransfers describes the countries outside homeCountry that third
+// parties are in, or "No".
+func noticeTransfers(c *compliance.RoPAContent, homeCountry string) string {
+	var countries []string
+	for _, tp := range c.ThirdParties {
+		if tp.Country != "" && !sameCountry(tp.Country, homeCountry) && !slices.Contains(countries, tp.Country) {
+			countries = append(countries, tp.Country)
+		}
+	}
+	if len(countries) == 0 {
+		return "No"
+	}
+	sort.Strings(countries)
+	return "Yes, to: " + strings.Join(countries, ", ")
+}
+
+// noticeRetentionLine is the retention period of one purpose.
+type noticeRetentionLine struct {
+	Purpose string
+	Period  string
+}
+
+// noticeRetention lists the retention period of each purpose with a policy.
+func noticeRetention(c *compliance.RoPAContent, purposes []compliance.RoPAPurpose) []noticeRetentionLine {
+	var out []noticeRetentionLine
+	for _, p := range purposes {
+		days := 0
+		for _, r := range c.RetentionPolicies {
+			if r.PurposeName == p.Name && r.MaxRetentionDays > days {
+				days = r.MaxRetentionDays
+			}
+		}
+		if days > 0 {
+			out = append(out, noticeRetentionLine{Purpose: p.Name, Period: fmt.Sprintf("%d days", days)})
+		}
+	}
+	return out
+}
+
+// noticeRecipients lists the third parties by recipient category.
+func noticeRecipients(c *compliance.RoPAContent) map[string][]string {
+	out := make(map[string][]string)
+	for _, tp := range c.ThirdParties {
+		key := humanizeCode(tp.Type) + "s"
+		out[key] = append(out[key], tp.Name)
+	}
+	return out
+}
+
+// staleNoticeFields lists the basis fields that differ.
+func staleNoticeFields(old, cur consent.NoticeRoPABasis) []string {
+	var stale []string
+	if !slices.Equal(old.DataTypesCollected, cur.DataTypesCollected) {
+		stale = append(stale, "data_types_collected")
+	}
+	if !slices.Equal(old.Purposes, cur.Purposes) {
+		stale = append(stale, "purposes")
+	}
+	if !slices.Equal(old.SharingCategories, cur.SharingCategories) {
+		stale = append(stale, "sharing_categories")
+	}
+	if old.CrossBorderTransfer != cur.CrossBorderTransfer {
+		stale = append(stale, "cross_border_transfer")
+	}
+	if old.RetentionPeriod != cur.RetentionPeriod {
+		stale = append(stale, "retention_period")
+	}
+	return stale
+}
+
+// noticeSchema fills the Schedule I fields. The generated text covers every
+// right, so their flags are set; contact fields come from the DPO contact.
+func noticeSchema(basis consent.NoticeRoPABasis, orgName string, dpo *compliance.DPOContact) consent.NoticeSchemaFields {
+	schema := consent.NoticeSchemaFields{
+		DataTypesCollected:   basis.DataTypesCollected,
+		Purposes:             basis.Purposes,
+		FiduciaryName:        orgName,
+		RightsWithdraw:       true,
+		RightsAccess:         true,
+		RightsCorrection:     true,
+		RightsGrievance:      true,
+		RightsNomination:     true,
+		BoardComplaintMethod: "If your grievance is not resolved, you may complain to the Data Protection Board of India through its online portal (DPDPA s.13(3)).",
+		SharingCategories:    basis.SharingCategories,
+		CrossBorderTransfer:  basis.CrossBorderTransfer,
+		RetentionPeriod:      basis.RetentionPeriod,
+	}
+	if dpo != nil {
+		contact := dpo.DPOEmail
+		if dpo.DPOPhone != nil && *dpo.DPOPhone != "" {
+			contact += ", " + *dpo.DPOPhone
+		}
+		schema.FiduciaryContact = contact
+		schema.DPOName = dpo.DPOName
+		schema.DPOContact = contact
+		if dpo.DPOEmail != "" {
+			schema.ComplaintMethod = "Write to our Grievance Officer at " + dpo.DPOEmail + ". We will respond within the period prescribed under the DPDP Rules."
+		}
+	}
+	return schema
+}
+
+// humanizeCode turns an enum code such as "GOVERNMENT_ID" into "Government id".
+func humanizeCode(code string) string {
+	s := strings.ToLower(strings.ReplaceAll(code, "_", " "))
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// =============================================================================
+// Rendering
+// =============================================================================
+
+type noticeView struct {
+	OrgName     string
+	HomeCountry string
+	Schema      consent.NoticeSchemaFields
+	Purposes    []compliance.RoPAPurpose
+	Retention   []noticeRetentionLine
+	Recipients  map[string][]string
+}
+
+var noticeTemplate = template.Must(template.New("notice").Funcs(template.FuncMap{
+	"join":     strings.Join,
+	"humanize": humanizeCode,
+}).Parse(`# Privacy Notice{{if .OrgName}} — {{.OrgName}}{{end}}
+
+This notice explains how {{if .OrgName}}{{.OrgName}}{{else}}we{{end}} process your personal data under the Digital Personal Data Protection Act, 2023.
+
+## 1. Personal data we process
+
+{{range .Schema.DataTypesCollected}}- {{.}}
+{{end}}
+## 2. Why we process it
+
+{{range .Purposes}}- **{{.Name}}**{{if .Description}}: {{.Description}}{{end}} (basis: {{humanize .LegalBasis}})
+{{end}}
+## 3. Who we share it with
+
+{{if .Recipients}}{{range $category, $names := .Recipients}}- {{$category}}: {{join $names ", "}}
+{{end}}{{else}}We do not share your personal data with third parties.
+{{end}}
+## 4. Transfers outside {{.HomeCountry}}
+
+{{if eq .Schema.CrossBorderTransfer "No"}}We do not transfer your personal data outside {{.HomeCountry}}.{{else}}{{.Schema.CrossBorderTransfer}}. Transfers comply with any restrictions notified under DPDPA s.16.{{end}}
+
+## 5. How long we keep it
+
+{{if .Retention}}{{range .Retention}}- {{.Purpose}}: {{.Period}}
+{{end}}{{else}}{{.Schema.RetentionPeriod}}
+{{end}}
+## 6. Your rights
+
+- **Withdraw consent** at any time, as easily as you gave it (s.6(4)).
+- **Access** a summary of your personal data and how it is processed (s.11).
+- **Correct, complete, update or erase** your personal data (s.12).
+- **Grievance redressal** for any act or omission of ours (s.13).
+- **Nominate** a person to exercise your rights in case of death or incapacity (s.14).
+
+## 7. Grievances and contact
+
+{{if .Schema.DPOName}}Data Protection Officer: {{.Schema.DPOName}}{{if .Schema.DPOContact}} ({{.Schema.DPOContact}}){{end}}
+{{end}}{{if .Schema.ComplaintMethod}}{{.Schema.ComplaintMethod}}
+{{end}}
+{{.Schema.BoardComplaintMethod}}
+`))
+
+// renderNotice renders the notice text in markdown.
+func renderNotice(v noticeView) (string, error) {
+	var buf bytes.Buffer
+	if err := noticeTemplate.Execute(&buf, v); err != nil {
+		return "", fmt.Errorf("render notice: %w", err)
+	}
+	return buf.String(), nil
+}
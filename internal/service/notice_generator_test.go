@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/domain/compliance"
+	"github.com/complyark/datalens/internal/domain/consent"
+	"github.com/complyark/datalens/internal/domain/governance"
+	"github.com/complyark/datalens/internal/domain/identity"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+type mockRoPARepo struct {
+	mu       sync.Mutex
+	versions []*compliance.RoPAVersion
+}
+
+func (r *mockRoPARepo) Create(_ context.Context, v *compliance.RoPAVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v.ID = types.NewID()
+	r.versions = append(r.versions, v)
+	return nil
+}
+func (r *mockRoPARepo) GetLatest(_ context.Context, tenantID types.ID) (*compliance.RoPAVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if r.versions[i].TenantID == tenantID {
+			return r.versions[i], nil
+		}
+	}
+	return nil, nil
+}
+func (r *mockRoPARepo) GetByVersion(_ context.Context, tenantID types.ID, version string) (*compliance.RoPAVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.versions {
+		if v.TenantID == tenantID && v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, types.NewNotFoundError("RoPA", version)
+}
+func (r *mockRoPARepo) ListVersions(_ context.Context, tenantID types.ID, _ types.Pagination) (*types.PaginatedResult[compliance.RoPAVersion], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var items []compliance.RoPAVersion
+	for i := len(r.versions) - 1; i >= 0; i-- { // Newest first
+		if r.versions[i].TenantID == tenantID {
+			items = append(items, *r.versions[i])
+		}
+	}
+	return &types.PaginatedResult[compliance.RoPAVersion]{Items: items, Total: len(items)}, nil
+}
+func (r *mockRoPARepo) UpdateStatus(_ context.Context, id types.ID, status compliance.RoPAStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.versions {
+		if v.ID == id {
+			v.Status = status
+			return nil
+		}
+	}
+	return types.NewNotFoundError("RoPA", id)
+}
+
+type noticeGenFixture struct {
+	ctx         context.Context
+	tenantID    types.ID
+	ropaSvc     *RoPAService
+	gen         *NoticeGeneratorService
+	noticeRepo  *mockNoticeRepo
+	purposeRepo *mockPurposeRepo
+	eventBus    *mockEventBus
+}
+
+// newNoticeGenFixture seeds an Indian tenant with a DPO, a marketing purpose
+// retaining contact data for a year, and a US processor.
+func newNoticeGenFixture(t *testing.T) *noticeGenFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	tenantRepo := newMockTenantRepo()
+	tenant := &identity.Tenant{Name: "Acme Retail", Country: "India"}
+	require.NoError(t, tenantRepo.Create(ctx, tenant))
+
+	dpoRepo := newMockDPOContactRepo()
+	require.NoError(t, dpoRepo.Upsert(ctx, &compliance.DPOContact{
+		TenantEntity: types.TenantEntity{TenantID: tenant.ID},
+		OrgName:      "Acme Retail Pvt Ltd",
+		DPOName:      "Asha Rao",
+		DPOEmail:     "dpo@acme.example",
+	}))
+
+	purposeRepo := newMockPurposeRepo()
+	marketing := &governance.Purpose{TenantEntity: types.TenantEntity{TenantID: tenant.ID}, Name: "Marketing", LegalBasis: types.LegalBasisConsent, IsActive: true}
+	require.NoError(t, purposeRepo.Create(ctx, marketing))
+
+	retentionRepo := newMockRetentionPolicyRepo()
+	require.NoError(t, retentionRepo.Create(ctx, &compliance.RetentionPolicy{
+		TenantID: tenant.ID, PurposeID: marketing.ID, MaxRetentionDays: 365, DataCategories: []string{"CONTACT"},
+	}))
+
+	tpRepo := &mockThirdPartyRepo{}
+	require.NoError(t, tpRepo.Create(ctx, &governance.ThirdParty{
+		TenantEntity: types.TenantEntity{TenantID: tenant.ID}, Name: "MailCo", Type: governance.ThirdPartyProcessor, Country: "US", IsActive: true,
+	}))
+
+	auditSvc := NewAuditService(newMockAuditRepo(), newTestLogger())
+	ropaRepo := &mockRoPARepo{}
+	ropaSvc := NewRoPAService(ropaRepo, purposeRepo, newMockDataSourceRepo(), retentionRepo, tpRepo, auditSvc, newTestLogger())
+
+	noticeRepo := newMockNoticeRepo()
+	eb := newMockEventBus()
+	gen := NewNoticeGeneratorService(noticeRepo, ropaRepo, dpoRepo, tenantRepo, nil, eb, newTestLogger())
+	ropaSvc.SetPublishHook(gen)
+
+	return &noticeGenFixture{
+		ctx:         context.WithValue(ctx, types.ContextKeyTenantID, tenant.ID),
+		tenantID:    tenant.ID,
+		ropaSvc:     ropaSvc,
+		gen:         gen,
+		noticeRepo:  noticeRepo,
+		purposeRepo: purposeRepo,
+		eventBus:    eb,
+	}
+}
+
+func (f *noticeGenFixture) publishRoPA(t *testing.T) {
+	t.Helper()
+	v, err := f.ropaSvc.Generate(f.ctx)
+	require.NoError(t, err)
+	require.NoError(t, f.ropaSvc.Publish(f.ctx, v.ID))
+}
+
+func (f *noticeGenFixture) eventTypes() []string {
+	var out []string
+	for _, e := range f.eventBus.Events {
+		out = append(out, e.Type)
+	}
+	return out
+}
+
+func TestNoticeGenerator_Generate(t *testing.T) {
+	f := newNoticeGenFixture(t)
+
+	_, err := f.gen.Generate(f.ctx, GenerateNoticeRequest{})
+	require.ErrorIs(t, err, types.ErrValidation, "needs a published RoPA")
+
+	f.publishRoPA(t)
+	n, err := f.gen.Generate(f.ctx, GenerateNoticeRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Privacy Notice", n.Title)
+	assert.Equal(t, consent.NoticeStatusDraft, n.Status)
+	assert.Len(t, n.Purposes, 1)
+	require.NotNil(t, n.Generation)
+	assert.Equal(t, "1.0", n.Generation.RoPAVersion)
+
+	// Every Schedule I field is filled, so the draft can be published as is.
+	assert.Empty(t, NewNoticeService(f.noticeRepo, nil, f.eventBus, newTestLogger()).ValidateSchema(n))
+	assert.Equal(t, "Acme Retail Pvt Ltd", n.Schema.FiduciaryName)
+	assert.Equal(t, []string{"Contact"}, n.Schema.DataTypesCollected)
+	assert.Equal(t, []string{"Processors"}, n.Schema.SharingCategories)
+	assert.Equal(t, "Yes, to: US", n.Schema.CrossBorderTransfer)
+	assert.Equal(t, "Marketing: 365 days", n.Schema.RetentionPeriod)
+
+	assert.Contains(t, n.Content, "**Marketing** (basis: Consent)")
+	assert.Contains(t, n.Content, "- Processors: MailCo")
+	assert.Contains(t, n.Content, "Data Protection Officer: Asha Rao (dpo@acme.example)")
+	assert.Contains(t, f.eventTypes(), EventNoticeGenerated)
+}
+
+func TestNoticeGenerator_OutOfSyncAfterRoPAChange(t *testing.T) {
+	f := newNoticeGenFixture(t)
+	f.publishRoPA(t)
+	n, err := f.gen.Generate(f.ctx, GenerateNoticeRequest{})
+	require.NoError(t, err)
+
+	// Republishing an unchanged RoPA keeps the notice in sync.
+	f.publishRoPA(t)
+	stored, err := f.noticeRepo.GetByID(f.ctx, n.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Generation.OutOfSync)
+	assert.Equal(t, "1.1", stored.Generation.CheckedAgainst)
+
+	// A new purpose changes the notice.
+	require.NoError(t, f.purposeRepo.Create(f.ctx, &governance.Purpose{
+		TenantEntity: types.TenantEntity{TenantID: f.tenantID}, Name: "Analytics", LegalBasis: types.LegalBasisConsent, IsActive: true,
+	}))
+	f.publishRoPA(t)
+
+	statuses, err := f.gen.SyncStatus(f.ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.True(t, statuses[0].OutOfSync)
+	assert.Equal(t, []string{"purposes"}, statuses[0].StaleFields)
+	assert.Contains(t, f.eventTypes(), EventNoticeOutOfSync)
+
+	// Regenerating drafts the next version of the series, in sync.
+	next, err := f.gen.Regenerate(f.ctx, n.ID)
+	require.NoError(t, err)
+	assert.Equal(t, n.SeriesID, next.SeriesID)
+	assert.Equal(t, 2, next.Version)
+	assert.Equal(t, "1.2", next.Generation.RoPAVersion)
+	assert.Equal(t, []string{"Analytics", "Marketing"}, next.Schema.Purposes)
+	assert.False(t, next.Generation.OutOfSync)
+}
+
+func TestNoticeGenerator_ScopedToPurposes(t *testing.T) {
+	f := newNoticeGenFixture(t)
+	analytics := &governance.Purpose{TenantEntity: types.TenantEntity{TenantID: f.tenantID}, Name: "Analytics", LegalBasis: types.LegalBasisLegitimateInterest, IsActive: true}
+	require.NoError(t, f.purposeRepo.Create(f.ctx, analytics))
+	f.publishRoPA(t)
+
+	n, err := f.gen.Generate(f.ctx, GenerateNoticeRequest{Title: "Analytics notice", PurposeIDs: []types.ID{analytics.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, []types.ID{analytics.ID}, n.Purposes)
+	assert.Equal(t, []string{"Analytics"}, n.Schema.Purposes)
+
+	// Changes to other purposes leave it in sync.
+	marketing := f.purposeIDByName(t, "Marketing")
+	require.NoError(t, f.purposeRepo.Delete(f.ctx, marketing))
+	f.publishRoPA(t)
+	stored, err := f.noticeRepo.GetByID(f.ctx, n.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Generation.OutOfSync, "stale: %v", stored.Generation.StaleFields)
+
+	_, err = f.gen.Generate(f.ctx, GenerateNoticeRequest{PurposeIDs: []types.ID{types.NewID()}})
+	assert.ErrorIs(t, err, types.ErrValidation)
+}
+
+func TestStaleNoticeFields(t *testing.T) {
+	old := deriveBasisFor(t, "US")
+	assert.Empty(t, staleNoticeFields(old, deriveBasisFor(t, "US")))
+	assert.Equal(t, []string{"cross_border_transfer"}, staleNoticeFields(old, deriveBasisFor(t, "India")))
+}
+
+func deriveBasisFor(t *testing.T, processorCountry string) consent.NoticeRoPABasis {
+	t.Helper()
+	basis, purposes := deriveNoticeBasis(&compliance.RoPAContent{
+		Purposes:     []compliance.RoPAPurpose{{ID: types.NewID(), Name: "Billing", IsActive: true}},
+		ThirdParties: []compliance.RoPAThirdParty{{Name: "PayCo", Type: "PROCESSOR", Country: processorCountry}},
+	}, nil, "IN")
+	require.Len(t, purposes, 1)
+	return basis
+}
+
+func (f *noticeGenFixture) purposeIDByName(t *testing.T, name string) types.ID {
+	t.Helper()
+	purposes, err := f.purposeRepo.GetByTenant(f.ctx, f.tenantID)
+	require.NoError(t, err)
+	for _, p := range purposes {
+		if p.Name == name {
+			return p.ID
+		}
+	}
+	t.Fatalf("purpose %q not found", name)
+	return types.ID{}
+}
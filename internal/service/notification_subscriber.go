@@ -8,6 +8,8 @@ import (
 
 	"github.com/complyark/datalens/internal/domain/breach"
 	"github.com/complyark/datalens/internal/domain/consent"
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/domain/identity"
 	"github.com/complyark/datalens/pkg/eventbus"
 	"github.com/complyark/datalens/pkg/types"
 )
@@ -62,6 +64,20 @@ func (s *NotificationSubscriber) Start(ctx context.Context) error {
 		s.logger.Info("subscribed to breach event", "topic", topic)
 	}
 
+	// Schema drift alerts go to the tenant (data fiduciary), not a data principal
+	if _, err := s.eventBus.Subscribe(ctx, eventbus.EventSchemaDriftDetected, s.handleSchemaDriftEvent); err != nil {
+		return fmt.Errorf("subscribe %s: %w", eventbus.EventSchemaDriftDetected, err)
+	}
+	s.logger.Info("subscribed to schema event", "topic", eventbus.EventSchemaDriftDetected)
+
+	// AI budget alerts also go to the tenant
+	for _, topic := range []string{eventbus.EventAIBudgetThreshold, eventbus.EventAIBudgetExceeded} {
+		if _, err := s.eventBus.Subscribe(ctx, topic, s.handleAIBudgetEvent); err != nil {
+			return fmt.Errorf("subscribe %s: %w", topic, err)
+		}
+		s.logger.Info("subscribed to AI budget event", "topic", topic)
+	}
+
 	return nil
 }
 
@@ -180,3 +196,75 @@ func (s *NotificationSubscriber) handleBreachEvent(ctx context.Context, event ev
 
 	return nil
 }
+
+func (s *NotificationSubscriber) handleSchemaDriftEvent(ctx context.Context, event eventbus.Event) error {
+	var drift discovery.SchemaDrift
+	dataBytes, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(dataBytes, &drift); err != nil {
+		return err
+	}
+
+	// New columns already classified as PII are called out separately so
+	// templates can highlight them.
+	var newPIIFields []string
+	for _, f := range drift.AddedFields {
+		if f.PIIType != "" {
+			newPIIFields = append(newPIIFields, fmt.Sprintf("%s.%s (%s)", f.Entity, f.Field, f.PIIType))
+		}
+	}
+
+	payload := map[string]any{
+		"data_source_id":   drift.DataSourceID.String(),
+		"data_source_name": drift.DataSourceName,
+		"added_entities":   drift.AddedEntities,
+		"removed_entities": drift.RemovedEntities,
+		"added_fields":     drift.AddedFields,
+		"removed_fields":   drift.RemovedFields,
+		"retyped_fields":   drift.RetypedFields,
+		"new_pii_fields":   newPIIFields,
+		"change_count":     drift.ChangeCount(),
+	}
+
+	return s.notificationService.DispatchNotification(
+		ctx,
+		event.Type,
+		event.TenantID,
+		consent.RecipientTypeDataFiduciary,
+		event.TenantID.String(),
+		payload,
+	)
+}
+
+func (s *NotificationSubscriber) handleAIBudgetEvent(ctx context.Context, event eventbus.Event) error {
+	var status identity.AIBudgetStatus
+	dataBytes, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(dataBytes, &status); err != nil {
+		return err
+	}
+
+	payload := map[string]any{
+		"period":       status.Period,
+		"level":        status.Level,
+		"percent_used": fmt.Sprintf("%.0f", status.PercentUsed),
+		"tokens_used":  status.TokensUsed,
+		"token_limit":  status.TokenLimit,
+		"cost_used":    fmt.Sprintf("%.2f", status.CostUsed),
+		"cost_limit":   fmt.Sprintf("%.2f", status.CostLimit),
+		"hard_limit":   status.HardLimit,
+	}
+
+	return s.notificationService.DispatchNotification(
+		ctx,
+		event.Type,
+		event.TenantID,
+		consent.RecipientTypeDataFiduciary,
+		event.TenantID.String(),
+		payload,
+	)
+}
@@ -21,6 +21,7 @@ type PolicyService struct {
 	piiRepo       discovery.PIIClassificationRepository
 	eventBus      eventbus.EventBus
 	auditService  *AuditService
+	reidRiskRepo  discovery.ReidentificationRiskRepository
 	logger        *slog.Logger
 }
 
@@ -48,6 +49,13 @@ func NewPolicyService(
 	}
 }
 
+// SetReidentificationRiskRepo enables the "reid_risk_score" and
+// "reid_risk_level" rule fields, which match quasi-identifier fields of
+// entities by their stored re-identification risk.
+func (s *PolicyService) SetReidentificationRiskRepo(repo discovery.ReidentificationRiskRepository) {
+	s.reidRiskRepo = repo
+}
+
 // CreatePolicy creates a new governance policy.
 func (s *PolicyService) CreatePolicy(ctx context.Context, p *governance.Policy) error {
 	tenantID, ok := types.TenantIDFromContext(ctx)
@@ -130,9 +138,14 @@ func (s *PolicyService) evaluatePolicy(ctx context.Context, policy governance.Po
 		return fmt.Errorf("fetch classifications: %w", err)
 	}
 
+	risks, err := s.reidRisksFor(ctx, policy)
+	if err != nil {
+		return err
+	}
+
 	for _, pii := range result.Items {
 		// Check for violation
-		if isViolation(policy, pii) {
+		if isViolation(policy, pii, risks[reidRiskKey(pii.DataSourceID, pii.EntityName)]) {
 			// Create Violation Record
 			violation := &governance.Violation{
 				TenantEntity: types.TenantEntity{
@@ -178,9 +191,43 @@ func (s *PolicyService) evaluatePolicy(ctx context.Context, policy governance.Po
 	return nil
 }
 
+// reidRisksFor loads entity re-identification risks, keyed by
+// reidRiskKey, when the policy has a rule on them.
+func (s *PolicyService) reidRisksFor(ctx context.Context, policy governance.Policy) (map[string]*discovery.ReidentificationRisk, error) {
+	if s.reidRiskRepo == nil {
+		return nil, nil
+	}
+	needed := false
+	for _, rule := range policy.Rules {
+		if rule.Field == "reid_risk_score" || rule.Field == "reid_risk_level" {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil, nil
+	}
+
+	items, err := s.reidRiskRepo.GetReidentificationRisks(ctx, policy.TenantID, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("fetch reidentification risks: %w", err)
+	}
+	risks := make(map[string]*discovery.ReidentificationRisk, len(items))
+	for i := range items {
+		risks[reidRiskKey(items[i].DataSourceID, items[i].EntityName)] = &items[i].Risk
+	}
+	return risks, nil
+}
+
+func reidRiskKey(dataSourceID types.ID, entityName string) string {
+	return dataSourceID.String() + "/" + entityName
+}
+
 // isViolation checks if the PII classification violates the policy.
-// This is a simplified rule engine.
-func isViolation(policy governance.Policy, pii discovery.PIIClassification) bool {
+// This is a simplified rule engine. risk is the re-identification risk of
+// the classification's entity, or nil if it was not assessed; rules on it
+// only match fields that are among the entity's quasi-identifiers.
+func isViolation(policy governance.Policy, pii discovery.PIIClassification, risk *discovery.ReidentificationRisk) bool {
 	// If any rule matches, we consider it a match (OR logic) or all match (AND logic)?
 	// Usually policies are "If X then Violation".
 	// Let's assume AND logic for fields within a rule, but we have a list of rules.
@@ -202,6 +249,12 @@ func isViolation(policy governance.Policy, pii discovery.PIIClassification) bool
 			matched = compare(string(pii.Type), rule.Operator, rule.Value)
 		case "status":
 			matched = compare(string(pii.Status), rule.Operator, rule.Value)
+		case "reid_risk_score":
+			matched = risk != nil && risk.HasQuasiIdentifier(pii.FieldName) &&
+				compareNumber(risk.Score, rule.Operator, rule.Value)
+		case "reid_risk_level":
+			matched = risk != nil && risk.HasQuasiIdentifier(pii.FieldName) &&
+				compare(string(risk.Level), rule.Operator, rule.Value)
 		default:
 			// Unknown field, ignore or fail? Let's ignore.
 		}
@@ -239,3 +292,33 @@ func compare(actual string, op string, expected any) bool {
 	}
 	return false
 }
+
+// compareNumber compares a numeric attribute. Rule values decoded from JSON
+// arrive as float64.
+func compareNumber(actual float64, op string, expected any) bool {
+	var exp float64
+	switch v := expected.(type) {
+	case float64:
+		exp = v
+	case int:
+		exp = float64(v)
+	default:
+		return false
+	}
+
+	switch op {
+	case "EQ":
+		return actual == exp
+	case "NEQ":
+		return actual != exp
+	case "GT":
+		return actual > exp
+	case "GTE":
+		return actual >= exp
+	case "LT":
+		return actual < exp
+	case "LTE":
+		return actual <= exp
+	}
+	return false
+}
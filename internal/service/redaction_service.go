@@ -0,0 +1,506 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/crypto"
+	"github.com/complyark/datalens/pkg/redact"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// Redaction request limits and defaults.
+const (
+	maxRedactionInput             = 1 << 20 // Bytes of text or JSON per request
+	defaultRedactionConfidence    = 0.5
+	defaultRedactionRetentionDays = 30
+	maxRedactionRetentionDays     = 365
+)
+
+// pseudonymBytes is how much of the HMAC a pseudonym keeps: 128 bits, so
+// two values of one tenant never share a token and Restore cannot return
+// another person's value.
+const pseudonymBytes = 16
+
+// redactionTokenRe matches pseudonym tokens: [EMAIL_3f9a0c1b7d2e4a5b8c6d9e0f1a2b3c4d].
+var redactionTokenRe = regexp.MustCompile(`\[[A-Z0-9_]+_[0-9a-f]{32}\]`)
+
+// jsonPathIdentRe matches object keys that need no quoting in a span path.
+var jsonPathIdentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// RedactionService is the public PII redaction API: it finds personal data
+// and credentials in customers' own prompts, logs and JSON with the
+// detectors used for scanning, and redacts or pseudonymizes them.
+// Detection runs offline — the text never reaches an LLM.
+//
+// Pseudonyms are an HMAC of the value under a per-tenant key, so one value
+// always gets the same token within a tenant and never across tenants.
+// Reversible requests keep the values, encrypted, in a token vault that
+// Restore reads from.
+type RedactionService struct {
+	detector         *detection.ComposableDetector
+	tenantStrategies TenantStrategyProvider
+	vault            discovery.RedactionTokenRepository
+	cipher           crypto.CredentialCipher
+	auditSvc         *AuditService
+	pseudonymKey     []byte
+	logger           *slog.Logger
+	now              func() time.Time
+}
+
+// NewRedactionService creates a new RedactionService. The detector should
+// be an offline one; pseudonym keys are derived from secretKey.
+func NewRedactionService(
+	detector *detection.ComposableDetector,
+	vault discovery.RedactionTokenRepository,
+	cipher crypto.CredentialCipher,
+	secretKey string,
+	auditSvc *AuditService,
+	logger *slog.Logger,
+) *RedactionService {
+	return &RedactionService{
+		detector:     detector,
+		vault:        vault,
+		cipher:       cipher,
+		auditSvc:     auditSvc,
+		pseudonymKey: []byte(secretKey),
+		logger:       logger.With("service", "redaction"),
+		now:          time.Now,
+	}
+}
+
+// SetTenantStrategies adds each tenant's custom detection rules to the
+// detectors used for its requests.
+func (s *RedactionService) SetTenantStrategies(p TenantStrategyProvider) {
+	s.tenantStrategies = p
+}
+
+// Redact detects PII in req.Text or in every string and number of
+// req.JSON, and replaces it as req.Mode says.
+func (s *RedactionService) Redact(ctx context.Context, req redact.Request) (*redact.Response, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	r, err := s.newRedaction(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &redact.Response{}
+	if req.Text != "" {
+		resp.Text, resp.Spans, err = r.scan(ctx, "", "", req.Text)
+	} else {
+		resp.JSON, err = transformJSON(req.JSON, func(path, key, value string) (string, error) {
+			out, spans, err := r.scan(ctx, path, key, value)
+			resp.Spans = append(resp.Spans, spans...)
+			return out, err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.Spans == nil {
+		resp.Spans = []redact.Span{}
+	}
+
+	if err := s.store(ctx, r); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Restore replaces the vault tokens in req.Text or req.JSON with the
+// values they stand for. Tokens the tenant's vault does not hold are left
+// in place and listed as unresolved. Every restore is audited.
+func (s *RedactionService) Restore(ctx context.Context, req redact.RestoreRequest) (*redact.RestoreResponse, error) {
+	tenantID, ok := types.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, types.NewForbiddenError("tenant context required")
+	}
+	if err := validateRedactionInput(req.Text, req.JSON); err != nil {
+		return nil, err
+	}
+
+	// Collect the tokens, then look them all up at once
+	seen := make(map[string]bool)
+	collect := func(_, _, value string) (string, error) {
+		for _, tok := range redactionTokenRe.FindAllString(value, -1) {
+			seen[tok] = true
+		}
+		return value, nil
+	}
+	if req.Text != "" {
+		collect("", "", req.Text)
+	} else if _, err := transformJSON(req.JSON, collect); err != nil {
+		return nil, err
+	}
+	tokens := make([]string, 0, len(seen))
+	for tok := range seen {
+		tokens = append(tokens, tok)
+	}
+	sort.Strings(tokens)
+
+	stored, err := s.vault.GetByTokens(ctx, tenantID, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("get redaction tokens: %w", err)
+	}
+	values := make(map[string]string, len(stored))
+	restoredTypes := make(map[string]int)
+	for _, t := range stored {
+		v, err := s.cipher.Decrypt(ctx, tenantID, t.Ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt redaction token: %w", err)
+		}
+		values[t.Token] = v
+		restoredTypes[string(t.PIIType)]++
+	}
+
+	resp := &redact.RestoreResponse{}
+	replace := func(_, _, value string) (string, error) {
+		return redactionTokenRe.ReplaceAllStringFunc(value, func(tok string) string {
+			if v, ok := values[tok]; ok {
+				resp.Restored++
+				return v
+			}
+			return tok
+		}), nil
+	}
+	if req.Text != "" {
+		resp.Text, _ = replace("", "", req.Text)
+	} else if resp.JSON, err = transformJSON(req.JSON, replace); err != nil {
+		return nil, err
+	}
+	for _, tok := range tokens {
+		if _, ok := values[tok]; !ok {
+			resp.Unresolved = append(resp.Unresolved, tok)
+		}
+	}
+
+	if len(values) > 0 {
+		userID, _ := types.UserIDFromContext(ctx)
+		s.auditSvc.Log(ctx, userID, "PII_DETOKENIZE", "REDACTION_TOKEN", types.ID{}, nil, map[string]any{
+			"tokens":     len(values),
+			"pii_types":  restoredTypes,
+			"unresolved": len(resp.Unresolved),
+		}, tenantID)
+	}
+	return resp, nil
+}
+
+// PurgeExpired deletes vault tokens past their retention, run daily by the
+// scheduler. It returns how many were deleted.
+func (s *RedactionService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.vault.DeleteExpired(ctx, s.now().UTC())
+}
+
+// store encrypts and saves the tokens a reversible request issued.
+func (s *RedactionService) store(ctx context.Context, r *redaction) error {
+	if len(r.issued) == 0 {
+		return nil
+	}
+	tokens := make([]discovery.RedactionToken, 0, len(r.issued))
+	for token, v := range r.issued {
+		ciphertext, err := s.cipher.Encrypt(ctx, r.tenantID, v.value)
+		if err != nil {
+			return fmt.Errorf("encrypt redaction token: %w", err)
+		}
+		tokens = append(tokens, discovery.RedactionToken{
+			TenantID:   r.tenantID,
+			Token:      token,
+			PIIType:    v.piiType,
+			Ciphertext: ciphertext,
+			ExpiresAt:  r.expiresAt,
+		})
+	}
+	if err := s.vault.Save(ctx, tokens); err != nil {
+		return fmt.Errorf("save redaction tokens: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// Redaction
+// =============================================================================
+
+// redaction is the state of one Redact request.
+type redaction struct {
+	tenantID      types.ID
+	detector      *detection.ComposableDetector
+	mode          redact.Mode
+	types         map[types.PIIType]bool // Empty means all
+	minConfidence float64
+	key           []byte // Tenant pseudonym key
+
+	reversible bool
+	expiresAt  time.Time
+	issued     map[string]issuedToken // Token → value, reversible requests only
+}
+
+type issuedToken struct {
+	piiType types.PIIType
+	value   string
+}
+
+// newRedaction validates a request and prepares its redaction.
+func (s *RedactionService) newRedaction(ctx context.Context, tenantID types.ID, req redact.Request) (*redaction, error) {
+	if err := validateRedactionInput(req.Text, req.JSON); err != nil {
+		return nil, err
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = redact.ModeRedact
+	}
+	if mode != redact.ModeRedact && mode != redact.ModePseudonymize {
+		return nil, types.NewValidationError("mode must be REDACT or PSEUDONYMIZE", map[string]any{"mode": req.Mode})
+	}
+	if req.Reversible && mode != redact.ModePseudonymize {
+		return nil, types.NewValidationError("reversible requires mode PSEUDONYMIZE", nil)
+	}
+	if req.MinConfidence < 0 || req.MinConfidence > 1 {
+		return nil, types.NewValidationError("min_confidence must be between 0 and 1", nil)
+	}
+	if req.RetentionDays < 0 || req.RetentionDays > maxRedactionRetentionDays {
+		return nil, types.NewValidationError(fmt.Sprintf("retention_days must be between 1 and %d", maxRedactionRetentionDays), nil)
+	}
+
+	r := &redaction{
+		tenantID:      tenantID,
+		detector:      s.detector,
+		mode:          mode,
+		types:         make(map[types.PIIType]bool, len(req.Types)),
+		minConfidence: req.MinConfidence,
+		key:           tenantPseudonymKey(s.pseudonymKey, tenantID),
+		reversible:    req.Reversible,
+	}
+	if r.minConfidence == 0 {
+		r.minConfidence = defaultRedactionConfidence
+	}
+	for _, t := range req.Types {
+		r.types[types.PIIType(strings.ToUpper(strings.TrimSpace(t)))] = true
+	}
+	if r.reversible {
+		days := req.RetentionDays
+		if days == 0 {
+			days = defaultRedactionRetentionDays
+		}
+		r.expiresAt = s.now().UTC().AddDate(0, 0, days)
+		r.issued = make(map[string]issuedToken)
+	}
+
+	if s.tenantStrategies != nil {
+		extra, err := s.tenantStrategies.StrategiesForTenant(ctx, tenantID)
+		if err != nil {
+			// Built-in detection still runs; the rules are a refinement
+			s.logger.Warn("custom detection rules not loaded", "tenant_id", tenantID, "error", err)
+		} else if len(extra) > 0 {
+			r.detector = r.detector.WithStrategies(extra...)
+		}
+	}
+	return r, nil
+}
+
+// validateRedactionInput checks that exactly one of text and JSON is given
+// and within the size limit.
+func validateRedactionInput(text string, raw json.RawMessage) error {
+	if (text == "") == (len(raw) == 0) {
+		return types.NewValidationError("exactly one of text and json is required", nil)
+	}
+	if len(text) > maxRedactionInput || len(raw) > maxRedactionInput {
+		return types.NewValidationError(fmt.Sprintf("input exceeds %d bytes", maxRedactionInput), nil)
+	}
+	return nil
+}
+
+// scan finds the PII in one string and returns it replaced. For a JSON
+// value, key is the name it is stored under: when nothing is found inside
+// the value, the whole value is classified by its key as a column sample
+// would be, so {"name": "Asha"} is caught although "Asha" alone is not.
+func (r *redaction) scan(ctx context.Context, path, key, value string) (string, []redact.Span, error) {
+	// Unwanted spans are dropped before overlaps are resolved, so they
+	// cannot displace a wanted one.
+	spans, err := r.detector.DetectSpansWhere(ctx, value, func(sp detection.Span) bool {
+		return r.wanted(sp.Type, sp.Confidence)
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("detect spans: %w", err)
+	}
+
+	if len(spans) == 0 && key != "" {
+		report, err := r.detector.Detect(ctx, detection.Input{ColumnName: key, Samples: []string{value}})
+		if err != nil {
+			return "", nil, fmt.Errorf("detect value: %w", err)
+		}
+		for _, d := range report.Detections {
+			if r.wanted(d.Type, d.FinalConfidence) && len(d.Methods) > 0 {
+				spans = append(spans, detection.Span{
+					Start:       0,
+					End:         len(value),
+					Category:    d.Category,
+					Type:        d.Type,
+					Sensitivity: d.Sensitivity,
+					Confidence:  d.FinalConfidence,
+					Method:      d.Methods[0],
+				})
+				break
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return value, nil, nil
+	}
+
+	var b strings.Builder
+	out := make([]redact.Span, len(spans))
+	last := 0
+	for i, sp := range spans {
+		replacement := r.replacement(sp.Type, value[sp.Start:sp.End])
+		b.WriteString(value[last:sp.Start])
+		b.WriteString(replacement)
+		last = sp.End
+		out[i] = redact.Span{
+			Path:        path,
+			Start:       sp.Start,
+			End:         sp.End,
+			Type:        string(sp.Type),
+			Category:    string(sp.Category),
+			Sensitivity: string(sp.Sensitivity),
+			Confidence:  sp.Confidence,
+			Method:      string(sp.Method),
+			Replacement: replacement,
+		}
+	}
+	b.WriteString(value[last:])
+	return b.String(), out, nil
+}
+
+func (r *redaction) wanted(t types.PIIType, confidence float64) bool {
+	return confidence >= r.minConfidence && (len(r.types) == 0 || r.types[t])
+}
+
+// replacement returns what a detected value is replaced with, recording
+// reversible pseudonyms for the vault.
+func (r *redaction) replacement(t types.PIIType, value string) string {
+	label := tokenLabel(t)
+	if r.mode == redact.ModeRedact {
+		return "[" + label + "]"
+	}
+
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(t))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	token := "[" + label + "_" + hex.EncodeToString(mac.Sum(nil)[:pseudonymBytes]) + "]"
+	if r.reversible {
+		r.issued[token] = issuedToken{piiType: t, value: value}
+	}
+	return token
+}
+
+// tokenLabel turns a PII type into the label of its replacement:
+// CUSTOM:policy-no → POLICY_NO.
+func tokenLabel(t types.PIIType) string {
+	label := strings.ToUpper(strings.TrimPrefix(string(t), detection.CustomTypePrefix))
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, label)
+}
+
+// tenantPseudonymKey derives a tenant's pseudonym key, so tokens cannot be
+// correlated across tenants.
+func tenantPseudonymKey(secret []byte, tenantID types.ID) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("redaction-pseudonym:" + tenantID.String()))
+	return mac.Sum(nil)
+}
+
+// =============================================================================
+// JSON
+// =============================================================================
+
+// transformJSON applies fn to every string and number in a JSON document,
+// in key order, and returns the document rewritten. fn gets the value's
+// path, the object key it is stored under (for array items, the array's)
+// and its text; a number fn changes becomes a string.
+func transformJSON(raw json.RawMessage, fn func(path, key, value string) (string, error)) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, types.NewValidationError("invalid json: "+err.Error(), nil)
+	}
+
+	out, err := walkJSON(doc, "$", "", fn)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(out); err != nil {
+		return nil, fmt.Errorf("encode json: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func walkJSON(v any, path, key string, fn func(path, key, value string) (string, error)) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child, err := walkJSON(v[k], path+jsonPathKey(k), k, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = child
+		}
+		return v, nil
+	case []any:
+		for i := range v {
+			child, err := walkJSON(v[i], path+"["+strconv.Itoa(i)+"]", key, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = child
+		}
+		return v, nil
+	case string:
+		return fn(path, key, v)
+	case json.Number:
+		out, err := fn(path, key, v.String())
+		if err != nil || out == v.String() {
+			return v, err
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func jsonPathKey(k string) string {
+	if jsonPathIdentRe.MatchString(k) {
+		return "." + k
+	}
+	return "[" + strconv.Quote(k) + "]"
+}
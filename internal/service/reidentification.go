@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/infrastructure/connector"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// reidSampleRows is the number of records sampled per entity to estimate
+// re-identification risk.
+const reidSampleRows = 1000
+
+// assessReidentification samples the entity's quasi-identifier fields
+// together and stores the resulting risk on the entity. Entities without
+// quasi-identifiers have any earlier assessment cleared. Connectors that
+// cannot sample whole records are skipped, as are sampling failures, so a
+// scan never fails on this analysis.
+func (s *DiscoveryService) assessReidentification(
+	ctx context.Context,
+	conn discovery.Connector,
+	entityID types.ID,
+	entityName string,
+	fields []discovery.DataField,
+	piiTypes map[string]types.PIIType,
+) {
+	sampler, ok := connector.Unwrap(conn).(discovery.RowSampler)
+	if !ok {
+		return
+	}
+
+	qis := quasiIdentifiers(fields, piiTypes)
+
+	var risk *discovery.ReidentificationRisk
+	if len(qis) > 0 {
+		names := make([]string, len(qis))
+		for i, qi := range qis {
+			names[i] = qi.Field
+		}
+		rows, err := sampler.SampleRows(ctx, entityName, names, reidSampleRows)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to sample rows for re-identification risk", "entity", entityName, "error", err)
+			return
+		}
+		risk = discovery.AssessReidentification(rows, qis)
+	}
+
+	entity, err := s.entityRepo.GetByID(ctx, entityID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to load entity for re-identification risk", "entity", entityName, "error", err)
+		return
+	}
+	if entity.ReidRisk == nil && risk == nil {
+		return
+	}
+	entity.ReidRisk = risk
+	if err := s.entityRepo.Update(ctx, entity); err != nil {
+		s.logger.WarnContext(ctx, "failed to save re-identification risk", "entity", entityName, "error", err)
+		return
+	}
+
+	if risk != nil && risk.Level == discovery.ReidentificationLevelHigh {
+		s.logger.InfoContext(ctx, "high re-identification risk",
+			"entity", entityName,
+			"score", risk.Score,
+			"k", risk.K,
+			"identifying_sets", risk.IdentifyingSets)
+	}
+}
+
+// quasiIdentifiers picks the entity's quasi-identifier fields from their
+// detected PII types and names.
+func quasiIdentifiers(fields []discovery.DataField, piiTypes map[string]types.PIIType) []discovery.QuasiIdentifier {
+	var qis []discovery.QuasiIdentifier
+	for _, f := range fields {
+		if kind, ok := discovery.ClassifyQuasiIdentifier(f.Name, piiTypes[f.Name]); ok {
+			qis = append(qis, discovery.QuasiIdentifier{Field: f.Name, Kind: kind})
+		}
+	}
+	return qis
+}
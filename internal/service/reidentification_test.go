@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/config"
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/domain/governance"
+	"github.com/complyark/datalens/internal/infrastructure/connector"
+	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+func TestDiscoveryService_Scan_AssessesReidentificationRisk(t *testing.T) {
+	dsRepo := newMockDataSourceRepo()
+	invRepo := newMockDataInventoryRepo()
+	entityRepo := newMockDataEntityRepo()
+	fieldRepo := newMockDataFieldRepo()
+	piiRepo := newMockPIIClassificationRepo()
+	conn := new(MockRowSampler)
+
+	strategy := new(MockStrategy)
+	strategy.On("Detect", mock.Anything, mock.Anything).Return(nil, nil)
+	detector := detection.NewComposableDetector(strategy)
+
+	registry := connector.NewConnectorRegistry(&config.Config{}, detector, nil)
+	dsType := types.DataSourceType("TEST_ROWS")
+	registry.Register(dsType, func() discovery.Connector { return conn })
+
+	svc := NewDiscoveryService(dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, newMockScanRunRepo(), registry, detector, nil, newMockEventBus(), slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{BaseEntity: types.BaseEntity{ID: types.NewID()}, TenantID: types.NewID()},
+		Name:         "Patients",
+		Type:         dsType,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+
+	conn.On("Connect", ctx, mock.Anything).Return(nil)
+	conn.On("Close").Return(nil)
+	conn.On("DiscoverSchema", ctx, mock.Anything).Return(
+		&discovery.DataInventory{DataSourceID: ds.ID},
+		[]discovery.DataEntity{{Name: "patients", Type: discovery.EntityTypeTable}},
+		nil,
+	)
+	conn.On("GetFields", ctx, "patients").Return([]discovery.DataField{
+		{Name: "id", DataType: "uuid"},
+		{Name: "dob", DataType: "date"},
+		{Name: "gender", DataType: "varchar"},
+		{Name: "pin_code", DataType: "varchar"},
+	}, nil)
+	conn.On("SampleData", ctx, "patients", mock.Anything, 10).Return([]string{}, nil)
+	conn.On("SampleRows", ctx, "patients", []string{"dob", "gender", "pin_code"}, reidSampleRows).Return([]map[string]string{
+		{"dob": "1990-01-01", "gender": "F", "pin_code": "560001"},
+		{"dob": "1985-06-12", "gender": "M", "pin_code": "560001"},
+		{"dob": "1972-03-30", "gender": "M", "pin_code": "110092"},
+		{"dob": "1972-03-30", "gender": "M", "pin_code": "110092"},
+	}, nil)
+
+	_, err := svc.ScanDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+
+	inv, err := invRepo.GetByDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+	entities, err := entityRepo.GetByInventory(ctx, inv.ID)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+
+	risk := entities[0].ReidRisk
+	require.NotNil(t, risk)
+	assert.Equal(t, 75.0, risk.Score)
+	assert.Equal(t, 1, risk.K)
+	assert.Equal(t, 4, risk.SampleSize)
+	assert.Equal(t, []discovery.QuasiIdentifier{
+		{Field: "dob", Kind: discovery.QuasiIdentifierBirthDate},
+		{Field: "gender", Kind: discovery.QuasiIdentifierGender},
+		{Field: "pin_code", Kind: discovery.QuasiIdentifierPostalCode},
+	}, risk.QuasiIdentifiers)
+	conn.AssertExpectations(t)
+}
+
+func TestIsViolation_ReidentificationRisk(t *testing.T) {
+	policy := governance.Policy{
+		Rules: []governance.PolicyRule{
+			{Field: "reid_risk_score", Operator: "GTE", Value: 50.0},
+		},
+	}
+	risk := &discovery.ReidentificationRisk{
+		Score: 72,
+		Level: discovery.ReidentificationLevelHigh,
+		QuasiIdentifiers: []discovery.QuasiIdentifier{
+			{Field: "dob", Kind: discovery.QuasiIdentifierBirthDate},
+		},
+	}
+	dob := discovery.PIIClassification{FieldName: "dob", Type: types.PIITypeDOB}
+	email := discovery.PIIClassification{FieldName: "email", Type: types.PIITypeEmail}
+
+	assert.True(t, isViolation(policy, dob, risk))
+	assert.False(t, isViolation(policy, email, risk), "only quasi-identifiers match")
+	assert.False(t, isViolation(policy, dob, nil), "unassessed entities do not match")
+
+	policy.Rules[0].Value = 80.0
+	assert.False(t, isViolation(policy, dob, risk))
+
+	policy.Rules = []governance.PolicyRule{{Field: "reid_risk_level", Operator: "EQ", Value: "HIGH"}}
+	assert.True(t, isViolation(policy, dob, risk))
+}
+
+func TestSummarizeReidRisks(t *testing.T) {
+	var risks []discovery.EntityReidentificationRisk
+	for _, score := range []float64{90, 80, 60, 45, 30, 10, 5} {
+		risks = append(risks, discovery.EntityReidentificationRisk{
+			EntityID: types.NewID(),
+			Risk:     discovery.ReidentificationRisk{Score: score, Level: discovery.ReidentificationLevelFor(score)},
+		})
+	}
+
+	summary := summarizeReidRisks(risks)
+	assert.Equal(t, 7, summary.AssessedEntities)
+	assert.Equal(t, map[string]int{"HIGH": 3, "MEDIUM": 2, "LOW": 2}, summary.ByLevel)
+	require.Len(t, summary.TopEntities, dashboardTopReidRisks)
+	assert.Equal(t, 90.0, summary.TopEntities[0].Risk.Score)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +22,8 @@ type RoPAService struct {
 	dsRepo         discovery.DataSourceRepository
 	retentionRepo  compliance.RetentionPolicyRepository
 	thirdPartyRepo governance.ThirdPartyRepository
+	dpiaRepo       compliance.DPIARepository
+	publishHook    RoPAPublishHook
 	auditSvc       *AuditService
 	logger         *slog.Logger
 }
@@ -46,6 +49,23 @@ func NewRoPAService(
 	}
 }
 
+// SetDPIARepo links the tenant's approved DPIAs into generated RoPA
+// versions, alongside the purposes they cover.
+func (s *RoPAService) SetDPIARepo(repo compliance.DPIARepository) {
+	s.dpiaRepo = repo
+}
+
+// RoPAPublishHook is told when a RoPA version is published, to bring
+// documents derived from the RoPA up to date.
+type RoPAPublishHook interface {
+	RoPAPublished(ctx context.Context, version *compliance.RoPAVersion)
+}
+
+// SetPublishHook registers the hook told of published RoPA versions.
+func (s *RoPAService) SetPublishHook(hook RoPAPublishHook) {
+	s.publishHook = hook
+}
+
 // SaveEditRequest holds input for user edits to RoPA.
 type SaveEditRequest struct {
 	Content       compliance.RoPAContent `json:"content"`
@@ -147,6 +167,14 @@ func (s *RoPAService) Generate(ctx context.Context) (*compliance.RoPAVersion, er
 		content.DataCategories = append(content.DataCategories, cat)
 	}
 
+	if s.dpiaRepo != nil {
+		dpias, err := s.dpiaRepo.GetApproved(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("get approved dpias: %w", err)
+		}
+		linkDPIAs(&content, dpias)
+	}
+
 	// Version logic: get latest, bump minor
 	latest, err := s.ropaRepo.GetLatest(ctx, tenantID)
 	if err != nil {
@@ -250,7 +278,11 @@ func (s *RoPAService) Publish(ctx context.Context, id types.ID) error {
 		return fmt.Errorf("list ropa versions: %w", err)
 	}
 
-	for _, v := range allVersions.Items {
+	var published *compliance.RoPAVersion
+	for i, v := range allVersions.Items {
+		if v.ID == id {
+			published = &allVersions.Items[i]
+		}
 		if v.Status == compliance.RoPAStatusPublished {
 			if err := s.ropaRepo.UpdateStatus(ctx, v.ID, compliance.RoPAStatusArchived); err != nil {
 				return fmt.Errorf("archive existing published version: %w", err)
@@ -271,6 +303,11 @@ func (s *RoPAService) Publish(ctx context.Context, id types.ID) error {
 		slog.String("version_id", id.String()),
 	)
 
+	if s.publishHook != nil && published != nil {
+		published.Status = compliance.RoPAStatusPublished
+		s.publishHook.RoPAPublished(ctx, published)
+	}
+
 	return nil
 }
 
@@ -344,6 +381,28 @@ func (s *RoPAService) ListVersions(ctx context.Context, pagination types.Paginat
 	return s.ropaRepo.ListVersions(ctx, tenantID, pagination)
 }
 
+// linkDPIAs adds approved DPIAs to the RoPA and links each purpose to the
+// DPIAs covering it. A DPIA without purposes covers every purpose.
+func linkDPIAs(content *compliance.RoPAContent, dpias []compliance.DPIA) {
+	for _, d := range dpias {
+		content.DPIAs = append(content.DPIAs, compliance.RoPADPIA{
+			ID:           d.ID,
+			Title:        d.Title,
+			RiskLevel:    d.RiskLevel,
+			Status:       d.Status,
+			ApprovedAt:   d.ApprovedAt,
+			NextReviewAt: d.NextReviewAt,
+			PurposeIDs:   d.PurposeIDs,
+		})
+		for i := range content.Purposes {
+			p := &content.Purposes[i]
+			if len(d.PurposeIDs) == 0 || slices.Contains(d.PurposeIDs, p.ID) {
+				p.DPIAIDs = append(p.DPIAIDs, d.ID)
+			}
+		}
+	}
+}
+
 // parseVersion extracts major and minor version numbers from a version string.
 func parseVersion(v string) (major, minor int) {
 	parts := strings.Split(v, ".")
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/complyark/datalens/internal/domain/discovery"
@@ -19,6 +21,11 @@ type ScanService struct {
 	discoverySvc  DiscoveryOrchestrator
 	logger        *slog.Logger
 	maxConcurrent int
+
+	// inFlight holds cancel funcs for scans running in this process so
+	// CancelScan can stop them without waiting for the next checkpoint.
+	mu       sync.Mutex
+	inFlight map[types.ID]context.CancelFunc
 }
 
 // NewScanService creates a new ScanService.
@@ -36,6 +43,7 @@ func NewScanService(
 		discoverySvc:  discoverySvc,
 		logger:        logger.With("service", "scan_orchestrator"),
 		maxConcurrent: 3, // Default limit
+		inFlight:      make(map[types.ID]context.CancelFunc),
 	}
 }
 
@@ -107,36 +115,72 @@ func (s *ScanService) ProcessScanJob(ctx context.Context, jobID string) error {
 		return fmt.Errorf("fetch run: %w", err)
 	}
 
-	if run.Status != discovery.ScanStatusPending {
+	switch run.Status {
+	case discovery.ScanStatusPending:
+	case discovery.ScanStatusRunning:
+		// Redelivered after a worker crash — resume from the last checkpoint.
+		s.logger.Info("resuming interrupted scan", "run_id", runID,
+			"completed_entities", len(run.Checkpoint.CompletedEntities))
+	default:
 		s.logger.Warn("skipping non-pending job", "run_id", runID, "status", run.Status)
 		return nil
 	}
 
+	// Jobs run off-request; AI calls made during the scan are billed to the
+	// run's tenant.
+	scanCtx, cancel := context.WithCancel(context.WithValue(ctx, types.ContextKeyTenantID, run.TenantID))
+	defer cancel()
+	s.trackInFlight(run.ID, cancel)
+	defer s.untrackInFlight(run.ID)
+
 	// 2. Mark Running
 	now := time.Now()
 	run.Status = discovery.ScanStatusRunning
-	run.StartedAt = &now
-	run.Progress = 0
+	if run.StartedAt == nil {
+		run.StartedAt = &now
+	}
 	if err := s.scanRunRepo.Update(ctx, run); err != nil {
 		return fmt.Errorf("mark running: %w", err)
 	}
 
-	// 3. Execute Scan via DiscoveryService
-	// Note: DiscoveryService methods update Inventory/Entites but NOT ScanRun stats/progress yet.
-	// Ideally, DiscoveryService should accept a way to report progress or we wrap it.
-	// For this iteration, we call ScanDataSource synchronously here.
-	// IMPROVEMENT: Refactor DiscoveryService to take a ProgressCallback or ScanRunID.
-
-	// Since I cannot heavily refactor DiscoveryService right now without breaking changes,
-	// I will just execute it. Real stats tracking requires DiscoveryService to return stats.
-	// DiscoveryService.ScanDataSource currently returns nil or error.
-	// It logs connection info.
+	// 3. Execute Scan via DiscoveryService, persisting a checkpoint after
+	// every entity so a redelivered job can skip completed work.
+	opts := ScanOptions{
+		Checkpoint: &run.Checkpoint,
+		OnCheckpoint: func(_ context.Context, cp discovery.ScanCheckpoint) error {
+			return s.saveCheckpoint(ctx, run, cp, cancel)
+		},
+	}
+	scanStats, scanErr := s.discoverySvc.ScanDataSourceWithOptions(scanCtx, run.DataSourceID, opts)
 
-	// Constraint: "The existing DiscoveryService.ScanDataSource method should be called by the worker, not refactored"
-	// This makes progress tracking hard.
-	// BUT, I can update the Status to COMPLETED/FAILED afterwards.
+	// A cancel request wins over whatever the scan returned.
+	latest, latestErr := s.scanRunRepo.GetByID(ctx, run.ID)
+	if latestErr == nil && latest.Status == discovery.ScanStatusCancelled {
+		run.Status = discovery.ScanStatusCancelled
+		run.CompletedAt = latest.CompletedAt
+		if run.CompletedAt == nil {
+			cancelledAt := time.Now()
+			run.CompletedAt = &cancelledAt
+		}
+		run.Stats.Duration = run.CompletedAt.Sub(*run.StartedAt)
+		s.logger.Info("scan cancelled", slog.String("tenant_id", run.TenantID.String()),
+			slog.String("run_id", run.ID.String()),
+			slog.Int("completed_entities", len(run.Checkpoint.CompletedEntities)),
+		)
+		return s.scanRunRepo.Update(ctx, run)
+	}
 
-	scanStats, scanErr := s.discoverySvc.ScanDataSource(ctx, run.DataSourceID)
+	// Worker shutting down, or the run's status is unknown: leave the run
+	// RUNNING so redelivery resumes it rather than failing a cancelled scan.
+	if errors.Is(scanErr, context.Canceled) {
+		if ctx.Err() != nil {
+			s.logger.Warn("scan interrupted, will resume on redelivery", "run_id", run.ID)
+			return scanErr
+		}
+		if latestErr != nil {
+			return fmt.Errorf("check cancelled run: %w", latestErr)
+		}
+	}
 
 	completedAt := time.Now()
 	run.CompletedAt = &completedAt
@@ -145,6 +189,36 @@ func (s *ScanService) ProcessScanJob(ctx context.Context, jobID string) error {
 	if scanErr != nil {
 		run.Status = discovery.ScanStatusFailed
 		run.ErrorMessage = types.Ptr(scanErr.Error())
+	} else {
+		run.Status = discovery.ScanStatusCompleted
+		run.Progress = 100
+
+		// Use real stats returned by DiscoveryService
+		if scanStats != nil {
+			run.Stats = *scanStats
+			run.Stats.Duration = completedAt.Sub(*run.StartedAt)
+		}
+	}
+
+	// Only a RUNNING run is finished, so a cancel that lands after the check
+	// above still wins.
+	finished, err := s.scanRunRepo.Finish(ctx, run)
+	if err != nil {
+		s.logger.Error("failed to update run status", slog.String("tenant_id", run.TenantID.String()),
+			slog.String("run_id", run.ID.String()),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	if !finished {
+		s.logger.Info("scan cancelled", slog.String("tenant_id", run.TenantID.String()),
+			slog.String("run_id", run.ID.String()),
+			slog.Int("completed_entities", len(run.Checkpoint.CompletedEntities)),
+		)
+		return nil
+	}
+
+	if scanErr != nil {
 		s.logger.Error("scan failed", slog.String("tenant_id", run.TenantID.String()),
 			slog.String("run_id", run.ID.String()),
 			slog.String("error", scanErr.Error()),
@@ -157,15 +231,6 @@ func (s *ScanService) ProcessScanJob(ctx context.Context, jobID string) error {
 			_ = s.dsRepo.Update(ctx, ds)
 		}
 	} else {
-		run.Status = discovery.ScanStatusCompleted
-		run.Progress = 100
-
-		// Use real stats returned by DiscoveryService
-		if scanStats != nil {
-			run.Stats = *scanStats
-			run.Stats.Duration = completedAt.Sub(*run.StartedAt)
-		}
-
 		// Update data source: mark CONNECTED and set last_sync_at
 		if ds, err := s.dsRepo.GetByID(ctx, run.DataSourceID); err == nil {
 			ds.Status = discovery.ConnectionStatusConnected
@@ -176,17 +241,102 @@ func (s *ScanService) ProcessScanJob(ctx context.Context, jobID string) error {
 		}
 	}
 
-	if err := s.scanRunRepo.Update(ctx, run); err != nil {
-		s.logger.Error("failed to update run status", slog.String("tenant_id", run.TenantID.String()),
-			slog.String("run_id", run.ID.String()),
-			slog.String("error", err.Error()),
-		)
-		return err
+	return nil
+}
+
+// saveCheckpoint persists scan progress. It returns context.Canceled (and
+// cancels the running scan) if the run is no longer RUNNING, i.e. it was
+// cancelled from another process.
+func (s *ScanService) saveCheckpoint(ctx context.Context, run *discovery.ScanRun, cp discovery.ScanCheckpoint, cancel context.CancelFunc) error {
+	run.Checkpoint = cp
+	if cp.TotalEntities > 0 {
+		// Never report 100 until the run is actually marked COMPLETED.
+		run.Progress = min(len(cp.CompletedEntities)*100/cp.TotalEntities, 99)
 	}
+	run.Stats.EntitiesScanned = len(cp.CompletedEntities)
+	run.Stats.FieldsScanned = cp.FieldsScanned
+	run.Stats.PIIDetected = cp.PIIDetected
 
+	running, err := s.scanRunRepo.UpdateCheckpoint(ctx, run)
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	if !running {
+		cancel()
+		return context.Canceled
+	}
 	return nil
 }
 
+// CancelScan cancels the active scan of a data source. Scans running in this
+// process are stopped immediately; scans running on other workers stop at
+// their next checkpoint.
+func (s *ScanService) CancelScan(ctx context.Context, dataSourceID types.ID, tenantID types.ID) (*discovery.ScanRun, error) {
+	ds, err := s.dsRepo.GetByID(ctx, dataSourceID)
+	if err != nil {
+		return nil, err
+	}
+	if ds.TenantID != tenantID {
+		return nil, types.NewForbiddenError("data source does not belong to tenant")
+	}
+
+	runs, err := s.scanRunRepo.GetByDataSource(ctx, dataSourceID)
+	if err != nil {
+		return nil, fmt.Errorf("list scan runs: %w", err)
+	}
+
+	var run *discovery.ScanRun
+	for i := range runs {
+		if !runs[i].Status.IsTerminal() {
+			run = &runs[i]
+			break
+		}
+	}
+	if run == nil {
+		return nil, types.NewNotFoundError("active scan", dataSourceID)
+	}
+
+	// The run may finish between the list above and this write; only an
+	// active run is cancelled.
+	now := time.Now()
+	run.CompletedAt = &now
+	cancelled, err := s.scanRunRepo.Cancel(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("cancel scan run: %w", err)
+	}
+	if !cancelled {
+		return nil, types.NewNotFoundError("active scan", dataSourceID)
+	}
+	run.Status = discovery.ScanStatusCancelled
+	if run.StartedAt != nil {
+		run.Stats.Duration = now.Sub(*run.StartedAt)
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.inFlight[run.ID]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	s.logger.Info("scan cancel requested", slog.String("tenant_id", tenantID.String()),
+		slog.String("run_id", run.ID.String()),
+		slog.String("ds_id", dataSourceID.String()),
+	)
+	return run, nil
+}
+
+func (s *ScanService) trackInFlight(runID types.ID, cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight[runID] = cancel
+}
+
+func (s *ScanService) untrackInFlight(runID types.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, runID)
+}
+
 // StartWorker initiates the background worker.
 func (s *ScanService) StartWorker(ctx context.Context) error {
 	s.logger.Info("starting scan worker")
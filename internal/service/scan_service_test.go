@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -34,9 +35,20 @@ type MockDiscoveryOrchestrator struct {
 	mock.Mock
 }
 
-func (m *MockDiscoveryOrchestrator) ScanDataSource(ctx context.Context, dataSourceID types.ID) error {
+func (m *MockDiscoveryOrchestrator) ScanDataSource(ctx context.Context, dataSourceID types.ID) (*discovery.ScanStats, error) {
 	args := m.Called(ctx, dataSourceID)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.ScanStats), args.Error(1)
+}
+
+func (m *MockDiscoveryOrchestrator) ScanDataSourceWithOptions(ctx context.Context, dataSourceID types.ID, opts ScanOptions) (*discovery.ScanStats, error) {
+	args := m.Called(ctx, dataSourceID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.ScanStats), args.Error(1)
 }
 
 func (m *MockDiscoveryOrchestrator) TestConnection(ctx context.Context, dataSourceID types.ID) error {
@@ -52,6 +64,14 @@ func (m *MockDiscoveryOrchestrator) GetClassifications(ctx context.Context, tena
 	return args.Get(0).(*types.PaginatedResult[discovery.PIIClassification]), args.Error(1)
 }
 
+func (m *MockDiscoveryOrchestrator) MarkFieldReviewed(ctx context.Context, tenantID, fieldID types.ID) (*discovery.DataField, error) {
+	args := m.Called(ctx, tenantID, fieldID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.DataField), args.Error(1)
+}
+
 type MockScanRunRepo struct {
 	mock.Mock
 }
@@ -89,6 +109,21 @@ func (m *MockScanRunRepo) Update(ctx context.Context, run *discovery.ScanRun) er
 	return args.Error(0)
 }
 
+func (m *MockScanRunRepo) UpdateCheckpoint(ctx context.Context, run *discovery.ScanRun) (bool, error) {
+	args := m.Called(ctx, run)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockScanRunRepo) Finish(ctx context.Context, run *discovery.ScanRun) (bool, error) {
+	args := m.Called(ctx, run)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockScanRunRepo) Cancel(ctx context.Context, run *discovery.ScanRun) (bool, error) {
+	args := m.Called(ctx, run)
+	return args.Bool(0), args.Error(1)
+}
+
 type MockDataSourceRepo struct {
 	mock.Mock
 }
@@ -232,12 +267,12 @@ func TestScanService_ProcessScanJob_Success(t *testing.T) {
 	})).Return(nil)
 
 	// 3. Discovery Service Scan
-	discoverySvc.On("ScanDataSource", ctx, dsID).Return(nil)
+	discoverySvc.On("ScanDataSourceWithOptions", mock.Anything, dsID, mock.Anything).Return(&discovery.ScanStats{}, nil)
 
-	// 4. Update to Completed
-	scanRepo.On("Update", ctx, mock.MatchedBy(func(run *discovery.ScanRun) bool {
+	// 4. Finish as Completed
+	scanRepo.On("Finish", ctx, mock.MatchedBy(func(run *discovery.ScanRun) bool {
 		return run.ID == runID && run.Status == discovery.ScanStatusCompleted && run.Progress == 100
-	})).Return(nil)
+	})).Return(true, nil)
 
 	// Execute
 	err := svc.ProcessScanJob(ctx, runID.String())
@@ -275,12 +310,12 @@ func TestScanService_ProcessScanJob_Failure(t *testing.T) {
 
 	// 3. Discovery Service Scan FAILS
 	scanErr := errors.New("connection failed")
-	discoverySvc.On("ScanDataSource", ctx, dsID).Return(scanErr)
+	discoverySvc.On("ScanDataSourceWithOptions", mock.Anything, dsID, mock.Anything).Return(nil, scanErr)
 
-	// 4. Update to Failed
-	scanRepo.On("Update", ctx, mock.MatchedBy(func(run *discovery.ScanRun) bool {
+	// 4. Finish as Failed
+	scanRepo.On("Finish", ctx, mock.MatchedBy(func(run *discovery.ScanRun) bool {
 		return run.ID == runID && run.Status == discovery.ScanStatusFailed && *run.ErrorMessage == "connection failed"
-	})).Return(nil)
+	})).Return(true, nil)
 
 	// Execute
 	err := svc.ProcessScanJob(ctx, runID.String())
@@ -289,3 +324,261 @@ func TestScanService_ProcessScanJob_Failure(t *testing.T) {
 	require.NoError(t, err) // Worker should not error out (it handled the failure)
 	scanRepo.AssertExpectations(t)
 }
+
+func TestScanService_ProcessScanJob_ResumesFromCheckpoint(t *testing.T) {
+	// Setup
+	scanRepo := new(MockScanRunRepo)
+	dsRepo := newMockDataSourceRepo()
+	queue := new(MockScanQueue)
+	discoverySvc := new(MockDiscoveryOrchestrator)
+	svc := NewScanService(scanRepo, dsRepo, queue, discoverySvc, slog.Default())
+
+	ctx := context.Background()
+	runID := types.NewID()
+	dsID := types.NewID()
+	startedAt := time.Now().Add(-time.Minute)
+
+	// Run left RUNNING by a crashed worker, with one entity checkpointed
+	runningRun := &discovery.ScanRun{
+		BaseEntity:   types.BaseEntity{ID: runID},
+		DataSourceID: dsID,
+		Status:       discovery.ScanStatusRunning,
+		StartedAt:    &startedAt,
+		Checkpoint: discovery.ScanCheckpoint{
+			CompletedEntities: []string{"users"},
+			TotalEntities:     2,
+			PIIDetected:       3,
+		},
+	}
+	scanRepo.On("GetByID", ctx, runID).Return(runningRun, nil)
+	scanRepo.On("Update", ctx, mock.Anything).Return(nil)
+	scanRepo.On("Finish", ctx, mock.Anything).Return(true, nil)
+
+	discoverySvc.On("ScanDataSourceWithOptions", mock.Anything, dsID, mock.MatchedBy(func(opts ScanOptions) bool {
+		return opts.Checkpoint != nil && opts.Checkpoint.IsCompleted("users") && opts.OnCheckpoint != nil
+	})).Return(&discovery.ScanStats{EntitiesScanned: 2, PIIDetected: 5}, nil)
+
+	// Execute
+	err := svc.ProcessScanJob(ctx, runID.String())
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, discovery.ScanStatusCompleted, runningRun.Status)
+	assert.Equal(t, startedAt, *runningRun.StartedAt, "resume must keep original start time")
+	assert.Equal(t, 5, runningRun.Stats.PIIDetected)
+	discoverySvc.AssertExpectations(t)
+}
+
+func TestScanService_ProcessScanJob_CancelledAtCheckpoint(t *testing.T) {
+	// Setup
+	scanRepo := new(MockScanRunRepo)
+	dsRepo := newMockDataSourceRepo()
+	queue := new(MockScanQueue)
+	discoverySvc := new(MockDiscoveryOrchestrator)
+	svc := NewScanService(scanRepo, dsRepo, queue, discoverySvc, slog.Default())
+
+	ctx := context.Background()
+	runID := types.NewID()
+	dsID := types.NewID()
+
+	run := &discovery.ScanRun{
+		BaseEntity:   types.BaseEntity{ID: runID},
+		DataSourceID: dsID,
+		Status:       discovery.ScanStatusPending,
+	}
+	scanRepo.On("GetByID", ctx, runID).Return(run, nil)
+	scanRepo.On("Update", ctx, mock.Anything).Return(nil)
+	scanRepo.On("UpdateCheckpoint", ctx, mock.Anything).Return(false, nil)
+
+	// Discovery reports a checkpoint after the run was cancelled elsewhere
+	discoverySvc.On("ScanDataSourceWithOptions", mock.Anything, dsID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			scanCtx := args.Get(0).(context.Context)
+			opts := args.Get(2).(ScanOptions)
+			run.Status = discovery.ScanStatusCancelled
+			cpErr := opts.OnCheckpoint(scanCtx, discovery.ScanCheckpoint{CompletedEntities: []string{"users"}})
+			assert.ErrorIs(t, cpErr, context.Canceled)
+			assert.Error(t, scanCtx.Err(), "scan context should be cancelled")
+		}).
+		Return(nil, context.Canceled)
+
+	// Execute
+	err := svc.ProcessScanJob(ctx, runID.String())
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, discovery.ScanStatusCancelled, run.Status)
+	assert.NotNil(t, run.CompletedAt)
+	assert.Nil(t, run.ErrorMessage)
+}
+
+func TestScanService_ProcessScanJob_CancelledInProcess(t *testing.T) {
+	scanRepo := newMockScanRunRepo()
+	dsRepo := newMockDataSourceRepo()
+	discoverySvc := new(MockDiscoveryOrchestrator)
+	svc := NewScanService(scanRepo, dsRepo, new(MockScanQueue), discoverySvc, slog.Default())
+
+	ctx := context.Background()
+	tenantID := types.NewID()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{BaseEntity: types.BaseEntity{ID: types.NewID()}, TenantID: tenantID},
+		Status:       discovery.ConnectionStatusConnected,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+	run := &discovery.ScanRun{
+		BaseEntity:   types.BaseEntity{ID: types.NewID()},
+		DataSourceID: ds.ID,
+		TenantID:     tenantID,
+		Status:       discovery.ScanStatusPending,
+	}
+	require.NoError(t, scanRepo.Create(ctx, run))
+
+	// The user cancels between two checkpoints; the scan stops with
+	// context.Canceled while the worker itself keeps running.
+	discoverySvc.On("ScanDataSourceWithOptions", mock.Anything, ds.ID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			scanCtx := args.Get(0).(context.Context)
+			opts := args.Get(2).(ScanOptions)
+			require.NoError(t, opts.OnCheckpoint(scanCtx, discovery.ScanCheckpoint{CompletedEntities: []string{"users"}, TotalEntities: 2}))
+			_, err := svc.CancelScan(ctx, ds.ID, tenantID)
+			require.NoError(t, err)
+			assert.ErrorIs(t, opts.OnCheckpoint(scanCtx, discovery.ScanCheckpoint{CompletedEntities: []string{"users", "orders"}, TotalEntities: 2}),
+				context.Canceled, "a checkpoint must not overwrite the cancel")
+		}).
+		Return(nil, context.Canceled)
+
+	require.NoError(t, svc.ProcessScanJob(ctx, run.ID.String()))
+
+	got, err := scanRepo.GetByID(ctx, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, discovery.ScanStatusCancelled, got.Status)
+	assert.Nil(t, got.ErrorMessage)
+	stored, err := dsRepo.GetByID(ctx, ds.ID)
+	require.NoError(t, err)
+	assert.Equal(t, discovery.ConnectionStatusConnected, stored.Status, "a cancelled scan is not a data source error")
+}
+
+func TestScanService_ProcessScanJob_CancelledBeforeFinish(t *testing.T) {
+	scanRepo := new(MockScanRunRepo)
+	dsRepo := newMockDataSourceRepo()
+	discoverySvc := new(MockDiscoveryOrchestrator)
+	svc := NewScanService(scanRepo, dsRepo, new(MockScanQueue), discoverySvc, slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{BaseEntity: types.BaseEntity{ID: types.NewID()}, TenantID: types.NewID()},
+		Status:       discovery.ConnectionStatusError,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+	runID := types.NewID()
+	run := &discovery.ScanRun{
+		BaseEntity:   types.BaseEntity{ID: runID},
+		DataSourceID: ds.ID,
+		Status:       discovery.ScanStatusPending,
+	}
+	scanRepo.On("GetByID", ctx, runID).Return(run, nil)
+	scanRepo.On("Update", ctx, mock.Anything).Return(nil)
+	discoverySvc.On("ScanDataSourceWithOptions", mock.Anything, ds.ID, mock.Anything).Return(&discovery.ScanStats{}, nil)
+
+	// The run is still RUNNING at the cancel check, then cancelled before
+	// the outcome is written.
+	scanRepo.On("Finish", ctx, mock.Anything).Return(false, nil)
+
+	require.NoError(t, svc.ProcessScanJob(ctx, runID.String()))
+
+	scanRepo.AssertNumberOfCalls(t, "Update", 1)
+	stored, err := dsRepo.GetByID(ctx, ds.ID)
+	require.NoError(t, err)
+	assert.Equal(t, discovery.ConnectionStatusError, stored.Status, "a cancelled scan must not mark the source synced")
+	assert.Nil(t, stored.LastSyncAt)
+}
+
+func TestScanService_CancelScan(t *testing.T) {
+	// Setup
+	scanRepo := new(MockScanRunRepo)
+	dsRepo := new(MockDataSourceRepo)
+	queue := new(MockScanQueue)
+	discoverySvc := new(MockDiscoveryOrchestrator)
+	svc := NewScanService(scanRepo, dsRepo, queue, discoverySvc, slog.Default())
+
+	ctx := context.Background()
+	tenantID := types.NewID()
+	dsID := types.NewID()
+	runID := types.NewID()
+
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{
+			BaseEntity: types.BaseEntity{ID: dsID},
+			TenantID:   tenantID,
+		},
+	}
+	dsRepo.On("GetByID", ctx, dsID).Return(ds, nil)
+
+	runs := []discovery.ScanRun{
+		{BaseEntity: types.BaseEntity{ID: runID}, DataSourceID: dsID, Status: discovery.ScanStatusRunning},
+		{BaseEntity: types.BaseEntity{ID: types.NewID()}, DataSourceID: dsID, Status: discovery.ScanStatusCompleted},
+	}
+	scanRepo.On("GetByDataSource", ctx, dsID).Return(runs, nil)
+	scanRepo.On("Cancel", ctx, mock.MatchedBy(func(run *discovery.ScanRun) bool {
+		return run.ID == runID && run.CompletedAt != nil
+	})).Return(true, nil)
+
+	// Simulate an in-flight scan in this process
+	scanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	svc.trackInFlight(runID, cancel)
+
+	// Execute
+	run, err := svc.CancelScan(ctx, dsID, tenantID)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, runID, run.ID)
+	assert.Equal(t, discovery.ScanStatusCancelled, run.Status)
+	assert.Error(t, scanCtx.Err(), "in-flight scan should be cancelled")
+	scanRepo.AssertExpectations(t)
+}
+
+func TestScanService_CancelScan_AlreadyFinished(t *testing.T) {
+	scanRepo := new(MockScanRunRepo)
+	dsRepo := new(MockDataSourceRepo)
+	svc := NewScanService(scanRepo, dsRepo, new(MockScanQueue), new(MockDiscoveryOrchestrator), slog.Default())
+
+	ctx := context.Background()
+	tenantID := types.NewID()
+	dsID := types.NewID()
+
+	dsRepo.On("GetByID", ctx, dsID).Return(&discovery.DataSource{
+		TenantEntity: types.TenantEntity{BaseEntity: types.BaseEntity{ID: dsID}, TenantID: tenantID},
+	}, nil)
+	scanRepo.On("GetByDataSource", ctx, dsID).Return([]discovery.ScanRun{
+		{BaseEntity: types.BaseEntity{ID: types.NewID()}, Status: discovery.ScanStatusRunning},
+	}, nil)
+	// The scan completes after the list was read
+	scanRepo.On("Cancel", ctx, mock.Anything).Return(false, nil)
+
+	_, err := svc.CancelScan(ctx, dsID, tenantID)
+	require.Error(t, err)
+	assert.True(t, types.IsNotFoundError(err))
+}
+
+func TestScanService_CancelScan_NoActiveScan(t *testing.T) {
+	scanRepo := new(MockScanRunRepo)
+	dsRepo := new(MockDataSourceRepo)
+	svc := NewScanService(scanRepo, dsRepo, new(MockScanQueue), new(MockDiscoveryOrchestrator), slog.Default())
+
+	ctx := context.Background()
+	tenantID := types.NewID()
+	dsID := types.NewID()
+
+	dsRepo.On("GetByID", ctx, dsID).Return(&discovery.DataSource{
+		TenantEntity: types.TenantEntity{BaseEntity: types.BaseEntity{ID: dsID}, TenantID: tenantID},
+	}, nil)
+	scanRepo.On("GetByDataSource", ctx, dsID).Return([]discovery.ScanRun{
+		{BaseEntity: types.BaseEntity{ID: types.NewID()}, Status: discovery.ScanStatusCompleted},
+	}, nil)
+
+	_, err := svc.CancelScan(ctx, dsID, tenantID)
+	require.Error(t, err)
+	assert.True(t, types.IsNotFoundError(err))
+}
@@ -107,7 +107,7 @@ func TestScanning_FileUpload_Flow(t *testing.T) {
 	registry := connector.NewConnectorRegistry(&config.Config{}, detector, parsingSvc)
 
 	// Services
-	discoverySvc := NewDiscoveryService(dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, scanRunRepo, registry, detector, eb, logger)
+	discoverySvc := NewDiscoveryService(dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, scanRunRepo, registry, detector, nil, eb, logger)
 
 	// Mock Queue (execute immediately)
 	mockQueue := &FileUploadMockScanQueue{
@@ -76,11 +76,11 @@ func TestIntegration_Postgres_Scanning_Flow(t *testing.T) {
 	})
 
 	// 6. Service
-	svc := NewDiscoveryService(dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, scanRunRepo, registry, detector, eb, slog.Default())
+	svc := NewDiscoveryService(dsRepo, invRepo, entityRepo, fieldRepo, piiRepo, scanRunRepo, registry, detector, nil, eb, slog.Default())
 
 	// 7. Execute Scan
 	t.Log("Starting ScanDataSource...")
-	err := svc.ScanDataSource(ctx, ds.ID)
+	_, err := svc.ScanDataSource(ctx, ds.ID)
 	require.NoError(t, err)
 
 	// 8. Verify
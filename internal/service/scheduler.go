@@ -21,12 +21,18 @@ type SchedulerService struct {
 	scanService        ScanOrchestrator
 	expirySvc          *ConsentExpiryService
 	retentionRepo      compliance.RetentionPolicyRepository
+	calibrationSvc     *CalibrationService
+	dpiaSvc            *DPIAService
+	redactionSvc       *RedactionService
 	logger             *slog.Logger
 	parser             cron.Parser
 	ticker             *time.Ticker
 	stopChan           chan struct{}
 	lastPolicyEval     time.Time
 	lastRetentionCheck time.Time
+	lastCalibration    time.Time
+	lastDPIAReview     time.Time
+	lastTokenPurge     time.Time
 }
 
 // NewSchedulerService creates a new SchedulerService.
@@ -65,6 +71,9 @@ func (s *SchedulerService) Start(ctx context.Context) error {
 				s.schedulePolicyEvaluations(ctx)
 				s.checkConsentExpiries(ctx)
 				s.checkRetentionPolicies(ctx)
+				s.runDetectionCalibration(ctx)
+				s.runDPIAReviews(ctx)
+				s.runRedactionTokenPurge(ctx)
 			case <-s.stopChan:
 				s.logger.Info("Stopping scan scheduler")
 				return
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// SetCalibrationService enables the daily detection calibration job.
+func (s *SchedulerService) SetCalibrationService(svc *CalibrationService) {
+	s.calibrationSvc = svc
+}
+
+// runDetectionCalibration relearns every tenant's detection calibration
+// from accumulated feedback. Runs once per 24 hours; results apply from each
+// tenant's next scan.
+func (s *SchedulerService) runDetectionCalibration(ctx context.Context) {
+	if s.calibrationSvc == nil {
+		return
+	}
+	// Throttle: run once per day
+	if time.Since(s.lastCalibration) < 24*time.Hour && !s.lastCalibration.IsZero() {
+		return
+	}
+	s.lastCalibration = time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	calibrated, err := s.calibrationSvc.CalibrateAll(ctx)
+	if err != nil {
+		s.logger.Error("Scheduled detection calibration failed", "error", err)
+		return
+	}
+	s.logger.Info("Daily detection calibration complete", "tenants_calibrated", calibrated)
+}
@@ -98,6 +98,14 @@ func (m *LocalMockScanOrchestrator) GetHistory(ctx context.Context, dataSourceID
 	return args.Get(0).([]discovery.ScanRun), args.Error(1)
 }
 
+func (m *LocalMockScanOrchestrator) CancelScan(ctx context.Context, dataSourceID types.ID, tenantID types.ID) (*discovery.ScanRun, error) {
+	args := m.Called(ctx, dataSourceID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.ScanRun), args.Error(1)
+}
+
 func TestCheckSchedules_TriggersScan(t *testing.T) {
 	// Setup
 	tenantRepo := new(LocalMockTenantRepo)
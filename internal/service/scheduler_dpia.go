@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// SetDPIAService enables the daily DPIA periodic review job.
+func (s *SchedulerService) SetDPIAService(svc *DPIAService) {
+	s.dpiaSvc = svc
+}
+
+// runDPIAReviews flags approved DPIAs whose periodic review has fallen due.
+// Runs once per 24 hours.
+func (s *SchedulerService) runDPIAReviews(ctx context.Context) {
+	if s.dpiaSvc == nil {
+		return
+	}
+	// Throttle: run once per day
+	if time.Since(s.lastDPIAReview) < 24*time.Hour && !s.lastDPIAReview.IsZero() {
+		return
+	}
+	s.lastDPIAReview = time.Now()
+
+	flagged, err := s.dpiaSvc.MarkReviewsDue(ctx)
+	if err != nil {
+		s.logger.Error("Scheduled DPIA review check failed", "error", err)
+		return
+	}
+	if flagged > 0 {
+		s.logger.Info("DPIA periodic reviews due", "dpias", flagged)
+	}
+}
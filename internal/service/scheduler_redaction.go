@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// SetRedactionService enables the daily purge of expired redaction tokens.
+func (s *SchedulerService) SetRedactionService(svc *RedactionService) {
+	s.redactionSvc = svc
+}
+
+// runRedactionTokenPurge deletes vault tokens past their retention.
+// Runs once per 24 hours.
+func (s *SchedulerService) runRedactionTokenPurge(ctx context.Context) {
+	if s.redactionSvc == nil {
+		return
+	}
+	// Throttle: run once per day
+	if time.Since(s.lastTokenPurge) < 24*time.Hour && !s.lastTokenPurge.IsZero() {
+		return
+	}
+	s.lastTokenPurge = time.Now()
+
+	purged, err := s.redactionSvc.PurgeExpired(ctx)
+	if err != nil {
+		s.logger.Error("Scheduled redaction token purge failed", "error", err)
+		return
+	}
+	if purged > 0 {
+		s.logger.Info("Expired redaction tokens purged", "tokens", purged)
+	}
+}
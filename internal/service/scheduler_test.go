@@ -41,6 +41,14 @@ func (m *MockScanOrchestrator) GetHistory(ctx context.Context, dataSourceID type
 	return args.Get(0).([]discovery.ScanRun), args.Error(1)
 }
 
+func (m *MockScanOrchestrator) CancelScan(ctx context.Context, dataSourceID types.ID, tenantID types.ID) (*discovery.ScanRun, error) {
+	args := m.Called(ctx, dataSourceID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*discovery.ScanRun), args.Error(1)
+}
+
 // =============================================================================
 // Tests
 // =============================================================================
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/infrastructure/connector"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+const (
+	// subjectGraphParentSample and subjectGraphChildSample are the values
+	// sampled per column to infer links by value overlap. Parents get the
+	// larger sample since child values are looked up among them.
+	subjectGraphParentSample = 1000
+	subjectGraphChildSample  = 100
+
+	// classificationPageSize is the page size classifications are loaded in.
+	classificationPageSize = 500
+)
+
+// entityField identifies a field within an entity; field names may contain
+// dots, so the pair is kept apart.
+type entityField struct {
+	entity string
+	field  string
+}
+
+// buildSubjectGraph rebuilds the inventory's subject graph from the stored
+// entities, fields and classifications, so entities skipped by a resumed or
+// incremental scan are still included. Roots are fields classified as
+// subject identifiers; links come from declared foreign keys where the
+// connector reports them and from value overlap between key-like columns.
+// Failures leave the previous graph in place and never fail the scan.
+func (s *DiscoveryService) buildSubjectGraph(
+	ctx context.Context,
+	conn discovery.Connector,
+	ds *discovery.DataSource,
+	inventory *discovery.DataInventory,
+) {
+	entities, err := s.entityRepo.GetByInventory(ctx, inventory.ID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to load entities for subject graph", "data_source_id", ds.ID, "error", err)
+		return
+	}
+	classifications, err := loadClassifications(ctx, s.piiRepo, ds.ID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to load classifications for subject graph", "data_source_id", ds.ID, "error", err)
+		return
+	}
+
+	piiTypes := make(map[entityField]types.PIIType)
+	var roots []discovery.SubjectRoot
+	for _, pii := range classifications {
+		key := entityField{pii.EntityName, pii.FieldName}
+		if _, seen := piiTypes[key]; seen {
+			continue
+		}
+		piiTypes[key] = pii.Type
+		if discovery.SubjectIdentifierTypes[pii.Type] {
+			roots = append(roots, discovery.SubjectRoot{Entity: pii.EntityName, Field: pii.FieldName, PIIType: pii.Type})
+		}
+	}
+	if len(roots) == 0 {
+		inventory.SubjectGraph = nil
+		return
+	}
+
+	var links []discovery.SubjectLink
+	declared := make(map[entityField]bool)
+	primaryKeys := make(map[string][]string)
+	if kd, ok := connector.Unwrap(conn).(discovery.KeyDiscoverer); ok {
+		keys, err := kd.DiscoverKeys(ctx)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to discover keys", "data_source_id", ds.ID, "error", err)
+		} else {
+			for _, fk := range keys.ForeignKeys {
+				links = append(links, fk.Link())
+				declared[entityField{fk.Entity, fk.Field}] = true
+			}
+			primaryKeys = keys.PrimaryKeys
+		}
+	}
+
+	// Parents are keys and identifiers a child column may hold; children
+	// are reference-like columns not already covered by a declared key.
+	var parents, children []discovery.KeyColumn
+	for _, entity := range entities {
+		fields, err := s.fieldRepo.GetByEntity(ctx, entity.ID)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to load fields for subject graph", "entity", entity.Name, "error", err)
+			continue
+		}
+		pk := make(map[string]bool)
+		for _, name := range primaryKeys[entity.Name] {
+			pk[name] = true
+		}
+		for _, f := range fields {
+			key := entityField{entity.Name, f.Name}
+			col := discovery.KeyColumn{Entity: entity.Name, Field: f.Name, PIIType: piiTypes[key]}
+			isKey := pk[f.Name] || f.IsPrimaryKey || strings.EqualFold(f.Name, "id") || f.Name == "_id"
+			isIdentifier := discovery.SubjectIdentifierTypes[col.PIIType]
+
+			if isKey || isIdentifier {
+				parents = append(parents, col)
+			}
+			if declared[key] {
+				continue
+			}
+			if (!isKey && discovery.IsKeyLikeField(f.Name)) || isIdentifier {
+				children = append(children, col)
+			}
+		}
+	}
+
+	type sampleKey struct {
+		entityField
+		limit int
+	}
+	samples := make(map[sampleKey][]string)
+	sample := func(c discovery.KeyColumn, limit int) []string {
+		key := sampleKey{entityField{c.Entity, c.Field}, limit}
+		if values, ok := samples[key]; ok {
+			return values
+		}
+		values, err := conn.SampleData(ctx, c.Entity, c.Field, limit)
+		if err != nil {
+			s.logger.WarnContext(ctx, "failed to sample key column", "entity", c.Entity, "field", c.Field, "error", err)
+		}
+		samples[key] = values
+		return values
+	}
+
+	for _, cand := range discovery.FindLinkCandidates(parents, children) {
+		if ctx.Err() != nil {
+			return
+		}
+		parentValues := sample(cand.Parent, subjectGraphParentSample)
+		childValues := sample(cand.Child, subjectGraphChildSample)
+		if link, ok := discovery.InferOverlapLink(cand, parentValues, childValues); ok {
+			links = append(links, link)
+		}
+	}
+
+	inventory.SubjectGraph = discovery.BuildSubjectGraph(roots, links)
+	s.logger.InfoContext(ctx, "subject graph built",
+		"data_source_id", ds.ID,
+		"roots", len(inventory.SubjectGraph.Roots),
+		"links", len(inventory.SubjectGraph.Links))
+}
+
+// loadClassifications pages through all of a data source's classifications.
+func loadClassifications(ctx context.Context, repo discovery.PIIClassificationRepository, dataSourceID types.ID) ([]discovery.PIIClassification, error) {
+	var all []discovery.PIIClassification
+	for page := 1; ; page++ {
+		result, err := repo.GetByDataSource(ctx, dataSourceID, types.Pagination{Page: page, PageSize: classificationPageSize})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, result.Items...)
+		if len(result.Items) < classificationPageSize || len(all) >= result.Total {
+			return all, nil
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/config"
+	"github.com/complyark/datalens/internal/domain/compliance"
+	"github.com/complyark/datalens/internal/domain/discovery"
+	"github.com/complyark/datalens/internal/infrastructure/connector"
+	"github.com/complyark/datalens/internal/service/detection"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+func TestDiscoveryService_Scan_BuildsSubjectGraph(t *testing.T) {
+	dsRepo := newMockDataSourceRepo()
+	invRepo := newMockDataInventoryRepo()
+	piiRepo := newMockPIIClassificationRepo()
+	conn := new(MockKeyDiscoverer)
+
+	strategy := new(MockStrategy)
+	strategy.On("Detect", mock.Anything, mock.Anything).Return(nil, nil)
+	detector := detection.NewComposableDetector(strategy)
+
+	registry := connector.NewConnectorRegistry(&config.Config{}, detector, nil)
+	dsType := types.DataSourceType("TEST_KEYS")
+	registry.Register(dsType, func() discovery.Connector { return conn })
+
+	svc := NewDiscoveryService(dsRepo, invRepo, newMockDataEntityRepo(), newMockDataFieldRepo(), piiRepo, newMockScanRunRepo(), registry, detector, nil, newMockEventBus(), slog.Default())
+
+	ctx := context.Background()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{BaseEntity: types.BaseEntity{ID: types.NewID()}, TenantID: types.NewID()},
+		Name:         "Shop",
+		Type:         dsType,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+	// Classified by an earlier scan; only users holds an identifier.
+	require.NoError(t, piiRepo.Create(ctx, &discovery.PIIClassification{
+		BaseEntity:   types.BaseEntity{ID: types.NewID()},
+		DataSourceID: ds.ID,
+		EntityName:   "users",
+		FieldName:    "email",
+		Type:         types.PIITypeEmail,
+	}))
+
+	conn.On("Connect", ctx, mock.Anything).Return(nil)
+	conn.On("Close").Return(nil)
+	conn.On("DiscoverSchema", ctx, mock.Anything).Return(
+		&discovery.DataInventory{DataSourceID: ds.ID},
+		[]discovery.DataEntity{{Name: "users"}, {Name: "orders"}, {Name: "order_items"}, {Name: "products"}},
+		nil,
+	)
+	conn.On("GetFields", ctx, "users").Return([]discovery.DataField{{Name: "id"}, {Name: "email"}}, nil)
+	conn.On("GetFields", ctx, "orders").Return([]discovery.DataField{{Name: "id"}, {Name: "user_id"}}, nil)
+	conn.On("GetFields", ctx, "order_items").Return([]discovery.DataField{{Name: "id"}, {Name: "order_id"}, {Name: "product_id"}}, nil)
+	conn.On("GetFields", ctx, "products").Return([]discovery.DataField{{Name: "id"}, {Name: "title"}}, nil)
+	conn.On("SampleData", ctx, mock.Anything, mock.Anything, 10).Return([]string{}, nil)
+
+	// orders.user_id is declared; order_items.order_id is not and is
+	// inferred from its values.
+	conn.On("DiscoverKeys", ctx).Return(&discovery.SchemaKeys{
+		PrimaryKeys: map[string][]string{"users": {"id"}, "orders": {"id"}, "order_items": {"id"}, "products": {"id"}},
+		ForeignKeys: []discovery.ForeignKey{
+			{Entity: "orders", Field: "user_id", RefEntity: "users", RefField: "id"},
+			{Entity: "order_items", Field: "product_id", RefEntity: "products", RefField: "id"},
+		},
+	}, nil)
+	conn.On("SampleData", ctx, "orders", "id", subjectGraphParentSample).Return([]string{"70", "71", "72", "73"}, nil)
+	conn.On("SampleData", ctx, "order_items", "order_id", subjectGraphChildSample).Return([]string{"70", "70", "71", "72"}, nil)
+
+	_, err := svc.ScanDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+
+	inv, err := invRepo.GetByDataSource(ctx, ds.ID)
+	require.NoError(t, err)
+	graph := inv.SubjectGraph
+	require.NotNil(t, graph)
+	assert.Equal(t, []discovery.SubjectRoot{{Entity: "users", Field: "email", PIIType: types.PIITypeEmail}}, graph.Roots)
+
+	require.Len(t, graph.Links, 2, "products is not reachable from users")
+	assert.Equal(t, "orders.id → order_items.order_id", graph.Links[0].String())
+	assert.Equal(t, discovery.SubjectLinkValueOverlap, graph.Links[0].Source)
+	assert.Equal(t, "users.id → orders.user_id", graph.Links[1].String())
+	assert.Equal(t, discovery.SubjectLinkForeignKey, graph.Links[1].Source)
+}
+
+func TestLoadClassifications_PagesThroughAll(t *testing.T) {
+	piiRepo := newMockPIIClassificationRepo()
+
+	ctx := context.Background()
+	dsID := types.NewID()
+	for i := 0; i < 2*classificationPageSize+1; i++ {
+		require.NoError(t, piiRepo.Create(ctx, &discovery.PIIClassification{DataSourceID: dsID, FieldName: fmt.Sprintf("f%d", i)}))
+	}
+
+	all, err := loadClassifications(ctx, piiRepo, dsID)
+	require.NoError(t, err)
+	assert.Len(t, all, 2*classificationPageSize+1)
+}
+
+func TestExecuteDSR_Erasure_FollowsSubjectGraph(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	dsrRepo := newMockDSRRepository()
+	dsRepo := newMockDataSourceRepo()
+	piiRepo := newMockPIIClassificationRepo()
+	invRepo := newMockDataInventoryRepo()
+
+	mockConn := new(MockConnector)
+	registry := connector.NewConnectorRegistry(&config.Config{}, detection.NewDefaultDetector(nil), nil)
+	registry.Register(types.DataSourcePostgreSQL, func() discovery.Connector { return mockConn })
+	executor := NewDSRExecutor(dsrRepo, dsRepo, piiRepo, registry, newMockEventBus(), logger)
+	executor.SetInventoryRepo(invRepo)
+	executor.startVerification = func(types.ID) {}
+	ctx := context.Background()
+
+	tenantID := types.NewID()
+	ds := &discovery.DataSource{
+		TenantEntity: types.TenantEntity{BaseEntity: types.BaseEntity{ID: types.NewID()}, TenantID: tenantID},
+		Name:         "Shop",
+		Type:         types.DataSourcePostgreSQL,
+	}
+	require.NoError(t, dsRepo.Create(ctx, ds))
+	require.NoError(t, piiRepo.Create(ctx, &discovery.PIIClassification{
+		BaseEntity:   types.BaseEntity{ID: types.NewID()},
+		DataSourceID: ds.ID,
+		EntityName:   "users",
+		FieldName:    "email",
+	}))
+	require.NoError(t, invRepo.Create(ctx, &discovery.DataInventory{
+		DataSourceID: ds.ID,
+		SubjectGraph: discovery.BuildSubjectGraph(
+			[]discovery.SubjectRoot{{Entity: "users", Field: "email", PIIType: types.PIITypeEmail}},
+			[]discovery.SubjectLink{
+				discovery.ForeignKey{Entity: "orders", Field: "user_id", RefEntity: "users", RefField: "id"}.Link(),
+				discovery.ForeignKey{Entity: "order_items", Field: "order_id", RefEntity: "orders", RefField: "id"}.Link(),
+				{ParentEntity: "users", ParentField: "id", ChildEntity: "reviews", ChildField: "author_id", Source: discovery.SubjectLinkValueOverlap, Confidence: 0.8},
+			},
+		),
+	}))
+
+	dsr := &compliance.DSR{
+		ID:                 types.NewID(),
+		TenantID:           tenantID,
+		RequestType:        compliance.RequestTypeErasure,
+		Status:             compliance.DSRStatusApproved,
+		SubjectIdentifiers: map[string]string{"email": "john@example.com"},
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+	require.NoError(t, dsrRepo.Create(ctx, dsr))
+	require.NoError(t, dsrRepo.CreateTask(ctx, &compliance.DSRTask{
+		ID:           types.NewID(),
+		DSRID:        dsr.ID,
+		DataSourceID: ds.ID,
+		TenantID:     tenantID,
+		TaskType:     compliance.RequestTypeErasure,
+		Status:       compliance.TaskStatusPending,
+		CreatedAt:    time.Now(),
+	}))
+
+	users := map[string]string{"email": "john@example.com"}
+	mockConn.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	mockConn.On("Close").Return(nil)
+	mockConn.On("Export", mock.Anything, "users", users).Return([]map[string]interface{}{{"id": int64(7), "email": "john@example.com"}}, nil)
+	mockConn.On("Export", mock.Anything, "orders", map[string]string{"user_id": "7"}).Return([]map[string]interface{}{{"id": int64(70)}, {"id": int64(71)}}, nil)
+	mockConn.On("Export", mock.Anything, "order_items", map[string]string{"order_id": "70"}).Return([]map[string]interface{}{{"id": int64(1)}}, nil)
+	mockConn.On("Export", mock.Anything, "order_items", map[string]string{"order_id": "71"}).Return([]map[string]interface{}{}, nil)
+	mockConn.On("Export", mock.Anything, "reviews", map[string]string{"author_id": "7"}).Return([]map[string]interface{}{{"id": int64(5)}}, nil)
+	mockConn.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(1, nil)
+
+	require.NoError(t, executor.ExecuteDSR(ctx, dsr.ID))
+
+	tasks, err := dsrRepo.GetTasksByDSR(ctx, dsr.ID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	result, ok := tasks[0].Result.(map[string]interface{})
+	require.True(t, ok)
+	deletions, ok := result["deletions"].([]map[string]interface{})
+	require.True(t, ok)
+
+	// The inferred reviews link is reported, not erased through.
+	require.NotEmpty(t, deletions)
+	assert.Equal(t, "reviews", deletions[0]["entity"])
+	assert.Equal(t, "NEEDS_REVIEW", deletions[0]["status"])
+	mockConn.AssertNotCalled(t, "Delete", mock.Anything, "reviews", mock.Anything)
+	deletions = deletions[1:]
+
+	// Children are deleted before the rows they reference; order_id 71 has
+	// no items, so it is not a target.
+	var order []string
+	for _, d := range deletions {
+		order = append(order, d["entity"].(string))
+	}
+	assert.Equal(t, []string{"order_items", "orders", "users"}, order)
+	assert.Equal(t, map[string]string{"order_id": "70"}, deletions[0]["filters"])
+	assert.Equal(t, "orders.id → order_items.order_id", deletions[0]["via"])
+	assert.Equal(t, "users.id → orders.user_id", deletions[1]["via"])
+	assert.NotContains(t, deletions[2], "via")
+	assert.Equal(t, int64(3), result["total_deleted"])
+}
+
+func TestRecordValue(t *testing.T) {
+	record := map[string]interface{}{
+		"id":      int64(42),
+		"profile": map[string]interface{}{"user_id": "u-9"},
+		"note":    nil,
+	}
+
+	v, ok := recordValue(record, "id")
+	assert.True(t, ok)
+	assert.Equal(t, "42", v)
+
+	v, ok = recordValue(record, "profile.user_id")
+	assert.True(t, ok)
+	assert.Equal(t, "u-9", v)
+
+	_, ok = recordValue(record, "note")
+	assert.False(t, ok)
+	_, ok = recordValue(record, "id.sub")
+	assert.False(t, ok)
+}
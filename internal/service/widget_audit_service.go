@@ -0,0 +1,415 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/complyark/datalens/internal/domain/consent"
+	"github.com/complyark/datalens/internal/service/analytics"
+	"github.com/complyark/datalens/pkg/eventbus"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// Widget audit events.
+const (
+	EventWidgetActivationBlocked = "consent.widget_activation_blocked"
+	EventWidgetAuditOverridden   = "consent.widget_audit_overridden"
+)
+
+// aiHighSeverityConfidence is the confidence from which an AI finding is
+// HIGH, and so blocks activation. Less confident findings are MEDIUM.
+const aiHighSeverityConfidence = 0.8
+
+// Theme colours the SDK falls back to, and the WCAG AA contrast minimum for
+// button text.
+const (
+	sdkDefaultPrimaryColor    = "#6C5CE7"
+	sdkDefaultBackgroundColor = "#ffffff"
+	sdkDefaultTextColor       = "#1a1a2e"
+	minButtonContrast         = 4.5
+)
+
+// DarkPatternAnalyzer is the AI half of a widget audit, implemented by
+// analytics.DarkPatternService.
+type DarkPatternAnalyzer interface {
+	AnalyzeContent(ctx context.Context, contentType string, content string) (*analytics.DarkPatternAnalysisResult, error)
+}
+
+// WidgetAuditService evaluates consent widgets against the 2023 Dark
+// Patterns Guidelines on every activation and config version bump,
+// combining deterministic checks of the config with an AI review of its
+// text. HIGH findings block activation unless overridden with a reason.
+type WidgetAuditService struct {
+	repo     consent.WidgetAuditRepository
+	analyzer DarkPatternAnalyzer
+	eventBus eventbus.EventBus
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewWidgetAuditService creates a new WidgetAuditService. A nil analyzer
+// audits with the deterministic checks only.
+func NewWidgetAuditService(repo consent.WidgetAuditRepository, analyzer DarkPatternAnalyzer, eventBus eventbus.EventBus, logger *slog.Logger) *WidgetAuditService {
+	return &WidgetAuditService{
+		repo:     repo,
+		analyzer: analyzer,
+		eventBus: eventBus,
+		logger:   logger.With("service", "widget_audit"),
+		now:      time.Now,
+	}
+}
+
+// Audit evaluates the widget's current config and stores the result. An
+// activation reuses the latest audit of the same config version if its AI
+// check completed. With enforce set, HIGH findings return a validation
+// error unless overrideReason is given, which is recorded on the audit.
+//
+// A non-nil save stores the audited version of the widget. It runs once the
+// audit passes, and the audit is only stored if it succeeds, so a blocked
+// or failed save leaves no audit of a version that was never stored.
+func (s *WidgetAuditService) Audit(ctx context.Context, w *consent.ConsentWidget, trigger consent.WidgetAuditTrigger, overrideReason string, enforce bool, save func() error) (*consent.WidgetAudit, error) {
+	audit, err := s.reusable(ctx, w, trigger)
+	if err != nil {
+		return nil, err
+	}
+	if audit == nil {
+		audit = s.evaluate(ctx, w, trigger)
+	}
+
+	var override *consent.WidgetAuditOverride
+	if enforce && audit.Blocks() {
+		overrideReason = strings.TrimSpace(overrideReason)
+		if overrideReason == "" {
+			return audit, s.blocked(ctx, w, audit, save == nil)
+		}
+		override = &consent.WidgetAuditOverride{Reason: overrideReason, OverriddenAt: s.now().UTC()}
+		if userID, ok := types.UserIDFromContext(ctx); ok {
+			override.OverriddenBy = &userID
+		}
+	}
+
+	if save != nil {
+		if err := save(); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.store(ctx, audit, override); err != nil {
+		return nil, err
+	}
+	if override == nil {
+		return audit, nil
+	}
+
+	s.logger.Warn("dark pattern findings overridden",
+		slog.String("widget_id", w.ID.String()),
+		slog.String("audit_id", audit.ID.String()),
+		slog.Int("high_findings", len(audit.HighFindings())),
+	)
+	s.publishEvent(ctx, EventWidgetAuditOverridden, w.TenantID, map[string]any{
+		"widget_id": w.ID,
+		"audit_id":  audit.ID,
+		"reason":    overrideReason,
+	})
+	return audit, nil
+}
+
+// ListAudits returns a widget's audits, newest first.
+func (s *WidgetAuditService) ListAudits(ctx context.Context, widgetID types.ID) ([]consent.WidgetAudit, error) {
+	return s.repo.ListByWidget(ctx, widgetID)
+}
+
+// reusable returns the latest audit of the widget if an activation can
+// reuse it, nil otherwise.
+func (s *WidgetAuditService) reusable(ctx context.Context, w *consent.ConsentWidget, trigger consent.WidgetAuditTrigger) (*consent.WidgetAudit, error) {
+	if trigger != consent.WidgetAuditActivation {
+		return nil, nil
+	}
+	latest, err := s.repo.GetLatest(ctx, w.ID)
+	if err != nil {
+		if types.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get latest widget audit: %w", err)
+	}
+	if latest.WidgetVersion != w.Version || latest.AIError != "" {
+		return nil, nil
+	}
+	return latest, nil
+}
+
+// blocked reports a blocking audit, storing it first if stored is set, and
+// returns the validation error that blocks the widget.
+func (s *WidgetAuditService) blocked(ctx context.Context, w *consent.ConsentWidget, audit *consent.WidgetAudit, stored bool) error {
+	details := map[string]any{"findings": audit.HighFindings()}
+	data := map[string]any{"widget_id": w.ID, "version": w.Version}
+	if stored {
+		if err := s.store(ctx, audit, nil); err != nil {
+			return err
+		}
+		details["audit_id"] = audit.ID
+		data["audit_id"] = audit.ID
+	}
+	s.publishEvent(ctx, EventWidgetActivationBlocked, w.TenantID, data)
+	return types.NewValidationError("consent widget has high-severity dark patterns; fix them or give an override_reason", details)
+}
+
+// store creates a new audit, or records override on a reused one.
+func (s *WidgetAuditService) store(ctx context.Context, audit *consent.WidgetAudit, override *consent.WidgetAuditOverride) error {
+	if audit.ID == (types.ID{}) {
+		audit.Override = override
+		if err := s.repo.Create(ctx, audit); err != nil {
+			return fmt.Errorf("create widget audit: %w", err)
+		}
+		return nil
+	}
+	if override == nil {
+		return nil
+	}
+	if err := s.repo.SetOverride(ctx, audit.ID, override); err != nil {
+		return fmt.Errorf("override widget audit: %w", err)
+	}
+	audit.Override = override
+	return nil
+}
+
+// evaluate runs the deterministic checks and the AI review. A failed AI
+// review is recorded on the audit rather than failing it.
+func (s *WidgetAuditService) evaluate(ctx context.Context, w *consent.ConsentWidget, trigger consent.WidgetAuditTrigger) *consent.WidgetAudit {
+	audit := &consent.WidgetAudit{
+		TenantEntity:  types.TenantEntity{TenantID: w.TenantID},
+		WidgetID:      w.ID,
+		WidgetVersion: w.Version,
+		Trigger:       trigger,
+		Findings:      widgetRuleFindings(w),
+	}
+
+	content := widgetAuditContent(w)
+	if s.analyzer == nil || content == "" {
+		return audit
+	}
+	result, err := s.analyzer.AnalyzeContent(ctx, "CONSENT_WIDGET", content)
+	if err != nil {
+		s.logger.Warn("AI dark pattern check failed",
+			slog.String("widget_id", w.ID.String()),
+			slog.String("error", err.Error()),
+		)
+		audit.AIError = err.Error()
+		return audit
+	}
+	audit.AIPromptVersion = result.PromptVersion
+	audit.Findings = append(audit.Findings, aiFindings(result)...)
+	return audit
+}
+
+func (s *WidgetAuditService) publishEvent(ctx context.Context, eventType string, tenantID types.ID, data any) {
+	event := eventbus.NewEvent(eventType, "consent", tenantID, data)
+	if err := s.eventBus.Publish(ctx, event); err != nil {
+		s.logger.Error("failed to publish event",
+			slog.String("event_type", eventType),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// =============================================================================
+// Checks
+// =============================================================================
+
+// widgetRuleFindings runs the deterministic checks of a widget config.
+func widgetRuleFindings(w *consent.ConsentWidget) []consent.DarkPatternFinding {
+	var findings []consent.DarkPatternFinding
+	cfg := w.Config
+
+	if cfg.DefaultState == "OPT_IN" {
+		findings = append(findings, consent.DarkPatternFinding{
+			Check:       "PRE_TICKED_DEFAULT",
+			Pattern:     string(analytics.DarkPatternInterfaceInterference),
+			Source:      consent.FindingSourceRule,
+			Severity:    consent.DarkPatternSeverityHigh,
+			Message:     "Purposes are pre-selected; consent must be given by a clear affirmative action",
+			CitedClause: "DPDPA s.6(1); Dark Patterns Guidelines Annexure 1(6) Interface Interference",
+		})
+	}
+
+	// The SDK always renders Reject All next to Accept All in a fixed style;
+	// a tenant can only hide it by blanking its label.
+	if langs := blankRejectAllLanguages(cfg.Translations); len(langs) > 0 {
+		findings = append(findings, consent.DarkPatternFinding{
+			Check:       "MISSING_REJECT_ALL",
+			Pattern:     string(analytics.DarkPatternForcedAction),
+			Source:      consent.FindingSourceRule,
+			Severity:    consent.DarkPatternSeverityHigh,
+			Message:     fmt.Sprintf("The Reject All label is blank in %s; declining must be as easy as accepting", strings.Join(langs, ", ")),
+			CitedClause: "DPDPA s.6(1); Dark Patterns Guidelines Annexure 1(4) Forced Action",
+		})
+	}
+
+	if f, ok := rejectAllContrastFinding(cfg.Theme); ok {
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+// rejectAllContrastFinding flags a theme whose Reject All button is hard to
+// read while Accept All is not. The SDK draws Reject All in the text colour
+// on the background and Accept All in white on the primary colour. Colours
+// that are not hex are not checked.
+func rejectAllContrastFinding(theme consent.ThemeConfig) (consent.DarkPatternFinding, bool) {
+	text, okText := parseHexColor(themeColor(theme.TextColor, sdkDefaultTextColor))
+	bg, okBG := parseHexColor(themeColor(theme.BackgroundColor, sdkDefaultBackgroundColor))
+	primary, okPrimary := parseHexColor(themeColor(theme.PrimaryColor, sdkDefaultPrimaryColor))
+	if !okText || !okBG || !okPrimary {
+		return consent.DarkPatternFinding{}, false
+	}
+
+	reject := contrastRatio(text, bg)
+	accept := contrastRatio([3]float64{1, 1, 1}, primary)
+	if reject >= minButtonContrast || reject >= accept {
+		return consent.DarkPatternFinding{}, false
+	}
+	return consent.DarkPatternFinding{
+		Check:       "LOW_CONTRAST_REJECT_ALL",
+		Pattern:     string(analytics.DarkPatternInterfaceInterference),
+		Source:      consent.FindingSourceRule,
+		Severity:    consent.DarkPatternSeverityHigh,
+		Message:     fmt.Sprintf("Reject All text has a contrast of %.1f:1 against %.1f:1 for Accept All; declining must be as visible as accepting", reject, accept),
+		CitedClause: "DPDPA s.6(1); Dark Patterns Guidelines Annexure 1(6) Interface Interference",
+	}, true
+}
+
+// themeColor returns the theme colour, or the SDK's fallback when unset.
+func themeColor(color, fallback string) string {
+	if strings.TrimSpace(color) == "" {
+		return fallback
+	}
+	return color
+}
+
+// parseHexColor parses a #rgb or #rrggbb colour into sRGB channels in [0, 1].
+func parseHexColor(s string) ([3]float64, bool) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return [3]float64{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return [3]float64{}, false
+	}
+	return [3]float64{
+		float64(v>>16&0xff) / 255,
+		float64(v>>8&0xff) / 255,
+		float64(v&0xff) / 255,
+	}, true
+}
+
+// contrastRatio returns the WCAG 2 contrast ratio of two sRGB colours.
+func contrastRatio(a, b [3]float64) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// relativeLuminance returns the WCAG 2 relative luminance of an sRGB colour.
+func relativeLuminance(c [3]float64) float64 {
+	var lin [3]float64
+	for i, v := range c {
+		if v <= 0.03928 {
+			lin[i] = v / 12.92
+		} else {
+			lin[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*lin[0] + 0.7152*lin[1] + 0.0722*lin[2]
+}
+
+// aiFindings converts an AI analysis into findings.
+func aiFindings(r *analytics.DarkPatternAnalysisResult) []consent.DarkPatternFinding {
+	severity := consent.DarkPatternSeverityMedium
+	if r.Confidence >= aiHighSeverityConfidence {
+		severity = consent.DarkPatternSeverityHigh
+	}
+	findings := make([]consent.DarkPatternFinding, 0, len(r.DetectedPatterns))
+	for _, p := range r.DetectedPatterns {
+		findings = append(findings, consent.DarkPatternFinding{
+			Check:       "AI",
+			Pattern:     string(p),
+			Source:      consent.FindingSourceAI,
+			Severity:    severity,
+			Message:     r.Explanation,
+			CitedClause: r.CitedClause,
+			Confidence:  r.Confidence,
+		})
+	}
+	return findings
+}
+
+// widgetAuditContent renders the tenant-authored text of a widget for the
+// AI review: its translations and purposes, with the layout for context.
+// Empty if the widget has no authored text, as the SDK defaults
+// need no review.
+func widgetAuditContent(w *consent.ConsentWidget) string {
+	cfg := w.Config
+	if len(cfg.Translations) == 0 && len(cfg.Purposes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Widget type: %s, layout: %s\n", w.Type, cfg.Layout)
+	fmt.Fprintf(&b, "Purpose toggles default to: %s\n", cfg.DefaultState)
+
+	langs := make([]string, 0, len(cfg.Translations))
+	for lang := range cfg.Translations {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		texts := cfg.Translations[lang]
+		keys := make([]string, 0, len(texts))
+		for k := range texts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(&b, "\nText [%s]:\n", lang)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "- %s: %s\n", k, texts[k])
+		}
+	}
+
+	if len(cfg.Purposes) > 0 {
+		b.WriteString("\nPurposes:\n")
+		for _, p := range cfg.Purposes {
+			essential := ""
+			if p.IsEssential {
+				essential = " (essential)"
+			}
+			fmt.Fprintf(&b, "- %s%s: %s\n", p.Name, essential, p.Description)
+		}
+	}
+	return b.String()
+}
+
+// blankRejectAllLanguages returns, sorted, the languages whose translation
+// sets the Reject All label to whitespace. An empty label is not counted, as
+// the SDK falls back to the default text.
+func blankRejectAllLanguages(translations map[string]map[string]string) []string {
+	var langs []string
+	for lang, texts := range translations {
+		if label, ok := texts["reject_all"]; ok && label != "" && strings.TrimSpace(label) == "" {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	return langs
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/complyark/datalens/internal/domain/consent"
+	"github.com/complyark/datalens/internal/service/analytics"
+	"github.com/complyark/datalens/pkg/types"
+)
+
+type mockWidgetAuditRepo struct {
+	mu     sync.Mutex
+	audits []*consent.WidgetAudit
+}
+
+func (r *mockWidgetAuditRepo) Create(_ context.Context, a *consent.WidgetAudit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a.ID = types.NewID()
+	r.audits = append(r.audits, a)
+	return nil
+}
+
+func (r *mockWidgetAuditRepo) GetLatest(_ context.Context, widgetID types.ID) (*consent.WidgetAudit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.audits) - 1; i >= 0; i-- {
+		if r.audits[i].WidgetID == widgetID {
+			return r.audits[i], nil
+		}
+	}
+	return nil, types.NewNotFoundError("widget audit", widgetID)
+}
+
+func (r *mockWidgetAuditRepo) ListByWidget(_ context.Context, widgetID types.ID) ([]consent.WidgetAudit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []consent.WidgetAudit
+	for i := len(r.audits) - 1; i >= 0; i-- {
+		if r.audits[i].WidgetID == widgetID {
+			out = append(out, *r.audits[i])
+		}
+	}
+	return out, nil
+}
+
+func (r *mockWidgetAuditRepo) SetOverride(_ context.Context, id types.ID, o *consent.WidgetAuditOverride) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range r.audits {
+		if a.ID == id {
+			a.Override = o
+			return nil
+		}
+	}
+	return types.NewNotFoundError("widget audit", id)
+}
+
+// stubDarkPatternAnalyzer returns a fixed result and counts calls.
+type stubDarkPatternAnalyzer struct {
+	result *analytics.DarkPatternAnalysisResult
+	err    error
+	calls  int
+}
+
+func (a *stubDarkPatternAnalyzer) AnalyzeContent(_ context.Context, _ string, _ string) (*analytics.DarkPatternAnalysisResult, error) {
+	a.calls++
+	return a.result, a.err
+}
+
+func newAuditedConsentService(analyzer DarkPatternAnalyzer) (*ConsentService, *mockWidgetAuditRepo, *mockEventBus, context.Context) {
+	svc, _, _, _, eventBus := newTestConsentService()
+	auditRepo := &mockWidgetAuditRepo{}
+	if analyzer == nil {
+		analyzer = &stubDarkPatternAnalyzer{result: &analytics.DarkPatternAnalysisResult{}}
+	}
+	svc.SetWidgetAuditor(NewWidgetAuditService(auditRepo, analyzer, eventBus, newTestLogger()))
+	ctx := context.WithValue(context.Background(), types.ContextKeyTenantID, types.NewID())
+	ctx = context.WithValue(ctx, types.ContextKeyUserID, types.NewID())
+	return svc, auditRepo, eventBus, ctx
+}
+
+func TestWidgetRuleFindings(t *testing.T) {
+	checks := func(cfg consent.WidgetConfig) map[string]consent.DarkPatternSeverity {
+		out := map[string]consent.DarkPatternSeverity{}
+		for _, f := range widgetRuleFindings(&consent.ConsentWidget{Config: cfg}) {
+			out[f.Check] = f.Severity
+		}
+		return out
+	}
+	blankReject := map[string]map[string]string{"en": {"reject_all": "Reject All"}, "hi": {"reject_all": " "}}
+
+	// The stock widget has no findings, and an empty label falls back to the default.
+	assert.Empty(t, checks(consent.WidgetConfig{DefaultState: "OPT_OUT"}))
+	assert.Empty(t, checks(consent.WidgetConfig{Translations: map[string]map[string]string{"en": {"reject_all": ""}}}))
+
+	assert.Equal(t, map[string]consent.DarkPatternSeverity{
+		"PRE_TICKED_DEFAULT": consent.DarkPatternSeverityHigh,
+		"MISSING_REJECT_ALL": consent.DarkPatternSeverityHigh,
+	}, checks(consent.WidgetConfig{DefaultState: "OPT_IN", Translations: blankReject}))
+
+	// Faint Reject All text next to the default Accept All is interference;
+	// a theme that is uniformly faint, or not in hex, is not.
+	assert.Equal(t, map[string]consent.DarkPatternSeverity{
+		"LOW_CONTRAST_REJECT_ALL": consent.DarkPatternSeverityHigh,
+	}, checks(consent.WidgetConfig{Theme: consent.ThemeConfig{TextColor: "#ccc", BackgroundColor: "#ffffff"}}))
+	assert.Empty(t, checks(consent.WidgetConfig{Theme: consent.ThemeConfig{TextColor: "#cccccc", PrimaryColor: "#dddddd"}}))
+	assert.Empty(t, checks(consent.WidgetConfig{Theme: consent.ThemeConfig{TextColor: "lightgray"}}))
+}
+
+func TestConsentService_ActivateWidget_BlockedByDarkPatterns(t *testing.T) {
+	svc, auditRepo, eventBus, ctx := newAuditedConsentService(nil)
+
+	widget, err := svc.CreateWidget(ctx, CreateWidgetRequest{
+		Name: "Banner", Type: "BANNER", Domain: "example.com",
+		Config: consent.WidgetConfig{DefaultState: "OPT_IN"},
+	})
+	require.NoError(t, err)
+
+	_, err = svc.ActivateWidget(ctx, widget.ID)
+	require.ErrorIs(t, err, types.ErrValidation)
+	var de *types.DomainError
+	require.True(t, errors.As(err, &de))
+	assert.Len(t, de.Details["findings"], 1)
+	assert.Equal(t, consent.WidgetStatusDraft, widget.Status)
+	assert.Equal(t, EventWidgetActivationBlocked, eventBus.Events[len(eventBus.Events)-1].Type)
+
+	// An override reason activates, and is recorded on the same audit.
+	activated, err := svc.ActivateWidgetWithOverride(ctx, widget.ID, "Legal approved the pre-selection for essential-adjacent purposes")
+	require.NoError(t, err)
+	assert.Equal(t, consent.WidgetStatusActive, activated.Status)
+
+	audits, err := svc.ListWidgetAudits(ctx, widget.ID)
+	require.NoError(t, err)
+	require.Len(t, audits, 1)
+	require.NotNil(t, audits[0].Override)
+	assert.NotNil(t, audits[0].Override.OverriddenBy)
+	assert.Equal(t, consent.WidgetAuditActivation, audits[0].Trigger)
+	assert.Len(t, auditRepo.audits, 1)
+}
+
+func TestConsentService_UpdateWidget_AuditsConfigBump(t *testing.T) {
+	svc, auditRepo, _, ctx := newAuditedConsentService(nil)
+
+	widget, err := svc.CreateWidget(ctx, CreateWidgetRequest{Name: "Banner", Type: "BANNER", Domain: "example.com"})
+	require.NoError(t, err)
+	_, err = svc.ActivateWidget(ctx, widget.ID)
+	require.NoError(t, err)
+	require.Len(t, auditRepo.audits, 1)
+
+	// A live widget rejects a config with HIGH findings, and the audit of
+	// the version that was never saved is not stored...
+	hidden := consent.WidgetConfig{Translations: map[string]map[string]string{"en": {"reject_all": " "}}}
+	_, err = svc.UpdateWidget(ctx, widget.ID, UpdateWidgetRequest{Config: &hidden})
+	require.ErrorIs(t, err, types.ErrValidation)
+	assert.Len(t, auditRepo.audits, 1)
+
+	// ...unless overridden.
+	updated, err := svc.UpdateWidget(ctx, widget.ID, UpdateWidgetRequest{
+		Config:         &hidden,
+		OverrideReason: "Reject is offered in the preference centre",
+	})
+	require.NoError(t, err)
+
+	latest, err := auditRepo.GetLatest(ctx, widget.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated.Version, latest.WidgetVersion)
+	assert.Equal(t, consent.WidgetAuditConfigUpdate, latest.Trigger)
+	assert.NotNil(t, latest.Override)
+}
+
+func TestWidgetAuditService_AIFindings(t *testing.T) {
+	analyzer := &stubDarkPatternAnalyzer{result: &analytics.DarkPatternAnalysisResult{
+		DetectedPatterns: []analytics.DarkPatternType{analytics.DarkPatternConfirmShaming},
+		Confidence:       0.92,
+		Explanation:      "The reject label shames the user",
+		CitedClause:      "Annexure 1(3) Confirm Shaming",
+		PromptVersion:    "dark_pattern@builtin",
+	}}
+	svc, _, _, ctx := newAuditedConsentService(analyzer)
+
+	widget, err := svc.CreateWidget(ctx, CreateWidgetRequest{
+		Name: "Banner", Type: "BANNER", Domain: "example.com",
+		Config: consent.WidgetConfig{
+			Translations: map[string]map[string]string{"en": {"reject_all": "No, I don't care about my privacy"}},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = svc.ActivateWidget(ctx, widget.ID)
+	require.ErrorIs(t, err, types.ErrValidation)
+	audits, err := svc.ListWidgetAudits(ctx, widget.ID)
+	require.NoError(t, err)
+	require.Len(t, audits, 1)
+	require.Len(t, audits[0].Findings, 1)
+	assert.Equal(t, consent.FindingSourceAI, audits[0].Findings[0].Source)
+	assert.Equal(t, "CONFIRM_SHAMING", audits[0].Findings[0].Pattern)
+	assert.Equal(t, "dark_pattern@builtin", audits[0].AIPromptVersion)
+
+	// Retrying the same version reuses the audit rather than re-asking.
+	_, err = svc.ActivateWidget(ctx, widget.ID)
+	require.ErrorIs(t, err, types.ErrValidation)
+	assert.Equal(t, 1, analyzer.calls)
+}
+
+func TestWidgetAuditService_AIUnavailable(t *testing.T) {
+	analyzer := &stubDarkPatternAnalyzer{err: errors.New("no provider available")}
+	svc, _, _, ctx := newAuditedConsentService(analyzer)
+
+	widget, err := svc.CreateWidget(ctx, CreateWidgetRequest{
+		Name: "Banner", Type: "BANNER", Domain: "example.com",
+		Config: consent.WidgetConfig{Translations: map[string]map[string]string{"en": {"title": "Your privacy"}}},
+	})
+	require.NoError(t, err)
+
+	// The deterministic checks still gate activation.
+	_, err = svc.ActivateWidget(ctx, widget.ID)
+	require.NoError(t, err)
+	audits, err := svc.ListWidgetAudits(ctx, widget.ID)
+	require.NoError(t, err)
+	require.Len(t, audits, 1)
+	assert.Equal(t, "no provider available", audits[0].AIError)
+
+	// An audit without the AI check is not reused.
+	_, err = svc.ActivateWidget(ctx, widget.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, analyzer.calls)
+}
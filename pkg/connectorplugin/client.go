@@ -0,0 +1,140 @@
+package connectorplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client is the host side of the plugin protocol. The DataLens host wraps
+// it in a discovery.Connector; plugin authors can use it to test their
+// connector end to end.
+type Client struct {
+	cc *grpc.ClientConn
+}
+
+// Dial connects to a plugin listening on addr. The connection is
+// loopback-only, so transport security is not used.
+func Dial(addr string) (*Client, error) {
+	cc, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connectorplugin: dial %s: %w", addr, err)
+	}
+	return &Client{cc: cc}, nil
+}
+
+func (c *Client) invoke(ctx context.Context, method string, req, resp any) error {
+	return c.cc.Invoke(ctx, "/"+serviceName+"/"+method, req, resp)
+}
+
+// Handshake negotiates the protocol version and capabilities. It must be
+// the first call on a new connection.
+func (c *Client) Handshake(ctx context.Context, hostCaps Capabilities) (*HandshakeResponse, error) {
+	resp := new(HandshakeResponse)
+	err := c.invoke(ctx, "Handshake", &HandshakeRequest{
+		ProtocolVersions: SupportedProtocolVersions,
+		HostCapabilities: hostCaps,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Connect forwards discovery.Connector.Connect.
+func (c *Client) Connect(ctx context.Context, ds DataSource) error {
+	return c.invoke(ctx, "Connect", &ConnectRequest{DataSource: ds}, new(Empty))
+}
+
+// DiscoverSchema forwards discovery.Connector.DiscoverSchema.
+func (c *Client) DiscoverSchema(ctx context.Context, input DiscoveryInput) (*DiscoverSchemaResponse, error) {
+	resp := new(DiscoverSchemaResponse)
+	if err := c.invoke(ctx, "DiscoverSchema", &input, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetFields forwards discovery.Connector.GetFields.
+func (c *Client) GetFields(ctx context.Context, entity string) ([]Field, error) {
+	resp := new(GetFieldsResponse)
+	if err := c.invoke(ctx, "GetFields", &GetFieldsRequest{Entity: entity}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Fields, nil
+}
+
+// SampleData forwards discovery.Connector.SampleData.
+func (c *Client) SampleData(ctx context.Context, entity, field string, limit int) ([]string, error) {
+	resp := new(SampleDataResponse)
+	if err := c.invoke(ctx, "SampleData", &SampleDataRequest{Entity: entity, Field: field, Limit: limit}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Values, nil
+}
+
+// Delete forwards discovery.Connector.Delete.
+func (c *Client) Delete(ctx context.Context, entity string, filter map[string]string) (int64, error) {
+	resp := new(DeleteResponse)
+	if err := c.invoke(ctx, "Delete", &FilterRequest{Entity: entity, Filter: filter}, resp); err != nil {
+		return 0, err
+	}
+	return resp.Deleted, nil
+}
+
+// Export forwards discovery.Connector.Export.
+func (c *Client) Export(ctx context.Context, entity string, filter map[string]string) ([]map[string]any, error) {
+	resp := new(ExportResponse)
+	if err := c.invoke(ctx, "Export", &FilterRequest{Entity: entity, Filter: filter}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Records, nil
+}
+
+// Scan streams findings from a plugin that negotiated SupportsScan.
+func (c *Client) Scan(ctx context.Context, ds DataSource, onFinding func(Finding)) error {
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/Scan")
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(&ScanRequest{DataSource: ds}); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		var f Finding
+		err := stream.RecvMsg(&f)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		onFinding(f)
+	}
+}
+
+// Close asks the plugin to close its connector, then closes the gRPC
+// connection.
+func (c *Client) Close(ctx context.Context) error {
+	err := c.invoke(ctx, "Close", new(Empty), new(Empty))
+	if cerr := c.Disconnect(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Disconnect closes the gRPC connection without notifying the plugin. Use
+// it when the plugin process is already gone.
+func (c *Client) Disconnect() error {
+	return c.cc.Close()
+}
@@ -0,0 +1,204 @@
+package connectorplugin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeConnector struct {
+	connected *DataSource
+	closed    int
+}
+
+func (f *fakeConnector) Connect(_ context.Context, ds *DataSource) error {
+	if ds.Credentials == "" {
+		return errors.New("credentials required")
+	}
+	f.connected = ds
+	return nil
+}
+
+func (f *fakeConnector) DiscoverSchema(_ context.Context, _ DiscoveryInput) (*Inventory, []Entity, error) {
+	return &Inventory{TotalEntities: 1, TotalFields: 2}, []Entity{{Name: "customers", Type: "TABLE"}}, nil
+}
+
+func (f *fakeConnector) GetFields(_ context.Context, entity string) ([]Field, error) {
+	if entity == "boom" {
+		panic("nil map")
+	}
+	return []Field{{Name: "id", DataType: "int", IsPrimaryKey: true}, {Name: "email", DataType: "text"}}, nil
+}
+
+func (f *fakeConnector) SampleData(_ context.Context, _, _ string, limit int) ([]string, error) {
+	return []string{"a@example.com", "b@example.com"}[:limit], nil
+}
+
+func (f *fakeConnector) Delete(_ context.Context, _ string, _ map[string]string) (int64, error) {
+	return 3, nil
+}
+
+func (f *fakeConnector) Export(_ context.Context, _ string, filter map[string]string) ([]map[string]any, error) {
+	return []map[string]any{{"email": filter["email"]}}, nil
+}
+
+func (f *fakeConnector) Close() error {
+	f.closed++
+	return nil
+}
+
+func (f *fakeConnector) Scan(_ context.Context, _ *DataSource, onFinding func(Finding) error) error {
+	for _, field := range []string{"email", "phone"} {
+		if err := onFinding(Finding{EntityName: "customers", FieldName: field, Type: "EMAIL", Confidence: 0.9}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testManifest() Manifest {
+	return Manifest{
+		Name:           "crm",
+		Version:        "0.1.0",
+		DataSourceType: "INHOUSE_CRM",
+		Capabilities: Capabilities{
+			CanDiscover: true, CanSample: true, CanDelete: true, CanExport: true,
+			SupportsScan: true, MaxConcurrency: 4,
+		},
+	}
+}
+
+func startTestServer(t *testing.T, conn Connector) *Client {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	gs, _ := newGRPCServer(testManifest(), conn)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	client, err := Dial(lis.Addr().String())
+	require.NoError(t, err)
+	return client
+}
+
+func TestCapabilities_Intersect(t *testing.T) {
+	plugin := Capabilities{CanDiscover: true, CanDelete: true, SupportsScan: true, MaxConcurrency: 8}
+	host := Capabilities{CanDiscover: true, CanDelete: false, SupportsScan: true, MaxConcurrency: 2}
+
+	got := plugin.Intersect(host)
+	assert.True(t, got.CanDiscover)
+	assert.False(t, got.CanDelete)
+	assert.True(t, got.SupportsScan)
+	assert.Equal(t, 2, got.MaxConcurrency)
+
+	assert.Equal(t, 8, plugin.Intersect(Capabilities{}).MaxConcurrency)
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	v, ok := negotiateVersion([]int{1, 2, 3}, []int{2, 1})
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+
+	_, ok = negotiateVersion([]int{2}, []int{1})
+	assert.False(t, ok)
+}
+
+func TestServe_RequiresMagicCookie(t *testing.T) {
+	t.Setenv(MagicCookieKey, "")
+	err := Serve(testManifest(), &fakeConnector{})
+	assert.ErrorIs(t, err, ErrNotLaunchedByHost)
+}
+
+func TestClient_RequiresHandshake(t *testing.T) {
+	client := startTestServer(t, &fakeConnector{})
+
+	err := client.Connect(context.Background(), DataSource{Credentials: "x"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestClient_RoundTrip(t *testing.T) {
+	fake := &fakeConnector{}
+	client := startTestServer(t, fake)
+	ctx := context.Background()
+
+	hs, err := client.Handshake(ctx, Capabilities{
+		CanDiscover: true, CanSample: true, CanDelete: true, CanExport: true, SupportsScan: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolVersion, hs.ProtocolVersion)
+	assert.Equal(t, "INHOUSE_CRM", hs.Manifest.DataSourceType)
+	assert.True(t, hs.Manifest.Capabilities.SupportsScan)
+
+	// Connector errors surface to the host.
+	assert.Error(t, client.Connect(ctx, DataSource{Name: "crm"}))
+	require.NoError(t, client.Connect(ctx, DataSource{Name: "crm", Credentials: "user:pass"}))
+	assert.Equal(t, "user:pass", fake.connected.Credentials)
+
+	schema, err := client.DiscoverSchema(ctx, DiscoveryInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, schema.Inventory.TotalFields)
+	require.Len(t, schema.Entities, 1)
+
+	fields, err := client.GetFields(ctx, "customers")
+	require.NoError(t, err)
+	assert.Len(t, fields, 2)
+	assert.True(t, fields[0].IsPrimaryKey)
+
+	samples, err := client.SampleData(ctx, "customers", "email", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a@example.com"}, samples)
+
+	deleted, err := client.Delete(ctx, "customers", map[string]string{"id": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	records, err := client.Export(ctx, "customers", map[string]string{"email": "a@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com", records[0]["email"])
+
+	var findings []Finding
+	require.NoError(t, client.Scan(ctx, DataSource{}, func(f Finding) { findings = append(findings, f) }))
+	assert.Len(t, findings, 2)
+	assert.Equal(t, "phone", findings[1].FieldName)
+
+	require.NoError(t, client.Close(ctx))
+	assert.Equal(t, 1, fake.closed)
+}
+
+func TestClient_NegotiatedCapabilitiesAreEnforced(t *testing.T) {
+	client := startTestServer(t, &fakeConnector{})
+	ctx := context.Background()
+
+	// Host does not allow deletes or plugin-driven scans.
+	hs, err := client.Handshake(ctx, Capabilities{CanDiscover: true, CanSample: true})
+	require.NoError(t, err)
+	assert.False(t, hs.Manifest.Capabilities.CanDelete)
+
+	_, err = client.Delete(ctx, "customers", nil)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	err = client.Scan(ctx, DataSource{}, func(Finding) {})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestClient_PluginPanicIsContained(t *testing.T) {
+	client := startTestServer(t, &fakeConnector{})
+	ctx := context.Background()
+
+	_, err := client.Handshake(ctx, testManifest().Capabilities)
+	require.NoError(t, err)
+
+	_, err = client.GetFields(ctx, "boom")
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// The plugin keeps serving after the panic.
+	fields, err := client.GetFields(ctx, "customers")
+	require.NoError(t, err)
+	assert.Len(t, fields, 2)
+}
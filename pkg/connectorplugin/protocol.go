@@ -0,0 +1,270 @@
+// Package connectorplugin is the SDK for building out-of-process DataLens
+// connectors. A plugin is a standalone executable that implements Connector
+// (and optionally Scanner) and calls Serve from its main function. DataLens
+// launches the executable, performs a handshake over stdout, and then talks
+// to it over a loopback gRPC connection. A crashing plugin only fails the
+// operation in flight; the host process keeps running.
+//
+// The package deliberately depends on nothing under internal/, so it can be
+// imported by connectors that live outside this repository.
+package connectorplugin
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ProtocolVersion is the newest plugin protocol version this SDK speaks.
+// The host and plugin agree on the highest version both support during
+// the handshake.
+const ProtocolVersion = 1
+
+// SupportedProtocolVersions lists every protocol version this SDK can speak.
+var SupportedProtocolVersions = []int{1}
+
+// MagicCookieKey and MagicCookieValue guard against a plugin executable
+// being run directly by a user. The host sets the variable when launching
+// a plugin; Serve refuses to start without it.
+const (
+	MagicCookieKey   = "DATALENS_PLUGIN_MAGIC_COOKIE"
+	MagicCookieValue = "b7c4a0e2-6f1d-4c52-9a3e-datalens-connector"
+)
+
+// ProtocolVersionsKey is the environment variable through which the host
+// advertises the protocol versions it supports (comma-separated).
+const ProtocolVersionsKey = "DATALENS_PLUGIN_PROTOCOL_VERSIONS"
+
+// HandshakePrefix starts the single line a plugin writes to stdout once it
+// is listening: "DATALENS_PLUGIN|<protocol version>|<address>".
+const HandshakePrefix = "DATALENS_PLUGIN"
+
+// =============================================================================
+// Plugin-side contract
+// =============================================================================
+
+// Connector is the contract a plugin implements. It mirrors the host's
+// discovery.Connector using the wire types in this package.
+type Connector interface {
+	Connect(ctx context.Context, ds *DataSource) error
+	DiscoverSchema(ctx context.Context, input DiscoveryInput) (*Inventory, []Entity, error)
+	GetFields(ctx context.Context, entity string) ([]Field, error)
+	SampleData(ctx context.Context, entity, field string, limit int) ([]string, error)
+	Delete(ctx context.Context, entity string, filter map[string]string) (int64, error)
+	Export(ctx context.Context, entity string, filter map[string]string) ([]map[string]any, error)
+	Close() error
+}
+
+// Scanner is implemented by plugins that perform their own traversal and
+// detection. It is only used when the manifest sets SupportsScan.
+type Scanner interface {
+	Scan(ctx context.Context, ds *DataSource, onFinding func(Finding) error) error
+}
+
+// Manifest describes a plugin. It is returned during the handshake and is
+// how the host learns which data source type the plugin serves.
+type Manifest struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version"`
+	DataSourceType string       `json:"data_source_type"`
+	Capabilities   Capabilities `json:"capabilities"`
+}
+
+// Capabilities mirrors discovery.ConnectorCapabilities plus SupportsScan,
+// which advertises the Scanner contract.
+type Capabilities struct {
+	CanDiscover             bool `json:"can_discover"`
+	CanSample               bool `json:"can_sample"`
+	CanDelete               bool `json:"can_delete"`
+	CanUpdate               bool `json:"can_update"`
+	CanExport               bool `json:"can_export"`
+	SupportsStreaming       bool `json:"supports_streaming"`
+	SupportsIncremental     bool `json:"supports_incremental"`
+	SupportsSchemaDiscovery bool `json:"supports_schema_discovery"`
+	SupportsDataSampling    bool `json:"supports_data_sampling"`
+	SupportsParallelScan    bool `json:"supports_parallel_scan"`
+	SupportsScan            bool `json:"supports_scan"`
+	MaxConcurrency          int  `json:"max_concurrency"`
+}
+
+// Intersect returns the capabilities supported by both sides. MaxConcurrency
+// takes the lower non-zero value.
+func (c Capabilities) Intersect(other Capabilities) Capabilities {
+	out := Capabilities{
+		CanDiscover:             c.CanDiscover && other.CanDiscover,
+		CanSample:               c.CanSample && other.CanSample,
+		CanDelete:               c.CanDelete && other.CanDelete,
+		CanUpdate:               c.CanUpdate && other.CanUpdate,
+		CanExport:               c.CanExport && other.CanExport,
+		SupportsStreaming:       c.SupportsStreaming && other.SupportsStreaming,
+		SupportsIncremental:     c.SupportsIncremental && other.SupportsIncremental,
+		SupportsSchemaDiscovery: c.SupportsSchemaDiscovery && other.SupportsSchemaDiscovery,
+		SupportsDataSampling:    c.SupportsDataSampling && other.SupportsDataSampling,
+		SupportsParallelScan:    c.SupportsParallelScan && other.SupportsParallelScan,
+		SupportsScan:            c.SupportsScan && other.SupportsScan,
+		MaxConcurrency:          c.MaxConcurrency,
+	}
+	if other.MaxConcurrency > 0 && (out.MaxConcurrency == 0 || other.MaxConcurrency < out.MaxConcurrency) {
+		out.MaxConcurrency = other.MaxConcurrency
+	}
+	return out
+}
+
+// =============================================================================
+// Wire types
+// =============================================================================
+
+// DataSource is the connection information handed to a plugin.
+// Credentials are already decrypted by the host.
+type DataSource struct {
+	ID          string `json:"id"`
+	TenantID    string `json:"tenant_id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Host        string `json:"host,omitempty"`
+	Port        int    `json:"port,omitempty"`
+	Database    string `json:"database,omitempty"`
+	Credentials string `json:"credentials,omitempty"`
+	Config      string `json:"config,omitempty"`
+}
+
+// DiscoveryInput mirrors discovery.DiscoveryInput.
+type DiscoveryInput struct {
+	ChangedSince time.Time `json:"changed_since,omitempty"`
+}
+
+// Inventory carries the totals reported by DiscoverSchema.
+type Inventory struct {
+	TotalEntities int `json:"total_entities"`
+	TotalFields   int `json:"total_fields"`
+}
+
+// Entity is a table, collection, folder, or file.
+type Entity struct {
+	Name     string `json:"name"`
+	Schema   string `json:"schema,omitempty"`
+	Type     string `json:"type"`
+	RowCount *int64 `json:"row_count,omitempty"`
+}
+
+// Field is a column or property of an entity.
+type Field struct {
+	Name         string `json:"name"`
+	DataType     string `json:"data_type"`
+	Nullable     bool   `json:"nullable"`
+	IsPrimaryKey bool   `json:"is_primary_key"`
+	IsForeignKey bool   `json:"is_foreign_key"`
+}
+
+// Finding is a PII detection reported by a Scanner. Category, Type,
+// Sensitivity and DetectionMethod use the host's enum values
+// (e.g. "CONTACT", "EMAIL", "HIGH", "REGEX").
+type Finding struct {
+	EntityName      string  `json:"entity_name"`
+	FieldName       string  `json:"field_name"`
+	Category        string  `json:"category"`
+	Type            string  `json:"type"`
+	Sensitivity     string  `json:"sensitivity"`
+	Confidence      float64 `json:"confidence"`
+	DetectionMethod string  `json:"detection_method"`
+	Reasoning       string  `json:"reasoning,omitempty"`
+}
+
+// =============================================================================
+// RPC messages
+// =============================================================================
+
+// HandshakeRequest is sent by the host right after dialing the plugin.
+type HandshakeRequest struct {
+	ProtocolVersions []int        `json:"protocol_versions"`
+	HostCapabilities Capabilities `json:"host_capabilities"`
+}
+
+// HandshakeResponse carries the negotiated protocol version and the
+// plugin's manifest with capabilities already intersected with the host's.
+type HandshakeResponse struct {
+	ProtocolVersion int      `json:"protocol_version"`
+	Manifest        Manifest `json:"manifest"`
+}
+
+// ConnectRequest is the Connect RPC payload.
+type ConnectRequest struct {
+	DataSource DataSource `json:"data_source"`
+}
+
+// DiscoverSchemaResponse is the DiscoverSchema RPC result.
+type DiscoverSchemaResponse struct {
+	Inventory Inventory `json:"inventory"`
+	Entities  []Entity  `json:"entities"`
+}
+
+// GetFieldsRequest is the GetFields RPC payload.
+type GetFieldsRequest struct {
+	Entity string `json:"entity"`
+}
+
+// GetFieldsResponse is the GetFields RPC result.
+type GetFieldsResponse struct {
+	Fields []Field `json:"fields"`
+}
+
+// SampleDataRequest is the SampleData RPC payload.
+type SampleDataRequest struct {
+	Entity string `json:"entity"`
+	Field  string `json:"field"`
+	Limit  int    `json:"limit"`
+}
+
+// SampleDataResponse is the SampleData RPC result.
+type SampleDataResponse struct {
+	Values []string `json:"values"`
+}
+
+// FilterRequest is the payload of the Delete and Export RPCs.
+type FilterRequest struct {
+	Entity string            `json:"entity"`
+	Filter map[string]string `json:"filter"`
+}
+
+// DeleteResponse is the Delete RPC result.
+type DeleteResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// ExportResponse is the Export RPC result.
+type ExportResponse struct {
+	Records []map[string]any `json:"records"`
+}
+
+// ScanRequest is the Scan RPC payload.
+type ScanRequest struct {
+	DataSource DataSource `json:"data_source"`
+}
+
+// Empty is used by RPCs without a payload or result.
+type Empty struct{}
+
+// =============================================================================
+// Codec
+// =============================================================================
+
+// jsonCodec encodes RPC messages as JSON so plugins need no generated
+// protobuf code. Both sides force it, so the content-subtype is "json".
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return "json" }
+
+// negotiateVersion picks the highest version present in both lists.
+func negotiateVersion(host, plugin []int) (int, bool) {
+	best := 0
+	for _, h := range host {
+		for _, p := range plugin {
+			if h == p && h > best {
+				best = h
+			}
+		}
+	}
+	return best, best > 0
+}
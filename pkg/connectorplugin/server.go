@@ -0,0 +1,339 @@
+package connectorplugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const serviceName = "datalens.connectorplugin.v1.Connector"
+
+// ErrNotLaunchedByHost is returned by Serve when the magic cookie is absent.
+var ErrNotLaunchedByHost = errors.New("connectorplugin: this binary is a DataLens connector plugin and must be launched by DataLens")
+
+// server adapts a plugin's Connector to the gRPC service. A plugin process
+// serves exactly one host connector, so it holds a single instance.
+type server struct {
+	manifest Manifest
+	conn     Connector
+
+	mu         sync.Mutex
+	negotiated *Capabilities
+	closeOnce  sync.Once
+	closeErr   error
+}
+
+// Serve runs the plugin until the host closes it. It listens on a loopback
+// port, announces itself on stdout, and blocks. Call it from main:
+//
+//	func main() {
+//		if err := connectorplugin.Serve(manifest, myconnector.New()); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func Serve(manifest Manifest, conn Connector) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return ErrNotLaunchedByHost
+	}
+	if manifest.DataSourceType == "" {
+		return errors.New("connectorplugin: manifest.DataSourceType is required")
+	}
+	if manifest.Capabilities.SupportsScan {
+		if _, ok := conn.(Scanner); !ok {
+			return errors.New("connectorplugin: manifest advertises SupportsScan but connector does not implement Scanner")
+		}
+	}
+
+	version, ok := negotiateVersion(parseVersions(os.Getenv(ProtocolVersionsKey)), SupportedProtocolVersions)
+	if !ok {
+		return fmt.Errorf("connectorplugin: no common protocol version with host (plugin supports %v)", SupportedProtocolVersions)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("connectorplugin: listen: %w", err)
+	}
+
+	gs, srv := newGRPCServer(manifest, conn)
+
+	// The host closes our stdin when it is done with us; treat that,
+	// SIGTERM and SIGINT as a graceful shutdown request.
+	go func() {
+		_, _ = io.Copy(io.Discard, bufio.NewReader(os.Stdin))
+		gs.GracefulStop()
+	}()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		<-sigs
+		gs.GracefulStop()
+	}()
+
+	fmt.Fprintf(os.Stdout, "%s|%d|%s\n", HandshakePrefix, version, lis.Addr().String())
+
+	err = gs.Serve(lis)
+	_ = srv.closeConn()
+	if errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+	return err
+}
+
+// newGRPCServer builds a gRPC server exposing conn. It is separate from
+// Serve so the SDK and host can exercise the protocol in-process.
+func newGRPCServer(manifest Manifest, conn Connector) (*grpc.Server, *server) {
+	gs := grpc.NewServer(
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnaryInterceptor(recoverUnary),
+		grpc.StreamInterceptor(recoverStream),
+	)
+	s := &server{manifest: manifest, conn: conn}
+	gs.RegisterService(&serviceDesc, s)
+	return gs, s
+}
+
+// closeConn closes the plugin's connector at most once; the host may call
+// the Close RPC and then shut the process down.
+func (s *server) closeConn() error {
+	s.closeOnce.Do(func() { s.closeErr = s.conn.Close() })
+	return s.closeErr
+}
+
+// recoverUnary turns a panic inside a plugin RPC into an Internal error so
+// a bug in one call does not take down the whole plugin process.
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = status.Errorf(codes.Internal, "plugin panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = status.Errorf(codes.Internal, "plugin panic in %s: %v\n%s", info.FullMethod, r, debug.Stack())
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func parseVersions(s string) []int {
+	var out []int
+	for _, part := range strings.Split(s, ",") {
+		if v, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// =============================================================================
+// RPC implementations
+// =============================================================================
+
+func (s *server) handshake(_ context.Context, req *HandshakeRequest) (*HandshakeResponse, error) {
+	version, ok := negotiateVersion(req.ProtocolVersions, SupportedProtocolVersions)
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "no common protocol version: host %v, plugin %v", req.ProtocolVersions, SupportedProtocolVersions)
+	}
+
+	caps := s.manifest.Capabilities.Intersect(req.HostCapabilities)
+	s.mu.Lock()
+	s.negotiated = &caps
+	s.mu.Unlock()
+
+	m := s.manifest
+	m.Capabilities = caps
+	return &HandshakeResponse{ProtocolVersion: version, Manifest: m}, nil
+}
+
+// capabilities returns the negotiated capabilities, or fails if the host
+// skipped the handshake.
+func (s *server) capabilities() (Capabilities, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.negotiated == nil {
+		return Capabilities{}, status.Error(codes.FailedPrecondition, "handshake required")
+	}
+	return *s.negotiated, nil
+}
+
+func (s *server) connect(ctx context.Context, req *ConnectRequest) (*Empty, error) {
+	if _, err := s.capabilities(); err != nil {
+		return nil, err
+	}
+	if err := s.conn.Connect(ctx, &req.DataSource); err != nil {
+		return nil, toStatus(err)
+	}
+	return &Empty{}, nil
+}
+
+func (s *server) discoverSchema(ctx context.Context, req *DiscoveryInput) (*DiscoverSchemaResponse, error) {
+	if caps, err := s.capabilities(); err != nil {
+		return nil, err
+	} else if !caps.CanDiscover {
+		return nil, status.Error(codes.Unimplemented, "discovery not supported")
+	}
+	inv, entities, err := s.conn.DiscoverSchema(ctx, *req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &DiscoverSchemaResponse{Entities: entities}
+	if inv != nil {
+		resp.Inventory = *inv
+	}
+	return resp, nil
+}
+
+func (s *server) getFields(ctx context.Context, req *GetFieldsRequest) (*GetFieldsResponse, error) {
+	if _, err := s.capabilities(); err != nil {
+		return nil, err
+	}
+	fields, err := s.conn.GetFields(ctx, req.Entity)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &GetFieldsResponse{Fields: fields}, nil
+}
+
+func (s *server) sampleData(ctx context.Context, req *SampleDataRequest) (*SampleDataResponse, error) {
+	if caps, err := s.capabilities(); err != nil {
+		return nil, err
+	} else if !caps.CanSample {
+		return nil, status.Error(codes.Unimplemented, "sampling not supported")
+	}
+	values, err := s.conn.SampleData(ctx, req.Entity, req.Field, req.Limit)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &SampleDataResponse{Values: values}, nil
+}
+
+func (s *server) delete(ctx context.Context, req *FilterRequest) (*DeleteResponse, error) {
+	if caps, err := s.capabilities(); err != nil {
+		return nil, err
+	} else if !caps.CanDelete {
+		return nil, status.Error(codes.Unimplemented, "delete not supported")
+	}
+	n, err := s.conn.Delete(ctx, req.Entity, req.Filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &DeleteResponse{Deleted: n}, nil
+}
+
+func (s *server) export(ctx context.Context, req *FilterRequest) (*ExportResponse, error) {
+	if caps, err := s.capabilities(); err != nil {
+		return nil, err
+	} else if !caps.CanExport {
+		return nil, status.Error(codes.Unimplemented, "export not supported")
+	}
+	records, err := s.conn.Export(ctx, req.Entity, req.Filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &ExportResponse{Records: records}, nil
+}
+
+func (s *server) close(_ context.Context, _ *Empty) (*Empty, error) {
+	if err := s.closeConn(); err != nil {
+		return nil, toStatus(err)
+	}
+	return &Empty{}, nil
+}
+
+func (s *server) scan(req *ScanRequest, stream grpc.ServerStream) error {
+	caps, err := s.capabilities()
+	if err != nil {
+		return err
+	}
+	scanner, ok := s.conn.(Scanner)
+	if !caps.SupportsScan || !ok {
+		return status.Error(codes.Unimplemented, "scan not supported")
+	}
+	err = scanner.Scan(stream.Context(), &req.DataSource, func(f Finding) error {
+		return stream.SendMsg(&f)
+	})
+	if err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+// toStatus preserves context errors so the host can tell a cancelled scan
+// from a connector failure.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
+
+// =============================================================================
+// Service descriptor
+// =============================================================================
+
+func unaryHandler[Req any, Resp any](method string, call func(*server, context.Context, *Req) (*Resp, error)) grpc.MethodHandler {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := new(Req)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		s := srv.(*server)
+		if interceptor == nil {
+			return call(s, ctx, req)
+		}
+		return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/" + method}, func(ctx context.Context, r any) (any, error) {
+			return call(s, ctx, r.(*Req))
+		})
+	}
+}
+
+func scanHandler(srv any, stream grpc.ServerStream) error {
+	req := new(ScanRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(*server).scan(req, stream)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Handshake", Handler: unaryHandler("Handshake", (*server).handshake)},
+		{MethodName: "Connect", Handler: unaryHandler("Connect", (*server).connect)},
+		{MethodName: "DiscoverSchema", Handler: unaryHandler("DiscoverSchema", (*server).discoverSchema)},
+		{MethodName: "GetFields", Handler: unaryHandler("GetFields", (*server).getFields)},
+		{MethodName: "SampleData", Handler: unaryHandler("SampleData", (*server).sampleData)},
+		{MethodName: "Delete", Handler: unaryHandler("Delete", (*server).delete)},
+		{MethodName: "Export", Handler: unaryHandler("Export", (*server).export)},
+		{MethodName: "Close", Handler: unaryHandler("Close", (*server).close)},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Scan", Handler: scanHandler, ServerStreams: true},
+	},
+}
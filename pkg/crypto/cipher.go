@@ -0,0 +1,42 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// CredentialCipher encrypts and decrypts secrets stored at rest, such as
+// DataSource.Credentials and OAuth refresh tokens. Implementations may use
+// the tenant ID to select a per-tenant key.
+type CredentialCipher interface {
+	Encrypt(ctx context.Context, tenantID types.ID, plaintext string) (string, error)
+	Decrypt(ctx context.Context, tenantID types.ID, ciphertext string) (string, error)
+}
+
+// StaticCipher is the original single-key scheme: every tenant shares one
+// AES-256 key derived from the application secret. It remains the fallback
+// for ciphertexts written before envelope encryption was enabled.
+type StaticCipher struct {
+	key string
+}
+
+// NewStaticCipher pads or truncates secret to the 32 bytes AES-256 needs,
+// matching how the key has always been derived from APP_SECRET_KEY.
+func NewStaticCipher(secret string) *StaticCipher {
+	if len(secret) < 32 {
+		secret = fmt.Sprintf("%-32s", secret)
+	}
+	return &StaticCipher{key: secret[:32]}
+}
+
+// Encrypt encrypts plaintext with the static key.
+func (c *StaticCipher) Encrypt(_ context.Context, _ types.ID, plaintext string) (string, error) {
+	return Encrypt(plaintext, c.key)
+}
+
+// Decrypt decrypts ciphertext with the static key.
+func (c *StaticCipher) Decrypt(_ context.Context, _ types.ID, ciphertext string) (string, error) {
+	return Decrypt(ciphertext, c.key)
+}
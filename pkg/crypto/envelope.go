@@ -0,0 +1,357 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// Envelope ciphertext layout (base64-encoded as a whole):
+//
+//	"DLE" | format version (1 byte) | data key version (uint32 BE) | nonce | AES-GCM
+//
+// The header and tenant ID are authenticated as additional data, so a
+// ciphertext cannot be moved to another tenant or relabelled with another
+// key version.
+var envelopeMagic = []byte("DLE")
+
+const (
+	envelopeFormatV1  byte = 1
+	envelopeHeaderLen      = 3 + 1 + 4
+)
+
+// activeKeyTTL bounds how long a process keeps using a tenant's active data
+// key version after another process rotates it.
+const activeKeyTTL = time.Minute
+
+var (
+	// ErrDataKeyNotFound is returned by a DataKeyStore when no key matches.
+	ErrDataKeyNotFound = errors.New("crypto: data key not found")
+	// ErrDataKeyExists is returned by DataKeyStore.Create when the version
+	// was created concurrently.
+	ErrDataKeyExists = errors.New("crypto: data key already exists")
+	// ErrNotEnvelope is returned when a ciphertext has no envelope header
+	// and no legacy cipher is configured.
+	ErrNotEnvelope = errors.New("crypto: ciphertext is not envelope encrypted")
+)
+
+// DataKey is a tenant's AES-256 data key, stored only in wrapped form.
+type DataKey struct {
+	TenantID    types.ID  `json:"tenant_id" db:"tenant_id"`
+	Version     int       `json:"version" db:"version"`
+	WrappedKey  []byte    `json:"-" db:"wrapped_key"`
+	MasterKeyID string    `json:"master_key_id" db:"master_key_id"`
+	Active      bool      `json:"active" db:"active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	RotatedAt   time.Time `json:"rotated_at" db:"rotated_at"`
+}
+
+// DataKeyStore persists wrapped data keys.
+type DataKeyStore interface {
+	// GetActive returns the tenant's active data key or ErrDataKeyNotFound.
+	GetActive(ctx context.Context, tenantID types.ID) (*DataKey, error)
+	// Get returns a specific data key version or ErrDataKeyNotFound.
+	Get(ctx context.Context, tenantID types.ID, version int) (*DataKey, error)
+	// Create stores a new data key and makes it the tenant's only active key.
+	// It returns ErrDataKeyExists if the version already exists.
+	Create(ctx context.Context, key *DataKey) error
+	// UpdateWrappedKey replaces the wrapped form of a data key after the
+	// master key was rotated.
+	UpdateWrappedKey(ctx context.Context, tenantID types.ID, version int, wrapped []byte, masterKeyID string) error
+	// ListAll returns every data key of every tenant.
+	ListAll(ctx context.Context) ([]DataKey, error)
+}
+
+// KeyManager implements CredentialCipher with envelope encryption: each
+// tenant has versioned data keys, which are wrapped by a MasterKeyProvider.
+// Ciphertexts without an envelope header are decrypted with the legacy
+// cipher, so existing data keeps working while it is re-encrypted.
+type KeyManager struct {
+	provider MasterKeyProvider
+	store    DataKeyStore
+	legacy   CredentialCipher
+
+	mu     sync.Mutex
+	keys   map[dataKeyRef][]byte
+	active map[types.ID]activeKey
+}
+
+type dataKeyRef struct {
+	tenantID types.ID
+	version  int
+}
+
+type activeKey struct {
+	version int
+	expires time.Time
+}
+
+// Compile-time check
+var _ CredentialCipher = (*KeyManager)(nil)
+
+// NewKeyManager creates a KeyManager. legacy may be nil once all
+// ciphertexts have been re-encrypted.
+func NewKeyManager(provider MasterKeyProvider, store DataKeyStore, legacy CredentialCipher) *KeyManager {
+	return &KeyManager{
+		provider: provider,
+		store:    store,
+		legacy:   legacy,
+		keys:     make(map[dataKeyRef][]byte),
+		active:   make(map[types.ID]activeKey),
+	}
+}
+
+// Encrypt encrypts plaintext with the tenant's active data key, creating
+// the first data key on demand.
+func (m *KeyManager) Encrypt(ctx context.Context, tenantID types.ID, plaintext string) (string, error) {
+	version, key, err := m.activeDataKey(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	header := envelopeHeader(version)
+	sealed, err := sealGCM(key, []byte(plaintext), envelopeAAD(header, tenantID))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(append(header, sealed...)), nil
+}
+
+// Decrypt decrypts an envelope ciphertext, or a legacy ciphertext if a
+// legacy cipher is configured.
+func (m *KeyManager) Decrypt(ctx context.Context, tenantID types.ID, ciphertext string) (string, error) {
+	raw, version, ok := parseEnvelope(ciphertext)
+	if !ok {
+		if m.legacy == nil {
+			return "", ErrNotEnvelope
+		}
+		return m.legacy.Decrypt(ctx, tenantID, ciphertext)
+	}
+
+	plaintext, err := m.openEnvelope(ctx, tenantID, raw, version)
+	if err != nil && m.legacy != nil {
+		// A legacy ciphertext's random nonce can begin with the magic
+		// bytes; give the legacy cipher a chance before failing.
+		if legacy, lerr := m.legacy.Decrypt(ctx, tenantID, ciphertext); lerr == nil {
+			return legacy, nil
+		}
+	}
+	return plaintext, err
+}
+
+func (m *KeyManager) openEnvelope(ctx context.Context, tenantID types.ID, raw []byte, version int) (string, error) {
+	key, err := m.dataKey(ctx, tenantID, version)
+	if err != nil {
+		return "", err
+	}
+	header := raw[:envelopeHeaderLen]
+	plaintext, err := openGCM(key, raw[envelopeHeaderLen:], envelopeAAD(header, tenantID))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Reencrypt returns ciphertext re-encrypted with the tenant's active data
+// key. It reports false, and returns the input unchanged, if the ciphertext
+// already uses the active key.
+func (m *KeyManager) Reencrypt(ctx context.Context, tenantID types.ID, ciphertext string) (string, bool, error) {
+	if _, version, ok := parseEnvelope(ciphertext); ok {
+		active, _, err := m.activeDataKey(ctx, tenantID)
+		if err != nil {
+			return "", false, err
+		}
+		if version == active {
+			return ciphertext, false, nil
+		}
+	}
+
+	plaintext, err := m.Decrypt(ctx, tenantID, ciphertext)
+	if err != nil {
+		return "", false, err
+	}
+	out, err := m.Encrypt(ctx, tenantID, plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return out, true, nil
+}
+
+// RotateDataKey creates a new data key version for the tenant and makes it
+// active. Older versions remain available for decryption.
+func (m *KeyManager) RotateDataKey(ctx context.Context, tenantID types.ID) (int, error) {
+	next := 1
+	current, err := m.store.GetActive(ctx, tenantID)
+	switch {
+	case err == nil:
+		next = current.Version + 1
+	case !errors.Is(err, ErrDataKeyNotFound):
+		return 0, fmt.Errorf("crypto: get active data key: %w", err)
+	}
+
+	if _, err := m.createDataKey(ctx, tenantID, next); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+// RewrapDataKeys re-wraps every stored data key under the master key
+// provider's current version. Data keys themselves do not change, so no
+// ciphertext needs to be touched. It returns the number of keys rewrapped.
+func (m *KeyManager) RewrapDataKeys(ctx context.Context) (int, error) {
+	keys, err := m.store.ListAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("crypto: list data keys: %w", err)
+	}
+
+	rewrapped := 0
+	for _, k := range keys {
+		wrapped, changed, err := m.provider.Rewrap(ctx, k.WrappedKey)
+		if err != nil {
+			return rewrapped, fmt.Errorf("crypto: rewrap data key %s v%d: %w", k.TenantID, k.Version, err)
+		}
+		if !changed && k.MasterKeyID == m.provider.ID() {
+			continue
+		}
+		if err := m.store.UpdateWrappedKey(ctx, k.TenantID, k.Version, wrapped, m.provider.ID()); err != nil {
+			return rewrapped, fmt.Errorf("crypto: save rewrapped data key %s v%d: %w", k.TenantID, k.Version, err)
+		}
+		rewrapped++
+	}
+	return rewrapped, nil
+}
+
+// activeDataKey returns the tenant's active data key, creating version 1
+// if the tenant has none yet.
+func (m *KeyManager) activeDataKey(ctx context.Context, tenantID types.ID) (int, []byte, error) {
+	m.mu.Lock()
+	cached, ok := m.active[tenantID]
+	m.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		key, err := m.dataKey(ctx, tenantID, cached.version)
+		return cached.version, key, err
+	}
+
+	dk, err := m.store.GetActive(ctx, tenantID)
+	if errors.Is(err, ErrDataKeyNotFound) {
+		dk, err = m.createDataKey(ctx, tenantID, 1)
+		if errors.Is(err, ErrDataKeyExists) {
+			// Another process created it first.
+			dk, err = m.store.GetActive(ctx, tenantID)
+		}
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("crypto: get active data key: %w", err)
+	}
+
+	key, err := m.unwrap(ctx, dk)
+	if err != nil {
+		return 0, nil, err
+	}
+	m.mu.Lock()
+	m.active[tenantID] = activeKey{version: dk.Version, expires: time.Now().Add(activeKeyTTL)}
+	m.mu.Unlock()
+	return dk.Version, key, nil
+}
+
+// dataKey returns a specific unwrapped data key version.
+func (m *KeyManager) dataKey(ctx context.Context, tenantID types.ID, version int) ([]byte, error) {
+	m.mu.Lock()
+	key, ok := m.keys[dataKeyRef{tenantID, version}]
+	m.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	dk, err := m.store.Get(ctx, tenantID, version)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: get data key v%d: %w", version, err)
+	}
+	return m.unwrap(ctx, dk)
+}
+
+func (m *KeyManager) unwrap(ctx context.Context, dk *DataKey) ([]byte, error) {
+	key, err := m.provider.Unwrap(ctx, dk.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: unwrap data key v%d: %w", dk.Version, err)
+	}
+	m.mu.Lock()
+	m.keys[dataKeyRef{dk.TenantID, dk.Version}] = key
+	m.mu.Unlock()
+	return key, nil
+}
+
+func (m *KeyManager) createDataKey(ctx context.Context, tenantID types.ID, version int) (*DataKey, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("crypto: generate data key: %w", err)
+	}
+	wrapped, err := m.provider.Wrap(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: wrap data key: %w", err)
+	}
+
+	now := time.Now().UTC()
+	dk := &DataKey{
+		TenantID:    tenantID,
+		Version:     version,
+		WrappedKey:  wrapped,
+		MasterKeyID: m.provider.ID(),
+		Active:      true,
+		CreatedAt:   now,
+		RotatedAt:   now,
+	}
+	if err := m.store.Create(ctx, dk); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.keys[dataKeyRef{tenantID, version}] = key
+	m.active[tenantID] = activeKey{version: version, expires: now.Add(activeKeyTTL)}
+	m.mu.Unlock()
+	return dk, nil
+}
+
+// =============================================================================
+// Ciphertext header
+// =============================================================================
+
+func envelopeHeader(version int) []byte {
+	header := make([]byte, envelopeHeaderLen)
+	copy(header, envelopeMagic)
+	header[3] = envelopeFormatV1
+	binary.BigEndian.PutUint32(header[4:], uint32(version))
+	return header
+}
+
+func envelopeAAD(header []byte, tenantID types.ID) []byte {
+	return append(append([]byte{}, header...), tenantID.String()...)
+}
+
+// parseEnvelope decodes ciphertext and reports its data key version if it
+// carries an envelope header.
+func parseEnvelope(ciphertext string) ([]byte, int, bool) {
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(raw) <= envelopeHeaderLen {
+		return nil, 0, false
+	}
+	if !bytes.Equal(raw[:3], envelopeMagic) || raw[3] != envelopeFormatV1 {
+		return nil, 0, false
+	}
+	return raw, int(binary.BigEndian.Uint32(raw[4:envelopeHeaderLen])), true
+}
+
+// IsEnvelope reports whether ciphertext carries an envelope header.
+func IsEnvelope(ciphertext string) bool {
+	_, _, ok := parseEnvelope(ciphertext)
+	return ok
+}
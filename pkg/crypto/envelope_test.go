@@ -0,0 +1,219 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/complyark/datalens/pkg/types"
+)
+
+// memoryKeyStore is an in-memory DataKeyStore.
+type memoryKeyStore struct {
+	mu   sync.Mutex
+	keys []DataKey
+}
+
+func (s *memoryKeyStore) GetActive(_ context.Context, tenantID types.ID) (*DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.keys {
+		if k.TenantID == tenantID && k.Active {
+			return &k, nil
+		}
+	}
+	return nil, ErrDataKeyNotFound
+}
+
+func (s *memoryKeyStore) Get(_ context.Context, tenantID types.ID, version int) (*DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.keys {
+		if k.TenantID == tenantID && k.Version == version {
+			return &k, nil
+		}
+	}
+	return nil, ErrDataKeyNotFound
+}
+
+func (s *memoryKeyStore) Create(_ context.Context, key *DataKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		if s.keys[i].TenantID == key.TenantID {
+			if s.keys[i].Version == key.Version {
+				return ErrDataKeyExists
+			}
+			s.keys[i].Active = false
+		}
+	}
+	s.keys = append(s.keys, *key)
+	return nil
+}
+
+func (s *memoryKeyStore) UpdateWrappedKey(_ context.Context, tenantID types.ID, version int, wrapped []byte, masterKeyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		if s.keys[i].TenantID == tenantID && s.keys[i].Version == version {
+			s.keys[i].WrappedKey = wrapped
+			s.keys[i].MasterKeyID = masterKeyID
+			return nil
+		}
+	}
+	return ErrDataKeyNotFound
+}
+
+func (s *memoryKeyStore) ListAll(_ context.Context) ([]DataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DataKey(nil), s.keys...), nil
+}
+
+func newTestKeyManager(t *testing.T) (*KeyManager, *LocalFileProvider, *memoryKeyStore) {
+	t.Helper()
+	provider, err := NewLocalFileProvider(filepath.Join(t.TempDir(), "master.json"))
+	if err != nil {
+		t.Fatalf("NewLocalFileProvider failed: %v", err)
+	}
+	store := &memoryKeyStore{}
+	return NewKeyManager(provider, store, NewStaticCipher("legacy-secret")), provider, store
+}
+
+func TestKeyManager_EncryptDecrypt(t *testing.T) {
+	m, _, store := newTestKeyManager(t)
+	ctx := context.Background()
+	tenantID := types.NewID()
+
+	ciphertext, err := m.Encrypt(ctx, tenantID, "user:pass")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !IsEnvelope(ciphertext) {
+		t.Fatal("Expected envelope header on ciphertext")
+	}
+	if len(store.keys) != 1 || store.keys[0].Version != 1 {
+		t.Fatalf("Expected data key v1 to be created, got %+v", store.keys)
+	}
+
+	plaintext, err := m.Decrypt(ctx, tenantID, ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if plaintext != "user:pass" {
+		t.Errorf("Expected %q, got %q", "user:pass", plaintext)
+	}
+
+	// A ciphertext is bound to its tenant.
+	if _, err := m.Decrypt(ctx, types.NewID(), ciphertext); err == nil {
+		t.Error("Expected error decrypting with another tenant, got nil")
+	}
+}
+
+func TestKeyManager_DecryptLegacy(t *testing.T) {
+	m, _, _ := newTestKeyManager(t)
+	ctx := context.Background()
+	tenantID := types.NewID()
+
+	legacy, err := NewStaticCipher("legacy-secret").Encrypt(ctx, tenantID, "refresh-token")
+	if err != nil {
+		t.Fatalf("Legacy encrypt failed: %v", err)
+	}
+
+	plaintext, err := m.Decrypt(ctx, tenantID, legacy)
+	if err != nil {
+		t.Fatalf("Decrypt legacy failed: %v", err)
+	}
+	if plaintext != "refresh-token" {
+		t.Errorf("Expected %q, got %q", "refresh-token", plaintext)
+	}
+
+	// Re-encryption upgrades legacy ciphertexts to envelopes.
+	upgraded, changed, err := m.Reencrypt(ctx, tenantID, legacy)
+	if err != nil || !changed || !IsEnvelope(upgraded) {
+		t.Fatalf("Expected legacy ciphertext to be upgraded, changed=%v err=%v", changed, err)
+	}
+
+	noLegacy := NewKeyManager(m.provider, m.store, nil)
+	if _, err := noLegacy.Decrypt(ctx, tenantID, legacy); !errors.Is(err, ErrNotEnvelope) {
+		t.Errorf("Expected ErrNotEnvelope without legacy cipher, got %v", err)
+	}
+}
+
+func TestKeyManager_RotateDataKey(t *testing.T) {
+	m, _, _ := newTestKeyManager(t)
+	ctx := context.Background()
+	tenantID := types.NewID()
+
+	old, err := m.Encrypt(ctx, tenantID, "secret")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	version, err := m.RotateDataKey(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("RotateDataKey failed: %v", err)
+	}
+	if version != 2 {
+		t.Fatalf("Expected version 2, got %d", version)
+	}
+
+	// Old ciphertexts still decrypt.
+	if got, err := m.Decrypt(ctx, tenantID, old); err != nil || got != "secret" {
+		t.Fatalf("Decrypt old ciphertext: got %q, err %v", got, err)
+	}
+
+	// Re-encryption moves them to the new version, and is a no-op after.
+	updated, changed, err := m.Reencrypt(ctx, tenantID, old)
+	if err != nil || !changed {
+		t.Fatalf("Expected re-encryption, changed=%v err=%v", changed, err)
+	}
+	if _, version, _ := parseEnvelope(updated); version != 2 {
+		t.Errorf("Expected re-encrypted ciphertext to use v2, got v%d", version)
+	}
+	if _, changed, _ := m.Reencrypt(ctx, tenantID, updated); changed {
+		t.Error("Expected no change for ciphertext already on active key")
+	}
+}
+
+func TestKeyManager_RewrapAfterMasterRotation(t *testing.T) {
+	m, provider, store := newTestKeyManager(t)
+	ctx := context.Background()
+	tenantID := types.NewID()
+
+	ciphertext, err := m.Encrypt(ctx, tenantID, "secret")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	before := append([]byte(nil), store.keys[0].WrappedKey...)
+
+	if err := provider.Rotate(ctx); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	n, err := m.RewrapDataKeys(ctx)
+	if err != nil {
+		t.Fatalf("RewrapDataKeys failed: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Expected 1 rewrapped key, got %d", n)
+	}
+	if v, _ := wrappedVersion(store.keys[0].WrappedKey); v != 2 {
+		t.Errorf("Expected data key wrapped by master v2, got v%d", v)
+	}
+	if string(before) == string(store.keys[0].WrappedKey) {
+		t.Error("Expected wrapped key to change")
+	}
+
+	// A fresh manager (no cache) still decrypts existing ciphertexts.
+	fresh := NewKeyManager(provider, store, nil)
+	if got, err := fresh.Decrypt(ctx, tenantID, ciphertext); err != nil || got != "secret" {
+		t.Fatalf("Decrypt after rewrap: got %q, err %v", got, err)
+	}
+
+	// A second pass has nothing to do.
+	if n, _ := m.RewrapDataKeys(ctx); n != 0 {
+		t.Errorf("Expected no keys to rewrap, got %d", n)
+	}
+}
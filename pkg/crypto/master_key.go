@@ -0,0 +1,282 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// MasterKeyProvider wraps and unwraps per-tenant data keys. The master key
+// itself never leaves the provider. Providers are versioned: after Rotate,
+// new wraps use the new version while older wraps still unwrap.
+type MasterKeyProvider interface {
+	// ID identifies the provider and key, e.g. "local:/etc/datalens/master.json".
+	ID() string
+	// Wrap encrypts a data key under the current master key version.
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	// Unwrap decrypts a wrapped data key with whichever version wrapped it.
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+	// Rewrap re-encrypts a wrapped data key under the current master key
+	// version. It reports false if the key was already current.
+	Rewrap(ctx context.Context, wrapped []byte) ([]byte, bool, error)
+	// Rotate creates a new master key version and makes it current.
+	Rotate(ctx context.Context) error
+}
+
+// =============================================================================
+// LocalFileProvider — master keys kept in a JSON key file
+// =============================================================================
+
+// localKeyFile is the on-disk format of a LocalFileProvider key file.
+type localKeyFile struct {
+	Active int               `json:"active"`
+	Keys   map[string]string `json:"keys"` // version → base64 AES-256 key
+}
+
+// LocalFileProvider keeps versioned AES-256 master keys in a JSON file.
+// It suits single-node and development deployments; use Vault transit when
+// the master key must not live on application hosts.
+//
+// Wrapped keys are: 4-byte big-endian master version || nonce || AES-GCM.
+type LocalFileProvider struct {
+	path string
+
+	mu     sync.RWMutex
+	active int
+	keys   map[int][]byte
+}
+
+// NewLocalFileProvider loads the key file at path, creating it with a fresh
+// master key if it does not exist.
+func NewLocalFileProvider(path string) (*LocalFileProvider, error) {
+	p := &LocalFileProvider{path: path}
+	if err := p.load(); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		p.keys = map[int][]byte{}
+		if err := p.addVersion(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// ID returns "local:<path>".
+func (p *LocalFileProvider) ID() string {
+	return "local:" + p.path
+}
+
+// Wrap encrypts dataKey under the active master key.
+func (p *LocalFileProvider) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	p.mu.RLock()
+	version, key := p.active, p.keys[p.active]
+	p.mu.RUnlock()
+
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, uint32(version))
+	sealed, err := sealGCM(key, dataKey, header)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, sealed...), nil
+}
+
+// Unwrap decrypts a data key wrapped by any known master key version. An
+// unknown version triggers a reload in case another process rotated.
+func (p *LocalFileProvider) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	version, err := wrappedVersion(wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := p.key(version)
+	if !ok {
+		if err := p.load(); err != nil {
+			return nil, fmt.Errorf("crypto: reload master keys: %w", err)
+		}
+		if key, ok = p.key(version); !ok {
+			return nil, fmt.Errorf("crypto: unknown master key version %d", version)
+		}
+	}
+	return openGCM(key, wrapped[4:], wrapped[:4])
+}
+
+// Rewrap re-wraps a data key under the active master version.
+func (p *LocalFileProvider) Rewrap(ctx context.Context, wrapped []byte) ([]byte, bool, error) {
+	version, err := wrappedVersion(wrapped)
+	if err != nil {
+		return nil, false, err
+	}
+	p.mu.RLock()
+	current := version == p.active
+	p.mu.RUnlock()
+	if current {
+		return wrapped, false, nil
+	}
+
+	dataKey, err := p.Unwrap(ctx, wrapped)
+	if err != nil {
+		return nil, false, err
+	}
+	rewrapped, err := p.Wrap(ctx, dataKey)
+	if err != nil {
+		return nil, false, err
+	}
+	return rewrapped, true, nil
+}
+
+// Rotate generates a new master key version and persists the key file.
+func (p *LocalFileProvider) Rotate(_ context.Context) error {
+	// Pick up versions written by other processes before adding ours.
+	if err := p.load(); err != nil {
+		return fmt.Errorf("crypto: reload master keys: %w", err)
+	}
+	return p.addVersion()
+}
+
+func (p *LocalFileProvider) key(version int) ([]byte, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	k, ok := p.keys[version]
+	return k, ok
+}
+
+func (p *LocalFileProvider) load() error {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+	var f localKeyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("crypto: parse key file %s: %w", p.path, err)
+	}
+
+	keys := make(map[int][]byte, len(f.Keys))
+	for v, enc := range f.Keys {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("crypto: invalid key version %q in %s", v, p.path)
+		}
+		key, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("crypto: master key version %d in %s must be 32 base64-encoded bytes", version, p.path)
+		}
+		keys[version] = key
+	}
+	if _, ok := keys[f.Active]; !ok {
+		return fmt.Errorf("crypto: active master key version %d missing from %s", f.Active, p.path)
+	}
+
+	p.mu.Lock()
+	p.active, p.keys = f.Active, keys
+	p.mu.Unlock()
+	return nil
+}
+
+// addVersion generates the next master key version, makes it active, and
+// writes the key file atomically with owner-only permissions.
+func (p *LocalFileProvider) addVersion() error {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return fmt.Errorf("crypto: generate master key: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	next := 0
+	for v := range p.keys {
+		next = max(next, v)
+	}
+	next++
+
+	f := localKeyFile{Active: next, Keys: make(map[string]string, len(p.keys)+1)}
+	for v, k := range p.keys {
+		f.Keys[strconv.Itoa(v)] = base64.StdEncoding.EncodeToString(k)
+	}
+	f.Keys[strconv.Itoa(next)] = base64.StdEncoding.EncodeToString(key)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o700); err != nil {
+		return fmt.Errorf("crypto: create key dir: %w", err)
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("crypto: write key file: %w", err)
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return fmt.Errorf("crypto: replace key file: %w", err)
+	}
+
+	p.keys[next] = key
+	p.active = next
+	return nil
+}
+
+func wrappedVersion(wrapped []byte) (int, error) {
+	if len(wrapped) < 4 {
+		return 0, errors.New("crypto: wrapped key too short")
+	}
+	return int(binary.BigEndian.Uint32(wrapped[:4])), nil
+}
+
+// =============================================================================
+// AES-GCM helpers
+// =============================================================================
+
+// sealGCM returns nonce || ciphertext, authenticating aad.
+func sealGCM(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("crypto: read nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// openGCM reverses sealGCM.
+func openGCM(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("crypto: ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: open gcm: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: new cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: new gcm: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,165 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLocalFileProvider_CreatesKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "master.json")
+	if _, err := NewLocalFileProvider(path); err != nil {
+		t.Fatalf("NewLocalFileProvider failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected key file to exist: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected key file mode 0600, got %o", info.Mode().Perm())
+	}
+}
+
+func TestLocalFileProvider_RotateAcrossProcesses(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "master.json")
+
+	a, err := NewLocalFileProvider(path)
+	if err != nil {
+		t.Fatalf("NewLocalFileProvider failed: %v", err)
+	}
+	b, err := NewLocalFileProvider(path)
+	if err != nil {
+		t.Fatalf("NewLocalFileProvider failed: %v", err)
+	}
+
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	if err := a.Rotate(ctx); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	wrapped, err := a.Wrap(ctx, dataKey)
+	if err != nil {
+		t.Fatalf("Wrap failed: %v", err)
+	}
+
+	// b has not seen v2 yet and must reload to unwrap.
+	got, err := b.Unwrap(ctx, wrapped)
+	if err != nil {
+		t.Fatalf("Unwrap failed: %v", err)
+	}
+	if string(got) != string(dataKey) {
+		t.Error("Unwrapped key does not match")
+	}
+
+	if _, changed, err := b.Rewrap(ctx, wrapped); err != nil || changed {
+		t.Errorf("Expected current wrap to be left alone, changed=%v err=%v", changed, err)
+	}
+}
+
+func TestLocalFileProvider_RejectsTamperedWrap(t *testing.T) {
+	ctx := context.Background()
+	p, err := NewLocalFileProvider(filepath.Join(t.TempDir(), "master.json"))
+	if err != nil {
+		t.Fatalf("NewLocalFileProvider failed: %v", err)
+	}
+	wrapped, _ := p.Wrap(ctx, []byte("0123456789abcdef0123456789abcdef"))
+	wrapped[len(wrapped)-1] ^= 0x01
+
+	if _, err := p.Unwrap(ctx, wrapped); err == nil {
+		t.Error("Expected error for tampered wrapped key, got nil")
+	}
+}
+
+// fakeVault emulates the transit endpoints used by VaultTransitProvider.
+// Ciphertexts are "vault:v<N>:<base64 plaintext>" — enough to exercise the
+// protocol without real cryptography.
+type fakeVault struct {
+	mu     sync.Mutex
+	latest int
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != "root" {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors":["permission denied"]}`)
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var body map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	reply := func(data map[string]any) { _ = json.NewEncoder(w).Encode(map[string]any{"data": data}) }
+
+	switch r.URL.Path {
+	case "/v1/transit/encrypt/datalens":
+		reply(map[string]any{"ciphertext": fmt.Sprintf("vault:v%d:%s", v.latest, body["plaintext"])})
+	case "/v1/transit/decrypt/datalens":
+		parts := strings.SplitN(body["ciphertext"], ":", 3)
+		reply(map[string]any{"plaintext": parts[2]})
+	case "/v1/transit/rewrap/datalens":
+		parts := strings.SplitN(body["ciphertext"], ":", 3)
+		reply(map[string]any{"ciphertext": fmt.Sprintf("vault:v%d:%s", v.latest, parts[2])})
+	case "/v1/transit/keys/datalens/rotate":
+		v.latest++
+		w.WriteHeader(http.StatusNoContent)
+	case "/v1/transit/keys/datalens":
+		reply(map[string]any{"latest_version": v.latest})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestVaultTransitProvider(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(&fakeVault{latest: 1})
+	defer srv.Close()
+
+	p, err := NewVaultTransitProvider(VaultTransitConfig{Address: srv.URL, Token: "root", KeyName: "datalens"})
+	if err != nil {
+		t.Fatalf("NewVaultTransitProvider failed: %v", err)
+	}
+
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	wrapped, err := p.Wrap(ctx, dataKey)
+	if err != nil {
+		t.Fatalf("Wrap failed: %v", err)
+	}
+	want := "vault:v1:" + base64.StdEncoding.EncodeToString(dataKey)
+	if string(wrapped) != want {
+		t.Fatalf("Expected %q, got %q", want, wrapped)
+	}
+
+	got, err := p.Unwrap(ctx, wrapped)
+	if err != nil || string(got) != string(dataKey) {
+		t.Fatalf("Unwrap: got %q, err %v", got, err)
+	}
+
+	if _, changed, _ := p.Rewrap(ctx, wrapped); changed {
+		t.Error("Expected no rewrap before rotation")
+	}
+	if err := p.Rotate(ctx); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	rewrapped, changed, err := p.Rewrap(ctx, wrapped)
+	if err != nil || !changed {
+		t.Fatalf("Expected rewrap after rotation, changed=%v err=%v", changed, err)
+	}
+	if !strings.HasPrefix(string(rewrapped), "vault:v2:") {
+		t.Errorf("Expected v2 ciphertext, got %q", rewrapped)
+	}
+
+	bad, _ := NewVaultTransitProvider(VaultTransitConfig{Address: srv.URL, Token: "wrong", KeyName: "datalens"})
+	if _, err := bad.Wrap(ctx, dataKey); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected permission error, got %v", err)
+	}
+}
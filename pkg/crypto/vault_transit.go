@@ -0,0 +1,188 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VaultTransitConfig configures a VaultTransitProvider.
+type VaultTransitConfig struct {
+	Address string // e.g. https://vault.internal:8200
+	Token   string
+	Mount   string // transit mount path, defaults to "transit"
+	KeyName string // transit key name
+	// Namespace is the Vault Enterprise namespace, if any.
+	Namespace string
+	Client    *http.Client
+}
+
+// VaultTransitProvider wraps data keys with HashiCorp Vault's transit
+// secrets engine, so the master key never leaves Vault. Wrapped keys are
+// Vault ciphertext strings ("vault:v<N>:...") and carry their own version.
+type VaultTransitProvider struct {
+	cfg    VaultTransitConfig
+	client *http.Client
+}
+
+// NewVaultTransitProvider creates a VaultTransitProvider.
+func NewVaultTransitProvider(cfg VaultTransitConfig) (*VaultTransitProvider, error) {
+	if cfg.Address == "" || cfg.KeyName == "" {
+		return nil, fmt.Errorf("crypto: vault address and transit key name are required")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = "transit"
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+	return &VaultTransitProvider{cfg: cfg, client: client}, nil
+}
+
+// ID returns "vault-transit:<mount>/<key>".
+func (p *VaultTransitProvider) ID() string {
+	return "vault-transit:" + p.cfg.Mount + "/" + p.cfg.KeyName
+}
+
+// Wrap encrypts dataKey with the latest transit key version.
+func (p *VaultTransitProvider) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dataKey)}
+	if err := p.do(ctx, http.MethodPost, "encrypt/"+p.cfg.KeyName, body, &resp); err != nil {
+		return nil, err
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+// Unwrap decrypts a Vault transit ciphertext.
+func (p *VaultTransitProvider) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": string(wrapped)}
+	if err := p.do(ctx, http.MethodPost, "decrypt/"+p.cfg.KeyName, body, &resp); err != nil {
+		return nil, err
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: decode vault plaintext: %w", err)
+	}
+	return dataKey, nil
+}
+
+// Rewrap uses Vault's rewrap endpoint, which re-encrypts under the latest
+// key version without exposing the data key to this process.
+func (p *VaultTransitProvider) Rewrap(ctx context.Context, wrapped []byte) ([]byte, bool, error) {
+	version, err := vaultCiphertextVersion(string(wrapped))
+	if err != nil {
+		return nil, false, err
+	}
+	latest, err := p.latestVersion(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if version >= latest {
+		return wrapped, false, nil
+	}
+
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": string(wrapped)}
+	if err := p.do(ctx, http.MethodPost, "rewrap/"+p.cfg.KeyName, body, &resp); err != nil {
+		return nil, false, err
+	}
+	return []byte(resp.Data.Ciphertext), true, nil
+}
+
+// Rotate creates a new version of the transit key.
+func (p *VaultTransitProvider) Rotate(ctx context.Context) error {
+	return p.do(ctx, http.MethodPost, "keys/"+p.cfg.KeyName+"/rotate", nil, nil)
+}
+
+func (p *VaultTransitProvider) latestVersion(ctx context.Context) (int, error) {
+	var resp struct {
+		Data struct {
+			LatestVersion int `json:"latest_version"`
+		} `json:"data"`
+	}
+	if err := p.do(ctx, http.MethodGet, "keys/"+p.cfg.KeyName, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Data.LatestVersion, nil
+}
+
+func (p *VaultTransitProvider) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/%s", p.cfg.Address, p.cfg.Mount, path)
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("crypto: build vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("crypto: vault %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&vaultErr)
+		return fmt.Errorf("crypto: vault %s: status %d: %s", path, resp.StatusCode, strings.Join(vaultErr.Errors, "; "))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("crypto: decode vault %s response: %w", path, err)
+	}
+	return nil
+}
+
+// vaultCiphertextVersion extracts N from "vault:vN:...".
+func vaultCiphertextVersion(ciphertext string) (int, error) {
+	parts := strings.SplitN(ciphertext, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" || !strings.HasPrefix(parts[1], "v") {
+		return 0, fmt.Errorf("crypto: not a vault transit ciphertext")
+	}
+	v, err := strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+	if err != nil {
+		return 0, fmt.Errorf("crypto: invalid vault ciphertext version %q", parts[1])
+	}
+	return v, nil
+}
@@ -57,6 +57,9 @@ const (
 	EventScanCompleted = "scan.completed"
 	EventScanFailed    = "scan.failed"
 
+	// Schema Events
+	EventSchemaDriftDetected = "schema.drift_detected"
+
 	// DSR Events
 	EventDSRCreated            = "dsr.created"
 	EventDSRExecuting          = "dsr.executing"
@@ -126,6 +129,10 @@ const (
 	// Governance Events (Additional)
 	EventLineageFlowTracked      = "governance.lineage.flow_tracked"
 	EventGovernancePolicyCreated = "governance.policy_created"
+
+	// AI Usage Events
+	EventAIBudgetThreshold = "ai.budget_threshold_reached"
+	EventAIBudgetExceeded  = "ai.budget_exceeded"
 )
 
 // =============================================================================
@@ -0,0 +1,120 @@
+package redact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client calls the redaction API with a DataLens API key. Redaction needs
+// a key with the PII:REDACT permission, Restore one with PII:DETOKENIZE.
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// New creates a client for the API at baseURL, e.g.
+// https://datalens.example.com/api/v2.
+func New(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SetHTTPClient replaces the default HTTP client, which times out after
+// 30 seconds.
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	c.http = hc
+}
+
+// APIError is an error response from the API.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("redact: %s (%d): %s", e.Code, e.Status, e.Message)
+}
+
+// Redact detects and replaces PII in req.Text or req.JSON.
+func (c *Client) Redact(ctx context.Context, req Request) (*Response, error) {
+	var resp Response
+	if err := c.post(ctx, "/redact", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Text returns text with its PII replaced according to mode. On error it
+// returns "", never the unredacted text, so callers fail closed.
+func (c *Client) Text(ctx context.Context, text string, mode Mode) (string, error) {
+	resp, err := c.Redact(ctx, Request{Text: text, Mode: mode})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// Restore replaces the vault tokens in req.Text or req.JSON with the
+// values they stand for.
+func (c *Client) Restore(ctx context.Context, req RestoreRequest) (*RestoreResponse, error) {
+	var resp RestoreResponse
+	if err := c.post(ctx, "/redact/restore", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// envelope is the API's standard response wrapper.
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *Client) post(ctx context.Context, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("redact: marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("redact: create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("redact: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return &APIError{Status: resp.StatusCode, Code: "BAD_RESPONSE", Message: err.Error()}
+	}
+	if resp.StatusCode >= 300 || !env.Success {
+		apiErr := &APIError{Status: resp.StatusCode, Code: "UNKNOWN", Message: http.StatusText(resp.StatusCode)}
+		if env.Error != nil {
+			apiErr.Code, apiErr.Message = env.Error.Code, env.Error.Message
+		}
+		return apiErr
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return fmt.Errorf("redact: decode response: %w", err)
+	}
+	return nil
+}
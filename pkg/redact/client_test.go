@@ -0,0 +1,58 @@
+package redact
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Text(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/redact", r.URL.Path)
+		assert.Equal(t, "dl_test", r.Header.Get("X-API-Key"))
+
+		var req Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "mail a@example.com", req.Text)
+		assert.Equal(t, ModePseudonymize, req.Mode)
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"success": true,
+			"data": Response{
+				Text:  "mail [EMAIL_3f9a0c1b7d2e4a5b8c6d9e0f1a2b3c4d]",
+				Spans: []Span{{Start: 5, End: 18, Type: "EMAIL", Replacement: "[EMAIL_3f9a0c1b7d2e4a5b8c6d9e0f1a2b3c4d]"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	c := New(server.URL+"/api/v2/", "dl_test")
+	got, err := c.Text(context.Background(), "mail a@example.com", ModePseudonymize)
+	require.NoError(t, err)
+	assert.Equal(t, "mail [EMAIL_3f9a0c1b7d2e4a5b8c6d9e0f1a2b3c4d]", got)
+}
+
+func TestClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"success":false,"error":{"code":"FORBIDDEN","message":"insufficient permissions"}}`))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, "dl_test").Restore(context.Background(), RestoreRequest{Text: "[EMAIL_3f9a0c1b7d2e4a5b8c6d9e0f1a2b3c4d]"})
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusForbidden, apiErr.Status)
+	assert.Equal(t, "FORBIDDEN", apiErr.Code)
+
+	// Text fails closed
+	got, err := New(server.URL, "dl_test").Text(context.Background(), "a@example.com", ModeRedact)
+	assert.Error(t, err)
+	assert.Empty(t, got)
+}
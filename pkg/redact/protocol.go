@@ -0,0 +1,87 @@
+// Package redact is the wire protocol and Go client of the DataLens PII
+// redaction API. Services send it prompts, log lines or JSON documents
+// before they leave for an LLM or a log pipeline; DataLens finds personal
+// data and credentials with the same detectors it scans data sources
+// with, and returns where they are and a scrubbed copy.
+//
+//	c := redact.New("https://datalens.example.com/api/v2", os.Getenv("DATALENS_API_KEY"))
+//	clean, err := c.Text(ctx, prompt, redact.ModePseudonymize)
+//
+// Pseudonymized output can be made reversible: DataLens keeps the original
+// values in a per-tenant token vault, and Restore puts them back into, say,
+// the LLM's answer.
+package redact
+
+import "encoding/json"
+
+// Mode is what a detected value is replaced with.
+type Mode string
+
+const (
+	// ModeRedact replaces values with their type: "[EMAIL]".
+	ModeRedact Mode = "REDACT"
+	// ModePseudonymize replaces values with a token that is the same for
+	// the same value within a tenant: "[EMAIL_3f9a0c1b7d2e4a5b8c6d9e0f1a2b3c4d]". Text stays
+	// coherent — an LLM can still tell two people apart.
+	ModePseudonymize Mode = "PSEUDONYMIZE"
+)
+
+// Request is the body of POST /redact. Exactly one of Text and JSON is set.
+type Request struct {
+	Text string          `json:"text,omitempty"`
+	JSON json.RawMessage `json:"json,omitempty"` // Every string and number in it is scanned
+
+	Mode Mode `json:"mode,omitempty"` // Default ModeRedact
+
+	// Reversible stores pseudonymized values in the token vault for
+	// Restore. ModePseudonymize only.
+	Reversible bool `json:"reversible,omitempty"`
+	// RetentionDays is how long the vault keeps the values; default 30,
+	// at most 365.
+	RetentionDays int `json:"retention_days,omitempty"`
+
+	// Types limits redaction to these PII types, e.g. EMAIL, CREDENTIAL
+	// or CUSTOM:POLICY_NUMBER. Empty means all.
+	Types []string `json:"types,omitempty"`
+	// MinConfidence is the confidence below which detections are left
+	// alone; default 0.5.
+	MinConfidence float64 `json:"min_confidence,omitempty"`
+}
+
+// Response is the result of POST /redact.
+type Response struct {
+	Text  string          `json:"text,omitempty"`
+	JSON  json.RawMessage `json:"json,omitempty"`
+	Spans []Span          `json:"spans"`
+}
+
+// Span is one detected value. Start and End are byte offsets into the
+// input text, or into the string at Path for JSON input; End is exclusive.
+type Span struct {
+	Path        string  `json:"path,omitempty"` // JSON input: e.g. $.user.emails[0]
+	Start       int     `json:"start"`
+	End         int     `json:"end"`
+	Type        string  `json:"type"`
+	Category    string  `json:"category"`
+	Sensitivity string  `json:"sensitivity"`
+	Confidence  float64 `json:"confidence"`
+	Method      string  `json:"method"`
+	Replacement string  `json:"replacement"`
+}
+
+// RestoreRequest is the body of POST /redact/restore. Exactly one of Text
+// and JSON is set.
+type RestoreRequest struct {
+	Text string          `json:"text,omitempty"`
+	JSON json.RawMessage `json:"json,omitempty"`
+}
+
+// RestoreResponse is the result of POST /redact/restore.
+type RestoreResponse struct {
+	Text     string          `json:"text,omitempty"`
+	JSON     json.RawMessage `json:"json,omitempty"`
+	Restored int             `json:"restored"`
+	// Unresolved lists tokens not in the vault: expired, issued without
+	// Reversible, or issued to another tenant.
+	Unresolved []string `json:"unresolved,omitempty"`
+}
@@ -6,6 +6,7 @@
 package types
 
 import (
+	"slices"
 	"strings"
 	"time"
 
@@ -48,8 +49,23 @@ const (
 	PIICategoryProfessional PIICategory = "PROFESSIONAL"
 	PIICategoryGovernmentID PIICategory = "GOVERNMENT_ID"
 	PIICategoryMinor        PIICategory = "MINOR"
+	PIICategoryCredentials  PIICategory = "CREDENTIALS" // Secrets that grant access to systems, not personal data as such
 )
 
+// PIICategories returns every PIICategory.
+func PIICategories() []PIICategory {
+	return []PIICategory{
+		PIICategoryIdentity, PIICategoryContact, PIICategoryFinancial, PIICategoryHealth,
+		PIICategoryBiometric, PIICategoryGenetic, PIICategoryLocation, PIICategoryBehavioral,
+		PIICategoryProfessional, PIICategoryGovernmentID, PIICategoryMinor, PIICategoryCredentials,
+	}
+}
+
+// IsValid reports whether c is a known PIICategory.
+func (c PIICategory) IsValid() bool {
+	return slices.Contains(PIICategories(), c)
+}
+
 // PIIType identifies specific types of personal data.
 type PIIType string
 
@@ -74,8 +90,26 @@ const (
 	PIITypeMedicalRecord PIIType = "MEDICAL_RECORD"
 	PIITypePhoto         PIIType = "PHOTO"
 	PIITypeSignature     PIIType = "SIGNATURE"
+	PIITypeOrganization  PIIType = "ORGANIZATION" // Employer or affiliation named alongside a person
+	PIITypeCredential    PIIType = "CREDENTIAL"   // API key, password, private key, token or connection string
 )
 
+// PIITypes returns every PIIType.
+func PIITypes() []PIIType {
+	return []PIIType{
+		PIITypeName, PIITypeEmail, PIITypePhone, PIITypeAddress, PIITypeAadhaar, PIITypePAN,
+		PIITypePassport, PIITypeSSN, PIITypeNationalID, PIITypeDOB, PIITypeGender,
+		PIITypeBankAccount, PIITypeCreditCard, PIITypeIPAddress, PIITypeMACAddress,
+		PIITypeDeviceID, PIITypeBiometric, PIITypeMedicalRecord, PIITypePhoto,
+		PIITypeSignature, PIITypeOrganization, PIITypeCredential,
+	}
+}
+
+// IsValid reports whether t is a known PIIType.
+func (t PIIType) IsValid() bool {
+	return slices.Contains(PIITypes(), t)
+}
+
 // SensitivityLevel classifies data sensitivity.
 type SensitivityLevel string
 
@@ -86,6 +120,16 @@ const (
 	SensitivityCritical SensitivityLevel = "CRITICAL"
 )
 
+// SensitivityLevels returns every SensitivityLevel, least sensitive first.
+func SensitivityLevels() []SensitivityLevel {
+	return []SensitivityLevel{SensitivityLow, SensitivityMedium, SensitivityHigh, SensitivityCritical}
+}
+
+// IsValid reports whether l is a known SensitivityLevel.
+func (l SensitivityLevel) IsValid() bool {
+	return slices.Contains(SensitivityLevels(), l)
+}
+
 // =============================================================================
 // Enums — Detection
 // =============================================================================
@@ -99,6 +143,10 @@ const (
 	DetectionMethodHeuristic DetectionMethod = "HEURISTIC"
 	DetectionMethodIndustry  DetectionMethod = "INDUSTRY"
 	DetectionMethodManual    DetectionMethod = "MANUAL"
+	DetectionMethodCustom    DetectionMethod = "CUSTOM" // Tenant-defined detection rules
+	DetectionMethodIndic     DetectionMethod = "INDIC"  // Indic-script and transliterated text
+	DetectionMethodNER       DetectionMethod = "NER"    // Offline named-entity model for free text
+	DetectionMethodSecret    DetectionMethod = "SECRET" // Credential formats and high-entropy tokens
 )
 
 // VerificationStatus tracks human verification state.
@@ -140,6 +188,19 @@ const (
 	LegalBasisEmployment         LegalBasis = "EMPLOYMENT"
 )
 
+// LegalBases returns every LegalBasis.
+func LegalBases() []LegalBasis {
+	return []LegalBasis{
+		LegalBasisConsent, LegalBasisContract, LegalBasisLegalObligation, LegalBasisVitalInterest,
+		LegalBasisPublicInterest, LegalBasisLegitimateInterest, LegalBasisEmployment,
+	}
+}
+
+// IsValid reports whether b is a known LegalBasis.
+func (b LegalBasis) IsValid() bool {
+	return slices.Contains(LegalBases(), b)
+}
+
 // ConsentMechanism describes how consent was obtained.
 type ConsentMechanism string
 